@@ -0,0 +1,153 @@
+// Package config has the settings of the subsystems of the toolkit,
+// loaded from a YAML file and environment variables:
+//
+//	cfg, err := config.Load("config.yaml")
+//	...
+//	o := outbox.New(sessionPool, cfg.Outbox.Options()...)
+//
+// Zero values of the optional settings mean the defaults of the constructors.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/inbox"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/outbox"
+)
+
+var ErrInvalidConfig = errors.New("invalid config")
+
+type Config struct {
+	Database DatabaseConfig `yaml:"database"`
+	Outbox   OutboxConfig   `yaml:"outbox"`
+	Inbox    InboxConfig    `yaml:"inbox"`
+}
+
+type DatabaseConfig struct {
+	DSN string `yaml:"dsn" env:"DATABASE_DSN"`
+	// MaxConns and MinConns size the pool, 0 means the defaults of pgxpool.
+	MaxConns int32 `yaml:"max_conns" env:"DATABASE_MAX_CONNS"`
+	MinConns int32 `yaml:"min_conns" env:"DATABASE_MIN_CONNS"`
+}
+
+type OutboxConfig struct {
+	Table        string `yaml:"table" env:"OUTBOX_TABLE"`
+	OffsetsTable string `yaml:"offsets_table" env:"OUTBOX_OFFSETS_TABLE"`
+	BatchSize    int    `yaml:"batch_size" env:"OUTBOX_BATCH_SIZE"`
+	// PollInterval and Workers are the defaults of the subscriptions run by the process.
+	PollInterval time.Duration `yaml:"poll_interval" env:"OUTBOX_POLL_INTERVAL"`
+	Workers      int           `yaml:"workers" env:"OUTBOX_WORKERS"`
+	// Retention is the age of acknowledged messages deleted by Cleanup, 0 keeps them.
+	Retention time.Duration `yaml:"retention" env:"OUTBOX_RETENTION"`
+}
+
+type InboxConfig struct {
+	Table        string        `yaml:"table" env:"INBOX_TABLE"`
+	Sequence     string        `yaml:"sequence" env:"INBOX_SEQUENCE"`
+	PollInterval time.Duration `yaml:"poll_interval" env:"INBOX_POLL_INTERVAL"`
+	Workers      int           `yaml:"workers" env:"INBOX_WORKERS"`
+}
+
+// Default returns the config with the defaults of the constructors, without DSN.
+func Default() Config {
+	return Config{
+		Outbox: OutboxConfig{
+			Table:        "outbox",
+			OffsetsTable: "outbox_offsets",
+			BatchSize:    100,
+			PollInterval: time.Second,
+			Workers:      1,
+		},
+		Inbox: InboxConfig{
+			Table:        "inbox",
+			Sequence:     "inbox_received_position_seq",
+			PollInterval: time.Second,
+			Workers:      1,
+		},
+	}
+}
+
+// identifier matches table names, optionally qualified by schema,
+// since they are substituted into SQL as is.
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Validate returns all problems of the config joined, each matching ErrInvalidConfig.
+func (c Config) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrInvalidConfig, fmt.Sprintf(format, args...)))
+	}
+
+	if c.Database.DSN == "" {
+		invalid("database.dsn is required")
+	}
+	if c.Database.MaxConns < 0 || c.Database.MinConns < 0 {
+		invalid("database pool sizes must not be negative")
+	}
+	if c.Database.MaxConns > 0 && c.Database.MinConns > c.Database.MaxConns {
+		invalid("database.min_conns %d exceeds database.max_conns %d", c.Database.MinConns, c.Database.MaxConns)
+	}
+
+	for name, table := range map[string]string{
+		"outbox.table":         c.Outbox.Table,
+		"outbox.offsets_table": c.Outbox.OffsetsTable,
+		"inbox.table":          c.Inbox.Table,
+		"inbox.sequence":       c.Inbox.Sequence,
+	} {
+		if table != "" && !identifier.MatchString(table) {
+			invalid("%s %q is not an identifier", name, table)
+		}
+	}
+
+	if c.Outbox.BatchSize < 0 {
+		invalid("outbox.batch_size must not be negative")
+	}
+	if c.Outbox.Retention < 0 {
+		invalid("outbox.retention must not be negative")
+	}
+	if c.Outbox.PollInterval < 0 || c.Inbox.PollInterval < 0 {
+		invalid("poll intervals must not be negative")
+	}
+	if c.Outbox.Workers < 0 || c.Inbox.Workers < 0 {
+		invalid("workers must not be negative")
+	}
+	return errors.Join(errs...)
+}
+
+// PoolConfig parses DSN and applies the pool sizes.
+func (c DatabaseConfig) PoolConfig() (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(c.DSN)
+	if err != nil {
+		return nil, err
+	}
+	if c.MaxConns > 0 {
+		poolConfig.MaxConns = c.MaxConns
+	}
+	if c.MinConns > 0 {
+		poolConfig.MinConns = c.MinConns
+	}
+	return poolConfig, nil
+}
+
+// Options returns the options of outbox.New.
+func (c OutboxConfig) Options() []outbox.Option {
+	return []outbox.Option{
+		outbox.WithOutboxTable(c.Table),
+		outbox.WithOffsetsTable(c.OffsetsTable),
+		outbox.WithBatchSize(c.BatchSize),
+		outbox.WithRetention(c.Retention),
+	}
+}
+
+// Options returns the options of inbox.New.
+func (c InboxConfig) Options() []inbox.Option {
+	return []inbox.Option{
+		inbox.WithTable(c.Table),
+		inbox.WithSequence(c.Sequence),
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("file overrides defaults and env overrides file", func(t *testing.T) {
+		path := writeFile(t, `
+database:
+  dsn: postgres://localhost/file
+  max_conns: 10
+outbox:
+  table: events
+  poll_interval: 500ms
+  retention: 72h
+`)
+		t.Setenv("ASCETICDDD_DATABASE_DSN", "postgres://localhost/env")
+		t.Setenv("ASCETICDDD_INBOX_WORKERS", "4")
+
+		c, err := Load(path)
+		require.NoError(t, err)
+		assert.Equal(t, "postgres://localhost/env", c.Database.DSN)
+		assert.Equal(t, int32(10), c.Database.MaxConns)
+		assert.Equal(t, "events", c.Outbox.Table)
+		assert.Equal(t, "outbox_offsets", c.Outbox.OffsetsTable)
+		assert.Equal(t, 500*time.Millisecond, c.Outbox.PollInterval)
+		assert.Equal(t, 72*time.Hour, c.Outbox.Retention)
+		assert.Equal(t, 4, c.Inbox.Workers)
+	})
+
+	t.Run("without file", func(t *testing.T) {
+		t.Setenv("ASCETICDDD_DATABASE_DSN", "postgres://localhost/env")
+
+		c, err := Load("")
+		require.NoError(t, err)
+		assert.Equal(t, 100, c.Outbox.BatchSize)
+	})
+
+	t.Run("validates", func(t *testing.T) {
+		_, err := Load(writeFile(t, ""))
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+	})
+
+	t.Run("rejects unknown keys", func(t *testing.T) {
+		_, err := Load(writeFile(t, "outbox:\n  tabel: events\n"))
+		assert.ErrorContains(t, err, "tabel")
+	})
+}
+
+func TestLoadEnv(t *testing.T) {
+	t.Run("uses the prefix", func(t *testing.T) {
+		t.Setenv("APP_OUTBOX_BATCH_SIZE", "25")
+		t.Setenv("APP_OUTBOX_POLL_INTERVAL", "2s")
+
+		c := Default()
+		require.NoError(t, c.LoadEnv("APP_"))
+		assert.Equal(t, 25, c.Outbox.BatchSize)
+		assert.Equal(t, 2*time.Second, c.Outbox.PollInterval)
+	})
+
+	t.Run("reports the variable of an invalid value", func(t *testing.T) {
+		t.Setenv("APP_DATABASE_MAX_CONNS", "many")
+
+		c := Default()
+		err := c.LoadEnv("APP_")
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+		assert.ErrorContains(t, err, "APP_DATABASE_MAX_CONNS")
+	})
+}
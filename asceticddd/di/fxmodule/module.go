@@ -0,0 +1,139 @@
+// Package fxmodule provides the toolkit to go.uber.org/fx applications:
+//
+//	fx.New(
+//		fx.Supply(cfg), // config.Config, e.g. of config.Load
+//		fxmodule.Module,
+//		fx.Provide(fxmodule.AsOutboxSubscription(newProjection)),
+//	)
+//
+// Module provides *pgxpool.Pool, session.SessionPool, outbox.Outbox, inbox.Inbox
+// and saga.ActivityTypeResolver, and runs the outbox and inbox subscriptions while the application runs.
+// The sections of config.Config are provided as well, di.WorkerErrorHandler is optional.
+package fxmodule
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/fx"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/config"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/di"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/inbox"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/outbox"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/saga"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+var Module = fx.Module("asceticddd",
+	fx.Provide(
+		ProvideConfigSections,
+		ProvidePgxPool,
+		di.NewSessionPool,
+		ProvideOutbox,
+		ProvideInbox,
+		fx.Annotate(
+			saga.NewMapBasedResolver,
+			fx.As(fx.Self()),
+			fx.As(new(saga.ActivityTypeResolver)),
+		),
+		ProvideWorkers,
+	),
+	// Workers are started whether or not the application depends on them.
+	fx.Invoke(func(*di.Workers) {}),
+)
+
+type ConfigSections struct {
+	fx.Out
+	Database config.DatabaseConfig
+	Outbox   config.OutboxConfig
+	Inbox    config.InboxConfig
+}
+
+func ProvideConfigSections(cfg config.Config) ConfigSections {
+	return ConfigSections{Database: cfg.Database, Outbox: cfg.Outbox, Inbox: cfg.Inbox}
+}
+
+// ProvidePgxPool creates the pool and closes it when the application stops.
+func ProvidePgxPool(lc fx.Lifecycle, cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
+	pool, err := di.NewPgxPool(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+	lc.Append(fx.StopHook(pool.Close))
+	return pool, nil
+}
+
+type OutboxParams struct {
+	fx.In
+	SessionPool session.SessionPool
+	Config      config.OutboxConfig
+}
+
+type OutboxResult struct {
+	fx.Out
+	PgOutbox *outbox.PgOutbox
+	Outbox   outbox.Outbox
+}
+
+func ProvideOutbox(p OutboxParams) OutboxResult {
+	o := di.NewOutbox(p.SessionPool, p.Config)
+	return OutboxResult{PgOutbox: o, Outbox: o}
+}
+
+type InboxParams struct {
+	fx.In
+	SessionPool session.SessionPool
+	Config      config.InboxConfig
+}
+
+type InboxResult struct {
+	fx.Out
+	PgInbox *inbox.PgInbox
+	Inbox   inbox.Inbox
+}
+
+func ProvideInbox(p InboxParams) InboxResult {
+	i := di.NewInbox(p.SessionPool, p.Config)
+	return InboxResult{PgInbox: i, Inbox: i}
+}
+
+type WorkersParams struct {
+	fx.In
+	Lifecycle fx.Lifecycle
+	Config    config.Config
+	Outbox    outbox.Outbox
+	Inbox     inbox.Inbox
+	// Subscriptions are provided with AsOutboxSubscription and AsInboxSubscription.
+	OutboxSubscriptions []di.OutboxSubscription `group:"outbox_subscriptions"`
+	InboxSubscriptions  []di.InboxSubscription  `group:"inbox_subscriptions"`
+	OnError             di.WorkerErrorHandler   `optional:"true"`
+}
+
+// ProvideWorkers runs the subscriptions from the start until the stop of the application.
+func ProvideWorkers(p WorkersParams) *di.Workers {
+	workers := di.NewWorkers(p.Outbox, p.Inbox, di.Subscriptions{
+		Outbox: p.OutboxSubscriptions,
+		Inbox:  p.InboxSubscriptions,
+	}, p.Config)
+	if p.OnError != nil {
+		workers.SetErrorHandler(p.OnError)
+	}
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			return workers.Start()
+		},
+		OnStop: workers.Stop,
+	})
+	return workers
+}
+
+// AsOutboxSubscription annotates the constructor of di.OutboxSubscription to be run by Module.
+func AsOutboxSubscription(constructor any) any {
+	return fx.Annotate(constructor, fx.ResultTags(`group:"outbox_subscriptions"`))
+}
+
+// AsInboxSubscription annotates the constructor of di.InboxSubscription to be run by Module.
+func AsInboxSubscription(constructor any) any {
+	return fx.Annotate(constructor, fx.ResultTags(`group:"inbox_subscriptions"`))
+}
@@ -0,0 +1,111 @@
+// Package di has the constructors shared by the dependency-injection integrations,
+// fxmodule for go.uber.org/fx and wireset for github.com/google/wire.
+// They are a separate module, so applications of the toolkit do not depend on fx and wire.
+package di
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/config"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/inbox"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/outbox"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	pgsession "github.com/krew-solutions/ascetic-ddd-go/asceticddd/session/pg"
+)
+
+// NewPgxPool creates the pool, connections are established lazily.
+func NewPgxPool(ctx context.Context, cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
+	poolConfig, err := cfg.PoolConfig()
+	if err != nil {
+		return nil, err
+	}
+	return pgxpool.NewWithConfig(ctx, poolConfig)
+}
+
+func NewSessionPool(pool *pgxpool.Pool) session.SessionPool {
+	return pgsession.NewSessionPool(pool)
+}
+
+func NewOutbox(sessionPool session.SessionPool, cfg config.OutboxConfig) *outbox.PgOutbox {
+	return outbox.New(sessionPool, cfg.Options()...)
+}
+
+func NewInbox(sessionPool session.SessionPool, cfg config.InboxConfig) *inbox.PgInbox {
+	return inbox.New(sessionPool, cfg.Options()...)
+}
+
+// OutboxSubscription runs the subscriber with outbox.Outbox.Run while the application runs.
+type OutboxSubscription struct {
+	Subscriber    outbox.Subscriber
+	ConsumerGroup string
+	URI           string
+	// ProcessID and NumProcesses shard the consumer group between processes, 0 means a single process.
+	ProcessID    int
+	NumProcesses int
+	// Concurrency is the number of workers of the process, 0 means config.OutboxConfig.Workers.
+	Concurrency int
+	// PollInterval is in seconds, 0 means config.OutboxConfig.PollInterval.
+	PollInterval float64
+}
+
+// InboxSubscription runs the subscriber with inbox.Inbox.Run while the application runs.
+type InboxSubscription struct {
+	Subscriber   inbox.Subscriber
+	ProcessID    int
+	NumProcesses int
+	Concurrency  int
+	PollInterval float64
+}
+
+// Subscriptions lists the subscribers to run in background.
+type Subscriptions struct {
+	Outbox []OutboxSubscription
+	Inbox  []InboxSubscription
+}
+
+// WorkerErrorHandler handles errors of the loops of Workers, e.g. logs them.
+type WorkerErrorHandler func(error)
+
+// NewWorkers returns the workers running the subscriptions, not started yet.
+// Zero Concurrency and PollInterval of the subscriptions are taken from cfg.
+func NewWorkers(o outbox.Outbox, i inbox.Inbox, subscriptions Subscriptions, cfg config.Config) *Workers {
+	workers := &Workers{}
+	for _, s := range subscriptions.Outbox {
+		numProcesses, concurrency, pollInterval := runDefaults(
+			s.NumProcesses, s.Concurrency, s.PollInterval, cfg.Outbox.Workers, cfg.Outbox.PollInterval,
+		)
+		workers.Add(func(ctx context.Context) error {
+			return o.Run(ctx, s.Subscriber, s.ConsumerGroup, s.URI, s.ProcessID, numProcesses, concurrency, pollInterval)
+		})
+	}
+	for _, s := range subscriptions.Inbox {
+		numProcesses, concurrency, pollInterval := runDefaults(
+			s.NumProcesses, s.Concurrency, s.PollInterval, cfg.Inbox.Workers, cfg.Inbox.PollInterval,
+		)
+		workers.Add(func(ctx context.Context) error {
+			return i.Run(ctx, s.Subscriber, s.ProcessID, numProcesses, concurrency, pollInterval)
+		})
+	}
+	return workers
+}
+
+func runDefaults(
+	numProcesses, concurrency int, pollInterval float64, defaultConcurrency int, defaultPollInterval time.Duration,
+) (int, int, float64) {
+	if numProcesses == 0 {
+		numProcesses = 1
+	}
+	if concurrency == 0 {
+		concurrency = max(defaultConcurrency, 1)
+	}
+	if pollInterval == 0 {
+		pollInterval = defaultPollInterval.Seconds()
+	}
+	if pollInterval <= 0 {
+		pollInterval = 1
+	}
+	return numProcesses, concurrency, pollInterval
+}
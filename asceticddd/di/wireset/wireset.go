@@ -0,0 +1,77 @@
+// Package wireset provides the toolkit to github.com/google/wire injectors:
+//
+//	func InitializeApp(ctx context.Context, cfg config.Config, subscriptions di.Subscriptions) (*App, func(), error) {
+//		wire.Build(wireset.ProviderSet, NewApp)
+//		return nil, nil, nil
+//	}
+//
+// The cleanup function of the injector stops the workers and closes the pool.
+package wireset
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/wire"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/config"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/di"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/inbox"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/outbox"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/saga"
+)
+
+// StopTimeout limits the wait for workers in the cleanup.
+var StopTimeout = 30 * time.Second
+
+// ConfigSet provides the sections of config.Config.
+var ConfigSet = wire.NewSet(wire.FieldsOf(new(config.Config), "Database", "Outbox", "Inbox"))
+
+var PgSet = wire.NewSet(ProvidePgxPool, di.NewSessionPool)
+
+var OutboxSet = wire.NewSet(
+	di.NewOutbox,
+	wire.Bind(new(outbox.Outbox), new(*outbox.PgOutbox)),
+)
+
+var InboxSet = wire.NewSet(
+	di.NewInbox,
+	wire.Bind(new(inbox.Inbox), new(*inbox.PgInbox)),
+)
+
+var SagaSet = wire.NewSet(
+	saga.NewMapBasedResolver,
+	wire.Bind(new(saga.ActivityTypeResolver), new(*saga.MapBasedResolver)),
+)
+
+// WorkersSet requires config.Config and di.Subscriptions.
+var WorkersSet = wire.NewSet(ProvideWorkers)
+
+// ProviderSet requires config.Config and di.Subscriptions.
+var ProviderSet = wire.NewSet(ConfigSet, PgSet, OutboxSet, InboxSet, SagaSet, WorkersSet)
+
+// ProvidePgxPool creates the pool, the cleanup closes it.
+func ProvidePgxPool(ctx context.Context, cfg config.DatabaseConfig) (*pgxpool.Pool, func(), error) {
+	pool, err := di.NewPgxPool(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pool, pool.Close, nil
+}
+
+// ProvideWorkers starts the workers running the subscriptions, the cleanup stops them.
+func ProvideWorkers(
+	o outbox.Outbox, i inbox.Inbox, subscriptions di.Subscriptions, cfg config.Config,
+) (*di.Workers, func(), error) {
+	workers := di.NewWorkers(o, i, subscriptions, cfg)
+	if err := workers.Start(); err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), StopTimeout)
+		defer cancel()
+		_ = workers.Stop(ctx)
+	}
+	return workers, cleanup, nil
+}
@@ -0,0 +1,108 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrWorkersStarted = errors.New("workers are already started")
+
+const defaultRestartDelay = time.Second
+
+// Workers runs background loops, e.g. of outbox and inbox subscriptions, between Start and Stop.
+// A loop returning an error (e.g. the database is unavailable) is restarted after the restart delay.
+type Workers struct {
+	mu           sync.Mutex
+	runs         []func(ctx context.Context) error
+	restartDelay time.Duration
+	onError      func(error)
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+}
+
+// Add adds the loop, it must return when ctx is done.
+func (w *Workers) Add(run func(ctx context.Context) error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.runs = append(w.runs, run)
+}
+
+// SetRestartDelay sets the delay before restarting a failed loop, 0 means 1 second.
+func (w *Workers) SetRestartDelay(delay time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.restartDelay = delay
+}
+
+// SetErrorHandler sets the handler of errors of loops, e.g. to log them.
+func (w *Workers) SetErrorHandler(onError func(error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onError = onError
+}
+
+func (w *Workers) Start() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel != nil {
+		return ErrWorkersStarted
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	delay := w.restartDelay
+	if delay == 0 {
+		delay = defaultRestartDelay
+	}
+	for _, run := range w.runs {
+		w.wg.Add(1)
+		go w.supervise(ctx, run, delay, w.onError)
+	}
+	return nil
+}
+
+// Stop cancels the loops and waits for them to return, or for ctx to be done.
+func (w *Workers) Stop(ctx context.Context) error {
+	w.mu.Lock()
+	cancel := w.cancel
+	w.cancel = nil
+	w.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *Workers) supervise(ctx context.Context, run func(ctx context.Context) error, delay time.Duration, onError func(error)) {
+	defer w.wg.Done()
+	for {
+		// Goroutines the run leaves behind on its context are canceled before it is restarted.
+		runCtx, cancel := context.WithCancel(ctx)
+		err := run(runCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
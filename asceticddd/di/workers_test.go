@@ -0,0 +1,95 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkers(t *testing.T) {
+	t.Run("runs until stopped", func(t *testing.T) {
+		workers := &Workers{}
+		started := make(chan struct{})
+		var stopped atomic.Bool
+		workers.Add(func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			stopped.Store(true)
+			return ctx.Err()
+		})
+
+		assert.NoError(t, workers.Start())
+		<-started
+		assert.NoError(t, workers.Stop(context.Background()))
+		assert.True(t, stopped.Load())
+	})
+
+	t.Run("restarts failed loop", func(t *testing.T) {
+		workers := &Workers{}
+		workers.SetRestartDelay(time.Millisecond)
+		var errs atomic.Int32
+		workers.SetErrorHandler(func(err error) {
+			errs.Add(1)
+		})
+		var runs atomic.Int32
+		recovered := make(chan struct{})
+		workers.Add(func(ctx context.Context) error {
+			if runs.Add(1) < 3 {
+				return errors.New("connection refused")
+			}
+			close(recovered)
+			<-ctx.Done()
+			return nil
+		})
+
+		assert.NoError(t, workers.Start())
+		<-recovered
+		assert.NoError(t, workers.Stop(context.Background()))
+		assert.Equal(t, int32(3), runs.Load())
+		assert.Equal(t, int32(2), errs.Load())
+	})
+
+	t.Run("cancels context of returned loop", func(t *testing.T) {
+		workers := &Workers{}
+		workers.SetRestartDelay(time.Hour)
+		leaked := make(chan error)
+		workers.Add(func(ctx context.Context) error {
+			go func() {
+				<-ctx.Done()
+				leaked <- ctx.Err()
+			}()
+			return errors.New("worker failed")
+		})
+
+		assert.NoError(t, workers.Start())
+		assert.ErrorIs(t, <-leaked, context.Canceled)
+		assert.NoError(t, workers.Stop(context.Background()))
+	})
+
+	t.Run("start twice", func(t *testing.T) {
+		workers := &Workers{}
+		assert.NoError(t, workers.Start())
+		assert.ErrorIs(t, workers.Start(), ErrWorkersStarted)
+		assert.NoError(t, workers.Stop(context.Background()))
+		assert.NoError(t, workers.Stop(context.Background()))
+	})
+
+	t.Run("stop timeout", func(t *testing.T) {
+		workers := &Workers{}
+		release := make(chan struct{})
+		defer close(release)
+		workers.Add(func(ctx context.Context) error {
+			<-release
+			return nil
+		})
+		assert.NoError(t, workers.Start())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, workers.Stop(ctx), context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,215 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type AggregateFunc string
+
+const (
+	AggregateCount AggregateFunc = "count"
+	AggregateSum   AggregateFunc = "sum"
+)
+
+// Aggregate computes a value over the states of a group.
+// Path is empty for count.
+type Aggregate struct {
+	Func AggregateFunc
+	Path string
+}
+
+// Name is the key of the aggregate in AggregateRow.Values, e.g. "count" or "sum(amount)".
+func (a Aggregate) Name() string {
+	if a.Path == "" {
+		return string(a.Func)
+	}
+	return fmt.Sprintf("%s(%s)", a.Func, a.Path)
+}
+
+// Aggregation groups the states matching Query by the text values of GroupBy paths,
+// the same way as GROUP BY value->>'path' in SQL:
+//
+//	GroupBy("status").Where(query).Count().Sum("amount")
+//
+// Missing paths and JSON nulls form the nil group.
+type Aggregation struct {
+	GroupBy    []string
+	Query      IQueryOperator
+	Aggregates []Aggregate
+}
+
+func GroupBy(paths ...string) Aggregation {
+	return Aggregation{GroupBy: paths}
+}
+
+func (a Aggregation) Where(query IQueryOperator) Aggregation {
+	a.Query = query
+	return a
+}
+
+func (a Aggregation) Count() Aggregation {
+	return a.with(Aggregate{Func: AggregateCount})
+}
+
+// Sum sums number values of the path. Missing values, numeric strings and other values are skipped.
+func (a Aggregation) Sum(path string) Aggregation {
+	return a.with(Aggregate{Func: AggregateSum, Path: path})
+}
+
+func (a Aggregation) with(aggregate Aggregate) Aggregation {
+	aggregates := make([]Aggregate, len(a.Aggregates), len(a.Aggregates)+1)
+	copy(aggregates, a.Aggregates)
+	a.Aggregates = append(aggregates, aggregate)
+	return a
+}
+
+func (a Aggregation) Validate() error {
+	if len(a.GroupBy) == 0 {
+		return fmt.Errorf("aggregation requires at least one group by path")
+	}
+	for _, path := range a.GroupBy {
+		if path == "" {
+			return fmt.Errorf("group by path must not be empty")
+		}
+	}
+	if len(a.Aggregates) == 0 {
+		return fmt.Errorf("aggregation requires at least one aggregate")
+	}
+	for _, aggregate := range a.Aggregates {
+		switch aggregate.Func {
+		case AggregateCount:
+		case AggregateSum:
+			if aggregate.Path == "" {
+				return fmt.Errorf("sum requires a path")
+			}
+		default:
+			return fmt.Errorf("unknown aggregate function %q", aggregate.Func)
+		}
+	}
+	return nil
+}
+
+// AggregateRow is a group: Key holds the text values of GroupBy paths, nil for the nil group.
+// Values holds int for count and float64 for sum, nil for sum without numeric values.
+type AggregateRow struct {
+	Key    []any
+	Values map[string]any
+}
+
+// Apply aggregates the states, which are expected to match Query already.
+// Rows are ordered by Key ascending, nil last.
+func (a Aggregation) Apply(states []map[string]any) ([]AggregateRow, error) {
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	type group struct {
+		key    []any
+		counts map[string]int
+		sums   map[string]*float64
+	}
+	groups := map[string]*group{}
+	var order []*group
+	for _, state := range states {
+		key := make([]any, len(a.GroupBy))
+		for i, path := range a.GroupBy {
+			value, _ := lookupPath(state, SplitPath(path))
+			key[i] = jsonText(value)
+		}
+		groupKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		g, ok := groups[string(groupKey)]
+		if !ok {
+			g = &group{key: key, counts: map[string]int{}, sums: map[string]*float64{}}
+			groups[string(groupKey)] = g
+			order = append(order, g)
+		}
+		for _, aggregate := range a.Aggregates {
+			name := aggregate.Name()
+			switch aggregate.Func {
+			case AggregateCount:
+				g.counts[name]++
+			case AggregateSum:
+				value, _ := lookupPath(state, SplitPath(aggregate.Path))
+				if n, ok := numberValue(value); ok {
+					if g.sums[name] == nil {
+						g.sums[name] = new(float64)
+					}
+					*g.sums[name] += n
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return compareKeys(order[i].key, order[j].key) < 0
+	})
+	rows := make([]AggregateRow, len(order))
+	for i, g := range order {
+		values := make(map[string]any, len(a.Aggregates))
+		for _, aggregate := range a.Aggregates {
+			name := aggregate.Name()
+			switch aggregate.Func {
+			case AggregateCount:
+				values[name] = g.counts[name]
+			case AggregateSum:
+				if sum := g.sums[name]; sum != nil {
+					values[name] = *sum
+				} else {
+					values[name] = nil
+				}
+			}
+		}
+		rows[i] = AggregateRow{Key: g.key, Values: values}
+	}
+	return rows, nil
+}
+
+// numberValue accepts JSON numbers only, like jsonb_typeof(value) = 'number'.
+func numberValue(value any) (float64, bool) {
+	if _, ok := value.(string); ok {
+		return 0, false
+	}
+	return toFloat64(value)
+}
+
+// jsonText returns the value as the ->> operator does: strings as is,
+// other values as JSON text, nil for missing values and JSON null.
+func jsonText(value any) any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return v
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func compareKeys(a, b []any) int {
+	for i := range a {
+		if c := compareNullableText(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareNullableText(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return strings.Compare(a.(string), b.(string))
+}
@@ -0,0 +1,82 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregationBuilder(t *testing.T) {
+	query := CompositeQuery{Fields: map[string]IQueryOperator{"status": EqOperator{Value: "active"}}}
+	base := GroupBy("customer_id").Where(query)
+	counted := base.Count()
+	summed := counted.Sum("amount")
+
+	assert.Equal(t, []string{"customer_id"}, summed.GroupBy)
+	assert.Equal(t, query, summed.Query)
+	assert.Equal(t, []Aggregate{{Func: AggregateCount}, {Func: AggregateSum, Path: "amount"}}, summed.Aggregates)
+	assert.Len(t, counted.Aggregates, 1, "builder steps must not share aggregates")
+	assert.Empty(t, base.Aggregates)
+
+	assert.Equal(t, "count", Aggregate{Func: AggregateCount}.Name())
+	assert.Equal(t, "sum(order.amount)", Aggregate{Func: AggregateSum, Path: "order.amount"}.Name())
+}
+
+func TestAggregationValidate(t *testing.T) {
+	assert.NoError(t, GroupBy("status").Count().Validate())
+	assert.Error(t, GroupBy().Count().Validate())
+	assert.Error(t, GroupBy("").Count().Validate())
+	assert.Error(t, GroupBy("status").Validate())
+	assert.Error(t, GroupBy("status").Sum("").Validate())
+	assert.Error(t, Aggregation{GroupBy: []string{"status"}, Aggregates: []Aggregate{{Func: "avg"}}}.Validate())
+}
+
+func TestAggregationApply(t *testing.T) {
+	states := []map[string]any{
+		{"status": "closed", "amount": 5},
+		{"status": "active", "amount": 10},
+		{"status": "active", "amount": 2.5},
+		{"status": "active", "amount": "7"},
+		{"amount": 1},
+		{"status": nil, "amount": nil},
+		{"status": 42},
+	}
+
+	t.Run("count and sum", func(t *testing.T) {
+		rows, err := GroupBy("status").Count().Sum("amount").Apply(states)
+		require.NoError(t, err)
+		assert.Equal(t, []AggregateRow{
+			{Key: []any{"42"}, Values: map[string]any{"count": 1, "sum(amount)": nil}},
+			{Key: []any{"active"}, Values: map[string]any{"count": 3, "sum(amount)": 12.5}},
+			{Key: []any{"closed"}, Values: map[string]any{"count": 1, "sum(amount)": 5.0}},
+			{Key: []any{nil}, Values: map[string]any{"count": 2, "sum(amount)": 1.0}},
+		}, rows)
+	})
+
+	t.Run("nested and multiple keys", func(t *testing.T) {
+		rows, err := GroupBy("customer.id", "status").Count().Apply([]map[string]any{
+			{"customer": map[string]any{"id": "c1"}, "status": "active"},
+			{"customer": map[string]any{"id": "c1"}, "status": "active"},
+			{"customer": map[string]any{"id": "c1"}, "status": "closed"},
+			{"customer": map[string]any{"id": "c2"}, "status": "active"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []AggregateRow{
+			{Key: []any{"c1", "active"}, Values: map[string]any{"count": 2}},
+			{Key: []any{"c1", "closed"}, Values: map[string]any{"count": 1}},
+			{Key: []any{"c2", "active"}, Values: map[string]any{"count": 1}},
+		}, rows)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		rows, err := GroupBy("status").Count().Apply(nil)
+		require.NoError(t, err)
+		assert.Empty(t, rows)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := GroupBy("status").Apply(states)
+		assert.Error(t, err)
+	})
+}
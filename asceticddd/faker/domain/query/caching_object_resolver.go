@@ -0,0 +1,183 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session/identitymap"
+)
+
+// IBatchObjectResolver resolves many foreign keys of the same relation with a single lookup.
+type IBatchObjectResolver interface {
+	IObjectResolver
+	// ResolveMany returns foreign states keyed by the given fk values. Missing keys are omitted.
+	// The returned resolver is the nested resolver shared by all found states.
+	ResolveMany(s session.Session, field *string, fkValues []any) (map[any]map[string]any, IObjectResolver, error)
+}
+
+type identityMapSession interface {
+	IdentityMap() *identitymap.IdentityMap
+}
+
+type resolvedObject struct {
+	state    map[string]any
+	resolver IObjectResolver
+}
+
+type resolvedObjectKey struct {
+	identitymap.IdentityKeyBase[*resolvedObject]
+	scope   string
+	fkValue any
+}
+
+// CachingObjectResolver decorates IObjectResolver with per-session result caching.
+//
+// Resolved states are stored in the identity map of the session,
+// so the cache lives exactly as long as the session scope
+// and obeys its isolation level. Sessions without an identity map are not cached.
+// Accesses to the identity map are serialized, so the resolver is safe for concurrent use
+// if the delegate is, see IConcurrentObjectResolver.
+type CachingObjectResolver struct {
+	delegate IObjectResolver
+	scope    string
+	// mu is shared by the descended and nested resolvers, which use the same identity maps.
+	mu *sync.Mutex
+}
+
+func NewCachingObjectResolver(delegate IObjectResolver) *CachingObjectResolver {
+	return &CachingObjectResolver{delegate: delegate, mu: &sync.Mutex{}}
+}
+
+// IsConcurrencySafe reports whether the delegate is safe for concurrent use.
+func (r *CachingObjectResolver) IsConcurrencySafe() bool {
+	return isConcurrencySafe(r.delegate)
+}
+
+func (r *CachingObjectResolver) Resolve(s session.Session, field *string, fkValue any) (map[string]any, IObjectResolver, error) {
+	return r.ResolveContext(sessionContext(s), s, field, fkValue)
+}
+
+// ResolveContext resolves with the delegate taking the context, if it implements IContextObjectResolver.
+func (r *CachingObjectResolver) ResolveContext(
+	ctx context.Context,
+	s session.Session,
+	field *string,
+	fkValue any,
+) (map[string]any, IObjectResolver, error) {
+	key := r.key(field, fkValue)
+	im := extractIdentityMap(s)
+	if !isCacheable(fkValue) {
+		im = nil
+	}
+	if im != nil {
+		r.mu.Lock()
+		cached, err := identitymap.Get(im, key)
+		r.mu.Unlock()
+		if err == nil {
+			return cached.state, r.wrap(field, cached.resolver), nil
+		}
+		if errors.Is(err, identitymap.ErrObjectNotFound) {
+			return nil, nil, nil
+		}
+	}
+	state, nestedResolver, err := resolveObject(ctx, r.delegate, s, field, fkValue)
+	if err != nil {
+		return nil, nil, err
+	}
+	if im != nil {
+		r.store(im, key, state, nestedResolver)
+	}
+	return state, r.wrap(field, nestedResolver), nil
+}
+
+func (r *CachingObjectResolver) Descend(field string) IObjectResolver {
+	descended := r.delegate.Descend(field)
+	if descended == nil {
+		return nil
+	}
+	return &CachingObjectResolver{delegate: descended, scope: r.scope + "/" + field, mu: r.mu}
+}
+
+// Prefetch loads the given fk values of a relation in one batch and caches them,
+// so subsequent Resolve calls during evaluation hit the session cache.
+// Does nothing if the delegate does not implement IBatchObjectResolver
+// or the session has no identity map.
+func (r *CachingObjectResolver) Prefetch(s session.Session, field *string, fkValues []any) error {
+	batch, ok := r.delegate.(IBatchObjectResolver)
+	if !ok {
+		return nil
+	}
+	im := extractIdentityMap(s)
+	if im == nil {
+		return nil
+	}
+	var missing []any
+	r.mu.Lock()
+	for _, fkValue := range fkValues {
+		if !isCacheable(fkValue) {
+			continue
+		}
+		if !identitymap.Has(im, r.key(field, fkValue)) {
+			missing = append(missing, fkValue)
+		}
+	}
+	r.mu.Unlock()
+	if len(missing) == 0 {
+		return nil
+	}
+	states, nestedResolver, err := batch.ResolveMany(s, field, missing)
+	if err != nil {
+		return err
+	}
+	for _, fkValue := range missing {
+		r.store(im, r.key(field, fkValue), states[fkValue], nestedResolver)
+	}
+	return nil
+}
+
+func (r *CachingObjectResolver) key(field *string, fkValue any) resolvedObjectKey {
+	return resolvedObjectKey{scope: r.relationScope(field), fkValue: fkValue}
+}
+
+func (r *CachingObjectResolver) relationScope(field *string) string {
+	if field == nil {
+		return r.scope + "->"
+	}
+	return r.scope + "/" + *field + "->"
+}
+
+func (r *CachingObjectResolver) store(im *identitymap.IdentityMap, key resolvedObjectKey, state map[string]any, nestedResolver IObjectResolver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if state == nil {
+		identitymap.AddAbsent(im, key)
+		return
+	}
+	identitymap.Add(im, key, &resolvedObject{state: state, resolver: nestedResolver})
+}
+
+func (r *CachingObjectResolver) wrap(field *string, nestedResolver IObjectResolver) IObjectResolver {
+	if nestedResolver == nil {
+		return nil
+	}
+	return &CachingObjectResolver{delegate: nestedResolver, scope: r.relationScope(field), mu: r.mu}
+}
+
+func extractIdentityMap(s session.Session) *identitymap.IdentityMap {
+	ims, ok := s.(identityMapSession)
+	if !ok {
+		return nil
+	}
+	return ims.IdentityMap()
+}
+
+// isCacheable reports whether fkValue can be used as a part of the identity map key.
+func isCacheable(fkValue any) bool {
+	if fkValue == nil {
+		return false
+	}
+	return reflect.TypeOf(fkValue).Comparable()
+}
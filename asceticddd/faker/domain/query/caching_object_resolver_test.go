@@ -0,0 +1,226 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session/identitymap"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/signals"
+)
+
+type identityMapSessionStub struct {
+	identityMap *identitymap.IdentityMap
+}
+
+func newIdentityMapSessionStub(level identitymap.IsolationLevel) *identityMapSessionStub {
+	return &identityMapSessionStub{identityMap: identitymap.New(100, level)}
+}
+
+func (s *identityMapSessionStub) Context() context.Context                { return context.Background() }
+func (s *identityMapSessionStub) Atomic(cb session.SessionCallback) error { return cb(s) }
+func (s *identityMapSessionStub) OnAtomicStarted() signals.Signal[session.SessionScopeStartedEvent] {
+	return nil
+}
+func (s *identityMapSessionStub) OnAtomicEnded() signals.Signal[session.SessionScopeEndedEvent] {
+	return nil
+}
+func (s *identityMapSessionStub) IdentityMap() *identitymap.IdentityMap { return s.identityMap }
+
+type countingObjectResolver struct {
+	storage       map[string]map[any]map[string]any
+	nested        map[string]IObjectResolver
+	children      map[string]IObjectResolver
+	resolveCalls  int
+	resolveMany   int
+	requestedMany []any
+}
+
+func (r *countingObjectResolver) Resolve(s session.Session, field *string, fkValue any) (map[string]any, IObjectResolver, error) {
+	r.resolveCalls++
+	key := ""
+	if field != nil {
+		key = *field
+	}
+	state, ok := r.storage[key][fkValue]
+	if !ok {
+		return nil, nil, nil
+	}
+	return state, r.nested[key], nil
+}
+
+func (r *countingObjectResolver) ResolveMany(s session.Session, field *string, fkValues []any) (map[any]map[string]any, IObjectResolver, error) {
+	r.resolveMany++
+	r.requestedMany = append(r.requestedMany, fkValues...)
+	key := ""
+	if field != nil {
+		key = *field
+	}
+	result := map[any]map[string]any{}
+	for _, fkValue := range fkValues {
+		if state, ok := r.storage[key][fkValue]; ok {
+			result[fkValue] = state
+		}
+	}
+	return result, r.nested[key], nil
+}
+
+func (r *countingObjectResolver) Descend(field string) IObjectResolver {
+	child, ok := r.children[field]
+	if !ok {
+		return nil
+	}
+	return child
+}
+
+func TestCachingObjectResolver(t *testing.T) {
+	companyField := "company_id"
+
+	newDelegate := func() *countingObjectResolver {
+		return &countingObjectResolver{
+			storage: map[string]map[any]map[string]any{
+				"company_id": {
+					1: {"id": 1, "name": "Acme"},
+					2: {"id": 2, "name": "Globex"},
+				},
+			},
+		}
+	}
+
+	t.Run("caches found object within session", func(t *testing.T) {
+		delegate := newDelegate()
+		resolver := NewCachingObjectResolver(delegate)
+		s := newIdentityMapSessionStub(identitymap.Serializable)
+
+		state, _, err := resolver.Resolve(s, &companyField, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Acme", state["name"])
+
+		state, _, err = resolver.Resolve(s, &companyField, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Acme", state["name"])
+		assert.Equal(t, 1, delegate.resolveCalls)
+	})
+
+	t.Run("caches absent object within session", func(t *testing.T) {
+		delegate := newDelegate()
+		resolver := NewCachingObjectResolver(delegate)
+		s := newIdentityMapSessionStub(identitymap.Serializable)
+
+		state, _, err := resolver.Resolve(s, &companyField, 99)
+		require.NoError(t, err)
+		assert.Nil(t, state)
+
+		state, _, err = resolver.Resolve(s, &companyField, 99)
+		require.NoError(t, err)
+		assert.Nil(t, state)
+		assert.Equal(t, 1, delegate.resolveCalls)
+	})
+
+	t.Run("does not share cache between sessions", func(t *testing.T) {
+		delegate := newDelegate()
+		resolver := NewCachingObjectResolver(delegate)
+
+		_, _, err := resolver.Resolve(newIdentityMapSessionStub(identitymap.Serializable), &companyField, 1)
+		require.NoError(t, err)
+		_, _, err = resolver.Resolve(newIdentityMapSessionStub(identitymap.Serializable), &companyField, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 2, delegate.resolveCalls)
+	})
+
+	t.Run("obeys disabled identity map", func(t *testing.T) {
+		delegate := newDelegate()
+		resolver := NewCachingObjectResolver(delegate)
+		s := newIdentityMapSessionStub(identitymap.ReadUncommitted)
+
+		_, _, err := resolver.Resolve(s, &companyField, 1)
+		require.NoError(t, err)
+		_, _, err = resolver.Resolve(s, &companyField, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 2, delegate.resolveCalls)
+	})
+
+	t.Run("session without identity map is not cached", func(t *testing.T) {
+		delegate := newDelegate()
+		resolver := NewCachingObjectResolver(delegate)
+
+		_, _, err := resolver.Resolve(sess, &companyField, 1)
+		require.NoError(t, err)
+		_, _, err = resolver.Resolve(sess, &companyField, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 2, delegate.resolveCalls)
+	})
+
+	t.Run("prefetch batches missing keys", func(t *testing.T) {
+		delegate := newDelegate()
+		resolver := NewCachingObjectResolver(delegate)
+		s := newIdentityMapSessionStub(identitymap.Serializable)
+
+		_, _, err := resolver.Resolve(s, &companyField, 1)
+		require.NoError(t, err)
+
+		err = resolver.Prefetch(s, &companyField, []any{1, 2, 3})
+		require.NoError(t, err)
+		assert.Equal(t, 1, delegate.resolveMany)
+		assert.Equal(t, []any{2, 3}, delegate.requestedMany)
+
+		state, _, err := resolver.Resolve(s, &companyField, 2)
+		require.NoError(t, err)
+		assert.Equal(t, "Globex", state["name"])
+		state, _, err = resolver.Resolve(s, &companyField, 3)
+		require.NoError(t, err)
+		assert.Nil(t, state)
+		assert.Equal(t, 1, delegate.resolveCalls)
+	})
+
+	t.Run("nested resolvers are cached separately", func(t *testing.T) {
+		countryResolver := &countingObjectResolver{
+			storage: map[string]map[any]map[string]any{
+				"country_id": {1: {"id": 1, "code": "US"}},
+			},
+		}
+		delegate := &countingObjectResolver{
+			storage: map[string]map[any]map[string]any{
+				"company_id": {1: {"id": 1, "country_id": 1}},
+			},
+			nested: map[string]IObjectResolver{"company_id": countryResolver},
+		}
+		resolver := NewCachingObjectResolver(delegate)
+		s := newIdentityMapSessionStub(identitymap.Serializable)
+
+		walker := NewEvaluateWalker(resolver)
+		query := CompositeQuery{Fields: map[string]IQueryOperator{
+			"company_id": RelOperator{Query: CompositeQuery{Fields: map[string]IQueryOperator{
+				"country_id": RelOperator{Query: CompositeQuery{Fields: map[string]IQueryOperator{
+					"code": EqOperator{Value: "US"},
+				}}},
+			}}},
+		}}
+		for range 2 {
+			result, err := walker.Evaluate(s, query, map[string]any{"company_id": 1})
+			require.NoError(t, err)
+			assert.True(t, result)
+		}
+		assert.Equal(t, 1, delegate.resolveCalls)
+		assert.Equal(t, 1, countryResolver.resolveCalls)
+	})
+
+	t.Run("descend scopes cache", func(t *testing.T) {
+		child := newDelegate()
+		delegate := newDelegate()
+		delegate.children = map[string]IObjectResolver{"profile": child}
+		resolver := NewCachingObjectResolver(delegate)
+		s := newIdentityMapSessionStub(identitymap.Serializable)
+
+		_, _, err := resolver.Resolve(s, &companyField, 1)
+		require.NoError(t, err)
+		_, _, err = resolver.Descend("profile").Resolve(s, &companyField, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, delegate.resolveCalls)
+		assert.Equal(t, 1, child.resolveCalls)
+		assert.Nil(t, resolver.Descend("unknown"))
+	})
+}
@@ -0,0 +1,61 @@
+package query
+
+// conjoin combines the conditions on fields into one CompositeQuery,
+// several conditions on the same field are combined with AndOperator.
+func conjoin(operands []IQueryOperator) IQueryOperator {
+	if len(operands) == 1 {
+		return operands[0]
+	}
+	fields := map[string]IQueryOperator{}
+	var rest []IQueryOperator
+	for _, op := range operands {
+		if query, ok := op.(CompositeQuery); ok {
+			conjoinFields(fields, query.Fields)
+		} else {
+			rest = append(rest, op)
+		}
+	}
+	if len(fields) > 0 {
+		rest = append([]IQueryOperator{CompositeQuery{Fields: fields}}, rest...)
+	}
+	if len(rest) == 1 {
+		return rest[0]
+	}
+	return AndOperator{Operands: rest}
+}
+
+func conjoinFields(dst, src map[string]IQueryOperator) {
+	for field, op := range src {
+		existing, ok := dst[field]
+		if !ok {
+			dst[field] = op
+			continue
+		}
+		dst[field] = conjoinField(existing, op)
+	}
+}
+
+func conjoinField(existing, op IQueryOperator) IQueryOperator {
+	switch e := existing.(type) {
+	case CompositeQuery:
+		if q, ok := op.(CompositeQuery); ok {
+			return CompositeQuery{Fields: mergedFields(e.Fields, q.Fields)}
+		}
+	case RelOperator:
+		if r, ok := op.(RelOperator); ok {
+			return RelOperator{Query: CompositeQuery{Fields: mergedFields(e.Query.Fields, r.Query.Fields)}}
+		}
+	case AndOperator:
+		return AndOperator{Operands: append(e.Operands[:len(e.Operands):len(e.Operands)], op)}
+	}
+	return AndOperator{Operands: []IQueryOperator{existing, op}}
+}
+
+func mergedFields(a, b map[string]IQueryOperator) map[string]IQueryOperator {
+	fields := make(map[string]IQueryOperator, len(a)+len(b))
+	for field, op := range a {
+		fields[field] = op
+	}
+	conjoinFields(fields, b)
+	return fields
+}
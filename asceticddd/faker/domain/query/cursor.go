@@ -0,0 +1,47 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Cursor points to the last row of a keyset page:
+// the values of its OrderBy paths and its primary key as tie-breaker.
+type Cursor struct {
+	Values []any `json:"v"`
+	Pk     any   `json:"pk"`
+}
+
+// NewCursor builds a cursor for the state using OrderBy paths.
+// Missing paths are stored as nil.
+func NewCursor(orderBy []OrderClause, state map[string]any, pk any) Cursor {
+	values := make([]any, len(orderBy))
+	for i, clause := range orderBy {
+		values[i], _ = lookupPath(state, SplitPath(clause.Path))
+	}
+	return Cursor{Values: values, Pk: pk}
+}
+
+// EncodeCursor encodes the cursor to an opaque URL-safe string.
+func EncodeCursor(cursor Cursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor decodes a string produced by EncodeCursor.
+// Numbers are decoded as float64, as with any JSON value.
+func DecodeCursor(token string) (Cursor, error) {
+	var cursor Cursor
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return cursor, nil
+}
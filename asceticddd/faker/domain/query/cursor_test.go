@@ -0,0 +1,38 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	orderBy := []OrderClause{{Path: "address.city"}, {Path: "age", Direction: Desc}, {Path: "missing"}}
+	state := map[string]any{
+		"age":     30,
+		"address": map[string]any{"city": "Moscow"},
+	}
+
+	t.Run("new cursor", func(t *testing.T) {
+		cursor := NewCursor(orderBy, state, 7)
+		assert.Equal(t, Cursor{Values: []any{"Moscow", 30, nil}, Pk: 7}, cursor)
+	})
+
+	t.Run("encode decode", func(t *testing.T) {
+		token, err := EncodeCursor(NewCursor(orderBy, state, 7))
+		require.NoError(t, err)
+		assert.NotContains(t, token, "=")
+
+		cursor, err := DecodeCursor(token)
+		require.NoError(t, err)
+		assert.Equal(t, Cursor{Values: []any{"Moscow", float64(30), nil}, Pk: float64(7)}, cursor)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		_, err := DecodeCursor("not a cursor")
+		assert.Error(t, err)
+		_, err = DecodeCursor("bm90IGpzb24")
+		assert.Error(t, err)
+	})
+}
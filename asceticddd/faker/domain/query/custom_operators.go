@@ -0,0 +1,119 @@
+package query
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ICustomOperator is an operator added by a third party, e.g. geo $near.
+// Its Accept should delegate to AcceptCustom:
+//
+//	func (o NearOperator) Name() string { return "$near" }
+//
+//	func (o NearOperator) Accept(visitor IQueryVisitor) (any, error) {
+//		return AcceptCustom(o, visitor)
+//	}
+//
+// QueryParser, EvaluateWalker, EvaluateVisitor and compilers find the parser, the evaluation func
+// and the SQL emitters of the operator by its name in CustomOperatorRegistry.
+type ICustomOperator interface {
+	IQueryOperator
+	Name() string
+}
+
+// ICustomOperatorVisitor is implemented by visitors supporting custom operators.
+type ICustomOperatorVisitor interface {
+	VisitCustom(op ICustomOperator) (any, error)
+}
+
+// AcceptCustom visits the custom operator, visitors not supporting custom operators fail with unknown operator.
+func AcceptCustom(op ICustomOperator, visitor IQueryVisitor) (any, error) {
+	if v, ok := visitor.(ICustomOperatorVisitor); ok {
+		return v.VisitCustom(op)
+	}
+	return nil, fmt.Errorf("unknown operator: %s", op.Name())
+}
+
+// CustomOperatorParser parses the value of the operator key, e.g. {"$near": value}.
+type CustomOperatorParser func(parser QueryParser, value any) (ICustomOperator, error)
+
+// CustomOperatorEvaluator reports whether the state, e.g. the value of the field, matches the operator.
+type CustomOperatorEvaluator func(op ICustomOperator, state any) (bool, error)
+
+// CustomOperatorRegistry registers custom operators by name.
+// Each compiler looks up its SQL emitter by its own compiler name, e.g. query.PgCompilerName,
+// the type of the emitter is defined by the compiler.
+// The registry is safe for concurrent use.
+type CustomOperatorRegistry struct {
+	mu         sync.RWMutex
+	parsers    map[string]CustomOperatorParser
+	evaluators map[string]CustomOperatorEvaluator
+	emitters   map[customEmitterKey]any
+}
+
+type customEmitterKey struct {
+	name     string
+	compiler string
+}
+
+func NewCustomOperatorRegistry() *CustomOperatorRegistry {
+	return &CustomOperatorRegistry{
+		parsers:    map[string]CustomOperatorParser{},
+		evaluators: map[string]CustomOperatorEvaluator{},
+		emitters:   map[customEmitterKey]any{},
+	}
+}
+
+// Register registers the parser and the evaluation func of the operator, e.g. "$near".
+func (r *CustomOperatorRegistry) Register(name string, parse CustomOperatorParser, evaluate CustomOperatorEvaluator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.parsers[name] = parse
+	r.evaluators[name] = evaluate
+}
+
+// RegisterEmitter registers the SQL emitter of the operator for the compiler.
+func (r *CustomOperatorRegistry) RegisterEmitter(name string, compiler string, emitter any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.emitters[customEmitterKey{name: name, compiler: compiler}] = emitter
+}
+
+func (r *CustomOperatorRegistry) Parser(name string) (CustomOperatorParser, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	parse, ok := r.parsers[name]
+	return parse, ok
+}
+
+func (r *CustomOperatorRegistry) Evaluator(name string) (CustomOperatorEvaluator, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	evaluate, ok := r.evaluators[name]
+	return evaluate, ok
+}
+
+func (r *CustomOperatorRegistry) Emitter(name string, compiler string) (any, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	emitter, ok := r.emitters[customEmitterKey{name: name, compiler: compiler}]
+	return emitter, ok
+}
+
+// Evaluate evaluates the custom operator against the state, failing with unknown operator if it is not registered.
+func (r *CustomOperatorRegistry) Evaluate(op ICustomOperator, state any) (bool, error) {
+	evaluate, ok := r.Evaluator(op.Name())
+	if !ok {
+		return false, fmt.Errorf("unknown operator: %s", op.Name())
+	}
+	return evaluate(op, state)
+}
@@ -0,0 +1,108 @@
+package query
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// divisibleByOperator is a custom operator: {"$divisible_by": 3}
+type divisibleByOperator struct {
+	Divisor int
+}
+
+func (o divisibleByOperator) Name() string {
+	return "$divisible_by"
+}
+
+func (o divisibleByOperator) Accept(visitor IQueryVisitor) (any, error) {
+	return AcceptCustom(o, visitor)
+}
+
+func (o divisibleByOperator) Equal(other IQueryOperator) bool {
+	return o == other
+}
+
+func (o divisibleByOperator) Merge(other IQueryOperator) (IQueryOperator, error) {
+	return nil, ErrUnsupportedMerge
+}
+
+func newDivisibleByRegistry() *CustomOperatorRegistry {
+	registry := NewCustomOperatorRegistry()
+	registry.Register(
+		"$divisible_by",
+		func(parser QueryParser, value any) (ICustomOperator, error) {
+			divisor, ok := value.(int)
+			if !ok || divisor == 0 {
+				return nil, fmt.Errorf("$divisible_by value must be non-zero int, got: %v", value)
+			}
+			return divisibleByOperator{Divisor: divisor}, nil
+		},
+		func(op ICustomOperator, state any) (bool, error) {
+			number, ok := state.(int)
+			return ok && number%op.(divisibleByOperator).Divisor == 0, nil
+		},
+	)
+	return registry
+}
+
+func TestCustomOperators(t *testing.T) {
+	registry := newDivisibleByRegistry()
+	parser := QueryParser{CustomOperators: registry}
+
+	t.Run("parse", func(t *testing.T) {
+		result, err := parser.Parse(map[string]any{"quantity": map[string]any{"$divisible_by": 3}})
+		require.NoError(t, err)
+		assert.Equal(t, CompositeQuery{Fields: map[string]IQueryOperator{
+			"quantity": divisibleByOperator{Divisor: 3},
+		}}, result)
+
+		_, err = parser.Parse(map[string]any{"quantity": map[string]any{"$divisible_by": 0}})
+		assert.Error(t, err)
+		_, err = QueryParser{}.Parse(map[string]any{"quantity": map[string]any{"$divisible_by": 3}})
+		assert.EqualError(t, err, "unknown operator: $divisible_by")
+	})
+
+	t.Run("evaluate walker", func(t *testing.T) {
+		query := CompositeQuery{Fields: map[string]IQueryOperator{
+			"quantity": AndOperator{Operands: []IQueryOperator{
+				divisibleByOperator{Divisor: 3},
+				ComparisonOperator{Op: "$gt", Value: 5},
+			}},
+		}}
+		walker := NewEvaluateWalker(nil)
+		walker.SetCustomOperators(registry)
+
+		result, err := walker.EvaluateSync(query, map[string]any{"quantity": 9})
+		require.NoError(t, err)
+		assert.True(t, result)
+		result, err = walker.Evaluate(&mockSession{}, query, map[string]any{"quantity": 10})
+		require.NoError(t, err)
+		assert.False(t, result)
+
+		_, err = NewEvaluateWalker(nil).EvaluateSync(query, map[string]any{"quantity": 9})
+		assert.EqualError(t, err, "unknown operator: $divisible_by")
+	})
+
+	t.Run("evaluate visitor", func(t *testing.T) {
+		query := CompositeQuery{Fields: map[string]IQueryOperator{
+			"quantity": NotOperator{Operand: divisibleByOperator{Divisor: 2}},
+		}}
+		visitor := NewEvaluateVisitor(map[string]any{"quantity": 7}, nil, nil)
+		visitor.SetCustomOperators(registry)
+
+		result, err := query.Accept(visitor)
+		require.NoError(t, err)
+		assert.Equal(t, true, result)
+
+		_, err = query.Accept(NewEvaluateVisitor(map[string]any{"quantity": 7}, nil, nil))
+		assert.EqualError(t, err, "unknown operator: $divisible_by")
+	})
+
+	t.Run("visitors without custom operators", func(t *testing.T) {
+		_, err := QueryToDict(divisibleByOperator{Divisor: 3})
+		assert.EqualError(t, err, "unknown operator: $divisible_by")
+	})
+}
@@ -1,9 +1,14 @@
 package query
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
+	"sync/atomic"
+
+	"golang.org/x/sync/errgroup"
 
 	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
 	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
@@ -18,6 +23,51 @@ type IObjectResolver interface {
 	Descend(field string) IObjectResolver
 }
 
+// IContextObjectResolver is implemented by resolvers taking the context of the evaluation,
+// e.g. to bound remote calls by its deadline. Other resolvers use the context of the session.
+type IContextObjectResolver interface {
+	IObjectResolver
+	ResolveContext(ctx context.Context, s session.Session, field *string, fkValue any) (map[string]any, IObjectResolver, error)
+}
+
+// IConcurrentObjectResolver is implemented by resolvers which may resolve relations of the same session
+// from many goroutines at once, see WithConcurrency.
+type IConcurrentObjectResolver interface {
+	IObjectResolver
+	// IsConcurrencySafe reports whether the resolver is safe for concurrent use,
+	// decorators report it of their delegates.
+	IsConcurrencySafe() bool
+}
+
+func isConcurrencySafe(resolver IObjectResolver) bool {
+	r, ok := resolver.(IConcurrentObjectResolver)
+	return ok && r.IsConcurrencySafe()
+}
+
+// resolveObject resolves the relation unless the context is done, so relation cascades stop on cancellation.
+func resolveObject(
+	ctx context.Context,
+	resolver IObjectResolver,
+	s session.Session,
+	field *string,
+	fkValue any,
+) (map[string]any, IObjectResolver, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if r, ok := resolver.(IContextObjectResolver); ok {
+		return r.ResolveContext(ctx, s, field, fkValue)
+	}
+	return resolver.Resolve(s, field, fkValue)
+}
+
+func sessionContext(s session.Session) context.Context {
+	if s == nil {
+		return context.Background()
+	}
+	return s.Context()
+}
+
 type fieldContext struct {
 	field   string
 	fkValue any
@@ -25,27 +75,113 @@ type fieldContext struct {
 
 // EvaluateWalker evaluates whether an object state matches query criteria.
 type EvaluateWalker struct {
-	registry       *operators.OperatorRegistry
-	objectResolver IObjectResolver
+	registry        *operators.OperatorRegistry
+	objectResolver  IObjectResolver
+	fieldTypes      FieldTypes
+	numericCoercion NumericCoercion
+	floatTolerance  *operators.FloatTolerance
+	customOperators *CustomOperatorRegistry
+	strictFields    bool
+	concurrency     int
+	tracer          IQueryTracer
+	path            []string
 }
 
-func NewEvaluateWalker(objectResolver IObjectResolver) *EvaluateWalker {
-	return &EvaluateWalker{
+type EvaluateWalkerOption func(*EvaluateWalker)
+
+// WithConcurrency evaluates operands of $and and $or resolving relations ($rel)
+// in up to n goroutines, the rest of the operands are skipped once the result is known,
+// e.g. the first matching operand of $or. Operands evaluated in memory only are evaluated sequentially.
+// Relations are resolved concurrently by resolvers safe for concurrent use only, see IConcurrentObjectResolver,
+// evaluation with other resolvers stays sequential, e.g. PgObjectResolver querying the single connection of the session.
+// Evaluation is sequential by default.
+func WithConcurrency(n int) EvaluateWalkerOption {
+	return func(w *EvaluateWalker) {
+		w.concurrency = n
+	}
+}
+
+// WithTracer traces evaluations and relation resolutions, see IQueryTracer.
+func WithTracer(tracer IQueryTracer) EvaluateWalkerOption {
+	return func(w *EvaluateWalker) {
+		w.tracer = tracer
+	}
+}
+
+// WithStrictFields makes the walker fail with *UnknownFieldError, which is ErrUnknownField,
+// when a queried field is absent from the state, instead of not matching it,
+// so typos in field names are not hidden. Fields present with null values still match as null.
+func WithStrictFields() EvaluateWalkerOption {
+	return func(w *EvaluateWalker) {
+		w.strictFields = true
+	}
+}
+
+func NewEvaluateWalker(objectResolver IObjectResolver, opts ...EvaluateWalkerOption) *EvaluateWalker {
+	w := &EvaluateWalker{
 		registry:       operators.NewDefaultRegistry(),
 		objectResolver: objectResolver,
 	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// SetFieldTypes sets type hints of the state paths.
+// Values on hinted paths are coerced before comparison, see TypeHint.Coerce.
+func (w *EvaluateWalker) SetFieldTypes(fieldTypes FieldTypes) {
+	w.fieldTypes = fieldTypes
+}
+
+// SetNumericCoercion sets how numbers of different Go types are compared.
+// The default is NumericCoercionLoose.
+func (w *EvaluateWalker) SetNumericCoercion(numericCoercion NumericCoercion) {
+	w.numericCoercion = numericCoercion
+}
+
+// SetFloatTolerance compares float64 numbers within the tolerance, by the given operators only if any,
+// where $eq and $in are operators.OperatorEq. Comparisons are exact by default.
+// PgQueryCompiler compares numbers exactly in SQL, so with a tolerance the walker may match
+// states which the compiled query does not.
+func (w *EvaluateWalker) SetFloatTolerance(tolerance operators.FloatTolerance, ops ...operators.Operator) {
+	operators.RegisterFloatTolerance(w.registry, tolerance, ops...)
+	if len(ops) == 0 || slices.Contains(ops, operators.OperatorEq) {
+		w.floatTolerance = &tolerance
+	}
+}
+
+// SetCustomOperators sets the registry evaluating custom operators, see ICustomOperator.
+func (w *EvaluateWalker) SetCustomOperators(customOperators *CustomOperatorRegistry) {
+	w.customOperators = customOperators
 }
 
 // Evaluate checks if state matches query. Supports IObjectResolver for RelOperator.
+// It is EvaluateContext with the context of the session.
 func (w *EvaluateWalker) Evaluate(
 	s session.Session,
 	query IQueryOperator,
 	state any,
 ) (bool, error) {
-	return w.evaluate(s, query, state, nil)
+	return w.EvaluateContext(sessionContext(s), s, query, state)
+}
+
+// EvaluateContext checks if state matches query, relations are not resolved once the context is done,
+// the evaluation fails with its error then. Panics are returned as *QueryPanicError.
+func (w *EvaluateWalker) EvaluateContext(
+	ctx context.Context,
+	s session.Session,
+	query IQueryOperator,
+	state any,
+) (result bool, err error) {
+	ctx, end := TraceQuery(ctx, w.tracer, QueryOperationEvaluate, "", query)
+	defer func() { end(err) }()
+	defer RecoverQueryPanic(&err, query, state)
+	return w.evaluate(ctx, s, query, state, nil)
 }
 
 func (w *EvaluateWalker) evaluate(
+	ctx context.Context,
 	s session.Session,
 	query IQueryOperator,
 	state any,
@@ -53,7 +189,7 @@ func (w *EvaluateWalker) evaluate(
 ) (bool, error) {
 	switch q := query.(type) {
 	case EqOperator:
-		return reflect.DeepEqual(state, q.Value), nil
+		return w.numericCoercion.equalWithin(w.coerce(state), w.coerce(q.Value), w.floatTolerance), nil
 
 	case ComparisonOperator:
 		return w.compare(q.Op, state, q.Value)
@@ -61,12 +197,18 @@ func (w *EvaluateWalker) evaluate(
 	case InOperator:
 		return w.contains(q.Values, state), nil
 
+	case BetweenOperator:
+		return w.between(q, state)
+
 	case IsNullOperator:
 		return (state == nil) == q.Value, nil
 
 	case AndOperator:
+		if w.isConcurrent(q.Operands) {
+			return w.evaluateConcurrently(ctx, s, q.Operands, state, fc, false)
+		}
 		for _, operand := range q.Operands {
-			result, err := w.evaluate(s, operand, state, fc)
+			result, err := w.evaluate(ctx, s, operand, state, fc)
 			if err != nil {
 				return false, err
 			}
@@ -77,8 +219,11 @@ func (w *EvaluateWalker) evaluate(
 		return true, nil
 
 	case OrOperator:
+		if w.isConcurrent(q.Operands) {
+			return w.evaluateConcurrently(ctx, s, q.Operands, state, fc, true)
+		}
 		for _, operand := range q.Operands {
-			result, err := w.evaluate(s, operand, state, fc)
+			result, err := w.evaluate(ctx, s, operand, state, fc)
 			if err != nil {
 				return false, err
 			}
@@ -89,7 +234,7 @@ func (w *EvaluateWalker) evaluate(
 		return false, nil
 
 	case NotOperator:
-		result, err := w.evaluate(s, q.Operand, state, fc)
+		result, err := w.evaluate(ctx, s, q.Operand, state, fc)
 		if err != nil {
 			return false, err
 		}
@@ -100,8 +245,9 @@ func (w *EvaluateWalker) evaluate(
 		if !ok {
 			return false, nil
 		}
+		elements := w.withPath(arrayElementsKey)
 		for _, item := range items {
-			result, err := w.evaluate(s, q.Query, item, nil)
+			result, err := elements.evaluate(ctx, s, q.Query, item, nil)
 			if err != nil {
 				return false, err
 			}
@@ -116,8 +262,9 @@ func (w *EvaluateWalker) evaluate(
 		if !ok {
 			return false, nil
 		}
+		elements := w.withPath(arrayElementsKey)
 		for _, item := range items {
-			result, err := w.evaluate(s, q.Query, item, nil)
+			result, err := elements.evaluate(ctx, s, q.Query, item, nil)
 			if err != nil {
 				return false, err
 			}
@@ -132,10 +279,10 @@ func (w *EvaluateWalker) evaluate(
 		if !ok {
 			return false, nil
 		}
-		return w.evaluate(s, q.Query, len(items), nil)
+		return w.untyped().evaluate(ctx, s, q.Query, len(items), nil)
 
 	case CompositeQuery:
-		return w.evaluateComposite(s, q, state)
+		return w.evaluateComposite(ctx, s, q, state)
 
 	case RelOperator:
 		if w.objectResolver != nil {
@@ -148,23 +295,26 @@ func (w *EvaluateWalker) evaluate(
 				field = nil
 				fkValue = state
 			}
-			foreignState, nestedResolver, err := w.objectResolver.Resolve(s, field, fkValue)
+			foreignState, nestedResolver, err := w.resolve(ctx, s, field, fkValue, q.Query)
 			if err != nil {
 				return false, err
 			}
 			if foreignState == nil {
 				return false, nil
 			}
-			nested := &EvaluateWalker{registry: w.registry, objectResolver: nestedResolver}
-			return nested.evaluate(s, q.Query, foreignState, nil)
+			return w.related(nestedResolver).evaluate(ctx, s, q.Query, foreignState, nil)
 		}
-		return w.evaluate(s, q.Query, state, nil)
+		return w.evaluate(ctx, s, q.Query, state, nil)
+
+	case ICustomOperator:
+		return w.customOperators.Evaluate(q, state)
 	}
 
 	return false, nil
 }
 
 func (w *EvaluateWalker) evaluateComposite(
+	ctx context.Context,
 	s session.Session,
 	query CompositeQuery,
 	state any,
@@ -173,8 +323,11 @@ func (w *EvaluateWalker) evaluateComposite(
 		return false, nil
 	}
 	for field, fieldOp := range query.Fields {
-		fieldValue, _ := getFieldValue(state, field)
-		result, err := w.evaluateField(s, field, fieldOp, fieldValue)
+		fieldValue, err := w.fieldValue(state, field)
+		if err != nil {
+			return false, err
+		}
+		result, err := w.evaluateField(ctx, s, field, fieldOp, fieldValue)
 		if err != nil {
 			return false, err
 		}
@@ -186,37 +339,130 @@ func (w *EvaluateWalker) evaluateComposite(
 }
 
 func (w *EvaluateWalker) evaluateField(
+	ctx context.Context,
 	s session.Session,
 	field string,
 	fieldOp IQueryOperator,
 	fieldValue any,
 ) (bool, error) {
+	defer AnnotateQueryPanic(field, fieldOp, fieldValue)
 	if relOp, ok := fieldOp.(RelOperator); ok && w.objectResolver != nil {
-		foreignState, nestedResolver, err := w.objectResolver.Resolve(s, &field, fieldValue)
+		foreignState, nestedResolver, err := w.resolve(ctx, s, &field, fieldValue, relOp.Query)
 		if err != nil {
 			return false, err
 		}
 		if foreignState == nil {
 			return false, nil
 		}
-		nested := &EvaluateWalker{registry: w.registry, objectResolver: nestedResolver}
-		return nested.evaluate(s, relOp.Query, foreignState, nil)
+		return w.related(nestedResolver).evaluate(ctx, s, relOp.Query, foreignState, nil)
 	}
-	walker := w
-	if w.objectResolver != nil {
-		descended := w.objectResolver.Descend(field)
-		if descended != nil {
-			walker = &EvaluateWalker{registry: w.registry, objectResolver: descended}
+	return w.fieldWalker(field).evaluate(ctx, s, fieldOp, fieldValue, &fieldContext{field: field, fkValue: fieldValue})
+}
+
+// resolve resolves the relation traced with the query of the related aggregate.
+func (w *EvaluateWalker) resolve(
+	ctx context.Context,
+	s session.Session,
+	field *string,
+	fkValue any,
+	query CompositeQuery,
+) (state map[string]any, nestedResolver IObjectResolver, err error) {
+	var tracedField string
+	if field != nil {
+		tracedField = *field
+	}
+	ctx, end := TraceQuery(ctx, w.tracer, QueryOperationResolve, tracedField, query)
+	defer func() { end(err) }()
+	return resolveObject(ctx, w.objectResolver, s, field, fkValue)
+}
+
+// isConcurrent reports whether the operands of $and or $or are evaluated concurrently,
+// i.e. some of them resolve relations.
+func (w *EvaluateWalker) isConcurrent(operands []IQueryOperator) bool {
+	return w.concurrency > 1 && isConcurrencySafe(w.objectResolver) && len(operands) > 1 &&
+		slices.ContainsFunc(operands, hasRelation)
+}
+
+// evaluateConcurrently evaluates the operands until one of them evaluates to the definitive result,
+// true for $or and false for $and, the operands still being evaluated are canceled then.
+func (w *EvaluateWalker) evaluateConcurrently(
+	ctx context.Context,
+	s session.Session,
+	operands []IQueryOperator,
+	state any,
+	fc *fieldContext,
+	definitive bool,
+) (bool, error) {
+	groupCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	g, operandCtx := errgroup.WithContext(groupCtx)
+	g.SetLimit(w.concurrency)
+	var decided atomic.Bool
+	for _, operand := range operands {
+		g.Go(func() (err error) {
+			if operandCtx.Err() != nil {
+				return nil
+			}
+			defer RecoverQueryPanic(&err, operand, state)
+			result, err := w.evaluate(operandCtx, s, operand, state, fc)
+			if err != nil {
+				return err
+			}
+			if result == definitive {
+				decided.Store(true)
+				cancel()
+			}
+			return nil
+		})
+	}
+	err := g.Wait()
+	// Panics of the operands are raised again in the calling goroutine, so that fields are annotated.
+	if panicErr, ok := err.(*QueryPanicError); ok {
+		panic(panicErr)
+	}
+	if decided.Load() {
+		return definitive, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return !definitive, nil
+}
+
+// hasRelation reports whether the query resolves relations.
+func hasRelation(query IQueryOperator) bool {
+	switch q := query.(type) {
+	case RelOperator:
+		return true
+	case CompositeQuery:
+		for _, fieldOp := range q.Fields {
+			if hasRelation(fieldOp) {
+				return true
+			}
 		}
+	case AndOperator:
+		return slices.ContainsFunc(q.Operands, hasRelation)
+	case OrOperator:
+		return slices.ContainsFunc(q.Operands, hasRelation)
+	case NotOperator:
+		return hasRelation(q.Operand)
+	case AnyElementOperator:
+		return hasRelation(q.Query)
+	case AllElementsOperator:
+		return hasRelation(q.Query)
 	}
-	return walker.evaluate(s, fieldOp, fieldValue, &fieldContext{field: field, fkValue: fieldValue})
+	return false
 }
 
 // EvaluateSync checks if state matches query without session or resolver support.
 func (w *EvaluateWalker) EvaluateSync(
 	query IQueryOperator,
 	state any,
-) (bool, error) {
+) (result bool, err error) {
+	defer RecoverQueryPanic(&err, query, state)
 	return w.evaluateSync(query, state, nil)
 }
 
@@ -227,7 +473,7 @@ func (w *EvaluateWalker) evaluateSync(
 ) (bool, error) {
 	switch q := query.(type) {
 	case EqOperator:
-		return reflect.DeepEqual(state, q.Value), nil
+		return w.numericCoercion.equalWithin(w.coerce(state), w.coerce(q.Value), w.floatTolerance), nil
 
 	case ComparisonOperator:
 		return w.compare(q.Op, state, q.Value)
@@ -235,6 +481,9 @@ func (w *EvaluateWalker) evaluateSync(
 	case InOperator:
 		return w.contains(q.Values, state), nil
 
+	case BetweenOperator:
+		return w.between(q, state)
+
 	case IsNullOperator:
 		return (state == nil) == q.Value, nil
 
@@ -274,8 +523,9 @@ func (w *EvaluateWalker) evaluateSync(
 		if !ok {
 			return false, nil
 		}
+		elements := w.withPath(arrayElementsKey)
 		for _, item := range items {
-			result, err := w.evaluateSync(q.Query, item, nil)
+			result, err := elements.evaluateSync(q.Query, item, nil)
 			if err != nil {
 				return false, err
 			}
@@ -290,8 +540,9 @@ func (w *EvaluateWalker) evaluateSync(
 		if !ok {
 			return false, nil
 		}
+		elements := w.withPath(arrayElementsKey)
 		for _, item := range items {
-			result, err := w.evaluateSync(q.Query, item, nil)
+			result, err := elements.evaluateSync(q.Query, item, nil)
 			if err != nil {
 				return false, err
 			}
@@ -306,13 +557,16 @@ func (w *EvaluateWalker) evaluateSync(
 		if !ok {
 			return false, nil
 		}
-		return w.evaluateSync(q.Query, len(items), nil)
+		return w.untyped().evaluateSync(q.Query, len(items), nil)
 
 	case CompositeQuery:
 		return w.evaluateCompositeSync(q, state)
 
 	case RelOperator:
 		return w.evaluateSync(q.Query, state, nil)
+
+	case ICustomOperator:
+		return w.customOperators.Evaluate(q, state)
 	}
 
 	return false, nil
@@ -326,7 +580,10 @@ func (w *EvaluateWalker) evaluateCompositeSync(
 		return false, nil
 	}
 	for field, fieldOp := range query.Fields {
-		fieldValue, _ := getFieldValue(state, field)
+		fieldValue, err := w.fieldValue(state, field)
+		if err != nil {
+			return false, err
+		}
 		result, err := w.evaluateFieldSync(field, fieldOp, fieldValue)
 		if err != nil {
 			return false, err
@@ -343,17 +600,109 @@ func (w *EvaluateWalker) evaluateFieldSync(
 	fieldOp IQueryOperator,
 	fieldValue any,
 ) (bool, error) {
+	defer AnnotateQueryPanic(field, fieldOp, fieldValue)
 	if relOp, ok := fieldOp.(RelOperator); ok {
-		return w.evaluateSync(relOp.Query, fieldValue, nil)
+		return w.untyped().evaluateSync(relOp.Query, fieldValue, nil)
 	}
+	return w.fieldWalker(field).evaluateSync(fieldOp, fieldValue, &fieldContext{field: field, fkValue: fieldValue})
+}
+
+// fieldValue returns the value of the field of the state, nil if absent unless the walker is strict.
+func (w *EvaluateWalker) fieldValue(state any, field string) (any, error) {
+	value, found := getFieldValue(state, field)
+	if !found && w.strictFields {
+		return nil, &UnknownFieldError{Path: fieldTypePath(append(slices.Clone(w.path), field))}
+	}
+	return value, nil
+}
+
+// fieldWalker returns the walker of the field value,
+// with the object resolver descended to the field.
+func (w *EvaluateWalker) fieldWalker(field string) *EvaluateWalker {
 	walker := w
 	if w.objectResolver != nil {
 		descended := w.objectResolver.Descend(field)
 		if descended != nil {
-			walker = &EvaluateWalker{registry: w.registry, objectResolver: descended}
+			walker = &EvaluateWalker{
+				registry:        w.registry,
+				objectResolver:  descended,
+				fieldTypes:      w.fieldTypes,
+				numericCoercion: w.numericCoercion,
+				floatTolerance:  w.floatTolerance,
+				customOperators: w.customOperators,
+				strictFields:    w.strictFields,
+				concurrency:     w.concurrency,
+				tracer:          w.tracer,
+				path:            w.path,
+			}
 		}
 	}
-	return walker.evaluateSync(fieldOp, fieldValue, &fieldContext{field: field, fkValue: fieldValue})
+	return walker.withPath(field)
+}
+
+// withPath returns the walker of the nested key, which is tracked only for FieldTypes lookups and strict fields.
+func (w *EvaluateWalker) withPath(key string) *EvaluateWalker {
+	if w.fieldTypes == nil && !w.strictFields {
+		return w
+	}
+	path := make([]string, len(w.path), len(w.path)+1)
+	copy(path, w.path)
+	return &EvaluateWalker{
+		registry:        w.registry,
+		objectResolver:  w.objectResolver,
+		fieldTypes:      w.fieldTypes,
+		numericCoercion: w.numericCoercion,
+		floatTolerance:  w.floatTolerance,
+		customOperators: w.customOperators,
+		strictFields:    w.strictFields,
+		concurrency:     w.concurrency,
+		tracer:          w.tracer,
+		path:            append(path, key),
+	}
+}
+
+// untyped returns the walker without field types,
+// for computed values such as $len and for states of other aggregates.
+func (w *EvaluateWalker) untyped() *EvaluateWalker {
+	if w.fieldTypes == nil {
+		return w
+	}
+	return &EvaluateWalker{
+		registry:        w.registry,
+		objectResolver:  w.objectResolver,
+		numericCoercion: w.numericCoercion,
+		floatTolerance:  w.floatTolerance,
+		customOperators: w.customOperators,
+		strictFields:    w.strictFields,
+		concurrency:     w.concurrency,
+		tracer:          w.tracer,
+	}
+}
+
+// related returns the walker of the related aggregate state, without field types of the current one.
+func (w *EvaluateWalker) related(objectResolver IObjectResolver) *EvaluateWalker {
+	return &EvaluateWalker{
+		registry:        w.registry,
+		objectResolver:  objectResolver,
+		numericCoercion: w.numericCoercion,
+		floatTolerance:  w.floatTolerance,
+		customOperators: w.customOperators,
+		strictFields:    w.strictFields,
+		concurrency:     w.concurrency,
+		tracer:          w.tracer,
+	}
+}
+
+// coerce converts the value according to the field type of the current path.
+func (w *EvaluateWalker) coerce(value any) any {
+	if len(w.path) == 0 {
+		return value
+	}
+	hint, ok := w.fieldTypes.Get(fieldTypePath(w.path))
+	if !ok {
+		return value
+	}
+	return hint.Coerce(value)
 }
 
 func (w *EvaluateWalker) compare(op string, actual, expected any) (bool, error) {
@@ -372,6 +721,8 @@ func (w *EvaluateWalker) compare(op string, actual, expected any) (bool, error)
 	default:
 		return false, fmt.Errorf("unknown comparison operator: %s", op)
 	}
+	actual, expected = coerceTemporal(w.coerce(actual), w.coerce(expected))
+	actual, expected = w.numericCoercion.Coerce(actual, expected)
 	result, err := w.registry.ExecBinary(actual, regOp, expected)
 	if err != nil {
 		return false, err
@@ -386,9 +737,19 @@ func (w *EvaluateWalker) compare(op string, actual, expected any) (bool, error)
 	return b, nil
 }
 
+func (w *EvaluateWalker) between(op BetweenOperator, state any) (bool, error) {
+	lowOp, highOp := op.BoundOps()
+	result, err := w.compare(lowOp, state, op.Low)
+	if err != nil || !result {
+		return false, err
+	}
+	return w.compare(highOp, state, op.High)
+}
+
 func (w *EvaluateWalker) contains(values []any, state any) bool {
+	state = w.coerce(state)
 	for _, v := range values {
-		if reflect.DeepEqual(state, v) {
+		if w.numericCoercion.equalWithin(state, w.coerce(v), w.floatTolerance) {
 			return true
 		}
 	}
@@ -439,7 +800,16 @@ func getFieldValue(state any, field string) (any, bool) {
 	if v.Kind() != reflect.Struct {
 		return nil, false
 	}
-	t := v.Type()
+	index, found := lookupStructField(v.Type(), field)
+	if !found {
+		return nil, false
+	}
+	return v.Field(index).Interface(), true
+}
+
+// lookupStructField returns the index of the exported field of the struct type
+// with the json name, or else with the Go name.
+func lookupStructField(t reflect.Type, field string) (int, bool) {
 	for i := 0; i < t.NumField(); i++ {
 		sf := t.Field(i)
 		if !sf.IsExported() {
@@ -449,7 +819,7 @@ func getFieldValue(state any, field string) (any, bool) {
 		if tag != "" {
 			name, _, _ := strings.Cut(tag, ",")
 			if name == field {
-				return v.Field(i).Interface(), true
+				return i, true
 			}
 		}
 	}
@@ -459,31 +829,42 @@ func getFieldValue(state any, field string) (any, bool) {
 			continue
 		}
 		if sf.Name == field {
-			return v.Field(i).Interface(), true
+			return i, true
 		}
 	}
-	return nil, false
+	return 0, false
 }
 
 // EvaluateVisitor is a visitor-based evaluator.
 // State is carried in the instance; recursion creates new instances.
 type EvaluateVisitor struct {
-	state          any
-	sess           session.Session
-	objectResolver IObjectResolver
-	fieldCtx       *fieldContext
-	registry       *operators.OperatorRegistry
+	state           any
+	ctx             context.Context
+	sess            session.Session
+	objectResolver  IObjectResolver
+	fieldCtx        *fieldContext
+	registry        *operators.OperatorRegistry
+	numericCoercion NumericCoercion
+	floatTolerance  *operators.FloatTolerance
+	customOperators *CustomOperatorRegistry
 }
 
 func NewEvaluateVisitor(state any, s session.Session, objectResolver IObjectResolver) *EvaluateVisitor {
 	return &EvaluateVisitor{
 		state:          state,
+		ctx:            sessionContext(s),
 		sess:           s,
 		objectResolver: objectResolver,
 		registry:       operators.NewDefaultRegistry(),
 	}
 }
 
+// SetContext sets the context of resolving relations, the context of the session by default.
+// Relations are not resolved once it is done, the evaluation fails with its error then.
+func (v *EvaluateVisitor) SetContext(ctx context.Context) {
+	v.ctx = ctx
+}
+
 func (v *EvaluateVisitor) withState(
 	state any,
 	objectResolver IObjectResolver,
@@ -494,16 +875,53 @@ func (v *EvaluateVisitor) withState(
 		resolver = v.objectResolver
 	}
 	return &EvaluateVisitor{
-		state:          state,
-		sess:           v.sess,
-		objectResolver: resolver,
-		fieldCtx:       fc,
-		registry:       v.registry,
+		state:           state,
+		ctx:             v.ctx,
+		sess:            v.sess,
+		objectResolver:  resolver,
+		fieldCtx:        fc,
+		registry:        v.registry,
+		numericCoercion: v.numericCoercion,
+		floatTolerance:  v.floatTolerance,
+		customOperators: v.customOperators,
+	}
+}
+
+// Evaluate checks if the state of the visitor matches query, panics are returned as *QueryPanicError.
+func (v *EvaluateVisitor) Evaluate(query IQueryOperator) (result bool, err error) {
+	defer RecoverQueryPanic(&err, query, v.state)
+	matched, err := query.Accept(v)
+	if err != nil {
+		return false, err
+	}
+	result, ok := matched.(bool)
+	if !ok {
+		return false, fmt.Errorf("%T evaluated to %T, not bool", query, matched)
+	}
+	return result, nil
+}
+
+// SetNumericCoercion sets how numbers of different Go types are compared.
+// The default is NumericCoercionLoose.
+func (v *EvaluateVisitor) SetNumericCoercion(numericCoercion NumericCoercion) {
+	v.numericCoercion = numericCoercion
+}
+
+// SetFloatTolerance compares float64 numbers within the tolerance, see EvaluateWalker.SetFloatTolerance.
+func (v *EvaluateVisitor) SetFloatTolerance(tolerance operators.FloatTolerance, ops ...operators.Operator) {
+	operators.RegisterFloatTolerance(v.registry, tolerance, ops...)
+	if len(ops) == 0 || slices.Contains(ops, operators.OperatorEq) {
+		v.floatTolerance = &tolerance
 	}
 }
 
+// SetCustomOperators sets the registry evaluating custom operators, see ICustomOperator.
+func (v *EvaluateVisitor) SetCustomOperators(customOperators *CustomOperatorRegistry) {
+	v.customOperators = customOperators
+}
+
 func (v *EvaluateVisitor) VisitEq(op EqOperator) (any, error) {
-	return reflect.DeepEqual(v.state, op.Value), nil
+	return v.numericCoercion.equalWithin(v.state, op.Value, v.floatTolerance), nil
 }
 
 func (v *EvaluateVisitor) VisitComparison(op ComparisonOperator) (any, error) {
@@ -522,7 +940,8 @@ func (v *EvaluateVisitor) VisitComparison(op ComparisonOperator) (any, error) {
 	default:
 		return false, nil
 	}
-	result, err := v.registry.ExecBinary(v.state, regOp, op.Value)
+	actual, expected := v.numericCoercion.Coerce(coerceTemporal(v.state, op.Value))
+	result, err := v.registry.ExecBinary(actual, regOp, expected)
 	if err != nil {
 		return false, err
 	}
@@ -534,13 +953,24 @@ func (v *EvaluateVisitor) VisitComparison(op ComparisonOperator) (any, error) {
 
 func (v *EvaluateVisitor) VisitIn(op InOperator) (any, error) {
 	for _, val := range op.Values {
-		if reflect.DeepEqual(v.state, val) {
+		if v.numericCoercion.equalWithin(v.state, val, v.floatTolerance) {
 			return true, nil
 		}
 	}
 	return false, nil
 }
 
+func (v *EvaluateVisitor) VisitBetween(op BetweenOperator) (any, error) {
+	lowOp, highOp := op.BoundOps()
+	for _, bound := range []ComparisonOperator{{Op: lowOp, Value: op.Low}, {Op: highOp, Value: op.High}} {
+		result, err := v.VisitComparison(bound)
+		if err != nil || !result.(bool) {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
 func (v *EvaluateVisitor) VisitIsNull(op IsNullOperator) (any, error) {
 	return (v.state == nil) == op.Value, nil
 }
@@ -638,7 +1068,7 @@ func (v *EvaluateVisitor) VisitRel(op RelOperator) (any, error) {
 			field = nil
 			fkValue = v.state
 		}
-		foreignState, nestedResolver, err := v.objectResolver.Resolve(v.sess, field, fkValue)
+		foreignState, nestedResolver, err := resolveObject(v.ctx, v.objectResolver, v.sess, field, fkValue)
 		if err != nil {
 			return false, err
 		}
@@ -656,38 +1086,44 @@ func (v *EvaluateVisitor) VisitComposite(op CompositeQuery) (any, error) {
 		return false, nil
 	}
 	for field, fieldOp := range op.Fields {
-		fieldValue, _ := getFieldValue(v.state, field)
-		if relOp, isRel := fieldOp.(RelOperator); isRel && v.objectResolver != nil {
-			f := field
-			foreignState, nestedResolver, err := v.objectResolver.Resolve(v.sess, &f, fieldValue)
-			if err != nil {
-				return false, err
-			}
-			if foreignState == nil {
-				return false, nil
-			}
-			nested := v.withState(foreignState, nestedResolver, nil)
-			result, err := relOp.Query.Accept(nested)
-			if err != nil {
-				return false, err
-			}
-			if !result.(bool) {
-				return false, nil
-			}
-		} else {
-			var descended IObjectResolver
-			if v.objectResolver != nil {
-				descended = v.objectResolver.Descend(field)
-			}
-			evaluator := v.withState(fieldValue, descended, &fieldContext{field: field, fkValue: fieldValue})
-			result, err := fieldOp.Accept(evaluator)
-			if err != nil {
-				return false, err
-			}
-			if !result.(bool) {
-				return false, nil
-			}
+		result, err := v.visitField(field, fieldOp)
+		if err != nil || !result {
+			return false, err
 		}
 	}
 	return true, nil
 }
+
+func (v *EvaluateVisitor) visitField(field string, fieldOp IQueryOperator) (bool, error) {
+	fieldValue, _ := getFieldValue(v.state, field)
+	defer AnnotateQueryPanic(field, fieldOp, fieldValue)
+	if relOp, isRel := fieldOp.(RelOperator); isRel && v.objectResolver != nil {
+		foreignState, nestedResolver, err := resolveObject(v.ctx, v.objectResolver, v.sess, &field, fieldValue)
+		if err != nil {
+			return false, err
+		}
+		if foreignState == nil {
+			return false, nil
+		}
+		nested := v.withState(foreignState, nestedResolver, nil)
+		result, err := relOp.Query.Accept(nested)
+		if err != nil {
+			return false, err
+		}
+		return result.(bool), nil
+	}
+	var descended IObjectResolver
+	if v.objectResolver != nil {
+		descended = v.objectResolver.Descend(field)
+	}
+	evaluator := v.withState(fieldValue, descended, &fieldContext{field: field, fkValue: fieldValue})
+	result, err := fieldOp.Accept(evaluator)
+	if err != nil {
+		return false, err
+	}
+	return result.(bool), nil
+}
+
+func (v *EvaluateVisitor) VisitCustom(op ICustomOperator) (any, error) {
+	return v.customOperators.Evaluate(op, v.state)
+}
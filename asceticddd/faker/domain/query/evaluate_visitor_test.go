@@ -2,11 +2,15 @@ package query
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session/identitymap"
 	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/signals"
 )
 
@@ -1309,6 +1313,50 @@ func TestEvaluateVisitor_Struct(t *testing.T) {
 // EvaluateWalker - $not, $any, $all, $len
 // =============================================================================
 
+func TestEvaluateWalkerBetween(t *testing.T) {
+	walker := NewEvaluateWalker(nil)
+	inclusive := BetweenOperator{Low: 18, High: 65, Inclusive: true}
+	exclusive := BetweenOperator{Low: 18, High: 65}
+
+	cases := []struct {
+		name     string
+		op       BetweenOperator
+		state    any
+		expected bool
+	}{
+		{"inside", inclusive, 30, true},
+		{"low bound inclusive", inclusive, 18, true},
+		{"high bound inclusive", inclusive, 65, true},
+		{"low bound exclusive", exclusive, 18, false},
+		{"high bound exclusive", exclusive, 65, false},
+		{"inside exclusive", exclusive, 19, true},
+		{"below", inclusive, 17, false},
+		{"above", inclusive, 66, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result, err := walker.Evaluate(sess, c.op, c.state)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, result)
+
+			result, err = walker.EvaluateSync(c.op, c.state)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, result)
+
+			accepted, err := c.op.Accept(NewEvaluateVisitor(c.state, sess, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, accepted)
+		})
+	}
+
+	t.Run("in composite", func(t *testing.T) {
+		query := CompositeQuery{Fields: map[string]IQueryOperator{"age": inclusive}}
+		result, err := walker.Evaluate(sess, query, map[string]any{"age": 30})
+		assert.NoError(t, err)
+		assert.True(t, result)
+	})
+}
+
 func TestEvaluateWalkerNot(t *testing.T) {
 	walker := NewEvaluateWalker(nil)
 
@@ -2210,3 +2258,208 @@ func TestEvaluateVisitorNestedCompositeDescend(t *testing.T) {
 		assert.False(t, result)
 	})
 }
+
+type contextKey struct{}
+
+// contextRecordingResolver resolves every fk to the same state and records the contexts it is called with.
+type contextRecordingResolver struct {
+	state    map[string]any
+	contexts []context.Context
+}
+
+func (r *contextRecordingResolver) Resolve(s session.Session, field *string, fkValue any) (map[string]any, IObjectResolver, error) {
+	return r.ResolveContext(s.Context(), s, field, fkValue)
+}
+
+func (r *contextRecordingResolver) ResolveContext(
+	ctx context.Context,
+	s session.Session,
+	field *string,
+	fkValue any,
+) (map[string]any, IObjectResolver, error) {
+	r.contexts = append(r.contexts, ctx)
+	return r.state, r, nil
+}
+
+func (r *contextRecordingResolver) Descend(field string) IObjectResolver {
+	return nil
+}
+
+func TestEvaluateContext(t *testing.T) {
+	query := CompositeQuery{Fields: map[string]IQueryOperator{
+		"status_id": RelOperator{Query: CompositeQuery{Fields: map[string]IQueryOperator{
+			"name": EqOperator{Value: "Active"},
+		}}},
+	}}
+	state := map[string]any{"status_id": "active"}
+
+	t.Run("walker passes context to resolver", func(t *testing.T) {
+		resolver := &contextRecordingResolver{state: map[string]any{"name": "Active"}}
+		ctx := context.WithValue(context.Background(), contextKey{}, "evaluation")
+
+		result, err := NewEvaluateWalker(NewCachingObjectResolver(resolver)).EvaluateContext(ctx, &mockSession{}, query, state)
+		require.NoError(t, err)
+		assert.True(t, result)
+		require.Len(t, resolver.contexts, 1)
+		assert.Equal(t, "evaluation", resolver.contexts[0].Value(contextKey{}))
+	})
+
+	t.Run("walker does not resolve with done context", func(t *testing.T) {
+		resolver := &contextRecordingResolver{state: map[string]any{"name": "Active"}}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := NewEvaluateWalker(resolver).EvaluateContext(ctx, &mockSession{}, query, state)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, resolver.contexts)
+	})
+
+	t.Run("visitor does not resolve with done context", func(t *testing.T) {
+		resolver := &contextRecordingResolver{state: map[string]any{"name": "Active"}}
+		ctx, cancel := context.WithTimeout(context.Background(), 0)
+		defer cancel()
+		visitor := NewEvaluateVisitor(state, &mockSession{}, resolver)
+		visitor.SetContext(ctx)
+
+		_, err := visitor.Evaluate(query)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Empty(t, resolver.contexts)
+	})
+}
+
+// blockingResolver resolves fk "slow" once the context is done and other fks immediately.
+type blockingResolver struct {
+	mu     sync.Mutex
+	states map[any]map[string]any
+	err    error
+}
+
+func (r *blockingResolver) Resolve(s session.Session, field *string, fkValue any) (map[string]any, IObjectResolver, error) {
+	return r.ResolveContext(s.Context(), s, field, fkValue)
+}
+
+func (r *blockingResolver) ResolveContext(
+	ctx context.Context,
+	s session.Session,
+	field *string,
+	fkValue any,
+) (map[string]any, IObjectResolver, error) {
+	if fkValue == "slow" {
+		<-ctx.Done()
+		return nil, nil, ctx.Err()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+	return r.states[fkValue], r, nil
+}
+
+func (r *blockingResolver) Descend(field string) IObjectResolver {
+	return nil
+}
+
+func (r *blockingResolver) IsConcurrencySafe() bool {
+	return true
+}
+
+func TestEvaluateConcurrently(t *testing.T) {
+	rel := func(field string, name string) IQueryOperator {
+		return CompositeQuery{Fields: map[string]IQueryOperator{
+			field: RelOperator{Query: CompositeQuery{Fields: map[string]IQueryOperator{
+				"name": EqOperator{Value: name},
+			}}},
+		}}
+	}
+	state := map[string]any{"slow_id": "slow", "fast_id": "fast"}
+	resolver := &blockingResolver{states: map[any]map[string]any{"fast": {"name": "Fast"}}}
+	walker := NewEvaluateWalker(resolver, WithConcurrency(2))
+
+	t.Run("or short-circuits on first match", func(t *testing.T) {
+		result, err := walker.Evaluate(sess, OrOperator{Operands: []IQueryOperator{
+			rel("slow_id", "Slow"), rel("fast_id", "Fast"),
+		}}, state)
+		require.NoError(t, err)
+		assert.True(t, result)
+	})
+
+	t.Run("and short-circuits on first mismatch", func(t *testing.T) {
+		result, err := walker.Evaluate(sess, AndOperator{Operands: []IQueryOperator{
+			rel("slow_id", "Slow"), rel("fast_id", "Other"),
+		}}, state)
+		require.NoError(t, err)
+		assert.False(t, result)
+	})
+
+	t.Run("or without match", func(t *testing.T) {
+		result, err := walker.Evaluate(sess, OrOperator{Operands: []IQueryOperator{
+			rel("fast_id", "Other"), CompositeQuery{Fields: map[string]IQueryOperator{"fast_id": EqOperator{Value: "other"}}},
+		}}, state)
+		require.NoError(t, err)
+		assert.False(t, result)
+	})
+
+	t.Run("resolver error", func(t *testing.T) {
+		failing := &blockingResolver{err: errors.New("unavailable")}
+		_, err := NewEvaluateWalker(failing, WithConcurrency(2)).Evaluate(sess, OrOperator{Operands: []IQueryOperator{
+			rel("slow_id", "Slow"), rel("fast_id", "Fast"),
+		}}, state)
+		assert.ErrorIs(t, err, failing.err)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := walker.EvaluateContext(ctx, sess, OrOperator{Operands: []IQueryOperator{
+			rel("slow_id", "Slow"), rel("fast_id", "Fast"),
+		}}, state)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("panic of operand", func(t *testing.T) {
+		registry := NewCustomOperatorRegistry()
+		registry.Register("$divisible_by", nil, func(op ICustomOperator, state any) (bool, error) {
+			return state.(int)%op.(divisibleByOperator).Divisor == 0, nil
+		})
+		walker := NewEvaluateWalker(resolver, WithConcurrency(2))
+		walker.SetCustomOperators(registry)
+
+		_, err := walker.Evaluate(sess, CompositeQuery{Fields: map[string]IQueryOperator{
+			"order": OrOperator{Operands: []IQueryOperator{
+				rel("slow_id", "Slow"),
+				CompositeQuery{Fields: map[string]IQueryOperator{"quantity": divisibleByOperator{Divisor: 3}}},
+			}},
+		}}, map[string]any{"order": map[string]any{"slow_id": "slow", "quantity": "nine"}})
+		var panicErr *QueryPanicError
+		require.ErrorAs(t, err, &panicErr)
+		assert.Equal(t, "order.quantity", panicErr.Path)
+	})
+	t.Run("caching resolver", func(t *testing.T) {
+		walker := NewEvaluateWalker(NewCachingObjectResolver(resolver), WithConcurrency(4))
+		s := newIdentityMapSessionStub(identitymap.Serializable)
+		state := map[string]any{"a_id": "fast", "b_id": "fast", "c_id": "fast", "d_id": "missing"}
+		for range 10 {
+			result, err := walker.Evaluate(s, AndOperator{Operands: []IQueryOperator{
+				rel("a_id", "Fast"), rel("b_id", "Fast"), rel("c_id", "Fast"), rel("d_id", "Missing"),
+			}}, state)
+			require.NoError(t, err)
+			assert.False(t, result)
+		}
+	})
+
+	t.Run("resolver unsafe for concurrent use", func(t *testing.T) {
+		sequential := &countingObjectResolver{storage: map[string]map[any]map[string]any{
+			"fast_id": {"fast": {"name": "Fast"}},
+		}}
+		walker := NewEvaluateWalker(sequential, WithConcurrency(2))
+		assert.False(t, walker.isConcurrent([]IQueryOperator{rel("fast_id", "Fast"), rel("fast_id", "Other")}))
+
+		result, err := walker.Evaluate(sess, OrOperator{Operands: []IQueryOperator{
+			rel("fast_id", "Other"), rel("fast_id", "Fast"),
+		}}, state)
+		require.NoError(t, err)
+		assert.True(t, result)
+		assert.Equal(t, 2, sequential.resolveCalls)
+	})
+}
@@ -0,0 +1,175 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TypeHint tells how values of a field are compared.
+type TypeHint string
+
+const (
+	TypeHintNumeric  TypeHint = "numeric"
+	TypeHintText     TypeHint = "text"
+	TypeHintBool     TypeHint = "bool"
+	TypeHintDatetime TypeHint = "datetime"
+	TypeHintUuid     TypeHint = "uuid"
+)
+
+// FieldTypes maps field paths to type hints.
+// Paths are dot-separated; elements of arrays are addressed with "[*]",
+// e.g. "events[*].occurred_at".
+//
+// The same FieldTypes is given to EvaluateWalker and PgQueryCompiler,
+// so that in-memory evaluation coerces values the same way the SQL casts them,
+// e.g. "42" equals 42 on a numeric path.
+type FieldTypes map[string]TypeHint
+
+func (f FieldTypes) Get(path string) (TypeHint, bool) {
+	hint, ok := f[path]
+	return hint, ok
+}
+
+const arrayElementsKey = "[*]"
+
+func fieldTypePath(path []string) string {
+	var b strings.Builder
+	for i, key := range path {
+		if i > 0 && key != arrayElementsKey {
+			b.WriteByte('.')
+		}
+		b.WriteString(key)
+	}
+	return b.String()
+}
+
+// Coerce converts the value to the canonical Go type of the hint:
+// float64 for numeric, string for text and uuid (lower-cased),
+// bool for bool and time.Time in UTC for datetime.
+// Values which can not be converted are returned unchanged, so they never
+// compare equal to converted ones.
+func (h TypeHint) Coerce(value any) any {
+	switch h {
+	case TypeHintNumeric:
+		if n, ok := toFloat64(value); ok {
+			return n
+		}
+	case TypeHintText:
+		switch v := value.(type) {
+		case string:
+			return v
+		case nil:
+			return nil
+		case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+			return fmt.Sprint(v)
+		}
+	case TypeHintBool:
+		switch v := value.(type) {
+		case bool:
+			return v
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b
+			}
+		}
+	case TypeHintDatetime:
+		switch v := value.(type) {
+		case time.Time:
+			return v.UTC()
+		case string:
+			if t, ok := ParseTimestamp(v); ok {
+				return t.UTC()
+			}
+		}
+	case TypeHintUuid:
+		if v, ok := value.(string); ok {
+			return strings.ToLower(v)
+		}
+	}
+	return value
+}
+
+func toFloat64(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// ParseTimestamp parses an ISO 8601 timestamp or date.
+// Timestamps without offset and dates are in UTC.
+func ParseTimestamp(value string) (time.Time, bool) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// coerceTemporal converts the operands of a comparison to time.Time
+// when one of them is time.Time and the other is an ISO string,
+// or both are RFC 3339 strings, which do not compare lexicographically across offsets.
+// Otherwise the operands are returned unchanged.
+func coerceTemporal(actual, expected any) (any, any) {
+	switch a := actual.(type) {
+	case time.Time:
+		if e, ok := expected.(string); ok {
+			if t, ok := ParseTimestamp(e); ok {
+				return a, t
+			}
+		}
+	case string:
+		switch e := expected.(type) {
+		case time.Time:
+			if t, ok := ParseTimestamp(a); ok {
+				return t, e
+			}
+		case string:
+			at, aErr := time.Parse(time.RFC3339Nano, a)
+			et, eErr := time.Parse(time.RFC3339Nano, e)
+			if aErr == nil && eErr == nil {
+				return at, et
+			}
+		}
+	}
+	return actual, expected
+}
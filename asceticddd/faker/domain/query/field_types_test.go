@@ -0,0 +1,158 @@
+package query
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	t.Run("rfc3339 with offset", func(t *testing.T) {
+		ts, ok := ParseTimestamp("2024-03-01T12:00:00+03:00")
+		assert.True(t, ok)
+		assert.True(t, ts.Equal(time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)))
+	})
+	t.Run("fractional seconds", func(t *testing.T) {
+		ts, ok := ParseTimestamp("2024-03-01T12:00:00.123Z")
+		assert.True(t, ok)
+		assert.Equal(t, 123*int(time.Millisecond), ts.Nanosecond())
+	})
+	t.Run("without offset is utc", func(t *testing.T) {
+		ts, ok := ParseTimestamp("2024-03-01T12:00:00")
+		assert.True(t, ok)
+		assert.True(t, ts.Equal(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)))
+	})
+	t.Run("date", func(t *testing.T) {
+		ts, ok := ParseTimestamp("2024-03-01")
+		assert.True(t, ok)
+		assert.True(t, ts.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)))
+	})
+	t.Run("not a timestamp", func(t *testing.T) {
+		_, ok := ParseTimestamp("Acme")
+		assert.False(t, ok)
+	})
+}
+
+func TestFieldTypes(t *testing.T) {
+	fieldTypes := FieldTypes{"created_at": TypeHintDatetime}
+	hint, ok := fieldTypes.Get("created_at")
+	assert.True(t, ok)
+	assert.Equal(t, TypeHintDatetime, hint)
+	_, ok = fieldTypes.Get("name")
+	assert.False(t, ok)
+	_, ok = FieldTypes(nil).Get("created_at")
+	assert.False(t, ok)
+}
+
+func TestTypeHintCoerce(t *testing.T) {
+	moment := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name     string
+		hint     TypeHint
+		value    any
+		expected any
+	}{
+		{"numeric int", TypeHintNumeric, 42, float64(42)},
+		{"numeric string", TypeHintNumeric, "42", float64(42)},
+		{"numeric json number", TypeHintNumeric, json.Number("4.5"), 4.5},
+		{"numeric invalid", TypeHintNumeric, "forty", "forty"},
+		{"text number", TypeHintText, 42, "42"},
+		{"text bool", TypeHintText, true, "true"},
+		{"text nil", TypeHintText, nil, nil},
+		{"bool string", TypeHintBool, "true", true},
+		{"bool invalid", TypeHintBool, "yes please", "yes please"},
+		{"datetime string", TypeHintDatetime, "2024-03-01T12:00:00+03:00", moment},
+		{"datetime time", TypeHintDatetime, moment.In(time.FixedZone("MSK", 3*3600)), moment},
+		{"uuid", TypeHintUuid, "6F9619FF-8B86-D011-B42D-00CF4FC964FF", "6f9619ff-8b86-d011-b42d-00cf4fc964ff"},
+		{"unknown hint", TypeHint("money"), "42", "42"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, c.hint.Coerce(c.value))
+		})
+	}
+}
+
+func TestEvaluateWalkerFieldTypes(t *testing.T) {
+	walker := NewEvaluateWalker(nil)
+	walker.SetFieldTypes(FieldTypes{
+		"age":            TypeHintNumeric,
+		"code":           TypeHintText,
+		"active":         TypeHintBool,
+		"id":             TypeHintUuid,
+		"profile.score":  TypeHintNumeric,
+		"items[*].price": TypeHintNumeric,
+		"tags":           TypeHintNumeric,
+	})
+
+	cases := []struct {
+		name     string
+		query    map[string]any
+		state    map[string]any
+		expected bool
+	}{
+		{"numeric eq string", map[string]any{"age": 42}, map[string]any{"age": "42"}, true},
+		{"numeric gt string", map[string]any{"age": map[string]any{"$gt": "9"}}, map[string]any{"age": 10}, true},
+		{"numeric in", map[string]any{"age": map[string]any{"$in": []any{"41", "42"}}}, map[string]any{"age": 42.0}, true},
+		{"numeric between", map[string]any{"age": map[string]any{"$between": []any{"18", "65"}}}, map[string]any{"age": 30}, true},
+		{"text eq number", map[string]any{"code": "007"}, map[string]any{"code": 7}, false},
+		{"text eq digits", map[string]any{"code": "7"}, map[string]any{"code": 7}, true},
+		{"bool eq string", map[string]any{"active": true}, map[string]any{"active": "true"}, true},
+		{"uuid case insensitive", map[string]any{"id": "ABC-1"}, map[string]any{"id": "abc-1"}, true},
+		{"nested path", map[string]any{"profile": map[string]any{"score": "7"}}, map[string]any{"profile": map[string]any{"score": 7}}, true},
+		{"array elements", map[string]any{"items": map[string]any{"$any": map[string]any{"price": map[string]any{"$lt": "10"}}}},
+			map[string]any{"items": []any{map[string]any{"price": 12}, map[string]any{"price": 5}}}, true},
+		{"len is not coerced", map[string]any{"tags": map[string]any{"$len": 2}}, map[string]any{"tags": []any{1, 2}}, true},
+		{"unhinted numeric string", map[string]any{"name": 42}, map[string]any{"name": "42"}, true},
+		{"unhinted text", map[string]any{"name": 42}, map[string]any{"name": "Acme"}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			query, err := ParseQuery(c.query)
+			assert.NoError(t, err)
+
+			result, err := walker.EvaluateSync(query, c.state)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, result)
+
+			result, err = walker.Evaluate(sess, query, c.state)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, result)
+		})
+	}
+}
+
+func TestEvaluateWalkerTemporalComparison(t *testing.T) {
+	walker := NewEvaluateWalker(nil)
+	moment := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		query    IQueryOperator
+		state    any
+		expected bool
+	}{
+		{"time vs iso string", ComparisonOperator{Op: "$gt", Value: "2024-03-01T11:00:00Z"}, moment, true},
+		{"iso string vs time", ComparisonOperator{Op: "$lt", Value: moment}, "2024-03-01T11:00:00Z", true},
+		{"time vs date", ComparisonOperator{Op: "$gte", Value: "2024-03-01"}, moment, true},
+		{"time vs time", ComparisonOperator{Op: "$lte", Value: moment}, moment.Add(-time.Second), true},
+		// Lexicographically "2024-03-01T13:00:00+03:00" > "2024-03-01T11:00:00Z".
+		{"offsets compare chronologically", ComparisonOperator{Op: "$gt", Value: "2024-03-01T11:00:00Z"}, "2024-03-01T13:00:00+03:00", false},
+		{"plain strings stay lexicographic", ComparisonOperator{Op: "$gt", Value: "apple"}, "banana", true},
+		{"between times", BetweenOperator{Low: "2024-01-01", High: "2024-12-31", Inclusive: true}, moment, true},
+		{"between outside", BetweenOperator{Low: "2024-04-01", High: "2024-12-31", Inclusive: true}, moment, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result, err := walker.EvaluateSync(c.query, c.state)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, result)
+
+			accepted, err := c.query.Accept(NewEvaluateVisitor(c.state, sess, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, accepted)
+		})
+	}
+}
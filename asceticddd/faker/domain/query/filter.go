@@ -0,0 +1,61 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+// filterCheckInterval is the number of states evaluated between checks of the context.
+const filterCheckInterval = 64
+
+// PartialResultError is returned with the states matched so far
+// when the context is done before all states are evaluated.
+// It unwraps to the error of the context, e.g. context.DeadlineExceeded.
+type PartialResultError struct {
+	Evaluated int
+	Total     int
+	Err       error
+}
+
+func (e *PartialResultError) Error() string {
+	return fmt.Sprintf("filter stopped after %d of %d states: %v", e.Evaluated, e.Total, e.Err)
+}
+
+func (e *PartialResultError) Unwrap() error {
+	return e.Err
+}
+
+// FilterCtx returns the states matching the query, in the order of states.
+// The context is checked periodically and by relation resolutions, see PartialResultError.
+func FilterCtx[T any](ctx context.Context, query IQueryOperator, states []T) ([]T, error) {
+	return FilterWalkerCtx(ctx, NewEvaluateWalker(nil), nil, query, states)
+}
+
+// FilterWalkerCtx is FilterCtx evaluating with the walker and the session, e.g. to resolve RelOperator.
+func FilterWalkerCtx[T any](
+	ctx context.Context, w *EvaluateWalker, s session.Session, query IQueryOperator, states []T,
+) ([]T, error) {
+	var result []T
+	for i, state := range states {
+		if i%filterCheckInterval == 0 {
+			if err := context.Cause(ctx); err != nil {
+				return result, &PartialResultError{Evaluated: i, Total: len(states), Err: err}
+			}
+		}
+		matched, err := w.EvaluateContext(ctx, s, query, state)
+		if err != nil {
+			// Relations are not resolved once the context is done, see EvaluateContext.
+			if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+				return result, &PartialResultError{Evaluated: i, Total: len(states), Err: context.Cause(ctx)}
+			}
+			return result, err
+		}
+		if matched {
+			result = append(result, state)
+		}
+	}
+	return result, nil
+}
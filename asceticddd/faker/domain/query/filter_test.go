@@ -0,0 +1,89 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+// cancellingObjectResolver cancels the context on the given resolve.
+type cancellingObjectResolver struct {
+	calls    int
+	cancelAt int
+	cancel   context.CancelCauseFunc
+}
+
+func (r *cancellingObjectResolver) Resolve(s session.Session, field *string, fkValue any) (map[string]any, IObjectResolver, error) {
+	r.calls++
+	if r.calls == r.cancelAt {
+		r.cancel(context.DeadlineExceeded)
+	}
+	return map[string]any{"type": "tech"}, nil, nil
+}
+
+func (r *cancellingObjectResolver) Descend(field string) IObjectResolver {
+	return r
+}
+
+func TestFilterCtx(t *testing.T) {
+	states := make([]map[string]any, 1000)
+	for i := range states {
+		states[i] = map[string]any{"id": i, "even": i%2 == 0, "company_id": i}
+	}
+
+	t.Run("returns matching states in order", func(t *testing.T) {
+		query := CompositeQuery{Fields: map[string]IQueryOperator{"even": EqOperator{Value: true}}}
+		result, err := FilterCtx(context.Background(), query, states)
+		assert.NoError(t, err)
+		assert.Len(t, result, 500)
+		assert.Equal(t, 0, result[0]["id"])
+		assert.Equal(t, 998, result[499]["id"])
+	})
+
+	t.Run("returns partial result at the resolution after cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		resolver := &cancellingObjectResolver{cancelAt: 100, cancel: cancel}
+		query := CompositeQuery{Fields: map[string]IQueryOperator{
+			"company_id": RelOperator{Query: CompositeQuery{Fields: map[string]IQueryOperator{
+				"type": EqOperator{Value: "tech"},
+			}}},
+		}}
+
+		result, err := FilterWalkerCtx(ctx, NewEvaluateWalker(resolver), nil, query, states)
+
+		var partial *PartialResultError
+		assert.True(t, errors.As(err, &partial))
+		assert.Equal(t, 100, partial.Evaluated)
+		assert.Equal(t, 1000, partial.Total)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Len(t, result, 100)
+	})
+
+	t.Run("resolves relations with the context", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), contextKey{}, "filter")
+		resolver := &contextRecordingResolver{state: map[string]any{"type": "tech"}}
+		query := CompositeQuery{Fields: map[string]IQueryOperator{
+			"company_id": RelOperator{Query: CompositeQuery{Fields: map[string]IQueryOperator{
+				"type": EqOperator{Value: "tech"},
+			}}},
+		}}
+
+		result, err := FilterWalkerCtx(ctx, NewEvaluateWalker(resolver), &mockSession{}, query, states[:2])
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+		assert.Len(t, resolver.contexts, 2)
+		assert.Equal(t, "filter", resolver.contexts[0].Value(contextKey{}))
+	})
+
+	t.Run("done context evaluates nothing", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		result, err := FilterCtx(ctx, EqOperator{Value: 1}, []int{1, 2})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, result)
+	})
+}
@@ -0,0 +1,283 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var ErrFieldNotAllowed = errors.New("field is not allowed in filter")
+
+// GraphQLFilterTranslator translates a conventional GraphQL where-input into IQueryOperator:
+//
+//	{
+//	  "status": {"eq": "active"},
+//	  "age": {"gte": 18, "lt": 65},
+//	  "company": {"type": {"in": ["tech", "retail"]}},
+//	  "OR": [{"name": {"eq": "Alice"}}, {"email": {"isNull": true}}]
+//	}
+//
+// Field operators: eq, ne (neq), gt, gte, lt, lte, in, nin (notIn), isNull, not,
+// some and every (for arrays of objects).
+// Logical operators: AND and OR (lists of filters), NOT (a filter).
+// A nested object of fields filters a nested field, or the related aggregate if the field is set by SetRelations.
+type GraphQLFilterTranslator struct {
+	allowed   map[string]struct{}
+	relations map[string]struct{}
+}
+
+func NewGraphQLFilterTranslator() *GraphQLFilterTranslator {
+	return &GraphQLFilterTranslator{relations: map[string]struct{}{}}
+}
+
+// SetAllowedFields restricts filters to the fields, as dotted paths, e.g. "company.type".
+// Allowing a field allows its nested fields. By default all fields are allowed.
+func (t *GraphQLFilterTranslator) SetAllowedFields(fields ...string) {
+	t.allowed = make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		t.allowed[field] = struct{}{}
+	}
+}
+
+// SetRelations marks the fields, as dotted paths, referencing other aggregates,
+// so that their nested filters are translated into RelOperator.
+func (t *GraphQLFilterTranslator) SetRelations(fields ...string) {
+	for _, field := range fields {
+		t.relations[field] = struct{}{}
+	}
+}
+
+func (t *GraphQLFilterTranslator) Translate(filter map[string]any) (IQueryOperator, error) {
+	return t.filter(nil, filter)
+}
+
+// filter translates an object of fields and logical operators at the path.
+func (t *GraphQLFilterTranslator) filter(path []string, filter map[string]any) (IQueryOperator, error) {
+	if len(filter) == 0 {
+		return nil, fmt.Errorf("%s: empty filter", graphQLPath(path))
+	}
+	keys := sortedKeys(filter)
+	fields := map[string]IQueryOperator{}
+	var operands []IQueryOperator
+	for _, key := range keys {
+		value := filter[key]
+		switch key {
+		case "AND", "OR":
+			items, err := t.filterList(path, key, value)
+			if err != nil {
+				return nil, err
+			}
+			if key == "AND" || len(items) == 1 {
+				operands = append(operands, items...)
+			} else {
+				operands = append(operands, OrOperator{Operands: items})
+			}
+		case "NOT":
+			m, ok := value.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s: NOT value must be object, got: %T", graphQLPath(path), value)
+			}
+			inner, err := t.filter(path, m)
+			if err != nil {
+				return nil, err
+			}
+			operands = append(operands, NotOperator{Operand: inner})
+		default:
+			op, err := t.field(append(path[:len(path):len(path)], key), value)
+			if err != nil {
+				return nil, err
+			}
+			fields[key] = op
+		}
+	}
+	if len(fields) > 0 {
+		operands = append([]IQueryOperator{CompositeQuery{Fields: fields}}, operands...)
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return AndOperator{Operands: operands}, nil
+}
+
+func (t *GraphQLFilterTranslator) filterList(path []string, key string, value any) ([]IQueryOperator, error) {
+	list, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: %s value must be list, got: %T", graphQLPath(path), key, value)
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("%s: %s requires at least 1 filter", graphQLPath(path), key)
+	}
+	items := make([]IQueryOperator, len(list))
+	for i, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: %s[%d] must be object, got: %T", graphQLPath(path), key, i, item)
+		}
+		op, err := t.filter(path, m)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = op
+	}
+	return items, nil
+}
+
+// field translates the input of the field at the path: operators, or an object of nested fields.
+func (t *GraphQLFilterTranslator) field(path []string, value any) (IQueryOperator, error) {
+	m, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: filter must be object, got: %T", graphQLPath(path), value)
+	}
+	operators := 0
+	for key := range m {
+		if _, ok := graphQLFieldOperators[key]; ok {
+			operators++
+		}
+	}
+	switch {
+	case operators == 0:
+		return t.nested(path, m)
+	case operators < len(m):
+		return nil, fmt.Errorf("%s: cannot mix operators and fields, got: %v", graphQLPath(path), sortedKeys(m))
+	}
+	return t.operators(path, m)
+}
+
+func (t *GraphQLFilterTranslator) nested(path []string, filter map[string]any) (IQueryOperator, error) {
+	if _, ok := t.relations[graphQLPath(path)]; !ok {
+		return t.filter(path, filter)
+	}
+	op, err := t.filter(path, filter)
+	if err != nil {
+		return nil, err
+	}
+	query, ok := op.(CompositeQuery)
+	if !ok {
+		return nil, fmt.Errorf("%s: relation filter supports fields only", graphQLPath(path))
+	}
+	return RelOperator{Query: query}, nil
+}
+
+var graphQLFieldOperators = map[string]struct{}{
+	"eq": {}, "ne": {}, "neq": {}, "gt": {}, "gte": {}, "lt": {}, "lte": {},
+	"in": {}, "nin": {}, "notIn": {}, "isNull": {}, "not": {}, "some": {}, "every": {},
+}
+
+var graphQLComparisons = map[string]string{
+	"ne": "$ne", "neq": "$ne", "gt": "$gt", "gte": "$gte", "lt": "$lt", "lte": "$lte",
+}
+
+func (t *GraphQLFilterTranslator) operators(path []string, ops map[string]any) (IQueryOperator, error) {
+	field := graphQLPath(path)
+	var operands []IQueryOperator
+	for _, name := range sortedKeys(ops) {
+		value := ops[name]
+		// Fields of elements are checked by themselves, e.g. "items.price".
+		if name != "some" && name != "every" {
+			if err := t.checkAllowed(path); err != nil {
+				return nil, err
+			}
+		}
+		var op IQueryOperator
+		switch name {
+		case "eq":
+			op = EqOperator{Value: value}
+		case "ne", "neq", "gt", "gte", "lt", "lte":
+			op = ComparisonOperator{Op: graphQLComparisons[name], Value: value}
+		case "in", "nin", "notIn":
+			list, ok := value.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s: %s value must be list, got: %T", field, name, value)
+			}
+			if len(list) == 0 {
+				return nil, fmt.Errorf("%s: %s requires at least 1 value", field, name)
+			}
+			op = InOperator{Values: append([]any(nil), list...)}
+			if name != "in" {
+				op = NotOperator{Operand: op}
+			}
+		case "isNull":
+			b, ok := value.(bool)
+			if !ok {
+				return nil, fmt.Errorf("%s: isNull value must be bool, got: %T", field, value)
+			}
+			op = IsNullOperator{Value: b}
+		case "not":
+			m, ok := value.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s: not value must be object, got: %T", field, value)
+			}
+			inner, err := t.field(path, m)
+			if err != nil {
+				return nil, err
+			}
+			op = NotOperator{Operand: inner}
+		case "some", "every":
+			m, ok := value.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s: %s value must be object, got: %T", field, name, value)
+			}
+			inner, err := t.filter(path, m)
+			if err != nil {
+				return nil, err
+			}
+			if name == "some" {
+				op = AnyElementOperator{Query: inner}
+			} else {
+				op = AllElementsOperator{Query: inner}
+			}
+		default:
+			return nil, fmt.Errorf("%s: unknown operator: %s", field, name)
+		}
+		operands = append(operands, op)
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return AndOperator{Operands: operands}, nil
+}
+
+func (t *GraphQLFilterTranslator) checkAllowed(path []string) error {
+	if !allowsPath(t.allowed, path) {
+		return fmt.Errorf("%s: %w", graphQLPath(path), ErrFieldNotAllowed)
+	}
+	return nil
+}
+
+// allowsPath checks the path or one of its parents is allowed, nil allows all paths.
+func allowsPath(allowed map[string]struct{}, path []string) bool {
+	if allowed == nil {
+		return true
+	}
+	for i := len(path); i > 0; i-- {
+		if _, ok := allowed[strings.Join(path[:i], ".")]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// graphQLPath renders the path for errors, "$" is the root.
+func graphQLPath(path []string) string {
+	if len(path) == 0 {
+		return "$"
+	}
+	return strings.Join(path, ".")
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var defaultGraphQLFilterTranslator = NewGraphQLFilterTranslator()
+
+// TranslateGraphQLFilter translates a GraphQL where-input allowing all fields.
+func TranslateGraphQLFilter(filter map[string]any) (IQueryOperator, error) {
+	return defaultGraphQLFilterTranslator.Translate(filter)
+}
@@ -0,0 +1,223 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslateGraphQLFilter(t *testing.T) {
+	cases := []struct {
+		name     string
+		filter   map[string]any
+		expected map[string]any
+	}{
+		{
+			"fields",
+			map[string]any{"status": map[string]any{"eq": "active"}, "age": map[string]any{"gt": 18}},
+			map[string]any{"status": "active", "age": map[string]any{"$gt": 18}},
+		},
+		{
+			"in",
+			map[string]any{"status": map[string]any{"in": []any{"a", "b"}}},
+			map[string]any{"status": map[string]any{"$in": []any{"a", "b"}}},
+		},
+		{
+			"nin",
+			map[string]any{"status": map[string]any{"nin": []any{"a"}}},
+			map[string]any{"status": map[string]any{"$not": map[string]any{"$in": []any{"a"}}}},
+		},
+		{
+			"is null",
+			map[string]any{"email": map[string]any{"isNull": true}},
+			map[string]any{"email": map[string]any{"$is_null": true}},
+		},
+		{
+			"not",
+			map[string]any{"age": map[string]any{"not": map[string]any{"gt": 18}}},
+			map[string]any{"age": map[string]any{"$not": map[string]any{"$gt": 18}}},
+		},
+		{
+			"nested field",
+			map[string]any{"profile": map[string]any{"score": map[string]any{"gt": 5}}},
+			map[string]any{"profile": map[string]any{"score": map[string]any{"$gt": 5}}},
+		},
+		{
+			"some",
+			map[string]any{"items": map[string]any{"some": map[string]any{"price": map[string]any{"lt": 10}}}},
+			map[string]any{"items": map[string]any{"$any": map[string]any{"price": map[string]any{"$lt": 10}}}},
+		},
+		{
+			"every",
+			map[string]any{"items": map[string]any{"every": map[string]any{"active": map[string]any{"eq": true}}}},
+			map[string]any{"items": map[string]any{"$all": map[string]any{"active": true}}},
+		},
+		{
+			"or",
+			map[string]any{"OR": []any{
+				map[string]any{"status": map[string]any{"eq": "active"}},
+				map[string]any{"age": map[string]any{"gt": 18}},
+			}},
+			map[string]any{"$or": []any{
+				map[string]any{"status": "active"},
+				map[string]any{"age": map[string]any{"$gt": 18}},
+			}},
+		},
+		{
+			"or of one",
+			map[string]any{"OR": []any{map[string]any{"status": map[string]any{"eq": "active"}}}},
+			map[string]any{"status": "active"},
+		},
+		{
+			"not filter",
+			map[string]any{"NOT": map[string]any{"status": map[string]any{"eq": "deleted"}}},
+			map[string]any{"$not": map[string]any{"status": "deleted"}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			expected, err := ParseQuery(c.expected)
+			assert.NoError(t, err)
+			actual, err := TranslateGraphQLFilter(c.filter)
+			assert.NoError(t, err)
+			assert.True(t, expected.Equal(actual), "expected %v, got %v", expected, actual)
+		})
+	}
+}
+
+func TestTranslateGraphQLFilterComparisons(t *testing.T) {
+	actual, err := TranslateGraphQLFilter(map[string]any{
+		"age": map[string]any{"lte": 64, "gt": 17, "ne": 30, "gte": 18, "neq": 40, "lt": 65},
+	})
+	assert.NoError(t, err)
+	// Operators are combined in the order of names.
+	expected := CompositeQuery{Fields: map[string]IQueryOperator{"age": AndOperator{Operands: []IQueryOperator{
+		ComparisonOperator{Op: "$gt", Value: 17},
+		ComparisonOperator{Op: "$gte", Value: 18},
+		ComparisonOperator{Op: "$lt", Value: 65},
+		ComparisonOperator{Op: "$lte", Value: 64},
+		ComparisonOperator{Op: "$ne", Value: 30},
+		ComparisonOperator{Op: "$ne", Value: 40},
+	}}}}
+	assert.True(t, expected.Equal(actual), "got %v", actual)
+}
+
+func TestTranslateGraphQLFilterLogical(t *testing.T) {
+	actual, err := TranslateGraphQLFilter(map[string]any{
+		"status": map[string]any{"eq": "active"},
+		"AND": []any{
+			map[string]any{"age": map[string]any{"gte": 18}},
+			map[string]any{"age": map[string]any{"lt": 65}},
+		},
+		"OR": []any{
+			map[string]any{"name": map[string]any{"eq": "Alice"}},
+			map[string]any{"name": map[string]any{"eq": "Bob"}},
+		},
+	})
+	assert.NoError(t, err)
+	expected := AndOperator{Operands: []IQueryOperator{
+		CompositeQuery{Fields: map[string]IQueryOperator{"status": EqOperator{Value: "active"}}},
+		CompositeQuery{Fields: map[string]IQueryOperator{"age": ComparisonOperator{Op: "$gte", Value: 18}}},
+		CompositeQuery{Fields: map[string]IQueryOperator{"age": ComparisonOperator{Op: "$lt", Value: 65}}},
+		OrOperator{Operands: []IQueryOperator{
+			CompositeQuery{Fields: map[string]IQueryOperator{"name": EqOperator{Value: "Alice"}}},
+			CompositeQuery{Fields: map[string]IQueryOperator{"name": EqOperator{Value: "Bob"}}},
+		}},
+	}}
+	assert.True(t, expected.Equal(actual), "got %v", actual)
+
+	cases := []struct {
+		state    map[string]any
+		expected bool
+	}{
+		{map[string]any{"status": "active", "age": 30, "name": "Alice"}, true},
+		{map[string]any{"status": "active", "age": 70, "name": "Alice"}, false},
+		{map[string]any{"status": "active", "age": 30, "name": "Carol"}, false},
+		{map[string]any{"status": "blocked", "age": 30, "name": "Bob"}, false},
+	}
+	for _, c := range cases {
+		result, err := evalVisitor(c.state, actual, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, result, "%v", c.state)
+	}
+}
+
+func TestGraphQLFilterTranslatorRelations(t *testing.T) {
+	translator := NewGraphQLFilterTranslator()
+	translator.SetRelations("company")
+
+	actual, err := translator.Translate(map[string]any{
+		"company": map[string]any{"type": map[string]any{"eq": "tech"}},
+	})
+	assert.NoError(t, err)
+	expected, _ := ParseQuery(map[string]any{"company": map[string]any{"$rel": map[string]any{"type": "tech"}}})
+	assert.True(t, expected.Equal(actual), "got %v", actual)
+
+	_, err = translator.Translate(map[string]any{
+		"company": map[string]any{"OR": []any{
+			map[string]any{"type": map[string]any{"eq": "tech"}},
+			map[string]any{"type": map[string]any{"eq": "retail"}},
+		}},
+	})
+	assert.ErrorContains(t, err, "company: relation filter supports fields only")
+}
+
+func TestGraphQLFilterTranslatorAllowedFields(t *testing.T) {
+	translator := NewGraphQLFilterTranslator()
+	translator.SetAllowedFields("status", "company.type", "items.price")
+	translator.SetRelations("company")
+
+	allowed := []map[string]any{
+		{"status": map[string]any{"eq": "active"}},
+		{"company": map[string]any{"type": map[string]any{"eq": "tech"}}},
+		{"items": map[string]any{"some": map[string]any{"price": map[string]any{"lt": 10}}}},
+		{"OR": []any{
+			map[string]any{"status": map[string]any{"eq": "a"}},
+			map[string]any{"status": map[string]any{"eq": "b"}},
+		}},
+	}
+	for _, filter := range allowed {
+		_, err := translator.Translate(filter)
+		assert.NoError(t, err, "%v", filter)
+	}
+
+	cases := []struct {
+		filter map[string]any
+		path   string
+	}{
+		{map[string]any{"password": map[string]any{"eq": "secret"}}, "password"},
+		{map[string]any{"company": map[string]any{"revenue": map[string]any{"gt": 1}}}, "company.revenue"},
+		{map[string]any{"items": map[string]any{"some": map[string]any{"cost": map[string]any{"gt": 1}}}}, "items.cost"},
+		{map[string]any{"NOT": map[string]any{"password": map[string]any{"isNull": true}}}, "password"},
+	}
+	for _, c := range cases {
+		_, err := translator.Translate(c.filter)
+		assert.ErrorIs(t, err, ErrFieldNotAllowed)
+		assert.ErrorContains(t, err, c.path+": ")
+	}
+}
+
+func TestTranslateGraphQLFilterErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		filter map[string]any
+		err    string
+	}{
+		{"empty", map[string]any{}, "$: empty filter"},
+		{"scalar field", map[string]any{"status": "active"}, "status: filter must be object, got: string"},
+		{"unknown operator", map[string]any{"name": map[string]any{"eq": "a", "like": "b%"}}, "name: cannot mix operators and fields"},
+		{"in not list", map[string]any{"status": map[string]any{"in": "a"}}, "status: in value must be list, got: string"},
+		{"in empty", map[string]any{"status": map[string]any{"in": []any{}}}, "status: in requires at least 1 value"},
+		{"is null not bool", map[string]any{"email": map[string]any{"isNull": "yes"}}, "email: isNull value must be bool"},
+		{"or not list", map[string]any{"OR": map[string]any{}}, "$: OR value must be list"},
+		{"or empty", map[string]any{"OR": []any{}}, "$: OR requires at least 1 filter"},
+		{"or item", map[string]any{"OR": []any{"a"}}, "$: OR[0] must be object"},
+		{"nested empty", map[string]any{"profile": map[string]any{}}, "profile: empty filter"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := TranslateGraphQLFilter(c.filter)
+			assert.ErrorContains(t, err, c.err)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+var ErrUnknownField = errors.New("unknown field")
+
+// UnknownFieldError is a queried field absent from the state, see WithStrictFields and LintQuery.
+type UnknownFieldError struct {
+	// Path is the dot-separated path of the field, with [*] for array elements.
+	Path string
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrUnknownField, e.Path)
+}
+
+func (e *UnknownFieldError) Is(target error) bool {
+	return target == ErrUnknownField
+}
+
+// LintQuery validates the fields of the query against the sample state, e.g. a typical state from a fixture,
+// or against the struct type, e.g. reflect.TypeFor[Order]().
+// It returns *UnknownFieldError of every unknown field, joined with errors.Join.
+// Fields of maps of a struct type, of interfaces and of related aggregates ($rel) are not validated,
+// nor elements of an empty sample array.
+func LintQuery(query IQueryOperator, sample any) error {
+	var errs []error
+	lintQuery(query, sample, nil, &errs)
+	return errors.Join(errs...)
+}
+
+func lintQuery(query IQueryOperator, sample any, path []string, errs *[]error) {
+	switch q := query.(type) {
+	case CompositeQuery:
+		for _, field := range slices.Sorted(maps.Keys(q.Fields)) {
+			fieldSample, known, found := sampleField(sample, field)
+			fieldPath := append(slices.Clone(path), field)
+			if !found {
+				*errs = append(*errs, &UnknownFieldError{Path: fieldTypePath(fieldPath)})
+			} else if known {
+				lintQuery(q.Fields[field], fieldSample, fieldPath, errs)
+			}
+		}
+	case AndOperator:
+		for _, operand := range q.Operands {
+			lintQuery(operand, sample, path, errs)
+		}
+	case OrOperator:
+		for _, operand := range q.Operands {
+			lintQuery(operand, sample, path, errs)
+		}
+	case NotOperator:
+		lintQuery(q.Operand, sample, path, errs)
+	case AnyElementOperator:
+		if element, known := sampleElement(sample); known {
+			lintQuery(q.Query, element, append(slices.Clone(path), arrayElementsKey), errs)
+		}
+	case AllElementsOperator:
+		if element, known := sampleElement(sample); known {
+			lintQuery(q.Query, element, append(slices.Clone(path), arrayElementsKey), errs)
+		}
+	}
+}
+
+// sampleField returns the sample of the field, a value or a reflect.Type,
+// known is false when the fields of the sample cannot be validated.
+func sampleField(sample any, field string) (fieldSample any, known bool, found bool) {
+	if sample == nil {
+		return nil, false, true
+	}
+	if m, ok := sample.(map[string]any); ok {
+		value, found := m[field]
+		return value, value != nil, found
+	}
+	t, ok := sample.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(sample)
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, false, true
+	}
+	index, found := lookupStructField(t, field)
+	if !found {
+		return nil, false, false
+	}
+	return t.Field(index).Type, true, true
+}
+
+// sampleElement returns the sample of the array elements, a value or a reflect.Type.
+func sampleElement(sample any) (any, bool) {
+	t, ok := sample.(reflect.Type)
+	if !ok {
+		items, ok := toSlice(sample)
+		if !ok || len(items) == 0 {
+			return nil, false
+		}
+		return items[0], items[0] != nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return nil, false
+	}
+	return t.Elem(), true
+}
@@ -0,0 +1,99 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type lintOrder struct {
+	Status string          `json:"status"`
+	Total  float64         `json:"total"`
+	Items  []lintOrderItem `json:"items"`
+	Meta   map[string]any  `json:"meta"`
+}
+
+type lintOrderItem struct {
+	Sku string
+}
+
+func TestStrictFields(t *testing.T) {
+	query := CompositeQuery{Fields: map[string]IQueryOperator{
+		"profile": CompositeQuery{Fields: map[string]IQueryOperator{
+			"emial": EqOperator{Value: "a@example.com"},
+		}},
+	}}
+	state := map[string]any{"profile": map[string]any{"email": "a@example.com", "phone": nil}}
+
+	t.Run("lenient by default", func(t *testing.T) {
+		result, err := NewEvaluateWalker(nil).EvaluateSync(query, state)
+		require.NoError(t, err)
+		assert.False(t, result)
+	})
+
+	t.Run("strict fails on unknown field", func(t *testing.T) {
+		walker := NewEvaluateWalker(nil, WithStrictFields())
+
+		_, err := walker.EvaluateSync(query, state)
+		assert.ErrorIs(t, err, ErrUnknownField)
+		var unknownErr *UnknownFieldError
+		require.ErrorAs(t, err, &unknownErr)
+		assert.Equal(t, "profile.emial", unknownErr.Path)
+
+		_, err = walker.Evaluate(&mockSession{}, CompositeQuery{Fields: map[string]IQueryOperator{
+			"tags": AnyElementOperator{Query: CompositeQuery{Fields: map[string]IQueryOperator{
+				"nmae": EqOperator{Value: "sale"},
+			}}},
+		}}, map[string]any{"tags": []any{map[string]any{"name": "sale"}}})
+		assert.EqualError(t, err, "unknown field: tags[*].nmae")
+	})
+
+	t.Run("strict matches null fields", func(t *testing.T) {
+		walker := NewEvaluateWalker(nil, WithStrictFields())
+		result, err := walker.EvaluateSync(CompositeQuery{Fields: map[string]IQueryOperator{
+			"profile": CompositeQuery{Fields: map[string]IQueryOperator{
+				"phone": IsNullOperator{Value: true},
+			}},
+		}}, state)
+		require.NoError(t, err)
+		assert.True(t, result)
+	})
+}
+
+func TestLintQuery(t *testing.T) {
+	query := CompositeQuery{Fields: map[string]IQueryOperator{
+		"status": EqOperator{Value: "paid"},
+		"totl":   ComparisonOperator{Op: "$gt", Value: 10},
+		"items": AnyElementOperator{Query: OrOperator{Operands: []IQueryOperator{
+			CompositeQuery{Fields: map[string]IQueryOperator{"Sku": EqOperator{Value: "A"}}},
+			CompositeQuery{Fields: map[string]IQueryOperator{"sku": EqOperator{Value: "B"}}},
+		}}},
+		"meta": CompositeQuery{Fields: map[string]IQueryOperator{"anything": EqOperator{Value: 1}}},
+	}}
+
+	t.Run("struct type", func(t *testing.T) {
+		err := LintQuery(query, reflect.TypeFor[lintOrder]())
+		assert.EqualError(t, err, "unknown field: items[*].sku\nunknown field: totl")
+		assert.True(t, errors.Is(err, ErrUnknownField))
+	})
+
+	t.Run("sample state", func(t *testing.T) {
+		sample := map[string]any{
+			"status": "paid",
+			"total":  12.5,
+			"items":  []any{map[string]any{"sku": "A"}},
+			"meta":   nil,
+		}
+		err := LintQuery(query, sample)
+		assert.EqualError(t, err, "unknown field: items[*].Sku\nunknown field: totl")
+	})
+
+	t.Run("valid query", func(t *testing.T) {
+		assert.NoError(t, LintQuery(CompositeQuery{Fields: map[string]IQueryOperator{
+			"status": InOperator{Values: []any{"paid", "shipped"}},
+		}}, &lintOrder{}))
+	})
+}
@@ -0,0 +1,106 @@
+package query
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+// NumericCoercion tells how EvaluateWalker and EvaluateVisitor compare numbers of different Go types.
+type NumericCoercion int
+
+const (
+	// NumericCoercionLoose compares int, int64, float64, json.Number and other numbers by value,
+	// e.g. EqOperator{Value: 42} matches float64(42) decoded by encoding/json.
+	// A numeric string compares by value with a number, but two strings compare as strings.
+	NumericCoercionLoose NumericCoercion = iota
+	// NumericCoercionStrict compares values of the same Go type only.
+	NumericCoercionStrict
+)
+
+// Coerce converts the operands of a comparison to int64, when both are integers,
+// or to float64, when one of them is a number and the other is a number or a numeric string.
+// Operands of the same Go type, other than json.Number, are returned unchanged.
+func (c NumericCoercion) Coerce(actual, expected any) (any, any) {
+	if c == NumericCoercionStrict || actual == nil || expected == nil {
+		return actual, expected
+	}
+	if reflect.TypeOf(actual) == reflect.TypeOf(expected) {
+		if _, ok := actual.(json.Number); !ok {
+			return actual, expected
+		}
+	}
+	_, actualIsString := actual.(string)
+	_, expectedIsString := expected.(string)
+	if actualIsString && expectedIsString {
+		return actual, expected
+	}
+	if a, ok := toInt64(actual); ok {
+		if e, ok := toInt64(expected); ok {
+			return a, e
+		}
+	}
+	a, ok := toFloat64(actual)
+	if !ok {
+		return actual, expected
+	}
+	e, ok := toFloat64(expected)
+	if !ok {
+		return actual, expected
+	}
+	return a, e
+}
+
+// Equal reports whether the values are deeply equal after Coerce.
+func (c NumericCoercion) Equal(actual, expected any) bool {
+	return c.equalWithin(actual, expected, nil)
+}
+
+// equalWithin is Equal with float64 numbers compared within the tolerance, if any.
+func (c NumericCoercion) equalWithin(actual, expected any, tolerance *operators.FloatTolerance) bool {
+	actual, expected = c.Coerce(actual, expected)
+	if tolerance != nil {
+		if a, ok := actual.(float64); ok {
+			if e, ok := expected.(float64); ok {
+				return tolerance.Equal(a, e)
+			}
+		}
+	}
+	return reflect.DeepEqual(actual, expected)
+}
+
+func toInt64(value any) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), uint64(v) <= math.MaxInt64
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), v <= math.MaxInt64
+	case json.Number:
+		i, err := v.Int64()
+		return i, err == nil
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
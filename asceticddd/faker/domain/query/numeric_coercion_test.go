@@ -0,0 +1,225 @@
+package query
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+func TestNumericCoercion(t *testing.T) {
+	cases := []struct {
+		name     string
+		actual   any
+		expected any
+		equal    bool
+	}{
+		{"int and float64", 42, float64(42), true},
+		{"int and fractional float64", 42, 42.5, false},
+		{"int and int64", 42, int64(42), true},
+		{"uint8 and int", uint8(7), 7, true},
+		{"json.Number and int", json.Number("42"), 42, true},
+		{"json.Number and float64", json.Number("1.5"), 1.5, true},
+		{"json.Numbers", json.Number("1.0"), json.Number("1"), true},
+		{"numeric string and int", "42", 42, true},
+		{"numeric string and float64", " 2.5 ", 2.5, true},
+		{"strings compare as strings", "42", "42.0", false},
+		{"non-numeric string", "abc", 42, false},
+		{"large uint64", uint64(math.MaxUint64), int64(-1), false},
+		{"nil", nil, 0, false},
+		{"bool", true, 1, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.equal, NumericCoercionLoose.Equal(c.actual, c.expected))
+		})
+	}
+
+	t.Run("integers keep precision", func(t *testing.T) {
+		actual, expected := NumericCoercionLoose.Coerce(int64(math.MaxInt64), json.Number("9223372036854775807"))
+		assert.Equal(t, int64(math.MaxInt64), actual)
+		assert.Equal(t, int64(math.MaxInt64), expected)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		assert.False(t, NumericCoercionStrict.Equal(42, float64(42)))
+		assert.False(t, NumericCoercionStrict.Equal("42", 42))
+		assert.True(t, NumericCoercionStrict.Equal(42, 42))
+		actual, expected := NumericCoercionStrict.Coerce(42, float64(42))
+		assert.Equal(t, 42, actual)
+		assert.Equal(t, float64(42), expected)
+	})
+}
+
+func TestEvaluateWalkerNumericCoercion(t *testing.T) {
+	var state map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{"age": 42, "score": 7.5, "tags": [1, 2]}`), &state))
+	cases := []struct {
+		name     string
+		query    map[string]any
+		expected bool
+	}{
+		{"eq", map[string]any{"age": 42}, true},
+		{"ne", map[string]any{"age": map[string]any{"$ne": 42}}, false},
+		{"gt", map[string]any{"score": map[string]any{"$gt": 7}}, true},
+		{"lte int64", map[string]any{"age": map[string]any{"$lte": int64(42)}}, true},
+		{"in", map[string]any{"age": map[string]any{"$in": []any{1, 42}}}, true},
+		{"between", map[string]any{"score": map[string]any{"$between": []any{7, 8}}}, true},
+		{"any element", map[string]any{"tags": map[string]any{"$any": map[string]any{"$eq": 2}}}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			query, err := ParseQuery(c.query)
+			require.NoError(t, err)
+
+			walker := NewEvaluateWalker(nil)
+			result, err := walker.Evaluate(sess, query, state)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, result)
+
+			result, err = walker.EvaluateSync(query, state)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, result)
+		})
+	}
+
+	t.Run("related", func(t *testing.T) {
+		resolver := makeResolver(map[string]relInfo{
+			"status_id": {storage: map[any]map[string]any{"paid": {"rank": float64(4)}}, resolver: makeResolver(nil)},
+		})
+		query := CompositeQuery{Fields: map[string]IQueryOperator{
+			"status_id": RelOperator{Query: CompositeQuery{Fields: map[string]IQueryOperator{
+				"rank": EqOperator{Value: 4},
+			}}},
+		}}
+
+		walker := NewEvaluateWalker(resolver)
+		result, err := walker.Evaluate(sess, query, map[string]any{"status_id": "paid"})
+		require.NoError(t, err)
+		assert.True(t, result)
+
+		walker.SetNumericCoercion(NumericCoercionStrict)
+		result, err = walker.Evaluate(sess, query, map[string]any{"status_id": "paid"})
+		require.NoError(t, err)
+		assert.False(t, result)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		walker := NewEvaluateWalker(nil)
+		walker.SetNumericCoercion(NumericCoercionStrict)
+
+		result, err := walker.EvaluateSync(CompositeQuery{Fields: map[string]IQueryOperator{
+			"age": EqOperator{Value: 42},
+		}}, state)
+		require.NoError(t, err)
+		assert.False(t, result)
+
+		_, err = walker.EvaluateSync(CompositeQuery{Fields: map[string]IQueryOperator{
+			"score": ComparisonOperator{Op: "$gt", Value: 7},
+		}}, state)
+		assert.Error(t, err)
+	})
+}
+
+func TestEvaluateVisitorNumericCoercion(t *testing.T) {
+	t.Run("loose", func(t *testing.T) {
+		r, err := evalVisitor(float64(42), EqOperator{Value: 42}, nil)
+		require.NoError(t, err)
+		assert.True(t, r)
+
+		r, err = evalVisitor(json.Number("7.5"), ComparisonOperator{Op: "$gt", Value: 7}, nil)
+		require.NoError(t, err)
+		assert.True(t, r)
+
+		r, err = evalVisitor(int64(3), InOperator{Values: []any{1, 3}}, nil)
+		require.NoError(t, err)
+		assert.True(t, r)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		visitor := NewEvaluateVisitor(float64(42), sess, nil)
+		visitor.SetNumericCoercion(NumericCoercionStrict)
+		result, err := EqOperator{Value: 42}.Accept(visitor)
+		require.NoError(t, err)
+		assert.Equal(t, false, result)
+	})
+}
+
+func TestFloatTolerance(t *testing.T) {
+	state := map[string]any{"price": 0.30000000000000004, "tags": map[string]any{"weight": 1.0000000001}}
+	byPrice := func(op IQueryOperator) IQueryOperator {
+		return CompositeQuery{Fields: map[string]IQueryOperator{"price": op}}
+	}
+
+	t.Run("exact by default", func(t *testing.T) {
+		result, err := NewEvaluateWalker(nil).EvaluateSync(byPrice(EqOperator{Value: 0.3}), state)
+		require.NoError(t, err)
+		assert.False(t, result)
+	})
+
+	t.Run("walker", func(t *testing.T) {
+		walker := NewEvaluateWalker(nil)
+		walker.SetFloatTolerance(operators.FloatTolerance{Absolute: 1e-9})
+
+		cases := []struct {
+			query    IQueryOperator
+			expected bool
+		}{
+			{byPrice(EqOperator{Value: 0.3}), true},
+			{byPrice(InOperator{Values: []any{0.1, 0.3}}), true},
+			{byPrice(ComparisonOperator{Op: "$ne", Value: 0.3}), false},
+			{byPrice(ComparisonOperator{Op: "$gt", Value: 0.3}), false},
+			{byPrice(ComparisonOperator{Op: "$lte", Value: 0.3}), true},
+			{CompositeQuery{Fields: map[string]IQueryOperator{
+				"tags": CompositeQuery{Fields: map[string]IQueryOperator{"weight": EqOperator{Value: 1}}},
+			}}, true},
+		}
+		for _, c := range cases {
+			result, err := walker.EvaluateSync(c.query, state)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, result, "%#v", c.query)
+		}
+	})
+
+	t.Run("walker of operators", func(t *testing.T) {
+		walker := NewEvaluateWalker(nil)
+		walker.SetFloatTolerance(operators.FloatTolerance{Absolute: 1e-9}, operators.OperatorGt)
+
+		result, err := walker.EvaluateSync(byPrice(EqOperator{Value: 0.3}), state)
+		require.NoError(t, err)
+		assert.False(t, result)
+
+		result, err = walker.EvaluateSync(byPrice(ComparisonOperator{Op: "$gt", Value: 0.3}), state)
+		require.NoError(t, err)
+		assert.False(t, result)
+	})
+
+	t.Run("related", func(t *testing.T) {
+		resolver := makeResolver(map[string]relInfo{
+			"product_id": {storage: map[any]map[string]any{"p1": state}, resolver: makeResolver(nil)},
+		})
+		walker := NewEvaluateWalker(resolver)
+		walker.SetFloatTolerance(operators.FloatTolerance{Absolute: 1e-9})
+
+		result, err := walker.Evaluate(sess, CompositeQuery{Fields: map[string]IQueryOperator{
+			"product_id": RelOperator{Query: CompositeQuery{Fields: map[string]IQueryOperator{
+				"price": EqOperator{Value: 0.3},
+			}}},
+		}}, map[string]any{"product_id": "p1"})
+		require.NoError(t, err)
+		assert.True(t, result)
+	})
+
+	t.Run("visitor", func(t *testing.T) {
+		visitor := NewEvaluateVisitor(0.30000000000000004, sess, nil)
+		visitor.SetFloatTolerance(operators.FloatTolerance{Absolute: 1e-9})
+		result, err := EqOperator{Value: 0.3}.Accept(visitor)
+		require.NoError(t, err)
+		assert.Equal(t, true, result)
+	})
+}
@@ -0,0 +1,441 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ODataFilterParser parses OData $filter expressions into IQueryOperator:
+//
+//	age gt 18 and status eq 'active'
+//	company/type in ('tech', 'retail') or not (email eq null)
+//	items/any(i: i/price lt 10 and i/quantity gt 0)
+//
+// Comparisons: eq, ne, gt, ge, lt, le against literals, eq null and ne null test for null,
+// in against a list of literals. Logical operators: and, or, not, with parentheses.
+// any and all with a range variable filter arrays, any() matches non-empty arrays.
+// Literals are strings in single quotes, doubled to escape, numbers, true, false and null.
+// A property path, e.g. company/type, filters a nested field,
+// or the related aggregate if the field is set by SetRelations.
+type ODataFilterParser struct {
+	allowed   map[string]struct{}
+	relations map[string]struct{}
+}
+
+func NewODataFilterParser() *ODataFilterParser {
+	return &ODataFilterParser{relations: map[string]struct{}{}}
+}
+
+// SetAllowedFields restricts filters to the fields, as dotted paths, e.g. "company.type" for company/type.
+// Allowing a field allows its nested fields. By default all fields are allowed.
+func (p *ODataFilterParser) SetAllowedFields(fields ...string) {
+	p.allowed = make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		p.allowed[field] = struct{}{}
+	}
+}
+
+// SetRelations marks the fields, as dotted paths, referencing other aggregates,
+// so that filters on their properties are translated into RelOperator.
+func (p *ODataFilterParser) SetRelations(fields ...string) {
+	for _, field := range fields {
+		p.relations[field] = struct{}{}
+	}
+}
+
+func (p *ODataFilterParser) Parse(filter string) (IQueryOperator, error) {
+	tokens, err := odataTokenize(filter)
+	if err != nil {
+		return nil, err
+	}
+	s := &odataState{parser: p, tokens: tokens}
+	op, err := s.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := s.peek(); t.kind != odataEOF {
+		return nil, s.unexpected(t, "end of filter")
+	}
+	return op, nil
+}
+
+type odataTokenKind int
+
+const (
+	odataEOF odataTokenKind = iota
+	odataIdent
+	odataLiteral
+	odataPunct
+)
+
+type odataToken struct {
+	kind  odataTokenKind
+	text  string
+	value any
+	pos   int
+}
+
+func odataTokenize(filter string) ([]odataToken, error) {
+	var tokens []odataToken
+	runes := []rune(filter)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case strings.ContainsRune("()/,:", r):
+			tokens = append(tokens, odataToken{kind: odataPunct, text: string(r), pos: i})
+			i++
+		case r == '\'':
+			var b strings.Builder
+			start := i
+			for i++; ; i++ {
+				if i == len(runes) {
+					return nil, fmt.Errorf("position %d: unterminated string", start)
+				}
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						i++
+					} else {
+						break
+					}
+				}
+				b.WriteRune(runes[i])
+			}
+			i++
+			tokens = append(tokens, odataToken{kind: odataLiteral, text: string(runes[start:i]), value: b.String(), pos: start})
+		case unicode.IsDigit(r) || r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			start := i
+			for i++; i < len(runes) && (unicode.IsDigit(runes[i]) || strings.ContainsRune(".eE+-", runes[i])); i++ {
+				if (runes[i] == '+' || runes[i] == '-') && runes[i-1] != 'e' && runes[i-1] != 'E' {
+					break
+				}
+			}
+			text := string(runes[start:i])
+			value, err := odataNumber(text)
+			if err != nil {
+				return nil, fmt.Errorf("position %d: invalid number: %s", start, text)
+			}
+			tokens = append(tokens, odataToken{kind: odataLiteral, text: text, value: value, pos: start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i++; i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_'); i++ {
+			}
+			text := string(runes[start:i])
+			switch text {
+			case "true", "false":
+				tokens = append(tokens, odataToken{kind: odataLiteral, text: text, value: text == "true", pos: start})
+			case "null":
+				tokens = append(tokens, odataToken{kind: odataLiteral, text: text, value: nil, pos: start})
+			default:
+				tokens = append(tokens, odataToken{kind: odataIdent, text: text, pos: start})
+			}
+		default:
+			return nil, fmt.Errorf("position %d: unexpected character: %q", i, r)
+		}
+	}
+	return append(tokens, odataToken{kind: odataEOF, pos: len(runes)}), nil
+}
+
+// odataNumber returns int for integers and float64 for decimals.
+func odataNumber(text string) (any, error) {
+	if !strings.ContainsAny(text, ".eE") {
+		if n, err := strconv.Atoi(text); err == nil {
+			return n, nil
+		}
+	}
+	return strconv.ParseFloat(text, 64)
+}
+
+var odataComparisons = map[string]string{
+	"ne": "$ne", "gt": "$gt", "ge": "$gte", "lt": "$lt", "le": "$lte",
+}
+
+// odataState is the state of one Parse.
+type odataState struct {
+	parser *ODataFilterParser
+	tokens []odataToken
+	pos    int
+	// scope is the path of the array filtered by the current lambda, variable is its range variable.
+	scope    []string
+	variable string
+}
+
+func (s *odataState) peek() odataToken {
+	return s.tokens[s.pos]
+}
+
+func (s *odataState) next() odataToken {
+	t := s.tokens[s.pos]
+	if t.kind != odataEOF {
+		s.pos++
+	}
+	return t
+}
+
+func (s *odataState) isKeyword(t odataToken, keyword string) bool {
+	return t.kind == odataIdent && t.text == keyword
+}
+
+func (s *odataState) isPunct(t odataToken, punct string) bool {
+	return t.kind == odataPunct && t.text == punct
+}
+
+func (s *odataState) expectPunct(punct string) error {
+	if t := s.next(); !s.isPunct(t, punct) {
+		return s.unexpected(t, fmt.Sprintf("%q", punct))
+	}
+	return nil
+}
+
+func (s *odataState) unexpected(t odataToken, expected string) error {
+	if t.kind == odataEOF {
+		return fmt.Errorf("position %d: expected %s, got end of filter", t.pos, expected)
+	}
+	return fmt.Errorf("position %d: expected %s, got: %s", t.pos, expected, t.text)
+}
+
+func (s *odataState) or() (IQueryOperator, error) {
+	operands, err := s.list("or", s.and)
+	if err != nil {
+		return nil, err
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return OrOperator{Operands: operands}, nil
+}
+
+func (s *odataState) and() (IQueryOperator, error) {
+	operands, err := s.list("and", s.unary)
+	if err != nil {
+		return nil, err
+	}
+	return conjoin(operands), nil
+}
+
+// list parses operands separated by the keyword.
+func (s *odataState) list(keyword string, operand func() (IQueryOperator, error)) ([]IQueryOperator, error) {
+	var operands []IQueryOperator
+	for {
+		op, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, op)
+		if !s.isKeyword(s.peek(), keyword) {
+			return operands, nil
+		}
+		s.next()
+	}
+}
+
+func (s *odataState) unary() (IQueryOperator, error) {
+	if s.isKeyword(s.peek(), "not") {
+		s.next()
+		op, err := s.unary()
+		if err != nil {
+			return nil, err
+		}
+		return NotOperator{Operand: op}, nil
+	}
+	if s.isPunct(s.peek(), "(") {
+		s.next()
+		op, err := s.or()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.expectPunct(")"); err != nil {
+			return nil, err
+		}
+		return op, nil
+	}
+	return s.comparison()
+}
+
+// comparison parses a condition on a property path: a comparison, in, or a lambda.
+func (s *odataState) comparison() (IQueryOperator, error) {
+	start := s.peek()
+	segments, lambda, err := s.path()
+	if err != nil {
+		return nil, err
+	}
+	path, err := s.relative(start, segments)
+	if err != nil {
+		return nil, err
+	}
+
+	if lambda != "" {
+		op, err := s.lambda(path, lambda)
+		if err != nil {
+			return nil, err
+		}
+		return s.wrap(path, op), nil
+	}
+
+	if len(path) == 0 && s.variable == "" {
+		return nil, s.unexpected(start, "property path")
+	}
+	if err := s.checkAllowed(path); err != nil {
+		return nil, err
+	}
+
+	t := s.next()
+	var op IQueryOperator
+	switch {
+	case s.isKeyword(t, "in"):
+		values, err := s.literals()
+		if err != nil {
+			return nil, err
+		}
+		op = InOperator{Values: values}
+	case s.isKeyword(t, "eq"), t.kind == odataIdent && odataComparisons[t.text] != "":
+		value, err := s.literal()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case value == nil && t.text == "eq":
+			op = IsNullOperator{Value: true}
+		case value == nil && t.text == "ne":
+			op = IsNullOperator{Value: false}
+		case value == nil:
+			return nil, fmt.Errorf("position %d: %s does not support null", t.pos, t.text)
+		case t.text == "eq":
+			op = EqOperator{Value: value}
+		default:
+			op = ComparisonOperator{Op: odataComparisons[t.text], Value: value}
+		}
+	default:
+		return nil, s.unexpected(t, "comparison operator")
+	}
+	return s.wrap(path, op), nil
+}
+
+// path parses a property path, and returns the name of the lambda operator ending it, if any.
+func (s *odataState) path() ([]string, string, error) {
+	var segments []string
+	for {
+		t := s.next()
+		if t.kind != odataIdent {
+			return nil, "", s.unexpected(t, "property")
+		}
+		if len(segments) > 0 && (t.text == "any" || t.text == "all") && s.isPunct(s.peek(), "(") {
+			return segments, t.text, nil
+		}
+		segments = append(segments, t.text)
+		if !s.isPunct(s.peek(), "/") {
+			return segments, "", nil
+		}
+		s.next()
+	}
+}
+
+// relative returns the path relative to the current lambda element, without the range variable.
+func (s *odataState) relative(start odataToken, segments []string) ([]string, error) {
+	if s.variable == "" {
+		return segments, nil
+	}
+	if segments[0] != s.variable {
+		return nil, fmt.Errorf("position %d: property path must start with range variable %s, got: %s",
+			start.pos, s.variable, strings.Join(segments, "/"))
+	}
+	return segments[1:], nil
+}
+
+func (s *odataState) lambda(path []string, name string) (IQueryOperator, error) {
+	if err := s.expectPunct("("); err != nil {
+		return nil, err
+	}
+	if name == "any" && s.isPunct(s.peek(), ")") {
+		s.next()
+		return LenOperator{Query: ComparisonOperator{Op: "$gt", Value: 0}}, nil
+	}
+	t := s.next()
+	if t.kind != odataIdent {
+		return nil, s.unexpected(t, "range variable")
+	}
+	if err := s.expectPunct(":"); err != nil {
+		return nil, err
+	}
+
+	scope, variable := s.scope, s.variable
+	s.scope, s.variable = append(s.full(nil), path...), t.text
+	inner, err := s.or()
+	s.scope, s.variable = scope, variable
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.expectPunct(")"); err != nil {
+		return nil, err
+	}
+	if name == "any" {
+		return AnyElementOperator{Query: inner}, nil
+	}
+	return AllElementsOperator{Query: inner}, nil
+}
+
+func (s *odataState) literals() ([]any, error) {
+	if err := s.expectPunct("("); err != nil {
+		return nil, err
+	}
+	var values []any
+	for {
+		value, err := s.literal()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		t := s.next()
+		if s.isPunct(t, ")") {
+			return values, nil
+		}
+		if !s.isPunct(t, ",") {
+			return nil, s.unexpected(t, `"," or ")"`)
+		}
+	}
+}
+
+func (s *odataState) literal() (any, error) {
+	t := s.next()
+	if t.kind != odataLiteral {
+		return nil, s.unexpected(t, "literal")
+	}
+	return t.value, nil
+}
+
+// full returns the path from the root of the filtered object.
+func (s *odataState) full(path []string) []string {
+	return append(s.scope[:len(s.scope):len(s.scope)], path...)
+}
+
+func (s *odataState) checkAllowed(path []string) error {
+	full := s.full(path)
+	if !allowsPath(s.parser.allowed, full) {
+		return fmt.Errorf("%s: %w", strings.Join(full, "/"), ErrFieldNotAllowed)
+	}
+	return nil
+}
+
+// wrap nests the operator into the fields of the path, relative to the current lambda element.
+func (s *odataState) wrap(path []string, op IQueryOperator) IQueryOperator {
+	for i := len(path) - 1; i >= 0; i-- {
+		if i < len(path)-1 {
+			if _, ok := s.parser.relations[strings.Join(s.full(path[:i+1]), ".")]; ok {
+				op = RelOperator{Query: op.(CompositeQuery)}
+			}
+		}
+		op = CompositeQuery{Fields: map[string]IQueryOperator{path[i]: op}}
+	}
+	return op
+}
+
+var defaultODataFilterParser = NewODataFilterParser()
+
+// ParseODataFilter parses an OData $filter expression allowing all fields.
+func ParseODataFilter(filter string) (IQueryOperator, error) {
+	return defaultODataFilterParser.Parse(filter)
+}
@@ -0,0 +1,199 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseODataFilter(t *testing.T) {
+	cases := []struct {
+		name     string
+		filter   string
+		expected map[string]any
+	}{
+		{
+			"and of fields",
+			"age gt 18 and status eq 'active'",
+			map[string]any{"status": "active", "age": map[string]any{"$gt": 18}},
+		},
+		{
+			"comparisons",
+			"a ne 1 and b ge 2 and c lt 3 and d le 4.5",
+			map[string]any{
+				"a": map[string]any{"$ne": 1},
+				"b": map[string]any{"$gte": 2},
+				"c": map[string]any{"$lt": 3},
+				"d": map[string]any{"$lte": 4.5},
+			},
+		},
+		{
+			"literals",
+			"name eq 'O''Brien' and active eq true and score eq -1.5e2",
+			map[string]any{"name": "O'Brien", "active": true, "score": -150.0},
+		},
+		{
+			"null",
+			"email eq null and phone ne null",
+			map[string]any{"email": map[string]any{"$is_null": true}, "phone": map[string]any{"$is_null": false}},
+		},
+		{
+			"in",
+			"status in ('a', 'b')",
+			map[string]any{"status": map[string]any{"$in": []any{"a", "b"}}},
+		},
+		{
+			"or",
+			"status eq 'active' or age gt 18",
+			map[string]any{"$or": []any{
+				map[string]any{"status": "active"},
+				map[string]any{"age": map[string]any{"$gt": 18}},
+			}},
+		},
+		{
+			"not",
+			"not (status eq 'deleted')",
+			map[string]any{"$not": map[string]any{"status": "deleted"}},
+		},
+		{
+			"property path",
+			"profile/score gt 5 and profile/level eq 2",
+			map[string]any{"profile": map[string]any{"score": map[string]any{"$gt": 5}, "level": 2}},
+		},
+		{
+			"any",
+			"items/any(i: i/price lt 10 and i/quantity gt 0)",
+			map[string]any{"items": map[string]any{"$any": map[string]any{
+				"price":    map[string]any{"$lt": 10},
+				"quantity": map[string]any{"$gt": 0},
+			}}},
+		},
+		{
+			"all of scalars",
+			"tags/all(t: t ne 'spam')",
+			map[string]any{"tags": map[string]any{"$all": map[string]any{"$ne": "spam"}}},
+		},
+		{
+			"any without lambda",
+			"tags/any()",
+			map[string]any{"tags": map[string]any{"$len": map[string]any{"$gt": 0}}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			expected, err := ParseQuery(c.expected)
+			assert.NoError(t, err)
+			actual, err := ParseODataFilter(c.filter)
+			assert.NoError(t, err)
+			assert.True(t, expected.Equal(actual), "expected %v, got %v", expected, actual)
+		})
+	}
+}
+
+func TestParseODataFilterPrecedence(t *testing.T) {
+	actual, err := ParseODataFilter("status eq 'active' and (age lt 18 or age ge 65) and age ne 30")
+	assert.NoError(t, err)
+	expected := AndOperator{Operands: []IQueryOperator{
+		CompositeQuery{Fields: map[string]IQueryOperator{
+			"status": EqOperator{Value: "active"},
+			"age":    ComparisonOperator{Op: "$ne", Value: 30},
+		}},
+		OrOperator{Operands: []IQueryOperator{
+			CompositeQuery{Fields: map[string]IQueryOperator{"age": ComparisonOperator{Op: "$lt", Value: 18}}},
+			CompositeQuery{Fields: map[string]IQueryOperator{"age": ComparisonOperator{Op: "$gte", Value: 65}}},
+		}},
+	}}
+	assert.True(t, expected.Equal(actual), "got %v", actual)
+
+	cases := []struct {
+		state    map[string]any
+		expected bool
+	}{
+		{map[string]any{"status": "active", "age": 70}, true},
+		{map[string]any{"status": "active", "age": 10}, true},
+		{map[string]any{"status": "active", "age": 40}, false},
+		{map[string]any{"status": "blocked", "age": 70}, false},
+	}
+	for _, c := range cases {
+		result, err := evalVisitor(c.state, actual, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, result, "%v", c.state)
+	}
+}
+
+func TestParseODataFilterSameField(t *testing.T) {
+	actual, err := ParseODataFilter("age gt 17 and age lt 65 and age ne 30")
+	assert.NoError(t, err)
+	expected := CompositeQuery{Fields: map[string]IQueryOperator{"age": AndOperator{Operands: []IQueryOperator{
+		ComparisonOperator{Op: "$gt", Value: 17},
+		ComparisonOperator{Op: "$lt", Value: 65},
+		ComparisonOperator{Op: "$ne", Value: 30},
+	}}}}
+	assert.True(t, expected.Equal(actual), "got %v", actual)
+}
+
+func TestODataFilterParserRelations(t *testing.T) {
+	parser := NewODataFilterParser()
+	parser.SetRelations("company")
+
+	actual, err := parser.Parse("company/type eq 'tech' and company/size gt 10")
+	assert.NoError(t, err)
+	expected, _ := ParseQuery(map[string]any{"company": map[string]any{"$rel": map[string]any{
+		"type": "tech",
+		"size": map[string]any{"$gt": 10},
+	}}})
+	assert.True(t, expected.Equal(actual), "got %v", actual)
+}
+
+func TestODataFilterParserAllowedFields(t *testing.T) {
+	parser := NewODataFilterParser()
+	parser.SetAllowedFields("status", "company.type", "items.price")
+
+	for _, filter := range []string{
+		"status eq 'active'",
+		"company/type eq 'tech'",
+		"items/any(i: i/price lt 10)",
+	} {
+		_, err := parser.Parse(filter)
+		assert.NoError(t, err, filter)
+	}
+
+	cases := []struct {
+		filter string
+		path   string
+	}{
+		{"password eq 'secret'", "password"},
+		{"company/revenue gt 1", "company/revenue"},
+		{"items/any(i: i/cost gt 1)", "items/cost"},
+		{"not (password eq null)", "password"},
+	}
+	for _, c := range cases {
+		_, err := parser.Parse(c.filter)
+		assert.ErrorIs(t, err, ErrFieldNotAllowed)
+		assert.ErrorContains(t, err, c.path+": ")
+	}
+}
+
+func TestParseODataFilterErrors(t *testing.T) {
+	cases := []struct {
+		filter string
+		err    string
+	}{
+		{"", "position 0: expected property, got end of filter"},
+		{"age gt", "position 6: expected literal, got end of filter"},
+		{"age between 1", "position 4: expected comparison operator, got: between"},
+		{"age gt 1 status eq 'a'", "position 9: expected end of filter, got: status"},
+		{"(age gt 1", `position 9: expected ")", got end of filter`},
+		{"name eq 'open", "position 8: unterminated string"},
+		{"age gt null", "position 4: gt does not support null"},
+		{"age eq 1 # comment", "position 9: unexpected character: '#'"},
+		{"items/any(i: price gt 1)", "position 13: property path must start with range variable i, got: price"},
+		{"status in ()", "position 11: expected literal, got: )"},
+	}
+	for _, c := range cases {
+		t.Run(c.filter, func(t *testing.T) {
+			_, err := ParseODataFilter(c.filter)
+			assert.EqualError(t, err, c.err)
+		})
+	}
+}
@@ -32,6 +32,12 @@ type IQueryVisitor interface {
 	VisitComposite(op CompositeQuery) (any, error)
 }
 
+// IBetweenVisitor is implemented by visitors supporting $between,
+// other visitors visit it as $and of the bound comparisons, see BetweenOperator.Conjunction.
+type IBetweenVisitor interface {
+	VisitBetween(op BetweenOperator) (any, error)
+}
+
 type IQueryOperator interface {
 	Accept(visitor IQueryVisitor) (any, error)
 	Equal(other IQueryOperator) bool
@@ -142,6 +148,64 @@ func (o InOperator) String() string {
 	return fmt.Sprintf("InOperator(%v)", o.Values)
 }
 
+// BetweenOperator represents range check: {'$between': [low, high]}
+// Bounds are included when Inclusive is true.
+type BetweenOperator struct {
+	Low       any
+	High      any
+	Inclusive bool
+}
+
+func (o BetweenOperator) Accept(visitor IQueryVisitor) (any, error) {
+	if v, ok := visitor.(IBetweenVisitor); ok {
+		return v.VisitBetween(o)
+	}
+	return o.Conjunction().Accept(visitor)
+}
+
+func (o BetweenOperator) Equal(other IQueryOperator) bool {
+	oo, ok := other.(BetweenOperator)
+	if !ok {
+		return false
+	}
+	return o.Inclusive == oo.Inclusive && reflect.DeepEqual(o.Low, oo.Low) && reflect.DeepEqual(o.High, oo.High)
+}
+
+func (o BetweenOperator) Merge(other IQueryOperator) (IQueryOperator, error) {
+	oo, ok := other.(BetweenOperator)
+	if !ok {
+		return nil, ErrUnsupportedMerge
+	}
+	if o.Equal(oo) {
+		return o, nil
+	}
+	return nil, &MergeConflict{
+		ExistingValue: [3]any{o.Low, o.High, o.Inclusive},
+		NewValue:      [3]any{oo.Low, oo.High, oo.Inclusive},
+	}
+}
+
+// BoundOps returns comparison operators for the low and high bounds.
+func (o BetweenOperator) BoundOps() (string, string) {
+	if o.Inclusive {
+		return "$gte", "$lte"
+	}
+	return "$gt", "$lt"
+}
+
+// Conjunction returns the equivalent $and of the comparisons with the bounds.
+func (o BetweenOperator) Conjunction() AndOperator {
+	lowOp, highOp := o.BoundOps()
+	return AndOperator{Operands: []IQueryOperator{
+		ComparisonOperator{Op: lowOp, Value: o.Low},
+		ComparisonOperator{Op: highOp, Value: o.High},
+	}}
+}
+
+func (o BetweenOperator) String() string {
+	return fmt.Sprintf("BetweenOperator(%v, %v, inclusive=%v)", o.Low, o.High, o.Inclusive)
+}
+
 // IsNullOperator represents null check: {'$is_null': true/false}
 type IsNullOperator struct {
 	Value bool
@@ -26,6 +26,68 @@ func TestEqOperatorEqual(t *testing.T) {
 	})
 }
 
+// =============================================================================
+// BetweenOperator equality and merge
+// =============================================================================
+
+func TestBetweenOperator(t *testing.T) {
+	t.Run("equal", func(t *testing.T) {
+		a := BetweenOperator{Low: 18, High: 65, Inclusive: true}
+		assert.True(t, a.Equal(BetweenOperator{Low: 18, High: 65, Inclusive: true}))
+		assert.False(t, a.Equal(BetweenOperator{Low: 18, High: 65}))
+		assert.False(t, a.Equal(BetweenOperator{Low: 18, High: 70, Inclusive: true}))
+		assert.False(t, a.Equal(ComparisonOperator{Op: "$gte", Value: 18}))
+	})
+	t.Run("merge same", func(t *testing.T) {
+		a := BetweenOperator{Low: 18, High: 65, Inclusive: true}
+		merged, err := a.Merge(a)
+		assert.NoError(t, err)
+		assert.Equal(t, a, merged)
+	})
+	t.Run("merge conflict", func(t *testing.T) {
+		_, err := BetweenOperator{Low: 18, High: 65}.Merge(BetweenOperator{Low: 20, High: 65})
+		var conflict *MergeConflict
+		assert.True(t, errors.As(err, &conflict))
+	})
+	t.Run("bound ops", func(t *testing.T) {
+		low, high := BetweenOperator{Inclusive: true}.BoundOps()
+		assert.Equal(t, []string{"$gte", "$lte"}, []string{low, high})
+		low, high = BetweenOperator{}.BoundOps()
+		assert.Equal(t, []string{"$gt", "$lt"}, []string{low, high})
+	})
+	t.Run("accept by visitor without between", func(t *testing.T) {
+		visitor := &comparisonRecordingVisitor{}
+		_, err := BetweenOperator{Low: 18, High: 65, Inclusive: true}.Accept(visitor)
+		assert.NoError(t, err)
+		_, err = BetweenOperator{Low: 18, High: 65}.Accept(visitor)
+		assert.NoError(t, err)
+		assert.Equal(t, []ComparisonOperator{
+			{Op: "$gte", Value: 18}, {Op: "$lte", Value: 65},
+			{Op: "$gt", Value: 18}, {Op: "$lt", Value: 65},
+		}, visitor.comparisons)
+	})
+}
+
+// comparisonRecordingVisitor visits $and and comparisons only, it does not implement IBetweenVisitor.
+type comparisonRecordingVisitor struct {
+	IQueryVisitor
+	comparisons []ComparisonOperator
+}
+
+func (v *comparisonRecordingVisitor) VisitComparison(op ComparisonOperator) (any, error) {
+	v.comparisons = append(v.comparisons, op)
+	return nil, nil
+}
+
+func (v *comparisonRecordingVisitor) VisitAnd(op AndOperator) (any, error) {
+	for _, operand := range op.Operands {
+		if _, err := operand.Accept(v); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
 // =============================================================================
 // ComparisonOperator equality
 // =============================================================================
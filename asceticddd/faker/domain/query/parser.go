@@ -8,7 +8,10 @@ import (
 const operatorPrefix = "$"
 
 // QueryParser parses map[string]any / scalar into IQueryOperator tree.
-type QueryParser struct{}
+type QueryParser struct {
+	// CustomOperators parses operators other than the built-in ones, if set.
+	CustomOperators *CustomOperatorRegistry
+}
 
 func (p QueryParser) Parse(query any) (IQueryOperator, error) {
 	m, ok := query.(map[string]any)
@@ -81,6 +84,8 @@ func (p QueryParser) parseSingleOperator(opName string, opValue any) (IQueryOper
 		return ComparisonOperator{Op: opName, Value: opValue}, nil
 	case "$in":
 		return p.parseIn(opValue)
+	case "$between":
+		return p.parseBetween(opValue)
 	case "$or":
 		return p.parseOr(opValue)
 	case "$is_null":
@@ -96,6 +101,9 @@ func (p QueryParser) parseSingleOperator(opName string, opValue any) (IQueryOper
 	case "$rel":
 		return p.parseRel(opValue)
 	default:
+		if parse, ok := p.CustomOperators.Parser(opName); ok {
+			return parse(p, opValue)
+		}
 		return nil, fmt.Errorf("unknown operator: %s", opName)
 	}
 }
@@ -143,6 +151,35 @@ func (p QueryParser) parseIn(values any) (IQueryOperator, error) {
 	return InOperator{Values: result}, nil
 }
 
+// parseBetween parses inclusive [low, high] list
+// or {"low": low, "high": high, "inclusive": bool} dict.
+func (p QueryParser) parseBetween(value any) (IQueryOperator, error) {
+	switch v := value.(type) {
+	case []any:
+		if len(v) != 2 {
+			return nil, fmt.Errorf("$between requires exactly 2 values, got: %d", len(v))
+		}
+		return BetweenOperator{Low: v[0], High: v[1], Inclusive: true}, nil
+	case map[string]any:
+		low, hasLow := v["low"]
+		high, hasHigh := v["high"]
+		if !hasLow || !hasHigh {
+			return nil, fmt.Errorf("$between dict requires low and high")
+		}
+		inclusive := true
+		if raw, ok := v["inclusive"]; ok {
+			b, ok := raw.(bool)
+			if !ok {
+				return nil, fmt.Errorf("$between inclusive must be bool, got: %T", raw)
+			}
+			inclusive = b
+		}
+		return BetweenOperator{Low: low, High: high, Inclusive: inclusive}, nil
+	default:
+		return nil, fmt.Errorf("$between value must be list or dict, got: %T", value)
+	}
+}
+
 func (p QueryParser) parseIsNull(value any) (IQueryOperator, error) {
 	b, ok := value.(bool)
 	if !ok {
@@ -309,6 +309,35 @@ func TestQueryParserIn(t *testing.T) {
 	})
 }
 
+func TestQueryParserBetween(t *testing.T) {
+	parser := QueryParser{}
+
+	t.Run("list is inclusive", func(t *testing.T) {
+		result, err := parser.Parse(map[string]any{"age": map[string]any{"$between": []any{18, 65}}})
+		assert.NoError(t, err)
+		cq := result.(CompositeQuery)
+		assert.Equal(t, BetweenOperator{Low: 18, High: 65, Inclusive: true}, cq.Fields["age"])
+	})
+	t.Run("dict exclusive", func(t *testing.T) {
+		result, err := parser.Parse(map[string]any{"$between": map[string]any{"low": 18, "high": 65, "inclusive": false}})
+		assert.NoError(t, err)
+		assert.Equal(t, BetweenOperator{Low: 18, High: 65}, result)
+	})
+	t.Run("wrong length raises", func(t *testing.T) {
+		_, err := parser.Parse(map[string]any{"$between": []any{18}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "exactly 2 values")
+	})
+	t.Run("dict without bound raises", func(t *testing.T) {
+		_, err := parser.Parse(map[string]any{"$between": map[string]any{"low": 18}})
+		assert.Error(t, err)
+	})
+	t.Run("scalar raises", func(t *testing.T) {
+		_, err := parser.Parse(map[string]any{"$between": 18})
+		assert.Error(t, err)
+	})
+}
+
 func TestQueryParserIsNull(t *testing.T) {
 	parser := QueryParser{}
 
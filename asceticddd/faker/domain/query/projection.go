@@ -0,0 +1,213 @@
+package query
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+// Projection selects a subset of state fields.
+//
+// Paths are dot-separated, e.g. "address.city".
+// If Include is empty, all fields are kept; otherwise only included paths are kept,
+// and missing included paths are present with nil value.
+// Exclude paths are removed after inclusion.
+// Rel maps a foreign key path to the projection of the related aggregate;
+// the foreign key value is replaced with the projected related state ($rel).
+// Rel paths are implicitly included.
+type Projection struct {
+	Include []string
+	Exclude []string
+	Rel     map[string]Projection
+}
+
+func (p Projection) IsEmpty() bool {
+	return len(p.Include) == 0 && len(p.Exclude) == 0 && len(p.Rel) == 0
+}
+
+// ProjectionTree is the Include paths of a projection grouped by path segment.
+// A nil subtree means the whole value at this key is included.
+type ProjectionTree map[string]ProjectionTree
+
+func (p Projection) IncludeTree() ProjectionTree {
+	tree := ProjectionTree{}
+	if len(p.Include) == 0 {
+		return tree
+	}
+	paths := append(append([]string{}, p.Include...), p.SortedRelPaths()...)
+	for _, path := range paths {
+		target := tree
+		keys := SplitPath(path)
+		for i, key := range keys {
+			subtree, exists := target[key]
+			if i == len(keys)-1 {
+				target[key] = nil
+				break
+			}
+			if exists && subtree == nil {
+				break
+			}
+			if !exists {
+				subtree = ProjectionTree{}
+				target[key] = subtree
+			}
+			target = subtree
+		}
+	}
+	return tree
+}
+
+// SortedKeys returns keys in deterministic order.
+func (t ProjectionTree) SortedKeys() []string {
+	keys := make([]string, 0, len(t))
+	for k := range t {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SortedRelPaths returns Rel paths in deterministic order.
+func (p Projection) SortedRelPaths() []string {
+	paths := make([]string, 0, len(p.Rel))
+	for path := range p.Rel {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func SplitPath(path string) []string {
+	return strings.Split(path, ".")
+}
+
+// ProjectionWalker applies Projection to object state in memory.
+// Supports IObjectResolver for Rel projections.
+type ProjectionWalker struct {
+	objectResolver IObjectResolver
+}
+
+func NewProjectionWalker(objectResolver IObjectResolver) *ProjectionWalker {
+	return &ProjectionWalker{objectResolver: objectResolver}
+}
+
+func (w *ProjectionWalker) Project(
+	s session.Session,
+	projection Projection,
+	state map[string]any,
+) (map[string]any, error) {
+	var result map[string]any
+	if len(projection.Include) > 0 {
+		result = includeTree(projection.IncludeTree(), state)
+	} else {
+		result = copyState(state)
+	}
+	for _, path := range projection.SortedRelPaths() {
+		relProjection := projection.Rel[path]
+		keys := SplitPath(path)
+		fkValue, _ := lookupPath(state, keys)
+		var projected any
+		if fkValue != nil && w.objectResolver != nil {
+			resolver := w.objectResolver
+			for _, key := range keys[:len(keys)-1] {
+				if descended := resolver.Descend(key); descended != nil {
+					resolver = descended
+				}
+			}
+			field := keys[len(keys)-1]
+			foreignState, nestedResolver, err := resolver.Resolve(s, &field, fkValue)
+			if err != nil {
+				return nil, err
+			}
+			if foreignState != nil {
+				nested := &ProjectionWalker{objectResolver: nestedResolver}
+				projected, err = nested.Project(s, relProjection, foreignState)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		assignPath(result, keys, projected)
+	}
+	for _, path := range projection.Exclude {
+		deletePath(result, SplitPath(path))
+	}
+	return result, nil
+}
+
+func includeTree(tree ProjectionTree, state map[string]any) map[string]any {
+	result := make(map[string]any, len(tree))
+	for key, subtree := range tree {
+		value := state[key]
+		if subtree == nil {
+			result[key] = copyValue(value)
+			continue
+		}
+		nested, _ := value.(map[string]any)
+		result[key] = includeTree(subtree, nested)
+	}
+	return result
+}
+
+func lookupPath(state map[string]any, keys []string) (any, bool) {
+	var current any = state
+	for _, key := range keys {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func assignPath(state map[string]any, keys []string, value any) {
+	target := state
+	for _, key := range keys[:len(keys)-1] {
+		nested, ok := target[key].(map[string]any)
+		if !ok {
+			return
+		}
+		target = nested
+	}
+	target[keys[len(keys)-1]] = value
+}
+
+func deletePath(state map[string]any, keys []string) {
+	target := state
+	for _, key := range keys[:len(keys)-1] {
+		nested, ok := target[key].(map[string]any)
+		if !ok {
+			return
+		}
+		target = nested
+	}
+	delete(target, keys[len(keys)-1])
+}
+
+func copyState(state map[string]any) map[string]any {
+	result := make(map[string]any, len(state))
+	for k, v := range state {
+		result[k] = copyValue(v)
+	}
+	return result
+}
+
+func copyValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return copyState(v)
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = copyValue(item)
+		}
+		return result
+	default:
+		return value
+	}
+}
@@ -0,0 +1,92 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectionWalker(t *testing.T) {
+	state := map[string]any{
+		"id":     1,
+		"name":   "Alice",
+		"secret": "xyz",
+		"address": map[string]any{
+			"city": "Moscow",
+			"zip":  "101000",
+		},
+		"company_id": 10,
+	}
+
+	t.Run("empty projection copies state", func(t *testing.T) {
+		walker := NewProjectionWalker(nil)
+		result, err := walker.Project(sess, Projection{}, state)
+		require.NoError(t, err)
+		assert.Equal(t, state, result)
+		result["address"].(map[string]any)["city"] = "Paris"
+		assert.Equal(t, "Moscow", state["address"].(map[string]any)["city"])
+	})
+
+	t.Run("include", func(t *testing.T) {
+		walker := NewProjectionWalker(nil)
+		result, err := walker.Project(sess, Projection{Include: []string{"name", "address.city", "missing"}}, state)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"name":    "Alice",
+			"address": map[string]any{"city": "Moscow"},
+			"missing": nil,
+		}, result)
+	})
+
+	t.Run("include whole and nested path", func(t *testing.T) {
+		walker := NewProjectionWalker(nil)
+		result, err := walker.Project(sess, Projection{Include: []string{"address", "address.city"}}, state)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"address": map[string]any{"city": "Moscow", "zip": "101000"},
+		}, result)
+	})
+
+	t.Run("exclude", func(t *testing.T) {
+		walker := NewProjectionWalker(nil)
+		result, err := walker.Project(sess, Projection{Exclude: []string{"secret", "address.zip"}}, state)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"id":         1,
+			"name":       "Alice",
+			"address":    map[string]any{"city": "Moscow"},
+			"company_id": 10,
+		}, result)
+	})
+
+	t.Run("rel", func(t *testing.T) {
+		resolver := makeResolver(map[string]relInfo{
+			"company_id": {storage: map[any]map[string]any{
+				10: {"id": 10, "name": "Acme", "revenue": 100},
+			}},
+		})
+		walker := NewProjectionWalker(resolver)
+		result, err := walker.Project(sess, Projection{
+			Include: []string{"name"},
+			Rel: map[string]Projection{
+				"company_id": {Include: []string{"name"}},
+			},
+		}, state)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"name":       "Alice",
+			"company_id": map[string]any{"name": "Acme"},
+		}, result)
+	})
+
+	t.Run("rel not found", func(t *testing.T) {
+		walker := NewProjectionWalker(makeResolver(map[string]relInfo{}))
+		result, err := walker.Project(sess, Projection{
+			Include: []string{"name"},
+			Rel:     map[string]Projection{"company_id": {}},
+		}, state)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"name": "Alice", "company_id": nil}, result)
+	})
+}
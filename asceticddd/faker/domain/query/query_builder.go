@@ -0,0 +1,173 @@
+package query
+
+import "fmt"
+
+// QueryBuilder builds CompositeQuery with a fluent API:
+//
+//	Where("status").Eq("active").
+//		And("age").Gt(18).
+//		Rel("company_id", func(b *QueryBuilder) { b.Where("type").Eq("tech") }).
+//		Build()
+//
+// Several conditions on the same field are combined with AndOperator,
+// the same way as the parser does for {"age": {"$gt": 18, "$lt": 65}}.
+type QueryBuilder struct {
+	fields map[string][]IQueryOperator
+	rels   map[string]*QueryBuilder
+	err    error
+}
+
+func NewQueryBuilder() *QueryBuilder {
+	return &QueryBuilder{
+		fields: map[string][]IQueryOperator{},
+		rels:   map[string]*QueryBuilder{},
+	}
+}
+
+// Where starts a new QueryBuilder with a condition on the field.
+func Where(field string) *FieldBuilder {
+	return NewQueryBuilder().Where(field)
+}
+
+func (b *QueryBuilder) Where(field string) *FieldBuilder {
+	return &FieldBuilder{builder: b, field: field}
+}
+
+// And is an alias of Where, for readability of chains.
+func (b *QueryBuilder) And(field string) *FieldBuilder {
+	return b.Where(field)
+}
+
+// Rel adds conditions on the object referenced by the field.
+// Conditions of several Rel calls on the same field are combined.
+func (b *QueryBuilder) Rel(field string, build func(b *QueryBuilder)) *QueryBuilder {
+	rel, ok := b.rels[field]
+	if !ok {
+		rel = NewQueryBuilder()
+		b.rels[field] = rel
+		if _, ok := b.fields[field]; !ok {
+			b.fields[field] = nil
+		}
+	}
+	build(rel)
+	return b
+}
+
+// Build returns the query, or the first error of the chain.
+func (b *QueryBuilder) Build() (CompositeQuery, error) {
+	if b.err != nil {
+		return CompositeQuery{}, b.err
+	}
+	fields := make(map[string]IQueryOperator, len(b.fields))
+	for field, operands := range b.fields {
+		if rel, ok := b.rels[field]; ok {
+			query, err := rel.Build()
+			if err != nil {
+				return CompositeQuery{}, fmt.Errorf("%s: %w", field, err)
+			}
+			if len(query.Fields) == 0 {
+				return CompositeQuery{}, fmt.Errorf("%s: relation requires at least one condition", field)
+			}
+			operands = append(operands, RelOperator{Query: query})
+		}
+		if len(operands) == 1 {
+			fields[field] = operands[0]
+		} else {
+			fields[field] = AndOperator{Operands: operands}
+		}
+	}
+	return CompositeQuery{Fields: fields}, nil
+}
+
+func (b *QueryBuilder) add(field string, op IQueryOperator) *QueryBuilder {
+	b.fields[field] = append(b.fields[field], op)
+	return b
+}
+
+func (b *QueryBuilder) fail(err error) *QueryBuilder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+// FieldBuilder adds a condition on one field and returns to the QueryBuilder.
+type FieldBuilder struct {
+	builder *QueryBuilder
+	field   string
+}
+
+func (f *FieldBuilder) Eq(value any) *QueryBuilder {
+	return f.builder.add(f.field, EqOperator{Value: value})
+}
+
+func (f *FieldBuilder) Ne(value any) *QueryBuilder {
+	return f.compare("$ne", value)
+}
+
+func (f *FieldBuilder) Gt(value any) *QueryBuilder {
+	return f.compare("$gt", value)
+}
+
+func (f *FieldBuilder) Gte(value any) *QueryBuilder {
+	return f.compare("$gte", value)
+}
+
+func (f *FieldBuilder) Lt(value any) *QueryBuilder {
+	return f.compare("$lt", value)
+}
+
+func (f *FieldBuilder) Lte(value any) *QueryBuilder {
+	return f.compare("$lte", value)
+}
+
+func (f *FieldBuilder) In(values ...any) *QueryBuilder {
+	if len(values) == 0 {
+		return f.builder.fail(fmt.Errorf("%s: $in requires at least 1 value", f.field))
+	}
+	return f.builder.add(f.field, InOperator{Values: values})
+}
+
+// Between matches values within [low, high].
+func (f *FieldBuilder) Between(low, high any) *QueryBuilder {
+	return f.builder.add(f.field, BetweenOperator{Low: low, High: high, Inclusive: true})
+}
+
+func (f *FieldBuilder) IsNull() *QueryBuilder {
+	return f.builder.add(f.field, IsNullOperator{Value: true})
+}
+
+func (f *FieldBuilder) IsNotNull() *QueryBuilder {
+	return f.builder.add(f.field, IsNullOperator{Value: false})
+}
+
+// Any matches arrays with at least one element matching the conditions.
+func (f *FieldBuilder) Any(build func(b *QueryBuilder)) *QueryBuilder {
+	return f.elements(build, func(query CompositeQuery) IQueryOperator {
+		return AnyElementOperator{Query: query}
+	})
+}
+
+// All matches arrays whose elements all match the conditions.
+func (f *FieldBuilder) All(build func(b *QueryBuilder)) *QueryBuilder {
+	return f.elements(build, func(query CompositeQuery) IQueryOperator {
+		return AllElementsOperator{Query: query}
+	})
+}
+
+func (f *FieldBuilder) compare(op string, value any) *QueryBuilder {
+	return f.builder.add(f.field, ComparisonOperator{Op: op, Value: value})
+}
+
+func (f *FieldBuilder) elements(build func(b *QueryBuilder), wrap func(CompositeQuery) IQueryOperator) *QueryBuilder {
+	nested := NewQueryBuilder()
+	build(nested)
+	query, err := nested.Build()
+	if err != nil {
+		return f.builder.fail(fmt.Errorf("%s: %w", f.field, err))
+	}
+	if len(query.Fields) == 0 {
+		return f.builder.fail(fmt.Errorf("%s: element query requires at least one condition", f.field))
+	}
+	return f.builder.add(f.field, wrap(query))
+}
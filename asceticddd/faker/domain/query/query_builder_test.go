@@ -0,0 +1,112 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryBuilder(t *testing.T) {
+	t.Run("fields and relation", func(t *testing.T) {
+		query, err := Where("status").Eq("active").
+			And("age").Gt(18).
+			Rel("company_id", func(b *QueryBuilder) { b.Where("type").Eq("tech") }).
+			Build()
+		require.NoError(t, err)
+
+		expected, err := ParseQuery(map[string]any{
+			"status":     "active",
+			"age":        map[string]any{"$gt": 18},
+			"company_id": map[string]any{"$rel": map[string]any{"type": "tech"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, expected, query)
+	})
+
+	t.Run("operators", func(t *testing.T) {
+		query, err := Where("a").Ne(1).
+			And("b").Gte(2).
+			And("c").Lt(3).
+			And("d").Lte(4).
+			And("e").In("x", "y").
+			And("f").Between(1, 10).
+			And("g").IsNull().
+			And("h").IsNotNull().
+			Build()
+		require.NoError(t, err)
+		assert.Equal(t, CompositeQuery{Fields: map[string]IQueryOperator{
+			"a": ComparisonOperator{Op: "$ne", Value: 1},
+			"b": ComparisonOperator{Op: "$gte", Value: 2},
+			"c": ComparisonOperator{Op: "$lt", Value: 3},
+			"d": ComparisonOperator{Op: "$lte", Value: 4},
+			"e": InOperator{Values: []any{"x", "y"}},
+			"f": BetweenOperator{Low: 1, High: 10, Inclusive: true},
+			"g": IsNullOperator{Value: true},
+			"h": IsNullOperator{Value: false},
+		}}, query)
+	})
+
+	t.Run("same field is combined with and", func(t *testing.T) {
+		query, err := Where("age").Gte(18).And("age").Lt(65).Build()
+		require.NoError(t, err)
+		assert.Equal(t, CompositeQuery{Fields: map[string]IQueryOperator{
+			"age": AndOperator{Operands: []IQueryOperator{
+				ComparisonOperator{Op: "$gte", Value: 18},
+				ComparisonOperator{Op: "$lt", Value: 65},
+			}},
+		}}, query)
+	})
+
+	t.Run("relation calls are combined", func(t *testing.T) {
+		b := NewQueryBuilder()
+		b.Rel("company_id", func(b *QueryBuilder) { b.Where("type").Eq("tech") })
+		b.Rel("company_id", func(b *QueryBuilder) { b.Where("size").Gt(10) })
+		query, err := b.Build()
+		require.NoError(t, err)
+		assert.Equal(t, CompositeQuery{Fields: map[string]IQueryOperator{
+			"company_id": RelOperator{Query: CompositeQuery{Fields: map[string]IQueryOperator{
+				"type": EqOperator{Value: "tech"},
+				"size": ComparisonOperator{Op: "$gt", Value: 10},
+			}}},
+		}}, query)
+	})
+
+	t.Run("array elements", func(t *testing.T) {
+		query, err := Where("items").Any(func(b *QueryBuilder) { b.Where("price").Lt(10) }).
+			And("tags").All(func(b *QueryBuilder) { b.Where("kind").Eq("public") }).
+			Build()
+		require.NoError(t, err)
+		assert.Equal(t, CompositeQuery{Fields: map[string]IQueryOperator{
+			"items": AnyElementOperator{Query: CompositeQuery{Fields: map[string]IQueryOperator{
+				"price": ComparisonOperator{Op: "$lt", Value: 10},
+			}}},
+			"tags": AllElementsOperator{Query: CompositeQuery{Fields: map[string]IQueryOperator{
+				"kind": EqOperator{Value: "public"},
+			}}},
+		}}, query)
+	})
+
+	t.Run("evaluates like a parsed query", func(t *testing.T) {
+		query, err := Where("status").Eq("active").And("age").Between(18, 65).Build()
+		require.NoError(t, err)
+		walker := NewEvaluateWalker(nil)
+		matched, err := walker.EvaluateSync(query, map[string]any{"status": "active", "age": 30})
+		require.NoError(t, err)
+		assert.True(t, matched)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := Where("status").In().Build()
+		assert.Error(t, err)
+
+		_, err = NewQueryBuilder().Rel("company_id", func(*QueryBuilder) {}).Build()
+		assert.Error(t, err)
+
+		_, err = Where("items").Any(func(b *QueryBuilder) { b.Where("sku").In() }).Build()
+		assert.Error(t, err)
+
+		_, err = Where("items").All(func(*QueryBuilder) {}).Build()
+		assert.Error(t, err)
+	})
+}
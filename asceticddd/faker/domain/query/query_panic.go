@@ -0,0 +1,77 @@
+package query
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// QueryPanicError is a panic recovered while visiting a query, e.g. of a malformed operator tree
+// or of a state of unexpected type, so that the query fails instead of crashing the goroutine.
+type QueryPanicError struct {
+	// Operator is the type of the operator of the innermost field being visited.
+	Operator string
+	// Path is the dot-separated path of the innermost field being visited, empty for the root.
+	Path string
+	// ValueType is the type of the state of the field, empty for nil states and for compilers.
+	ValueType string
+	Recovered any
+	Stack     []byte
+}
+
+func (e *QueryPanicError) Error() string {
+	message := fmt.Sprintf("panic visiting %s", e.Operator)
+	if e.Path != "" {
+		message += fmt.Sprintf(" at %s", e.Path)
+	}
+	if e.ValueType != "" {
+		message += fmt.Sprintf(" of %s", e.ValueType)
+	}
+	return fmt.Sprintf("%s: %v", message, e.Recovered)
+}
+
+// Unwrap returns the recovered error, if the panic value is an error.
+func (e *QueryPanicError) Unwrap() error {
+	err, _ := e.Recovered.(error)
+	return err
+}
+
+// RecoverQueryPanic converts a panic into *QueryPanicError assigned to err.
+// It is deferred by entry points of visitors and walkers:
+//
+//	func (c *Compiler) Compile(query IQueryOperator) (sql string, err error) {
+//		defer query.RecoverQueryPanic(&err, query, nil)
+//		...
+func RecoverQueryPanic(err *error, op IQueryOperator, value any) {
+	if r := recover(); r != nil {
+		*err = newQueryPanicError(r, op, value)
+	}
+}
+
+// AnnotateQueryPanic is deferred when visiting the field of CompositeQuery,
+// it panics again with the field prepended to the path of the recovered panic.
+func AnnotateQueryPanic(field string, op IQueryOperator, value any) {
+	if r := recover(); r != nil {
+		e := newQueryPanicError(r, op, value)
+		if e.Path == "" {
+			e.Path = field
+		} else {
+			e.Path = field + "." + e.Path
+		}
+		panic(e)
+	}
+}
+
+func newQueryPanicError(recovered any, op IQueryOperator, value any) *QueryPanicError {
+	if e, ok := recovered.(*QueryPanicError); ok {
+		return e
+	}
+	e := &QueryPanicError{
+		Operator:  fmt.Sprintf("%T", op),
+		Recovered: recovered,
+		Stack:     debug.Stack(),
+	}
+	if value != nil {
+		e.ValueType = fmt.Sprintf("%T", value)
+	}
+	return e
+}
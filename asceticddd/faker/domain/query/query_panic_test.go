@@ -0,0 +1,59 @@
+package query
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryPanicRecovery(t *testing.T) {
+	registry := NewCustomOperatorRegistry()
+	registry.Register("$divisible_by", nil, func(op ICustomOperator, state any) (bool, error) {
+		return state.(int)%op.(divisibleByOperator).Divisor == 0, nil
+	})
+	query := CompositeQuery{Fields: map[string]IQueryOperator{
+		"order": CompositeQuery{Fields: map[string]IQueryOperator{
+			"quantity": divisibleByOperator{Divisor: 3},
+		}},
+	}}
+	state := map[string]any{"order": map[string]any{"quantity": "nine"}}
+
+	assertPanicError := func(t *testing.T, err error) {
+		var panicErr *QueryPanicError
+		require.ErrorAs(t, err, &panicErr)
+		assert.Equal(t, "query.divisibleByOperator", panicErr.Operator)
+		assert.Equal(t, "order.quantity", panicErr.Path)
+		assert.Equal(t, "string", panicErr.ValueType)
+		assert.NotEmpty(t, panicErr.Stack)
+		var runtimeErr runtime.Error
+		assert.True(t, errors.As(err, &runtimeErr))
+		assert.Contains(t, err.Error(), "panic visiting query.divisibleByOperator at order.quantity of string: ")
+	}
+
+	t.Run("evaluate walker", func(t *testing.T) {
+		walker := NewEvaluateWalker(nil)
+		walker.SetCustomOperators(registry)
+
+		_, err := walker.Evaluate(&mockSession{}, query, state)
+		assertPanicError(t, err)
+		_, err = walker.EvaluateSync(query, state)
+		assertPanicError(t, err)
+	})
+
+	t.Run("evaluate visitor", func(t *testing.T) {
+		visitor := NewEvaluateVisitor(state, nil, nil)
+		visitor.SetCustomOperators(registry)
+
+		_, err := visitor.Evaluate(query)
+		assertPanicError(t, err)
+
+		result, err := visitor.Evaluate(CompositeQuery{Fields: map[string]IQueryOperator{
+			"order": IsNullOperator{Value: false},
+		}})
+		require.NoError(t, err)
+		assert.True(t, result)
+	})
+}
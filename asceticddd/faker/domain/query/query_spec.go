@@ -0,0 +1,60 @@
+package query
+
+import "fmt"
+
+type SortDirection string
+
+const (
+	Asc  SortDirection = "ASC"
+	Desc SortDirection = "DESC"
+)
+
+// OrderClause sorts by a dot-separated field path.
+type OrderClause struct {
+	Path      string
+	Direction SortDirection
+}
+
+func (c OrderClause) String() string {
+	return fmt.Sprintf("%s %s", c.Path, c.direction())
+}
+
+func (c OrderClause) direction() SortDirection {
+	if c.Direction == "" {
+		return Asc
+	}
+	return c.Direction
+}
+
+// IsDesc reports whether the clause sorts in descending order.
+func (c OrderClause) IsDesc() bool {
+	return c.direction() == Desc
+}
+
+// QuerySpec wraps IQueryOperator with ordering, pagination and projection.
+// Nil Query matches everything, zero Limit means no limit.
+type QuerySpec struct {
+	Query      IQueryOperator
+	Projection Projection
+	OrderBy    []OrderClause
+	Limit      int
+	Offset     int
+}
+
+func (s QuerySpec) Validate() error {
+	for _, clause := range s.OrderBy {
+		if clause.Path == "" {
+			return fmt.Errorf("order clause path must not be empty")
+		}
+		if clause.Direction != "" && clause.Direction != Asc && clause.Direction != Desc {
+			return fmt.Errorf("unknown sort direction: %s", clause.Direction)
+		}
+	}
+	if s.Limit < 0 {
+		return fmt.Errorf("limit must not be negative, got: %d", s.Limit)
+	}
+	if s.Offset < 0 {
+		return fmt.Errorf("offset must not be negative, got: %d", s.Offset)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Package querytest provides helpers for tests asserting IQueryOperator trees.
+package querytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+// Diff walks both trees and reports the first divergence, or returns "" if the trees are equal:
+//
+//	company_id.$rel.type: expected {"$eq":"tech"}, got {"$eq":"retail"}
+//
+// The path consists of field names and operators, with indexes of $and and $or operands;
+// "$" is the root. Fields are walked in the order of names, so the report is deterministic.
+// Subtrees are rendered in the syntax of the parser.
+func Diff(expected, actual query.IQueryOperator) string {
+	return diff(nil, expected, actual)
+}
+
+func diff(path []string, expected, actual query.IQueryOperator) string {
+	if expected == nil || actual == nil {
+		if expected == nil && actual == nil {
+			return ""
+		}
+		return report(path, expected, actual)
+	}
+	if reflect.TypeOf(expected) != reflect.TypeOf(actual) {
+		return report(path, expected, actual)
+	}
+
+	switch e := expected.(type) {
+	case query.EqOperator:
+		a := actual.(query.EqOperator)
+		eOp, eNested := e.Value.(query.IQueryOperator)
+		aOp, aNested := a.Value.(query.IQueryOperator)
+		if eNested && aNested {
+			return diff(path, eOp, aOp)
+		}
+	case query.NotOperator:
+		return diff(append(path, "$not"), e.Operand, actual.(query.NotOperator).Operand)
+	case query.AnyElementOperator:
+		return diff(append(path, "$any"), e.Query, actual.(query.AnyElementOperator).Query)
+	case query.AllElementsOperator:
+		return diff(append(path, "$all"), e.Query, actual.(query.AllElementsOperator).Query)
+	case query.LenOperator:
+		return diff(append(path, "$len"), e.Query, actual.(query.LenOperator).Query)
+	case query.RelOperator:
+		return diff(append(path, "$rel"), e.Query, actual.(query.RelOperator).Query)
+	case query.AndOperator:
+		return diffOperands(path, "$and", e.Operands, actual.(query.AndOperator).Operands)
+	case query.OrOperator:
+		return diffOperands(path, "$or", e.Operands, actual.(query.OrOperator).Operands)
+	case query.CompositeQuery:
+		return diffFields(path, e.Fields, actual.(query.CompositeQuery).Fields)
+	}
+
+	if !expected.Equal(actual) {
+		return report(path, expected, actual)
+	}
+	return ""
+}
+
+func diffOperands(path []string, op string, expected, actual []query.IQueryOperator) string {
+	for i := range max(len(expected), len(actual)) {
+		var e, a query.IQueryOperator
+		if i < len(expected) {
+			e = expected[i]
+		}
+		if i < len(actual) {
+			a = actual[i]
+		}
+		if result := diff(append(path, fmt.Sprintf("%s[%d]", op, i)), e, a); result != "" {
+			return result
+		}
+	}
+	return ""
+}
+
+func diffFields(path []string, expected, actual map[string]query.IQueryOperator) string {
+	names := make([]string, 0, len(expected)+len(actual))
+	for name := range expected {
+		names = append(names, name)
+	}
+	for name := range actual {
+		if _, ok := expected[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if result := diff(append(path, name), expected[name], actual[name]); result != "" {
+			return result
+		}
+	}
+	return ""
+}
+
+func report(path []string, expected, actual query.IQueryOperator) string {
+	location := "$"
+	if len(path) > 0 {
+		location = strings.Join(path, ".")
+	}
+	return fmt.Sprintf("%s: expected %s, got %s", location, render(expected), render(actual))
+}
+
+func render(op query.IQueryOperator) string {
+	if op == nil {
+		return "nothing"
+	}
+	dict, err := query.QueryToDict(op)
+	if err != nil {
+		return fmt.Sprintf("%v", op)
+	}
+	data, err := json.Marshal(dict)
+	if err != nil {
+		return fmt.Sprintf("%v", op)
+	}
+	return string(data)
+}
@@ -0,0 +1,140 @@
+package querytest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+func parse(t *testing.T, dict map[string]any) query.IQueryOperator {
+	t.Helper()
+	op, err := query.ParseQuery(dict)
+	assert.NoError(t, err)
+	return op
+}
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected map[string]any
+		actual   map[string]any
+		diff     string
+	}{
+		{
+			"equal",
+			map[string]any{"status": "active", "age": map[string]any{"$gt": 18}},
+			map[string]any{"age": map[string]any{"$gt": 18}, "status": "active"},
+			"",
+		},
+		{
+			"value",
+			map[string]any{"status": "active"},
+			map[string]any{"status": "inactive"},
+			`status: expected {"$eq":"active"}, got {"$eq":"inactive"}`,
+		},
+		{
+			"operator",
+			map[string]any{"age": map[string]any{"$gt": 18}},
+			map[string]any{"age": map[string]any{"$gte": 18}},
+			`age: expected {"$gt":18}, got {"$gte":18}`,
+		},
+		{
+			"operator type",
+			map[string]any{"age": map[string]any{"$gt": 18}},
+			map[string]any{"age": 18},
+			`age: expected {"$gt":18}, got {"$eq":18}`,
+		},
+		{
+			"missing field",
+			map[string]any{"age": 18, "status": "active"},
+			map[string]any{"age": 18},
+			`status: expected {"$eq":"active"}, got nothing`,
+		},
+		{
+			"unexpected field",
+			map[string]any{"age": 18},
+			map[string]any{"age": 18, "name": "Alice"},
+			`name: expected nothing, got {"$eq":"Alice"}`,
+		},
+		{
+			"first field by name",
+			map[string]any{"age": 18, "status": "active"},
+			map[string]any{"age": 21, "status": "inactive"},
+			`age: expected {"$eq":18}, got {"$eq":21}`,
+		},
+		{
+			"relation",
+			map[string]any{"company_id": map[string]any{"$rel": map[string]any{"type": "tech"}}},
+			map[string]any{"company_id": map[string]any{"$rel": map[string]any{"type": "retail"}}},
+			`company_id.$rel.type: expected {"$eq":"tech"}, got {"$eq":"retail"}`,
+		},
+		{
+			"nested field",
+			map[string]any{"profile": map[string]any{"score": 7}},
+			map[string]any{"profile": map[string]any{"score": 8}},
+			`profile.score: expected {"$eq":7}, got {"$eq":8}`,
+		},
+		{
+			"or operand",
+			map[string]any{"$or": []any{map[string]any{"a": 1}, map[string]any{"b": 2}}},
+			map[string]any{"$or": []any{map[string]any{"a": 1}, map[string]any{"b": 3}}},
+			`$or[1].b: expected {"$eq":2}, got {"$eq":3}`,
+		},
+		{
+			"missing or operand",
+			map[string]any{"$or": []any{map[string]any{"a": 1}, map[string]any{"b": 2}, map[string]any{"c": 3}}},
+			map[string]any{"$or": []any{map[string]any{"a": 1}, map[string]any{"b": 2}}},
+			`$or[2]: expected {"c":{"$eq":3}}, got nothing`,
+		},
+		{
+			"array elements",
+			map[string]any{"items": map[string]any{"$any": map[string]any{"price": map[string]any{"$lt": 10}}}},
+			map[string]any{"items": map[string]any{"$any": map[string]any{"price": map[string]any{"$lt": 20}}}},
+			`items.$any.price: expected {"$lt":10}, got {"$lt":20}`,
+		},
+		{
+			"not",
+			map[string]any{"status": map[string]any{"$not": "deleted"}},
+			map[string]any{"status": map[string]any{"$not": "archived"}},
+			`status.$not: expected {"$eq":"deleted"}, got {"$eq":"archived"}`,
+		},
+		{
+			"len",
+			map[string]any{"tags": map[string]any{"$len": map[string]any{"$gt": 2}}},
+			map[string]any{"tags": map[string]any{"$len": map[string]any{"$gt": 3}}},
+			`tags.$len: expected {"$gt":2}, got {"$gt":3}`,
+		},
+		{
+			"in",
+			map[string]any{"status": map[string]any{"$in": []any{"a", "b"}}},
+			map[string]any{"status": map[string]any{"$in": []any{"b", "a"}}},
+			`status: expected {"$in":["a","b"]}, got {"$in":["b","a"]}`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.diff, Diff(parse(t, c.expected), parse(t, c.actual)))
+		})
+	}
+}
+
+func TestDiffAndOperands(t *testing.T) {
+	expected := query.AndOperator{Operands: []query.IQueryOperator{
+		query.ComparisonOperator{Op: "$gt", Value: 18},
+		query.ComparisonOperator{Op: "$lt", Value: 65},
+	}}
+	actual := query.AndOperator{Operands: []query.IQueryOperator{
+		query.ComparisonOperator{Op: "$gt", Value: 18},
+		query.ComparisonOperator{Op: "$lt", Value: 60},
+	}}
+	assert.Equal(t, `$and[1]: expected {"$lt":65}, got {"$lt":60}`, Diff(expected, actual))
+}
+
+func TestDiffRoot(t *testing.T) {
+	assert.Equal(t, "", Diff(nil, nil))
+	assert.Equal(t, `$: expected {"$eq":1}, got nothing`, Diff(query.EqOperator{Value: 1}, nil))
+	assert.Equal(t, `$: expected nothing, got {"$is_null":true}`, Diff(nil, query.IsNullOperator{Value: true}))
+	assert.Equal(t, `$: expected {"$eq":1}, got {"$eq":2}`, Diff(query.EqOperator{Value: 1}, query.EqOperator{Value: 2}))
+}
@@ -0,0 +1,389 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+var ErrUnsupportedConversion = errors.New("unsupported conversion")
+
+var specComparisons = map[operators.Operator]string{
+	operators.OperatorNe:  "$ne",
+	operators.OperatorGt:  "$gt",
+	operators.OperatorGte: "$gte",
+	operators.OperatorLt:  "$lt",
+	operators.OperatorLte: "$lte",
+}
+
+// mirroredComparisons flips comparisons for a value on the left: 5 < age is age > 5.
+var mirroredComparisons = map[operators.Operator]operators.Operator{
+	operators.OperatorEq:  operators.OperatorEq,
+	operators.OperatorNe:  operators.OperatorNe,
+	operators.OperatorIs:  operators.OperatorIs,
+	operators.OperatorGt:  operators.OperatorLt,
+	operators.OperatorGte: operators.OperatorLte,
+	operators.OperatorLt:  operators.OperatorGt,
+	operators.OperatorLte: operators.OperatorGte,
+}
+
+// SpecToQuery converts the specification AST, e.g. generated by specgen, into IQueryOperator:
+//
+//	And(Equal(Field(GlobalScope(), "status"), Value("active")),
+//		Wildcard(Object(GlobalScope(), "items"), GreaterThan(Field(Item(), "price"), Value(10))))
+//
+// becomes {"status": "active", "items": {"$any": {"price": {"$gt": 10}}}}.
+// Comparisons of fields with values (with nil they test for null), IN and NOT IN with slices, IS NULL, IS NOT NULL,
+// boolean fields, AND, OR, NOT and wildcards (any element) are supported;
+// arithmetic and comparisons of two fields are ErrUnsupportedConversion.
+func SpecToQuery(node spec.Visitable) (IQueryOperator, error) {
+	return specToQuery(node, false)
+}
+
+// specToQuery converts the node with fields rooted at the collection item if item is set.
+func specToQuery(node spec.Visitable, item bool) (IQueryOperator, error) {
+	switch n := node.(type) {
+	case spec.InfixNode:
+		switch n.Operator() {
+		case operators.OperatorAnd, operators.OperatorOr:
+			var operands []IQueryOperator
+			for _, operand := range specOperands(n, n.Operator()) {
+				op, err := specToQuery(operand, item)
+				if err != nil {
+					return nil, err
+				}
+				operands = append(operands, op)
+			}
+			if n.Operator() == operators.OperatorAnd {
+				return conjoin(operands), nil
+			}
+			return OrOperator{Operands: operands}, nil
+		}
+		return specComparison(n, item)
+	case spec.PrefixNode:
+		if n.Operator() != operators.OperatorNot {
+			return nil, fmt.Errorf("%w: prefix operator %s", ErrUnsupportedConversion, n.Operator())
+		}
+		op, err := specToQuery(n.Operand(), item)
+		if err != nil {
+			return nil, err
+		}
+		return NotOperator{Operand: op}, nil
+	case spec.PostfixNode:
+		field, ok := n.Operand().(spec.FieldNode)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s of %T", ErrUnsupportedConversion, n.Operator(), n.Operand())
+		}
+		switch n.Operator() {
+		case operators.OperatorIsNull:
+			return specField(field, item, IsNullOperator{Value: true})
+		case operators.OperatorIsNotNull:
+			return specField(field, item, IsNullOperator{Value: false})
+		}
+		return nil, fmt.Errorf("%w: postfix operator %s", ErrUnsupportedConversion, n.Operator())
+	case spec.CollectionNode:
+		path, itemRooted, err := specPath(n.Parent())
+		if err != nil {
+			return nil, err
+		}
+		if itemRooted != item {
+			return nil, fmt.Errorf("%w: wildcard outside of its scope", ErrUnsupportedConversion)
+		}
+		if len(path) == 0 {
+			return nil, fmt.Errorf("%w: wildcard without collection", ErrUnsupportedConversion)
+		}
+		if !n.IsWildcard() {
+			return nil, fmt.Errorf("%w: selector [%s] of collection", ErrUnsupportedConversion, n.Name())
+		}
+		predicate, err := specToQuery(n.Predicate(), true)
+		if err != nil {
+			return nil, err
+		}
+		return nestQuery(path, AnyElementOperator{Query: predicate}), nil
+	case spec.FieldNode:
+		// A boolean field as predicate.
+		return specField(n, item, EqOperator{Value: true})
+	}
+	return nil, fmt.Errorf("%w: %T as predicate", ErrUnsupportedConversion, node)
+}
+
+// specOperands flattens the chain of the same logical operator.
+func specOperands(node spec.Visitable, operator operators.Operator) []spec.Visitable {
+	n, ok := node.(spec.InfixNode)
+	if !ok || n.Operator() != operator {
+		return []spec.Visitable{node}
+	}
+	return append(specOperands(n.Left(), operator), specOperands(n.Right(), operator)...)
+}
+
+func specComparison(n spec.InfixNode, item bool) (IQueryOperator, error) {
+	operator := n.Operator()
+	field, fieldOk := n.Left().(spec.FieldNode)
+	value, valueOk := n.Right().(spec.ValueNode)
+	if !fieldOk || !valueOk {
+		field, fieldOk = n.Right().(spec.FieldNode)
+		value, valueOk = n.Left().(spec.ValueNode)
+		operator = mirroredComparisons[operator]
+	}
+	if !fieldOk || !valueOk {
+		return nil, fmt.Errorf("%w: %T %s %T", ErrUnsupportedConversion, n.Left(), n.Operator(), n.Right())
+	}
+
+	var op IQueryOperator
+	switch {
+	case operator == operators.OperatorIn || operator == operators.OperatorNotIn:
+		values, ok := toSlice(value.Value())
+		if !ok {
+			return nil, fmt.Errorf("%w: %s with %v", ErrUnsupportedConversion, operator, value.Value())
+		}
+		op = InOperator{Values: values}
+		if operator == operators.OperatorNotIn {
+			op = NotOperator{Operand: op}
+		}
+	case operator == operators.OperatorEq && value.Value() != nil:
+		op = EqOperator{Value: value.Value()}
+	case (operator == operators.OperatorIs || operator == operators.OperatorEq) && value.Value() == nil:
+		op = IsNullOperator{Value: true}
+	case operator == operators.OperatorNe && value.Value() == nil:
+		op = IsNullOperator{Value: false}
+	case specComparisons[operator] != "" && value.Value() != nil:
+		op = ComparisonOperator{Op: specComparisons[operator], Value: value.Value()}
+	default:
+		return nil, fmt.Errorf("%w: operator %s with %v", ErrUnsupportedConversion, n.Operator(), value.Value())
+	}
+	return specField(field, item, op)
+}
+
+func specField(field spec.FieldNode, item bool, op IQueryOperator) (IQueryOperator, error) {
+	path, itemRooted, err := specPath(field.Object())
+	if err != nil {
+		return nil, err
+	}
+	if itemRooted != item {
+		return nil, fmt.Errorf("%w: field %s outside of its scope", ErrUnsupportedConversion, field.Name())
+	}
+	return nestQuery(append(path, field.Name()), op), nil
+}
+
+// specPath returns the names of the objects up to the root, and whether the root is the collection item.
+func specPath(object spec.EmptiableObject) ([]string, bool, error) {
+	var path []string
+	for {
+		switch o := object.(type) {
+		case spec.GlobalScopeNode:
+			return path, false, nil
+		case spec.ItemNode:
+			return path, true, nil
+		case spec.ObjectNode:
+			path = append([]string{o.Name()}, path...)
+			object = o.Parent()
+		default:
+			return nil, false, fmt.Errorf("%w: path through %T", ErrUnsupportedConversion, object)
+		}
+	}
+}
+
+// nestQuery nests the operator into the fields of the path.
+func nestQuery(path []string, op IQueryOperator) IQueryOperator {
+	for i := len(path) - 1; i >= 0; i-- {
+		op = CompositeQuery{Fields: map[string]IQueryOperator{path[i]: op}}
+	}
+	return op
+}
+
+// QueryToSpec converts IQueryOperator into the specification AST, the reverse of SpecToQuery.
+// $in becomes OR of equalities, $between a pair of comparisons,
+// and $all becomes NOT of a wildcard matching elements which do not satisfy the query.
+// $len, $rel, and conditions on the elements of arrays of scalars are ErrUnsupportedConversion.
+func QueryToSpec(query IQueryOperator) (spec.Visitable, error) {
+	result, err := query.Accept(&specVisitor{scope: spec.GlobalScope()})
+	if err != nil {
+		return nil, err
+	}
+	return result.(spec.Visitable), nil
+}
+
+// specVisitor builds the AST of the operator applied to the field at the path within the scope.
+type specVisitor struct {
+	scope spec.EmptiableObject
+	path  []string
+}
+
+func (v *specVisitor) object() spec.EmptiableObject {
+	object := v.scope
+	for _, name := range v.path {
+		object = spec.Object(object, name)
+	}
+	return object
+}
+
+func (v *specVisitor) field() (spec.FieldNode, error) {
+	if len(v.path) == 0 {
+		return spec.FieldNode{}, fmt.Errorf("%w: condition on %s itself", ErrUnsupportedConversion, v.scope.Name())
+	}
+	object := v.scope
+	for _, name := range v.path[:len(v.path)-1] {
+		object = spec.Object(object, name)
+	}
+	return spec.Field(object, v.path[len(v.path)-1]), nil
+}
+
+func (v *specVisitor) compare(operator operators.Operator, value any) (any, error) {
+	field, err := v.field()
+	if err != nil {
+		return nil, err
+	}
+	return spec.NewInfixNode(field, operator, spec.Value(value), spec.NonAssociative), nil
+}
+
+func (v *specVisitor) VisitEq(op EqOperator) (any, error) {
+	return v.compare(operators.OperatorEq, op.Value)
+}
+
+var querySpecComparisons = map[string]operators.Operator{
+	"$ne":  operators.OperatorNe,
+	"$gt":  operators.OperatorGt,
+	"$gte": operators.OperatorGte,
+	"$lt":  operators.OperatorLt,
+	"$lte": operators.OperatorLte,
+}
+
+func (v *specVisitor) VisitComparison(op ComparisonOperator) (any, error) {
+	operator, ok := querySpecComparisons[op.Op]
+	if !ok {
+		return nil, fmt.Errorf("%w: comparison %s", ErrUnsupportedConversion, op.Op)
+	}
+	return v.compare(operator, op.Value)
+}
+
+func (v *specVisitor) VisitIn(op InOperator) (any, error) {
+	operands := make([]spec.Visitable, len(op.Values))
+	for i, value := range op.Values {
+		operand, err := v.compare(operators.OperatorEq, value)
+		if err != nil {
+			return nil, err
+		}
+		operands[i] = operand.(spec.Visitable)
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return spec.Or(operands[0], operands[1:]...), nil
+}
+
+func (v *specVisitor) VisitBetween(op BetweenOperator) (any, error) {
+	lowOp, highOp := op.BoundOps()
+	low, err := v.compare(querySpecComparisons[lowOp], op.Low)
+	if err != nil {
+		return nil, err
+	}
+	high, err := v.compare(querySpecComparisons[highOp], op.High)
+	if err != nil {
+		return nil, err
+	}
+	return spec.And(low.(spec.Visitable), high.(spec.Visitable)), nil
+}
+
+func (v *specVisitor) VisitIsNull(op IsNullOperator) (any, error) {
+	field, err := v.field()
+	if err != nil {
+		return nil, err
+	}
+	if op.Value {
+		return spec.IsNull(field), nil
+	}
+	return spec.IsNotNull(field), nil
+}
+
+func (v *specVisitor) VisitNot(op NotOperator) (any, error) {
+	operand, err := op.Operand.Accept(v)
+	if err != nil {
+		return nil, err
+	}
+	return spec.Not(operand.(spec.Visitable)), nil
+}
+
+func (v *specVisitor) VisitAnyElement(op AnyElementOperator) (any, error) {
+	return v.wildcard(op.Query, false)
+}
+
+func (v *specVisitor) VisitAllElements(op AllElementsOperator) (any, error) {
+	return v.wildcard(op.Query, true)
+}
+
+// wildcard matches elements satisfying the query, or, negated, not satisfying it.
+func (v *specVisitor) wildcard(query IQueryOperator, negated bool) (any, error) {
+	if len(v.path) == 0 {
+		return nil, fmt.Errorf("%w: wildcard without collection", ErrUnsupportedConversion)
+	}
+	result, err := query.Accept(&specVisitor{scope: spec.Item()})
+	if err != nil {
+		return nil, err
+	}
+	predicate := result.(spec.Visitable)
+	if negated {
+		return spec.Not(spec.Wildcard(v.object(), spec.Not(predicate))), nil
+	}
+	return spec.Wildcard(v.object(), predicate), nil
+}
+
+func (v *specVisitor) VisitLen(op LenOperator) (any, error) {
+	return nil, fmt.Errorf("%w: $len", ErrUnsupportedConversion)
+}
+
+func (v *specVisitor) VisitAnd(op AndOperator) (any, error) {
+	return v.logical(op.Operands, spec.And)
+}
+
+func (v *specVisitor) VisitOr(op OrOperator) (any, error) {
+	return v.logical(op.Operands, spec.Or)
+}
+
+func (v *specVisitor) logical(
+	operands []IQueryOperator, combine func(spec.Visitable, ...spec.Visitable) spec.InfixNode,
+) (any, error) {
+	nodes := make([]spec.Visitable, len(operands))
+	for i, operand := range operands {
+		node, err := operand.Accept(v)
+		if err != nil {
+			return nil, err
+		}
+		nodes[i] = node.(spec.Visitable)
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return combine(nodes[0], nodes[1:]...), nil
+}
+
+func (v *specVisitor) VisitRel(op RelOperator) (any, error) {
+	return nil, fmt.Errorf("%w: $rel", ErrUnsupportedConversion)
+}
+
+// VisitComposite combines the conditions of the fields with AND, in the order of the names.
+func (v *specVisitor) VisitComposite(op CompositeQuery) (any, error) {
+	if len(op.Fields) == 0 {
+		return nil, fmt.Errorf("%w: empty query", ErrUnsupportedConversion)
+	}
+	names := make([]string, 0, len(op.Fields))
+	for name := range op.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	nodes := make([]spec.Visitable, len(names))
+	for i, name := range names {
+		nested := &specVisitor{scope: v.scope, path: append(v.path[:len(v.path):len(v.path)], name)}
+		node, err := op.Fields[name].Accept(nested)
+		if err != nil {
+			return nil, err
+		}
+		nodes[i] = node.(spec.Visitable)
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return spec.And(nodes[0], nodes[1:]...), nil
+}
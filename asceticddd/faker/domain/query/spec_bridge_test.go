@@ -0,0 +1,150 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+	infra "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/infrastructure"
+)
+
+func TestSpecToQuery(t *testing.T) {
+	root := spec.GlobalScope()
+	cases := []struct {
+		name     string
+		node     spec.Visitable
+		expected map[string]any
+	}{
+		{
+			"comparisons",
+			spec.And(
+				spec.Equal(spec.Field(root, "status"), spec.Value("active")),
+				spec.GreaterThanEqual(spec.Field(root, "age"), spec.Value(18)),
+				spec.NotEqual(spec.Field(root, "name"), spec.Value("root")),
+			),
+			map[string]any{"status": "active", "age": map[string]any{"$gte": 18}, "name": map[string]any{"$ne": "root"}},
+		},
+		{
+			"value on the left",
+			spec.LessThan(spec.Value(18), spec.Field(root, "age")),
+			map[string]any{"age": map[string]any{"$gt": 18}},
+		},
+		{
+			"null",
+			spec.And(spec.IsNull(spec.Field(root, "email")), spec.IsNotNull(spec.Field(root, "phone"))),
+			map[string]any{"email": map[string]any{"$is_null": true}, "phone": map[string]any{"$is_null": false}},
+		},
+		{
+			"or and not",
+			spec.Or(
+				spec.Equal(spec.Field(root, "status"), spec.Value("active")),
+				spec.Not(spec.Equal(spec.Field(root, "status"), spec.Value("deleted"))),
+			),
+			map[string]any{"$or": []any{
+				map[string]any{"status": "active"},
+				map[string]any{"$not": map[string]any{"status": "deleted"}},
+			}},
+		},
+		{
+			"membership",
+			spec.And(
+				spec.In(spec.Field(root, "status"), spec.Value([]string{"active", "pending"})),
+				spec.NotIn(spec.Field(root, "role"), spec.Value([]any{"root"})),
+			),
+			map[string]any{
+				"status": map[string]any{"$in": []any{"active", "pending"}},
+				"role":   map[string]any{"$not": map[string]any{"$in": []any{"root"}}},
+			},
+		},
+		{
+			"nested object",
+			spec.GreaterThan(spec.Field(spec.Object(root, "profile"), "score"), spec.Value(5)),
+			map[string]any{"profile": map[string]any{"score": map[string]any{"$gt": 5}}},
+		},
+		{
+			"wildcard",
+			spec.Wildcard(spec.Object(root, "items"), spec.And(
+				spec.GreaterThan(spec.Field(spec.Item(), "price"), spec.Value(10)),
+				spec.Field(spec.Item(), "active"),
+			)),
+			map[string]any{"items": map[string]any{"$any": map[string]any{
+				"price":  map[string]any{"$gt": 10},
+				"active": true,
+			}}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			expected, err := ParseQuery(c.expected)
+			assert.NoError(t, err)
+			actual, err := SpecToQuery(c.node)
+			assert.NoError(t, err)
+			assert.True(t, expected.Equal(actual), "expected %v, got %v", expected, actual)
+		})
+	}
+}
+
+func TestSpecToQueryUnsupported(t *testing.T) {
+	root := spec.GlobalScope()
+	cases := []spec.Visitable{
+		spec.Equal(spec.Field(root, "a"), spec.Field(root, "b")),
+		spec.GreaterThan(spec.Add(spec.Field(root, "a"), spec.Value(1)), spec.Value(2)),
+		spec.GreaterThan(spec.Field(root, "a"), spec.Value(nil)),
+		spec.Wildcard(spec.Object(root, "items"), spec.Equal(spec.Field(root, "a"), spec.Value(1))),
+	}
+	for _, node := range cases {
+		_, err := SpecToQuery(node)
+		assert.ErrorIs(t, err, ErrUnsupportedConversion)
+	}
+}
+
+func TestQueryToSpec(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		queries := []map[string]any{
+			{"status": "active", "age": map[string]any{"$gte": 18, "$lt": 65}},
+			{"email": map[string]any{"$is_null": true}, "profile": map[string]any{"score": map[string]any{"$gt": 5}}},
+			{"$or": []any{map[string]any{"status": "active"}, map[string]any{"$not": map[string]any{"role": "guest"}}}},
+			{"items": map[string]any{"$any": map[string]any{"price": map[string]any{"$lt": 10}}}},
+		}
+		for _, raw := range queries {
+			query, err := ParseQuery(raw)
+			assert.NoError(t, err)
+			node, err := QueryToSpec(query)
+			assert.NoError(t, err)
+			actual, err := SpecToQuery(node)
+			assert.NoError(t, err)
+			assert.True(t, query.Equal(actual), "expected %v, got %v", query, actual)
+		}
+	})
+
+	t.Run("in and all compile to SQL", func(t *testing.T) {
+		query, err := ParseQuery(map[string]any{
+			"status": map[string]any{"$in": []any{"a", "b"}},
+			"items":  map[string]any{"$all": map[string]any{"active": true}},
+		})
+		assert.NoError(t, err)
+		node, err := QueryToSpec(query)
+		assert.NoError(t, err)
+
+		sql, params, err := infra.CompileToSQL(node)
+		assert.NoError(t, err)
+		assert.Equal(t,
+			"NOT EXISTS (SELECT 1 FROM unnest(items) AS item_1 WHERE NOT item_1.active = $1) AND (status = $2 OR status = $3)",
+			sql)
+		assert.Equal(t, []any{true, "a", "b"}, params)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		for _, raw := range []map[string]any{
+			{"tags": map[string]any{"$len": 2}},
+			{"company_id": map[string]any{"$rel": map[string]any{"type": "tech"}}},
+			{"tags": map[string]any{"$any": map[string]any{"$eq": "go"}}},
+		} {
+			query, err := ParseQuery(raw)
+			assert.NoError(t, err)
+			_, err = QueryToSpec(query)
+			assert.ErrorIs(t, err, ErrUnsupportedConversion, "%v", raw)
+		}
+	})
+}
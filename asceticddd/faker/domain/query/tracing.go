@@ -0,0 +1,131 @@
+package query
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+type QueryOperation string
+
+const (
+	QueryOperationEvaluate QueryOperation = "evaluate"
+	QueryOperationResolve  QueryOperation = "resolve"
+	QueryOperationCompile  QueryOperation = "compile"
+	QueryOperationFind     QueryOperation = "find"
+)
+
+// QueryEvent describes the traced operation.
+type QueryEvent struct {
+	Operation QueryOperation
+	// Shape is the query with values stripped, see QueryShape, so it is a label of low cardinality.
+	// For QueryOperationResolve it is the shape of the query of the related aggregate.
+	Shape string
+	// Field is the relation field of QueryOperationResolve, empty for the root.
+	Field string
+}
+
+// IQueryTracer instruments evaluation, relation resolution, compilation and finding by queries,
+// e.g. with OpenTelemetry spans or with latency histograms labeled by the query shape.
+type IQueryTracer interface {
+	// StartQuery is called before the operation, the returned context is passed to the nested operations,
+	// e.g. the context of the started span, and end is called with the error of the operation when it is done.
+	StartQuery(ctx context.Context, event QueryEvent) (context.Context, func(err error))
+}
+
+// QueryTimer is IQueryTracer reporting the duration of every operation to the observer, e.g. to a histogram.
+type QueryTimer func(ctx context.Context, event QueryEvent, elapsed time.Duration, err error)
+
+func (t QueryTimer) StartQuery(ctx context.Context, event QueryEvent) (context.Context, func(err error)) {
+	start := time.Now()
+	return ctx, func(err error) {
+		t(ctx, event, time.Since(start), err)
+	}
+}
+
+// TraceQuery starts tracing of the operation, it does nothing without the tracer.
+func TraceQuery(
+	ctx context.Context,
+	tracer IQueryTracer,
+	operation QueryOperation,
+	field string,
+	query IQueryOperator,
+) (context.Context, func(err error)) {
+	if tracer == nil {
+		return ctx, func(err error) {}
+	}
+	return tracer.StartQuery(ctx, QueryEvent{Operation: operation, Shape: QueryShape(query), Field: field})
+}
+
+// QueryShape renders the structure of the query with fields in the order of their names and values stripped,
+// e.g. {age:$gt,company_id:$rel({name:$eq})}, so that queries differing only in values have the same shape.
+func QueryShape(query IQueryOperator) string {
+	var b strings.Builder
+	writeQueryShape(&b, query)
+	return b.String()
+}
+
+func writeQueryShape(b *strings.Builder, query IQueryOperator) {
+	switch q := query.(type) {
+	case nil:
+		b.WriteString("*")
+	case EqOperator:
+		b.WriteString("$eq")
+	case ComparisonOperator:
+		b.WriteString(q.Op)
+	case InOperator:
+		b.WriteString("$in")
+	case BetweenOperator:
+		b.WriteString("$between")
+	case IsNullOperator:
+		b.WriteString("$is_null")
+	case NotOperator:
+		writeNestedShape(b, "$not", q.Operand)
+	case AnyElementOperator:
+		writeNestedShape(b, "$any", q.Query)
+	case AllElementsOperator:
+		writeNestedShape(b, "$all", q.Query)
+	case LenOperator:
+		writeNestedShape(b, "$len", q.Query)
+	case RelOperator:
+		writeNestedShape(b, "$rel", q.Query)
+	case AndOperator:
+		writeOperandsShape(b, "$and", q.Operands)
+	case OrOperator:
+		writeOperandsShape(b, "$or", q.Operands)
+	case CompositeQuery:
+		b.WriteString("{")
+		for i, field := range slices.Sorted(maps.Keys(q.Fields)) {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString(field)
+			b.WriteString(":")
+			writeQueryShape(b, q.Fields[field])
+		}
+		b.WriteString("}")
+	case ICustomOperator:
+		b.WriteString(q.Name())
+	}
+}
+
+func writeNestedShape(b *strings.Builder, name string, query IQueryOperator) {
+	b.WriteString(name)
+	b.WriteString("(")
+	writeQueryShape(b, query)
+	b.WriteString(")")
+}
+
+func writeOperandsShape(b *strings.Builder, name string, operands []IQueryOperator) {
+	b.WriteString(name)
+	b.WriteString("(")
+	for i, operand := range operands {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		writeQueryShape(b, operand)
+	}
+	b.WriteString(")")
+}
@@ -0,0 +1,47 @@
+package query
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryShape(t *testing.T) {
+	query := CompositeQuery{Fields: map[string]IQueryOperator{
+		"status": InOperator{Values: []any{"active", "pending"}},
+		"age":    ComparisonOperator{Op: "$gt", Value: 18},
+		"company_id": RelOperator{Query: CompositeQuery{Fields: map[string]IQueryOperator{
+			"name": OrOperator{Operands: []IQueryOperator{EqOperator{Value: "Acme"}, IsNullOperator{Value: true}}},
+		}}},
+		"tags": AnyElementOperator{Query: NotOperator{Operand: EqOperator{Value: "vip"}}},
+	}}
+	assert.Equal(t, "{age:$gt,company_id:$rel({name:$or($eq,$is_null)}),status:$in,tags:$any($not($eq))}", QueryShape(query))
+	assert.Equal(t, "*", QueryShape(nil))
+}
+
+func TestEvaluateWalkerTracer(t *testing.T) {
+	var events []QueryEvent
+	var errs []error
+	timer := QueryTimer(func(ctx context.Context, event QueryEvent, elapsed time.Duration, err error) {
+		events = append(events, event)
+		errs = append(errs, err)
+	})
+	resolver := &contextRecordingResolver{state: map[string]any{"name": "Active"}}
+	walker := NewEvaluateWalker(resolver, WithTracer(timer))
+
+	result, err := walker.Evaluate(sess, CompositeQuery{Fields: map[string]IQueryOperator{
+		"status_id": RelOperator{Query: CompositeQuery{Fields: map[string]IQueryOperator{
+			"name": EqOperator{Value: "Active"},
+		}}},
+	}}, map[string]any{"status_id": "active"})
+	require.NoError(t, err)
+	assert.True(t, result)
+	assert.Equal(t, []QueryEvent{
+		{Operation: QueryOperationResolve, Shape: "{name:$eq}", Field: "status_id"},
+		{Operation: QueryOperationEvaluate, Shape: "{status_id:$rel({name:$eq})}"},
+	}, events)
+	assert.Equal(t, []error{nil, nil}, errs)
+}
@@ -1,5 +1,14 @@
 package query
 
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // QueryToDictVisitor converts IQueryOperator to map[string]any with operators.
 type QueryToDictVisitor struct{}
 
@@ -32,6 +41,10 @@ func (v QueryToDictVisitor) VisitIn(op InOperator) (any, error) {
 	return map[string]any{"$in": values}, nil
 }
 
+func (v QueryToDictVisitor) VisitBetween(op BetweenOperator) (any, error) {
+	return betweenToDict(op), nil
+}
+
 func (v QueryToDictVisitor) VisitIsNull(op IsNullOperator) (any, error) {
 	return map[string]any{"$is_null": op.Value}, nil
 }
@@ -138,6 +151,10 @@ func (v QueryToPlainValueVisitor) VisitIn(op InOperator) (any, error) {
 	return map[string]any{"$in": values}, nil
 }
 
+func (v QueryToPlainValueVisitor) VisitBetween(op BetweenOperator) (any, error) {
+	return betweenToDict(op), nil
+}
+
 func (v QueryToPlainValueVisitor) VisitIsNull(op IsNullOperator) (any, error) {
 	return map[string]any{"$is_null": op.Value}, nil
 }
@@ -216,9 +233,179 @@ func (v QueryToPlainValueVisitor) VisitComposite(op CompositeQuery) (any, error)
 	return result, nil
 }
 
+// QueryToStringVisitor converts IQueryOperator to a readable deterministic string
+// for logging and error messages, e.g. status == "active" AND company_id -> (type == "tech").
+// Fields are sorted by name. The result is not meant to be parsed back.
+type QueryToStringVisitor struct {
+	subject string
+}
+
+func (v QueryToStringVisitor) Visit(op IQueryOperator) (string, error) {
+	result, err := op.Accept(v)
+	if err != nil {
+		return "", err
+	}
+	return result.(string), nil
+}
+
+func (v QueryToStringVisitor) VisitEq(op EqOperator) (any, error) {
+	if inner, ok := op.Value.(IQueryOperator); ok {
+		return inner.Accept(v)
+	}
+	return v.binary("==", op.Value), nil
+}
+
+func (v QueryToStringVisitor) VisitComparison(op ComparisonOperator) (any, error) {
+	symbol, ok := comparisonSymbols[op.Op]
+	if !ok {
+		return nil, fmt.Errorf("unknown comparison operator: %s", op.Op)
+	}
+	return v.binary(symbol, op.Value), nil
+}
+
+func (v QueryToStringVisitor) VisitIn(op InOperator) (any, error) {
+	values := make([]string, len(op.Values))
+	for i, value := range op.Values {
+		values[i] = formatQueryValue(value)
+	}
+	return fmt.Sprintf("%s IN (%s)", v.subjectOrRoot(), strings.Join(values, ", ")), nil
+}
+
+func (v QueryToStringVisitor) VisitBetween(op BetweenOperator) (any, error) {
+	if op.Inclusive {
+		return fmt.Sprintf(
+			"%s BETWEEN %s AND %s", v.subjectOrRoot(), formatQueryValue(op.Low), formatQueryValue(op.High),
+		), nil
+	}
+	return fmt.Sprintf("%s AND %s", v.binary(">", op.Low), v.binary("<", op.High)), nil
+}
+
+func (v QueryToStringVisitor) VisitIsNull(op IsNullOperator) (any, error) {
+	if op.Value {
+		return v.subjectOrRoot() + " IS NULL", nil
+	}
+	return v.subjectOrRoot() + " IS NOT NULL", nil
+}
+
+func (v QueryToStringVisitor) VisitNot(op NotOperator) (any, error) {
+	inner, err := op.Operand.Accept(v)
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Sprintf("NOT (%s)", inner), nil
+}
+
+func (v QueryToStringVisitor) VisitAnyElement(op AnyElementOperator) (any, error) {
+	return v.nested("ANY", op.Query)
+}
+
+func (v QueryToStringVisitor) VisitAllElements(op AllElementsOperator) (any, error) {
+	return v.nested("ALL", op.Query)
+}
+
+func (v QueryToStringVisitor) VisitLen(op LenOperator) (any, error) {
+	return op.Query.Accept(QueryToStringVisitor{subject: fmt.Sprintf("len(%s)", v.subjectOrRoot())})
+}
+
+func (v QueryToStringVisitor) VisitAnd(op AndOperator) (any, error) {
+	return v.join(op.Operands, " AND ")
+}
+
+func (v QueryToStringVisitor) VisitOr(op OrOperator) (any, error) {
+	result, err := v.join(op.Operands, " OR ")
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Sprintf("(%s)", result), nil
+}
+
+func (v QueryToStringVisitor) VisitRel(op RelOperator) (any, error) {
+	return v.nested("->", op.Query)
+}
+
+func (v QueryToStringVisitor) VisitComposite(op CompositeQuery) (any, error) {
+	fields := make([]string, 0, len(op.Fields))
+	for field := range op.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		subject := field
+		if v.subject != "" {
+			subject = v.subject + "." + field
+		}
+		result, err := op.Fields[field].Accept(QueryToStringVisitor{subject: subject})
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = result.(string)
+	}
+	return strings.Join(parts, " AND "), nil
+}
+
+func (v QueryToStringVisitor) binary(symbol string, value any) string {
+	return fmt.Sprintf("%s %s %s", v.subjectOrRoot(), symbol, formatQueryValue(value))
+}
+
+// nested renders a query over related objects or array elements, relative to them.
+func (v QueryToStringVisitor) nested(keyword string, query IQueryOperator) (any, error) {
+	inner, err := query.Accept(QueryToStringVisitor{})
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Sprintf("%s %s (%s)", v.subjectOrRoot(), keyword, inner), nil
+}
+
+func (v QueryToStringVisitor) join(operands []IQueryOperator, separator string) (string, error) {
+	parts := make([]string, len(operands))
+	for i, operand := range operands {
+		result, err := operand.Accept(v)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = result.(string)
+	}
+	return strings.Join(parts, separator), nil
+}
+
+func (v QueryToStringVisitor) subjectOrRoot() string {
+	if v.subject == "" {
+		return "$"
+	}
+	return v.subject
+}
+
+var comparisonSymbols = map[string]string{
+	"$ne":  "!=",
+	"$gt":  ">",
+	"$gte": ">=",
+	"$lt":  "<",
+	"$lte": "<=",
+}
+
+func formatQueryValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(v)
+	case time.Time:
+		return strconv.Quote(v.Format(time.RFC3339Nano))
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return fmt.Sprint(v)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
 var (
 	queryToDictVisitor       = QueryToDictVisitor{}
 	queryToPlainValueVisitor = QueryToPlainValueVisitor{}
+	queryToStringVisitor     = QueryToStringVisitor{}
 )
 
 // QueryToDict converts IQueryOperator to map[string]any with operators.
@@ -231,6 +418,11 @@ func QueryToPlainValue(op IQueryOperator) (any, error) {
 	return queryToPlainValueVisitor.Visit(op)
 }
 
+// QueryToString converts IQueryOperator to a readable string, see QueryToStringVisitor.
+func QueryToString(op IQueryOperator) (string, error) {
+	return queryToStringVisitor.Visit(op)
+}
+
 // DictToQuery converts plain dict to query format with $eq operators.
 func DictToQuery(value any) any {
 	if m, ok := value.(map[string]any); ok {
@@ -242,3 +434,10 @@ func DictToQuery(value any) any {
 	}
 	return map[string]any{"$eq": value}
 }
+
+func betweenToDict(op BetweenOperator) map[string]any {
+	if op.Inclusive {
+		return map[string]any{"$between": []any{op.Low, op.High}}
+	}
+	return map[string]any{"$between": map[string]any{"low": op.Low, "high": op.High, "inclusive": false}}
+}
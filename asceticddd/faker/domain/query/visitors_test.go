@@ -2,6 +2,7 @@ package query
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -193,6 +194,28 @@ func TestQueryToDictVisitorIn(t *testing.T) {
 	})
 }
 
+func TestQueryToDictVisitorBetween(t *testing.T) {
+	v := QueryToDictVisitor{}
+
+	t.Run("inclusive roundtrip", func(t *testing.T) {
+		op := BetweenOperator{Low: 18, High: 65, Inclusive: true}
+		result, err := v.Visit(op)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"$between": []any{18, 65}}, result)
+		parsed, err := QueryParser{}.Parse(result)
+		assert.NoError(t, err)
+		assert.True(t, op.Equal(parsed))
+	})
+	t.Run("exclusive roundtrip", func(t *testing.T) {
+		op := BetweenOperator{Low: 18, High: 65}
+		result, err := v.Visit(op)
+		assert.NoError(t, err)
+		parsed, err := QueryParser{}.Parse(result)
+		assert.NoError(t, err)
+		assert.True(t, op.Equal(parsed))
+	})
+}
+
 func TestQueryToDictVisitorIsNull(t *testing.T) {
 	v := QueryToDictVisitor{}
 
@@ -593,6 +616,103 @@ func TestQueryToPlainValueVisitorLen(t *testing.T) {
 	})
 }
 
+// =============================================================================
+// QueryToStringVisitor
+// =============================================================================
+
+func TestQueryToStringVisitor(t *testing.T) {
+	cases := []struct {
+		name     string
+		query    IQueryOperator
+		expected string
+	}{
+		{
+			"fields and relation",
+			CompositeQuery{Fields: map[string]IQueryOperator{
+				"status": EqOperator{Value: "active"},
+				"company_id": RelOperator{Query: CompositeQuery{Fields: map[string]IQueryOperator{
+					"type": EqOperator{Value: "tech"},
+				}}},
+			}},
+			`company_id -> (type == "tech") AND status == "active"`,
+		},
+		{
+			"comparisons",
+			CompositeQuery{Fields: map[string]IQueryOperator{
+				"age": AndOperator{Operands: []IQueryOperator{
+					ComparisonOperator{Op: "$gte", Value: 18},
+					ComparisonOperator{Op: "$ne", Value: 30},
+				}},
+			}},
+			`age >= 18 AND age != 30`,
+		},
+		{
+			"in, null and between",
+			CompositeQuery{Fields: map[string]IQueryOperator{
+				"a": InOperator{Values: []any{"x", 1, nil}},
+				"b": IsNullOperator{Value: true},
+				"c": IsNullOperator{Value: false},
+				"d": BetweenOperator{Low: 1, High: 5, Inclusive: true},
+				"e": BetweenOperator{Low: 1, High: 5},
+			}},
+			`a IN ("x", 1, null) AND b IS NULL AND c IS NOT NULL AND d BETWEEN 1 AND 5 AND e > 1 AND e < 5`,
+		},
+		{
+			"or and not",
+			CompositeQuery{Fields: map[string]IQueryOperator{
+				"status": OrOperator{Operands: []IQueryOperator{
+					EqOperator{Value: "a"},
+					NotOperator{Operand: EqOperator{Value: "b"}},
+				}},
+				"tier": EqOperator{Value: 1},
+			}},
+			`(status == "a" OR NOT (status == "b")) AND tier == 1`,
+		},
+		{
+			"nested fields",
+			CompositeQuery{Fields: map[string]IQueryOperator{
+				"profile": CompositeQuery{Fields: map[string]IQueryOperator{"score": EqOperator{Value: 7.5}}},
+			}},
+			`profile.score == 7.5`,
+		},
+		{
+			"array elements and length",
+			CompositeQuery{Fields: map[string]IQueryOperator{
+				"items": AnyElementOperator{Query: CompositeQuery{Fields: map[string]IQueryOperator{
+					"price": ComparisonOperator{Op: "$lt", Value: 10},
+				}}},
+				"tags": AndOperator{Operands: []IQueryOperator{
+					AllElementsOperator{Query: EqOperator{Value: "x"}},
+					LenOperator{Query: ComparisonOperator{Op: "$gt", Value: 2}},
+				}},
+			}},
+			`items ANY (price < 10) AND tags ALL ($ == "x") AND len(tags) > 2`,
+		},
+		{
+			"values",
+			CompositeQuery{Fields: map[string]IQueryOperator{
+				"at":   EqOperator{Value: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+				"meta": EqOperator{Value: map[string]any{"b": 1, "a": []any{true}}},
+				"name": EqOperator{Value: "say \"hi\""},
+			}},
+			`at == "2024-03-01T12:00:00Z" AND meta == {"a":[true],"b":1} AND name == "say \"hi\""`,
+		},
+		{"root", EqOperator{Value: 42}, `$ == 42`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result, err := QueryToString(c.query)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, result)
+		})
+	}
+
+	t.Run("unknown comparison", func(t *testing.T) {
+		_, err := QueryToString(ComparisonOperator{Op: "$like", Value: "x"})
+		assert.Error(t, err)
+	})
+}
+
 // =============================================================================
 // Convenience functions
 // =============================================================================
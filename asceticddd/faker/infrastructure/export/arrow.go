@@ -0,0 +1,91 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+// ArrowSchema returns the Arrow schema of the columns, all of them nullable:
+// float64 for numeric, utf8 for text and uuid, bool for bool
+// and timestamp of microseconds in UTC for datetime.
+func ArrowSchema(schema Schema) (*arrow.Schema, error) {
+	fields := make([]arrow.Field, len(schema))
+	for i, column := range schema {
+		var dataType arrow.DataType
+		switch column.Type {
+		case domainquery.TypeHintNumeric:
+			dataType = arrow.PrimitiveTypes.Float64
+		case domainquery.TypeHintText, domainquery.TypeHintUuid:
+			dataType = arrow.BinaryTypes.String
+		case domainquery.TypeHintBool:
+			dataType = arrow.FixedWidthTypes.Boolean
+		case domainquery.TypeHintDatetime:
+			dataType = &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}
+		default:
+			return nil, fmt.Errorf("column %s: unknown type hint %q", column.Path, column.Type)
+		}
+		fields[i] = arrow.Field{Name: column.Path, Type: dataType, Nullable: true}
+	}
+	return arrow.NewSchema(fields, nil), nil
+}
+
+// EachRecord calls fn with the Arrow record batch of each batch of the states
+// and returns the number of the states. The record is released when fn returns,
+// fn has to Retain it to keep it longer.
+func (e *Exporter) EachRecord(states States, fn func(record array.Record) error) (int, error) {
+	schema, err := ArrowSchema(e.schema)
+	if err != nil {
+		return 0, err
+	}
+	builder := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	defer builder.Release()
+	return e.eachBatch(states, func(rows [][]any) error {
+		for _, row := range rows {
+			for i, value := range row {
+				appendArrowValue(builder.Field(i), value)
+			}
+		}
+		record := builder.NewRecord()
+		defer record.Release()
+		return fn(record)
+	})
+}
+
+// ExportArrow writes the states into the Arrow IPC stream and returns their number.
+func (e *Exporter) ExportArrow(w io.Writer, states States) (int, error) {
+	schema, err := ArrowSchema(e.schema)
+	if err != nil {
+		return 0, err
+	}
+	writer := ipc.NewWriter(w, ipc.WithSchema(schema))
+	count, err := e.EachRecord(states, writer.Write)
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	return count, err
+}
+
+func appendArrowValue(builder array.Builder, value any) {
+	if value == nil {
+		builder.AppendNull()
+		return
+	}
+	switch b := builder.(type) {
+	case *array.Float64Builder:
+		b.Append(value.(float64))
+	case *array.StringBuilder:
+		b.Append(value.(string))
+	case *array.BooleanBuilder:
+		b.Append(value.(bool))
+	case *array.TimestampBuilder:
+		b.Append(arrow.Timestamp(value.(time.Time).UnixMicro()))
+	}
+}
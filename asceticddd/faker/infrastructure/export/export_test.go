@@ -0,0 +1,174 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+var (
+	testSchema = Schema{
+		{Path: "age", Type: domainquery.TypeHintNumeric},
+		{Path: "active", Type: domainquery.TypeHintBool},
+		{Path: "name", Type: domainquery.TypeHintText},
+		{Path: "profile.joined", Type: domainquery.TypeHintDatetime},
+	}
+	testJoined = time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC)
+	testStates = []map[string]any{
+		{"age": 30, "active": true, "name": "Ann", "profile": map[string]any{"joined": "2024-01-02T03:04:05.000006Z"}},
+		{"age": "41", "active": "false"},
+		{"age": "unknown", "active": 1, "name": "Bob", "profile": "none"},
+	}
+	// testColumns are the columns of testStates.
+	testColumns = [][]any{
+		{30.0, 41.0, nil},
+		{true, false, nil},
+		{"Ann", nil, "Bob"},
+		{testJoined, nil, nil},
+	}
+)
+
+type failingStates struct {
+	States
+	err error
+}
+
+func (s failingStates) Err() error {
+	return s.err
+}
+
+func TestSchemaFromFieldTypes(t *testing.T) {
+	schema := SchemaFromFieldTypes(domainquery.FieldTypes{
+		"name":                  domainquery.TypeHintText,
+		"address.zip":           domainquery.TypeHintText,
+		"age":                   domainquery.TypeHintNumeric,
+		"events[*].occurred_at": domainquery.TypeHintDatetime,
+	})
+	assert.Equal(t, Schema{
+		{Path: "address.zip", Type: domainquery.TypeHintText},
+		{Path: "age", Type: domainquery.TypeHintNumeric},
+		{Path: "name", Type: domainquery.TypeHintText},
+	}, schema)
+}
+
+func TestArrowSchema(t *testing.T) {
+	schema, err := ArrowSchema(testSchema)
+	require.NoError(t, err)
+	assert.Equal(t, arrow.NewSchema([]arrow.Field{
+		{Name: "age", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "active", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "profile.joined", Type: &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}, Nullable: true},
+	}, nil), schema)
+
+	_, err = ArrowSchema(Schema{{Path: "tags", Type: "array"}})
+	assert.EqualError(t, err, `column tags: unknown type hint "array"`)
+}
+
+func TestEachRecord(t *testing.T) {
+	exporter := NewExporter(testSchema)
+	exporter.SetBatchSize(2)
+
+	var batches [][][]any
+	count, err := exporter.EachRecord(SliceStates(testStates), func(record array.Record) error {
+		batches = append(batches, arrowColumns(record))
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, count)
+	require.Len(t, batches, 2)
+	assert.Equal(t, sliceColumns(testColumns, 0, 2), batches[0])
+	assert.Equal(t, sliceColumns(testColumns, 2, 3), batches[1])
+}
+
+func TestExportArrow(t *testing.T) {
+	exporter := NewExporter(testSchema)
+	exporter.SetBatchSize(2)
+
+	var buf bytes.Buffer
+	count, err := exporter.ExportArrow(&buf, SliceStates(testStates))
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	reader, err := ipc.NewReader(&buf, ipc.WithAllocator(memory.NewGoAllocator()))
+	require.NoError(t, err)
+	defer reader.Release()
+	expected, err := ArrowSchema(testSchema)
+	require.NoError(t, err)
+	assert.True(t, expected.Equal(reader.Schema()))
+
+	var rows int
+	var columns [][]any
+	for reader.Next() {
+		record := reader.Record()
+		rows += int(record.NumRows())
+		for i, column := range arrowColumns(record) {
+			if len(columns) <= i {
+				columns = append(columns, nil)
+			}
+			columns[i] = append(columns[i], column...)
+		}
+	}
+	require.NoError(t, reader.Err())
+	assert.Equal(t, 3, rows)
+	assert.Equal(t, testColumns, columns)
+}
+
+func TestExportErrors(t *testing.T) {
+	t.Run("states", func(t *testing.T) {
+		statesErr := errors.New("connection reset")
+		states := failingStates{States: SliceStates(testStates[:1]), err: statesErr}
+		_, err := NewExporter(testSchema).ExportParquet(&bytes.Buffer{}, states)
+		assert.ErrorIs(t, err, statesErr)
+	})
+
+	t.Run("record", func(t *testing.T) {
+		recordErr := errors.New("disk full")
+		_, err := NewExporter(testSchema).EachRecord(SliceStates(testStates), func(array.Record) error {
+			return recordErr
+		})
+		assert.EqualError(t, err, "write batch of 3 records: disk full")
+	})
+}
+
+func arrowColumns(record array.Record) [][]any {
+	columns := make([][]any, record.NumCols())
+	for i, column := range record.Columns() {
+		for j := 0; j < column.Len(); j++ {
+			if column.IsNull(j) {
+				columns[i] = append(columns[i], nil)
+				continue
+			}
+			switch c := column.(type) {
+			case *array.Float64:
+				columns[i] = append(columns[i], c.Value(j))
+			case *array.Boolean:
+				columns[i] = append(columns[i], c.Value(j))
+			case *array.String:
+				columns[i] = append(columns[i], c.Value(j))
+			case *array.Timestamp:
+				columns[i] = append(columns[i], time.UnixMicro(int64(c.Value(j))).UTC())
+			}
+		}
+	}
+	return columns
+}
+
+func sliceColumns(columns [][]any, from, to int) [][]any {
+	sliced := make([][]any, len(columns))
+	for i, column := range columns {
+		sliced[i] = column[from:to]
+	}
+	return sliced
+}
@@ -0,0 +1,64 @@
+package export
+
+import (
+	"fmt"
+)
+
+// Exporter converts states into batches of rows of the schema:
+// Arrow record batches, see EachRecord and ExportArrow, or Parquet row groups, see ExportParquet.
+// Only one batch is held in memory at a time.
+type Exporter struct {
+	schema    Schema
+	keys      [][]string
+	batchSize int
+}
+
+func NewExporter(schema Schema) *Exporter {
+	return &Exporter{
+		schema:    schema,
+		keys:      schema.keys(),
+		batchSize: defaultBatchSize,
+	}
+}
+
+// SetBatchSize sets the number of rows of a record batch or a row group, 1024 by default.
+func (e *Exporter) SetBatchSize(batchSize int) {
+	e.batchSize = batchSize
+}
+
+func (e *Exporter) Schema() Schema {
+	return e.schema
+}
+
+// eachBatch calls fn with the rows of each batch of the states and returns the number of the states.
+func (e *Exporter) eachBatch(states States, fn func(rows [][]any) error) (int, error) {
+	batchSize := e.batchSize
+	if batchSize < 1 {
+		batchSize = defaultBatchSize
+	}
+	count := 0
+	rows := make([][]any, 0, batchSize)
+	flush := func() error {
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := fn(rows); err != nil {
+			return fmt.Errorf("write batch of %d records: %w", len(rows), err)
+		}
+		count += len(rows)
+		rows = rows[:0]
+		return nil
+	}
+	for states.Next() {
+		rows = append(rows, e.schema.row(e.keys, states.State()))
+		if len(rows) >= batchSize {
+			if err := flush(); err != nil {
+				return count, err
+			}
+		}
+	}
+	if err := states.Err(); err != nil {
+		return count, err
+	}
+	return count, flush()
+}
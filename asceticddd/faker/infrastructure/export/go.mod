@@ -0,0 +1,20 @@
+module github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/infrastructure/export
+
+go 1.25
+
+require (
+	github.com/apache/arrow/go/arrow v0.0.0-20200730104253-651201b0f516
+	github.com/krew-solutions/ascetic-ddd-go v0.0.0
+	github.com/stretchr/testify v1.8.4
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/google/flatbuffers v1.11.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sync v0.8.0 // indirect
+	golang.org/x/xerrors v0.0.0-20240903120638-7835f813f4da // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/krew-solutions/ascetic-ddd-go => ../../../..
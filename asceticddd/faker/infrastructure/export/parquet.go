@@ -0,0 +1,262 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+const parquetMagic = "PAR1"
+
+// Enums of parquet.thrift.
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetUtf8            = 0
+	parquetTimestampMicros = 10
+
+	parquetOptional = 1
+
+	parquetPlain = 0
+	parquetRle   = 3
+
+	parquetUncompressed = 0
+	parquetDataPage     = 0
+)
+
+// ExportParquet writes the states into the Parquet file, one row group per batch, and returns their number.
+// All columns are optional: DOUBLE for numeric, BYTE_ARRAY of UTF8 for text and uuid, BOOLEAN for bool
+// and INT64 of TIMESTAMP_MICROS in UTC for datetime. Pages are PLAIN encoded and not compressed.
+func (e *Exporter) ExportParquet(w io.Writer, states States) (int, error) {
+	writer, err := newParquetWriter(w, e.schema)
+	if err != nil {
+		return 0, err
+	}
+	count, err := e.eachBatch(states, writer.writeRowGroup)
+	if err != nil {
+		return count, err
+	}
+	return count, writer.close()
+}
+
+type parquetColumn struct {
+	physicalType  int32
+	convertedType int32
+	logicalType   func(w *thriftWriter)
+}
+
+func parquetColumnOf(column Column) (parquetColumn, error) {
+	switch column.Type {
+	case domainquery.TypeHintNumeric:
+		return parquetColumn{physicalType: parquetDouble, convertedType: -1}, nil
+	case domainquery.TypeHintText, domainquery.TypeHintUuid:
+		return parquetColumn{physicalType: parquetByteArray, convertedType: parquetUtf8, logicalType: func(w *thriftWriter) {
+			w.FieldStruct(1) // STRING
+			w.EndStruct()
+		}}, nil
+	case domainquery.TypeHintBool:
+		return parquetColumn{physicalType: parquetBoolean, convertedType: -1}, nil
+	case domainquery.TypeHintDatetime:
+		return parquetColumn{physicalType: parquetInt64, convertedType: parquetTimestampMicros, logicalType: func(w *thriftWriter) {
+			w.FieldStruct(8) // TIMESTAMP
+			w.FieldBool(1, true)
+			w.FieldStruct(2)
+			w.FieldStruct(2) // MICROS
+			w.EndStruct()
+			w.EndStruct()
+			w.EndStruct()
+		}}, nil
+	}
+	return parquetColumn{}, fmt.Errorf("column %s: unknown type hint %q", column.Path, column.Type)
+}
+
+type parquetChunk struct {
+	numValues      int64
+	dataPageOffset int64
+	size           int64
+}
+
+type parquetRowGroup struct {
+	numRows int64
+	size    int64
+	chunks  []parquetChunk
+}
+
+// parquetWriter writes row groups as they come and the metadata of the file on close.
+type parquetWriter struct {
+	w         io.Writer
+	offset    int64
+	schema    Schema
+	columns   []parquetColumn
+	rowGroups []parquetRowGroup
+	numRows   int64
+}
+
+func newParquetWriter(w io.Writer, schema Schema) (*parquetWriter, error) {
+	columns := make([]parquetColumn, len(schema))
+	for i, column := range schema {
+		var err error
+		if columns[i], err = parquetColumnOf(column); err != nil {
+			return nil, err
+		}
+	}
+	writer := &parquetWriter{w: w, schema: schema, columns: columns}
+	if err := writer.write([]byte(parquetMagic)); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (w *parquetWriter) write(data []byte) error {
+	n, err := w.w.Write(data)
+	w.offset += int64(n)
+	return err
+}
+
+func (w *parquetWriter) writeRowGroup(rows [][]any) error {
+	rowGroup := parquetRowGroup{numRows: int64(len(rows)), chunks: make([]parquetChunk, len(w.columns))}
+	for i, column := range w.columns {
+		page := encodeParquetPage(column.physicalType, rows, i)
+		header := &thriftWriter{}
+		header.FieldI32(1, parquetDataPage)
+		header.FieldI32(2, int32(len(page)))
+		header.FieldI32(3, int32(len(page)))
+		header.FieldStruct(5)
+		header.FieldI32(1, int32(len(rows)))
+		header.FieldI32(2, parquetPlain)
+		header.FieldI32(3, parquetRle)
+		header.FieldI32(4, parquetRle)
+		header.EndStruct()
+		header.EndStruct()
+
+		chunk := parquetChunk{numValues: int64(len(rows)), dataPageOffset: w.offset}
+		if err := w.write(header.Bytes()); err != nil {
+			return err
+		}
+		if err := w.write(page); err != nil {
+			return err
+		}
+		chunk.size = w.offset - chunk.dataPageOffset
+		rowGroup.size += chunk.size
+		rowGroup.chunks[i] = chunk
+	}
+	w.rowGroups = append(w.rowGroups, rowGroup)
+	w.numRows += rowGroup.numRows
+	return nil
+}
+
+// encodeParquetPage returns the data page of the column of the rows:
+// the definition levels, 1 for values and 0 for nulls, and the PLAIN values.
+func encodeParquetPage(physicalType int32, rows [][]any, column int) []byte {
+	levels := make([]byte, len(rows))
+	var values bytes.Buffer
+	var bits []bool
+	for i, row := range rows {
+		value := row[column]
+		if value == nil {
+			continue
+		}
+		levels[i] = 1
+		switch physicalType {
+		case parquetDouble:
+			values.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(value.(float64))))
+		case parquetByteArray:
+			str := value.(string)
+			values.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(str))))
+			values.WriteString(str)
+		case parquetBoolean:
+			bits = append(bits, value.(bool))
+		case parquetInt64:
+			values.Write(binary.LittleEndian.AppendUint64(nil, uint64(value.(time.Time).UnixMicro())))
+		}
+	}
+	if physicalType == parquetBoolean {
+		packed := make([]byte, (len(bits)+7)/8)
+		for i, bit := range bits {
+			if bit {
+				packed[i/8] |= 1 << (i % 8)
+			}
+		}
+		values.Write(packed)
+	}
+
+	encodedLevels := encodeRleLevels(levels)
+	page := binary.LittleEndian.AppendUint32(nil, uint32(len(encodedLevels)))
+	page = append(page, encodedLevels...)
+	return append(page, values.Bytes()...)
+}
+
+// encodeRleLevels encodes the levels of the bit width 1 by the RLE runs of the RLE/bit-packing hybrid.
+func encodeRleLevels(levels []byte) []byte {
+	var encoded []byte
+	for start := 0; start < len(levels); {
+		end := start + 1
+		for end < len(levels) && levels[end] == levels[start] {
+			end++
+		}
+		encoded = binary.AppendUvarint(encoded, uint64(end-start)<<1)
+		encoded = append(encoded, levels[start])
+		start = end
+	}
+	return encoded
+}
+
+func (w *parquetWriter) close() error {
+	meta := &thriftWriter{}
+	meta.FieldI32(1, 1)
+	meta.FieldStructList(2, len(w.schema)+1, func(i int) {
+		if i == 0 {
+			meta.FieldString(4, "schema")
+			meta.FieldI32(5, int32(len(w.schema)))
+			return
+		}
+		column := w.columns[i-1]
+		meta.FieldI32(1, column.physicalType)
+		meta.FieldI32(3, parquetOptional)
+		meta.FieldString(4, w.schema[i-1].Path)
+		if column.convertedType >= 0 {
+			meta.FieldI32(6, column.convertedType)
+		}
+		if column.logicalType != nil {
+			meta.FieldStruct(10)
+			column.logicalType(meta)
+			meta.EndStruct()
+		}
+	})
+	meta.FieldI64(3, w.numRows)
+	meta.FieldStructList(4, len(w.rowGroups), func(i int) {
+		rowGroup := w.rowGroups[i]
+		meta.FieldStructList(1, len(rowGroup.chunks), func(j int) {
+			chunk := rowGroup.chunks[j]
+			meta.FieldI64(2, chunk.dataPageOffset)
+			meta.FieldStruct(3)
+			meta.FieldI32(1, w.columns[j].physicalType)
+			meta.FieldI32List(2, parquetPlain, parquetRle)
+			meta.FieldStringList(3, w.schema[j].Path)
+			meta.FieldI32(4, parquetUncompressed)
+			meta.FieldI64(5, chunk.numValues)
+			meta.FieldI64(6, chunk.size)
+			meta.FieldI64(7, chunk.size)
+			meta.FieldI64(9, chunk.dataPageOffset)
+			meta.EndStruct()
+		})
+		meta.FieldI64(2, rowGroup.size)
+		meta.FieldI64(3, rowGroup.numRows)
+	})
+	meta.FieldString(6, "ascetic-ddd-go")
+	meta.EndStruct()
+
+	if err := w.write(meta.Bytes()); err != nil {
+		return err
+	}
+	footer := binary.LittleEndian.AppendUint32(nil, uint32(len(meta.Bytes())))
+	return w.write(append(footer, parquetMagic...))
+}
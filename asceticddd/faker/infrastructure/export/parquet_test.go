@@ -0,0 +1,188 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// thriftReader decodes structs of the Thrift compact protocol into maps by field id,
+// so that the tests read back the metadata of the written Parquet files.
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) readStruct(t *testing.T) map[int16]any {
+	fields := map[int16]any{}
+	var lastId int16
+	for {
+		header := r.data[r.pos]
+		r.pos++
+		if header == 0 {
+			return fields
+		}
+		fieldType := header & 0x0f
+		if delta := int16(header >> 4); delta != 0 {
+			lastId += delta
+		} else {
+			lastId = int16(r.varint(t))
+		}
+		fields[lastId] = r.readValue(t, fieldType)
+	}
+}
+
+func (r *thriftReader) readValue(t *testing.T, valueType byte) any {
+	switch valueType {
+	case thriftTrue:
+		return true
+	case thriftFalse:
+		return false
+	case thriftI32, thriftI64:
+		return r.varint(t)
+	case thriftBinary:
+		size := int(r.uvarint(t))
+		value := string(r.data[r.pos : r.pos+size])
+		r.pos += size
+		return value
+	case thriftList:
+		header := r.data[r.pos]
+		r.pos++
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.uvarint(t))
+		}
+		values := make([]any, size)
+		for i := range values {
+			values[i] = r.readValue(t, header&0x0f)
+		}
+		return values
+	case thriftStruct:
+		return r.readStruct(t)
+	}
+	t.Fatalf("unexpected thrift type %d", valueType)
+	return nil
+}
+
+func (r *thriftReader) varint(t *testing.T) int64 {
+	value, n := binary.Varint(r.data[r.pos:])
+	require.Positive(t, n)
+	r.pos += n
+	return value
+}
+
+func (r *thriftReader) uvarint(t *testing.T) uint64 {
+	value, n := binary.Uvarint(r.data[r.pos:])
+	require.Positive(t, n)
+	r.pos += n
+	return value
+}
+
+// readParquet returns the metadata of the file and the values of its columns, nil for nulls.
+func readParquet(t *testing.T, data []byte) (map[int16]any, [][]any) {
+	require.Equal(t, parquetMagic, string(data[:4]))
+	require.Equal(t, parquetMagic, string(data[len(data)-4:]))
+	size := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	meta := (&thriftReader{data: data[len(data)-8-size : len(data)-8]}).readStruct(t)
+
+	schema := meta[2].([]any)
+	columns := make([][]any, len(schema)-1)
+	for _, rowGroup := range meta[4].([]any) {
+		for i, chunk := range rowGroup.(map[int16]any)[1].([]any) {
+			columnMeta := chunk.(map[int16]any)[3].(map[int16]any)
+			columns[i] = append(columns[i], readParquetPage(t, data, columnMeta)...)
+		}
+	}
+	return meta, columns
+}
+
+func readParquetPage(t *testing.T, data []byte, columnMeta map[int16]any) []any {
+	reader := &thriftReader{data: data, pos: int(columnMeta[9].(int64))}
+	header := reader.readStruct(t)
+	require.Equal(t, int64(parquetDataPage), header[1])
+	numValues := int(header[5].(map[int16]any)[1].(int64))
+	page := data[reader.pos : reader.pos+int(header[3].(int64))]
+
+	levelsSize := int(binary.LittleEndian.Uint32(page))
+	levels := page[4 : 4+levelsSize]
+	values := page[4+levelsSize:]
+	var defined []bool
+	for len(levels) > 0 {
+		run, n := binary.Uvarint(levels)
+		require.Zero(t, run&1, "bit-packed runs are not written")
+		for i := 0; i < int(run>>1); i++ {
+			defined = append(defined, levels[n] == 1)
+		}
+		levels = levels[n+1:]
+	}
+	require.Len(t, defined, numValues)
+
+	result := make([]any, numValues)
+	bit := 0
+	for i := range result {
+		if !defined[i] {
+			continue
+		}
+		switch columnMeta[1].(int64) {
+		case parquetDouble:
+			result[i] = math.Float64frombits(binary.LittleEndian.Uint64(values))
+			values = values[8:]
+		case parquetByteArray:
+			size := int(binary.LittleEndian.Uint32(values))
+			result[i] = string(values[4 : 4+size])
+			values = values[4+size:]
+		case parquetBoolean:
+			result[i] = values[bit/8]&(1<<(bit%8)) != 0
+			bit++
+		case parquetInt64:
+			result[i] = time.UnixMicro(int64(binary.LittleEndian.Uint64(values))).UTC()
+			values = values[8:]
+		}
+	}
+	return result
+}
+
+func TestExportParquet(t *testing.T) {
+	exporter := NewExporter(testSchema)
+	exporter.SetBatchSize(2)
+
+	var buf bytes.Buffer
+	count, err := exporter.ExportParquet(&buf, SliceStates(testStates))
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	meta, columns := readParquet(t, buf.Bytes())
+	assert.Equal(t, int64(3), meta[3])
+	assert.Len(t, meta[4], 2)
+	assert.Equal(t, testColumns, columns)
+
+	schema := meta[2].([]any)
+	require.Len(t, schema, 5)
+	assert.Equal(t, map[int16]any{4: "schema", 5: int64(4)}, schema[0])
+	assert.Equal(t, map[int16]any{1: int64(parquetDouble), 3: int64(parquetOptional), 4: "age"}, schema[1])
+	assert.Equal(t, map[int16]any{
+		1: int64(parquetByteArray), 3: int64(parquetOptional), 4: "name", 6: int64(parquetUtf8),
+		10: map[int16]any{1: map[int16]any{}},
+	}, schema[3])
+	assert.Equal(t, map[int16]any{
+		1: int64(parquetInt64), 3: int64(parquetOptional), 4: "profile.joined", 6: int64(parquetTimestampMicros),
+		10: map[int16]any{8: map[int16]any{1: true, 2: map[int16]any{2: map[int16]any{}}}},
+	}, schema[4])
+}
+
+func TestExportParquetEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	count, err := NewExporter(testSchema).ExportParquet(&buf, SliceStates(nil))
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	meta, columns := readParquet(t, buf.Bytes())
+	assert.Equal(t, int64(0), meta[3])
+	assert.Empty(t, meta[4])
+	assert.Equal(t, make([][]any, len(testSchema)), columns)
+}
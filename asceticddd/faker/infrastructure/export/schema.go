@@ -0,0 +1,131 @@
+// Package export streams query results into Apache Arrow record batches and Parquet files
+// with the schema derived from the field types of the table, for the hand-off to analytics.
+// It is a separate module, so that the core module does not depend on Arrow.
+package export
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+const defaultBatchSize = 1024
+
+// Column is a field of exported records, typed by the hint of its path.
+// The path is the name of the column.
+type Column struct {
+	Path string
+	Type domainquery.TypeHint
+}
+
+// Schema is the ordered list of exported columns.
+type Schema []Column
+
+// SchemaFromFieldTypes derives the schema from the field types registered for the table,
+// see PgDocumentTable.SetFieldTypes, ordered by path.
+// Paths within arrays ("[*]") are skipped, since they are not scalar columns.
+func SchemaFromFieldTypes(fieldTypes domainquery.FieldTypes) Schema {
+	schema := make(Schema, 0, len(fieldTypes))
+	for path, hint := range fieldTypes {
+		if strings.Contains(path, "[*]") {
+			continue
+		}
+		schema = append(schema, Column{Path: path, Type: hint})
+	}
+	sort.Slice(schema, func(i, j int) bool { return schema[i].Path < schema[j].Path })
+	return schema
+}
+
+// States is the stream of exported states, e.g. repositories.StateIterator of FindIter,
+// so that results of any size are exported in constant memory.
+type States interface {
+	Next() bool
+	State() map[string]any
+	Err() error
+}
+
+// SliceStates returns the stream of the states.
+func SliceStates(states []map[string]any) States {
+	return &sliceStates{states: states, index: -1}
+}
+
+type sliceStates struct {
+	states []map[string]any
+	index  int
+}
+
+func (s *sliceStates) Next() bool {
+	if s.index+1 >= len(s.states) {
+		return false
+	}
+	s.index++
+	return true
+}
+
+func (s *sliceStates) State() map[string]any {
+	return s.states[s.index]
+}
+
+func (s *sliceStates) Err() error {
+	return nil
+}
+
+// row returns the values of the columns of the state, of the canonical Go type of their hints
+// (see TypeHint.Coerce): float64, string, bool or time.Time.
+// Missing fields and values which can not be coerced to the column type are nil.
+func (schema Schema) row(keys [][]string, state map[string]any) []any {
+	row := make([]any, len(schema))
+	for i, column := range schema {
+		value, _ := lookupPath(state, keys[i])
+		row[i] = columnValue(column.Type, value)
+	}
+	return row
+}
+
+func (schema Schema) keys() [][]string {
+	keys := make([][]string, len(schema))
+	for i, column := range schema {
+		keys[i] = domainquery.SplitPath(column.Path)
+	}
+	return keys
+}
+
+func columnValue(hint domainquery.TypeHint, value any) any {
+	value = hint.Coerce(value)
+	switch value.(type) {
+	case float64:
+		if hint == domainquery.TypeHintNumeric {
+			return value
+		}
+	case string:
+		if hint == domainquery.TypeHintText || hint == domainquery.TypeHintUuid {
+			return value
+		}
+	case bool:
+		if hint == domainquery.TypeHintBool {
+			return value
+		}
+	case time.Time:
+		if hint == domainquery.TypeHintDatetime {
+			return value
+		}
+	}
+	return nil
+}
+
+func lookupPath(state map[string]any, keys []string) (any, bool) {
+	var current any = state
+	for _, key := range keys {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
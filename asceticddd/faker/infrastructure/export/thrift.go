@@ -0,0 +1,129 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Types of the Thrift compact protocol, the encoding of the Parquet metadata.
+const (
+	thriftTrue   = 1
+	thriftFalse  = 2
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes structs by the Thrift compact protocol.
+// Fields of a struct have to be written in the order of their ids.
+type thriftWriter struct {
+	buf     bytes.Buffer
+	lastIds []int16
+	lastId  int16
+}
+
+func (w *thriftWriter) Bytes() []byte {
+	return w.buf.Bytes()
+}
+
+func (w *thriftWriter) fieldHeader(id int16, fieldType byte) {
+	if delta := id - w.lastId; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		w.buf.WriteByte(fieldType)
+		w.varint(int64(id))
+	}
+	w.lastId = id
+}
+
+func (w *thriftWriter) FieldBool(id int16, value bool) {
+	if value {
+		w.fieldHeader(id, thriftTrue)
+	} else {
+		w.fieldHeader(id, thriftFalse)
+	}
+}
+
+func (w *thriftWriter) FieldI32(id int16, value int32) {
+	w.fieldHeader(id, thriftI32)
+	w.varint(int64(value))
+}
+
+func (w *thriftWriter) FieldI64(id int16, value int64) {
+	w.fieldHeader(id, thriftI64)
+	w.varint(value)
+}
+
+func (w *thriftWriter) FieldString(id int16, value string) {
+	w.fieldHeader(id, thriftBinary)
+	w.uvarint(uint64(len(value)))
+	w.buf.WriteString(value)
+}
+
+func (w *thriftWriter) FieldI32List(id int16, values ...int32) {
+	w.fieldHeader(id, thriftList)
+	w.listHeader(len(values), thriftI32)
+	for _, value := range values {
+		w.varint(int64(value))
+	}
+}
+
+func (w *thriftWriter) FieldStringList(id int16, values ...string) {
+	w.fieldHeader(id, thriftList)
+	w.listHeader(len(values), thriftBinary)
+	for _, value := range values {
+		w.uvarint(uint64(len(value)))
+		w.buf.WriteString(value)
+	}
+}
+
+// FieldStructList writes the list of the structs written by fn for each index.
+func (w *thriftWriter) FieldStructList(id int16, size int, fn func(i int)) {
+	w.fieldHeader(id, thriftList)
+	w.listHeader(size, thriftStruct)
+	for i := 0; i < size; i++ {
+		w.beginStruct()
+		fn(i)
+		w.EndStruct()
+	}
+}
+
+// FieldStruct begins the struct field, its fields are written until EndStruct.
+func (w *thriftWriter) FieldStruct(id int16) {
+	w.fieldHeader(id, thriftStruct)
+	w.beginStruct()
+}
+
+func (w *thriftWriter) beginStruct() {
+	w.lastIds = append(w.lastIds, w.lastId)
+	w.lastId = 0
+}
+
+// EndStruct ends the struct of FieldStruct, or the top-level struct.
+func (w *thriftWriter) EndStruct() {
+	w.buf.WriteByte(0)
+	if n := len(w.lastIds); n > 0 {
+		w.lastId = w.lastIds[n-1]
+		w.lastIds = w.lastIds[:n-1]
+	}
+}
+
+func (w *thriftWriter) listHeader(size int, elementType byte) {
+	if size < 15 {
+		w.buf.WriteByte(byte(size)<<4 | elementType)
+		return
+	}
+	w.buf.WriteByte(0xf0 | elementType)
+	w.uvarint(uint64(size))
+}
+
+// varint writes the zigzag varint of i16, i32 and i64.
+func (w *thriftWriter) varint(value int64) {
+	w.buf.Write(binary.AppendVarint(nil, value))
+}
+
+func (w *thriftWriter) uvarint(value uint64) {
+	w.buf.Write(binary.AppendUvarint(nil, value))
+}
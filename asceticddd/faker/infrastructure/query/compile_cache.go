@@ -0,0 +1,319 @@
+package query
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+const defaultCompileCacheSize = 1000
+
+// CompileCache caches SQL compiled by PgQueryCompiler by the structure of queries, i.e. with values stripped,
+// so queries differing only in values are compiled once and get fresh params:
+//
+//	cache := query.NewCompileCache(0)
+//	compiler.SetCompileCache(cache)
+//	compiler.CompileContext(ctx, CompositeQuery{Fields: {"age": ComparisonOperator{Op: "$gt", Value: 18}}})
+//	compiler.CompileContext(ctx, CompositeQuery{Fields: {"age": ComparisonOperator{Op: "$gt", Value: 21}}}) // cache hit
+//
+// Queries with custom operators are compiled every time.
+// The number of values of $in and whether $eq compares with null are parts of the structure.
+// The SQL text of a structure stays the same, so the pgx session reuses the statement
+// it prepares and caches per connection by the SQL text.
+//
+// The cache is safe for concurrent use and may be shared by compilers of the same configuration only:
+// the value expression, relation resolver, indexed fields, field types and param binder.
+// The least recently used entries are evicted above the size.
+type CompileCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	lru     *list.List
+	hits    int
+	misses  int
+}
+
+type compiledQuery struct {
+	key         string
+	sql         string
+	params      []any
+	nonPushable []string
+}
+
+// NewCompileCache creates the cache of the given number of query structures, 1000 if 0.
+func NewCompileCache(size int) *CompileCache {
+	if size <= 0 {
+		size = defaultCompileCacheSize
+	}
+	return &CompileCache{
+		size:    size,
+		entries: map[string]*list.Element{},
+		lru:     list.New(),
+	}
+}
+
+// Stats returns the numbers of cache hits and misses.
+func (c *CompileCache) Stats() (hits int, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+func (c *CompileCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *CompileCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]*list.Element{}
+	c.lru.Init()
+}
+
+func (c *CompileCache) get(key string) (*compiledQuery, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.lru.MoveToFront(element)
+	return element.Value.(*compiledQuery), true
+}
+
+func (c *CompileCache) put(entry *compiledQuery) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[entry.key]; ok {
+		element.Value = entry
+		c.lru.MoveToFront(element)
+		return
+	}
+	c.entries[entry.key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*compiledQuery).key)
+	}
+}
+
+// errNotCacheable is returned for queries with custom operators, whose values are unknown to the cache.
+var errNotCacheable = errors.New("query is not cacheable")
+
+// paramSlot stands for the value with the index in compiled params of the cached SQL.
+type paramSlot int
+
+// documentSlot is the param slot of a non-scalar value, so that the compiler
+// sees the same scalar/non-scalar shape of $in values as in the original query.
+type documentSlot struct {
+	slot paramSlot
+}
+
+// bindSlots replaces param slots of the cached params with the values,
+// including slots in jsonb containment documents.
+func bindSlots(param any, values []any) any {
+	switch p := param.(type) {
+	case paramSlot:
+		return values[p]
+	case documentSlot:
+		return values[p.slot]
+	case Jsonb:
+		return Jsonb{Obj: bindSlots(p.Obj, values)}
+	case map[string]any:
+		bound := make(map[string]any, len(p))
+		for key, value := range p {
+			bound[key] = bindSlots(value, values)
+		}
+		return bound
+	case []any:
+		bound := make([]any, len(p))
+		for i, value := range p {
+			bound[i] = bindSlots(value, values)
+		}
+		return bound
+	default:
+		return param
+	}
+}
+
+// queryShapeVisitor strips values of the query: it builds the key of the query structure
+// and the template query with param slots instead of the values.
+type queryShapeVisitor struct {
+	key    strings.Builder
+	values []any
+}
+
+func newQueryShape(query domainquery.IQueryOperator) (string, domainquery.IQueryOperator, []any, error) {
+	v := &queryShapeVisitor{}
+	template, err := query.Accept(v)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return v.key.String(), template.(domainquery.IQueryOperator), v.values, nil
+}
+
+func (v *queryShapeVisitor) slot(value any) paramSlot {
+	v.values = append(v.values, value)
+	return paramSlot(len(v.values) - 1)
+}
+
+func (v *queryShapeVisitor) VisitEq(op domainquery.EqOperator) (any, error) {
+	// Null values of $rel containments are left out, so they are a part of the structure.
+	if op.Value == nil {
+		v.key.WriteString("$eq(null)")
+		return op, nil
+	}
+	v.key.WriteString("$eq")
+	return domainquery.EqOperator{Value: v.slot(op.Value)}, nil
+}
+
+func (v *queryShapeVisitor) VisitComparison(op domainquery.ComparisonOperator) (any, error) {
+	v.key.WriteString(op.Op)
+	return domainquery.ComparisonOperator{Op: op.Op, Value: v.slot(op.Value)}, nil
+}
+
+func (v *queryShapeVisitor) VisitIn(op domainquery.InOperator) (any, error) {
+	// Scalar values may be compiled into an array param, documents may not.
+	fmt.Fprintf(&v.key, "$in(%d", len(op.Values))
+	values := make([]any, len(op.Values))
+	for i, value := range op.Values {
+		if isJsonScalar(value) {
+			values[i] = v.slot(value)
+			continue
+		}
+		v.key.WriteString(",document")
+		values[i] = documentSlot{v.slot(value)}
+	}
+	v.key.WriteString(")")
+	return domainquery.InOperator{Values: values}, nil
+}
+
+func (v *queryShapeVisitor) VisitBetween(op domainquery.BetweenOperator) (any, error) {
+	fmt.Fprintf(&v.key, "$between(%t)", op.Inclusive)
+	return domainquery.BetweenOperator{Low: v.slot(op.Low), High: v.slot(op.High), Inclusive: op.Inclusive}, nil
+}
+
+func (v *queryShapeVisitor) VisitIsNull(op domainquery.IsNullOperator) (any, error) {
+	fmt.Fprintf(&v.key, "$is_null(%t)", op.Value)
+	return op, nil
+}
+
+func (v *queryShapeVisitor) VisitNot(op domainquery.NotOperator) (any, error) {
+	operand, err := v.nested("$not", op.Operand)
+	if err != nil {
+		return nil, err
+	}
+	return domainquery.NotOperator{Operand: operand}, nil
+}
+
+func (v *queryShapeVisitor) VisitAnyElement(op domainquery.AnyElementOperator) (any, error) {
+	query, err := v.nested("$any", op.Query)
+	if err != nil {
+		return nil, err
+	}
+	return domainquery.AnyElementOperator{Query: query}, nil
+}
+
+func (v *queryShapeVisitor) VisitAllElements(op domainquery.AllElementsOperator) (any, error) {
+	query, err := v.nested("$all", op.Query)
+	if err != nil {
+		return nil, err
+	}
+	return domainquery.AllElementsOperator{Query: query}, nil
+}
+
+func (v *queryShapeVisitor) VisitLen(op domainquery.LenOperator) (any, error) {
+	query, err := v.nested("$len", op.Query)
+	if err != nil {
+		return nil, err
+	}
+	return domainquery.LenOperator{Query: query}, nil
+}
+
+func (v *queryShapeVisitor) VisitAnd(op domainquery.AndOperator) (any, error) {
+	operands, err := v.operands("$and", op.Operands)
+	if err != nil {
+		return nil, err
+	}
+	return domainquery.AndOperator{Operands: operands}, nil
+}
+
+func (v *queryShapeVisitor) VisitOr(op domainquery.OrOperator) (any, error) {
+	operands, err := v.operands("$or", op.Operands)
+	if err != nil {
+		return nil, err
+	}
+	return domainquery.OrOperator{Operands: operands}, nil
+}
+
+func (v *queryShapeVisitor) VisitRel(op domainquery.RelOperator) (any, error) {
+	query, err := v.nested("$rel", op.Query)
+	if err != nil {
+		return nil, err
+	}
+	return domainquery.RelOperator{Query: query.(domainquery.CompositeQuery)}, nil
+}
+
+// VisitComposite visits the fields in the order of their names, so that slots are numbered deterministically.
+func (v *queryShapeVisitor) VisitComposite(op domainquery.CompositeQuery) (any, error) {
+	fields := make(map[string]domainquery.IQueryOperator, len(op.Fields))
+	v.key.WriteString("{")
+	for i, field := range slices.Sorted(maps.Keys(op.Fields)) {
+		if i > 0 {
+			v.key.WriteString(",")
+		}
+		v.key.WriteString(strconv.Quote(field))
+		v.key.WriteString(":")
+		template, err := op.Fields[field].Accept(v)
+		if err != nil {
+			return nil, err
+		}
+		fields[field] = template.(domainquery.IQueryOperator)
+	}
+	v.key.WriteString("}")
+	return domainquery.CompositeQuery{Fields: fields}, nil
+}
+
+func (v *queryShapeVisitor) VisitCustom(op domainquery.ICustomOperator) (any, error) {
+	return nil, errNotCacheable
+}
+
+func (v *queryShapeVisitor) nested(name string, query domainquery.IQueryOperator) (domainquery.IQueryOperator, error) {
+	v.key.WriteString(name)
+	v.key.WriteString("(")
+	template, err := query.Accept(v)
+	if err != nil {
+		return nil, err
+	}
+	v.key.WriteString(")")
+	return template.(domainquery.IQueryOperator), nil
+}
+
+func (v *queryShapeVisitor) operands(name string, operands []domainquery.IQueryOperator) ([]domainquery.IQueryOperator, error) {
+	templates := make([]domainquery.IQueryOperator, len(operands))
+	v.key.WriteString(name)
+	v.key.WriteString("(")
+	for i, operand := range operands {
+		if i > 0 {
+			v.key.WriteString(",")
+		}
+		template, err := operand.Accept(v)
+		if err != nil {
+			return nil, err
+		}
+		templates[i] = template.(domainquery.IQueryOperator)
+	}
+	v.key.WriteString(")")
+	return templates, nil
+}
@@ -0,0 +1,157 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+func TestCompileCache(t *testing.T) {
+	fieldTypes := domainquery.FieldTypes{"age": domainquery.TypeHintNumeric}
+	newCompiler := func(cache *CompileCache) *PgQueryCompiler {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetFieldTypes(fieldTypes)
+		compiler.SetCompileCache(cache)
+		return compiler
+	}
+	userQuery := func(status, city string, minAge, maxAge int) domainquery.IQueryOperator {
+		return domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"status": domainquery.EqOperator{Value: status},
+			"address": domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"city": domainquery.EqOperator{Value: city},
+			}},
+			"age": domainquery.BetweenOperator{Low: minAge, High: maxAge, Inclusive: true},
+		}}
+	}
+
+	t.Run("same structure reuses sql with fresh params", func(t *testing.T) {
+		cache := NewCompileCache(0)
+		compiler := newCompiler(cache)
+
+		firstSql, firstParams, err := compiler.Compile(userQuery("active", "Paris", 18, 30))
+		require.NoError(t, err)
+		sql, params, err := compiler.Compile(userQuery("blocked", "Rome", 40, 50))
+		require.NoError(t, err)
+
+		assert.Equal(t, firstSql, sql)
+		expectedSql, expectedParams, err := newCompiler(nil).Compile(userQuery("blocked", "Rome", 40, 50))
+		require.NoError(t, err)
+		assert.Equal(t, expectedSql, sql)
+		assert.Equal(t, expectedParams, params)
+		assert.Equal(t, []any{
+			encode(map[string]any{"status": "active", "address": map[string]any{"city": "Paris"}}), 18, 30,
+		}, firstParams)
+		hits, misses := cache.Stats()
+		assert.Equal(t, 1, hits)
+		assert.Equal(t, 1, misses)
+	})
+
+	t.Run("number of in values and null are structure", func(t *testing.T) {
+		cache := NewCompileCache(0)
+		compiler := newCompiler(cache)
+		queries := []domainquery.IQueryOperator{
+			domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"age": domainquery.InOperator{Values: []any{1, 2}},
+			}},
+			domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"age": domainquery.InOperator{Values: []any{1, 2, 3}},
+			}},
+			domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"age": domainquery.EqOperator{Value: 1},
+			}},
+			domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"age": domainquery.EqOperator{Value: nil},
+			}},
+		}
+		for _, query := range queries {
+			_, _, err := compiler.Compile(query)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, 4, cache.Len())
+		sql, params, err := compiler.Compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"age": domainquery.InOperator{Values: []any{7, 8, 9}},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, "(value->>'age')::numeric IN ($1, $2, $3)", sql)
+		assert.Equal(t, []any{7, 8, 9}, params)
+	})
+
+	t.Run("least recently used structure is evicted", func(t *testing.T) {
+		cache := NewCompileCache(1)
+		compiler := newCompiler(cache)
+
+		_, _, err := compiler.Compile(userQuery("active", "Paris", 18, 30))
+		require.NoError(t, err)
+		_, _, err = compiler.Compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"age": domainquery.ComparisonOperator{Op: "$gt", Value: 18},
+		}})
+		require.NoError(t, err)
+		_, _, err = compiler.Compile(userQuery("active", "Paris", 18, 30))
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, cache.Len())
+		hits, misses := cache.Stats()
+		assert.Equal(t, 0, hits)
+		assert.Equal(t, 3, misses)
+	})
+
+	t.Run("non-pushable paths are restored on hit", func(t *testing.T) {
+		resolver := &StubRelationResolver{
+			relations: map[string]*RelationInfo{
+				"customer_id": {Table: "customers", PkField: "value_id", Remote: true},
+			},
+		}
+		compiler := NewPgQueryCompiler("", resolver, nil)
+		compiler.SetCompileCache(NewCompileCache(0))
+		remoteQuery := func(status string) domainquery.IQueryOperator {
+			return domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"status": domainquery.EqOperator{Value: status},
+				"customer_id": domainquery.RelOperator{Query: domainquery.CompositeQuery{
+					Fields: map[string]domainquery.IQueryOperator{"tier": domainquery.EqOperator{Value: "gold"}},
+				}},
+			}}
+		}
+
+		_, _, err := compiler.Compile(remoteQuery("open"))
+		require.NoError(t, err)
+		_, _, err = compiler.Compile(domainquery.EqOperator{Value: 1})
+		require.NoError(t, err)
+		assert.Empty(t, compiler.NonPushablePaths())
+		sql, params, err := compiler.Compile(remoteQuery("closed"))
+		require.NoError(t, err)
+
+		assert.Equal(t, "value @> $1", sql)
+		assert.Equal(t, []any{encode(map[string]any{"status": "closed"})}, params)
+		assert.Equal(t, []string{"customer_id"}, compiler.NonPushablePaths())
+	})
+
+	t.Run("in values of documents are structure", func(t *testing.T) {
+		tagsQuery := func(values ...any) domainquery.IQueryOperator {
+			return domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"tags": domainquery.InOperator{Values: values},
+			}}
+		}
+		documents := tagsQuery(map[string]any{"a": 1}, map[string]any{"b": 2}, map[string]any{"c": 3})
+		scalars := tagsQuery("a", "b", "c")
+		cache := NewCompileCache(0)
+		compiler := newCompiler(cache)
+		compiler.SetInArrayThreshold(2)
+		uncached := newCompiler(nil)
+		uncached.SetInArrayThreshold(2)
+
+		for _, query := range []domainquery.IQueryOperator{scalars, documents, scalars, documents} {
+			sql, params, err := compiler.CompileContext(context.Background(), query)
+			require.NoError(t, err)
+			expectedSql, expectedParams, err := uncached.CompileContext(context.Background(), query)
+			require.NoError(t, err)
+			assert.Equal(t, expectedSql, sql)
+			assert.Equal(t, expectedParams, params)
+		}
+		assert.Equal(t, 2, cache.Len())
+	})
+}
@@ -0,0 +1,62 @@
+package query
+
+import (
+	"fmt"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+// PgCompilerName is the compiler name of PgQueryCompiler in domainquery.CustomOperatorRegistry.
+const PgCompilerName = "pg"
+
+// PgOperatorExpr holds the expressions of the path the custom operator is applied to.
+type PgOperatorExpr struct {
+	// Path is the dot-separated path, empty for the whole value.
+	Path string
+	// Json is the jsonb expression of the path, e.g. value->'location'.
+	Json string
+	// Text is the text expression of the path, e.g. value->>'location'.
+	Text string
+}
+
+// PgOperatorEmitter compiles the custom operator to an SQL predicate with ? param markers.
+type PgOperatorEmitter func(op domainquery.ICustomOperator, expr PgOperatorExpr) (string, []any, error)
+
+// RegisterPgEmitter registers the emitter of the custom operator for PgQueryCompiler:
+//
+//	query.RegisterPgEmitter(registry, "$near", func(op domainquery.ICustomOperator, expr query.PgOperatorExpr) (string, []any, error) {
+//		near := op.(NearOperator)
+//		return fmt.Sprintf("point((%s)->>'x', (%s)->>'y') <-> point(?, ?) <= ?", expr.Json, expr.Json), []any{near.X, near.Y, near.Distance}, nil
+//	})
+func RegisterPgEmitter(registry *domainquery.CustomOperatorRegistry, name string, emitter PgOperatorEmitter) {
+	registry.RegisterEmitter(name, PgCompilerName, emitter)
+}
+
+func (c *PgQueryCompiler) VisitCustom(op domainquery.ICustomOperator) (any, error) {
+	emitter, ok := c.customOperators.Emitter(op.Name(), PgCompilerName)
+	if !ok {
+		return nil, fmt.Errorf("unknown operator: %s", op.Name())
+	}
+	emit, ok := emitter.(PgOperatorEmitter)
+	if !ok {
+		return nil, fmt.Errorf("emitter of %s is %T, not PgOperatorEmitter", op.Name(), emitter)
+	}
+	if err := c.checkIndexed(); err != nil {
+		return nil, err
+	}
+	jsonPath := c.targetValueExpr
+	if len(c.fieldPath) > 0 {
+		jsonPath = c.jsonPathExpr()
+	}
+	sql, params, err := emit(op, PgOperatorExpr{
+		Path: joinPath(c.currentPath()),
+		Json: jsonPath,
+		Text: c.jsonTextPathExpr(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.sqlParts = append(c.sqlParts, sql)
+	c.params = append(c.params, params...)
+	return nil, nil
+}
@@ -0,0 +1,89 @@
+package query
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+// nearOperator is a custom operator: {"$near": {"x": 1, "y": 2, "distance": 5}}
+type nearOperator struct {
+	X, Y, Distance float64
+}
+
+func (o nearOperator) Name() string {
+	return "$near"
+}
+
+func (o nearOperator) Accept(visitor domainquery.IQueryVisitor) (any, error) {
+	return domainquery.AcceptCustom(o, visitor)
+}
+
+func (o nearOperator) Equal(other domainquery.IQueryOperator) bool {
+	return o == other
+}
+
+func (o nearOperator) Merge(other domainquery.IQueryOperator) (domainquery.IQueryOperator, error) {
+	return nil, domainquery.ErrUnsupportedMerge
+}
+
+func TestCustomOperators(t *testing.T) {
+	registry := domainquery.NewCustomOperatorRegistry()
+	RegisterPgEmitter(registry, "$near", func(op domainquery.ICustomOperator, expr PgOperatorExpr) (string, []any, error) {
+		near := op.(nearOperator)
+		sql := fmt.Sprintf("point((%s->>'x')::float8, (%s->>'y')::float8) <-> point(?, ?) <= ?", expr.Json, expr.Json)
+		return sql, []any{near.X, near.Y, near.Distance}, nil
+	})
+	query := domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+		"location": nearOperator{X: 1, Y: 2, Distance: 5},
+	}}
+
+	t.Run("compiles with emitter", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetCustomOperators(registry)
+
+		sql, params, err := compiler.Compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"status":   domainquery.EqOperator{Value: "open"},
+			"location": nearOperator{X: 1, Y: 2, Distance: 5},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t,
+			"value @> $1 AND point((value->'location'->>'x')::float8, (value->'location'->>'y')::float8) <-> point($2, $3) <= $4",
+			sql)
+		assert.Equal(t, []any{encode(map[string]any{"status": "open"}), 1.0, 2.0, 5.0}, params)
+	})
+
+	t.Run("sub-compilers inherit registry", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetCustomOperators(registry)
+
+		sql, _, err := compiler.Compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"stops": domainquery.AnyElementOperator{Query: domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"location": nearOperator{X: 1, Y: 2, Distance: 5},
+			}}},
+		}})
+		require.NoError(t, err)
+		assert.Contains(t, sql, "point((rt1->'location'->>'x')::float8")
+	})
+
+	t.Run("compile cache is bypassed", func(t *testing.T) {
+		cache := NewCompileCache(0)
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetCustomOperators(registry)
+		compiler.SetCompileCache(cache)
+
+		_, params, err := compiler.Compile(query)
+		require.NoError(t, err)
+		assert.Equal(t, []any{1.0, 2.0, 5.0}, params)
+		assert.Equal(t, 0, cache.Len())
+	})
+
+	t.Run("unknown operator", func(t *testing.T) {
+		_, _, err := NewPgQueryCompiler("", nil, nil).Compile(query)
+		assert.EqualError(t, err, "unknown operator: $near")
+	})
+}
@@ -0,0 +1,160 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	specinfra "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/infrastructure"
+)
+
+// Dialect extends the SQL syntax of the specification compiler, specinfra.Dialect,
+// with the JSON access syntax of the documents the specification is compiled for, see SpecToSqlVisitor.
+// Values of the document are compared with JSON encoded params, "?" markers of the returned SQL
+// are replaced with the placeholders of the ParamBinder of the dialect.
+type Dialect interface {
+	specinfra.Dialect
+	// ParamBinder returns the placeholder style of the database.
+	ParamBinder() ParamBinder
+	// QuoteIdentifier quotes the name of a table or a column, e.g. of the target value expression.
+	QuoteIdentifier(name string) string
+	// JSONPath returns the value at the keys of the JSON document, the document itself without keys.
+	JSONPath(document string, keys []string) string
+	// JSONParam returns the JSON encoded param "?" comparable with the values of JSONPath.
+	JSONParam() string
+	// JSONText returns the text of the JSON value, e.g. of a string without quotes.
+	JSONText(value string) string
+	// JSONArrayElements returns the FROM item of the elements of the JSON array under the alias,
+	// and the expression of the element.
+	JSONArrayElements(array, alias string) (from, element string)
+	// JSONIn returns the predicate that the value is an element of the JSON array param "?".
+	JSONIn(value string) string
+	// DistinctFrom returns the inequality of the values treating NULL as a comparable value.
+	DistinctFrom(left, right string) string
+}
+
+// PostgresDialect compiles to the operators of jsonb, the values are compared as jsonb.
+type PostgresDialect struct {
+	specinfra.PostgresDialect
+}
+
+func (d PostgresDialect) ParamBinder() ParamBinder {
+	return DollarParamBinder{}
+}
+
+func (d PostgresDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (d PostgresDialect) JSONPath(document string, keys []string) string {
+	return pathExpr(document, keys)
+}
+
+func (d PostgresDialect) JSONParam() string {
+	return "?"
+}
+
+func (d PostgresDialect) JSONText(value string) string {
+	return fmt.Sprintf("(%s #>> '{}')", value)
+}
+
+func (d PostgresDialect) JSONArrayElements(array, alias string) (from, element string) {
+	return fmt.Sprintf("jsonb_array_elements(%s) AS %s", array, alias), alias
+}
+
+func (d PostgresDialect) JSONIn(value string) string {
+	return fmt.Sprintf("%s IN (SELECT jsonb_array_elements(?))", value)
+}
+
+func (d PostgresDialect) DistinctFrom(left, right string) string {
+	return fmt.Sprintf("%s IS DISTINCT FROM %s", left, right)
+}
+
+// MySQLDialect compiles to the JSON functions of MySQL 8, the values are compared as JSON.
+type MySQLDialect struct {
+	specinfra.MySQLDialect
+}
+
+func (d MySQLDialect) ParamBinder() ParamBinder {
+	return QuestionParamBinder{}
+}
+
+func (d MySQLDialect) QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (d MySQLDialect) JSONPath(document string, keys []string) string {
+	if len(keys) == 0 {
+		return document
+	}
+	return fmt.Sprintf("JSON_EXTRACT(%s, %s)", document, jsonPathLiteral(keys))
+}
+
+func (d MySQLDialect) JSONParam() string {
+	return "CAST(? AS JSON)"
+}
+
+func (d MySQLDialect) JSONText(value string) string {
+	return fmt.Sprintf("JSON_UNQUOTE(%s)", value)
+}
+
+func (d MySQLDialect) JSONArrayElements(array, alias string) (from, element string) {
+	return fmt.Sprintf("JSON_TABLE(%s, '$[*]' COLUMNS (value JSON PATH '$')) AS %s", array, alias), alias + ".value"
+}
+
+func (d MySQLDialect) JSONIn(value string) string {
+	return fmt.Sprintf("%s MEMBER OF(CAST(? AS JSON))", value)
+}
+
+func (d MySQLDialect) DistinctFrom(left, right string) string {
+	return fmt.Sprintf("NOT (%s <=> %s)", left, right)
+}
+
+// SQLiteDialect compiles to the JSON functions of SQLite, the values are compared as SQL values,
+// so JSON null is NULL.
+type SQLiteDialect struct {
+	specinfra.SQLiteDialect
+}
+
+func (d SQLiteDialect) ParamBinder() ParamBinder {
+	return QuestionParamBinder{}
+}
+
+func (d SQLiteDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (d SQLiteDialect) JSONPath(document string, keys []string) string {
+	if len(keys) == 0 {
+		return document
+	}
+	return fmt.Sprintf("json_extract(%s, %s)", document, jsonPathLiteral(keys))
+}
+
+func (d SQLiteDialect) JSONParam() string {
+	return "json_extract(?, '$')"
+}
+
+func (d SQLiteDialect) JSONText(value string) string {
+	return value
+}
+
+func (d SQLiteDialect) JSONArrayElements(array, alias string) (from, element string) {
+	return fmt.Sprintf("json_each(%s) AS %s", array, alias), alias + ".value"
+}
+
+func (d SQLiteDialect) JSONIn(value string) string {
+	return fmt.Sprintf("%s IN (SELECT value FROM json_each(?))", value)
+}
+
+func (d SQLiteDialect) DistinctFrom(left, right string) string {
+	return fmt.Sprintf("%s IS NOT %s", left, right)
+}
+
+// jsonPathLiteral returns the string literal of the JSON path of the keys, e.g. '$."address"."city"'.
+func jsonPathLiteral(keys []string) string {
+	path := "$"
+	for _, key := range keys {
+		path += `."` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(key) + `"`
+	}
+	return "'" + strings.ReplaceAll(path, "'", "''") + "'"
+}
@@ -0,0 +1,81 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+)
+
+func TestSpecToSqlDialects(t *testing.T) {
+	root := spec.GlobalScope()
+	node := spec.And(
+		spec.Equal(spec.Field(spec.Object(root, "address"), "city"), spec.Value("Berlin")),
+		spec.NotEqual(spec.Field(root, "status"), spec.Value("closed")),
+		spec.NotIn(spec.Field(root, "region"), spec.Value([]string{"eu"})),
+		spec.StartsWith(spec.Field(root, "name"), spec.Value("Jo")),
+		spec.Wildcard(spec.Object(root, "items"), spec.GreaterThan(spec.Field(spec.Item(), "price"), spec.Value(10))),
+	)
+	cases := []struct {
+		name    string
+		dialect Dialect
+		sql     string
+	}{
+		{
+			"postgres",
+			PostgresDialect{},
+			"value->'address'->'city' = $1 AND value->'status' IS DISTINCT FROM $2 AND " +
+				"NOT coalesce(value->'region' IN (SELECT jsonb_array_elements($3)), false) AND " +
+				"(value->'name' #>> '{}') LIKE $4 AND " +
+				"EXISTS (SELECT 1 FROM jsonb_array_elements(value->'items') AS rt1 WHERE rt1->'price' > $5)",
+		},
+		{
+			"mysql",
+			MySQLDialect{},
+			`JSON_EXTRACT(value, '$."address"."city"') = CAST(? AS JSON) AND ` +
+				`NOT (JSON_EXTRACT(value, '$."status"') <=> CAST(? AS JSON)) AND ` +
+				`NOT coalesce(JSON_EXTRACT(value, '$."region"') MEMBER OF(CAST(? AS JSON)), false) AND ` +
+				`JSON_UNQUOTE(JSON_EXTRACT(value, '$."name"')) LIKE ? AND ` +
+				`EXISTS (SELECT 1 FROM JSON_TABLE(JSON_EXTRACT(value, '$."items"'), '$[*]' COLUMNS (value JSON PATH '$')) AS rt1 ` +
+				`WHERE JSON_EXTRACT(rt1.value, '$."price"') > CAST(? AS JSON))`,
+		},
+		{
+			"sqlite",
+			SQLiteDialect{},
+			`json_extract(value, '$."address"."city"') = json_extract(?, '$') AND ` +
+				`json_extract(value, '$."status"') IS NOT json_extract(?, '$') AND ` +
+				`NOT coalesce(json_extract(value, '$."region"') IN (SELECT value FROM json_each(?)), 0) AND ` +
+				`json_extract(value, '$."name"') LIKE ? ESCAPE '\' AND ` +
+				`EXISTS (SELECT 1 FROM json_each(json_extract(value, '$."items"')) AS rt1 ` +
+				`WHERE json_extract(rt1.value, '$."price"') > json_extract(?, '$'))`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v := NewSpecToSqlVisitor("")
+			v.SetDialect(c.dialect)
+			require.NoError(t, node.Accept(v))
+			sql, params, err := v.Result()
+			require.NoError(t, err)
+			assert.Equal(t, c.sql, sql)
+			assert.Equal(t, []any{encode("Berlin"), encode("closed"), encode([]string{"eu"}), "Jo%", encode(10)}, params)
+		})
+	}
+}
+
+func TestSpecToDialectSql(t *testing.T) {
+	node := spec.GreaterThanEqual(spec.Field(spec.GlobalScope(), "age"), spec.Value(18))
+
+	sql, params, err := SpecToDialectSql(node, MySQLDialect{})
+	require.NoError(t, err)
+	assert.Equal(t, `JSON_EXTRACT(value, '$."age"') >= CAST(? AS JSON)`, sql)
+	assert.Equal(t, []any{encode(18)}, params)
+}
+
+func TestDialectQuoteIdentifier(t *testing.T) {
+	assert.Equal(t, `"my""doc"`, PostgresDialect{}.QuoteIdentifier(`my"doc`))
+	assert.Equal(t, "`my``doc`", MySQLDialect{}.QuoteIdentifier("my`doc"))
+	assert.Equal(t, `"doc"`, SQLiteDialect{}.QuoteIdentifier("doc"))
+}
@@ -0,0 +1,169 @@
+package query
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+// Explain compiles the query and inlines the params as quoted SQL literals.
+// The result is for logging and debugging only, never execute it.
+func (c *PgQueryCompiler) Explain(query domainquery.IQueryOperator) (string, error) {
+	sql, params, err := c.CompileContext(context.Background(), query)
+	if err != nil {
+		return "", err
+	}
+	return InlineParams(sql, params, c.paramBinder)
+}
+
+// Explain builds the statement and inlines the params as quoted SQL literals.
+// The result is for logging and debugging only, never execute it.
+func (b *PgSelectBuilder) Explain(spec domainquery.QuerySpec) (string, error) {
+	sql, params, err := b.Build(spec)
+	if err != nil {
+		return "", err
+	}
+	return InlineParams(sql, params, b.compiler.paramBinder)
+}
+
+// InlineParams replaces the placeholders of the binder with quoted literals of the params.
+// Placeholders within quoted strings and identifiers are kept.
+func InlineParams(sql string, params []any, binder ParamBinder) (string, error) {
+	if binder == nil {
+		binder = DollarParamBinder{}
+	}
+	literals := make([]string, len(params))
+	for i, param := range params {
+		literal, err := sqlLiteral(param)
+		if err != nil {
+			return "", fmt.Errorf("param %d: %w", i+1, err)
+		}
+		literals[i] = literal
+	}
+
+	var b strings.Builder
+	var quote byte
+	next := 1
+	for i := 0; i < len(sql); {
+		ch := sql[i]
+		if quote != 0 {
+			b.WriteByte(ch)
+			if ch == quote {
+				quote = 0
+			}
+			i++
+			continue
+		}
+		if ch == '\'' || ch == '"' {
+			quote = ch
+			b.WriteByte(ch)
+			i++
+			continue
+		}
+		index, length := matchPlaceholder(sql[i:], binder, len(params), next)
+		if length == 0 {
+			b.WriteByte(ch)
+			i++
+			continue
+		}
+		if index > len(params) {
+			return "", fmt.Errorf("placeholder %q has no param", sql[i:i+length])
+		}
+		b.WriteString(literals[index-1])
+		next = index + 1
+		i += length
+	}
+	return b.String(), nil
+}
+
+// matchPlaceholder returns the 1-based index and the length of the placeholder at the start of sql.
+// Indexed placeholders are matched from the highest index, so that $1 does not match $10.
+func matchPlaceholder(sql string, binder ParamBinder, count int, next int) (int, int) {
+	if binder.Positional() {
+		placeholder := binder.Placeholder(next)
+		if strings.HasPrefix(sql, placeholder) {
+			return next, len(placeholder)
+		}
+		return 0, 0
+	}
+	for index := count; index >= 1; index-- {
+		placeholder := binder.Placeholder(index)
+		if strings.HasPrefix(sql, placeholder) && !continuesNumber(sql, len(placeholder)) {
+			return index, len(placeholder)
+		}
+	}
+	return 0, 0
+}
+
+func continuesNumber(sql string, end int) bool {
+	return end < len(sql) && sql[end] >= '0' && sql[end] <= '9'
+}
+
+func sqlLiteral(value any) (string, error) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return "", err
+		}
+		return sqlLiteral(v)
+	}
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return quoteSqlString(v), nil
+	case []byte:
+		return "'\\x" + hex.EncodeToString(v) + "'::bytea", nil
+	case bool:
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), nil
+	case float32:
+		return sqlFloat(float64(v)), nil
+	case float64:
+		return sqlFloat(v), nil
+	case time.Time:
+		return quoteSqlString(v.Format(time.RFC3339Nano)), nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		items := make([]string, rv.Len())
+		for i := range items {
+			item, err := sqlLiteral(rv.Index(i).Interface())
+			if err != nil {
+				return "", err
+			}
+			items[i] = item
+		}
+		return "ARRAY[" + strings.Join(items, ", ") + "]", nil
+	}
+	return quoteSqlString(fmt.Sprint(value)), nil
+}
+
+func sqlFloat(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "'NaN'::float8"
+	case math.IsInf(v, 1):
+		return "'Infinity'::float8"
+	case math.IsInf(v, -1):
+		return "'-Infinity'::float8"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// quoteSqlString quotes the string as a standard conforming literal.
+func quoteSqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,96 @@
+package query
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+func TestExplain(t *testing.T) {
+	query := domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+		"status": domainquery.EqOperator{Value: "it's"},
+		"age":    domainquery.ComparisonOperator{Op: "$gt", Value: 18},
+	}}
+
+	t.Run("compiler", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		sql, err := compiler.Explain(query)
+		require.NoError(t, err)
+		assert.Equal(t, `value @> '{"status":"it''s"}' AND value->'age' > 18`, sql)
+	})
+
+	t.Run("select builder", func(t *testing.T) {
+		builder := NewPgSelectBuilder("users", nil)
+		sql, err := builder.Explain(domainquery.QuerySpec{Query: query, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t,
+			`SELECT value FROM users WHERE value @> '{"status":"it''s"}' AND value->'age' > 18 LIMIT 10`,
+			sql,
+		)
+	})
+
+	t.Run("compile error", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		_, err := compiler.Explain(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"company_id": domainquery.RelOperator{},
+		}})
+		assert.Error(t, err)
+	})
+}
+
+func TestInlineParams(t *testing.T) {
+	t.Run("literals", func(t *testing.T) {
+		sql, err := InlineParams(
+			"SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9",
+			[]any{
+				nil, true, 42, 1.5, math.Inf(-1), []byte{0xde, 0xad},
+				time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), []any{"a", 1}, Jsonb{Obj: map[string]any{"a": "b"}},
+			},
+			DollarParamBinder{},
+		)
+		require.NoError(t, err)
+		assert.Equal(t,
+			`SELECT NULL, TRUE, 42, 1.5, '-Infinity'::float8, '\xdead'::bytea, `+
+				`'2024-03-01T12:00:00Z', ARRAY['a', 1], '{"a":"b"}'`,
+			sql,
+		)
+	})
+
+	t.Run("two-digit indexes", func(t *testing.T) {
+		params := make([]any, 10)
+		for i := range params {
+			params[i] = i + 1
+		}
+		sql, err := InlineParams("$1 + $10", params, DollarParamBinder{})
+		require.NoError(t, err)
+		assert.Equal(t, "1 + 10", sql)
+	})
+
+	t.Run("placeholders in quotes are kept", func(t *testing.T) {
+		sql, err := InlineParams(`SELECT '$1', "?" , $1`, []any{"x"}, DollarParamBinder{})
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT '$1', "?" , 'x'`, sql)
+	})
+
+	t.Run("question binder", func(t *testing.T) {
+		sql, err := InlineParams("a = ? AND b = ?", []any{1, "x"}, QuestionParamBinder{})
+		require.NoError(t, err)
+		assert.Equal(t, "a = 1 AND b = 'x'", sql)
+	})
+
+	t.Run("named binder", func(t *testing.T) {
+		sql, err := InlineParams("a = :p2 OR b = :p1", []any{1, 2}, NamedParamBinder{})
+		require.NoError(t, err)
+		assert.Equal(t, "a = 2 OR b = 1", sql)
+	})
+
+	t.Run("missing param", func(t *testing.T) {
+		_, err := InlineParams("a = ? AND b = ?", []any{1}, QuestionParamBinder{})
+		assert.Error(t, err)
+	})
+}
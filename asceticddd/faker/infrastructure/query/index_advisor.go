@@ -0,0 +1,147 @@
+package query
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/disposable"
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+// IndexRecommendation is an index serving the recorded predicates.
+type IndexRecommendation struct {
+	Table string
+	// Paths of the predicates served by the index, empty for the GIN index on the value.
+	Paths []string
+	// Count is the number of recorded predicates served by the index.
+	Count     int
+	Statement string
+}
+
+// IndexAdvisor records predicates of compiled queries, e.g. during tests,
+// and recommends indexes for the most frequent ones:
+//
+//	advisor := query.NewIndexAdvisor()
+//	advisor.Observe(compiler)
+//	// ... compile queries
+//	for _, recommendation := range advisor.Recommend(5) {
+//		fmt.Println(recommendation.Statement)
+//	}
+//
+// Containments get the GIN index on the value, comparisons and sorts get btree indexes
+// on the expressions the compiler emits, so hinted paths are indexed by their typed expression.
+// Predicates on paths of IndexedFields are not recorded, nor predicates of compilers without table,
+// which is set with SetIndexedFields (nil IndexedFields keep warnings off).
+// Index names follow PgDocumentTable.
+type IndexAdvisor struct {
+	mu      sync.Mutex
+	indexes map[indexKey]*IndexRecommendation
+}
+
+type indexKey struct {
+	table string
+	expr  string
+}
+
+func NewIndexAdvisor() *IndexAdvisor {
+	return &IndexAdvisor{indexes: map[indexKey]*IndexRecommendation{}}
+}
+
+// Observe records the predicates of the compiler until the returned disposable is disposed.
+func (a *IndexAdvisor) Observe(compiler *PgQueryCompiler) disposable.Disposable {
+	return compiler.OnPredicate().Attach(a.Record)
+}
+
+// Record records the predicate, it is the observer of PgQueryCompiler.OnPredicate.
+func (a *IndexAdvisor) Record(event PredicateEvent) error {
+	if event.Table == "" || event.Indexed {
+		return nil
+	}
+	expr, name, ok := indexExpr(event)
+	if !ok {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := indexKey{table: event.Table, expr: expr}
+	recommendation, ok := a.indexes[key]
+	if !ok {
+		recommendation = &IndexRecommendation{Table: event.Table, Statement: indexStatement(event.Table, name, expr)}
+		a.indexes[key] = recommendation
+	}
+	recommendation.Count++
+	if event.Kind == PredicateComparison && !slices.Contains(recommendation.Paths, event.Path) {
+		recommendation.Paths = append(recommendation.Paths, event.Path)
+	}
+	return nil
+}
+
+// Recommend returns at most limit recommendations, the most frequent first.
+// Non-positive limit returns all of them.
+func (a *IndexAdvisor) Recommend(limit int) []IndexRecommendation {
+	a.mu.Lock()
+	recommendations := make([]IndexRecommendation, 0, len(a.indexes))
+	for _, recommendation := range a.indexes {
+		r := *recommendation
+		r.Paths = slices.Clone(recommendation.Paths)
+		recommendations = append(recommendations, r)
+	}
+	a.mu.Unlock()
+
+	slices.SortFunc(recommendations, func(x, y IndexRecommendation) int {
+		return cmp.Or(
+			cmp.Compare(y.Count, x.Count),
+			cmp.Compare(x.Table, y.Table),
+			cmp.Compare(x.Statement, y.Statement),
+		)
+	})
+	if limit > 0 && len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+	return recommendations
+}
+
+// Reset forgets the recorded predicates.
+func (a *IndexAdvisor) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.indexes = map[indexKey]*IndexRecommendation{}
+}
+
+// indexExpr returns the indexed expression of the predicate and the name of the index.
+// Empty expression is the GIN index on the value.
+func indexExpr(event PredicateEvent) (string, string, bool) {
+	if event.Kind == PredicateContainment {
+		return "", "value_gin", true
+	}
+	if event.Path == "" {
+		return "", "", false
+	}
+	name := strings.ReplaceAll(event.Path, ".", "_")
+	if event.Hint != "" {
+		if expr, ok := IndexablePathExpr("value", event.Path, event.Hint); ok {
+			return expr, name, true
+		}
+		return "", "", false
+	}
+	return pathExpr("value", domainquery.SplitPath(event.Path)), name, true
+}
+
+// IndexablePathExpr returns TypedPathExpr of the hinted path if an expression index can be built on it.
+// The cast of text to timestamptz depends on the time zone, so datetime paths are not indexable.
+func IndexablePathExpr(valueExpr string, path string, hint domainquery.TypeHint) (string, bool) {
+	if hint == domainquery.TypeHintDatetime {
+		return "", false
+	}
+	return TypedPathExpr(valueExpr, path, hint)
+}
+
+func indexStatement(table, name, expr string) string {
+	if expr == "" {
+		return fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_%s_idx ON %s USING GIN (value jsonb_path_ops)", table, name, table)
+	}
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_%s_idx ON %s ((%s))", table, name, table, expr)
+}
@@ -0,0 +1,128 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+func TestIndexAdvisor(t *testing.T) {
+	newCompiler := func(indexedFields IndexedFields) *PgQueryCompiler {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetIndexedFields("users", indexedFields)
+		compiler.SetFieldTypes(domainquery.FieldTypes{
+			"age":        domainquery.TypeHintNumeric,
+			"created_at": domainquery.TypeHintDatetime,
+		})
+		return compiler
+	}
+	byAge := domainquery.CompositeQuery{
+		Fields: map[string]domainquery.IQueryOperator{
+			"age": domainquery.ComparisonOperator{Op: "$gt", Value: 18},
+		},
+	}
+	byCity := domainquery.CompositeQuery{
+		Fields: map[string]domainquery.IQueryOperator{
+			"address": domainquery.CompositeQuery{
+				Fields: map[string]domainquery.IQueryOperator{
+					"city": domainquery.EqOperator{Value: "Moscow"},
+				},
+			},
+		},
+	}
+
+	t.Run("recommends most frequent first", func(t *testing.T) {
+		compiler := newCompiler(nil)
+		advisor := NewIndexAdvisor()
+		advisor.Observe(compiler)
+
+		for _, q := range []domainquery.IQueryOperator{byAge, byCity, byAge, byCity} {
+			_, _, err := compiler.Compile(q)
+			require.NoError(t, err)
+		}
+		_, _, err := compiler.Compile(domainquery.CompositeQuery{
+			Fields: map[string]domainquery.IQueryOperator{
+				"score": domainquery.BetweenOperator{Low: 1, High: 5},
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []IndexRecommendation{
+			{
+				Table:     "users",
+				Paths:     []string{"age"},
+				Count:     2,
+				Statement: "CREATE INDEX IF NOT EXISTS users_age_idx ON users (((value->>'age')::numeric))",
+			},
+			{
+				Table:     "users",
+				Count:     2,
+				Statement: "CREATE INDEX IF NOT EXISTS users_value_gin_idx ON users USING GIN (value jsonb_path_ops)",
+			},
+		}, advisor.Recommend(2))
+		assert.Equal(t,
+			"CREATE INDEX IF NOT EXISTS users_score_idx ON users ((value->'score'))",
+			advisor.Recommend(0)[2].Statement,
+		)
+	})
+
+	t.Run("sort", func(t *testing.T) {
+		compiler := newCompiler(nil)
+		advisor := NewIndexAdvisor()
+		advisor.Observe(compiler)
+
+		_, _, err := NewPgSelectBuilder("users", compiler).Build(domainquery.QuerySpec{
+			OrderBy: []domainquery.OrderClause{{Path: "profile.rank"}},
+		})
+		require.NoError(t, err)
+
+		recommendations := advisor.Recommend(0)
+		require.Len(t, recommendations, 1)
+		assert.Equal(t,
+			"CREATE INDEX IF NOT EXISTS users_profile_rank_idx ON users ((value->'profile'->'rank'))",
+			recommendations[0].Statement,
+		)
+	})
+
+	t.Run("skips indexed, datetime and array paths", func(t *testing.T) {
+		compiler := newCompiler(IndexedFields{"age": true})
+		advisor := NewIndexAdvisor()
+		advisor.Observe(compiler)
+
+		_, _, err := compiler.Compile(domainquery.AndOperator{Operands: []domainquery.IQueryOperator{
+			byAge,
+			domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"created_at": domainquery.ComparisonOperator{Op: "$gte", Value: "2024-01-01T00:00:00Z"},
+			}},
+			domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"items": domainquery.AnyElementOperator{Query: domainquery.CompositeQuery{
+					Fields: map[string]domainquery.IQueryOperator{
+						"price": domainquery.ComparisonOperator{Op: "$gt", Value: 100},
+					},
+				}},
+			}},
+		}})
+		require.NoError(t, err)
+
+		assert.Empty(t, advisor.Recommend(0))
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		compiler := newCompiler(nil)
+		advisor := NewIndexAdvisor()
+		subscription := advisor.Observe(compiler)
+
+		_, _, err := compiler.Compile(byAge)
+		require.NoError(t, err)
+		advisor.Reset()
+		assert.Empty(t, advisor.Recommend(0))
+
+		subscription.Dispose()
+		_, _, err = compiler.Compile(byAge)
+		require.NoError(t, err)
+		assert.Empty(t, advisor.Recommend(0))
+	})
+}
@@ -0,0 +1,114 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+// KeysetPaginator builds keyset (cursor) pages over JSONB-backed tables.
+// Instead of OFFSET it compares the sort keys with the cursor of the previous page:
+//
+//	(a > $1) OR (a = $1 AND b < $2) OR (a = $1 AND b = $2 AND pk > $3)
+//
+// The primary key column is always appended to ORDER BY as tie-breaker,
+// so pages are stable even for duplicate sort keys.
+// Sort paths are expected to be NOT NULL, since NULL never satisfies the comparison.
+type KeysetPaginator struct {
+	builder  *PgSelectBuilder
+	pkColumn string
+}
+
+func NewKeysetPaginator(builder *PgSelectBuilder, pkColumn string) *KeysetPaginator {
+	return &KeysetPaginator{
+		builder:  builder,
+		pkColumn: pkColumn,
+	}
+}
+
+// Build returns the SELECT statement of the page following the cursor.
+// Empty cursor means the first page. spec.Offset must be zero.
+func (p *KeysetPaginator) Build(spec domainquery.QuerySpec, cursor string) (string, []any, error) {
+	if spec.Offset != 0 {
+		return "", nil, fmt.Errorf("keyset pagination does not support offset")
+	}
+	stmt, err := p.builder.statement(spec)
+	if err != nil {
+		return "", nil, err
+	}
+	if cursor != "" {
+		decoded, err := domainquery.DecodeCursor(cursor)
+		if err != nil {
+			return "", nil, err
+		}
+		predicate, err := p.predicate(stmt, spec.OrderBy, decoded)
+		if err != nil {
+			return "", nil, err
+		}
+		stmt.where = append(stmt.where, predicate)
+	}
+	stmt.orderBy = append(stmt.orderBy, orderByExpr(p.pkColumn, false))
+	sql, params := stmt.render()
+	return sql, params, nil
+}
+
+func (p *KeysetPaginator) predicate(
+	stmt *selectStatement,
+	orderBy []domainquery.OrderClause,
+	cursor domainquery.Cursor,
+) (string, error) {
+	if len(cursor.Values) != len(orderBy) {
+		return "", fmt.Errorf(
+			"cursor does not match order by: %d values for %d clauses",
+			len(cursor.Values), len(orderBy),
+		)
+	}
+	exprs := make([]string, 0, len(orderBy)+1)
+	values := make([]any, 0, len(orderBy)+1)
+	ops := make([]string, 0, len(orderBy)+1)
+	for i, clause := range orderBy {
+		exprs = append(exprs, pathExpr(p.builder.compiler.targetValueExpr, domainquery.SplitPath(clause.Path)))
+		values = append(values, encode(cursor.Values[i]))
+		ops = append(ops, keysetOp(clause.IsDesc()))
+	}
+	exprs = append(exprs, p.pkColumn)
+	values = append(values, encode(cursor.Pk))
+	ops = append(ops, keysetOp(false))
+
+	// Each value is bound once and referenced by index,
+	// unless placeholders are positional and the value has to be repeated.
+	placeholders := make([]string, len(values))
+	placeholder := func(i int) string {
+		if stmt.paramBinder.Positional() {
+			return stmt.nextParam(values[i])
+		}
+		if placeholders[i] == "" {
+			placeholders[i] = stmt.nextParam(values[i])
+		}
+		return placeholders[i]
+	}
+
+	orParts := make([]string, 0, len(exprs))
+	for i := range exprs {
+		andParts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			andParts = append(andParts, fmt.Sprintf("%s = %s", exprs[j], placeholder(j)))
+		}
+		andParts = append(andParts, fmt.Sprintf("%s %s %s", exprs[i], ops[i], placeholder(i)))
+		orParts = append(orParts, fmt.Sprintf("(%s)", strings.Join(andParts, " AND ")))
+	}
+	return strings.Join(orParts, " OR "), nil
+}
+
+func keysetOp(desc bool) string {
+	if desc {
+		return "<"
+	}
+	return ">"
+}
+
+// NextCursor encodes the cursor of the last row of a page.
+func (p *KeysetPaginator) NextCursor(orderBy []domainquery.OrderClause, state map[string]any, pk any) (string, error) {
+	return domainquery.EncodeCursor(domainquery.NewCursor(orderBy, state, pk))
+}
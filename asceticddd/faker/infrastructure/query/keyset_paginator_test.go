@@ -0,0 +1,72 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+func TestKeysetPaginator(t *testing.T) {
+	orderBy := []domainquery.OrderClause{
+		{Path: "city"},
+		{Path: "age", Direction: domainquery.Desc},
+	}
+
+	t.Run("first page", func(t *testing.T) {
+		paginator := NewKeysetPaginator(NewPgSelectBuilder("users", nil), "value_id")
+		sql, params, err := paginator.Build(domainquery.QuerySpec{OrderBy: orderBy, Limit: 10}, "")
+		require.NoError(t, err)
+		assert.Equal(t,
+			"SELECT value FROM users ORDER BY value->'city' ASC, value->'age' DESC, value_id ASC LIMIT $1",
+			sql,
+		)
+		assert.Equal(t, []any{10}, params)
+	})
+
+	t.Run("next page", func(t *testing.T) {
+		paginator := NewKeysetPaginator(NewPgSelectBuilder("users", nil), "value_id")
+		cursor, err := paginator.NextCursor(orderBy, map[string]any{"city": "Moscow", "age": 30}, 7)
+		require.NoError(t, err)
+
+		sql, params, err := paginator.Build(domainquery.QuerySpec{
+			Query: domainquery.CompositeQuery{
+				Fields: map[string]domainquery.IQueryOperator{
+					"active": domainquery.EqOperator{Value: true},
+				},
+			},
+			OrderBy: orderBy,
+			Limit:   10,
+		}, cursor)
+		require.NoError(t, err)
+		assert.Equal(t,
+			"SELECT value FROM users WHERE (value @> $1) AND ("+
+				"(value->'city' > $2) OR "+
+				"(value->'city' = $2 AND value->'age' < $3) OR "+
+				"(value->'city' = $2 AND value->'age' = $3 AND value_id > $4)) "+
+				"ORDER BY value->'city' ASC, value->'age' DESC, value_id ASC LIMIT $5",
+			sql,
+		)
+		require.Len(t, params, 5)
+		assert.Equal(t, Jsonb{"Moscow"}, params[1])
+		assert.Equal(t, Jsonb{float64(30)}, params[2])
+		assert.Equal(t, Jsonb{float64(7)}, params[3])
+		assert.Equal(t, 10, params[4])
+	})
+
+	t.Run("cursor mismatch", func(t *testing.T) {
+		paginator := NewKeysetPaginator(NewPgSelectBuilder("users", nil), "value_id")
+		cursor, err := domainquery.EncodeCursor(domainquery.Cursor{Values: []any{"Moscow"}, Pk: 7})
+		require.NoError(t, err)
+		_, _, err = paginator.Build(domainquery.QuerySpec{OrderBy: orderBy}, cursor)
+		assert.Error(t, err)
+	})
+
+	t.Run("offset rejected", func(t *testing.T) {
+		paginator := NewKeysetPaginator(NewPgSelectBuilder("users", nil), "value_id")
+		_, _, err := paginator.Build(domainquery.QuerySpec{OrderBy: orderBy, Offset: 10}, "")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,92 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParamBinder defines the placeholder style of compiled SQL.
+// Compilers always return params in positional order,
+// Args converts them to the arguments expected by the driver.
+type ParamBinder interface {
+	// Placeholder returns the placeholder of the param with the 1-based index.
+	Placeholder(index int) string
+	// Positional reports whether placeholders are bound by their order in SQL
+	// rather than by index, so a param cannot be referenced twice.
+	Positional() bool
+	Args(params []any) []any
+}
+
+// DollarParamBinder uses $1, $2, ... placeholders (PostgreSQL, pgx, lib/pq).
+type DollarParamBinder struct{}
+
+func (b DollarParamBinder) Placeholder(index int) string {
+	return fmt.Sprintf("$%d", index)
+}
+
+func (b DollarParamBinder) Positional() bool {
+	return false
+}
+
+func (b DollarParamBinder) Args(params []any) []any {
+	return params
+}
+
+// QuestionParamBinder uses ? placeholders (MySQL, SQLite).
+type QuestionParamBinder struct{}
+
+func (b QuestionParamBinder) Placeholder(index int) string {
+	return "?"
+}
+
+func (b QuestionParamBinder) Positional() bool {
+	return true
+}
+
+func (b QuestionParamBinder) Args(params []any) []any {
+	return params
+}
+
+// NamedParamBinder uses :p1, :p2, ... placeholders (sqlx named queries).
+// Args returns a single map[string]any argument.
+type NamedParamBinder struct {
+	Prefix string
+}
+
+func (b NamedParamBinder) Placeholder(index int) string {
+	return fmt.Sprintf(":%s%d", b.prefix(), index)
+}
+
+func (b NamedParamBinder) Positional() bool {
+	return false
+}
+
+func (b NamedParamBinder) Args(params []any) []any {
+	named := make(map[string]any, len(params))
+	for i, param := range params {
+		named[fmt.Sprintf("%s%d", b.prefix(), i+1)] = param
+	}
+	return []any{named}
+}
+
+func (b NamedParamBinder) prefix() string {
+	if b.Prefix == "" {
+		return "p"
+	}
+	return b.Prefix
+}
+
+// bindParamMarkers replaces ? markers with placeholders of the binder.
+func bindParamMarkers(sql string, binder ParamBinder) string {
+	var b strings.Builder
+	idx := 1
+	for i := 0; i < len(sql); i++ {
+		if sql[i] == '?' {
+			b.WriteString(binder.Placeholder(idx))
+			idx++
+		} else {
+			b.WriteByte(sql[i])
+		}
+	}
+	return b.String()
+}
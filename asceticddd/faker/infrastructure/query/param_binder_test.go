@@ -0,0 +1,78 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+func TestParamBinder(t *testing.T) {
+	query := domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+		"status": domainquery.EqOperator{Value: "active"},
+		"age":    domainquery.ComparisonOperator{Op: "$gt", Value: 18},
+	}}
+
+	t.Run("dollar by default", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		sql, params, err := compiler.Compile(query)
+		require.NoError(t, err)
+		assert.Equal(t, "value @> $1 AND value->'age' > $2", sql)
+		assert.Equal(t, params, compiler.ParamBinder().Args(params))
+	})
+
+	t.Run("question", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetParamBinder(QuestionParamBinder{})
+		sql, params, err := compiler.Compile(query)
+		require.NoError(t, err)
+		assert.Equal(t, "value @> ? AND value->'age' > ?", sql)
+		assert.Len(t, params, 2)
+	})
+
+	t.Run("named", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		binder := NamedParamBinder{}
+		compiler.SetParamBinder(binder)
+		sql, params, err := compiler.Compile(query)
+		require.NoError(t, err)
+		assert.Equal(t, "value @> :p1 AND value->'age' > :p2", sql)
+		args := binder.Args(params)
+		require.Len(t, args, 1)
+		assert.Equal(t, map[string]any{"p1": params[0], "p2": 18}, args[0])
+	})
+
+	t.Run("named prefix", func(t *testing.T) {
+		assert.Equal(t, ":arg3", NamedParamBinder{Prefix: "arg"}.Placeholder(3))
+	})
+
+	t.Run("select builder continues numbering", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetParamBinder(NamedParamBinder{})
+		builder := NewPgSelectBuilder("users", compiler)
+		sql, params, err := builder.Build(domainquery.QuerySpec{Query: query, Limit: 10, Offset: 5})
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT value FROM users WHERE value @> :p1 AND value->'age' > :p2 LIMIT :p3 OFFSET :p4", sql)
+		assert.Len(t, params, 4)
+	})
+
+	t.Run("keyset repeats positional params", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetParamBinder(QuestionParamBinder{})
+		paginator := NewKeysetPaginator(NewPgSelectBuilder("users", compiler), "value_id")
+		orderBy := []domainquery.OrderClause{{Path: "age"}}
+		cursor, err := paginator.NextCursor(orderBy, map[string]any{"age": 30}, 7)
+		require.NoError(t, err)
+
+		sql, params, err := paginator.Build(domainquery.QuerySpec{OrderBy: orderBy, Limit: 10}, cursor)
+		require.NoError(t, err)
+		assert.Equal(t,
+			"SELECT value FROM users WHERE (value->'age' > ?) OR (value->'age' = ? AND value_id > ?) "+
+				"ORDER BY value->'age' ASC, value_id ASC LIMIT ?",
+			sql,
+		)
+		assert.Equal(t, []any{Jsonb{float64(30)}, Jsonb{float64(30)}, Jsonb{float64(7)}, 10}, params)
+	})
+}
@@ -0,0 +1,59 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+// CompileAggregation compiles the aggregation to SELECT of group keys followed by aggregates,
+// in the order of GroupBy and Aggregates:
+//
+//	SELECT value->>'status', count(*) FROM t WHERE ... GROUP BY 1 ORDER BY 1
+func (c *PgQueryCompiler) CompileAggregation(table string, aggregation domainquery.Aggregation) (string, []any, error) {
+	if err := aggregation.Validate(); err != nil {
+		return "", nil, err
+	}
+	where, params, err := c.compileWhereClause(aggregation.Query)
+	if err != nil {
+		return "", nil, err
+	}
+	columns := make([]string, 0, len(aggregation.GroupBy)+len(aggregation.Aggregates))
+	positions := make([]string, len(aggregation.GroupBy))
+	for i, path := range aggregation.GroupBy {
+		keys := domainquery.SplitPath(path)
+		if err := c.diagnostics.checkIndexed(c.table, c.indexedFields, keys, PathUsageGroup); err != nil {
+			return "", nil, err
+		}
+		columns = append(columns, textPathExpr(c.targetValueExpr, keys))
+		positions[i] = fmt.Sprintf("%d", i+1)
+	}
+	for _, aggregate := range aggregation.Aggregates {
+		columns = append(columns, c.aggregateExpr(aggregate))
+	}
+	return fmt.Sprintf(
+		"SELECT %s FROM %s%s GROUP BY %s ORDER BY %s",
+		strings.Join(columns, ", "), table, where,
+		strings.Join(positions, ", "), strings.Join(positions, ", "),
+	), params, nil
+}
+
+func (c *PgQueryCompiler) aggregateExpr(aggregate domainquery.Aggregate) string {
+	switch aggregate.Func {
+	case domainquery.AggregateSum:
+		keys := domainquery.SplitPath(aggregate.Path)
+		return fmt.Sprintf(
+			"sum(CASE WHEN jsonb_typeof(%s) = 'number' THEN (%s)::numeric END)",
+			pathExpr(c.targetValueExpr, keys), textPathExpr(c.targetValueExpr, keys),
+		)
+	default:
+		return "count(*)"
+	}
+}
+
+// textPathExpr is pathExpr with the last key extracted as text.
+func textPathExpr(valueExpr string, keys []string) string {
+	last := len(keys) - 1
+	return fmt.Sprintf("%s->>'%s'", pathExpr(valueExpr, keys[:last]), keys[last])
+}
@@ -0,0 +1,61 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+func TestCompileAggregation(t *testing.T) {
+	t.Run("count", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		sql, params, err := compiler.CompileAggregation("orders", domainquery.GroupBy("status").Count())
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT value->>'status', count(*) FROM orders GROUP BY 1 ORDER BY 1", sql)
+		assert.Nil(t, params)
+	})
+
+	t.Run("where, nested keys and sum", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		aggregation := domainquery.GroupBy("customer.id", "status").
+			Where(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"status": domainquery.EqOperator{Value: "active"},
+			}}).
+			Count().
+			Sum("total.amount")
+		sql, params, err := compiler.CompileAggregation("orders", aggregation)
+		require.NoError(t, err)
+		assert.Equal(t,
+			"SELECT value->'customer'->>'id', value->>'status', count(*), "+
+				"sum(CASE WHEN jsonb_typeof(value->'total'->'amount') = 'number' "+
+				"THEN (value->'total'->>'amount')::numeric END) "+
+				"FROM orders WHERE value @> $1 GROUP BY 1, 2 ORDER BY 1, 2",
+			sql,
+		)
+		assert.Equal(t, []any{Jsonb{Obj: map[string]any{"status": "active"}}}, params)
+	})
+
+	t.Run("group by path warning", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetIndexedFields("orders", IndexedFields{"status": true})
+		var events []QueryWarningEvent
+		compiler.OnWarning().Attach(func(event QueryWarningEvent) error {
+			events = append(events, event)
+			return nil
+		})
+		_, _, err := compiler.CompileAggregation("orders", domainquery.GroupBy("status", "region").Count())
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, "region", events[0].Path)
+		assert.Equal(t, PathUsageGroup, events[0].Usage)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		_, _, err := compiler.CompileAggregation("orders", domainquery.GroupBy("status"))
+		assert.Error(t, err)
+	})
+}
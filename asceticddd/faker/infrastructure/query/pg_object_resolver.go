@@ -0,0 +1,155 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+// PgObjectResolver resolves relation fields to foreign object state
+// stored in the Pg document store, i.e. tables with a jsonb "value" column
+// and a jsonb primary key described by RelationInfo.
+//
+// It navigates the same IRelationResolver tree that PgQueryCompiler uses,
+// so in-memory evaluation and SQL compilation see identical relations.
+// Wrap it with domainquery.CachingObjectResolver for per-session caching.
+type PgObjectResolver struct {
+	relationResolver IRelationResolver
+}
+
+func NewPgObjectResolver(relationResolver IRelationResolver) *PgObjectResolver {
+	return &PgObjectResolver{relationResolver: relationResolver}
+}
+
+func (r *PgObjectResolver) Resolve(s session.Session, field *string, fkValue any) (map[string]any, domainquery.IObjectResolver, error) {
+	states, nestedResolver, err := r.ResolveMany(s, field, []any{fkValue})
+	if err != nil {
+		return nil, nil, err
+	}
+	state, ok := states[fkValue]
+	if !ok {
+		return nil, nil, nil
+	}
+	return state, nestedResolver, nil
+}
+
+func (r *PgObjectResolver) ResolveMany(s session.Session, field *string, fkValues []any) (map[any]map[string]any, domainquery.IObjectResolver, error) {
+	if r.relationResolver == nil {
+		return nil, nil, nil
+	}
+	ri := r.relationResolver.Resolve(field)
+	if ri == nil {
+		return nil, nil, nil
+	}
+	if ri.Remote {
+		return nil, nil, fmt.Errorf("relation %s is remote, route it with RoutingObjectResolver", relationName(field))
+	}
+
+	requested := make(map[string]any, len(fkValues))
+	for _, fkValue := range fkValues {
+		key, err := json.Marshal(fkValue)
+		if err != nil {
+			return nil, nil, err
+		}
+		requested[string(key)] = fkValue
+	}
+	fkValuesJson, err := json.Marshal(fkValues)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sql := fmt.Sprintf(
+		"SELECT %s, value FROM %s WHERE %s IN (SELECT jsonb_array_elements($1::jsonb))",
+		ri.PkField, ri.Table, ri.PkField,
+	)
+	rows, err := s.(session.DbSession).Connection().Query(sql, string(fkValuesJson))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	states := make(map[any]map[string]any, len(fkValues))
+	for rows.Next() {
+		var pkBytes []byte
+		var valueBytes []byte
+		if err := rows.Scan(&pkBytes, &valueBytes); err != nil {
+			return nil, nil, err
+		}
+		key, err := CanonicalJson(pkBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		fkValue, ok := requested[key]
+		if !ok {
+			continue
+		}
+		var state map[string]any
+		if err := json.Unmarshal(valueBytes, &state); err != nil {
+			return nil, nil, err
+		}
+		states[fkValue] = state
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var nestedResolver domainquery.IObjectResolver
+	if ri.NestedResolver != nil {
+		nestedResolver = NewPgObjectResolver(ri.NestedResolver)
+	}
+	return states, nestedResolver, nil
+}
+
+func (r *PgObjectResolver) Descend(field string) domainquery.IObjectResolver {
+	if r.relationResolver == nil {
+		return nil
+	}
+	descended := r.relationResolver.Descend(field)
+	if descended == nil {
+		return nil
+	}
+	return NewPgObjectResolver(descended)
+}
+
+// CanonicalJson re-encodes jsonb output so that it matches json.Marshal of Go values,
+// e.g. to look up rows returned by Postgres by the keys of requested values.
+// Integral numbers are decoded as int64, so large ids keep their precision.
+func CanonicalJson(data []byte) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return "", err
+	}
+	result, err := json.Marshal(canonicalNumbers(v))
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+// canonicalNumbers replaces json.Number with int64 or float64, e.g. jsonb 1.0 with 1.
+func canonicalNumbers(v any) any {
+	switch value := v.(type) {
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+		if f, err := value.Float64(); err == nil {
+			return f
+		}
+		return value
+	case map[string]any:
+		for key, item := range value {
+			value[key] = canonicalNumbers(item)
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = canonicalNumbers(item)
+		}
+	}
+	return v
+}
@@ -0,0 +1,100 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/utils/testutils"
+)
+
+func TestPgObjectResolver(t *testing.T) {
+	companyField := "company_id"
+	countryResolver := &StubRelationResolver{
+		relations: map[string]*RelationInfo{
+			"country_id": {Table: "countries", PkField: "value_id"},
+		},
+	}
+	resolver := NewPgObjectResolver(&DescendableStubRelationResolver{
+		relations: map[string]*RelationInfo{
+			"company_id": {Table: "companies", PkField: "value_id", NestedResolver: countryResolver},
+		},
+		children: map[string]IRelationResolver{
+			"profile": &StubRelationResolver{},
+		},
+	})
+
+	t.Run("resolve single", func(t *testing.T) {
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub(
+			[]any{[]byte(`1`), []byte(`{"id": 1, "name": "Acme"}`)},
+		))
+		state, nested, err := resolver.Resolve(s, &companyField, 1)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": float64(1), "name": "Acme"}, state)
+		assert.NotNil(t, nested)
+		assert.Equal(t,
+			"SELECT value_id, value FROM companies WHERE value_id IN (SELECT jsonb_array_elements($1::jsonb))",
+			s.ActualQuery,
+		)
+		assert.Equal(t, []any{"[1]"}, s.ActualParams)
+	})
+
+	t.Run("resolve missing", func(t *testing.T) {
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+		state, nested, err := resolver.Resolve(s, &companyField, 1)
+		require.NoError(t, err)
+		assert.Nil(t, state)
+		assert.Nil(t, nested)
+	})
+
+	t.Run("resolve many maps rows to requested keys", func(t *testing.T) {
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub(
+			[]any{[]byte(`2`), []byte(`{"name": "Globex"}`)},
+			[]any{[]byte(`"x"`), []byte(`{"name": "Initech"}`)},
+		))
+		states, _, err := resolver.ResolveMany(s, &companyField, []any{2, "x", 3})
+		require.NoError(t, err)
+		assert.Len(t, states, 2)
+		assert.Equal(t, "Globex", states[2]["name"])
+		assert.Equal(t, "Initech", states["x"]["name"])
+		assert.Equal(t, []any{`[2,"x",3]`}, s.ActualParams)
+	})
+
+	t.Run("resolve large int ids", func(t *testing.T) {
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub(
+			[]any{[]byte(`9007199254740993`), []byte(`{"name": "Globex"}`)},
+		))
+		states, _, err := resolver.ResolveMany(s, &companyField, []any{int64(9007199254740993), int64(9007199254740992)})
+		require.NoError(t, err)
+		assert.Equal(t, map[any]map[string]any{int64(9007199254740993): {"name": "Globex"}}, states)
+	})
+
+	t.Run("unknown relation", func(t *testing.T) {
+		unknown := "unknown"
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+		state, _, err := resolver.Resolve(s, &unknown, 1)
+		require.NoError(t, err)
+		assert.Nil(t, state)
+		assert.Equal(t, "", s.ActualQuery)
+	})
+
+	t.Run("descend", func(t *testing.T) {
+		assert.NotNil(t, resolver.Descend("profile"))
+		assert.Nil(t, resolver.Descend("unknown"))
+	})
+}
+
+func TestCanonicalJson(t *testing.T) {
+	for data, expected := range map[string]string{
+		`9007199254740993`:                      `9007199254740993`,
+		`1.0`:                                   `1`,
+		`1.5e300`:                               `1.5e+300`,
+		`{"tenant": 2, "id": 9007199254740993}`: `{"id":9007199254740993,"tenant":2}`,
+		`["a", 3]`:                              `["a",3]`,
+	} {
+		actual, err := CanonicalJson([]byte(data))
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual, data)
+	}
+}
@@ -0,0 +1,97 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+// CompileProjection compiles Projection to a jsonb expression for the SELECT list.
+// Included paths are extracted with jsonb_build_object(),
+// excluded paths are removed with the #- operator,
+// and Rel projections are embedded as correlated subqueries.
+func (c *PgQueryCompiler) CompileProjection(projection domainquery.Projection) (string, error) {
+	return c.projectionExpr(c.targetValueExpr, c.relationResolver, projection)
+}
+
+func (c *PgQueryCompiler) projectionExpr(
+	valueExpr string,
+	relationResolver IRelationResolver,
+	projection domainquery.Projection,
+) (string, error) {
+	expr := valueExpr
+	if len(projection.Include) > 0 {
+		expr = buildObjectExpr(valueExpr, projection.IncludeTree())
+	}
+	for _, path := range projection.SortedRelPaths() {
+		keys := domainquery.SplitPath(path)
+		relExpr, err := c.relProjectionExpr(valueExpr, relationResolver, keys, projection.Rel[path])
+		if err != nil {
+			return "", err
+		}
+		expr = fmt.Sprintf(
+			"jsonb_set(%s, '{%s}', COALESCE(%s, 'null'::jsonb))",
+			expr, strings.Join(keys, ","), relExpr,
+		)
+	}
+	for _, path := range projection.Exclude {
+		keys := domainquery.SplitPath(path)
+		expr = fmt.Sprintf("(%s #- '{%s}')", expr, strings.Join(keys, ","))
+	}
+	return expr, nil
+}
+
+func (c *PgQueryCompiler) relProjectionExpr(
+	valueExpr string,
+	relationResolver IRelationResolver,
+	keys []string,
+	projection domainquery.Projection,
+) (string, error) {
+	if relationResolver == nil {
+		return "", fmt.Errorf("cannot compile $rel projection without relation_resolver")
+	}
+	resolver := relationResolver
+	for _, key := range keys[:len(keys)-1] {
+		if descended := resolver.Descend(key); descended != nil {
+			resolver = descended
+		}
+	}
+	field := keys[len(keys)-1]
+	ri := resolver.Resolve(&field)
+	if ri == nil {
+		return "", fmt.Errorf("cannot compile $rel projection: unknown relation %s", strings.Join(keys, "."))
+	}
+	if ri.Remote {
+		return "", fmt.Errorf("cannot compile $rel projection: relation %s is remote", strings.Join(keys, "."))
+	}
+	alias := c.nextAlias()
+	nestedExpr, err := c.projectionExpr(fmt.Sprintf("%s.value", alias), ri.NestedResolver, projection)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(
+		"(SELECT %s FROM %s %s WHERE %s.%s = %s)",
+		nestedExpr, ri.Table, alias, alias, ri.PkField, pathExpr(valueExpr, keys),
+	), nil
+}
+
+func buildObjectExpr(valueExpr string, tree domainquery.ProjectionTree) string {
+	parts := make([]string, 0, len(tree))
+	for _, key := range tree.SortedKeys() {
+		keyExpr := pathExpr(valueExpr, []string{key})
+		if subtree := tree[key]; subtree != nil {
+			keyExpr = buildObjectExpr(keyExpr, subtree)
+		}
+		parts = append(parts, fmt.Sprintf("'%s', %s", key, keyExpr))
+	}
+	return fmt.Sprintf("jsonb_build_object(%s)", strings.Join(parts, ", "))
+}
+
+func pathExpr(valueExpr string, keys []string) string {
+	expr := valueExpr
+	for _, key := range keys {
+		expr += fmt.Sprintf("->'%s'", key)
+	}
+	return expr
+}
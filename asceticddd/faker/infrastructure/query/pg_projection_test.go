@@ -0,0 +1,77 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+func TestCompileProjection(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		sql, err := compiler.CompileProjection(domainquery.Projection{})
+		require.NoError(t, err)
+		assert.Equal(t, "value", sql)
+	})
+
+	t.Run("include", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		sql, err := compiler.CompileProjection(domainquery.Projection{
+			Include: []string{"name", "address.city", "address.zip"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t,
+			"jsonb_build_object('address', jsonb_build_object('city', value->'address'->'city', 'zip', value->'address'->'zip'), 'name', value->'name')",
+			sql,
+		)
+	})
+
+	t.Run("exclude", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		sql, err := compiler.CompileProjection(domainquery.Projection{
+			Exclude: []string{"secret", "address.zip"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "((value #- '{secret}') #- '{address,zip}')", sql)
+	})
+
+	t.Run("rel", func(t *testing.T) {
+		resolver := &StubRelationResolver{
+			relations: map[string]*RelationInfo{
+				"company_id": {Table: "companies", PkField: "value_id"},
+			},
+		}
+		compiler := NewPgQueryCompiler("", resolver, nil)
+		sql, err := compiler.CompileProjection(domainquery.Projection{
+			Include: []string{"name"},
+			Rel: map[string]domainquery.Projection{
+				"company_id": {Include: []string{"name"}},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t,
+			"jsonb_set(jsonb_build_object('company_id', value->'company_id', 'name', value->'name'), '{company_id}', "+
+				"COALESCE((SELECT jsonb_build_object('name', rt1.value->'name') FROM companies rt1 WHERE rt1.value_id = value->'company_id'), 'null'::jsonb))",
+			sql,
+		)
+	})
+
+	t.Run("rel without resolver", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		_, err := compiler.CompileProjection(domainquery.Projection{
+			Rel: map[string]domainquery.Projection{"company_id": {}},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown rel", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", &StubRelationResolver{}, nil)
+		_, err := compiler.CompileProjection(domainquery.Projection{
+			Rel: map[string]domainquery.Projection{"company_id": {}},
+		})
+		assert.Error(t, err)
+	})
+}
@@ -1,20 +1,42 @@
 package query
 
 import (
+	"context"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
+	"slices"
 	"strings"
 
 	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/signals"
 )
 
 type Jsonb struct {
 	Obj any
 }
 
+// Value implements driver.Valuer, so that compiled params can be passed to the driver as is.
+func (j Jsonb) Value() (driver.Value, error) {
+	data, err := json.Marshal(j.Obj)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
 type RelationInfo struct {
 	Table          string
 	PkField        string
 	NestedResolver IRelationResolver
+	IndexedFields  IndexedFields
+	FieldTypes     domainquery.FieldTypes
+	// Remote marks a relation to another bounded context whose data is not in the local database.
+	// PgQueryCompiler does not compile its predicates and reports its path, see NonPushablePaths.
+	Remote bool
 }
 
 type IRelationResolver interface {
@@ -25,6 +47,8 @@ type IRelationResolver interface {
 	Descend(field string) IRelationResolver
 }
 
+const defaultInArrayThreshold = 100
+
 var sqlOps = map[string]string{
 	"$gt":  ">",
 	"$gte": ">=",
@@ -32,6 +56,8 @@ var sqlOps = map[string]string{
 	"$lte": "<=",
 }
 
+// PgQueryCompiler compiles IQueryOperator to SQL over the jsonb "value" column.
+// Numbers are compared exactly by SQL, without the tolerance of EvaluateWalker.SetFloatTolerance.
 type PgQueryCompiler struct {
 	targetValueExpr  string
 	relationResolver IRelationResolver
@@ -40,6 +66,19 @@ type PgQueryCompiler struct {
 	eqValues         map[string]any
 	sqlParts         []string
 	params           []any
+	table            string
+	indexedFields    IndexedFields
+	pathPrefix       []string
+	diagnostics      *queryDiagnostics
+	paramBinder      ParamBinder
+	fieldTypes       domainquery.FieldTypes
+	compileCache     *CompileCache
+	customOperators  *domainquery.CustomOperatorRegistry
+	inArrayThreshold int
+	tracer           domainquery.IQueryTracer
+	ctx              context.Context
+	// skipped is set when a predicate of a remote relation was left out of sqlParts.
+	skipped bool
 }
 
 func NewPgQueryCompiler(targetValueExpr string, relationResolver IRelationResolver, aliasSeq *int) *PgQueryCompiler {
@@ -55,24 +94,180 @@ func NewPgQueryCompiler(targetValueExpr string, relationResolver IRelationResolv
 		relationResolver: relationResolver,
 		aliasSeq:         aliasSeq,
 		eqValues:         map[string]any{},
+		diagnostics:      newQueryDiagnostics(),
+		paramBinder:      DollarParamBinder{},
+		inArrayThreshold: defaultInArrayThreshold,
+		ctx:              context.Background(),
 	}
 }
 
+// SetParamBinder sets the placeholder style of compiled SQL, $n by default.
+func (c *PgQueryCompiler) SetParamBinder(paramBinder ParamBinder) {
+	c.paramBinder = paramBinder
+}
+
+func (c *PgQueryCompiler) ParamBinder() ParamBinder {
+	return c.paramBinder
+}
+
+// SetIndexedFields sets index metadata of the target table.
+// When set, the compiler emits OnWarning events for predicates on non-indexed paths.
+func (c *PgQueryCompiler) SetIndexedFields(table string, indexedFields IndexedFields) {
+	c.table = table
+	c.indexedFields = indexedFields
+}
+
+// SetFieldTypes sets type hints of the target table paths.
+// Predicates on hinted paths compare the text value cast to the SQL type
+// instead of jsonb, e.g. (value->>'age')::numeric > $1.
+func (c *PgQueryCompiler) SetFieldTypes(fieldTypes domainquery.FieldTypes) {
+	c.fieldTypes = fieldTypes
+}
+
+// SetCompileCache makes Compile reuse SQL of queries of the same structure, see CompileCache.
+// OnWarning and OnPredicate notify about queries compiled on cache misses only.
+func (c *PgQueryCompiler) SetCompileCache(compileCache *CompileCache) {
+	c.compileCache = compileCache
+}
+
+// SetInArrayThreshold sets the number of $in values above which they are passed as a single jsonb array param,
+// 100 by default, 0 never does:
+//
+//	value->'status' IN (SELECT jsonb_array_elements($1))
+//	(value->>'age')::numeric IN (SELECT (jsonb_array_elements_text($1))::numeric)
+//
+// instead of a containment per value or a placeholder per value of a hinted path.
+// Values of objects and arrays, and $in on the whole value, keep containments,
+// whose semantics differ from equality for them. The array form does not use the GIN index on the value.
+func (c *PgQueryCompiler) SetInArrayThreshold(threshold int) {
+	c.inArrayThreshold = threshold
+}
+
+// SetCustomOperators sets the registry of custom operators, compiled by their PgOperatorEmitter.
+func (c *PgQueryCompiler) SetCustomOperators(customOperators *domainquery.CustomOperatorRegistry) {
+	c.customOperators = customOperators
+}
+
+// SetTracer traces compilations, see domainquery.IQueryTracer.
+func (c *PgQueryCompiler) SetTracer(tracer domainquery.IQueryTracer) {
+	c.tracer = tracer
+}
+
+func (c *PgQueryCompiler) OnWarning() signals.Signal[QueryWarningEvent] {
+	return c.diagnostics.onWarning
+}
+
+// OnPredicate notifies about indexable predicates of compiled queries, see IndexAdvisor.
+func (c *PgQueryCompiler) OnPredicate() signals.Signal[PredicateEvent] {
+	return c.diagnostics.onPredicate
+}
+
+// NonPushablePaths returns the paths of remote relations of the last compiled query.
+// Their predicates are left out, so the SQL matches a superset of the rows,
+// which must be filtered with EvaluateWalker and a resolver of the remote relations, e.g. RoutingObjectResolver.
+// A remote relation under $not leaves out the whole $not, under $or the whole $or.
+func (c *PgQueryCompiler) NonPushablePaths() []string {
+	return c.diagnostics.nonPushable
+}
+
+// Compile compiles the query to an SQL predicate, panics are returned as *domainquery.QueryPanicError.
+//
+// Deprecated: use CompileContext.
 func (c *PgQueryCompiler) Compile(query domainquery.IQueryOperator) (string, []any, error) {
+	return c.CompileContext(context.Background(), query)
+}
+
+// CompileContext is Compile aborted with the error of the context once it is done,
+// which is checked at each field, e.g. of deep relation cascades.
+func (c *PgQueryCompiler) CompileContext(ctx context.Context, query domainquery.IQueryOperator) (sql string, params []any, err error) {
+	ctx, end := domainquery.TraceQuery(ctx, c.tracer, domainquery.QueryOperationCompile, "", query)
+	defer func() { end(err) }()
+	defer domainquery.RecoverQueryPanic(&err, query, nil)
+	if err := ctx.Err(); err != nil {
+		return "", nil, err
+	}
+	c.ctx = ctx
+	defer func() { c.ctx = context.Background() }()
+	if c.compileCache != nil {
+		return c.compileCached(query)
+	}
+	return c.compile(query)
+}
+
+func (c *PgQueryCompiler) compile(query domainquery.IQueryOperator) (string, []any, error) {
 	c.fieldPath = nil
 	c.eqValues = map[string]any{}
 	c.sqlParts = nil
 	c.params = nil
+	c.skipped = false
+	c.diagnostics.reset()
 	_, err := query.Accept(c)
 	if err != nil {
 		return "", nil, err
 	}
 	c.flushEq()
 	sql := c.sql()
-	sql = replaceParamMarkers(sql)
+	sql = bindParamMarkers(sql, c.paramBinder)
 	return sql, c.params, nil
 }
 
+func (c *PgQueryCompiler) compileCached(query domainquery.IQueryOperator) (string, []any, error) {
+	key, template, values, err := newQueryShape(query)
+	if errors.Is(err, errNotCacheable) {
+		return c.compile(query)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	entry, ok := c.compileCache.get(key)
+	if ok {
+		c.diagnostics.reset()
+		c.diagnostics.nonPushable = slices.Clone(entry.nonPushable)
+	} else {
+		sql, params, err := c.compile(template)
+		if err != nil {
+			return "", nil, err
+		}
+		entry = &compiledQuery{key: key, sql: sql, params: params, nonPushable: slices.Clone(c.diagnostics.nonPushable)}
+		c.compileCache.put(entry)
+	}
+	params := make([]any, len(entry.params))
+	for i, param := range entry.params {
+		params[i] = bindSlots(param, values)
+	}
+	return entry.sql, params, nil
+}
+
+// CompileCount compiles the query to SELECT count(*) over the table.
+// Nil query counts all rows.
+func (c *PgQueryCompiler) CompileCount(table string, query domainquery.IQueryOperator) (string, []any, error) {
+	where, params, err := c.compileWhereClause(query)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("SELECT count(*) FROM %s%s", table, where), params, nil
+}
+
+// CompileExists compiles the query to SELECT EXISTS(...) over the table.
+func (c *PgQueryCompiler) CompileExists(table string, query domainquery.IQueryOperator) (string, []any, error) {
+	where, params, err := c.compileWhereClause(query)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s%s)", table, where), params, nil
+}
+
+func (c *PgQueryCompiler) compileWhereClause(query domainquery.IQueryOperator) (string, []any, error) {
+	if query == nil {
+		return "", nil, nil
+	}
+	sql, params, err := c.CompileContext(context.Background(), query)
+	if err != nil || sql == "" {
+		return "", params, err
+	}
+	return " WHERE " + sql, params, nil
+}
+
 func (c *PgQueryCompiler) sql() string {
 	if len(c.sqlParts) == 0 {
 		return ""
@@ -80,6 +275,45 @@ func (c *PgQueryCompiler) sql() string {
 	return strings.Join(c.sqlParts, " AND ")
 }
 
+// subCompiler creates a compiler for a nested SQL fragment of the same table.
+func (c *PgQueryCompiler) subCompiler(targetValueExpr string, pathPrefix []string) *PgQueryCompiler {
+	sub := NewPgQueryCompiler(targetValueExpr, c.relationResolver, c.aliasSeq)
+	sub.table = c.table
+	sub.indexedFields = c.indexedFields
+	sub.fieldTypes = c.fieldTypes
+	sub.customOperators = c.customOperators
+	sub.inArrayThreshold = c.inArrayThreshold
+	sub.ctx = c.ctx
+	sub.pathPrefix = pathPrefix
+	sub.diagnostics = c.diagnostics
+	return sub
+}
+
+func (c *PgQueryCompiler) currentPath() []string {
+	path := make([]string, 0, len(c.pathPrefix)+len(c.fieldPath))
+	path = append(path, c.pathPrefix...)
+	return append(path, c.fieldPath...)
+}
+
+func (c *PgQueryCompiler) checkIndexed() error {
+	if len(c.fieldPath) == 0 {
+		return nil
+	}
+	return c.diagnostics.checkIndexed(c.table, c.indexedFields, c.currentPath(), PathUsageFilter)
+}
+
+// reportPredicate reports the predicate on the current path.
+// Comparisons on hinted paths carry the hint of their typed expression.
+func (c *PgQueryCompiler) reportPredicate(kind PredicateKind) error {
+	var hint domainquery.TypeHint
+	if kind == PredicateComparison {
+		if _, ok := c.typedExpr(); ok {
+			hint, _ = c.fieldTypes.Get(joinPath(c.currentPath()))
+		}
+	}
+	return c.diagnostics.reportPredicate(c.table, c.indexedFields, c.currentPath(), PathUsageFilter, kind, hint)
+}
+
 func (c *PgQueryCompiler) nextAlias() string {
 	*c.aliasSeq++
 	return fmt.Sprintf("rt%d", *c.aliasSeq)
@@ -88,6 +322,20 @@ func (c *PgQueryCompiler) nextAlias() string {
 // --- Visitor methods ---
 
 func (c *PgQueryCompiler) VisitEq(op domainquery.EqOperator) (any, error) {
+	if err := c.checkIndexed(); err != nil {
+		return nil, err
+	}
+	if expr, ok := c.typedExpr(); ok {
+		if err := c.reportPredicate(PredicateComparison); err != nil {
+			return nil, err
+		}
+		c.sqlParts = append(c.sqlParts, fmt.Sprintf("%s = ?", expr))
+		c.params = append(c.params, op.Value)
+		return nil, nil
+	}
+	if err := c.reportPredicate(PredicateContainment); err != nil {
+		return nil, err
+	}
 	if len(c.fieldPath) > 0 {
 		c.collectEq(op.Value)
 	} else {
@@ -98,18 +346,58 @@ func (c *PgQueryCompiler) VisitEq(op domainquery.EqOperator) (any, error) {
 }
 
 func (c *PgQueryCompiler) VisitComparison(op domainquery.ComparisonOperator) (any, error) {
+	if err := c.checkIndexed(); err != nil {
+		return nil, err
+	}
 	if op.Op == "$ne" {
+		if expr, ok := c.typedExpr(); ok {
+			c.sqlParts = append(c.sqlParts, fmt.Sprintf("%s IS DISTINCT FROM ?", expr))
+			c.params = append(c.params, op.Value)
+			return nil, nil
+		}
 		c.compileNe(op.Value)
 		return nil, nil
 	}
+	if err := c.reportPredicate(PredicateComparison); err != nil {
+		return nil, err
+	}
 	sqlOp := sqlOps[op.Op]
-	jsonPath := c.jsonPathExpr()
-	c.sqlParts = append(c.sqlParts, fmt.Sprintf("%s %s ?", jsonPath, sqlOp))
+	c.sqlParts = append(c.sqlParts, fmt.Sprintf("%s %s ?", c.comparableExpr(), sqlOp))
 	c.params = append(c.params, op.Value)
 	return nil, nil
 }
 
 func (c *PgQueryCompiler) VisitIn(op domainquery.InOperator) (any, error) {
+	if err := c.checkIndexed(); err != nil {
+		return nil, err
+	}
+	if expr, ok := c.typedExpr(); ok {
+		if err := c.reportPredicate(PredicateComparison); err != nil {
+			return nil, err
+		}
+		if c.inArray(op.Values) {
+			hint, _ := c.typeHint()
+			elementExpr, _ := castTextExpr("jsonb_array_elements_text(?)", hint)
+			c.sqlParts = append(c.sqlParts, fmt.Sprintf("%s IN (SELECT %s)", expr, elementExpr))
+			c.params = append(c.params, encode(op.Values))
+			return nil, nil
+		}
+		placeholders := make([]string, len(op.Values))
+		for i, value := range op.Values {
+			placeholders[i] = "?"
+			c.params = append(c.params, value)
+		}
+		c.sqlParts = append(c.sqlParts, fmt.Sprintf("%s IN (%s)", expr, strings.Join(placeholders, ", ")))
+		return nil, nil
+	}
+	if err := c.reportPredicate(PredicateContainment); err != nil {
+		return nil, err
+	}
+	if len(c.fieldPath) > 0 && c.inArray(op.Values) {
+		c.sqlParts = append(c.sqlParts, fmt.Sprintf("%s IN (SELECT jsonb_array_elements(?))", c.jsonPathExpr()))
+		c.params = append(c.params, encode(op.Values))
+		return nil, nil
+	}
 	var orParts []string
 	for _, value := range op.Values {
 		if len(c.fieldPath) > 0 {
@@ -129,7 +417,42 @@ func (c *PgQueryCompiler) VisitIn(op domainquery.InOperator) (any, error) {
 	return nil, nil
 }
 
+// inArray reports whether the $in values are compiled to a jsonb array param, see SetInArrayThreshold.
+func (c *PgQueryCompiler) inArray(values []any) bool {
+	if c.inArrayThreshold <= 0 || len(values) <= c.inArrayThreshold {
+		return false
+	}
+	return !slices.ContainsFunc(values, func(value any) bool { return !isJsonScalar(value) })
+}
+
+// isJsonScalar reports whether the value is encoded as a JSON string, number, boolean or null.
+func isJsonScalar(value any) bool {
+	if _, ok := value.(encoding.TextMarshaler); ok {
+		return true
+	}
+	switch reflect.Indirect(reflect.ValueOf(value)).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Interface:
+		return false
+	}
+	return true
+}
+
+func (c *PgQueryCompiler) VisitBetween(op domainquery.BetweenOperator) (any, error) {
+	if err := c.checkIndexed(); err != nil {
+		return nil, err
+	}
+	if err := c.reportPredicate(PredicateComparison); err != nil {
+		return nil, err
+	}
+	c.sqlParts = append(c.sqlParts, betweenSql(c.comparableExpr(), op))
+	c.params = append(c.params, op.Low, op.High)
+	return nil, nil
+}
+
 func (c *PgQueryCompiler) VisitIsNull(op domainquery.IsNullOperator) (any, error) {
+	if err := c.checkIndexed(); err != nil {
+		return nil, err
+	}
 	var jsonPath string
 	if len(c.fieldPath) > 0 {
 		jsonPath = c.jsonPathExpr()
@@ -156,8 +479,10 @@ func (c *PgQueryCompiler) VisitAnd(op domainquery.AndOperator) (any, error) {
 
 func (c *PgQueryCompiler) VisitOr(op domainquery.OrOperator) (any, error) {
 	var orParts []string
+	var orParams []any
+	skipped := false
 	for _, operand := range op.Operands {
-		sub := NewPgQueryCompiler(c.targetValueExpr, c.relationResolver, c.aliasSeq)
+		sub := c.subCompiler(c.targetValueExpr, c.pathPrefix)
 		sub.fieldPath = make([]string, len(c.fieldPath))
 		copy(sub.fieldPath, c.fieldPath)
 		_, err := operand.Accept(sub)
@@ -165,25 +490,35 @@ func (c *PgQueryCompiler) VisitOr(op domainquery.OrOperator) (any, error) {
 			return nil, err
 		}
 		sub.flushEq()
+		skipped = skipped || sub.skipped
 		if subSql := sub.sql(); subSql != "" {
 			orParts = append(orParts, subSql)
-			c.params = append(c.params, sub.params...)
+			orParams = append(orParams, sub.params...)
 		}
 	}
+	if skipped {
+		c.skipped = true
+		return nil, nil
+	}
 	if len(orParts) > 0 {
 		c.sqlParts = append(c.sqlParts, fmt.Sprintf("(%s)", strings.Join(orParts, " OR ")))
+		c.params = append(c.params, orParams...)
 	}
 	return nil, nil
 }
 
 func (c *PgQueryCompiler) VisitNot(op domainquery.NotOperator) (any, error) {
-	sub := NewPgQueryCompiler(c.targetValueExpr, c.relationResolver, c.aliasSeq)
+	sub := c.subCompiler(c.targetValueExpr, c.pathPrefix)
 	sub.fieldPath = make([]string, len(c.fieldPath))
 	copy(sub.fieldPath, c.fieldPath)
 	_, err := op.Operand.Accept(sub)
 	if err != nil {
 		return nil, err
 	}
+	if sub.skipped {
+		c.skipped = true
+		return nil, nil
+	}
 	sub.flushEq()
 	if subSql := sub.sql(); subSql != "" {
 		c.sqlParts = append(c.sqlParts, fmt.Sprintf("NOT (%s)", subSql))
@@ -199,12 +534,16 @@ func (c *PgQueryCompiler) VisitAnyElement(op domainquery.AnyElementOperator) (an
 	} else {
 		jsonPath = c.targetValueExpr
 	}
+	if err := c.checkIndexed(); err != nil {
+		return nil, err
+	}
 	alias := c.nextAlias()
-	sub := NewPgQueryCompiler(alias, c.relationResolver, c.aliasSeq)
+	sub := c.subCompiler(alias, append(c.currentPath(), arrayElementsPathKey))
 	_, err := op.Query.Accept(sub)
 	if err != nil {
 		return nil, err
 	}
+	c.skipped = c.skipped || sub.skipped
 	sub.flushEq()
 	if subSql := sub.sql(); subSql != "" {
 		sql := fmt.Sprintf(
@@ -224,12 +563,16 @@ func (c *PgQueryCompiler) VisitAllElements(op domainquery.AllElementsOperator) (
 	} else {
 		jsonPath = c.targetValueExpr
 	}
+	if err := c.checkIndexed(); err != nil {
+		return nil, err
+	}
 	alias := c.nextAlias()
-	sub := NewPgQueryCompiler(alias, c.relationResolver, c.aliasSeq)
+	sub := c.subCompiler(alias, append(c.currentPath(), arrayElementsPathKey))
 	_, err := op.Query.Accept(sub)
 	if err != nil {
 		return nil, err
 	}
+	c.skipped = c.skipped || sub.skipped
 	sub.flushEq()
 	if subSql := sub.sql(); subSql != "" {
 		sql := fmt.Sprintf(
@@ -249,6 +592,9 @@ func (c *PgQueryCompiler) VisitLen(op domainquery.LenOperator) (any, error) {
 	} else {
 		jsonPath = c.targetValueExpr
 	}
+	if err := c.checkIndexed(); err != nil {
+		return nil, err
+	}
 	lenExpr := fmt.Sprintf("jsonb_array_length(%s)", jsonPath)
 	scalar := NewScalarPgQueryCompiler(lenExpr)
 	_, err := op.Query.Accept(scalar)
@@ -264,32 +610,37 @@ func (c *PgQueryCompiler) VisitLen(op domainquery.LenOperator) (any, error) {
 
 func (c *PgQueryCompiler) VisitComposite(op domainquery.CompositeQuery) (any, error) {
 	for field, fieldOp := range op.Fields {
-		if _, ok := fieldOp.(domainquery.RelOperator); ok {
-			f := field
-			err := c.compileRelField(&f, fieldOp.(domainquery.RelOperator))
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			c.fieldPath = append(c.fieldPath, field)
-			oldResolver := c.relationResolver
-			if c.relationResolver != nil {
-				descended := c.relationResolver.Descend(field)
-				if descended != nil {
-					c.relationResolver = descended
-				}
-			}
-			_, err := fieldOp.Accept(c)
-			if err != nil {
-				return nil, err
-			}
-			c.relationResolver = oldResolver
-			c.fieldPath = c.fieldPath[:len(c.fieldPath)-1]
+		if err := c.compileField(field, fieldOp); err != nil {
+			return nil, err
 		}
 	}
 	return nil, nil
 }
 
+func (c *PgQueryCompiler) compileField(field string, fieldOp domainquery.IQueryOperator) error {
+	defer domainquery.AnnotateQueryPanic(field, fieldOp, nil)
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	if relOp, ok := fieldOp.(domainquery.RelOperator); ok {
+		return c.compileRelField(&field, relOp)
+	}
+	c.fieldPath = append(c.fieldPath, field)
+	oldResolver := c.relationResolver
+	if c.relationResolver != nil {
+		descended := c.relationResolver.Descend(field)
+		if descended != nil {
+			c.relationResolver = descended
+		}
+	}
+	if _, err := fieldOp.Accept(c); err != nil {
+		return err
+	}
+	c.relationResolver = oldResolver
+	c.fieldPath = c.fieldPath[:len(c.fieldPath)-1]
+	return nil
+}
+
 func (c *PgQueryCompiler) VisitRel(op domainquery.RelOperator) (any, error) {
 	if c.relationResolver == nil {
 		return nil, fmt.Errorf("cannot compile $rel without relation_resolver")
@@ -336,9 +687,19 @@ func (c *PgQueryCompiler) compileRelField(field *string, op domainquery.RelOpera
 
 	ri := c.relationResolver.Resolve(field)
 
+	if ri != nil && ri.Remote {
+		path := c.currentPath()
+		if field != nil {
+			path = append(path, *field)
+		}
+		c.diagnostics.reportNonPushable(joinPath(path))
+		c.skipped = true
+		return nil
+	}
 	if ri != nil {
-		c.buildExistsSubquery(field, op, ri)
-	} else if field != nil {
+		return c.buildExistsSubquery(field, op, ri)
+	}
+	if field != nil {
 		nested := toDict(op.Query)
 		if nested != nil {
 			c.sqlParts = append(c.sqlParts, fmt.Sprintf("%s @> ?", c.targetValueExpr))
@@ -348,7 +709,7 @@ func (c *PgQueryCompiler) compileRelField(field *string, op domainquery.RelOpera
 	return nil
 }
 
-func (c *PgQueryCompiler) buildExistsSubquery(field *string, op domainquery.RelOperator, ri *RelationInfo) {
+func (c *PgQueryCompiler) buildExistsSubquery(field *string, op domainquery.RelOperator, ri *RelationInfo) error {
 	alias := c.nextAlias()
 
 	nested := NewPgQueryCompiler(
@@ -356,7 +717,17 @@ func (c *PgQueryCompiler) buildExistsSubquery(field *string, op domainquery.RelO
 		ri.NestedResolver,
 		c.aliasSeq,
 	)
-	op.Query.Accept(nested)
+	nested.table = ri.Table
+	nested.indexedFields = ri.IndexedFields
+	nested.fieldTypes = ri.FieldTypes
+	nested.customOperators = c.customOperators
+	nested.inArrayThreshold = c.inArrayThreshold
+	nested.ctx = c.ctx
+	nested.diagnostics = c.diagnostics
+	if _, err := op.Query.Accept(nested); err != nil {
+		return err
+	}
+	c.skipped = c.skipped || nested.skipped
 	nested.flushEq()
 
 	if nestedSql := nested.sql(); nestedSql != "" {
@@ -373,6 +744,7 @@ func (c *PgQueryCompiler) buildExistsSubquery(field *string, op domainquery.RelO
 		c.sqlParts = append(c.sqlParts, sql)
 		c.params = append(c.params, nested.params...)
 	}
+	return nil
 }
 
 // --- Helpers ---
@@ -385,6 +757,68 @@ func (c *PgQueryCompiler) jsonPathExpr() string {
 	return expr
 }
 
+// comparableExpr returns the expression of the current path for ordering comparisons:
+// the typed expression of a hinted path, or jsonb otherwise.
+func (c *PgQueryCompiler) comparableExpr() string {
+	if expr, ok := c.typedExpr(); ok {
+		return expr
+	}
+	return c.jsonPathExpr()
+}
+
+var typeHintCasts = map[domainquery.TypeHint]string{
+	domainquery.TypeHintNumeric:  "numeric",
+	domainquery.TypeHintBool:     "boolean",
+	domainquery.TypeHintDatetime: "timestamptz",
+	domainquery.TypeHintUuid:     "uuid",
+}
+
+// typedExpr returns the text value of the current path cast according to its type hint.
+func (c *PgQueryCompiler) typedExpr() (string, bool) {
+	hint, ok := c.typeHint()
+	if !ok {
+		return "", false
+	}
+	return castTextExpr(c.jsonTextPathExpr(), hint)
+}
+
+func (c *PgQueryCompiler) typeHint() (domainquery.TypeHint, bool) {
+	path := c.currentPath()
+	if len(path) == 0 {
+		return "", false
+	}
+	return c.fieldTypes.Get(joinPath(path))
+}
+
+// TypedPathExpr returns the expression compiled predicates on the hinted dot-separated path compare,
+// e.g. (value->>'age')::numeric, so expression indexes can match the compiled queries.
+// Paths into arrays have no such expression.
+func TypedPathExpr(valueExpr string, path string, hint domainquery.TypeHint) (string, bool) {
+	if path == "" || strings.Contains(path, arrayElementsPathKey) {
+		return "", false
+	}
+	return castTextExpr(textPathExpr(valueExpr, strings.Split(path, ".")), hint)
+}
+
+func castTextExpr(textExpr string, hint domainquery.TypeHint) (string, bool) {
+	if hint == domainquery.TypeHintText {
+		return textExpr, true
+	}
+	cast, ok := typeHintCasts[hint]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("(%s)::%s", textExpr, cast), true
+}
+
+// jsonTextPathExpr is jsonPathExpr with the last key extracted as text.
+func (c *PgQueryCompiler) jsonTextPathExpr() string {
+	if len(c.fieldPath) == 0 {
+		return fmt.Sprintf("%s #>> '{}'", c.targetValueExpr)
+	}
+	return textPathExpr(c.targetValueExpr, c.fieldPath)
+}
+
 func (c *PgQueryCompiler) compileNe(value any) {
 	if len(c.fieldPath) > 0 {
 		nested := buildNestedDict(c.fieldPath, value)
@@ -417,6 +851,13 @@ func toDict(op domainquery.IQueryOperator) any {
 	}
 }
 
+func betweenSql(expr string, op domainquery.BetweenOperator) string {
+	if op.Inclusive {
+		return fmt.Sprintf("%s BETWEEN ? AND ?", expr)
+	}
+	return fmt.Sprintf("(%s > ? AND %s < ?)", expr, expr)
+}
+
 func encode(obj any) Jsonb {
 	return Jsonb{Obj: obj}
 }
@@ -433,17 +874,7 @@ func buildNestedDict(fieldPath []string, value any) map[string]any {
 }
 
 func replaceParamMarkers(sql string) string {
-	var b strings.Builder
-	idx := 1
-	for i := 0; i < len(sql); i++ {
-		if sql[i] == '?' {
-			b.WriteString(fmt.Sprintf("$%d", idx))
-			idx++
-		} else {
-			b.WriteByte(sql[i])
-		}
-	}
-	return b.String()
+	return bindParamMarkers(sql, DollarParamBinder{})
 }
 
 // ScalarPgQueryCompiler compiles IQueryOperator tree against a scalar SQL expression.
@@ -451,25 +882,29 @@ func replaceParamMarkers(sql string) string {
 // this generates standard SQL comparisons (=, >, <, etc.)
 // for plain values like jsonb_array_length().
 type ScalarPgQueryCompiler struct {
-	targetExpr string
-	sqlParts   []string
-	params     []any
+	targetExpr  string
+	sqlParts    []string
+	params      []any
+	paramBinder ParamBinder
 }
 
 func NewScalarPgQueryCompiler(targetExpr string) *ScalarPgQueryCompiler {
-	return &ScalarPgQueryCompiler{targetExpr: targetExpr}
+	return &ScalarPgQueryCompiler{targetExpr: targetExpr, paramBinder: DollarParamBinder{}}
+}
+
+func (c *ScalarPgQueryCompiler) SetParamBinder(paramBinder ParamBinder) {
+	c.paramBinder = paramBinder
 }
 
-func (c *ScalarPgQueryCompiler) Compile(query domainquery.IQueryOperator) (string, []any, error) {
+// Compile compiles the query to an SQL predicate, panics are returned as *domainquery.QueryPanicError.
+func (c *ScalarPgQueryCompiler) Compile(query domainquery.IQueryOperator) (sql string, params []any, err error) {
+	defer domainquery.RecoverQueryPanic(&err, query, nil)
 	c.sqlParts = nil
 	c.params = nil
-	_, err := query.Accept(c)
-	if err != nil {
+	if _, err := query.Accept(c); err != nil {
 		return "", nil, err
 	}
-	sql := c.sql()
-	sql = replaceParamMarkers(sql)
-	return sql, c.params, nil
+	return bindParamMarkers(c.sql(), c.paramBinder), c.params, nil
 }
 
 func (c *ScalarPgQueryCompiler) sql() string {
@@ -511,6 +946,12 @@ func (c *ScalarPgQueryCompiler) VisitIn(op domainquery.InOperator) (any, error)
 	return nil, nil
 }
 
+func (c *ScalarPgQueryCompiler) VisitBetween(op domainquery.BetweenOperator) (any, error) {
+	c.sqlParts = append(c.sqlParts, betweenSql(c.targetExpr, op))
+	c.params = append(c.params, op.Low, op.High)
+	return nil, nil
+}
+
 func (c *ScalarPgQueryCompiler) VisitIsNull(op domainquery.IsNullOperator) (any, error) {
 	if op.Value {
 		c.sqlParts = append(c.sqlParts, fmt.Sprintf("%s IS NULL", c.targetExpr))
@@ -1,7 +1,9 @@
 package query
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -355,6 +357,185 @@ func TestVisitComparison(t *testing.T) {
 	})
 }
 
+func TestVisitBetween(t *testing.T) {
+	t.Run("inclusive", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		sql, params, err := compiler.Compile(domainquery.CompositeQuery{
+			Fields: map[string]domainquery.IQueryOperator{
+				"age": domainquery.BetweenOperator{Low: 18, High: 65, Inclusive: true},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "value->'age' BETWEEN $1 AND $2", sql)
+		assert.Equal(t, []any{18, 65}, params)
+	})
+
+	t.Run("exclusive", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		sql, params, err := compiler.Compile(domainquery.CompositeQuery{
+			Fields: map[string]domainquery.IQueryOperator{
+				"age": domainquery.BetweenOperator{Low: 18, High: 65},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "(value->'age' > $1 AND value->'age' < $2)", sql)
+		assert.Equal(t, []any{18, 65}, params)
+	})
+
+	t.Run("len between", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		sql, params, err := compiler.Compile(domainquery.CompositeQuery{
+			Fields: map[string]domainquery.IQueryOperator{
+				"tags": domainquery.LenOperator{Query: domainquery.BetweenOperator{Low: 1, High: 3, Inclusive: true}},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "jsonb_array_length(value->'tags') BETWEEN $1 AND $2", sql)
+		assert.Equal(t, []any{1, 3}, params)
+	})
+}
+
+func TestFieldTypesCompilation(t *testing.T) {
+	hints := domainquery.FieldTypes{
+		"created_at":       domainquery.TypeHintDatetime,
+		"audit.updated_at": domainquery.TypeHintDatetime,
+		"events[*].at":     domainquery.TypeHintDatetime,
+		"holidays[*]":      domainquery.TypeHintDatetime,
+		"age":              domainquery.TypeHintNumeric,
+		"active":           domainquery.TypeHintBool,
+		"code":             domainquery.TypeHintText,
+		"owner_id":         domainquery.TypeHintUuid,
+	}
+
+	compile := func(query domainquery.IQueryOperator) (string, []any) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetFieldTypes(hints)
+		sql, params, err := compiler.Compile(query)
+		require.NoError(t, err)
+		return sql, params
+	}
+
+	t.Run("comparison casts to timestamptz", func(t *testing.T) {
+		sql, params := compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"created_at": domainquery.ComparisonOperator{Op: "$gt", Value: "2024-01-01T00:00:00Z"},
+		}})
+		assert.Equal(t, "(value->>'created_at')::timestamptz > $1", sql)
+		assert.Equal(t, []any{"2024-01-01T00:00:00Z"}, params)
+	})
+
+	t.Run("nested path", func(t *testing.T) {
+		sql, _ := compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"audit": domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"updated_at": domainquery.ComparisonOperator{Op: "$lte", Value: "2024-01-01"},
+			}},
+		}})
+		assert.Equal(t, "(value->'audit'->>'updated_at')::timestamptz <= $1", sql)
+	})
+
+	t.Run("between", func(t *testing.T) {
+		sql, params := compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"created_at": domainquery.BetweenOperator{Low: "2024-01-01", High: "2024-12-31", Inclusive: true},
+		}})
+		assert.Equal(t, "(value->>'created_at')::timestamptz BETWEEN $1 AND $2", sql)
+		assert.Equal(t, []any{"2024-01-01", "2024-12-31"}, params)
+	})
+
+	t.Run("array element fields", func(t *testing.T) {
+		sql, _ := compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"events": domainquery.AnyElementOperator{Query: domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"at": domainquery.ComparisonOperator{Op: "$gt", Value: "2024-01-01"},
+			}}},
+		}})
+		assert.Equal(t,
+			"EXISTS (SELECT 1 FROM jsonb_array_elements(value->'events') AS rt1 WHERE (rt1->>'at')::timestamptz > $1)",
+			sql,
+		)
+	})
+
+	t.Run("scalar array elements", func(t *testing.T) {
+		sql, _ := compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"holidays": domainquery.AnyElementOperator{Query: domainquery.ComparisonOperator{Op: "$gt", Value: "2024-01-01"}},
+		}})
+		assert.Equal(t,
+			"EXISTS (SELECT 1 FROM jsonb_array_elements(value->'holidays') AS rt1 WHERE (rt1 #>> '{}')::timestamptz > $1)",
+			sql,
+		)
+	})
+
+	t.Run("numeric comparison", func(t *testing.T) {
+		sql, params := compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"age": domainquery.ComparisonOperator{Op: "$gte", Value: 18},
+		}})
+		assert.Equal(t, "(value->>'age')::numeric >= $1", sql)
+		assert.Equal(t, []any{18}, params)
+	})
+
+	t.Run("typed eq is not collected into containment", func(t *testing.T) {
+		sql, params := compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"age": domainquery.EqOperator{Value: "42"},
+		}})
+		assert.Equal(t, "(value->>'age')::numeric = $1", sql)
+		assert.Equal(t, []any{"42"}, params)
+	})
+
+	t.Run("typed ne", func(t *testing.T) {
+		sql, _ := compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"active": domainquery.ComparisonOperator{Op: "$ne", Value: true},
+		}})
+		assert.Equal(t, "(value->>'active')::boolean IS DISTINCT FROM $1", sql)
+	})
+
+	t.Run("typed in", func(t *testing.T) {
+		sql, params := compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"code": domainquery.InOperator{Values: []any{"a", "b"}},
+		}})
+		assert.Equal(t, "value->>'code' IN ($1, $2)", sql)
+		assert.Equal(t, []any{"a", "b"}, params)
+	})
+
+	t.Run("uuid", func(t *testing.T) {
+		sql, _ := compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"owner_id": domainquery.EqOperator{Value: "6f9619ff-8b86-d011-b42d-00cf4fc964ff"},
+		}})
+		assert.Equal(t, "(value->>'owner_id')::uuid = $1", sql)
+	})
+
+	t.Run("unhinted path is jsonb", func(t *testing.T) {
+		sql, _ := compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"rank": domainquery.ComparisonOperator{Op: "$gt", Value: 18},
+		}})
+		assert.Equal(t, "value->'rank' > $1", sql)
+	})
+
+	t.Run("relation type hints", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", &StubRelationResolver{
+			relations: map[string]*RelationInfo{
+				"company_id": {
+					Table:      "companies",
+					PkField:    "value_id",
+					FieldTypes: domainquery.FieldTypes{"founded_at": domainquery.TypeHintDatetime},
+				},
+			},
+		}, nil)
+		sql, _, err := compiler.Compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"company_id": domainquery.RelOperator{Query: domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"founded_at": domainquery.ComparisonOperator{Op: "$lt", Value: "2000-01-01"},
+			}}},
+		}})
+		require.NoError(t, err)
+		assert.Contains(t, sql, "(rt1.value->>'founded_at')::timestamptz < $1")
+	})
+}
+
+func TestJsonbValue(t *testing.T) {
+	value, err := Jsonb{Obj: map[string]any{"status": "active"}}.Value()
+	require.NoError(t, err)
+	assert.Equal(t, `{"status":"active"}`, value)
+
+	_, err = Jsonb{Obj: func() {}}.Value()
+	assert.Error(t, err)
+}
+
 func TestVisitOr(t *testing.T) {
 	t.Run("or with eq", func(t *testing.T) {
 		compiler := NewPgQueryCompiler("", nil, nil)
@@ -611,6 +792,69 @@ func TestVisitIn(t *testing.T) {
 	})
 }
 
+func TestVisitInArrayThreshold(t *testing.T) {
+	values := []any{"active", "pending", "blocked"}
+	statusIn := func(values []any) domainquery.IQueryOperator {
+		return domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"profile": domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"status": domainquery.InOperator{Values: values},
+			}},
+		}}
+	}
+
+	t.Run("above threshold", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetInArrayThreshold(2)
+		sql, params, err := compiler.Compile(statusIn(values))
+		require.NoError(t, err)
+		assert.Equal(t, "value->'profile'->'status' IN (SELECT jsonb_array_elements($1))", sql)
+		assert.Equal(t, []any{encode(values)}, params)
+	})
+
+	t.Run("at threshold", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetInArrayThreshold(3)
+		sql, _, err := compiler.Compile(statusIn(values))
+		require.NoError(t, err)
+		assert.Equal(t, "(value @> $1 OR value @> $2 OR value @> $3)", sql)
+	})
+
+	t.Run("typed", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetInArrayThreshold(2)
+		compiler.SetFieldTypes(domainquery.FieldTypes{"age": domainquery.TypeHintNumeric})
+		sql, params, err := compiler.Compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"age": domainquery.InOperator{Values: []any{18, 21, 30}},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, "(value->>'age')::numeric IN (SELECT (jsonb_array_elements_text($1))::numeric)", sql)
+		assert.Equal(t, []any{encode([]any{18, 21, 30})}, params)
+	})
+
+	t.Run("objects and bare value keep containment", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetInArrayThreshold(2)
+		sql, _, err := compiler.Compile(statusIn([]any{"active", "pending", map[string]any{"code": 1}}))
+		require.NoError(t, err)
+		assert.Equal(t, "(value @> $1 OR value @> $2 OR value @> $3)", sql)
+
+		sql, _, err = compiler.Compile(domainquery.InOperator{Values: values})
+		require.NoError(t, err)
+		assert.Equal(t, "(value @> $1 OR value @> $2 OR value @> $3)", sql)
+	})
+
+	t.Run("compile cache binds array", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetInArrayThreshold(2)
+		compiler.SetCompileCache(NewCompileCache(0))
+		_, _, err := compiler.Compile(statusIn(values))
+		require.NoError(t, err)
+		_, params, err := compiler.Compile(statusIn([]any{"a", "b", "c"}))
+		require.NoError(t, err)
+		assert.Equal(t, []any{encode([]any{"a", "b", "c"})}, params)
+	})
+}
+
 func TestVisitAnd(t *testing.T) {
 	t.Run("and range", func(t *testing.T) {
 		compiler := NewPgQueryCompiler("", nil, nil)
@@ -1548,3 +1792,186 @@ func TestNestedCompositeDescend(t *testing.T) {
 		assert.Contains(t, sql, "@>")
 	})
 }
+
+func TestCompileCountAndExists(t *testing.T) {
+	query := domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+		"status": domainquery.EqOperator{Value: "active"},
+		"age":    domainquery.ComparisonOperator{Op: "$gt", Value: 18},
+	}}
+
+	t.Run("count", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		sql, params, err := compiler.CompileCount("users", query)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT count(*) FROM users WHERE value @> $1 AND value->'age' > $2", sql)
+		require.Len(t, params, 2)
+		assert.Equal(t, 18, params[1])
+	})
+
+	t.Run("count all", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		sql, params, err := compiler.CompileCount("users", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT count(*) FROM users", sql)
+		assert.Empty(t, params)
+	})
+
+	t.Run("exists", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		sql, params, err := compiler.CompileExists("users", query)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT EXISTS(SELECT 1 FROM users WHERE value @> $1 AND value->'age' > $2)", sql)
+		assert.Len(t, params, 2)
+	})
+
+	t.Run("exists with rel", func(t *testing.T) {
+		resolver := &StubRelationResolver{
+			relations: map[string]*RelationInfo{
+				"company_id": {Table: "companies", PkField: "value_id"},
+			},
+		}
+		compiler := NewPgQueryCompiler("", resolver, nil)
+		sql, params, err := compiler.CompileExists("users", domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"company_id": domainquery.RelOperator{Query: domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"name": domainquery.EqOperator{Value: "Acme"},
+			}}},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t,
+			"SELECT EXISTS(SELECT 1 FROM users WHERE EXISTS (SELECT 1 FROM companies rt1 WHERE rt1.value @> $1 AND rt1.value_id = value->'company_id'))",
+			sql,
+		)
+		assert.Len(t, params, 1)
+	})
+}
+
+func TestRemoteRelations(t *testing.T) {
+	resolver := &StubRelationResolver{
+		relations: map[string]*RelationInfo{
+			"customer_id": {Table: "customers", PkField: "value_id", Remote: true},
+		},
+	}
+	remoteRel := domainquery.RelOperator{Query: domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+		"tier": domainquery.EqOperator{Value: "gold"},
+	}}}
+
+	t.Run("conjunction keeps local predicates", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", resolver, nil)
+		sql, params, err := compiler.Compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"status":      domainquery.EqOperator{Value: "open"},
+			"customer_id": remoteRel,
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, "value @> $1", sql)
+		assert.Equal(t, []any{encode(map[string]any{"status": "open"})}, params)
+		assert.Equal(t, []string{"customer_id"}, compiler.NonPushablePaths())
+	})
+
+	t.Run("or and not are left out", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", resolver, nil)
+		sql, params, err := compiler.Compile(domainquery.AndOperator{Operands: []domainquery.IQueryOperator{
+			domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"total": domainquery.ComparisonOperator{Op: "$gt", Value: 10},
+			}},
+			domainquery.OrOperator{Operands: []domainquery.IQueryOperator{
+				domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+					"status": domainquery.EqOperator{Value: "open"},
+				}},
+				domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{"customer_id": remoteRel}},
+			}},
+			domainquery.NotOperator{Operand: domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"customer_id": remoteRel,
+			}}},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, "value->'total' > $1", sql)
+		assert.Equal(t, []any{10}, params)
+		assert.Equal(t, []string{"customer_id"}, compiler.NonPushablePaths())
+	})
+
+	t.Run("nested path", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", &DescendableStubRelationResolver{
+			children: map[string]IRelationResolver{"billing": resolver},
+		}, nil)
+		sql, _, err := compiler.Compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"billing": domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{"customer_id": remoteRel}},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, "", sql)
+		assert.Equal(t, []string{"billing.customer_id"}, compiler.NonPushablePaths())
+
+		_, _, err = compiler.Compile(domainquery.EqOperator{Value: 1})
+		require.NoError(t, err)
+		assert.Empty(t, compiler.NonPushablePaths())
+	})
+}
+
+func TestTypedPathExpr(t *testing.T) {
+	expr, ok := TypedPathExpr("value", "address.zip", domainquery.TypeHintNumeric)
+	require.True(t, ok)
+	assert.Equal(t, "(value->'address'->>'zip')::numeric", expr)
+
+	expr, ok = TypedPathExpr("value", "name", domainquery.TypeHintText)
+	require.True(t, ok)
+	assert.Equal(t, "value->>'name'", expr)
+
+	_, ok = TypedPathExpr("value", "items[*].sku", domainquery.TypeHintText)
+	assert.False(t, ok)
+}
+
+func TestCompilePanicRecovery(t *testing.T) {
+	compiler := NewPgQueryCompiler("", nil, nil)
+
+	_, _, err := compiler.Compile(domainquery.AndOperator{Operands: []domainquery.IQueryOperator{
+		domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"address": domainquery.EqOperator{Value: "Paris"},
+		}},
+		domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"address": domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"city": domainquery.EqOperator{Value: "Paris"},
+			}},
+		}},
+	}})
+
+	var panicErr *domainquery.QueryPanicError
+	require.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, "query.EqOperator", panicErr.Operator)
+	assert.Equal(t, "address.city", panicErr.Path)
+
+	sql, _, err := compiler.Compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+		"address": domainquery.EqOperator{Value: "Paris"},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "value @> $1", sql)
+}
+
+func TestCompileContext(t *testing.T) {
+	compiler := NewPgQueryCompiler("", nil, nil)
+	query := domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+		"status": domainquery.EqOperator{Value: "active"},
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := compiler.CompileContext(ctx, query)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	sql, _, err := compiler.Compile(query)
+	require.NoError(t, err)
+	assert.NotEmpty(t, sql)
+}
+
+func TestCompileTracer(t *testing.T) {
+	var events []domainquery.QueryEvent
+	compiler := NewPgQueryCompiler("", nil, nil)
+	compiler.SetTracer(domainquery.QueryTimer(func(ctx context.Context, event domainquery.QueryEvent, elapsed time.Duration, err error) {
+		require.NoError(t, err)
+		events = append(events, event)
+	}))
+
+	_, _, err := compiler.Compile(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+		"age": domainquery.ComparisonOperator{Op: "$gt", Value: 18},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []domainquery.QueryEvent{{Operation: domainquery.QueryOperationCompile, Shape: "{age:$gt}"}}, events)
+}
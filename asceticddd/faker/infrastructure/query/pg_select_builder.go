@@ -0,0 +1,131 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+// PgSelectBuilder builds a complete SELECT statement for QuerySpec:
+// projection, WHERE compiled by PgQueryCompiler, ORDER BY, LIMIT and OFFSET.
+// Parameters are numbered sequentially across all clauses.
+type PgSelectBuilder struct {
+	table    string
+	compiler *PgQueryCompiler
+}
+
+func NewPgSelectBuilder(table string, compiler *PgQueryCompiler) *PgSelectBuilder {
+	if compiler == nil {
+		compiler = NewPgQueryCompiler("", nil, nil)
+	}
+	return &PgSelectBuilder{
+		table:    table,
+		compiler: compiler,
+	}
+}
+
+func (b *PgSelectBuilder) Build(spec domainquery.QuerySpec) (string, []any, error) {
+	stmt, err := b.statement(spec)
+	if err != nil {
+		return "", nil, err
+	}
+	sql, params := stmt.render()
+	return sql, params, nil
+}
+
+func (b *PgSelectBuilder) statement(spec domainquery.QuerySpec) (*selectStatement, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	b.compiler.diagnostics.reset()
+	selectExpr, err := b.compiler.CompileProjection(spec.Projection)
+	if err != nil {
+		return nil, err
+	}
+	stmt := &selectStatement{
+		selectExpr:  selectExpr,
+		table:       b.table,
+		limit:       spec.Limit,
+		offset:      spec.Offset,
+		paramBinder: b.compiler.paramBinder,
+	}
+	if spec.Query != nil {
+		where, params, err := b.compiler.CompileContext(context.Background(), spec.Query)
+		if err != nil {
+			return nil, err
+		}
+		if where != "" {
+			stmt.where = append(stmt.where, where)
+			stmt.params = append(stmt.params, params...)
+		}
+	}
+	for _, clause := range spec.OrderBy {
+		keys := domainquery.SplitPath(clause.Path)
+		if err := b.compiler.diagnostics.checkIndexed(
+			b.compiler.table, b.compiler.indexedFields, keys, PathUsageSort,
+		); err != nil {
+			return nil, err
+		}
+		if err := b.compiler.diagnostics.reportPredicate(
+			b.compiler.table, b.compiler.indexedFields, keys, PathUsageSort, PredicateComparison, "",
+		); err != nil {
+			return nil, err
+		}
+		stmt.orderBy = append(stmt.orderBy, orderByExpr(pathExpr(b.compiler.targetValueExpr, keys), clause.IsDesc()))
+	}
+	return stmt, nil
+}
+
+func orderByExpr(expr string, desc bool) string {
+	if desc {
+		return fmt.Sprintf("%s %s", expr, domainquery.Desc)
+	}
+	return fmt.Sprintf("%s %s", expr, domainquery.Asc)
+}
+
+// selectStatement holds the clauses of a SELECT statement.
+// WHERE conditions are already bound in the order of params.
+type selectStatement struct {
+	selectExpr  string
+	table       string
+	where       []string
+	params      []any
+	orderBy     []string
+	limit       int
+	offset      int
+	paramBinder ParamBinder
+}
+
+// nextParam appends the param and returns its placeholder.
+func (s *selectStatement) nextParam(value any) string {
+	s.params = append(s.params, value)
+	return s.paramBinder.Placeholder(len(s.params))
+}
+
+func (s *selectStatement) render() (string, []any) {
+	var sql strings.Builder
+	sql.WriteString(fmt.Sprintf("SELECT %s FROM %s", s.selectExpr, s.table))
+	if len(s.where) == 1 {
+		sql.WriteString(" WHERE ")
+		sql.WriteString(s.where[0])
+	} else if len(s.where) > 1 {
+		sql.WriteString(" WHERE (")
+		sql.WriteString(strings.Join(s.where, ") AND ("))
+		sql.WriteString(")")
+	}
+	if len(s.orderBy) > 0 {
+		sql.WriteString(" ORDER BY ")
+		sql.WriteString(strings.Join(s.orderBy, ", "))
+	}
+	if s.limit > 0 {
+		sql.WriteString(" LIMIT ")
+		sql.WriteString(s.nextParam(s.limit))
+	}
+	if s.offset > 0 {
+		sql.WriteString(" OFFSET ")
+		sql.WriteString(s.nextParam(s.offset))
+	}
+	return sql.String(), s.params
+}
@@ -0,0 +1,86 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+func TestPgSelectBuilder(t *testing.T) {
+	t.Run("empty spec", func(t *testing.T) {
+		builder := NewPgSelectBuilder("users", nil)
+		sql, params, err := builder.Build(domainquery.QuerySpec{})
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT value FROM users", sql)
+		assert.Empty(t, params)
+	})
+
+	t.Run("where order limit offset", func(t *testing.T) {
+		builder := NewPgSelectBuilder("users", NewPgQueryCompiler("", nil, nil))
+		sql, params, err := builder.Build(domainquery.QuerySpec{
+			Query: domainquery.CompositeQuery{
+				Fields: map[string]domainquery.IQueryOperator{
+					"age": domainquery.ComparisonOperator{Op: "$gt", Value: 18},
+				},
+			},
+			OrderBy: []domainquery.OrderClause{
+				{Path: "address.city"},
+				{Path: "age", Direction: domainquery.Desc},
+			},
+			Limit:  10,
+			Offset: 20,
+		})
+		require.NoError(t, err)
+		assert.Equal(t,
+			"SELECT value FROM users WHERE value->'age' > $1 "+
+				"ORDER BY value->'address'->'city' ASC, value->'age' DESC LIMIT $2 OFFSET $3",
+			sql,
+		)
+		assert.Equal(t, []any{18, 10, 20}, params)
+	})
+
+	t.Run("offset without limit", func(t *testing.T) {
+		builder := NewPgSelectBuilder("users", nil)
+		sql, params, err := builder.Build(domainquery.QuerySpec{Offset: 5})
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT value FROM users OFFSET $1", sql)
+		assert.Equal(t, []any{5}, params)
+	})
+
+	t.Run("projection", func(t *testing.T) {
+		builder := NewPgSelectBuilder("users", nil)
+		sql, _, err := builder.Build(domainquery.QuerySpec{
+			Projection: domainquery.Projection{Include: []string{"name"}},
+			Limit:      1,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT jsonb_build_object('name', value->'name') FROM users LIMIT $1", sql)
+	})
+
+	t.Run("invalid spec", func(t *testing.T) {
+		builder := NewPgSelectBuilder("users", nil)
+		_, _, err := builder.Build(domainquery.QuerySpec{Limit: -1})
+		assert.Error(t, err)
+		_, _, err = builder.Build(domainquery.QuerySpec{
+			OrderBy: []domainquery.OrderClause{{Path: "age", Direction: "UP"}},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("sort on non-indexed path warns", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetIndexedFields("users", IndexedFields{"age": true})
+		warnings := collectWarnings(compiler)
+		builder := NewPgSelectBuilder("users", compiler)
+		_, _, err := builder.Build(domainquery.QuerySpec{
+			OrderBy: []domainquery.OrderClause{{Path: "age"}, {Path: "name"}},
+		})
+		require.NoError(t, err)
+		require.Len(t, *warnings, 1)
+		assert.Equal(t, "name", (*warnings)[0].Path)
+		assert.Equal(t, PathUsageSort, (*warnings)[0].Usage)
+	})
+}
@@ -0,0 +1,136 @@
+package query
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/signals"
+)
+
+type PathUsage string
+
+const (
+	PathUsageFilter PathUsage = "filter"
+	PathUsageSort   PathUsage = "sort"
+	PathUsageGroup  PathUsage = "group"
+)
+
+// QueryWarningEvent reports a potentially slow construct found during compilation.
+type QueryWarningEvent struct {
+	Table   string
+	Path    string
+	Usage   PathUsage
+	Message string
+}
+
+type PredicateKind string
+
+const (
+	// PredicateContainment is a jsonb containment (@>) on the value, served by a GIN index.
+	PredicateContainment PredicateKind = "containment"
+	// PredicateComparison compares the expression of a path, served by a btree index on the expression.
+	PredicateComparison PredicateKind = "comparison"
+)
+
+// PredicateEvent reports an indexable predicate of the compiled query, see IndexAdvisor.
+// Hint is the type hint of the path the comparison is typed with, empty for jsonb comparison.
+type PredicateEvent struct {
+	Table   string
+	Path    string
+	Usage   PathUsage
+	Kind    PredicateKind
+	Hint    domainquery.TypeHint
+	Indexed bool
+}
+
+// IndexedFields marks jsonb paths of a document table as covered by an index.
+// Paths are dot-separated; elements of arrays are addressed with "[*]",
+// e.g. "items[*].sku". A nil IndexedFields means the metadata is unknown
+// and no warnings are emitted for the table.
+type IndexedFields map[string]bool
+
+func (f IndexedFields) IsIndexed(path string) bool {
+	return f[path]
+}
+
+// queryDiagnostics is shared between a compiler and all its sub-compilers.
+type queryDiagnostics struct {
+	onWarning   signals.Signal[QueryWarningEvent]
+	onPredicate signals.Signal[PredicateEvent]
+	reported    map[QueryWarningEvent]struct{}
+	nonPushable []string
+}
+
+func newQueryDiagnostics() *queryDiagnostics {
+	return &queryDiagnostics{
+		onWarning:   signals.NewSignal[QueryWarningEvent](),
+		onPredicate: signals.NewSignal[PredicateEvent](),
+		reported:    map[QueryWarningEvent]struct{}{},
+	}
+}
+
+func (d *queryDiagnostics) reset() {
+	d.reported = map[QueryWarningEvent]struct{}{}
+	d.nonPushable = nil
+}
+
+func (d *queryDiagnostics) reportNonPushable(path string) {
+	if !slices.Contains(d.nonPushable, path) {
+		d.nonPushable = append(d.nonPushable, path)
+	}
+}
+
+func (d *queryDiagnostics) checkIndexed(table string, indexedFields IndexedFields, path []string, usage PathUsage) error {
+	if indexedFields == nil || len(path) == 0 {
+		return nil
+	}
+	p := joinPath(path)
+	if indexedFields.IsIndexed(p) {
+		return nil
+	}
+	event := QueryWarningEvent{
+		Table:   table,
+		Path:    p,
+		Usage:   usage,
+		Message: fmt.Sprintf("%s on non-indexed path %q", usage, p),
+	}
+	if _, ok := d.reported[event]; ok {
+		return nil
+	}
+	d.reported[event] = struct{}{}
+	return d.onWarning.Notify(event)
+}
+
+// reportPredicate notifies about predicates on paths outside of arrays,
+// whose elements are not indexable by expression.
+func (d *queryDiagnostics) reportPredicate(
+	table string, indexedFields IndexedFields, path []string, usage PathUsage, kind PredicateKind, hint domainquery.TypeHint,
+) error {
+	if slices.Contains(path, arrayElementsPathKey) {
+		return nil
+	}
+	p := joinPath(path)
+	return d.onPredicate.Notify(PredicateEvent{
+		Table:   table,
+		Path:    p,
+		Usage:   usage,
+		Kind:    kind,
+		Hint:    hint,
+		Indexed: indexedFields.IsIndexed(p),
+	})
+}
+
+func joinPath(path []string) string {
+	var b strings.Builder
+	for i, key := range path {
+		if i > 0 && key != arrayElementsPathKey {
+			b.WriteByte('.')
+		}
+		b.WriteString(key)
+	}
+	return b.String()
+}
+
+const arrayElementsPathKey = "[*]"
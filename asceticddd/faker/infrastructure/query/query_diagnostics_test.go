@@ -0,0 +1,152 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+)
+
+func collectWarnings(compiler *PgQueryCompiler) *[]QueryWarningEvent {
+	var warnings []QueryWarningEvent
+	compiler.OnWarning().Attach(func(e QueryWarningEvent) error {
+		warnings = append(warnings, e)
+		return nil
+	})
+	return &warnings
+}
+
+func TestQueryCostWarnings(t *testing.T) {
+	t.Run("no metadata no warnings", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		warnings := collectWarnings(compiler)
+		_, _, err := compiler.Compile(domainquery.CompositeQuery{
+			Fields: map[string]domainquery.IQueryOperator{
+				"age": domainquery.ComparisonOperator{Op: "$gt", Value: 18},
+			},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, *warnings)
+	})
+
+	t.Run("indexed path", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetIndexedFields("users", IndexedFields{"age": true})
+		warnings := collectWarnings(compiler)
+		_, _, err := compiler.Compile(domainquery.CompositeQuery{
+			Fields: map[string]domainquery.IQueryOperator{
+				"age": domainquery.ComparisonOperator{Op: "$gt", Value: 18},
+			},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, *warnings)
+	})
+
+	t.Run("non-indexed nested path", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetIndexedFields("users", IndexedFields{"age": true})
+		warnings := collectWarnings(compiler)
+		_, _, err := compiler.Compile(domainquery.CompositeQuery{
+			Fields: map[string]domainquery.IQueryOperator{
+				"address": domainquery.CompositeQuery{
+					Fields: map[string]domainquery.IQueryOperator{
+						"city": domainquery.EqOperator{Value: "Moscow"},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []QueryWarningEvent{{
+			Table:   "users",
+			Path:    "address.city",
+			Usage:   PathUsageFilter,
+			Message: `filter on non-indexed path "address.city"`,
+		}}, *warnings)
+	})
+
+	t.Run("reported once per compilation", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetIndexedFields("users", IndexedFields{})
+		warnings := collectWarnings(compiler)
+		query := domainquery.CompositeQuery{
+			Fields: map[string]domainquery.IQueryOperator{
+				"age": domainquery.OrOperator{Operands: []domainquery.IQueryOperator{
+					domainquery.ComparisonOperator{Op: "$lt", Value: 18},
+					domainquery.ComparisonOperator{Op: "$gt", Value: 65},
+				}},
+			},
+		}
+		_, _, err := compiler.Compile(query)
+		require.NoError(t, err)
+		assert.Len(t, *warnings, 1)
+		_, _, err = compiler.Compile(query)
+		require.NoError(t, err)
+		assert.Len(t, *warnings, 2)
+	})
+
+	t.Run("array element path", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetIndexedFields("orders", IndexedFields{"items": true})
+		warnings := collectWarnings(compiler)
+		_, _, err := compiler.Compile(domainquery.CompositeQuery{
+			Fields: map[string]domainquery.IQueryOperator{
+				"items": domainquery.AnyElementOperator{
+					Query: domainquery.CompositeQuery{
+						Fields: map[string]domainquery.IQueryOperator{
+							"price": domainquery.ComparisonOperator{Op: "$gt", Value: 100},
+						},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, *warnings, 1)
+		assert.Equal(t, "items[*].price", (*warnings)[0].Path)
+	})
+
+	t.Run("related table uses relation metadata", func(t *testing.T) {
+		resolver := &StubRelationResolver{
+			relations: map[string]*RelationInfo{
+				"company_id": {
+					Table:         "companies",
+					PkField:       "value_id",
+					IndexedFields: IndexedFields{"name": true},
+				},
+			},
+		}
+		compiler := NewPgQueryCompiler("", resolver, nil)
+		warnings := collectWarnings(compiler)
+		_, _, err := compiler.Compile(domainquery.CompositeQuery{
+			Fields: map[string]domainquery.IQueryOperator{
+				"company_id": domainquery.RelOperator{Query: domainquery.CompositeQuery{
+					Fields: map[string]domainquery.IQueryOperator{
+						"name": domainquery.EqOperator{Value: "Acme"},
+						"size": domainquery.ComparisonOperator{Op: "$gt", Value: 10},
+					},
+				}},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, *warnings, 1)
+		assert.Equal(t, "companies", (*warnings)[0].Table)
+		assert.Equal(t, "size", (*warnings)[0].Path)
+	})
+
+	t.Run("observer error aborts compilation", func(t *testing.T) {
+		compiler := NewPgQueryCompiler("", nil, nil)
+		compiler.SetIndexedFields("users", IndexedFields{})
+		errSlow := errors.New("slow query")
+		compiler.OnWarning().Attach(func(e QueryWarningEvent) error {
+			return errSlow
+		})
+		_, _, err := compiler.Compile(domainquery.CompositeQuery{
+			Fields: map[string]domainquery.IQueryOperator{
+				"age": domainquery.ComparisonOperator{Op: "$gt", Value: 18},
+			},
+		})
+		assert.ErrorIs(t, err, errSlow)
+	})
+}
@@ -0,0 +1,295 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+var ErrCircuitOpen = errors.New("circuit open")
+
+// IRemoteObjectFetcher loads foreign states of another bounded context, e.g. over HTTP or gRPC.
+// FetchMany returns the states keyed by the given fk values, missing keys are omitted.
+type IRemoteObjectFetcher interface {
+	FetchMany(ctx context.Context, fkValues []any) (map[any]map[string]any, error)
+}
+
+// RemoteObjectResolver resolves a relation to another bounded context through IRemoteObjectFetcher.
+// Fetched states, missing ones included, are cached for cacheTtl across sessions,
+// since they do not belong to the local transaction.
+// After failureThreshold consecutive failures the circuit opens and calls fail with ErrCircuitOpen
+// for openTimeout, then one trial call decides whether it closes again.
+// Remote states have no nested relations.
+type RemoteObjectResolver struct {
+	fetcher          IRemoteObjectFetcher
+	cacheTtl         time.Duration
+	failureThreshold int
+	openTimeout      time.Duration
+	now              func() time.Time
+
+	mu        sync.Mutex
+	cache     map[string]remoteCacheEntry
+	failures  int
+	openUntil time.Time
+}
+
+type remoteCacheEntry struct {
+	state   map[string]any
+	expires time.Time
+}
+
+func NewRemoteObjectResolver(fetcher IRemoteObjectFetcher) *RemoteObjectResolver {
+	return &RemoteObjectResolver{
+		fetcher:          fetcher,
+		cacheTtl:         time.Minute,
+		failureThreshold: 5,
+		openTimeout:      30 * time.Second,
+		now:              time.Now,
+		cache:            map[string]remoteCacheEntry{},
+	}
+}
+
+// SetCacheTtl sets how long fetched states are cached, one minute by default. Zero disables caching.
+func (r *RemoteObjectResolver) SetCacheTtl(cacheTtl time.Duration) {
+	r.cacheTtl = cacheTtl
+}
+
+// SetCircuitBreaker sets the number of consecutive failures which opens the circuit
+// and the time it stays open, 5 and 30 seconds by default.
+func (r *RemoteObjectResolver) SetCircuitBreaker(failureThreshold int, openTimeout time.Duration) {
+	r.failureThreshold = failureThreshold
+	r.openTimeout = openTimeout
+}
+
+func (r *RemoteObjectResolver) Resolve(s session.Session, field *string, fkValue any) (map[string]any, domainquery.IObjectResolver, error) {
+	return r.ResolveContext(s.Context(), s, field, fkValue)
+}
+
+// ResolveContext fetches the state within the context of the evaluation, see domainquery.IContextObjectResolver.
+func (r *RemoteObjectResolver) ResolveContext(
+	ctx context.Context,
+	s session.Session,
+	field *string,
+	fkValue any,
+) (map[string]any, domainquery.IObjectResolver, error) {
+	states, err := r.resolveMany(ctx, field, []any{fkValue})
+	if err != nil {
+		return nil, nil, err
+	}
+	return states[fkValue], nil, nil
+}
+
+func (r *RemoteObjectResolver) ResolveMany(s session.Session, field *string, fkValues []any) (map[any]map[string]any, domainquery.IObjectResolver, error) {
+	states, err := r.resolveMany(s.Context(), field, fkValues)
+	return states, nil, err
+}
+
+func (r *RemoteObjectResolver) resolveMany(ctx context.Context, field *string, fkValues []any) (map[any]map[string]any, error) {
+	states := make(map[any]map[string]any, len(fkValues))
+	keys := make([]string, len(fkValues))
+	missing := map[string]any{}
+	now := r.now()
+
+	r.mu.Lock()
+	for i, fkValue := range fkValues {
+		key, err := json.Marshal(fkValue)
+		if err != nil {
+			r.mu.Unlock()
+			return nil, err
+		}
+		keys[i] = string(key)
+		if entry, ok := r.cache[keys[i]]; ok && now.Before(entry.expires) {
+			if entry.state != nil {
+				states[fkValue] = entry.state
+			}
+		} else if _, ok := missing[keys[i]]; !ok {
+			missing[keys[i]] = fkValue
+		}
+	}
+	open := now.Before(r.openUntil)
+	r.mu.Unlock()
+
+	if len(missing) == 0 {
+		return states, nil
+	}
+	if open {
+		return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, relationName(field))
+	}
+	// A canceled request is not a failure of the remote service, so it does not trip the circuit breaker.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	requested := make([]any, 0, len(missing))
+	for _, fkValue := range missing {
+		requested = append(requested, fkValue)
+	}
+	fetched, err := r.fetcher.FetchMany(ctx, requested)
+	r.record(err)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", relationName(field), err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	expires := r.now().Add(r.cacheTtl)
+	for key, fkValue := range missing {
+		if r.cacheTtl > 0 {
+			r.cache[key] = remoteCacheEntry{state: fetched[fkValue], expires: expires}
+		}
+	}
+	for i, fkValue := range fkValues {
+		if requestedValue, ok := missing[keys[i]]; ok {
+			if state := fetched[requestedValue]; state != nil {
+				states[fkValue] = state
+			}
+		}
+	}
+	return states, nil
+}
+
+func (r *RemoteObjectResolver) Descend(field string) domainquery.IObjectResolver {
+	return nil
+}
+
+// IsConcurrencySafe reports true, the resolver does not use the session, see domainquery.IConcurrentObjectResolver.
+func (r *RemoteObjectResolver) IsConcurrencySafe() bool {
+	return true
+}
+
+// record counts consecutive failures and opens the circuit at failureThreshold.
+func (r *RemoteObjectResolver) record(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil {
+		r.failures = 0
+		return
+	}
+	r.failures++
+	if r.failureThreshold > 0 && r.failures >= r.failureThreshold {
+		r.openUntil = r.now().Add(r.openTimeout)
+		r.failures = 0
+	}
+}
+
+func relationName(field *string) string {
+	if field == nil {
+		return "relation"
+	}
+	return *field
+}
+
+// RoutingObjectResolver resolves the registered relation paths with their own resolvers,
+// e.g. RemoteObjectResolver for relations to other bounded contexts, and other relations with the local one.
+// Paths are dot-separated field names of composite queries, e.g. "order.customer_id".
+// Mark remote relations with RelationInfo.Remote, so PgQueryCompiler does not push them down to SQL.
+type RoutingObjectResolver struct {
+	local  domainquery.IObjectResolver
+	routes map[string]domainquery.IObjectResolver
+}
+
+// NewRoutingObjectResolver creates the router with the resolver of local relations, which may be nil.
+func NewRoutingObjectResolver(local domainquery.IObjectResolver) *RoutingObjectResolver {
+	return &RoutingObjectResolver{local: local, routes: map[string]domainquery.IObjectResolver{}}
+}
+
+// Register routes the relation path to the resolver.
+func (r *RoutingObjectResolver) Register(path string, resolver domainquery.IObjectResolver) {
+	r.routes[path] = resolver
+}
+
+func (r *RoutingObjectResolver) Resolve(s session.Session, field *string, fkValue any) (map[string]any, domainquery.IObjectResolver, error) {
+	return r.ResolveContext(s.Context(), s, field, fkValue)
+}
+
+// ResolveContext passes the context to the routed resolver, if it implements domainquery.IContextObjectResolver.
+func (r *RoutingObjectResolver) ResolveContext(
+	ctx context.Context,
+	s session.Session,
+	field *string,
+	fkValue any,
+) (map[string]any, domainquery.IObjectResolver, error) {
+	resolver := r.route(field)
+	if resolver == nil {
+		return nil, nil, nil
+	}
+	if contextResolver, ok := resolver.(domainquery.IContextObjectResolver); ok {
+		return contextResolver.ResolveContext(ctx, s, field, fkValue)
+	}
+	return resolver.Resolve(s, field, fkValue)
+}
+
+func (r *RoutingObjectResolver) ResolveMany(s session.Session, field *string, fkValues []any) (map[any]map[string]any, domainquery.IObjectResolver, error) {
+	resolver := r.route(field)
+	if resolver == nil {
+		return nil, nil, nil
+	}
+	if batch, ok := resolver.(domainquery.IBatchObjectResolver); ok {
+		return batch.ResolveMany(s, field, fkValues)
+	}
+	states := make(map[any]map[string]any, len(fkValues))
+	var nestedResolver domainquery.IObjectResolver
+	for _, fkValue := range fkValues {
+		state, nested, err := resolver.Resolve(s, field, fkValue)
+		if err != nil {
+			return nil, nil, err
+		}
+		if state != nil {
+			states[fkValue] = state
+			nestedResolver = nested
+		}
+	}
+	return states, nestedResolver, nil
+}
+
+func (r *RoutingObjectResolver) Descend(field string) domainquery.IObjectResolver {
+	var local domainquery.IObjectResolver
+	if r.local != nil {
+		local = r.local.Descend(field)
+	}
+	routes := map[string]domainquery.IObjectResolver{}
+	prefix := field + "."
+	for path, resolver := range r.routes {
+		if rest, ok := strings.CutPrefix(path, prefix); ok {
+			routes[rest] = resolver
+		}
+	}
+	if len(routes) == 0 {
+		return local
+	}
+	return &RoutingObjectResolver{local: local, routes: routes}
+}
+
+// IsConcurrencySafe reports whether the local and all routed resolvers are safe for concurrent use,
+// see domainquery.IConcurrentObjectResolver.
+func (r *RoutingObjectResolver) IsConcurrencySafe() bool {
+	if r.local != nil && !isConcurrencySafe(r.local) {
+		return false
+	}
+	for _, resolver := range r.routes {
+		if !isConcurrencySafe(resolver) {
+			return false
+		}
+	}
+	return true
+}
+
+func isConcurrencySafe(resolver domainquery.IObjectResolver) bool {
+	r, ok := resolver.(domainquery.IConcurrentObjectResolver)
+	return ok && r.IsConcurrencySafe()
+}
+
+func (r *RoutingObjectResolver) route(field *string) domainquery.IObjectResolver {
+	if field != nil {
+		if resolver, ok := r.routes[*field]; ok {
+			return resolver
+		}
+	}
+	return r.local
+}
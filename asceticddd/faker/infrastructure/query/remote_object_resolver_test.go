@@ -0,0 +1,145 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/utils/testutils"
+)
+
+type stubRemoteFetcher struct {
+	states map[any]map[string]any
+	err    error
+	calls  [][]any
+	ctx    context.Context
+}
+
+func (f *stubRemoteFetcher) FetchMany(ctx context.Context, fkValues []any) (map[any]map[string]any, error) {
+	f.calls = append(f.calls, fkValues)
+	f.ctx = ctx
+	if f.err != nil {
+		return nil, f.err
+	}
+	states := map[any]map[string]any{}
+	for _, fkValue := range fkValues {
+		if state, ok := f.states[fkValue]; ok {
+			states[fkValue] = state
+		}
+	}
+	return states, nil
+}
+
+func TestRemoteObjectResolver(t *testing.T) {
+	field := "customer_id"
+	s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+
+	t.Run("caches states and misses", func(t *testing.T) {
+		fetcher := &stubRemoteFetcher{states: map[any]map[string]any{1: {"tier": "gold"}}}
+		resolver := NewRemoteObjectResolver(fetcher)
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		resolver.now = func() time.Time { return now }
+
+		state, nested, err := resolver.Resolve(s, &field, 1)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"tier": "gold"}, state)
+		assert.Nil(t, nested)
+		state, _, err = resolver.Resolve(s, &field, 2)
+		require.NoError(t, err)
+		assert.Nil(t, state)
+
+		states, _, err := resolver.ResolveMany(s, &field, []any{1, 2})
+		require.NoError(t, err)
+		assert.Equal(t, map[any]map[string]any{1: {"tier": "gold"}}, states)
+		assert.Len(t, fetcher.calls, 2)
+
+		now = now.Add(2 * time.Minute)
+		_, _, err = resolver.Resolve(s, &field, 1)
+		require.NoError(t, err)
+		assert.Len(t, fetcher.calls, 3, "expired entries are fetched again")
+	})
+
+	t.Run("circuit breaker", func(t *testing.T) {
+		fetcher := &stubRemoteFetcher{err: errors.New("unavailable")}
+		resolver := NewRemoteObjectResolver(fetcher)
+		resolver.SetCircuitBreaker(2, time.Second)
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		resolver.now = func() time.Time { return now }
+
+		for range 2 {
+			_, _, err := resolver.Resolve(s, &field, 1)
+			assert.ErrorIs(t, err, fetcher.err)
+		}
+		_, _, err := resolver.Resolve(s, &field, 1)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.Len(t, fetcher.calls, 2)
+
+		now = now.Add(2 * time.Second)
+		fetcher.err = nil
+		fetcher.states = map[any]map[string]any{1: {"tier": "gold"}}
+		state, _, err := resolver.Resolve(s, &field, 1)
+		require.NoError(t, err)
+		assert.NotNil(t, state)
+	})
+
+	t.Run("context", func(t *testing.T) {
+		fetcher := &stubRemoteFetcher{states: map[any]map[string]any{1: {"tier": "gold"}}}
+		resolver := NewRemoteObjectResolver(fetcher)
+		ctx, cancel := context.WithCancel(context.WithValue(context.Background(), fetcher, "request"))
+
+		_, _, err := resolver.ResolveContext(ctx, s, &field, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "request", fetcher.ctx.Value(fetcher))
+
+		cancel()
+		_, _, err = resolver.ResolveContext(ctx, s, &field, 2)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Len(t, fetcher.calls, 1)
+	})
+}
+
+func TestRoutingObjectResolver(t *testing.T) {
+	s := testutils.NewDbSessionStub(testutils.NewRowsStub(
+		[]any{[]byte(`"c1"`), []byte(`{"name": "Acme"}`)},
+	))
+	remote := NewRemoteObjectResolver(&stubRemoteFetcher{states: map[any]map[string]any{
+		"u1": {"tier": "gold"},
+	}})
+	router := NewRoutingObjectResolver(NewPgObjectResolver(&DescendableStubRelationResolver{
+		relations: map[string]*RelationInfo{
+			"company_id":  {Table: "companies", PkField: "value_id"},
+			"customer_id": {Table: "customers", PkField: "value_id", Remote: true},
+		},
+		children: map[string]IRelationResolver{"billing": &StubRelationResolver{}},
+	}))
+	router.Register("billing.customer_id", remote)
+
+	walker := domainquery.NewEvaluateWalker(router)
+	state := map[string]any{"company_id": "c1", "billing": map[string]any{"customer_id": "u1"}}
+	matched, err := walker.Evaluate(s, domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+		"company_id": domainquery.RelOperator{Query: domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"name": domainquery.EqOperator{Value: "Acme"},
+		}}},
+		"billing": domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"customer_id": domainquery.RelOperator{Query: domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"tier": domainquery.EqOperator{Value: "gold"},
+			}}},
+		}},
+	}}, state)
+	require.NoError(t, err)
+	assert.True(t, matched)
+
+	customerField := "customer_id"
+	_, _, err = router.Resolve(s, &customerField, "u1")
+	assert.Error(t, err, "remote relations are not resolved from the local database")
+
+	assert.False(t, router.IsConcurrencySafe(), "pg resolver queries the connection of the session")
+	remoteOnly := NewRoutingObjectResolver(nil)
+	remoteOnly.Register("customer_id", remote)
+	assert.True(t, remoteOnly.IsConcurrencySafe())
+}
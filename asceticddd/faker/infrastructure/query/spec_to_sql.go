@@ -0,0 +1,363 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+	specinfra "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/infrastructure"
+)
+
+var specSqlOps = map[operators.Operator]string{
+	operators.OperatorEq:  "=",
+	operators.OperatorGt:  ">",
+	operators.OperatorGte: ">=",
+	operators.OperatorLt:  "<",
+	operators.OperatorLte: "<=",
+}
+
+// Precedence of the compiled expressions, to parenthesize operands of lower precedence.
+const (
+	specPrecedenceOr = iota + 1
+	specPrecedenceAnd
+	specPrecedenceAtom
+)
+
+// SpecToSql compiles the specification AST to the WHERE clause over the jsonb "value" column.
+func SpecToSql(node spec.Visitable) (string, []any, error) {
+	v := NewSpecToSqlVisitor("")
+	if err := node.Accept(v); err != nil {
+		return "", nil, err
+	}
+	return v.Result()
+}
+
+// SpecToDialectSql compiles the specification AST to the WHERE clause over the JSON "value" column
+// in the SQL syntax of the dialect.
+func SpecToDialectSql(node spec.Visitable, dialect Dialect) (string, []any, error) {
+	v := NewSpecToSqlVisitor("")
+	v.SetDialect(dialect)
+	if err := node.Accept(v); err != nil {
+		return "", nil, err
+	}
+	return v.Result()
+}
+
+// SpecToSqlVisitor compiles the specification AST to SQL over a jsonb document column,
+// without conversion to IQueryOperator, so comparisons of two fields are supported as well:
+//
+//	And(Equal(Field(GlobalScope(), "status"), Value("active")),
+//		Wildcard(Object(GlobalScope(), "items"), GreaterThan(Field(Item(), "price"), Value(10))))
+//
+// becomes
+//
+//	value->'status' = $1 AND EXISTS (SELECT 1 FROM jsonb_array_elements(value->'items') AS rt1
+//		WHERE rt1->'price' > $2)
+//
+// Fields and values are compared as jsonb, so values are passed as Jsonb params.
+// Comparisons with nil and IS NULL test for a missing key, as PgQueryCompiler does.
+// Arithmetic is ErrUnsupportedConversion.
+// The SQL is of PostgreSQL unless the dialect is set, see SetDialect.
+type SpecToSqlVisitor struct {
+	targetValueExpr string
+	items           []string
+	aliasSeq        int
+	sql             string
+	precedence      int
+	params          []any
+	paramBinder     ParamBinder
+	dialect         Dialect
+}
+
+func NewSpecToSqlVisitor(targetValueExpr string) *SpecToSqlVisitor {
+	if targetValueExpr == "" {
+		targetValueExpr = "value"
+	}
+	return &SpecToSqlVisitor{
+		targetValueExpr: targetValueExpr,
+		paramBinder:     DollarParamBinder{},
+		dialect:         PostgresDialect{},
+	}
+}
+
+// SetDialect sets the SQL syntax of the database, and the placeholder style of its ParamBinder.
+func (v *SpecToSqlVisitor) SetDialect(dialect Dialect) {
+	v.dialect = dialect
+	v.paramBinder = dialect.ParamBinder()
+}
+
+// SetParamBinder sets the placeholder style of compiled SQL, $n by default.
+func (v *SpecToSqlVisitor) SetParamBinder(paramBinder ParamBinder) {
+	v.paramBinder = paramBinder
+}
+
+// Result returns the SQL of the last visited node and the params collected so far.
+func (v *SpecToSqlVisitor) Result() (string, []any, error) {
+	if v.sql == "" {
+		return "", nil, fmt.Errorf("%w: nothing to compile", domainquery.ErrUnsupportedConversion)
+	}
+	return bindParamMarkers(v.sql, v.paramBinder), v.params, nil
+}
+
+func (v *SpecToSqlVisitor) VisitGlobalScope(n spec.GlobalScopeNode) error {
+	return fmt.Errorf("%w: %T as predicate", domainquery.ErrUnsupportedConversion, n)
+}
+
+func (v *SpecToSqlVisitor) VisitObject(n spec.ObjectNode) error {
+	return fmt.Errorf("%w: %T as predicate", domainquery.ErrUnsupportedConversion, n)
+}
+
+// VisitCollection compiles the wildcard to EXISTS over the elements of the collection,
+// Item() of the predicate refers to the element.
+func (v *SpecToSqlVisitor) VisitCollection(n spec.CollectionNode) error {
+	if _, ok := n.Parent().(spec.ObjectNode); !ok {
+		return fmt.Errorf("%w: wildcard without collection", domainquery.ErrUnsupportedConversion)
+	}
+	if !n.IsWildcard() {
+		return fmt.Errorf("%w: selector [%s] of collection", domainquery.ErrUnsupportedConversion, n.Name())
+	}
+	collection, err := v.objectExpr(n.Parent())
+	if err != nil {
+		return err
+	}
+	v.aliasSeq++
+	from, element := v.dialect.JSONArrayElements(collection, fmt.Sprintf("rt%d", v.aliasSeq))
+	v.items = append(v.items, element)
+	predicate, err := v.operand(n.Predicate(), specPrecedenceOr)
+	v.items = v.items[:len(v.items)-1]
+	if err != nil {
+		return err
+	}
+	v.emit(fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE %s)", from, predicate), specPrecedenceAtom)
+	return nil
+}
+
+func (v *SpecToSqlVisitor) VisitItem(n spec.ItemNode) error {
+	return fmt.Errorf("%w: %T as predicate", domainquery.ErrUnsupportedConversion, n)
+}
+
+// VisitField compiles the field to its jsonb path. Visited as a predicate,
+// i.e. not as an operand of a comparison, the field is a boolean one.
+func (v *SpecToSqlVisitor) VisitField(n spec.FieldNode) error {
+	expr, err := v.fieldExpr(n)
+	if err != nil {
+		return err
+	}
+	v.params = append(v.params, encode(true))
+	v.emit(fmt.Sprintf("%s = %s", expr, v.dialect.JSONParam()), specPrecedenceAtom)
+	return nil
+}
+
+func (v *SpecToSqlVisitor) VisitValue(n spec.ValueNode) error {
+	return fmt.Errorf("%w: %T as predicate", domainquery.ErrUnsupportedConversion, n)
+}
+
+func (v *SpecToSqlVisitor) VisitPrefix(n spec.PrefixNode) error {
+	if n.Operator() != operators.OperatorNot {
+		return fmt.Errorf("%w: prefix operator %s", domainquery.ErrUnsupportedConversion, n.Operator())
+	}
+	if err := n.Operand().Accept(v); err != nil {
+		return err
+	}
+	v.emit(fmt.Sprintf("NOT (%s)", v.sql), specPrecedenceAtom)
+	return nil
+}
+
+func (v *SpecToSqlVisitor) VisitPostfix(n spec.PostfixNode) error {
+	var sqlOp string
+	switch n.Operator() {
+	case operators.OperatorIsNull:
+		sqlOp = "IS NULL"
+	case operators.OperatorIsNotNull:
+		sqlOp = "IS NOT NULL"
+	default:
+		return fmt.Errorf("%w: postfix operator %s", domainquery.ErrUnsupportedConversion, n.Operator())
+	}
+	operand, err := v.valueOperand(n.Operand())
+	if err != nil {
+		return err
+	}
+	v.emit(fmt.Sprintf("%s %s", operand, sqlOp), specPrecedenceAtom)
+	return nil
+}
+
+func (v *SpecToSqlVisitor) VisitInfix(n spec.InfixNode) error {
+	switch n.Operator() {
+	case operators.OperatorAnd, operators.OperatorOr:
+		return v.visitLogical(n)
+	case operators.OperatorIs:
+		if value, ok := n.Right().(spec.ValueNode); ok && value.Value() == nil {
+			return v.VisitPostfix(spec.IsNull(n.Left()))
+		}
+		return fmt.Errorf("%w: IS with a value other than null", domainquery.ErrUnsupportedConversion)
+	case operators.OperatorEq, operators.OperatorNe:
+		for _, side := range [][2]spec.Visitable{{n.Left(), n.Right()}, {n.Right(), n.Left()}} {
+			if value, ok := side[1].(spec.ValueNode); ok && value.Value() == nil {
+				if n.Operator() == operators.OperatorEq {
+					return v.VisitPostfix(spec.IsNull(side[0]))
+				}
+				return v.VisitPostfix(spec.IsNotNull(side[0]))
+			}
+		}
+	}
+
+	left, err := v.valueOperand(n.Left())
+	if err != nil {
+		return err
+	}
+	if n.Operator() == operators.OperatorIn || n.Operator() == operators.OperatorNotIn {
+		return v.visitIn(n, left)
+	}
+	right, err := v.valueOperand(n.Right())
+	if err != nil {
+		return err
+	}
+	if n.Operator() == operators.OperatorNe {
+		// A missing key differs from any value, as with $ne of PgQueryCompiler.
+		v.emit(v.dialect.DistinctFrom(left, right), specPrecedenceAtom)
+		return nil
+	}
+	sqlOp, ok := specSqlOps[n.Operator()]
+	if !ok {
+		return fmt.Errorf("%w: infix operator %s", domainquery.ErrUnsupportedConversion, n.Operator())
+	}
+	v.emit(fmt.Sprintf("%s %s %s", left, sqlOp, right), specPrecedenceAtom)
+	return nil
+}
+
+// visitIn compiles membership in the slice value to the comparison with the elements of a single jsonb array param.
+// A missing key is not in any list, so it matches NOT IN, as with $ne of PgQueryCompiler.
+func (v *SpecToSqlVisitor) visitIn(n spec.InfixNode, left string) error {
+	value, ok := n.Right().(spec.ValueNode)
+	if !ok {
+		return fmt.Errorf("%w: %s of %T", domainquery.ErrUnsupportedConversion, n.Operator(), n.Right())
+	}
+	v.params = append(v.params, encode(value.Value()))
+	in := v.dialect.JSONIn(left)
+	if n.Operator() == operators.OperatorNotIn {
+		in = fmt.Sprintf("NOT coalesce(%s, %s)", in, v.dialect.BoolLiteral(false))
+	}
+	v.emit(in, specPrecedenceAtom)
+	return nil
+}
+
+// VisitFunction compiles the string function to LIKE or to the regular expression match of the text of the field,
+// see specinfra.FunctionPattern. Unlike evaluation, a field that is not a string is matched by its jsonb text, e.g. 42.
+// Between compiles to the comparisons of its bounds.
+func (v *SpecToSqlVisitor) VisitFunction(n spec.FunctionNode) error {
+	if n.Name() == spec.FunctionBetween {
+		comparison, err := spec.BetweenComparison(n)
+		if err != nil {
+			return fmt.Errorf("%w: %w", domainquery.ErrUnsupportedConversion, err)
+		}
+		return comparison.Accept(v)
+	}
+	args := n.Args()
+	if len(args) != 2 {
+		return fmt.Errorf("%w: function %s of %d arguments", domainquery.ErrUnsupportedConversion, n.Name(), len(args))
+	}
+	pattern, ok := args[1].(spec.ValueNode)
+	if !ok {
+		return fmt.Errorf("%w: function %s with pattern %T", domainquery.ErrUnsupportedConversion, n.Name(), args[1])
+	}
+	sqlOp, param, err := specinfra.FunctionPattern(n.Name(), pattern.Value())
+	if err != nil {
+		return fmt.Errorf("%w: %w", domainquery.ErrUnsupportedConversion, err)
+	}
+	value, err := v.valueOperand(args[0])
+	if err != nil {
+		return err
+	}
+	v.params = append(v.params, param)
+	operator, clause := v.dialect.PatternMatch(sqlOp)
+	v.emit(fmt.Sprintf("%s %s ?%s", v.dialect.JSONText(value), operator, clause), specPrecedenceAtom)
+	return nil
+}
+
+func (v *SpecToSqlVisitor) VisitExtension(n spec.ExtensionNode) error {
+	return spec.VisitFallback(n, v)
+}
+
+// visitLogical flattens the chain of the same logical operator.
+func (v *SpecToSqlVisitor) visitLogical(n spec.InfixNode) error {
+	precedence := specPrecedenceAnd
+	if n.Operator() == operators.OperatorOr {
+		precedence = specPrecedenceOr
+	}
+	var parts []string
+	for _, node := range specOperands(n, n.Operator()) {
+		part, err := v.operand(node, precedence)
+		if err != nil {
+			return err
+		}
+		parts = append(parts, part)
+	}
+	v.emit(strings.Join(parts, fmt.Sprintf(" %s ", n.Operator())), precedence)
+	return nil
+}
+
+// operand compiles the predicate, parenthesized if it binds weaker than the enclosing operator.
+func (v *SpecToSqlVisitor) operand(node spec.Visitable, precedence int) (string, error) {
+	if err := node.Accept(v); err != nil {
+		return "", err
+	}
+	if v.precedence < precedence {
+		return "(" + v.sql + ")", nil
+	}
+	return v.sql, nil
+}
+
+// valueOperand compiles the operand of a comparison: a field, an object or a value.
+func (v *SpecToSqlVisitor) valueOperand(node spec.Visitable) (string, error) {
+	switch n := node.(type) {
+	case spec.FieldNode:
+		return v.fieldExpr(n)
+	case spec.ValueNode:
+		v.params = append(v.params, encode(n.Value()))
+		return v.dialect.JSONParam(), nil
+	case spec.GlobalScopeNode, spec.ObjectNode, spec.ItemNode:
+		return v.objectExpr(n.(spec.EmptiableObject))
+	}
+	return "", fmt.Errorf("%w: %T as operand of comparison", domainquery.ErrUnsupportedConversion, node)
+}
+
+func (v *SpecToSqlVisitor) fieldExpr(n spec.FieldNode) (string, error) {
+	return v.objectExpr(n.Object(), n.Name())
+}
+
+// objectExpr returns the jsonb path of the object from the document, or from the current element,
+// followed by the keys.
+func (v *SpecToSqlVisitor) objectExpr(object spec.EmptiableObject, keys ...string) (string, error) {
+	for {
+		switch o := object.(type) {
+		case spec.GlobalScopeNode:
+			return v.dialect.JSONPath(v.targetValueExpr, keys), nil
+		case spec.ItemNode:
+			if len(v.items) == 0 {
+				return "", fmt.Errorf("%w: item outside of wildcard", domainquery.ErrUnsupportedConversion)
+			}
+			return v.dialect.JSONPath(v.items[len(v.items)-1], keys), nil
+		case spec.ObjectNode:
+			keys = append([]string{o.Name()}, keys...)
+			object = o.Parent()
+		default:
+			return "", fmt.Errorf("%w: path through %T", domainquery.ErrUnsupportedConversion, object)
+		}
+	}
+}
+
+func (v *SpecToSqlVisitor) emit(sql string, precedence int) {
+	v.sql = sql
+	v.precedence = precedence
+}
+
+// specOperands flattens the chain of the same logical operator.
+func specOperands(node spec.Visitable, operator operators.Operator) []spec.Visitable {
+	n, ok := node.(spec.InfixNode)
+	if !ok || n.Operator() != operator {
+		return []spec.Visitable{node}
+	}
+	return append(specOperands(n.Left(), operator), specOperands(n.Right(), operator)...)
+}
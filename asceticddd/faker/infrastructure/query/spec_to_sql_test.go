@@ -0,0 +1,138 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+)
+
+func TestSpecToSql(t *testing.T) {
+	root := spec.GlobalScope()
+	cases := []struct {
+		name   string
+		node   spec.Visitable
+		sql    string
+		params []any
+	}{
+		{
+			"comparison",
+			spec.GreaterThan(spec.Field(root, "age"), spec.Value(18)),
+			"value->'age' > $1",
+			[]any{encode(18)},
+		},
+		{
+			"nested field",
+			spec.Equal(spec.Field(spec.Object(root, "address"), "city"), spec.Value("Berlin")),
+			"value->'address'->'city' = $1",
+			[]any{encode("Berlin")},
+		},
+		{
+			"two fields",
+			spec.LessThanEqual(spec.Field(root, "spent"), spec.Field(root, "budget")),
+			"value->'spent' <= value->'budget'",
+			nil,
+		},
+		{
+			"not equal",
+			spec.NotEqual(spec.Field(root, "status"), spec.Value("deleted")),
+			"value->'status' IS DISTINCT FROM $1",
+			[]any{encode("deleted")},
+		},
+		{
+			"null",
+			spec.And(
+				spec.Equal(spec.Value(nil), spec.Field(root, "email")),
+				spec.NotEqual(spec.Field(root, "phone"), spec.Value(nil)),
+				spec.IsNotNull(spec.Field(root, "name")),
+			),
+			"value->'email' IS NULL AND value->'phone' IS NOT NULL AND value->'name' IS NOT NULL",
+			nil,
+		},
+		{
+			"membership",
+			spec.And(
+				spec.In(spec.Field(root, "status"), spec.Value([]string{"active", "pending"})),
+				spec.NotIn(spec.Field(root, "role"), spec.Value([]string{"root"})),
+			),
+			"value->'status' IN (SELECT jsonb_array_elements($1)) AND " +
+				"NOT coalesce(value->'role' IN (SELECT jsonb_array_elements($2)), false)",
+			[]any{encode([]string{"active", "pending"}), encode([]string{"root"})},
+		},
+		{
+			"string functions",
+			spec.Or(
+				spec.Contains(spec.Field(root, "name"), spec.Value("50%")),
+				spec.Search(spec.Field(root, "code"), spec.Value("^A")),
+			),
+			"(value->'name' #>> '{}') LIKE $1 OR (value->'code' #>> '{}') ~ $2",
+			[]any{`%50\%%`, "^A"},
+		},
+		{
+			"between",
+			spec.Between(spec.Field(root, "age"), spec.Value(18), spec.Value(65)),
+			"value->'age' >= $1 AND value->'age' <= $2",
+			[]any{encode(18), encode(65)},
+		},
+		{
+			"boolean field",
+			spec.Not(spec.Field(root, "blocked")),
+			"NOT (value->'blocked' = $1)",
+			[]any{encode(true)},
+		},
+		{
+			"precedence",
+			spec.And(
+				spec.Or(spec.LessThan(spec.Field(root, "age"), spec.Value(18)), spec.GreaterThan(spec.Field(root, "age"), spec.Value(65))),
+				spec.Equal(spec.Field(root, "status"), spec.Value("active")),
+			),
+			"(value->'age' < $1 OR value->'age' > $2) AND value->'status' = $3",
+			[]any{encode(18), encode(65), encode("active")},
+		},
+		{
+			"wildcard",
+			spec.Wildcard(spec.Object(root, "items"), spec.And(
+				spec.GreaterThan(spec.Field(spec.Item(), "price"), spec.Value(10)),
+				spec.Wildcard(spec.Object(spec.Item(), "tags"), spec.Equal(spec.Field(spec.Item(), "name"), spec.Value("sale"))),
+			)),
+			"EXISTS (SELECT 1 FROM jsonb_array_elements(value->'items') AS rt1 WHERE rt1->'price' > $1 AND " +
+				"EXISTS (SELECT 1 FROM jsonb_array_elements(rt1->'tags') AS rt2 WHERE rt2->'name' = $2))",
+			[]any{encode(10), encode("sale")},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sql, params, err := SpecToSql(c.node)
+			require.NoError(t, err)
+			assert.Equal(t, c.sql, sql)
+			assert.Equal(t, c.params, params)
+		})
+	}
+}
+
+func TestSpecToSqlVisitorTargetValueExpr(t *testing.T) {
+	v := NewSpecToSqlVisitor("t.doc")
+	v.SetParamBinder(QuestionParamBinder{})
+	err := spec.Equal(spec.Field(spec.GlobalScope(), "status"), spec.Value("active")).Accept(v)
+	require.NoError(t, err)
+	sql, _, err := v.Result()
+	require.NoError(t, err)
+	assert.Equal(t, "t.doc->'status' = ?", sql)
+}
+
+func TestSpecToSqlUnsupported(t *testing.T) {
+	root := spec.GlobalScope()
+	for _, node := range []spec.Visitable{
+		spec.Equal(spec.Add(spec.Field(root, "a"), spec.Value(1)), spec.Value(2)),
+		spec.GreaterThan(spec.Field(spec.Item(), "price"), spec.Value(1)),
+		spec.Wildcard(root, spec.Field(spec.Item(), "active")),
+		spec.Slice(spec.Object(root, "items"), 0, 2, spec.Field(spec.Item(), "active")),
+		spec.Value(true),
+	} {
+		_, _, err := SpecToSql(node)
+		assert.ErrorIs(t, err, domainquery.ErrUnsupportedConversion)
+	}
+}
@@ -0,0 +1,395 @@
+package repositories
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+)
+
+// Format is the file format of exported and imported states.
+type Format string
+
+const (
+	// FormatNDJSON is one JSON object per line.
+	FormatNDJSON Format = "ndjson"
+	// FormatCSV is a header of top-level keys and one state per row.
+	// Strings are written as is unless they are valid JSON, other values are written as JSON;
+	// empty cells are missing keys. So states round-trip, nested objects included.
+	FormatCSV Format = "csv"
+)
+
+// Validator checks a state before import.
+type Validator func(state map[string]any) error
+
+// ImportError is the error of the state at the line of the input, 1-based.
+// For CSV the header is line 1.
+type ImportError struct {
+	Line int
+	Err  error
+}
+
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ImportError) Unwrap() error {
+	return e.Err
+}
+
+// ImportStats counts the outcomes of imported states.
+type ImportStats struct {
+	Inserted int
+	Updated  int
+	Skipped  int
+}
+
+// DocumentTransfer exports states of a table into NDJSON or CSV
+// and imports them back with batch upserts through the DocumentStore,
+// e.g. for data fixes and seeding of environments.
+type DocumentTransfer struct {
+	store      DocumentStore
+	batchSize  int
+	onConflict OnConflict
+	validator  Validator
+	masking    *MaskingPolicy
+	role       string
+	fieldTypes domainquery.FieldTypes
+}
+
+func NewDocumentTransfer(store DocumentStore) *DocumentTransfer {
+	return &DocumentTransfer{
+		store:      store,
+		batchSize:  defaultBatchSize,
+		onConflict: OnConflictMerge,
+	}
+}
+
+// SetBatchSize sets the number of states of one SaveMany call of Import
+// and of the first states whose keys make the CSV header of Export.
+func (t *DocumentTransfer) SetBatchSize(batchSize int) {
+	t.batchSize = batchSize
+}
+
+// SetOnConflict sets what Import does with stored ids, OnConflictMerge by default.
+func (t *DocumentTransfer) SetOnConflict(onConflict OnConflict) {
+	t.onConflict = onConflict
+}
+
+// SetValidator sets the check of imported states.
+func (t *DocumentTransfer) SetValidator(validator Validator) {
+	t.validator = validator
+}
+
+// SetMasking makes Export mask the states with the policy for the recipient role,
+// e.g. when production data is exported for lower environments.
+func (t *DocumentTransfer) SetMasking(policy *MaskingPolicy, role string) {
+	t.masking = policy
+	t.role = role
+}
+
+// SetFieldTypes adds the top-level keys of the paths to the CSV header of Export,
+// so that keys missing in the first batch of states are exported too.
+func (t *DocumentTransfer) SetFieldTypes(fieldTypes domainquery.FieldTypes) {
+	t.fieldTypes = fieldTypes
+}
+
+// Export writes the states matching the specification (all states if nil)
+// in the order of ids and returns their number. The states are streamed from the store;
+// for CSV the first batch is buffered to make the header of its keys and of SetFieldTypes,
+// a key of a later state out of the header is an error.
+func (t *DocumentTransfer) Export(
+	s session.Session, table string, specification spec.Visitable, w io.Writer, format Format,
+) (int, error) {
+	var write func(state map[string]any) error
+	var flush func() error
+	switch format {
+	case FormatNDJSON:
+		write, flush = ndjsonWriter(w)
+	case FormatCSV:
+		write, flush = csvWriter(w, t.batchSize, t.fieldTypes)
+	default:
+		return 0, fmt.Errorf("unknown format %q", format)
+	}
+
+	count := 0
+	err := t.store.FindEachBySpecification(s, table, specification, func(state map[string]any) error {
+		if t.masking != nil {
+			var err error
+			if state, err = t.masking.Mask(s, table, t.role, state); err != nil {
+				return err
+			}
+		}
+		count++
+		return write(state)
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Import reads the states, validates them and saves them in batches with SaveMany.
+// Import stops at the first invalid state, batches saved before it stay saved,
+// so run Import within Atomic to import all or nothing.
+func (t *DocumentTransfer) Import(
+	s session.Session, table string, r io.Reader, format Format,
+) (ImportStats, error) {
+	var stats ImportStats
+	var read func() (map[string]any, int, error)
+	switch format {
+	case FormatNDJSON:
+		read = ndjsonReader(r)
+	case FormatCSV:
+		read = csvReader(r)
+	default:
+		return stats, fmt.Errorf("unknown format %q", format)
+	}
+
+	batchSize := t.batchSize
+	if batchSize < 1 {
+		batchSize = defaultBatchSize
+	}
+	batch := make([]map[string]any, 0, batchSize)
+	lines := make([]int, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		results, err := t.store.SaveMany(s, table, batch, t.onConflict)
+		if err != nil {
+			return &ImportError{Line: lines[0], Err: fmt.Errorf("batch of %d states: %w", len(batch), err)}
+		}
+		for _, result := range results {
+			switch result.Outcome {
+			case SaveInserted:
+				stats.Inserted++
+			case SaveUpdated:
+				stats.Updated++
+			default:
+				stats.Skipped++
+			}
+		}
+		batch, lines = batch[:0], lines[:0]
+		return nil
+	}
+
+	for {
+		state, line, err := read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return stats, err
+		}
+		if t.validator != nil {
+			if err := t.validator(state); err != nil {
+				return stats, &ImportError{Line: line, Err: err}
+			}
+		}
+		batch = append(batch, state)
+		lines = append(lines, line)
+		if len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				return stats, err
+			}
+		}
+	}
+	return stats, flush()
+}
+
+func ndjsonWriter(w io.Writer) (write func(state map[string]any) error, flush func() error) {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	write = func(state map[string]any) error {
+		return encoder.Encode(state)
+	}
+	return write, func() error { return nil }
+}
+
+// csvWriter buffers the first batchSize states, then writes the header of their keys
+// and of the top-level keys of the field types, the buffered states and the later ones.
+func csvWriter(
+	w io.Writer, batchSize int, fieldTypes domainquery.FieldTypes,
+) (write func(state map[string]any) error, flush func() error) {
+	if batchSize < 1 {
+		batchSize = defaultBatchSize
+	}
+	writer := csv.NewWriter(w)
+	var header []string
+	var columns map[string]bool
+	var buffered []map[string]any
+
+	writeRow := func(state map[string]any) error {
+		for key := range state {
+			if !columns[key] {
+				return fmt.Errorf("key %s is not in the CSV header of the first %d states", key, batchSize)
+			}
+		}
+		record := make([]string, len(header))
+		for i, key := range header {
+			value, ok := state[key]
+			if !ok {
+				continue
+			}
+			cell, err := encodeCell(value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			record[i] = cell
+		}
+		return writer.Write(record)
+	}
+	writeHeader := func() error {
+		columns = map[string]bool{}
+		for path := range fieldTypes {
+			if i := strings.IndexAny(path, ".["); i >= 0 {
+				path = path[:i]
+			}
+			columns[path] = true
+		}
+		for _, state := range buffered {
+			for key := range state {
+				columns[key] = true
+			}
+		}
+		header = make([]string, 0, len(columns))
+		for key := range columns {
+			header = append(header, key)
+		}
+		sort.Strings(header)
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		for _, state := range buffered {
+			if err := writeRow(state); err != nil {
+				return err
+			}
+		}
+		buffered = nil
+		return nil
+	}
+
+	write = func(state map[string]any) error {
+		if header != nil {
+			return writeRow(state)
+		}
+		buffered = append(buffered, state)
+		if len(buffered) < batchSize {
+			return nil
+		}
+		return writeHeader()
+	}
+	flush = func() error {
+		if header == nil {
+			if err := writeHeader(); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+	return write, flush
+}
+
+func encodeCell(value any) (string, error) {
+	if str, ok := value.(string); ok && str != "" && !json.Valid([]byte(str)) {
+		return str, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func decodeCell(cell string) (any, error) {
+	if !json.Valid([]byte(cell)) {
+		return cell, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(cell)))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// ndjsonReader returns the states of the lines, skipping blank ones.
+// Numbers are json.Number, so that big integers are saved as is.
+func ndjsonReader(r io.Reader) func() (map[string]any, int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	line := 0
+	return func() (map[string]any, int, error) {
+		for scanner.Scan() {
+			line++
+			data := bytes.TrimSpace(scanner.Bytes())
+			if len(data) == 0 {
+				continue
+			}
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.UseNumber()
+			var state map[string]any
+			if err := decoder.Decode(&state); err != nil {
+				return nil, line, &ImportError{Line: line, Err: err}
+			}
+			if state == nil {
+				return nil, line, &ImportError{Line: line, Err: errors.New("state is not an object")}
+			}
+			return state, line, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, line, err
+		}
+		return nil, line, io.EOF
+	}
+}
+
+func csvReader(r io.Reader) func() (map[string]any, int, error) {
+	reader := csv.NewReader(r)
+	var header []string
+	return func() (map[string]any, int, error) {
+		if header == nil {
+			record, err := reader.Read()
+			if err != nil {
+				return nil, 1, err
+			}
+			header = record
+		}
+		record, err := reader.Read()
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, parseErr.Line, &ImportError{Line: parseErr.Line, Err: parseErr.Err}
+			}
+			return nil, 0, err
+		}
+		line, _ := reader.FieldPos(0)
+		state := make(map[string]any, len(header))
+		for i, key := range header {
+			if record[i] == "" {
+				continue
+			}
+			value, err := decodeCell(record[i])
+			if err != nil {
+				return nil, line, &ImportError{Line: line, Err: fmt.Errorf("%s: %w", key, err)}
+			}
+			state[key] = value
+		}
+		return state, line, nil
+	}
+}
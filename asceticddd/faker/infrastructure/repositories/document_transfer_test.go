@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/utils/testutils"
+)
+
+func TestDocumentTransferExport(t *testing.T) {
+	s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+	store := NewInMemoryDocumentStore("", nil)
+	_, err := store.SaveMany(s, "companies", []map[string]any{
+		{"id": 2, "name": "Globex", "address": map[string]any{"city": "Berlin"}},
+		{"id": 1, "name": "Acme, Inc.", "zip": "01001", "code": "42"},
+		{"id": 3, "name": "Initech", "closed": true},
+	}, OnConflictError)
+	require.NoError(t, err)
+	transfer := NewDocumentTransfer(store)
+	active := spec.Not(spec.Field(spec.GlobalScope(), "closed"))
+
+	t.Run("ndjson", func(t *testing.T) {
+		var out bytes.Buffer
+		count, err := transfer.Export(s, "companies", active, &out, FormatNDJSON)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.Equal(t,
+			`{"code":"42","id":1,"name":"Acme, Inc.","zip":"01001"}`+"\n"+
+				`{"address":{"city":"Berlin"},"id":2,"name":"Globex"}`+"\n",
+			out.String(),
+		)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var out bytes.Buffer
+		count, err := transfer.Export(s, "companies", nil, &out, FormatCSV)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t,
+			"address,closed,code,id,name,zip\n"+
+				`,,"""42""",1,"Acme, Inc.",01001`+"\n"+
+				`"{""city"":""Berlin""}",,,2,Globex,`+"\n"+
+				",true,,3,Initech,\n",
+			out.String(),
+		)
+	})
+
+	t.Run("csv header of the first batch", func(t *testing.T) {
+		transfer := NewDocumentTransfer(store)
+		transfer.SetBatchSize(2)
+		_, err := transfer.Export(s, "companies", nil, &bytes.Buffer{}, FormatCSV)
+		assert.EqualError(t, err, "key closed is not in the CSV header of the first 2 states")
+
+		transfer.SetFieldTypes(domainquery.FieldTypes{"closed": domainquery.TypeHintBool})
+		var out bytes.Buffer
+		count, err := transfer.Export(s, "companies", nil, &out, FormatCSV)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t,
+			"address,closed,code,id,name,zip\n"+
+				`,,"""42""",1,"Acme, Inc.",01001`+"\n"+
+				`"{""city"":""Berlin""}",,,2,Globex,`+"\n"+
+				",true,,3,Initech,\n",
+			out.String(),
+		)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := transfer.Export(s, "companies", nil, &bytes.Buffer{}, "xml")
+		assert.Error(t, err)
+	})
+}
+
+func TestDocumentTransferImport(t *testing.T) {
+	s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+
+	t.Run("round trip", func(t *testing.T) {
+		for _, format := range []Format{FormatNDJSON, FormatCSV} {
+			source := NewInMemoryDocumentStore("", nil)
+			_, err := source.SaveMany(s, "companies", []map[string]any{
+				{"id": 1, "name": "Acme", "code": "42", "empty": ""},
+				{"id": 2, "name": "Globex", "tags": []any{"a", "b"}, "score": 9007199254740993},
+			}, OnConflictError)
+			require.NoError(t, err)
+			var out bytes.Buffer
+			_, err = NewDocumentTransfer(source).Export(s, "companies", nil, &out, format)
+			require.NoError(t, err)
+
+			target := NewInMemoryDocumentStore("", nil)
+			transfer := NewDocumentTransfer(target)
+			transfer.SetBatchSize(1)
+			stats, err := transfer.Import(s, "companies", &out, format)
+			require.NoError(t, err, format)
+			assert.Equal(t, ImportStats{Inserted: 2}, stats)
+
+			acme := target.tables["companies"]["1"]
+			assert.Equal(t, "42", acme["code"], format)
+			assert.Equal(t, "", acme["empty"], format)
+			globex := target.tables["companies"]["2"]
+			assert.Equal(t, json.Number("9007199254740993"), globex["score"], format)
+			assert.Equal(t, []any{"a", "b"}, globex["tags"], format)
+		}
+	})
+
+	t.Run("upsert", func(t *testing.T) {
+		store := NewInMemoryDocumentStore("", nil)
+		_, err := store.SaveMany(s, "companies", []map[string]any{{"id": 1, "name": "Acme", "city": "Berlin"}}, OnConflictError)
+		require.NoError(t, err)
+		stats, err := NewDocumentTransfer(store).Import(s, "companies", strings.NewReader(
+			"id,name\n1,Acme Corp\n2,Globex\n",
+		), FormatCSV)
+		require.NoError(t, err)
+		assert.Equal(t, ImportStats{Inserted: 1, Updated: 1}, stats)
+		assert.Equal(t, map[string]any{"id": json.Number("1"), "name": "Acme Corp", "city": "Berlin"}, store.tables["companies"]["1"])
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		store := NewInMemoryDocumentStore("", nil)
+		transfer := NewDocumentTransfer(store)
+		transfer.SetBatchSize(2)
+		invalid := errors.New("name is required")
+		transfer.SetValidator(func(state map[string]any) error {
+			if _, ok := state["name"]; !ok {
+				return invalid
+			}
+			return nil
+		})
+		stats, err := transfer.Import(s, "companies", strings.NewReader(
+			`{"id":1,"name":"Acme"}`+"\n"+`{"id":2,"name":"Globex"}`+"\n\n"+`{"id":3}`+"\n",
+		), FormatNDJSON)
+		assert.ErrorIs(t, err, invalid)
+		var importErr *ImportError
+		require.ErrorAs(t, err, &importErr)
+		assert.Equal(t, 4, importErr.Line)
+		assert.Equal(t, ImportStats{Inserted: 2}, stats)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		transfer := NewDocumentTransfer(NewInMemoryDocumentStore("", nil))
+		_, err := transfer.Import(s, "companies", strings.NewReader(`{"id":1}`+"\n[1]\n"), FormatNDJSON)
+		assert.ErrorContains(t, err, "line 2: ")
+		_, err = transfer.Import(s, "companies", strings.NewReader("id,name\n1\n"), FormatCSV)
+		assert.ErrorContains(t, err, "line 2: ")
+		_, err = transfer.Import(s, "companies", strings.NewReader("name\nAcme\n"), FormatCSV)
+		assert.ErrorContains(t, err, "line 2: batch of 1 states: ")
+	})
+}
+
+func TestDocumentTransferExportPg(t *testing.T) {
+	s := testutils.NewDbSessionStub(testutils.NewRowsStub([]any{[]byte(`{"id":1}`)}))
+	var out bytes.Buffer
+	_, err := NewDocumentTransfer(NewPgDocumentStore("", "")).Export(s, "companies", nil, &out, FormatNDJSON)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT value FROM companies ORDER BY value_id", s.ActualQuery)
+	assert.Equal(t, "{\"id\":1}\n", out.String())
+}
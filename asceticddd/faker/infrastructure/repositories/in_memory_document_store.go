@@ -0,0 +1,215 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"sort"
+	"sync"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+)
+
+// InMemoryDocumentStore keeps states in memory, by table and id.
+// Spec queries are evaluated with EvaluateWalker.
+type InMemoryDocumentStore struct {
+	mu     sync.RWMutex
+	idKey  string
+	walker *domainquery.EvaluateWalker
+	tables map[string]map[string]map[string]any
+}
+
+func NewInMemoryDocumentStore(idKey string, walker *domainquery.EvaluateWalker) *InMemoryDocumentStore {
+	if idKey == "" {
+		idKey = defaultIdKey
+	}
+	if walker == nil {
+		walker = domainquery.NewEvaluateWalker(nil)
+	}
+	return &InMemoryDocumentStore{
+		idKey:  idKey,
+		walker: walker,
+		tables: map[string]map[string]map[string]any{},
+	}
+}
+
+// SaveMany stores shallow copies of the states.
+// With OnConflictError nothing is stored if any id is already stored.
+func (s *InMemoryDocumentStore) SaveMany(
+	sess session.Session, table string, states []map[string]any, onConflict OnConflict,
+) ([]SaveResult, error) {
+	keys := make([]string, len(states))
+	positions := make(map[string]int, len(states))
+	for i, state := range states {
+		id, ok := state[s.idKey]
+		if !ok || id == nil {
+			return nil, fmt.Errorf("state %d has no %q", i, s.idKey)
+		}
+		key, err := json.Marshal(id)
+		if err != nil {
+			return nil, err
+		}
+		if prev, ok := positions[string(key)]; ok {
+			return nil, fmt.Errorf("states %d and %d have the same id %s", prev, i, key)
+		}
+		positions[string(key)] = i
+		keys[i] = string(key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows, ok := s.tables[table]
+	if !ok {
+		rows = map[string]map[string]any{}
+		s.tables[table] = rows
+	}
+	switch onConflict {
+	case OnConflictError:
+		for _, key := range keys {
+			if _, ok := rows[key]; ok {
+				return nil, fmt.Errorf("duplicate key %s in table %s", key, table)
+			}
+		}
+	case OnConflictDoNothing, OnConflictMerge:
+	default:
+		return nil, fmt.Errorf("unknown conflict strategy %d", onConflict)
+	}
+
+	results := make([]SaveResult, len(states))
+	for i, state := range states {
+		id := state[s.idKey]
+		stored, exists := rows[keys[i]]
+		switch {
+		case !exists:
+			rows[keys[i]] = maps.Clone(state)
+			results[i] = SaveResult{Id: id, Outcome: SaveInserted}
+		case onConflict == OnConflictMerge:
+			merged := maps.Clone(stored)
+			maps.Copy(merged, state)
+			rows[keys[i]] = merged
+			results[i] = SaveResult{Id: id, Outcome: SaveUpdated}
+		default:
+			results[i] = SaveResult{Id: id, Outcome: SaveSkipped}
+		}
+	}
+	return results, nil
+}
+
+// CountBySpec returns the number of states matching spec.Query.
+// Ordering and pagination of the spec are ignored.
+func (s *InMemoryDocumentStore) CountBySpec(sess session.Session, table string, spec domainquery.QuerySpec) (int, error) {
+	count := 0
+	err := s.scan(sess, table, spec.Query, func(map[string]any) bool {
+		count++
+		return true
+	})
+	return count, err
+}
+
+// ExistsBySpec reports whether any state matches spec.Query.
+// Ordering and pagination of the spec are ignored.
+func (s *InMemoryDocumentStore) ExistsBySpec(sess session.Session, table string, spec domainquery.QuerySpec) (bool, error) {
+	exists := false
+	err := s.scan(sess, table, spec.Query, func(map[string]any) bool {
+		exists = true
+		return false
+	})
+	return exists, err
+}
+
+// Aggregate returns the groups of states matching aggregation.Query, see domainquery.Aggregation.
+func (s *InMemoryDocumentStore) Aggregate(
+	sess session.Session, table string, aggregation domainquery.Aggregation,
+) ([]domainquery.AggregateRow, error) {
+	if err := aggregation.Validate(); err != nil {
+		return nil, err
+	}
+	var states []map[string]any
+	err := s.scan(sess, table, aggregation.Query, func(state map[string]any) bool {
+		states = append(states, state)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return aggregation.Apply(states)
+}
+
+// FindBySpecification returns copies of the states matching the specification in the order of ids.
+// The specification is converted with domainquery.SpecToQuery,
+// so unlike PgDocumentStore comparisons of two fields are ErrUnsupportedConversion.
+// Nil specification matches all states.
+func (s *InMemoryDocumentStore) FindBySpecification(
+	sess session.Session, table string, specification spec.Visitable,
+) ([]map[string]any, error) {
+	var states []map[string]any
+	err := s.FindEachBySpecification(sess, table, specification, func(state map[string]any) error {
+		states = append(states, state)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// FindEachBySpecification calls fn with copies of the states matching the specification
+// like FindBySpecification. It stops at the first error of fn.
+func (s *InMemoryDocumentStore) FindEachBySpecification(
+	sess session.Session, table string, specification spec.Visitable, fn func(state map[string]any) error,
+) error {
+	var query domainquery.IQueryOperator
+	if specification != nil {
+		var err error
+		if query, err = domainquery.SpecToQuery(specification); err != nil {
+			return err
+		}
+	}
+	var fnErr error
+	err := s.scan(sess, table, query, func(state map[string]any) bool {
+		fnErr = fn(maps.Clone(state))
+		return fnErr == nil
+	})
+	if err != nil {
+		return err
+	}
+	return fnErr
+}
+
+// scan calls yield for each state matching the query in the order of ids,
+// until yield returns false. Nil query matches all states.
+func (s *InMemoryDocumentStore) scan(
+	sess session.Session, table string, query domainquery.IQueryOperator, yield func(map[string]any) bool,
+) error {
+	s.mu.RLock()
+	rows := s.tables[table]
+	keys := make([]string, 0, len(rows))
+	for key := range rows {
+		keys = append(keys, key)
+	}
+	states := make([]map[string]any, len(keys))
+	sort.Strings(keys)
+	for i, key := range keys {
+		states[i] = rows[key]
+	}
+	s.mu.RUnlock()
+
+	for _, state := range states {
+		if query != nil {
+			matched, err := s.walker.Evaluate(sess, query, state)
+			if err != nil {
+				return err
+			}
+			if !matched {
+				continue
+			}
+		}
+		if !yield(state) {
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/utils/testutils"
+)
+
+func TestInMemoryDocumentStoreSaveMany(t *testing.T) {
+	s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+
+	seed := func(t *testing.T) *InMemoryDocumentStore {
+		store := NewInMemoryDocumentStore("", nil)
+		_, err := store.SaveMany(s, "companies", []map[string]any{
+			{"id": 1, "name": "Acme", "city": "Berlin"},
+		}, OnConflictError)
+		require.NoError(t, err)
+		return store
+	}
+	states := []map[string]any{
+		{"id": 1, "name": "Acme Corp"},
+		{"id": 2, "name": "Globex"},
+	}
+
+	t.Run("error strategy rejects the whole batch", func(t *testing.T) {
+		store := seed(t)
+		_, err := store.SaveMany(s, "companies", states, OnConflictError)
+		assert.Error(t, err)
+		count, err := store.CountBySpec(s, "companies", domainquery.QuerySpec{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("do nothing", func(t *testing.T) {
+		store := seed(t)
+		results, err := store.SaveMany(s, "companies", states, OnConflictDoNothing)
+		require.NoError(t, err)
+		assert.Equal(t, []SaveResult{
+			{Id: 1, Outcome: SaveSkipped},
+			{Id: 2, Outcome: SaveInserted},
+		}, results)
+		assert.Equal(t, "Acme", store.tables["companies"]["1"]["name"])
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		store := seed(t)
+		results, err := store.SaveMany(s, "companies", states, OnConflictMerge)
+		require.NoError(t, err)
+		assert.Equal(t, []SaveResult{
+			{Id: 1, Outcome: SaveUpdated},
+			{Id: 2, Outcome: SaveInserted},
+		}, results)
+		assert.Equal(t, map[string]any{"id": 1, "name": "Acme Corp", "city": "Berlin"}, store.tables["companies"]["1"])
+	})
+
+	t.Run("stores copies", func(t *testing.T) {
+		store := NewInMemoryDocumentStore("", nil)
+		state := map[string]any{"id": 1, "name": "Acme"}
+		_, err := store.SaveMany(s, "companies", []map[string]any{state}, OnConflictError)
+		require.NoError(t, err)
+		state["name"] = "Changed"
+		assert.Equal(t, "Acme", store.tables["companies"]["1"]["name"])
+	})
+
+	t.Run("duplicate ids", func(t *testing.T) {
+		store := NewInMemoryDocumentStore("", nil)
+		_, err := store.SaveMany(s, "companies", []map[string]any{{"id": 1}, {"id": 1}}, OnConflictMerge)
+		assert.Error(t, err)
+	})
+
+	t.Run("missing id", func(t *testing.T) {
+		store := NewInMemoryDocumentStore("", nil)
+		_, err := store.SaveMany(s, "companies", []map[string]any{{"name": "Acme"}}, OnConflictMerge)
+		assert.Error(t, err)
+	})
+}
+
+func TestInMemoryDocumentStoreCountAndExists(t *testing.T) {
+	s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+	store := NewInMemoryDocumentStore("", nil)
+	_, err := store.SaveMany(s, "companies", []map[string]any{
+		{"id": 1, "status": "active"},
+		{"id": 2, "status": "closed"},
+		{"id": 3, "status": "active"},
+	}, OnConflictError)
+	require.NoError(t, err)
+
+	active := domainquery.QuerySpec{
+		Query: domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"status": domainquery.EqOperator{Value: "active"},
+		}},
+		Limit: 1,
+	}
+	missing := domainquery.QuerySpec{
+		Query: domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"status": domainquery.EqOperator{Value: "unknown"},
+		}},
+	}
+
+	t.Run("count ignores pagination", func(t *testing.T) {
+		count, err := store.CountBySpec(s, "companies", active)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("count all", func(t *testing.T) {
+		count, err := store.CountBySpec(s, "companies", domainquery.QuerySpec{})
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("count unknown table", func(t *testing.T) {
+		count, err := store.CountBySpec(s, "unknown", domainquery.QuerySpec{})
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("exists", func(t *testing.T) {
+		exists, err := store.ExistsBySpec(s, "companies", active)
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		exists, err = store.ExistsBySpec(s, "companies", missing)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("field types of walker", func(t *testing.T) {
+		walker := domainquery.NewEvaluateWalker(nil)
+		walker.SetFieldTypes(domainquery.FieldTypes{"age": domainquery.TypeHintNumeric})
+		typed := NewInMemoryDocumentStore("", walker)
+		_, err := typed.SaveMany(s, "people", []map[string]any{{"id": 1, "age": "42"}}, OnConflictError)
+		require.NoError(t, err)
+		exists, err := typed.ExistsBySpec(s, "people", domainquery.QuerySpec{
+			Query: domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+				"age": domainquery.ComparisonOperator{Op: "$gt", Value: 40},
+			}},
+		})
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+}
+
+func TestInMemoryDocumentStoreAggregate(t *testing.T) {
+	s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+	store := NewInMemoryDocumentStore("", nil)
+	_, err := store.SaveMany(s, "orders", []map[string]any{
+		{"id": 1, "customer_id": "c1", "status": "active", "amount": 10},
+		{"id": 2, "customer_id": "c1", "status": "active", "amount": 5.5},
+		{"id": 3, "customer_id": "c2", "status": "active", "amount": 1},
+		{"id": 4, "customer_id": "c2", "status": "closed", "amount": 100},
+	}, OnConflictError)
+	require.NoError(t, err)
+
+	rows, err := store.Aggregate(s, "orders", domainquery.GroupBy("customer_id").
+		Where(domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"status": domainquery.EqOperator{Value: "active"},
+		}}).
+		Count().
+		Sum("amount"))
+	require.NoError(t, err)
+	assert.Equal(t, []domainquery.AggregateRow{
+		{Key: []any{"c1"}, Values: map[string]any{"count": 2, "sum(amount)": 15.5}},
+		{Key: []any{"c2"}, Values: map[string]any{"count": 1, "sum(amount)": 1.0}},
+	}, rows)
+
+	_, err = store.Aggregate(s, "orders", domainquery.GroupBy("customer_id"))
+	assert.Error(t, err)
+}
+
+func TestInMemoryDocumentStoreFindBySpecification(t *testing.T) {
+	s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+	store := NewInMemoryDocumentStore("", nil)
+	_, err := store.SaveMany(s, "orders", []map[string]any{
+		{"id": 1, "status": "active", "items": []any{map[string]any{"price": 5}}},
+		{"id": 2, "status": "active", "items": []any{map[string]any{"price": 20}}},
+		{"id": 3, "status": "closed", "items": []any{map[string]any{"price": 50}}},
+	}, OnConflictError)
+	require.NoError(t, err)
+	root := spec.GlobalScope()
+
+	states, err := store.FindBySpecification(s, "orders", spec.And(
+		spec.Equal(spec.Field(root, "status"), spec.Value("active")),
+		spec.Wildcard(spec.Object(root, "items"), spec.GreaterThan(spec.Field(spec.Item(), "price"), spec.Value(10))),
+	))
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, 2, states[0]["id"])
+
+	states[0]["status"] = "changed"
+	assert.Equal(t, "active", store.tables["orders"]["2"]["status"])
+
+	_, err = store.FindBySpecification(s, "orders",
+		spec.LessThan(spec.Field(root, "spent"), spec.Field(root, "budget")))
+	assert.ErrorIs(t, err, domainquery.ErrUnsupportedConversion)
+
+	stop := errors.New("stop")
+	var ids []any
+	err = store.FindEachBySpecification(s, "orders", nil, func(state map[string]any) error {
+		ids = append(ids, state["id"])
+		if len(ids) == 2 {
+			return stop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, []any{1, 2}, ids)
+}
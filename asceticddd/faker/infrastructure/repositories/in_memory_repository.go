@@ -0,0 +1,259 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session/middleware"
+)
+
+var ErrStateNotFound = errors.New("state not found")
+
+// InMemoryRepository keeps states by id, so domain tests may run without Postgres.
+// Within InMemorySession.Atomic changes are kept by the atomic scope (copy-on-write)
+// and become visible to other sessions only when the outermost scope succeeds.
+// Outside of a transaction, or with sessions of other kinds, changes are applied at once.
+// Ids must be comparable, states are stored and returned as shallow copies.
+//
+// With SetSoftDeleteKey, Delete sets the key of the state to the time of deletion
+// instead of removing the state, and Get and Find skip such states, see WithDeleted.
+type InMemoryRepository struct {
+	table         *memoryTable
+	idKey         string
+	walker        *domainquery.EvaluateWalker
+	softDeleteKey string
+	withDeleted   bool
+	tracer        domainquery.IQueryTracer
+}
+
+// memoryTable is shared by the repository and its WithDeleted views.
+type memoryTable struct {
+	mu     sync.RWMutex
+	states map[any]map[string]any
+}
+
+func NewInMemoryRepository(idKey string, walker *domainquery.EvaluateWalker) *InMemoryRepository {
+	if idKey == "" {
+		idKey = defaultIdKey
+	}
+	if walker == nil {
+		walker = domainquery.NewEvaluateWalker(nil)
+	}
+	return &InMemoryRepository{
+		table:  &memoryTable{states: map[any]map[string]any{}},
+		idKey:  idKey,
+		walker: walker,
+	}
+}
+
+// SetSoftDeleteKey enables soft delete with the key of the deletion time, e.g. "deleted_at".
+// Empty key disables soft delete.
+func (r *InMemoryRepository) SetSoftDeleteKey(softDeleteKey string) {
+	r.softDeleteKey = softDeleteKey
+}
+
+// SetTracer traces Find and FindEach, see domainquery.IQueryTracer.
+// Evaluations of the states are traced by the tracer of the walker, see domainquery.WithTracer.
+func (r *InMemoryRepository) SetTracer(tracer domainquery.IQueryTracer) {
+	r.tracer = tracer
+}
+
+// WithDeleted returns the view of the same states which does not skip soft-deleted ones.
+func (r *InMemoryRepository) WithDeleted() *InMemoryRepository {
+	view := *r
+	view.withDeleted = true
+	return &view
+}
+
+// Get returns the state with the id, or ErrStateNotFound.
+func (r *InMemoryRepository) Get(s session.Session, id any) (map[string]any, error) {
+	if err := checkId(id); err != nil {
+		return nil, err
+	}
+	state, ok := r.lookup(scopeOf(s), id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrStateNotFound, id)
+	}
+	return maps.Clone(state), nil
+}
+
+// Save inserts or replaces the state with the id taken from idKey.
+func (r *InMemoryRepository) Save(s session.Session, state map[string]any) error {
+	id, ok := state[r.idKey]
+	if !ok || id == nil {
+		return fmt.Errorf("state has no %q", r.idKey)
+	}
+	if err := checkId(id); err != nil {
+		return err
+	}
+	r.set(scopeOf(s), id, maps.Clone(state))
+	return nil
+}
+
+// Delete removes the state with the id, or marks it as deleted in soft delete mode.
+// It returns ErrStateNotFound if there is no such state.
+func (r *InMemoryRepository) Delete(s session.Session, id any) error {
+	if err := checkId(id); err != nil {
+		return err
+	}
+	scope := scopeOf(s)
+	state, ok := r.lookup(scope, id)
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrStateNotFound, id)
+	}
+	if r.softDeleteKey == "" {
+		r.set(scope, id, nil)
+		return nil
+	}
+	deleted := maps.Clone(state)
+	deleted[r.softDeleteKey] = time.Now().UTC()
+	r.set(scope, id, deleted)
+	return nil
+}
+
+// Find returns the states matching the query in the order of ids. Nil query matches all states.
+func (r *InMemoryRepository) Find(s session.Session, query domainquery.IQueryOperator) (result []map[string]any, err error) {
+	ctx, end := domainquery.TraceQuery(s.Context(), r.tracer, domainquery.QueryOperationFind, "", query)
+	defer func() { end(err) }()
+	visible := r.table.snapshot(scopeOf(s))
+	ids := make([]any, 0, len(visible))
+	keys := make(map[any]string, len(visible))
+	for id := range visible {
+		key, err := json.Marshal(id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+		keys[id] = string(key)
+	}
+	sort.Slice(ids, func(i, j int) bool { return keys[ids[i]] < keys[ids[j]] })
+
+	for _, id := range ids {
+		state := visible[id]
+		if r.isDeleted(state) {
+			continue
+		}
+		if query != nil {
+			matched, err := r.walker.EvaluateContext(ctx, s, query, state)
+			if err != nil {
+				return nil, err
+			}
+			if !matched {
+				continue
+			}
+		}
+		result = append(result, maps.Clone(state))
+	}
+	return result, nil
+}
+
+// FindEach calls fn with each state matching the query in the order of ids, like Find.
+// It stops at the first error of fn.
+func (r *InMemoryRepository) FindEach(
+	s session.Session, query domainquery.IQueryOperator, fn func(state map[string]any) error,
+) error {
+	states, err := r.Find(s, query)
+	if err != nil {
+		return err
+	}
+	for _, state := range states {
+		if err := fn(state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookup returns the state visible within the scope, soft-deleted states are skipped unless withDeleted.
+func (r *InMemoryRepository) lookup(scope *memoryScope, id any) (map[string]any, bool) {
+	state, ok := r.table.lookup(scope, id)
+	if !ok || r.isDeleted(state) {
+		return nil, false
+	}
+	return state, true
+}
+
+func (r *InMemoryRepository) isDeleted(state map[string]any) bool {
+	if r.softDeleteKey == "" || r.withDeleted {
+		return false
+	}
+	deletedAt, ok := state[r.softDeleteKey]
+	return ok && deletedAt != nil
+}
+
+func (r *InMemoryRepository) set(scope *memoryScope, id any, state map[string]any) {
+	if scope != nil {
+		scope.set(r.table, id, state)
+		return
+	}
+	r.table.apply(map[any]map[string]any{id: state})
+}
+
+func (t *memoryTable) lookup(scope *memoryScope, id any) (map[string]any, bool) {
+	for sc := scope; sc != nil; sc = sc.parent {
+		if state, ok := sc.changes[t][id]; ok {
+			return state, state != nil
+		}
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	state, ok := t.states[id]
+	return state, ok
+}
+
+// snapshot returns the states visible within the scope.
+func (t *memoryTable) snapshot(scope *memoryScope) map[any]map[string]any {
+	t.mu.RLock()
+	visible := maps.Clone(t.states)
+	t.mu.RUnlock()
+
+	var scopes []*memoryScope
+	for sc := scope; sc != nil; sc = sc.parent {
+		scopes = append(scopes, sc)
+	}
+	for i := len(scopes) - 1; i >= 0; i-- {
+		for id, state := range scopes[i].changes[t] {
+			if state == nil {
+				delete(visible, id)
+			} else {
+				visible[id] = state
+			}
+		}
+	}
+	return visible
+}
+
+func (t *memoryTable) apply(changes map[any]map[string]any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, state := range changes {
+		if state == nil {
+			delete(t.states, id)
+		} else {
+			t.states[id] = state
+		}
+	}
+}
+
+// scopeOf returns the atomic scope of InMemorySession, possibly decorated by middleware.
+func scopeOf(s session.Session) *memoryScope {
+	if ms, ok := middleware.Unwrap(s).(*InMemorySession); ok {
+		return ms.scope
+	}
+	return nil
+}
+
+func checkId(id any) error {
+	if id == nil || !reflect.TypeOf(id).Comparable() {
+		return fmt.Errorf("id %v is not comparable", id)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+func TestInMemoryRepository(t *testing.T) {
+	s := NewInMemorySession(context.Background())
+
+	t.Run("get save delete", func(t *testing.T) {
+		repository := NewInMemoryRepository("", nil)
+		state := map[string]any{"id": 1, "name": "Acme"}
+		require.NoError(t, repository.Save(s, state))
+		state["name"] = "changed"
+
+		stored, err := repository.Get(s, 1)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": 1, "name": "Acme"}, stored)
+
+		require.NoError(t, repository.Delete(s, 1))
+		_, err = repository.Get(s, 1)
+		assert.ErrorIs(t, err, ErrStateNotFound)
+		assert.ErrorIs(t, repository.Delete(s, 1), ErrStateNotFound)
+	})
+
+	t.Run("invalid ids", func(t *testing.T) {
+		repository := NewInMemoryRepository("", nil)
+		assert.Error(t, repository.Save(s, map[string]any{"name": "Acme"}))
+		assert.Error(t, repository.Save(s, map[string]any{"id": []any{1}}))
+	})
+
+	t.Run("find", func(t *testing.T) {
+		repository := NewInMemoryRepository("", nil)
+		for _, state := range []map[string]any{
+			{"id": 3, "status": "active"},
+			{"id": 1, "status": "active"},
+			{"id": 2, "status": "closed"},
+		} {
+			require.NoError(t, repository.Save(s, state))
+		}
+		states, err := repository.Find(s, domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"status": domainquery.EqOperator{Value: "active"},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]any{
+			{"id": 1, "status": "active"},
+			{"id": 3, "status": "active"},
+		}, states)
+
+		all, err := repository.Find(s, nil)
+		require.NoError(t, err)
+		assert.Len(t, all, 3)
+
+		var ids []any
+		require.NoError(t, repository.FindEach(s, nil, func(state map[string]any) error {
+			ids = append(ids, state["id"])
+			return nil
+		}))
+		assert.Equal(t, []any{1, 2, 3}, ids)
+	})
+
+	t.Run("tracer", func(t *testing.T) {
+		var events []domainquery.QueryEvent
+		repository := NewInMemoryRepository("", nil)
+		repository.SetTracer(domainquery.QueryTimer(
+			func(ctx context.Context, event domainquery.QueryEvent, elapsed time.Duration, err error) {
+				events = append(events, event)
+			},
+		))
+		require.NoError(t, repository.Save(s, map[string]any{"id": 1, "status": "active"}))
+
+		_, err := repository.Find(s, domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"status": domainquery.EqOperator{Value: "active"},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, []domainquery.QueryEvent{{Operation: domainquery.QueryOperationFind, Shape: "{status:$eq}"}}, events)
+	})
+}
+
+func TestInMemoryRepositoryAtomic(t *testing.T) {
+	s := NewInMemorySession(context.Background())
+	seed := func(t *testing.T) *InMemoryRepository {
+		repository := NewInMemoryRepository("", nil)
+		require.NoError(t, repository.Save(s, map[string]any{"id": 1, "name": "Acme"}))
+		require.NoError(t, repository.Save(s, map[string]any{"id": 2, "name": "Globex"}))
+		return repository
+	}
+
+	t.Run("commit", func(t *testing.T) {
+		repository := seed(t)
+		err := s.Atomic(func(tx session.Session) error {
+			if err := repository.Save(tx, map[string]any{"id": 3, "name": "Initech"}); err != nil {
+				return err
+			}
+			if err := repository.Delete(tx, 1); err != nil {
+				return err
+			}
+			_, err := repository.Get(s, 3)
+			assert.ErrorIs(t, err, ErrStateNotFound, "not visible outside of the transaction")
+			states, err := repository.Find(tx, nil)
+			assert.NoError(t, err)
+			assert.Len(t, states, 2)
+			return nil
+		})
+		require.NoError(t, err)
+
+		states, err := repository.Find(s, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]any{
+			{"id": 2, "name": "Globex"},
+			{"id": 3, "name": "Initech"},
+		}, states)
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		repository := seed(t)
+		failure := errors.New("failure")
+		err := s.Atomic(func(tx session.Session) error {
+			if err := repository.Save(tx, map[string]any{"id": 1, "name": "Acme Corp"}); err != nil {
+				return err
+			}
+			return failure
+		})
+		assert.ErrorIs(t, err, failure)
+
+		state, err := repository.Get(s, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Acme", state["name"])
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		repository := seed(t)
+		err := s.Atomic(func(tx session.Session) error {
+			if err := repository.Delete(tx, 1); err != nil {
+				return err
+			}
+			_ = tx.Atomic(func(nested session.Session) error {
+				_, err := repository.Get(nested, 1)
+				assert.ErrorIs(t, err, ErrStateNotFound)
+				if err := repository.Delete(nested, 2); err != nil {
+					return err
+				}
+				return errors.New("rollback to savepoint")
+			})
+			return tx.Atomic(func(nested session.Session) error {
+				return repository.Save(nested, map[string]any{"id": 3, "name": "Initech"})
+			})
+		})
+		require.NoError(t, err)
+
+		states, err := repository.Find(s, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]any{
+			{"id": 2, "name": "Globex"},
+			{"id": 3, "name": "Initech"},
+		}, states)
+	})
+}
+
+func TestInMemoryRepositorySoftDelete(t *testing.T) {
+	s := NewInMemorySession(context.Background())
+	repository := NewInMemoryRepository("", nil)
+	repository.SetSoftDeleteKey("deleted_at")
+	require.NoError(t, repository.Save(s, map[string]any{"id": 1, "name": "Acme"}))
+	require.NoError(t, repository.Save(s, map[string]any{"id": 2, "name": "Globex"}))
+
+	err := s.Atomic(func(tx session.Session) error {
+		return repository.Delete(tx, 1)
+	})
+	require.NoError(t, err)
+
+	_, err = repository.Get(s, 1)
+	assert.ErrorIs(t, err, ErrStateNotFound)
+	assert.ErrorIs(t, repository.Delete(s, 1), ErrStateNotFound)
+	states, err := repository.Find(s, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"id": 2, "name": "Globex"}}, states)
+
+	deleted, err := repository.WithDeleted().Get(s, 1)
+	require.NoError(t, err)
+	assert.IsType(t, time.Time{}, deleted["deleted_at"])
+	all, err := repository.WithDeleted().Find(s, nil)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+}
@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/signals"
+)
+
+// InMemorySession is a session for InMemoryRepository.
+// Changes made within Atomic are visible only to the atomic session and its nested ones,
+// they are applied to the enclosing scope when the callback succeeds and discarded on error.
+type InMemorySession struct {
+	ctx       context.Context
+	scope     *memoryScope
+	onStarted signals.Signal[session.SessionScopeStartedEvent]
+	onEnded   signals.Signal[session.SessionScopeEndedEvent]
+}
+
+func NewInMemorySession(ctx context.Context) *InMemorySession {
+	return newInMemorySession(ctx, nil)
+}
+
+func newInMemorySession(ctx context.Context, scope *memoryScope) *InMemorySession {
+	return &InMemorySession{
+		ctx:       ctx,
+		scope:     scope,
+		onStarted: signals.NewSignal[session.SessionScopeStartedEvent](),
+		onEnded:   signals.NewSignal[session.SessionScopeEndedEvent](),
+	}
+}
+
+func (s *InMemorySession) Context() context.Context {
+	return s.ctx
+}
+
+func (s *InMemorySession) OnAtomicStarted() signals.Signal[session.SessionScopeStartedEvent] {
+	return s.onStarted
+}
+
+func (s *InMemorySession) OnAtomicEnded() signals.Signal[session.SessionScopeEndedEvent] {
+	return s.onEnded
+}
+
+func (s *InMemorySession) Atomic(callback session.SessionCallback) error {
+	scope := &memoryScope{parent: s.scope, changes: map[*memoryTable]map[any]map[string]any{}}
+	atomicSession := newInMemorySession(s.ctx, scope)
+
+	if err := s.onStarted.Notify(session.SessionScopeStartedEvent{Session: atomicSession}); err != nil {
+		return err
+	}
+
+	err := callback(atomicSession)
+
+	if endedErr := s.onEnded.Notify(session.SessionScopeEndedEvent{Session: atomicSession}); err == nil {
+		err = endedErr
+	}
+	if err != nil {
+		return err
+	}
+	scope.commit()
+	return nil
+}
+
+// memoryScope keeps the changes of an atomic scope by table and id,
+// a nil state is a deleted one.
+type memoryScope struct {
+	parent  *memoryScope
+	changes map[*memoryTable]map[any]map[string]any
+}
+
+func (sc *memoryScope) set(table *memoryTable, id any, state map[string]any) {
+	changes, ok := sc.changes[table]
+	if !ok {
+		changes = map[any]map[string]any{}
+		sc.changes[table] = changes
+	}
+	changes[id] = state
+}
+
+// commit applies the changes to the enclosing scope, or to the tables at the top level.
+func (sc *memoryScope) commit() {
+	for table, changes := range sc.changes {
+		if sc.parent == nil {
+			table.apply(changes)
+			continue
+		}
+		for id, state := range changes {
+			sc.parent.set(table, id, state)
+		}
+	}
+}
+
+// InMemorySessionPool opens InMemorySession.
+type InMemorySessionPool struct {
+	onSessionStarted signals.Signal[session.SessionScopeStartedEvent]
+	onSessionEnded   signals.Signal[session.SessionScopeEndedEvent]
+}
+
+func NewInMemorySessionPool() *InMemorySessionPool {
+	return &InMemorySessionPool{
+		onSessionStarted: signals.NewSignal[session.SessionScopeStartedEvent](),
+		onSessionEnded:   signals.NewSignal[session.SessionScopeEndedEvent](),
+	}
+}
+
+func (p *InMemorySessionPool) OnSessionStarted() signals.Signal[session.SessionScopeStartedEvent] {
+	return p.onSessionStarted
+}
+
+func (p *InMemorySessionPool) OnSessionEnded() signals.Signal[session.SessionScopeEndedEvent] {
+	return p.onSessionEnded
+}
+
+func (p *InMemorySessionPool) Session(ctx context.Context, callback session.SessionPoolCallback) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	sess := NewInMemorySession(ctx)
+
+	if err := p.onSessionStarted.Notify(session.SessionScopeStartedEvent{Session: sess}); err != nil {
+		return err
+	}
+
+	err := callback(sess)
+
+	if endedErr := p.onSessionEnded.Notify(session.SessionScopeEndedEvent{Session: sess}); err == nil {
+		err = endedErr
+	}
+
+	return err
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session/middleware"
+)
+
+func TestInMemorySessionPool(t *testing.T) {
+	repository := NewInMemoryRepository("", nil)
+	pool := middleware.NewSessionPool(NewInMemorySessionPool())
+
+	var events []string
+	err := pool.Session(context.Background(), func(s session.Session) error {
+		s.OnAtomicStarted().Attach(func(session.SessionScopeStartedEvent) error {
+			events = append(events, "started")
+			return nil
+		})
+		s.OnAtomicEnded().Attach(func(session.SessionScopeEndedEvent) error {
+			events = append(events, "ended")
+			return nil
+		})
+		return s.Atomic(func(tx session.Session) error {
+			assert.Equal(t, 1, middleware.AtomicDepth(tx))
+			if err := repository.Save(tx, map[string]any{"id": "a"}); err != nil {
+				return err
+			}
+			_, err := repository.Get(s, "a")
+			assert.ErrorIs(t, err, ErrStateNotFound)
+			return nil
+		})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"started", "ended"}, events)
+
+	_, err = repository.Get(NewInMemorySession(context.Background()), "a")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = NewInMemorySessionPool().Session(ctx, func(session.Session) error { return nil })
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+)
+
+// DocumentStore is implemented by PgDocumentStore and InMemoryDocumentStore
+// with the same semantics, so tests may run without Postgres.
+type DocumentStore interface {
+	SaveMany(s session.Session, table string, states []map[string]any, onConflict OnConflict) ([]SaveResult, error)
+	CountBySpec(s session.Session, table string, spec domainquery.QuerySpec) (int, error)
+	ExistsBySpec(s session.Session, table string, spec domainquery.QuerySpec) (bool, error)
+	Aggregate(s session.Session, table string, aggregation domainquery.Aggregation) ([]domainquery.AggregateRow, error)
+	FindBySpecification(s session.Session, table string, specification spec.Visitable) ([]map[string]any, error)
+	FindEachBySpecification(
+		s session.Session, table string, specification spec.Visitable, fn func(state map[string]any) error,
+	) error
+}
+
+var (
+	_ DocumentStore = (*PgDocumentStore)(nil)
+	_ DocumentStore = (*InMemoryDocumentStore)(nil)
+)
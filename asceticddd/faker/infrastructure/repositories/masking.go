@@ -0,0 +1,156 @@
+package repositories
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+// MaskAction is what MaskingPolicy does with the value of a path.
+type MaskAction string
+
+const (
+	// MaskRedact replaces the value with Redacted.
+	MaskRedact MaskAction = "redact"
+	// MaskHash replaces the value with the hex HMAC-SHA256 of its JSON,
+	// so equal values stay equal and may still be joined on.
+	MaskHash MaskAction = "hash"
+	// MaskRemove removes the key.
+	MaskRemove MaskAction = "remove"
+)
+
+const Redacted = "[REDACTED]"
+
+// MaskRule masks the dot-separated path of the states of a table.
+// Arrays on the path are masked element-wise, e.g. "contacts.email" masks the email of every contact.
+type MaskRule struct {
+	Path   string
+	Action MaskAction
+	// Roles are the recipient roles the rule applies to, all roles if empty.
+	Roles []string
+	// Where restricts the rule to the states matching the query, all states if nil.
+	Where domainquery.IQueryOperator
+}
+
+// MaskingPolicy masks personal data of exported states, per table and recipient role.
+type MaskingPolicy struct {
+	key    []byte
+	walker *domainquery.EvaluateWalker
+	rules  map[string][]MaskRule
+}
+
+// NewMaskingPolicy creates the policy with the secret key of MaskHash.
+func NewMaskingPolicy(key []byte) *MaskingPolicy {
+	return &MaskingPolicy{
+		key:    key,
+		walker: domainquery.NewEvaluateWalker(nil),
+		rules:  map[string][]MaskRule{},
+	}
+}
+
+// AddRule adds the rule for the table.
+func (p *MaskingPolicy) AddRule(table string, rule MaskRule) error {
+	switch rule.Action {
+	case MaskRedact, MaskHash, MaskRemove:
+	default:
+		return fmt.Errorf("unknown mask action %q", rule.Action)
+	}
+	if rule.Path == "" {
+		return fmt.Errorf("mask rule of %s has no path", table)
+	}
+	p.rules[table] = append(p.rules[table], rule)
+	return nil
+}
+
+// Mask returns the state with the rules of the table for the role applied.
+// The state itself is not modified, maps and arrays on masked paths are copied.
+func (p *MaskingPolicy) Mask(s session.Session, table, role string, state map[string]any) (map[string]any, error) {
+	result := state
+	for _, rule := range p.rules[table] {
+		if len(rule.Roles) > 0 && !slices.Contains(rule.Roles, role) {
+			continue
+		}
+		if rule.Where != nil {
+			matched, err := p.walker.Evaluate(s, rule.Where, state)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", rule.Path, err)
+			}
+			if !matched {
+				continue
+			}
+		}
+		masked, err := p.maskPath(result, domainquery.SplitPath(rule.Path), rule.Action)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rule.Path, err)
+		}
+		result = masked
+	}
+	return result, nil
+}
+
+func (p *MaskingPolicy) maskPath(state map[string]any, keys []string, action MaskAction) (map[string]any, error) {
+	value, ok := state[keys[0]]
+	if !ok {
+		return state, nil
+	}
+	result := maps.Clone(state)
+	if len(keys) == 1 {
+		if action == MaskRemove {
+			delete(result, keys[0])
+			return result, nil
+		}
+		masked, err := p.maskValue(value, action)
+		if err != nil {
+			return nil, err
+		}
+		result[keys[0]] = masked
+		return result, nil
+	}
+	nested, err := p.maskNested(value, keys[1:], action)
+	if err != nil {
+		return nil, err
+	}
+	result[keys[0]] = nested
+	return result, nil
+}
+
+func (p *MaskingPolicy) maskNested(value any, keys []string, action MaskAction) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		return p.maskPath(v, keys, action)
+	case []any:
+		elements := make([]any, len(v))
+		for i, element := range v {
+			masked, err := p.maskNested(element, keys, action)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = masked
+		}
+		return elements, nil
+	}
+	return value, nil
+}
+
+func (p *MaskingPolicy) maskValue(value any, action MaskAction) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if action == MaskRedact {
+		return Redacted, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
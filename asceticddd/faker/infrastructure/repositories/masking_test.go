@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/utils/testutils"
+)
+
+func TestMaskingPolicy(t *testing.T) {
+	s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+	policy := NewMaskingPolicy([]byte("secret"))
+	require.NoError(t, policy.AddRule("customers", MaskRule{Path: "email", Action: MaskHash}))
+	require.NoError(t, policy.AddRule("customers", MaskRule{Path: "contacts.phone", Action: MaskRedact}))
+	require.NoError(t, policy.AddRule("customers", MaskRule{Path: "notes", Action: MaskRemove, Roles: []string{"qa"}}))
+	require.NoError(t, policy.AddRule("customers", MaskRule{
+		Path:   "name",
+		Action: MaskRedact,
+		Where: domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"vip": domainquery.EqOperator{Value: true},
+		}},
+	}))
+
+	state := map[string]any{
+		"id":       1,
+		"name":     "Alice",
+		"vip":      true,
+		"email":    "alice@example.com",
+		"notes":    "likes tea",
+		"contacts": []any{map[string]any{"phone": "+1"}, map[string]any{"phone": nil}, "n/a"},
+	}
+
+	t.Run("role with all rules", func(t *testing.T) {
+		masked, err := policy.Mask(s, "customers", "qa", state)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"id":       1,
+			"name":     Redacted,
+			"vip":      true,
+			"email":    hmacHex("secret", `"alice@example.com"`),
+			"contacts": []any{map[string]any{"phone": Redacted}, map[string]any{"phone": nil}, "n/a"},
+		}, masked)
+		assert.Equal(t, "alice@example.com", state["email"], "state is not modified")
+		assert.Equal(t, "+1", state["contacts"].([]any)[0].(map[string]any)["phone"])
+	})
+
+	t.Run("other role and state", func(t *testing.T) {
+		other := map[string]any{"id": 2, "name": "Bob", "email": "alice@example.com", "notes": "n"}
+		masked, err := policy.Mask(s, "customers", "analyst", other)
+		require.NoError(t, err)
+		assert.Equal(t, "Bob", masked["name"])
+		assert.Equal(t, "n", masked["notes"])
+		first, err := policy.Mask(s, "customers", "qa", state)
+		require.NoError(t, err)
+		assert.Equal(t, first["email"], masked["email"], "hashes are deterministic")
+	})
+
+	t.Run("other table", func(t *testing.T) {
+		masked, err := policy.Mask(s, "orders", "qa", state)
+		require.NoError(t, err)
+		assert.Equal(t, state, masked)
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		assert.Error(t, policy.AddRule("customers", MaskRule{Path: "email", Action: "shuffle"}))
+		assert.Error(t, policy.AddRule("customers", MaskRule{Action: MaskRedact}))
+	})
+}
+
+func TestDocumentTransferExportMasked(t *testing.T) {
+	s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+	store := NewInMemoryDocumentStore("", nil)
+	_, err := store.SaveMany(s, "customers", []map[string]any{
+		{"id": 1, "name": "Alice", "email": "alice@example.com"},
+	}, OnConflictError)
+	require.NoError(t, err)
+	policy := NewMaskingPolicy(nil)
+	require.NoError(t, policy.AddRule("customers", MaskRule{Path: "email", Action: MaskRemove, Roles: []string{"staging"}}))
+
+	transfer := NewDocumentTransfer(store)
+	transfer.SetMasking(policy, "staging")
+	var out bytes.Buffer
+	_, err = transfer.Export(s, "customers", nil, &out, FormatNDJSON)
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":1,"name":"Alice"}`+"\n", out.String())
+	assert.Equal(t, "alice@example.com", store.tables["customers"]["1"]["email"])
+}
+
+func hmacHex(key, data string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,339 @@
+package repositories
+
+import (
+	dbsql "database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/infrastructure/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+)
+
+const (
+	defaultPkColumn  = "value_id"
+	defaultIdKey     = "id"
+	defaultBatchSize = 1000
+)
+
+// OnConflict selects what SaveMany does with a state whose id is already stored.
+type OnConflict int
+
+const (
+	// OnConflictError fails the whole batch with the unique violation of the database.
+	OnConflictError OnConflict = iota
+	// OnConflictDoNothing keeps the stored state.
+	OnConflictDoNothing
+	// OnConflictMerge merges the new state into the stored one, top-level keys of the new state win.
+	OnConflictMerge
+)
+
+type SaveOutcome string
+
+const (
+	SaveInserted SaveOutcome = "inserted"
+	SaveUpdated  SaveOutcome = "updated"
+	SaveSkipped  SaveOutcome = "skipped"
+)
+
+type SaveResult struct {
+	Id      any
+	Outcome SaveOutcome
+}
+
+// PgDocumentStore persists states into the Pg document store,
+// i.e. tables with a jsonb primary key column and a jsonb "value" column.
+// The primary key is taken from the idKey of the state.
+type PgDocumentStore struct {
+	pkColumn        string
+	idKey           string
+	batchSize       int
+	compilerFactory func() *query.PgQueryCompiler
+}
+
+func NewPgDocumentStore(pkColumn string, idKey string) *PgDocumentStore {
+	if pkColumn == "" {
+		pkColumn = defaultPkColumn
+	}
+	if idKey == "" {
+		idKey = defaultIdKey
+	}
+	return &PgDocumentStore{
+		pkColumn:  pkColumn,
+		idKey:     idKey,
+		batchSize: defaultBatchSize,
+		compilerFactory: func() *query.PgQueryCompiler {
+			return query.NewPgQueryCompiler("", nil, nil)
+		},
+	}
+}
+
+// SetCompilerFactory sets the factory of compilers for spec queries,
+// e.g. to configure relation resolver and field types.
+func (s *PgDocumentStore) SetCompilerFactory(factory func() *query.PgQueryCompiler) {
+	s.compilerFactory = factory
+}
+
+// SetBatchSize sets the maximum number of rows of one INSERT statement.
+func (s *PgDocumentStore) SetBatchSize(batchSize int) {
+	s.batchSize = batchSize
+}
+
+// SaveMany inserts the states with multi-row INSERT ... ON CONFLICT statements
+// and returns the outcome of each state in the order of states.
+// Ids must be unique within the call.
+func (s *PgDocumentStore) SaveMany(
+	sess session.Session, table string, states []map[string]any, onConflict OnConflict,
+) ([]SaveResult, error) {
+	results := make([]SaveResult, len(states))
+	positions := make(map[string]int, len(states))
+	for i, state := range states {
+		id, ok := state[s.idKey]
+		if !ok || id == nil {
+			return nil, fmt.Errorf("state %d has no %q", i, s.idKey)
+		}
+		key, err := json.Marshal(id)
+		if err != nil {
+			return nil, err
+		}
+		if prev, ok := positions[string(key)]; ok {
+			return nil, fmt.Errorf("states %d and %d have the same id %s", prev, i, key)
+		}
+		positions[string(key)] = i
+		results[i] = SaveResult{Id: id, Outcome: SaveSkipped}
+	}
+
+	batchSize := s.batchSize
+	if batchSize < 1 {
+		batchSize = defaultBatchSize
+	}
+	for start := 0; start < len(states); start += batchSize {
+		end := min(start+batchSize, len(states))
+		if err := s.saveBatch(sess, table, states[start:end], onConflict, positions, results); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+func (s *PgDocumentStore) saveBatch(
+	sess session.Session, table string, states []map[string]any, onConflict OnConflict,
+	positions map[string]int, results []SaveResult,
+) error {
+	values := make([]string, 0, len(states))
+	params := make([]any, 0, len(states)*2)
+	for _, state := range states {
+		id, err := json.Marshal(state[s.idKey])
+		if err != nil {
+			return err
+		}
+		value, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		params = append(params, string(id), string(value))
+		values = append(values, fmt.Sprintf("($%d::jsonb, $%d::jsonb)", len(params)-1, len(params)))
+	}
+
+	sql, err := s.insertSql(table, values, onConflict)
+	if err != nil {
+		return err
+	}
+	rows, err := sess.(session.DbSession).Connection().Query(sql, params...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pkBytes []byte
+		var inserted bool
+		if err := rows.Scan(&pkBytes, &inserted); err != nil {
+			return err
+		}
+		key, err := query.CanonicalJson(pkBytes)
+		if err != nil {
+			return err
+		}
+		i, ok := positions[key]
+		if !ok {
+			continue
+		}
+		if inserted {
+			results[i].Outcome = SaveInserted
+		} else {
+			results[i].Outcome = SaveUpdated
+		}
+	}
+	return rows.Err()
+}
+
+// insertSql uses xmax = 0 to tell inserted rows from updated ones.
+func (s *PgDocumentStore) insertSql(table string, values []string, onConflict OnConflict) (string, error) {
+	var sql strings.Builder
+	sql.WriteString(fmt.Sprintf(
+		"INSERT INTO %s (%s, value) VALUES %s", table, s.pkColumn, strings.Join(values, ", "),
+	))
+	switch onConflict {
+	case OnConflictError:
+	case OnConflictDoNothing:
+		sql.WriteString(fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", s.pkColumn))
+	case OnConflictMerge:
+		sql.WriteString(fmt.Sprintf(
+			" ON CONFLICT (%s) DO UPDATE SET value = %s.value || EXCLUDED.value", s.pkColumn, table,
+		))
+	default:
+		return "", fmt.Errorf("unknown conflict strategy %d", onConflict)
+	}
+	sql.WriteString(fmt.Sprintf(" RETURNING %s, (xmax = 0)", s.pkColumn))
+	return sql.String(), nil
+}
+
+// CountBySpec returns the number of rows matching spec.Query.
+// Ordering and pagination of the spec are ignored.
+func (s *PgDocumentStore) CountBySpec(sess session.Session, table string, spec domainquery.QuerySpec) (int, error) {
+	sql, params, err := s.compilerFactory().CompileCount(table, spec.Query)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	err = s.queryScalar(sess, sql, params, &count)
+	return count, err
+}
+
+// ExistsBySpec reports whether any row matches spec.Query.
+// Ordering and pagination of the spec are ignored.
+func (s *PgDocumentStore) ExistsBySpec(sess session.Session, table string, spec domainquery.QuerySpec) (bool, error) {
+	sql, params, err := s.compilerFactory().CompileExists(table, spec.Query)
+	if err != nil {
+		return false, err
+	}
+	var exists bool
+	err = s.queryScalar(sess, sql, params, &exists)
+	return exists, err
+}
+
+// Aggregate returns the groups of rows matching aggregation.Query, see domainquery.Aggregation.
+func (s *PgDocumentStore) Aggregate(
+	sess session.Session, table string, aggregation domainquery.Aggregation,
+) ([]domainquery.AggregateRow, error) {
+	sql, params, err := s.compilerFactory().CompileAggregation(table, aggregation)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := sess.(session.DbSession).Connection().Query(sql, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domainquery.AggregateRow
+	for rows.Next() {
+		keys := make([]dbsql.NullString, len(aggregation.GroupBy))
+		counts := make([]int64, len(aggregation.Aggregates))
+		sums := make([]dbsql.NullFloat64, len(aggregation.Aggregates))
+		dest := make([]any, 0, len(keys)+len(aggregation.Aggregates))
+		for i := range keys {
+			dest = append(dest, &keys[i])
+		}
+		for i, aggregate := range aggregation.Aggregates {
+			if aggregate.Func == domainquery.AggregateCount {
+				dest = append(dest, &counts[i])
+			} else {
+				dest = append(dest, &sums[i])
+			}
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		row := domainquery.AggregateRow{
+			Key:    make([]any, len(keys)),
+			Values: make(map[string]any, len(aggregation.Aggregates)),
+		}
+		for i, key := range keys {
+			if key.Valid {
+				row.Key[i] = key.String
+			}
+		}
+		for i, aggregate := range aggregation.Aggregates {
+			switch {
+			case aggregate.Func == domainquery.AggregateCount:
+				row.Values[aggregate.Name()] = int(counts[i])
+			case sums[i].Valid:
+				row.Values[aggregate.Name()] = sums[i].Float64
+			default:
+				row.Values[aggregate.Name()] = nil
+			}
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// FindBySpecification returns the states of rows matching the specification in the order of primary keys.
+// The specification is compiled directly to SQL over the value column, see query.SpecToSqlVisitor.
+// Nil specification matches all rows.
+func (s *PgDocumentStore) FindBySpecification(
+	sess session.Session, table string, specification spec.Visitable,
+) ([]map[string]any, error) {
+	var states []map[string]any
+	err := s.FindEachBySpecification(sess, table, specification, func(state map[string]any) error {
+		states = append(states, state)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// FindEachBySpecification calls fn with each state matching the specification like FindBySpecification,
+// streaming the rows instead of loading them at once. It stops at the first error of fn.
+func (s *PgDocumentStore) FindEachBySpecification(
+	sess session.Session, table string, specification spec.Visitable, fn func(state map[string]any) error,
+) error {
+	var where string
+	var params []any
+	if specification != nil {
+		sql, specParams, err := query.SpecToSql(specification)
+		if err != nil {
+			return err
+		}
+		where, params = " WHERE "+sql, specParams
+	}
+	sql := fmt.Sprintf("SELECT value FROM %s%s ORDER BY %s", table, where, s.pkColumn)
+	rows, err := sess.(session.DbSession).Connection().Query(sql, params...)
+	if err != nil {
+		return err
+	}
+	return eachState(newStateIterator(rows, scanDocument), fn)
+}
+
+func scanDocument(rows session.Rows) (map[string]any, error) {
+	var value []byte
+	if err := rows.Scan(&value); err != nil {
+		return nil, err
+	}
+	var state map[string]any
+	if err := json.Unmarshal(value, &state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (s *PgDocumentStore) queryScalar(sess session.Session, sql string, params []any, dest any) error {
+	rows, err := sess.(session.DbSession).Connection().Query(sql, params...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err := rows.Scan(dest); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
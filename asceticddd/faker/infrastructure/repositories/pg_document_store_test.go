@@ -0,0 +1,241 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/infrastructure/query"
+	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/utils/testutils"
+)
+
+func TestPgDocumentStoreSaveMany(t *testing.T) {
+	states := []map[string]any{
+		{"id": 1, "name": "Acme"},
+		{"id": 2, "name": "Globex"},
+		{"id": 3, "name": "Initech"},
+	}
+
+	t.Run("error strategy", func(t *testing.T) {
+		store := NewPgDocumentStore("", "")
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub(
+			[]any{[]byte(`1`), true},
+			[]any{[]byte(`2`), true},
+			[]any{[]byte(`3`), true},
+		))
+		results, err := store.SaveMany(s, "companies", states, OnConflictError)
+		require.NoError(t, err)
+		assert.Equal(t,
+			"INSERT INTO companies (value_id, value) VALUES "+
+				"($1::jsonb, $2::jsonb), ($3::jsonb, $4::jsonb), ($5::jsonb, $6::jsonb) "+
+				"RETURNING value_id, (xmax = 0)",
+			s.ActualQuery,
+		)
+		assert.Equal(t, []any{
+			`1`, `{"id":1,"name":"Acme"}`,
+			`2`, `{"id":2,"name":"Globex"}`,
+			`3`, `{"id":3,"name":"Initech"}`,
+		}, s.ActualParams)
+		assert.Equal(t, []SaveResult{
+			{Id: 1, Outcome: SaveInserted},
+			{Id: 2, Outcome: SaveInserted},
+			{Id: 3, Outcome: SaveInserted},
+		}, results)
+	})
+
+	t.Run("do nothing reports skipped rows", func(t *testing.T) {
+		store := NewPgDocumentStore("", "")
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub(
+			[]any{[]byte(`3`), true},
+			[]any{[]byte(`1`), true},
+		))
+		results, err := store.SaveMany(s, "companies", states, OnConflictDoNothing)
+		require.NoError(t, err)
+		assert.Contains(t, s.ActualQuery, " ON CONFLICT (value_id) DO NOTHING RETURNING ")
+		assert.Equal(t, []SaveResult{
+			{Id: 1, Outcome: SaveInserted},
+			{Id: 2, Outcome: SaveSkipped},
+			{Id: 3, Outcome: SaveInserted},
+		}, results)
+	})
+
+	t.Run("merge reports updated rows", func(t *testing.T) {
+		store := NewPgDocumentStore("", "")
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub(
+			[]any{[]byte(`1`), false},
+			[]any{[]byte(`2`), true},
+			[]any{[]byte(`3`), false},
+		))
+		results, err := store.SaveMany(s, "companies", states, OnConflictMerge)
+		require.NoError(t, err)
+		assert.Contains(t, s.ActualQuery,
+			" ON CONFLICT (value_id) DO UPDATE SET value = companies.value || EXCLUDED.value RETURNING ",
+		)
+		assert.Equal(t, []SaveResult{
+			{Id: 1, Outcome: SaveUpdated},
+			{Id: 2, Outcome: SaveInserted},
+			{Id: 3, Outcome: SaveUpdated},
+		}, results)
+	})
+
+	t.Run("large int ids", func(t *testing.T) {
+		store := NewPgDocumentStore("", "")
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub(
+			[]any{[]byte(`9007199254740993`), true},
+		))
+		results, err := store.SaveMany(s, "companies", []map[string]any{
+			{"id": int64(9007199254740993)}, {"id": int64(9007199254740992)},
+		}, OnConflictDoNothing)
+		require.NoError(t, err)
+		assert.Equal(t, []SaveResult{
+			{Id: int64(9007199254740993), Outcome: SaveInserted},
+			{Id: int64(9007199254740992), Outcome: SaveSkipped},
+		}, results)
+	})
+
+	t.Run("custom pk column and id key", func(t *testing.T) {
+		store := NewPgDocumentStore("pk", "code")
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub(
+			[]any{[]byte(`"a"`), true},
+		))
+		results, err := store.SaveMany(s, "items", []map[string]any{{"code": "a"}}, OnConflictError)
+		require.NoError(t, err)
+		assert.Equal(t,
+			"INSERT INTO items (pk, value) VALUES ($1::jsonb, $2::jsonb) RETURNING pk, (xmax = 0)",
+			s.ActualQuery,
+		)
+		assert.Equal(t, []SaveResult{{Id: "a", Outcome: SaveInserted}}, results)
+	})
+
+	t.Run("batches", func(t *testing.T) {
+		store := NewPgDocumentStore("", "")
+		store.SetBatchSize(2)
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+		_, err := store.SaveMany(s, "companies", states, OnConflictError)
+		require.NoError(t, err)
+		assert.Equal(t,
+			"INSERT INTO companies (value_id, value) VALUES ($1::jsonb, $2::jsonb) RETURNING value_id, (xmax = 0)",
+			s.ActualQuery,
+		)
+		assert.Equal(t, []any{`3`, `{"id":3,"name":"Initech"}`}, s.ActualParams)
+	})
+
+	t.Run("missing id", func(t *testing.T) {
+		store := NewPgDocumentStore("", "")
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+		_, err := store.SaveMany(s, "companies", []map[string]any{{"name": "Acme"}}, OnConflictError)
+		assert.Error(t, err)
+		assert.Equal(t, "", s.ActualQuery)
+	})
+
+	t.Run("duplicate ids", func(t *testing.T) {
+		store := NewPgDocumentStore("", "")
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+		_, err := store.SaveMany(s, "companies", []map[string]any{{"id": 1}, {"id": 1}}, OnConflictMerge)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "same id")
+	})
+
+	t.Run("unknown strategy", func(t *testing.T) {
+		store := NewPgDocumentStore("", "")
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+		_, err := store.SaveMany(s, "companies", states, OnConflict(42))
+		assert.Error(t, err)
+	})
+}
+
+func TestPgDocumentStoreCountAndExists(t *testing.T) {
+	spec := domainquery.QuerySpec{
+		Query: domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"status": domainquery.EqOperator{Value: "active"},
+		}},
+		OrderBy: []domainquery.OrderClause{{Path: "name"}},
+		Limit:   10,
+	}
+
+	t.Run("count", func(t *testing.T) {
+		store := NewPgDocumentStore("", "")
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub([]any{int64(3)}))
+		count, err := store.CountBySpec(s, "companies", spec)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, "SELECT count(*) FROM companies WHERE value @> $1", s.ActualQuery)
+		assert.Equal(t, []any{query.Jsonb{Obj: map[string]any{"status": "active"}}}, s.ActualParams)
+	})
+
+	t.Run("count all", func(t *testing.T) {
+		store := NewPgDocumentStore("", "")
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub([]any{int64(5)}))
+		count, err := store.CountBySpec(s, "companies", domainquery.QuerySpec{})
+		require.NoError(t, err)
+		assert.Equal(t, 5, count)
+		assert.Equal(t, "SELECT count(*) FROM companies", s.ActualQuery)
+	})
+
+	t.Run("exists", func(t *testing.T) {
+		store := NewPgDocumentStore("", "")
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub([]any{true}))
+		exists, err := store.ExistsBySpec(s, "companies", spec)
+		require.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, "SELECT EXISTS(SELECT 1 FROM companies WHERE value @> $1)", s.ActualQuery)
+	})
+
+	t.Run("compiler factory", func(t *testing.T) {
+		store := NewPgDocumentStore("", "")
+		store.SetCompilerFactory(func() *query.PgQueryCompiler {
+			compiler := query.NewPgQueryCompiler("", nil, nil)
+			compiler.SetFieldTypes(domainquery.FieldTypes{"status": domainquery.TypeHintText})
+			return compiler
+		})
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub([]any{false}))
+		exists, err := store.ExistsBySpec(s, "companies", spec)
+		require.NoError(t, err)
+		assert.False(t, exists)
+		assert.Equal(t, "SELECT EXISTS(SELECT 1 FROM companies WHERE value->>'status' = $1)", s.ActualQuery)
+	})
+}
+
+func TestPgDocumentStoreFindBySpecification(t *testing.T) {
+	store := NewPgDocumentStore("", "")
+	s := testutils.NewDbSessionStub(testutils.NewRowsStub(
+		[]any{[]byte(`{"id":1,"status":"active","spent":5,"budget":10}`)},
+	))
+	root := spec.GlobalScope()
+	states, err := store.FindBySpecification(s, "companies", spec.And(
+		spec.Equal(spec.Field(root, "status"), spec.Value("active")),
+		spec.LessThan(spec.Field(root, "spent"), spec.Field(root, "budget")),
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{
+		{"id": 1.0, "status": "active", "spent": 5.0, "budget": 10.0},
+	}, states)
+	assert.Equal(t,
+		"SELECT value FROM companies WHERE value->'status' = $1 AND value->'spent' < value->'budget' ORDER BY value_id",
+		s.ActualQuery,
+	)
+	assert.Equal(t, []any{query.Jsonb{Obj: "active"}}, s.ActualParams)
+}
+
+func TestPgDocumentStoreAggregate(t *testing.T) {
+	store := NewPgDocumentStore("", "")
+	s := testutils.NewDbSessionStub(testutils.NewRowsStub(
+		[]any{"active", int64(2), 30.5},
+		[]any{nil, int64(1), nil},
+	))
+	rows, err := store.Aggregate(s, "orders", domainquery.GroupBy("status").Count().Sum("amount"))
+	require.NoError(t, err)
+	assert.Equal(t,
+		"SELECT value->>'status', count(*), "+
+			"sum(CASE WHEN jsonb_typeof(value->'amount') = 'number' THEN (value->>'amount')::numeric END) "+
+			"FROM orders GROUP BY 1 ORDER BY 1",
+		s.ActualQuery,
+	)
+	assert.Equal(t, []domainquery.AggregateRow{
+		{Key: []any{"active"}, Values: map[string]any{"count": 2, "sum(amount)": 30.5}},
+		{Key: []any{nil}, Values: map[string]any{"count": 1, "sum(amount)": nil}},
+	}, rows)
+}
@@ -0,0 +1,226 @@
+package repositories
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/infrastructure/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+const defaultMigrationsTable = "faker_migrations"
+
+// PgDocumentTable creates a table of the Pg document store and migrates it:
+//
+//	CREATE TABLE companies (
+//		value_id jsonb PRIMARY KEY,
+//		value jsonb NOT NULL
+//	);
+//	CREATE INDEX companies_value_gin_idx ON companies USING GIN (value jsonb_path_ops);
+//
+// with the version column of PgVersionedRepository and the soft delete column when they are set.
+//
+// Migrations are numbered from 1 in the order of AddMigration and applied once,
+// their versions are tracked per table in the migrations table, "faker_migrations" by default.
+//
+// Paths with type hints, see SetFieldTypes, get expression indexes on the typed expressions
+// the compiler emits, e.g. ((value->>'age')::numeric). Datetime hints get no index,
+// because the cast of text to timestamptz depends on the time zone and is not allowed in indexes.
+type PgDocumentTable struct {
+	table            string
+	pkColumn         string
+	versionColumn    string
+	softDeleteColumn string
+	migrationsTable  string
+	fieldTypes       domainquery.FieldTypes
+	migrations       []string
+}
+
+func NewPgDocumentTable(table, pkColumn string) *PgDocumentTable {
+	if pkColumn == "" {
+		pkColumn = defaultPkColumn
+	}
+	return &PgDocumentTable{
+		table:           table,
+		pkColumn:        pkColumn,
+		migrationsTable: defaultMigrationsTable,
+	}
+}
+
+// SetVersionColumn adds the integer version column of PgVersionedRepository, empty column omits it.
+func (t *PgDocumentTable) SetVersionColumn(versionColumn string) {
+	t.versionColumn = versionColumn
+}
+
+// SetSoftDeleteColumn adds the timestamptz column of soft delete, empty column omits it.
+func (t *PgDocumentTable) SetSoftDeleteColumn(softDeleteColumn string) {
+	t.softDeleteColumn = softDeleteColumn
+}
+
+// SetMigrationsTable sets the table tracking applied migrations, "faker_migrations" by default.
+func (t *PgDocumentTable) SetMigrationsTable(migrationsTable string) {
+	t.migrationsTable = migrationsTable
+}
+
+// SetFieldTypes sets type hints of the paths to index, the same as PgQueryCompiler.SetFieldTypes.
+func (t *PgDocumentTable) SetFieldTypes(fieldTypes domainquery.FieldTypes) {
+	t.fieldTypes = fieldTypes
+}
+
+// AddMigration registers the SQL of the next migration version and returns the version.
+// Never change or reorder applied migrations.
+func (t *PgDocumentTable) AddMigration(sql string) int {
+	t.migrations = append(t.migrations, sql)
+	return len(t.migrations)
+}
+
+// IndexedFields returns the paths covered by the expression indexes of Setup,
+// for PgQueryCompiler.SetIndexedFields.
+func (t *PgDocumentTable) IndexedFields() query.IndexedFields {
+	indexedFields := query.IndexedFields{}
+	for _, index := range t.fieldIndexes() {
+		indexedFields[index.path] = true
+	}
+	return indexedFields
+}
+
+// Setup creates the table, its indexes and the migrations table if they do not exist,
+// and applies pending migrations. It is idempotent and safe to run from concurrent processes,
+// which are serialized with an advisory lock on the table name.
+func (t *PgDocumentTable) Setup(s session.Session) error {
+	if err := t.createMigrationsTable(s); err != nil {
+		return err
+	}
+	return s.Atomic(func(txSession session.Session) error {
+		conn := txSession.(session.DbSession).Connection()
+		if _, err := conn.Exec("SELECT pg_advisory_xact_lock(hashtext($1))", t.table); err != nil {
+			return err
+		}
+		if err := t.createTable(txSession); err != nil {
+			return err
+		}
+		for _, index := range t.fieldIndexes() {
+			sql := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s ((%s))", index.name, t.table, index.expr)
+			if _, err := conn.Exec(sql); err != nil {
+				return err
+			}
+		}
+		return t.migrate(txSession)
+	})
+}
+
+// Version returns the last applied migration version of the table, 0 if none.
+func (t *PgDocumentTable) Version(s session.Session) (int, error) {
+	sql := fmt.Sprintf(`
+		SELECT COALESCE(MAX(version), 0)
+		FROM %s
+		WHERE table_name = $1
+	`, t.migrationsTable)
+
+	rows, err := s.(session.DbSession).Connection().Query(sql, t.table)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var version int
+	if rows.Next() {
+		if err := rows.Scan(&version); err != nil {
+			return 0, err
+		}
+	}
+	return version, rows.Err()
+}
+
+func (t *PgDocumentTable) migrate(s session.Session) error {
+	applied, err := t.Version(s)
+	if err != nil {
+		return err
+	}
+	conn := s.(session.DbSession).Connection()
+	for version := applied + 1; version <= len(t.migrations); version++ {
+		if _, err := conn.Exec(t.migrations[version-1]); err != nil {
+			return fmt.Errorf("migration %d of %s: %w", version, t.table, err)
+		}
+		insertSql := fmt.Sprintf(
+			"INSERT INTO %s (table_name, version) VALUES ($1, $2)", t.migrationsTable,
+		)
+		if _, err := conn.Exec(insertSql, t.table, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *PgDocumentTable) createTable(s session.Session) error {
+	columns := []string{
+		fmt.Sprintf("%s jsonb PRIMARY KEY", t.pkColumn),
+		"value jsonb NOT NULL",
+	}
+	if t.versionColumn != "" {
+		columns = append(columns, fmt.Sprintf("%s integer NOT NULL DEFAULT 1", t.versionColumn))
+	}
+	if t.softDeleteColumn != "" {
+		columns = append(columns, fmt.Sprintf("%s timestamptz", t.softDeleteColumn))
+	}
+
+	statements := []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n\t%s\n)", t.table, strings.Join(columns, ",\n\t")),
+		fmt.Sprintf(
+			"CREATE INDEX IF NOT EXISTS %s_value_gin_idx ON %s USING GIN (value jsonb_path_ops)",
+			t.table, t.table,
+		),
+	}
+	conn := s.(session.DbSession).Connection()
+	for _, sql := range statements {
+		if _, err := conn.Exec(sql); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *PgDocumentTable) createMigrationsTable(s session.Session) error {
+	sql := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			table_name VARCHAR(255) NOT NULL,
+			version INTEGER NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (table_name, version)
+		)
+	`, t.migrationsTable)
+
+	_, err := s.(session.DbSession).Connection().Exec(sql)
+	return err
+}
+
+type fieldIndex struct {
+	path string
+	name string
+	expr string
+}
+
+// fieldIndexes returns the expression indexes of hinted paths in the order of paths.
+func (t *PgDocumentTable) fieldIndexes() []fieldIndex {
+	paths := make([]string, 0, len(t.fieldTypes))
+	for path := range t.fieldTypes {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	var indexes []fieldIndex
+	for _, path := range paths {
+		expr, ok := query.IndexablePathExpr("value", path, t.fieldTypes[path])
+		if !ok {
+			continue
+		}
+		indexes = append(indexes, fieldIndex{
+			path: path,
+			name: fmt.Sprintf("%s_%s_idx", t.table, strings.ReplaceAll(path, ".", "_")),
+			expr: expr,
+		})
+	}
+	return indexes
+}
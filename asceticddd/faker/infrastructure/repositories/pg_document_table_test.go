@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/infrastructure/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/utils/testutils"
+)
+
+func TestPgDocumentTableSetup(t *testing.T) {
+	t.Run("creates table and indexes", func(t *testing.T) {
+		table := NewPgDocumentTable("companies", "")
+		table.SetVersionColumn("version")
+		table.SetFieldTypes(domainquery.FieldTypes{
+			"age":          domainquery.TypeHintNumeric,
+			"address.city": domainquery.TypeHintText,
+			"founded_at":   domainquery.TypeHintDatetime,
+			"items[*].sku": domainquery.TypeHintText,
+		})
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub([]any{0}))
+
+		require.NoError(t, table.Setup(s))
+		require.Len(t, s.ActualQueries, 7)
+		assert.Contains(t, s.ActualQueries[0], "CREATE TABLE IF NOT EXISTS faker_migrations")
+		assert.Equal(t, "SELECT pg_advisory_xact_lock(hashtext($1))", s.ActualQueries[1])
+		assert.Equal(t,
+			"CREATE TABLE IF NOT EXISTS companies (\n"+
+				"\tvalue_id jsonb PRIMARY KEY,\n"+
+				"\tvalue jsonb NOT NULL,\n"+
+				"\tversion integer NOT NULL DEFAULT 1\n"+
+				")",
+			s.ActualQueries[2],
+		)
+		assert.Equal(t,
+			"CREATE INDEX IF NOT EXISTS companies_value_gin_idx ON companies USING GIN (value jsonb_path_ops)",
+			s.ActualQueries[3],
+		)
+		assert.Equal(t,
+			"CREATE INDEX IF NOT EXISTS companies_address_city_idx ON companies ((value->'address'->>'city'))",
+			s.ActualQueries[4],
+		)
+		assert.Equal(t,
+			"CREATE INDEX IF NOT EXISTS companies_age_idx ON companies (((value->>'age')::numeric))",
+			s.ActualQueries[5],
+		)
+		assert.Contains(t, s.ActualQueries[6], "SELECT COALESCE(MAX(version), 0)")
+	})
+
+	t.Run("applies pending migrations", func(t *testing.T) {
+		table := NewPgDocumentTable("companies", "")
+		table.SetMigrationsTable("schema_versions")
+		assert.Equal(t, 1, table.AddMigration("ALTER TABLE companies ADD COLUMN tenant text"))
+		assert.Equal(t, 2, table.AddMigration("CREATE INDEX companies_tenant_idx ON companies (tenant)"))
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub([]any{1}))
+
+		require.NoError(t, table.Setup(s))
+		assert.Equal(t, []string{
+			"CREATE INDEX companies_tenant_idx ON companies (tenant)",
+			"INSERT INTO schema_versions (table_name, version) VALUES ($1, $2)",
+		}, s.ActualQueries[len(s.ActualQueries)-2:])
+		assert.Equal(t, []any{"companies", 2}, s.ActualParams)
+	})
+
+	t.Run("skips applied migrations", func(t *testing.T) {
+		table := NewPgDocumentTable("companies", "")
+		table.AddMigration("ALTER TABLE companies ADD COLUMN tenant text")
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub([]any{1}))
+
+		require.NoError(t, table.Setup(s))
+		assert.Contains(t, s.ActualQuery, "SELECT COALESCE(MAX(version), 0)")
+	})
+}
+
+func TestPgDocumentTableIndexedFields(t *testing.T) {
+	table := NewPgDocumentTable("companies", "")
+	table.SetFieldTypes(domainquery.FieldTypes{
+		"age":        domainquery.TypeHintNumeric,
+		"founded_at": domainquery.TypeHintDatetime,
+	})
+	assert.Equal(t, query.IndexedFields{"age": true}, table.IndexedFields())
+}
@@ -0,0 +1,478 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/infrastructure/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+const (
+	defaultVersionColumn = "version"
+	defaultVersionKey    = "_version"
+)
+
+var ErrConcurrentModification = errors.New("concurrent modification")
+
+// ConcurrentModificationError is returned by Save when the stored version differs from the expected one,
+// it matches ErrConcurrentModification with errors.Is.
+type ConcurrentModificationError struct {
+	Table           string
+	Id              any
+	ExpectedVersion int
+}
+
+func (e *ConcurrentModificationError) Error() string {
+	return fmt.Sprintf(
+		"%v of %s with id %v: expected version %d", ErrConcurrentModification, e.Table, e.Id, e.ExpectedVersion,
+	)
+}
+
+func (e *ConcurrentModificationError) Unwrap() error {
+	return ErrConcurrentModification
+}
+
+// PgVersionedRepository keeps states in a table of the Pg document store
+// with an integer version column for optimistic concurrency:
+//
+//	CREATE TABLE companies (
+//		value_id jsonb PRIMARY KEY,
+//		value jsonb NOT NULL,
+//		version integer NOT NULL
+//	);
+//
+// The version is not stored in the value, Get injects it into the state under versionKey,
+// and SaveState extracts it from there.
+//
+// With SetSoftDeleteColumn, Delete sets the timestamptz column to now() instead of deleting the row,
+// and Get and Find add "deleted_at IS NULL" to the WHERE clause, see WithDeleted.
+type PgVersionedRepository struct {
+	table            string
+	pkColumn         string
+	versionColumn    string
+	idKey            string
+	versionKey       string
+	softDeleteColumn string
+	withDeleted      bool
+	batchSize        int
+	tracer           domainquery.IQueryTracer
+}
+
+func NewPgVersionedRepository(table, pkColumn, idKey string) *PgVersionedRepository {
+	if pkColumn == "" {
+		pkColumn = defaultPkColumn
+	}
+	if idKey == "" {
+		idKey = defaultIdKey
+	}
+	return &PgVersionedRepository{
+		table:         table,
+		pkColumn:      pkColumn,
+		versionColumn: defaultVersionColumn,
+		idKey:         idKey,
+		versionKey:    defaultVersionKey,
+		batchSize:     defaultBatchSize,
+	}
+}
+
+// SetVersionColumn sets the name of the version column, "version" by default.
+func (r *PgVersionedRepository) SetVersionColumn(versionColumn string) {
+	r.versionColumn = versionColumn
+}
+
+// SetVersionKey sets the key of the version in states, "_version" by default.
+func (r *PgVersionedRepository) SetVersionKey(versionKey string) {
+	r.versionKey = versionKey
+}
+
+// SetBatchSize sets the maximum number of rows of one INSERT statement of SaveMany.
+func (r *PgVersionedRepository) SetBatchSize(batchSize int) {
+	r.batchSize = batchSize
+}
+
+// SetSoftDeleteColumn enables soft delete with the column of the deletion time, e.g. "deleted_at".
+// Empty column disables soft delete.
+func (r *PgVersionedRepository) SetSoftDeleteColumn(softDeleteColumn string) {
+	r.softDeleteColumn = softDeleteColumn
+}
+
+// SetTracer traces Find, FindEach and FindIter with the compilation of their queries,
+// see domainquery.IQueryTracer. FindIter is traced until the rows are queried.
+func (r *PgVersionedRepository) SetTracer(tracer domainquery.IQueryTracer) {
+	r.tracer = tracer
+}
+
+// WithDeleted returns the repository of the same table which does not skip soft-deleted rows.
+func (r *PgVersionedRepository) WithDeleted() *PgVersionedRepository {
+	view := *r
+	view.withDeleted = true
+	return &view
+}
+
+// Get returns the state with its version under versionKey, or ErrStateNotFound.
+func (r *PgVersionedRepository) Get(sess session.Session, id any) (map[string]any, error) {
+	pk, err := json.Marshal(id)
+	if err != nil {
+		return nil, err
+	}
+	states, err := r.find(sess, fmt.Sprintf("%s = $1::jsonb", r.pkColumn), []any{string(pk)}, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(states) == 0 {
+		return nil, fmt.Errorf("%w: %v", ErrStateNotFound, id)
+	}
+	return states[0], nil
+}
+
+// Find returns the states matching the query in the order of primary keys, with their versions.
+// Nil query matches all states.
+func (r *PgVersionedRepository) Find(sess session.Session, q domainquery.IQueryOperator) ([]map[string]any, error) {
+	var states []map[string]any
+	err := r.FindEach(sess, q, func(state map[string]any) error {
+		states = append(states, state)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// Delete deletes the row, or marks it as deleted in soft delete mode.
+// It returns ErrStateNotFound if there is no such row.
+func (r *PgVersionedRepository) Delete(sess session.Session, id any) error {
+	pk, err := json.Marshal(id)
+	if err != nil {
+		return err
+	}
+	var sql string
+	if r.softDeleteColumn == "" {
+		sql = fmt.Sprintf("DELETE FROM %s WHERE %s = $1::jsonb", r.table, r.pkColumn)
+	} else {
+		sql = fmt.Sprintf(
+			"UPDATE %s SET %s = now() WHERE %s = $1::jsonb AND %s IS NULL",
+			r.table, r.softDeleteColumn, r.pkColumn, r.softDeleteColumn,
+		)
+	}
+	rows, err := sess.(session.DbSession).Connection().Query(sql+" RETURNING "+r.pkColumn, string(pk))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrStateNotFound, id)
+	}
+	return rows.Err()
+}
+
+// FindEach calls fn with each state matching the query in the order of primary keys,
+// streaming the rows instead of loading them at once. It stops at the first error of fn.
+// Nil query matches all states.
+func (r *PgVersionedRepository) FindEach(
+	sess session.Session, q domainquery.IQueryOperator, fn func(state map[string]any) error,
+) (err error) {
+	ctx, end := domainquery.TraceQuery(sess.Context(), r.tracer, domainquery.QueryOperationFind, "", q)
+	defer func() { end(err) }()
+	it, err := r.findIter(ctx, sess, q)
+	if err != nil {
+		return err
+	}
+	return eachState(it, fn)
+}
+
+// FindIter returns the iterator over the states matching the query in the order of primary keys.
+// Nil query matches all states.
+func (r *PgVersionedRepository) FindIter(sess session.Session, q domainquery.IQueryOperator) (it *StateIterator, err error) {
+	ctx, end := domainquery.TraceQuery(sess.Context(), r.tracer, domainquery.QueryOperationFind, "", q)
+	defer func() { end(err) }()
+	return r.findIter(ctx, sess, q)
+}
+
+func (r *PgVersionedRepository) findIter(
+	ctx context.Context, sess session.Session, q domainquery.IQueryOperator,
+) (*StateIterator, error) {
+	var where string
+	var params []any
+	if q != nil {
+		compiler := query.NewPgQueryCompiler("", nil, nil)
+		compiler.SetTracer(r.tracer)
+		var err error
+		where, params, err = compiler.CompileContext(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r.iter(sess, where, params, " ORDER BY "+r.pkColumn)
+}
+
+// find selects the states by the condition with $n params, the soft delete condition is added to it.
+func (r *PgVersionedRepository) find(
+	sess session.Session, where string, params []any, orderBy string,
+) ([]map[string]any, error) {
+	it, err := r.iter(sess, where, params, orderBy)
+	if err != nil {
+		return nil, err
+	}
+	var states []map[string]any
+	err = eachState(it, func(state map[string]any) error {
+		states = append(states, state)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+func (r *PgVersionedRepository) iter(
+	sess session.Session, where string, params []any, orderBy string,
+) (*StateIterator, error) {
+	var conditions []string
+	if where != "" {
+		conditions = append(conditions, where)
+	}
+	if r.softDeleteColumn != "" && !r.withDeleted {
+		conditions = append(conditions, fmt.Sprintf("%s IS NULL", r.softDeleteColumn))
+	}
+	sql := fmt.Sprintf("SELECT value, %s FROM %s", r.versionColumn, r.table)
+	if len(conditions) > 0 {
+		sql += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	sql += orderBy
+
+	rows, err := sess.(session.DbSession).Connection().Query(sql, params...)
+	if err != nil {
+		return nil, err
+	}
+	return newStateIterator(rows, r.scanState), nil
+}
+
+func (r *PgVersionedRepository) scanState(rows session.Rows) (map[string]any, error) {
+	var value []byte
+	var version int
+	if err := rows.Scan(&value, &version); err != nil {
+		return nil, err
+	}
+	var state map[string]any
+	if err := json.Unmarshal(value, &state); err != nil {
+		return nil, err
+	}
+	state[r.versionKey] = version
+	return state, nil
+}
+
+// Save stores the state if the stored version equals expectedVersion,
+// 0 means that the state must not be stored yet, and returns the new version.
+// Otherwise it returns *ConcurrentModificationError.
+// The versionKey of the state is not stored and is set to the new version on success.
+func (r *PgVersionedRepository) Save(sess session.Session, id any, state map[string]any, expectedVersion int) (int, error) {
+	pk, err := json.Marshal(id)
+	if err != nil {
+		return 0, err
+	}
+	valueJson, err := r.valueOf(state)
+	if err != nil {
+		return 0, err
+	}
+
+	var sql string
+	params := []any{string(pk), string(valueJson)}
+	if expectedVersion == 0 {
+		sql = fmt.Sprintf(
+			"INSERT INTO %s (%s, value, %s) VALUES ($1::jsonb, $2::jsonb, 1) ON CONFLICT (%s) DO NOTHING RETURNING %s",
+			r.table, r.pkColumn, r.versionColumn, r.pkColumn, r.versionColumn,
+		)
+	} else {
+		sql = fmt.Sprintf(
+			"UPDATE %s SET value = $2::jsonb, %s = %s + 1 WHERE %s = $1::jsonb AND %s = $3",
+			r.table, r.versionColumn, r.versionColumn, r.pkColumn, r.versionColumn,
+		)
+		if r.softDeleteColumn != "" {
+			sql += fmt.Sprintf(" AND %s IS NULL", r.softDeleteColumn)
+		}
+		sql += " RETURNING " + r.versionColumn
+		params = append(params, expectedVersion)
+	}
+
+	rows, err := sess.(session.DbSession).Connection().Query(sql, params...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, &ConcurrentModificationError{Table: r.table, Id: id, ExpectedVersion: expectedVersion}
+	}
+	var version int
+	if err := rows.Scan(&version); err != nil {
+		return 0, err
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	state[r.versionKey] = version
+	return version, nil
+}
+
+// SaveState is Save with the id taken from idKey and the expected version from versionKey,
+// a state without versionKey is a new one.
+func (r *PgVersionedRepository) SaveState(sess session.Session, state map[string]any) (int, error) {
+	id, ok := state[r.idKey]
+	if !ok || id == nil {
+		return 0, fmt.Errorf("state has no %q", r.idKey)
+	}
+	expectedVersion := 0
+	if v, ok := state[r.versionKey]; ok && v != nil {
+		version, ok := versionOf(v)
+		if !ok {
+			return 0, fmt.Errorf("%q is not a version: %v", r.versionKey, v)
+		}
+		expectedVersion = version
+	}
+	return r.Save(sess, id, state, expectedVersion)
+}
+
+// SaveMany upserts the states with multi-row INSERT ... ON CONFLICT DO UPDATE statements
+// of at most batchSize rows, e.g. to load fixtures. The versions are not checked,
+// a stored state is replaced and its version incremented, a new one gets version 1.
+// The ids are taken from idKey and must be unique within the call.
+// SaveMany returns the new versions in the order of states and sets them under versionKey.
+// In soft delete mode deleted states are not replaced, SaveMany returns ErrStateNotFound for them.
+func (r *PgVersionedRepository) SaveMany(sess session.Session, states []map[string]any) ([]int, error) {
+	positions := make(map[string]int, len(states))
+	for i, state := range states {
+		id, ok := state[r.idKey]
+		if !ok || id == nil {
+			return nil, fmt.Errorf("state %d has no %q", i, r.idKey)
+		}
+		key, err := json.Marshal(id)
+		if err != nil {
+			return nil, err
+		}
+		if prev, ok := positions[string(key)]; ok {
+			return nil, fmt.Errorf("states %d and %d have the same id %s", prev, i, key)
+		}
+		positions[string(key)] = i
+	}
+
+	batchSize := r.batchSize
+	if batchSize < 1 {
+		batchSize = defaultBatchSize
+	}
+	versions := make([]int, len(states))
+	for start := 0; start < len(states); start += batchSize {
+		end := min(start+batchSize, len(states))
+		if err := r.saveBatch(sess, states[start:end], positions, versions); err != nil {
+			return nil, err
+		}
+	}
+	for i, state := range states {
+		state[r.versionKey] = versions[i]
+	}
+	return versions, nil
+}
+
+func (r *PgVersionedRepository) saveBatch(
+	sess session.Session, states []map[string]any, positions map[string]int, versions []int,
+) error {
+	values := make([]string, 0, len(states))
+	params := make([]any, 0, len(states)*2)
+	for _, state := range states {
+		pk, err := json.Marshal(state[r.idKey])
+		if err != nil {
+			return err
+		}
+		value, err := r.valueOf(state)
+		if err != nil {
+			return err
+		}
+		params = append(params, string(pk), string(value))
+		values = append(values, fmt.Sprintf("($%d::jsonb, $%d::jsonb, 1)", len(params)-1, len(params)))
+	}
+
+	sql := fmt.Sprintf(
+		"INSERT INTO %s (%s, value, %s) VALUES %s ON CONFLICT (%s) DO UPDATE SET value = EXCLUDED.value, %s = %s.%s + 1",
+		r.table, r.pkColumn, r.versionColumn, strings.Join(values, ", "),
+		r.pkColumn, r.versionColumn, r.table, r.versionColumn,
+	)
+	if r.softDeleteColumn != "" {
+		sql += fmt.Sprintf(" WHERE %s.%s IS NULL", r.table, r.softDeleteColumn)
+	}
+	sql += fmt.Sprintf(" RETURNING %s, %s", r.pkColumn, r.versionColumn)
+
+	rows, err := sess.(session.DbSession).Connection().Query(sql, params...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	saved := 0
+	for rows.Next() {
+		var pk []byte
+		var version int
+		if err := rows.Scan(&pk, &version); err != nil {
+			return err
+		}
+		key, err := query.CanonicalJson(pk)
+		if err != nil {
+			return err
+		}
+		if i, ok := positions[key]; ok {
+			versions[i] = version
+			saved++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if saved < len(states) {
+		for _, state := range states {
+			pk, _ := json.Marshal(state[r.idKey])
+			if versions[positions[string(pk)]] == 0 {
+				return fmt.Errorf("%w: %v", ErrStateNotFound, state[r.idKey])
+			}
+		}
+	}
+	return nil
+}
+
+// valueOf returns the JSON of the state without versionKey.
+func (r *PgVersionedRepository) valueOf(state map[string]any) ([]byte, error) {
+	value := make(map[string]any, len(state))
+	for key, v := range state {
+		if key != r.versionKey {
+			value[key] = v
+		}
+	}
+	return json.Marshal(value)
+}
+
+func versionOf(value any) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case float64:
+		if v == math.Trunc(v) {
+			return int(v), true
+		}
+	case json.Number:
+		n, err := v.Int64()
+		return int(n), err == nil
+	}
+	return 0, false
+}
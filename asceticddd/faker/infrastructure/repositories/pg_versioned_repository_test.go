@@ -0,0 +1,195 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/utils/testutils"
+)
+
+func TestPgVersionedRepositoryGet(t *testing.T) {
+	repository := NewPgVersionedRepository("companies", "", "")
+
+	t.Run("injects version", func(t *testing.T) {
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub([]any{[]byte(`{"id":1,"name":"Acme"}`), 3}))
+		state, err := repository.Get(s, 1)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": 1.0, "name": "Acme", "_version": 3}, state)
+		assert.Equal(t, "SELECT value, version FROM companies WHERE value_id = $1::jsonb", s.ActualQuery)
+		assert.Equal(t, []any{`1`}, s.ActualParams)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+		_, err := repository.Get(s, 1)
+		assert.ErrorIs(t, err, ErrStateNotFound)
+	})
+}
+
+func TestPgVersionedRepositorySave(t *testing.T) {
+	repository := NewPgVersionedRepository("companies", "", "")
+
+	t.Run("insert", func(t *testing.T) {
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub([]any{1}))
+		state := map[string]any{"id": 1, "name": "Acme"}
+		version, err := repository.SaveState(s, state)
+		require.NoError(t, err)
+		assert.Equal(t, 1, version)
+		assert.Equal(t, 1, state["_version"])
+		assert.Equal(t,
+			"INSERT INTO companies (value_id, value, version) VALUES ($1::jsonb, $2::jsonb, 1) "+
+				"ON CONFLICT (value_id) DO NOTHING RETURNING version",
+			s.ActualQuery,
+		)
+		assert.Equal(t, []any{`1`, `{"id":1,"name":"Acme"}`}, s.ActualParams)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub([]any{4}))
+		state := map[string]any{"id": 1, "name": "Acme Corp", "_version": 3.0}
+		version, err := repository.SaveState(s, state)
+		require.NoError(t, err)
+		assert.Equal(t, 4, version)
+		assert.Equal(t, 4, state["_version"])
+		assert.Equal(t,
+			"UPDATE companies SET value = $2::jsonb, version = version + 1 "+
+				"WHERE value_id = $1::jsonb AND version = $3 RETURNING version",
+			s.ActualQuery,
+		)
+		assert.Equal(t, []any{`1`, `{"id":1,"name":"Acme Corp"}`, 3}, s.ActualParams)
+	})
+
+	t.Run("concurrent modification", func(t *testing.T) {
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+		state := map[string]any{"id": 1, "name": "Acme Corp"}
+		_, err := repository.Save(s, 1, state, 3)
+		assert.ErrorIs(t, err, ErrConcurrentModification)
+		var modificationErr *ConcurrentModificationError
+		require.ErrorAs(t, err, &modificationErr)
+		assert.Equal(t, ConcurrentModificationError{Table: "companies", Id: 1, ExpectedVersion: 3}, *modificationErr)
+		assert.NotContains(t, state, "_version")
+	})
+
+	t.Run("custom column and key", func(t *testing.T) {
+		custom := NewPgVersionedRepository("companies", "pk", "code")
+		custom.SetVersionColumn("revision")
+		custom.SetVersionKey("rev")
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub([]any{2}))
+		_, err := custom.SaveState(s, map[string]any{"code": "acme", "rev": 1})
+		require.NoError(t, err)
+		assert.Equal(t,
+			"UPDATE companies SET value = $2::jsonb, revision = revision + 1 "+
+				"WHERE pk = $1::jsonb AND revision = $3 RETURNING revision",
+			s.ActualQuery,
+		)
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+		_, err := repository.SaveState(s, map[string]any{"id": 1, "_version": "3"})
+		assert.Error(t, err)
+	})
+}
+
+func TestPgVersionedRepositoryFind(t *testing.T) {
+	repository := NewPgVersionedRepository("companies", "", "")
+	s := testutils.NewDbSessionStub(testutils.NewRowsStub(
+		[]any{[]byte(`{"id":1,"status":"active"}`), 2},
+	))
+	states, err := repository.Find(s, domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+		"status": domainquery.EqOperator{Value: "active"},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"id": 1.0, "status": "active", "_version": 2}}, states)
+	assert.Equal(t, "SELECT value, version FROM companies WHERE value @> $1 ORDER BY value_id", s.ActualQuery)
+}
+
+func TestPgVersionedRepositorySoftDelete(t *testing.T) {
+	repository := NewPgVersionedRepository("companies", "", "")
+	repository.SetSoftDeleteColumn("deleted_at")
+
+	t.Run("delete", func(t *testing.T) {
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub([]any{[]byte(`1`)}))
+		require.NoError(t, repository.Delete(s, 1))
+		assert.Equal(t,
+			"UPDATE companies SET deleted_at = now() WHERE value_id = $1::jsonb AND deleted_at IS NULL RETURNING value_id",
+			s.ActualQuery,
+		)
+
+		s = testutils.NewDbSessionStub(testutils.NewRowsStub())
+		assert.ErrorIs(t, repository.Delete(s, 1), ErrStateNotFound)
+	})
+
+	t.Run("get and find skip deleted", func(t *testing.T) {
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+		_, err := repository.Get(s, 1)
+		assert.ErrorIs(t, err, ErrStateNotFound)
+		assert.Equal(t, "SELECT value, version FROM companies WHERE value_id = $1::jsonb AND deleted_at IS NULL", s.ActualQuery)
+
+		_, err = repository.Find(s, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT value, version FROM companies WHERE deleted_at IS NULL ORDER BY value_id", s.ActualQuery)
+	})
+
+	t.Run("with deleted", func(t *testing.T) {
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+		_, err := repository.WithDeleted().Find(s, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT value, version FROM companies ORDER BY value_id", s.ActualQuery)
+	})
+
+	t.Run("save does not resurrect", func(t *testing.T) {
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+		_, err := repository.Save(s, 1, map[string]any{"id": 1}, 2)
+		assert.ErrorIs(t, err, ErrConcurrentModification)
+		assert.Contains(t, s.ActualQuery, " AND version = $3 AND deleted_at IS NULL RETURNING version")
+	})
+
+	t.Run("hard delete", func(t *testing.T) {
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub([]any{[]byte(`1`)}))
+		require.NoError(t, NewPgVersionedRepository("companies", "", "").Delete(s, 1))
+		assert.Equal(t, "DELETE FROM companies WHERE value_id = $1::jsonb RETURNING value_id", s.ActualQuery)
+	})
+}
+
+func TestPgVersionedRepositorySaveMany(t *testing.T) {
+	repository := NewPgVersionedRepository("companies", "", "")
+
+	t.Run("upsert", func(t *testing.T) {
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub(
+			[]any{[]byte(`2`), 1},
+			[]any{[]byte(`1`), 4},
+		))
+		states := []map[string]any{{"id": 1, "name": "Acme", "_version": 3}, {"id": 2, "name": "Globex"}}
+		versions, err := repository.SaveMany(s, states)
+		require.NoError(t, err)
+		assert.Equal(t, []int{4, 1}, versions)
+		assert.Equal(t, 4, states[0]["_version"])
+		assert.Equal(t, 1, states[1]["_version"])
+		assert.Equal(t,
+			"INSERT INTO companies (value_id, value, version) VALUES ($1::jsonb, $2::jsonb, 1), ($3::jsonb, $4::jsonb, 1) "+
+				"ON CONFLICT (value_id) DO UPDATE SET value = EXCLUDED.value, version = companies.version + 1 "+
+				"RETURNING value_id, version",
+			s.ActualQuery,
+		)
+		assert.Equal(t, []any{`1`, `{"id":1,"name":"Acme"}`, `2`, `{"id":2,"name":"Globex"}`}, s.ActualParams)
+	})
+
+	t.Run("duplicate ids", func(t *testing.T) {
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+		_, err := repository.SaveMany(s, []map[string]any{{"id": 1}, {"id": 1}})
+		assert.Error(t, err)
+	})
+
+	t.Run("soft-deleted states are not replaced", func(t *testing.T) {
+		repository := NewPgVersionedRepository("companies", "", "")
+		repository.SetSoftDeleteColumn("deleted_at")
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub([]any{[]byte(`1`), 1}))
+		_, err := repository.SaveMany(s, []map[string]any{{"id": 1}, {"id": 2}})
+		assert.ErrorIs(t, err, ErrStateNotFound)
+		assert.Contains(t, s.ActualQuery, " + 1 WHERE companies.deleted_at IS NULL RETURNING ")
+	})
+}
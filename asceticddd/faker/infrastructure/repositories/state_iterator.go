@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+// StateIterator streams states from the rows of a query, so that large results are not loaded at once:
+//
+//	it, err := repository.FindIter(s, query)
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close()
+//	for it.Next() {
+//		process(it.State())
+//	}
+//	return it.Err()
+//
+// The rows are closed when Next returns false, Close is needed only when iteration stops early.
+type StateIterator struct {
+	rows  session.Rows
+	scan  func(rows session.Rows) (map[string]any, error)
+	state map[string]any
+	err   error
+	done  bool
+}
+
+func newStateIterator(rows session.Rows, scan func(rows session.Rows) (map[string]any, error)) *StateIterator {
+	return &StateIterator{rows: rows, scan: scan}
+}
+
+// Next advances to the next state and reports whether there is one.
+func (it *StateIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	if !it.rows.Next() {
+		it.err = it.rows.Err()
+		it.finish()
+		return false
+	}
+	state, err := it.scan(it.rows)
+	if err != nil {
+		it.err = err
+		it.finish()
+		return false
+	}
+	it.state = state
+	return true
+}
+
+// State returns the current state.
+func (it *StateIterator) State() map[string]any {
+	return it.state
+}
+
+// Err returns the error which stopped the iteration.
+func (it *StateIterator) Err() error {
+	return it.err
+}
+
+// Close closes the rows, it may be called more than once.
+func (it *StateIterator) Close() error {
+	if it.done {
+		return nil
+	}
+	it.done = true
+	it.state = nil
+	return it.rows.Close()
+}
+
+func (it *StateIterator) finish() {
+	it.state = nil
+	if err := it.Close(); err != nil && it.err == nil {
+		it.err = err
+	}
+}
+
+// eachState calls fn with every state of the iterator and closes it.
+func eachState(it *StateIterator, fn func(state map[string]any) error) error {
+	defer it.Close()
+	for it.Next() {
+		if err := fn(it.State()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
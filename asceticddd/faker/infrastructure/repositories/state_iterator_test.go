@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/utils/testutils"
+)
+
+func TestStateIterator(t *testing.T) {
+	repository := NewPgVersionedRepository("companies", "", "")
+	newRows := func() *testutils.RowsStub {
+		return testutils.NewRowsStub(
+			[]any{[]byte(`{"id":1}`), 1},
+			[]any{[]byte(`{"id":2}`), 3},
+		)
+	}
+
+	t.Run("iterates and closes rows", func(t *testing.T) {
+		rows := newRows()
+		s := testutils.NewDbSessionStub(rows)
+		it, err := repository.FindIter(s, nil)
+		require.NoError(t, err)
+		var states []map[string]any
+		for it.Next() {
+			states = append(states, it.State())
+		}
+		require.NoError(t, it.Err())
+		assert.Equal(t, []map[string]any{{"id": 1.0, "_version": 1}, {"id": 2.0, "_version": 3}}, states)
+		assert.True(t, rows.Closed)
+		assert.Equal(t, "SELECT value, version FROM companies ORDER BY value_id", s.ActualQuery)
+		assert.False(t, it.Next())
+		assert.NoError(t, it.Close())
+	})
+
+	t.Run("scan error", func(t *testing.T) {
+		rows := testutils.NewRowsStub([]any{[]byte(`not json`), 1})
+		it, err := repository.FindIter(testutils.NewDbSessionStub(rows), nil)
+		require.NoError(t, err)
+		assert.False(t, it.Next())
+		assert.Error(t, it.Err())
+		assert.True(t, rows.Closed)
+	})
+
+	t.Run("find each stops at callback error", func(t *testing.T) {
+		rows := newRows()
+		stop := errors.New("stop")
+		calls := 0
+		err := repository.FindEach(testutils.NewDbSessionStub(rows), nil, func(state map[string]any) error {
+			calls++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+		assert.True(t, rows.Closed)
+	})
+}
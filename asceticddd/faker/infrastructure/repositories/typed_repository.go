@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+// StateRepository is the map-based repository wrapped by TypedRepository.
+// InMemoryRepository implements it, PgVersionedRepository does with AsStateRepository.
+type StateRepository interface {
+	Get(s session.Session, id any) (map[string]any, error)
+	Save(s session.Session, state map[string]any) error
+	Delete(s session.Session, id any) error
+	Find(s session.Session, query domainquery.IQueryOperator) ([]map[string]any, error)
+}
+
+var (
+	_ StateRepository = (*InMemoryRepository)(nil)
+	_ StateRepository = pgVersionedStates{}
+)
+
+// TypedRepository keeps structs in a StateRepository, so application code works with domain structs
+// while queries and SQL stay on the map representation.
+// Structs are converted to states and back with encoding/json, so json tags name the keys
+// the same way as for EvaluateWalker. Numbers of states are float64, as decoded from jsonb,
+// and ids are converted the same way before lookup.
+type TypedRepository[T any] struct {
+	states StateRepository
+}
+
+func NewTypedRepository[T any](states StateRepository) *TypedRepository[T] {
+	return &TypedRepository[T]{states: states}
+}
+
+// Get returns the struct with the id, or ErrStateNotFound.
+func (r *TypedRepository[T]) Get(s session.Session, id any) (*T, error) {
+	normalized, err := normalizeId(id)
+	if err != nil {
+		return nil, err
+	}
+	state, err := r.states.Get(s, normalized)
+	if err != nil {
+		return nil, err
+	}
+	return FromState[T](state)
+}
+
+// Save stores the struct. Keys set by the repository on save, e.g. the version of
+// PgVersionedRepository, are hydrated back into the struct.
+func (r *TypedRepository[T]) Save(s session.Session, entity *T) error {
+	state, err := ToState(entity)
+	if err != nil {
+		return err
+	}
+	if err := r.states.Save(s, state); err != nil {
+		return err
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, entity)
+}
+
+// Delete deletes the struct with the id.
+func (r *TypedRepository[T]) Delete(s session.Session, id any) error {
+	normalized, err := normalizeId(id)
+	if err != nil {
+		return err
+	}
+	return r.states.Delete(s, normalized)
+}
+
+// Find returns the structs whose states match the query. Nil query matches all states.
+func (r *TypedRepository[T]) Find(s session.Session, query domainquery.IQueryOperator) ([]*T, error) {
+	states, err := r.states.Find(s, query)
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]*T, 0, len(states))
+	for _, state := range states {
+		entity, err := FromState[T](state)
+		if err != nil {
+			return nil, err
+		}
+		entities = append(entities, entity)
+	}
+	return entities, nil
+}
+
+// ToState converts the struct to the state as encoding/json sees it.
+func ToState(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var state map[string]any
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, fmt.Errorf("%T is not an object", v)
+	}
+	return state, nil
+}
+
+// FromState converts the state to the struct.
+func FromState[T any](state map[string]any) (*T, error) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	entity := new(T)
+	if err := json.Unmarshal(data, entity); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+func normalizeId(id any) (any, error) {
+	data, err := json.Marshal(id)
+	if err != nil {
+		return nil, err
+	}
+	var normalized any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// AsStateRepository adapts the repository to StateRepository, Save is SaveState.
+func (r *PgVersionedRepository) AsStateRepository() StateRepository {
+	return pgVersionedStates{r}
+}
+
+type pgVersionedStates struct {
+	*PgVersionedRepository
+}
+
+func (r pgVersionedStates) Save(s session.Session, state map[string]any) error {
+	_, err := r.SaveState(s, state)
+	return err
+}
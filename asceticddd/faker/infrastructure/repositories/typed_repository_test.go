@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/utils/testutils"
+)
+
+type typedCompany struct {
+	Id      int      `json:"id"`
+	Name    string   `json:"name"`
+	Status  string   `json:"status,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	Version int      `json:"_version,omitempty"`
+}
+
+func TestTypedRepository(t *testing.T) {
+	s := NewInMemorySession(context.Background())
+
+	t.Run("get save delete", func(t *testing.T) {
+		repository := NewTypedRepository[typedCompany](NewInMemoryRepository("", nil))
+		require.NoError(t, repository.Save(s, &typedCompany{Id: 1, Name: "Acme", Tags: []string{"b2b"}}))
+
+		company, err := repository.Get(s, 1)
+		require.NoError(t, err)
+		assert.Equal(t, &typedCompany{Id: 1, Name: "Acme", Tags: []string{"b2b"}}, company)
+
+		require.NoError(t, repository.Delete(s, 1))
+		_, err = repository.Get(s, 1)
+		assert.ErrorIs(t, err, ErrStateNotFound)
+	})
+
+	t.Run("find by json keys", func(t *testing.T) {
+		repository := NewTypedRepository[typedCompany](NewInMemoryRepository("", nil))
+		require.NoError(t, repository.Save(s, &typedCompany{Id: 1, Name: "Acme", Status: "active"}))
+		require.NoError(t, repository.Save(s, &typedCompany{Id: 2, Name: "Globex", Status: "closed"}))
+
+		companies, err := repository.Find(s, domainquery.CompositeQuery{Fields: map[string]domainquery.IQueryOperator{
+			"status": domainquery.EqOperator{Value: "active"},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, []*typedCompany{{Id: 1, Name: "Acme", Status: "active"}}, companies)
+	})
+
+	t.Run("save hydrates version", func(t *testing.T) {
+		repository := NewTypedRepository[typedCompany](NewPgVersionedRepository("companies", "", "").AsStateRepository())
+		stub := testutils.NewDbSessionStub(testutils.NewRowsStub([]any{1}))
+		company := &typedCompany{Id: 1, Name: "Acme"}
+		require.NoError(t, repository.Save(stub, company))
+		assert.Equal(t, 1, company.Version)
+		assert.Equal(t, []any{`1`, `{"id":1,"name":"Acme"}`}, stub.ActualParams)
+	})
+}
+
+func TestToState(t *testing.T) {
+	state, err := ToState(typedCompany{Id: 1, Name: "Acme"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": 1.0, "name": "Acme"}, state)
+
+	_, err = ToState([]int{1})
+	assert.Error(t, err)
+}
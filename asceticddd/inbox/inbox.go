@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/outbox"
 	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
 )
 
@@ -16,27 +17,16 @@ type PgInbox struct {
 	partitionKeyStrategy  PartitionKeyStrategy
 }
 
+// NewInbox returns the inbox of the table, the sequence and the partition key strategy.
+//
+// Deprecated: use New with WithTable, WithSequence and WithPartitionKeyStrategy.
 func NewInbox(
 	sessionPool session.SessionPool,
 	table string,
 	sequence string,
 	partitionKeyStrategy PartitionKeyStrategy,
 ) *PgInbox {
-	if table == "" {
-		table = "inbox"
-	}
-	if sequence == "" {
-		sequence = "inbox_received_position_seq"
-	}
-	if partitionKeyStrategy == nil {
-		partitionKeyStrategy = &UriPartitionKeyStrategy{}
-	}
-	return &PgInbox{
-		sessionPool:          sessionPool,
-		table:                table,
-		sequence:             sequence,
-		partitionKeyStrategy: partitionKeyStrategy,
-	}
+	return New(sessionPool, WithTable(table), WithSequence(sequence), WithPartitionKeyStrategy(partitionKeyStrategy))
 }
 
 func (i *PgInbox) Publish(message *InboxMessage) error {
@@ -64,7 +54,7 @@ func (i *PgInbox) Dispatch(subscriber Subscriber, workerID int, numWorkers int)
 				return nil
 			}
 
-			if err := subscriber(txSession, message); err != nil {
+			if err := subscriber(withCausation(txSession, message), message); err != nil {
 				return err
 			}
 
@@ -151,7 +141,7 @@ func (i *PgInbox) Messages(ctx context.Context, workerID int, numWorkers int, po
 					select {
 					case <-ctx.Done():
 						return ctx.Err()
-					case messageCh <- &SessionMessage{Session: txSession, Message: message}:
+					case messageCh <- &SessionMessage{Session: withCausation(txSession, message), Message: message}:
 					}
 
 					// Mark as processed after yield
@@ -458,3 +448,9 @@ func (i *PgInbox) createTable(s session.Session) error {
 func (i *PgInbox) Cleanup(s session.Session) error {
 	return nil
 }
+
+// withCausation makes messages published to the outbox while handling the message
+// carry its event_id as causation_id and inherit its correlation_id.
+func withCausation(s session.Session, message *InboxMessage) session.Session {
+	return outbox.WithCausation(s, outbox.CausationFromMetadata(message.Metadata))
+}
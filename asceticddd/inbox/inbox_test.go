@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/outbox"
 	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
 	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session/identitymap"
 	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/signals"
@@ -315,6 +316,49 @@ func TestDispatchProcessesMessageWithoutDependencies(t *testing.T) {
 	}
 }
 
+func TestDispatchPassesCausationToSubscriber(t *testing.T) {
+	streamIDBytes, _ := json.Marshal(map[string]any{"id": "order-123"})
+	payloadBytes, _ := json.Marshal(map[string]any{"type": "OrderCreated"})
+	metadataBytes, _ := json.Marshal(map[string]any{"event_id": "e2", "correlation_id": "e1"})
+
+	conn := &mockConnection{
+		queryRowFunc: func(query string, args ...any) session.Row {
+			return &mockRow{
+				values: []any{
+					"tenant1", "Order", streamIDBytes, 1, "kafka://orders",
+					payloadBytes, metadataBytes, int64(1), nil,
+				},
+			}
+		},
+		execFunc: func(query string, args ...any) (session.Result, error) {
+			return &mockResult{}, nil
+		},
+	}
+
+	pool := &mockSessionPool{session: &mockDbSession{connection: conn}}
+	inbox := NewInbox(pool, "inbox", "inbox_received_position_seq", nil)
+
+	var causation outbox.Causation
+	var isDbSession bool
+	subscriber := func(s session.Session, msg *InboxMessage) error {
+		causation, _ = outbox.CausationFromContext(s.Context())
+		_, isDbSession = s.(session.DbSession)
+		return nil
+	}
+
+	if _, err := inbox.Dispatch(subscriber, 0, 1); err != nil {
+		t.Fatalf("Dispatch failed: %v", err)
+	}
+
+	expected := outbox.Causation{CausationId: "e2", CorrelationId: "e1"}
+	if causation != expected {
+		t.Errorf("Expected causation %v, got %v", expected, causation)
+	}
+	if !isDbSession {
+		t.Error("Expected subscriber session to remain a DbSession")
+	}
+}
+
 func TestDependenciesSatisfiedWhenEmpty(t *testing.T) {
 	message := &InboxMessage{
 		TenantId:       "tenant1",
@@ -703,3 +747,26 @@ func TestRunWorkerSleepsWhenNoMessages(t *testing.T) {
 		t.Errorf("Expected 0 messages, got %d", len(handled))
 	}
 }
+
+func TestNewAppliesOptions(t *testing.T) {
+	strategy := &StreamPartitionKeyStrategy{}
+	inbox := New(nil, WithTable("custom_inbox"), WithSequence("custom_seq"), WithPartitionKeyStrategy(strategy))
+
+	if inbox.table != "custom_inbox" {
+		t.Errorf("Expected table=custom_inbox, got %s", inbox.table)
+	}
+	if inbox.sequence != "custom_seq" {
+		t.Errorf("Expected sequence=custom_seq, got %s", inbox.sequence)
+	}
+	if inbox.partitionKeyStrategy != strategy {
+		t.Errorf("Expected custom partition key strategy, got %T", inbox.partitionKeyStrategy)
+	}
+
+	defaults := New(nil, WithTable(""), WithPartitionKeyStrategy(nil))
+	if defaults.table != "inbox" || defaults.sequence != "inbox_received_position_seq" {
+		t.Errorf("Expected default names, got %s, %s", defaults.table, defaults.sequence)
+	}
+	if _, ok := defaults.partitionKeyStrategy.(*UriPartitionKeyStrategy); !ok {
+		t.Errorf("Expected UriPartitionKeyStrategy, got %T", defaults.partitionKeyStrategy)
+	}
+}
@@ -0,0 +1,47 @@
+package inbox
+
+import (
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+type Option func(*PgInbox)
+
+// WithTable sets the table of received messages, "" means "inbox".
+func WithTable(table string) Option {
+	return func(i *PgInbox) {
+		if table != "" {
+			i.table = table
+		}
+	}
+}
+
+// WithSequence sets the sequence of received positions, "" means "inbox_received_position_seq".
+func WithSequence(sequence string) Option {
+	return func(i *PgInbox) {
+		if sequence != "" {
+			i.sequence = sequence
+		}
+	}
+}
+
+// WithPartitionKeyStrategy sets the strategy of ordering messages, nil means UriPartitionKeyStrategy.
+func WithPartitionKeyStrategy(strategy PartitionKeyStrategy) Option {
+	return func(i *PgInbox) {
+		if strategy != nil {
+			i.partitionKeyStrategy = strategy
+		}
+	}
+}
+
+func New(sessionPool session.SessionPool, opts ...Option) *PgInbox {
+	i := &PgInbox{
+		sessionPool:          sessionPool,
+		table:                "inbox",
+		sequence:             "inbox_received_position_seq",
+		partitionKeyStrategy: &UriPartitionKeyStrategy{},
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
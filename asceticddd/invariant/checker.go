@@ -0,0 +1,118 @@
+package invariant
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+var ErrInvariantViolation = errors.New("invariant violation")
+
+// Violation is a rule not held by an aggregate.
+type Violation struct {
+	Rule      string
+	Aggregate any
+}
+
+// ViolationError lists all violations found by one check.
+// It matches ErrInvariantViolation with errors.Is.
+type ViolationError struct {
+	Violations []Violation
+}
+
+func (e *ViolationError) Error() string {
+	rules := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		rules[i] = fmt.Sprintf("%s (%T)", v.Rule, v.Aggregate)
+	}
+	return fmt.Sprintf("%s: %s", ErrInvariantViolation, strings.Join(rules, ", "))
+}
+
+func (e *ViolationError) Is(target error) bool {
+	return target == ErrInvariantViolation
+}
+
+// Rules returns the names of the violated rules.
+func (e *ViolationError) Rules() []string {
+	rules := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		rules[i] = v.Rule
+	}
+	return rules
+}
+
+type rule struct {
+	name          string
+	aggregateType reflect.Type
+	isSatisfiedBy func(s session.Session, aggregate any) (bool, error)
+}
+
+func (r rule) appliesTo(aggregateType reflect.Type) bool {
+	if r.aggregateType.Kind() == reflect.Interface {
+		return aggregateType.Implements(r.aggregateType)
+	}
+	return aggregateType == r.aggregateType
+}
+
+// Checker holds invariant rules by aggregate type.
+// Aggregates are checked on demand with Check,
+// or at the end of atomic scopes with Track and Middleware.
+type Checker struct {
+	mu    sync.RWMutex
+	rules []rule
+	units map[session.Session]*unit
+}
+
+func NewChecker() *Checker {
+	return &Checker{units: map[session.Session]*unit{}}
+}
+
+// Register adds the rule for aggregates of type T.
+// T may be an interface, then the rule applies to all aggregates implementing it.
+func Register[T any](c *Checker, name string, specification Specification[T]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rules = append(c.rules, rule{
+		name:          name,
+		aggregateType: reflect.TypeFor[T](),
+		isSatisfiedBy: func(s session.Session, aggregate any) (bool, error) {
+			return specification.IsSatisfiedBy(s, aggregate.(T))
+		},
+	})
+}
+
+// Check runs the rules of each aggregate in the order of registration.
+// It returns *ViolationError listing all violations, or the first error of a specification.
+func (c *Checker) Check(s session.Session, aggregates ...any) error {
+	c.mu.RLock()
+	rules := c.rules
+	c.mu.RUnlock()
+
+	var violations []Violation
+	for _, aggregate := range aggregates {
+		aggregateType := reflect.TypeOf(aggregate)
+		if aggregateType == nil {
+			continue
+		}
+		for _, r := range rules {
+			if !r.appliesTo(aggregateType) {
+				continue
+			}
+			ok, err := r.isSatisfiedBy(s, aggregate)
+			if err != nil {
+				return fmt.Errorf("invariant %s: %w", r.name, err)
+			}
+			if !ok {
+				violations = append(violations, Violation{Rule: r.name, Aggregate: aggregate})
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return &ViolationError{Violations: violations}
+	}
+	return nil
+}
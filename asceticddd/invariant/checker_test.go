@@ -0,0 +1,139 @@
+package invariant
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/jsonpath"
+)
+
+type order struct {
+	customerId string
+	total      int
+	lines      int
+}
+
+type customer struct {
+	name string
+}
+
+type named interface {
+	Name() string
+}
+
+func (c *customer) Name() string {
+	return c.name
+}
+
+func totalIsPositive() Specification[*order] {
+	return SpecificationFunc[*order](func(s session.Session, o *order) (bool, error) {
+		return o.total > 0, nil
+	})
+}
+
+func hasLines() Specification[*order] {
+	return SpecificationFunc[*order](func(s session.Session, o *order) (bool, error) {
+		return o.lines > 0, nil
+	})
+}
+
+func TestChecker(t *testing.T) {
+	var s session.Session
+
+	t.Run("valid aggregates", func(t *testing.T) {
+		checker := NewChecker()
+		Register(checker, "order.total_is_positive", totalIsPositive())
+		assert.NoError(t, checker.Check(s, &order{total: 10, lines: 1}, &customer{name: "Acme"}))
+	})
+
+	t.Run("violations of all aggregates", func(t *testing.T) {
+		checker := NewChecker()
+		Register(checker, "order.total_is_positive", totalIsPositive())
+		Register(checker, "order.has_lines", hasLines())
+		invalid := &order{}
+		partial := &order{total: 5}
+
+		err := checker.Check(s, invalid, &order{total: 1, lines: 1}, partial)
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvariantViolation)
+		var violationErr *ViolationError
+		require.True(t, errors.As(err, &violationErr))
+		assert.Equal(t, []Violation{
+			{Rule: "order.total_is_positive", Aggregate: invalid},
+			{Rule: "order.has_lines", Aggregate: invalid},
+			{Rule: "order.has_lines", Aggregate: partial},
+		}, violationErr.Violations)
+		assert.Equal(t, []string{"order.total_is_positive", "order.has_lines", "order.has_lines"}, violationErr.Rules())
+		assert.Equal(t,
+			"invariant violation: order.total_is_positive (*invariant.order), "+
+				"order.has_lines (*invariant.order), order.has_lines (*invariant.order)",
+			err.Error(),
+		)
+	})
+
+	t.Run("interface rules", func(t *testing.T) {
+		checker := NewChecker()
+		Register(checker, "named", SpecificationFunc[named](func(s session.Session, n named) (bool, error) {
+			return n.Name() != "", nil
+		}))
+		err := checker.Check(s, &customer{}, &order{})
+		var violationErr *ViolationError
+		require.True(t, errors.As(err, &violationErr))
+		assert.Equal(t, []string{"named"}, violationErr.Rules())
+	})
+
+	t.Run("specification error", func(t *testing.T) {
+		checker := NewChecker()
+		errBoom := errors.New("boom")
+		Register(checker, "failing", SpecificationFunc[*order](func(s session.Session, o *order) (bool, error) {
+			return false, errBoom
+		}))
+		err := checker.Check(s, &order{})
+		assert.ErrorIs(t, err, errBoom)
+		assert.NotErrorIs(t, err, ErrInvariantViolation)
+	})
+}
+
+func TestExpressionSpecification(t *testing.T) {
+	specification := NewExpressionSpecification(
+		spec.GreaterThan(spec.Field(spec.GlobalScope(), "total"), spec.Value(0)),
+		func(o *order) spec.Context {
+			return jsonpath.NewDictContext(map[string]any{"total": o.total})
+		},
+	)
+
+	ok, err := specification.IsSatisfiedBy(nil, &order{total: 3})
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = specification.IsSatisfiedBy(nil, &order{total: 0})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestExpressionSpecificationClock(t *testing.T) {
+	deadline := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	specification := NewExpressionSpecification(
+		spec.LessThanEqual(spec.Now(), spec.Field(spec.GlobalScope(), "deadline")),
+		func(o *order) spec.Context {
+			return jsonpath.NewDictContext(map[string]any{"deadline": deadline})
+		},
+	)
+
+	specification.SetClock(spec.FixedClock(deadline.Add(-time.Hour)))
+	ok, err := specification.IsSatisfiedBy(nil, &order{})
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	specification.SetClock(spec.FixedClock(deadline.Add(time.Hour)))
+	ok, err = specification.IsSatisfiedBy(nil, &order{})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,73 @@
+package invariant
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session/middleware"
+)
+
+var ErrNotInAtomicScope = errors.New("session is not in an atomic scope of the invariant middleware")
+
+// unit collects the aggregates changed within one atomic scope.
+type unit struct {
+	aggregates []any
+	seen       map[any]struct{}
+}
+
+func (u *unit) add(aggregate any) {
+	if aggregate == nil {
+		return
+	}
+	if reflect.ValueOf(aggregate).Comparable() {
+		if _, ok := u.seen[aggregate]; ok {
+			return
+		}
+		u.seen[aggregate] = struct{}{}
+	}
+	u.aggregates = append(u.aggregates, aggregate)
+}
+
+// Middleware checks the aggregates tracked within an atomic scope when the callback succeeds,
+// so that a violation fails the scope and rolls back the transaction (or savepoint).
+func (c *Checker) Middleware() middleware.Middleware {
+	return middleware.Middleware{
+		Atomic: func(next middleware.AtomicHandler) middleware.AtomicHandler {
+			return func(s session.Session, callback session.SessionCallback) error {
+				return next(s, func(txSession session.Session) error {
+					u := &unit{seen: map[any]struct{}{}}
+					c.mu.Lock()
+					c.units[txSession] = u
+					c.mu.Unlock()
+					defer func() {
+						c.mu.Lock()
+						delete(c.units, txSession)
+						c.mu.Unlock()
+					}()
+
+					if err := callback(txSession); err != nil {
+						return err
+					}
+					return c.Check(txSession, u.aggregates...)
+				})
+			}
+		},
+	}
+}
+
+// Track registers the aggregates changed within the atomic scope of the session.
+// Aggregates are checked once per scope, in the order of the first Track.
+// Each scope checks only its own aggregates, a nested scope does not pass them to the enclosing one.
+func (c *Checker) Track(s session.Session, aggregates ...any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	u, ok := c.units[s]
+	if !ok {
+		return ErrNotInAtomicScope
+	}
+	for _, aggregate := range aggregates {
+		u.add(aggregate)
+	}
+	return nil
+}
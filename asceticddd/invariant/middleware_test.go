@@ -0,0 +1,127 @@
+package invariant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session/middleware"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/signals"
+)
+
+type fakeSession struct {
+	committed *int
+}
+
+func (s *fakeSession) Context() context.Context {
+	return context.Background()
+}
+
+func (s *fakeSession) Atomic(callback session.SessionCallback) error {
+	err := callback(&fakeSession{committed: s.committed})
+	if err == nil {
+		*s.committed++
+	}
+	return err
+}
+
+func (s *fakeSession) OnAtomicStarted() signals.Signal[session.SessionScopeStartedEvent] {
+	return signals.NewSignal[session.SessionScopeStartedEvent]()
+}
+
+func (s *fakeSession) OnAtomicEnded() signals.Signal[session.SessionScopeEndedEvent] {
+	return signals.NewSignal[session.SessionScopeEndedEvent]()
+}
+
+type fakeSessionPool struct {
+	committed int
+}
+
+func (p *fakeSessionPool) Session(ctx context.Context, callback session.SessionPoolCallback) error {
+	return callback(&fakeSession{committed: &p.committed})
+}
+
+func (p *fakeSessionPool) OnSessionStarted() signals.Signal[session.SessionScopeStartedEvent] {
+	return signals.NewSignal[session.SessionScopeStartedEvent]()
+}
+
+func (p *fakeSessionPool) OnSessionEnded() signals.Signal[session.SessionScopeEndedEvent] {
+	return signals.NewSignal[session.SessionScopeEndedEvent]()
+}
+
+func TestMiddleware(t *testing.T) {
+	newPool := func() (*fakeSessionPool, *middleware.SessionPool, *Checker) {
+		checker := NewChecker()
+		Register(checker, "order.total_is_positive", totalIsPositive())
+		delegate := &fakeSessionPool{}
+		return delegate, middleware.NewSessionPool(delegate, checker.Middleware()), checker
+	}
+
+	t.Run("commits valid aggregates", func(t *testing.T) {
+		delegate, pool, checker := newPool()
+		err := pool.Session(context.Background(), func(s session.Session) error {
+			return s.Atomic(func(tx session.Session) error {
+				return checker.Track(tx, &order{total: 1})
+			})
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, delegate.committed)
+	})
+
+	t.Run("violation fails the transaction", func(t *testing.T) {
+		delegate, pool, checker := newPool()
+		o := &order{total: 1}
+		err := pool.Session(context.Background(), func(s session.Session) error {
+			return s.Atomic(func(tx session.Session) error {
+				if err := checker.Track(tx, o, o); err != nil {
+					return err
+				}
+				o.total = 0
+				return nil
+			})
+		})
+		var violationErr *ViolationError
+		require.True(t, errors.As(err, &violationErr))
+		assert.Equal(t, []Violation{{Rule: "order.total_is_positive", Aggregate: o}}, violationErr.Violations)
+		assert.Equal(t, 0, delegate.committed)
+	})
+
+	t.Run("callback error skips the check", func(t *testing.T) {
+		_, pool, checker := newPool()
+		errBoom := errors.New("boom")
+		err := pool.Session(context.Background(), func(s session.Session) error {
+			return s.Atomic(func(tx session.Session) error {
+				_ = checker.Track(tx, &order{})
+				return errBoom
+			})
+		})
+		assert.Equal(t, errBoom, err)
+	})
+
+	t.Run("nested scope checks its own aggregates", func(t *testing.T) {
+		delegate, pool, checker := newPool()
+		err := pool.Session(context.Background(), func(s session.Session) error {
+			return s.Atomic(func(tx session.Session) error {
+				savepointErr := tx.Atomic(func(savepoint session.Session) error {
+					return checker.Track(savepoint, &order{})
+				})
+				assert.ErrorIs(t, savepointErr, ErrInvariantViolation)
+				return checker.Track(tx, &order{total: 1})
+			})
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, delegate.committed)
+	})
+
+	t.Run("track outside of atomic scope", func(t *testing.T) {
+		_, pool, checker := newPool()
+		err := pool.Session(context.Background(), func(s session.Session) error {
+			return checker.Track(s, &order{})
+		})
+		assert.ErrorIs(t, err, ErrNotInAtomicScope)
+	})
+}
@@ -0,0 +1,53 @@
+package invariant
+
+import (
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+// Specification is satisfied by aggregates which hold the invariant.
+type Specification[T any] interface {
+	IsSatisfiedBy(s session.Session, aggregate T) (bool, error)
+}
+
+// SpecificationFunc adapts a function to Specification.
+type SpecificationFunc[T any] func(s session.Session, aggregate T) (bool, error)
+
+func (f SpecificationFunc[T]) IsSatisfiedBy(s session.Session, aggregate T) (bool, error) {
+	return f(s, aggregate)
+}
+
+// ExpressionSpecification evaluates a specification expression
+// over the context exported by the aggregate.
+type ExpressionSpecification[T any] struct {
+	expression spec.Visitable
+	context    func(T) spec.Context
+	registry   *operators.OperatorRegistry
+	clock      spec.Clock
+}
+
+func NewExpressionSpecification[T any](
+	expression spec.Visitable, context func(T) spec.Context,
+) *ExpressionSpecification[T] {
+	return &ExpressionSpecification[T]{
+		expression: expression,
+		context:    context,
+		registry:   operators.NewDefaultRegistry(),
+		clock:      spec.SystemClock,
+	}
+}
+
+// SetClock sets the Clock of spec.Now and spec.CurrentDate of the expression, spec.SystemClock by default.
+func (e *ExpressionSpecification[T]) SetClock(clock spec.Clock) {
+	e.clock = clock
+}
+
+func (e *ExpressionSpecification[T]) IsSatisfiedBy(s session.Session, aggregate T) (bool, error) {
+	visitor := spec.NewEvaluateVisitor(e.context(aggregate), e.registry)
+	visitor.SetClock(e.clock)
+	if err := e.expression.Accept(visitor); err != nil {
+		return false, err
+	}
+	return visitor.Result()
+}
@@ -0,0 +1,186 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+var ErrProjectionTimeout = errors.New("projection has not reached the position")
+
+// Position is the place of a message in the outbox.
+// Messages are delivered in the order of (TransactionID, Offset).
+// A command handler returns the position of its last published message,
+// so that API clients may pass it back as a consistency token
+// and query handlers may wait until read models have caught up, see ProjectionWaiter.
+type Position struct {
+	TransactionID int64
+	Offset        int64
+}
+
+// Reached reports whether the position of a consumer group covers the target,
+// i.e. the message at the target has been processed.
+func (p Position) Reached(target Position) bool {
+	return p.TransactionID > target.TransactionID ||
+		p.TransactionID == target.TransactionID && p.Offset >= target.Offset
+}
+
+func (p Position) IsZero() bool {
+	return p == Position{}
+}
+
+// String returns the token "transaction_id:offset", see ParsePosition.
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.TransactionID, p.Offset)
+}
+
+// ParsePosition parses the token returned by Position.String.
+func ParsePosition(token string) (Position, error) {
+	txPart, offsetPart, ok := strings.Cut(token, ":")
+	if !ok {
+		return Position{}, fmt.Errorf("invalid outbox position %q", token)
+	}
+	transactionID, err := strconv.ParseInt(txPart, 10, 64)
+	if err != nil {
+		return Position{}, fmt.Errorf("invalid outbox position %q: %w", token, err)
+	}
+	offset, err := strconv.ParseInt(offsetPart, 10, 64)
+	if err != nil {
+		return Position{}, fmt.Errorf("invalid outbox position %q: %w", token, err)
+	}
+	return Position{TransactionID: transactionID, Offset: offset}, nil
+}
+
+// PublishWithPosition publishes the message like Publish and returns its position,
+// which is also set to the Position and TransactionID of the message.
+func (o *PgOutbox) PublishWithPosition(s session.Session, message *OutboxMessage) (Position, error) {
+	sql := fmt.Sprintf(`
+		INSERT INTO %s (uri, payload, metadata, transaction_id)
+		VALUES ($1, $2, $3, pg_current_xact_id())
+		RETURNING transaction_id, "position"
+	`, o.outboxTable)
+
+	payload, metadata, err := marshalMessage(s, message)
+	if err != nil {
+		return Position{}, err
+	}
+
+	var position Position
+	row := s.(session.DbSession).Connection().QueryRow(sql, message.URI, payload, metadata)
+	if err := row.Scan(&position.TransactionID, &position.Offset); err != nil {
+		return Position{}, err
+	}
+	message.TransactionID = &position.TransactionID
+	message.Position = &position.Offset
+	return position, nil
+}
+
+// LastPosition returns the position of the last message of the URI, or of the URIs under it,
+// at or before the position, or the zero position if there is none. An empty URI matches all messages.
+func (o *PgOutbox) LastPosition(s session.Session, uri string, position Position) (Position, error) {
+	args := []any{fmt.Sprintf("%d", position.TransactionID), position.Offset}
+	uriFilter := ""
+	if uri != "" {
+		uriFilter = "AND (uri = $3 OR uri LIKE $4)"
+		args = append(args, uri, uri+"/%")
+	}
+
+	sql := fmt.Sprintf(`
+		SELECT transaction_id, "position"
+		FROM %s
+		WHERE (transaction_id < $1 OR transaction_id = $1 AND "position" <= $2)
+		%s
+		ORDER BY transaction_id DESC, "position" DESC
+		LIMIT 1
+	`, o.outboxTable, uriFilter)
+
+	rows, err := s.(session.DbSession).Connection().Query(sql, args...)
+	if err != nil {
+		return Position{}, err
+	}
+	defer rows.Close()
+
+	var last Position
+	if rows.Next() {
+		if err := rows.Scan(&last.TransactionID, &last.Offset); err != nil {
+			return Position{}, err
+		}
+	}
+	return last, rows.Err()
+}
+
+// ProjectionWaiter waits until the consumer group of a projector has processed a position,
+// giving clients read-your-writes semantics over eventually consistent read models.
+// The consumer group must be dispatched by a single worker,
+// since workers and shards acknowledge their partitions separately.
+type ProjectionWaiter struct {
+	outbox        PositionedOutbox
+	sessionPool   session.SessionPool
+	consumerGroup string
+	uri           string
+	pollInterval  time.Duration
+}
+
+func NewProjectionWaiter(outbox PositionedOutbox, sessionPool session.SessionPool, consumerGroup string, uri string) *ProjectionWaiter {
+	return &ProjectionWaiter{
+		outbox:        outbox,
+		sessionPool:   sessionPool,
+		consumerGroup: consumerGroup,
+		uri:           uri,
+		pollInterval:  50 * time.Millisecond,
+	}
+}
+
+// SetPollInterval sets how often the position of the consumer group is read, 50ms by default.
+func (w *ProjectionWaiter) SetPollInterval(pollInterval time.Duration) {
+	w.pollInterval = pollInterval
+}
+
+// WaitForProjection returns when the consumer group has processed the position,
+// or ErrProjectionTimeout after the timeout. A zero position returns at once.
+// The consumer group of a URI never acknowledges messages of other URIs,
+// so it waits for the last message of its URI at or before the position, see LastPosition,
+// and returns at once if there is none.
+func (w *ProjectionWaiter) WaitForProjection(ctx context.Context, position Position, timeout time.Duration) error {
+	if position.IsZero() {
+		return nil
+	}
+	if w.uri != "" {
+		err := w.sessionPool.Session(ctx, func(s session.Session) error {
+			var err error
+			position, err = w.outbox.LastPosition(s, w.uri, position)
+			return err
+		})
+		if err != nil || position.IsZero() {
+			return err
+		}
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		var current Position
+		err := w.sessionPool.Session(ctx, func(s session.Session) error {
+			var err error
+			current.TransactionID, current.Offset, err = w.outbox.GetPosition(s, w.consumerGroup, w.uri)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if current.Reached(position) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			return fmt.Errorf("%w: %s of %s, current %s", ErrProjectionTimeout, position, w.consumerGroup, current)
+		case <-time.After(w.pollInterval):
+		}
+	}
+}
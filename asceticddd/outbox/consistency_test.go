@@ -0,0 +1,141 @@
+package outbox
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+func TestPositionReached(t *testing.T) {
+	target := Position{TransactionID: 10, Offset: 5}
+	assert.True(t, Position{TransactionID: 10, Offset: 5}.Reached(target))
+	assert.True(t, Position{TransactionID: 11, Offset: 1}.Reached(target))
+	assert.False(t, Position{TransactionID: 10, Offset: 4}.Reached(target))
+	assert.False(t, Position{TransactionID: 9, Offset: 100}.Reached(target))
+}
+
+func TestParsePosition(t *testing.T) {
+	position, err := ParsePosition(Position{TransactionID: 10, Offset: 5}.String())
+	require.NoError(t, err)
+	assert.Equal(t, Position{TransactionID: 10, Offset: 5}, position)
+
+	for _, token := range []string{"", "10", "x:5", "10:y"} {
+		_, err := ParsePosition(token)
+		assert.Error(t, err, token)
+	}
+}
+
+func TestPublishWithPositionReturnsPosition(t *testing.T) {
+	conn := &mockConnection{
+		queryRowFunc: func(query string, args ...any) session.Row {
+			return &mockRow{scanFunc: func(dest ...any) error {
+				*dest[0].(*int64) = 100
+				*dest[1].(*int64) = 7
+				return nil
+			}}
+		},
+	}
+	dbSession := &mockDbSession{conn: conn}
+
+	outbox := NewOutbox(nil, "outbox", "outbox_offsets", 100)
+	message := &OutboxMessage{URI: "kafka://orders", Payload: map[string]any{"type": "OrderCreated"}}
+	position, err := outbox.PublishWithPosition(dbSession, message)
+	require.NoError(t, err)
+
+	assert.Equal(t, Position{TransactionID: 100, Offset: 7}, position)
+	assert.Equal(t, int64(100), *message.TransactionID)
+	assert.Equal(t, int64(7), *message.Position)
+	assert.Contains(t, conn.lastQuery, `RETURNING transaction_id, "position"`)
+	assert.Equal(t, "kafka://orders", conn.lastArgs[0])
+}
+
+// newURIWaiter returns the waiter of the consumer group of the URI
+// whose outbox has the last message of the URI at the position, if any, and the consumer group has the positions.
+func newURIWaiter(uri string, last *Position, positions ...Position) *ProjectionWaiter {
+	calls := 0
+	conn := &mockConnection{
+		queryRowFunc: func(query string, args ...any) session.Row {
+			current := positions[min(calls, len(positions)-1)]
+			calls++
+			return &mockRow{scanFunc: func(dest ...any) error {
+				*dest[0].(*int64) = current.TransactionID
+				*dest[1].(*int64) = current.Offset
+				return nil
+			}}
+		},
+		queryFunc: func(query string, args ...any) (session.Rows, error) {
+			if last == nil {
+				return &mockRows{}, nil
+			}
+			return &mockRows{rows: [][]any{{last.TransactionID, last.Offset}}}, nil
+		},
+	}
+	pool := &mockSessionPool{session: &mockDbSession{conn: conn}}
+	waiter := NewProjectionWaiter(NewOutbox(pool, "", "", 0), pool, "projector", uri)
+	waiter.SetPollInterval(time.Millisecond)
+	return waiter
+}
+
+func TestWaitForProjection(t *testing.T) {
+	newWaiter := func(positions ...Position) *ProjectionWaiter {
+		return newURIWaiter("", nil, positions...)
+	}
+	target := Position{TransactionID: 10, Offset: 5}
+
+	t.Run("waits until reached", func(t *testing.T) {
+		waiter := newWaiter(Position{TransactionID: 9, Offset: 1}, Position{TransactionID: 10, Offset: 3}, target)
+		require.NoError(t, waiter.WaitForProjection(context.Background(), target, time.Second))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		waiter := newWaiter(Position{TransactionID: 9, Offset: 1})
+		err := waiter.WaitForProjection(context.Background(), target, 10*time.Millisecond)
+		assert.ErrorIs(t, err, ErrProjectionTimeout)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		waiter := newWaiter(Position{TransactionID: 9, Offset: 1})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := waiter.WaitForProjection(ctx, target, time.Second)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("zero position", func(t *testing.T) {
+		waiter := newWaiter(Position{})
+		require.NoError(t, waiter.WaitForProjection(context.Background(), Position{}, 0))
+	})
+
+	t.Run("no message of the uri", func(t *testing.T) {
+		waiter := newURIWaiter("kafka://orders", nil, Position{TransactionID: 9, Offset: 1})
+		require.NoError(t, waiter.WaitForProjection(context.Background(), target, 10*time.Millisecond))
+	})
+
+	t.Run("last message of the uri", func(t *testing.T) {
+		last := Position{TransactionID: 10, Offset: 2}
+		waiter := newURIWaiter("kafka://orders", &last, Position{TransactionID: 9, Offset: 1}, last)
+		require.NoError(t, waiter.WaitForProjection(context.Background(), target, time.Second))
+
+		waiter = newURIWaiter("kafka://orders", &last, Position{TransactionID: 10, Offset: 1})
+		err := waiter.WaitForProjection(context.Background(), target, 10*time.Millisecond)
+		assert.ErrorIs(t, err, ErrProjectionTimeout)
+	})
+}
+
+func TestLastPositionQuery(t *testing.T) {
+	conn := &mockConnection{}
+	outbox := NewOutbox(nil, "outbox", "outbox_offsets", 100)
+
+	last, err := outbox.LastPosition(&mockDbSession{conn: conn}, "kafka://orders", Position{TransactionID: 10, Offset: 5})
+	require.NoError(t, err)
+
+	assert.True(t, last.IsZero())
+	assert.Contains(t, conn.lastQuery, `(transaction_id < $1 OR transaction_id = $1 AND "position" <= $2)`)
+	assert.Contains(t, conn.lastQuery, "AND (uri = $3 OR uri LIKE $4)")
+	assert.Equal(t, []any{"10", int64(5), "kafka://orders", "kafka://orders/%"}, conn.lastArgs)
+}
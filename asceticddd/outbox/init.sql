@@ -83,12 +83,33 @@ CREATE TABLE IF NOT EXISTS outbox_offsets (
     -- Timestamp of last position update
     "updated_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
 
+    -- Number of shards for sharded dispatch (NULL if not sharded)
+    -- Stored on the consumer group row, positions are tracked per shard
+    -- in rows with consumer_group = '<group>:shard:<n>'
+    "shard_count" INTEGER,
+
     -- Composite primary key: (consumer_group, uri)
     -- Allows tracking position per consumer group per uri
     PRIMARY KEY ("consumer_group", "uri")
 );
 
 
+-- =============================================================================
+-- OUTBOX PAUSES TABLE
+-- =============================================================================
+-- Consumer groups whose delivery is paused by an operator.
+-- Running workers check it between batches and keep their positions.
+-- Kept apart from the offsets, so pausing a consumer group which has
+-- no position yet does not create one.
+
+CREATE TABLE IF NOT EXISTS outbox_offsets_pauses (
+    "consumer_group" VARCHAR(255) NOT NULL,
+    "uri" VARCHAR(255) NOT NULL DEFAULT '',
+    "paused_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("consumer_group", "uri")
+);
+
+
 -- =============================================================================
 -- USAGE NOTES
 -- =============================================================================
@@ -6,8 +6,19 @@ import (
 	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
 )
 
+// Subscriber handles a message outside of the session of the dispatch.
+// A subscriber publishing messages should wrap its session by CausedBy to stamp their causation,
+// or be dispatched as the MessageHandler by DispatchBatchInTx and ForEachMessage.
 type Subscriber func(*OutboxMessage) error
 
+// BatchSubscriber handles a batch of messages within the transaction session of the dispatch.
+// The session carries no causation, since the batch holds many messages, see ForEachMessage.
+type BatchSubscriber func(s session.Session, messages []*OutboxMessage) error
+
+// MessageHandler handles a message within the transaction session of the dispatch,
+// messages published in the session are caused by the handled message, see ForEachMessage.
+type MessageHandler func(s session.Session, message *OutboxMessage) error
+
 type Outbox interface {
 	Publish(s session.Session, message *OutboxMessage) error
 	Dispatch(subscriber Subscriber, consumerGroup string, uri string, workerID int, numWorkers int) (bool, error)
@@ -18,3 +29,37 @@ type Outbox interface {
 	Setup(s session.Session) error
 	Cleanup(s session.Session) error
 }
+
+// PositionedOutbox returns positions of published messages, see ProjectionWaiter.
+type PositionedOutbox interface {
+	Outbox
+	PublishWithPosition(s session.Session, message *OutboxMessage) (Position, error)
+	LastPosition(s session.Session, uri string, position Position) (Position, error)
+}
+
+// BatchOutbox dispatches batches of messages within the transaction of the handler.
+type BatchOutbox interface {
+	Outbox
+	DispatchBatchInTx(handler BatchSubscriber, consumerGroup string, uri string, workerID int, numWorkers int) (bool, error)
+}
+
+// PausableOutbox pauses and resumes dispatching of consumer groups.
+type PausableOutbox interface {
+	Outbox
+	Pause(s session.Session, consumerGroup string, uri string) error
+	Resume(s session.Session, consumerGroup string, uri string) error
+	IsPaused(s session.Session, consumerGroup string, uri string) (bool, error)
+}
+
+// TracedOutbox loads causal chains of the messages from its storage, see BuildCausalChain.
+type TracedOutbox interface {
+	Outbox
+	CausalChain(s session.Session, eventId string) ([]*OutboxMessage, error)
+}
+
+type ShardedOutbox interface {
+	Outbox
+	DispatchShard(subscriber Subscriber, consumerGroup string, uri string, shard int, numShards int) (bool, error)
+	RunSharded(ctx context.Context, subscriber Subscriber, consumerGroup string, uri string, shards []int, numShards int, pollInterval float64) error
+	GetShardCount(s session.Session, consumerGroup string, uri string) (int, error)
+}
@@ -0,0 +1,56 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+type Option func(*PgOutbox)
+
+// WithOutboxTable sets the table of messages, "" means "outbox".
+func WithOutboxTable(table string) Option {
+	return func(o *PgOutbox) {
+		if table != "" {
+			o.outboxTable = table
+		}
+	}
+}
+
+// WithOffsetsTable sets the table of positions of consumer groups, "" means "outbox_offsets".
+func WithOffsetsTable(table string) Option {
+	return func(o *PgOutbox) {
+		if table != "" {
+			o.offsetsTable = table
+		}
+	}
+}
+
+// WithBatchSize sets the number of messages dispatched per transaction, 0 means 100.
+func WithBatchSize(batchSize int) Option {
+	return func(o *PgOutbox) {
+		if batchSize > 0 {
+			o.batchSize = batchSize
+		}
+	}
+}
+
+// WithRetention makes Cleanup delete acknowledged messages older than the retention.
+func WithRetention(retention time.Duration) Option {
+	return func(o *PgOutbox) {
+		o.retention = retention
+	}
+}
+
+func New(sessionPool session.SessionPool, opts ...Option) *PgOutbox {
+	o := &PgOutbox{
+		sessionPool:  sessionPool,
+		outboxTable:  "outbox",
+		offsetsTable: "outbox_offsets",
+		batchSize:    100,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"time"
 
+	"golang.org/x/sync/errgroup"
+
 	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
 )
 
@@ -14,29 +16,19 @@ type PgOutbox struct {
 	outboxTable  string
 	offsetsTable string
 	batchSize    int
+	retention    time.Duration
 }
 
+// NewOutbox returns the outbox of the tables and the batch size.
+//
+// Deprecated: use New with WithOutboxTable, WithOffsetsTable and WithBatchSize.
 func NewOutbox(
 	sessionPool session.SessionPool,
 	outboxTable string,
 	offsetsTable string,
 	batchSize int,
 ) *PgOutbox {
-	if outboxTable == "" {
-		outboxTable = "outbox"
-	}
-	if offsetsTable == "" {
-		offsetsTable = "outbox_offsets"
-	}
-	if batchSize == 0 {
-		batchSize = 100
-	}
-	return &PgOutbox{
-		sessionPool:  sessionPool,
-		outboxTable:  outboxTable,
-		offsetsTable: offsetsTable,
-		batchSize:    batchSize,
-	}
+	return New(sessionPool, WithOutboxTable(outboxTable), WithOffsetsTable(offsetsTable), WithBatchSize(batchSize))
 }
 
 func (o *PgOutbox) Publish(s session.Session, message *OutboxMessage) error {
@@ -45,12 +37,7 @@ func (o *PgOutbox) Publish(s session.Session, message *OutboxMessage) error {
 		VALUES ($1, $2, $3, pg_current_xact_id())
 	`, o.outboxTable)
 
-	payload, err := json.Marshal(message.Payload)
-	if err != nil {
-		return err
-	}
-
-	metadata, err := json.Marshal(message.Metadata)
+	payload, metadata, err := marshalMessage(s, message)
 	if err != nil {
 		return err
 	}
@@ -59,7 +46,35 @@ func (o *PgOutbox) Publish(s session.Session, message *OutboxMessage) error {
 	return err
 }
 
+// marshalMessage returns the JSON of the payload and of the metadata stamped with the causation of the session.
+func marshalMessage(s session.Session, message *OutboxMessage) ([]byte, []byte, error) {
+	payload, err := json.Marshal(message.Payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	metadata, err := json.Marshal(stampCausation(s, message.Metadata))
+	if err != nil {
+		return nil, nil, err
+	}
+	return payload, metadata, nil
+}
+
 func (o *PgOutbox) Dispatch(subscriber Subscriber, consumerGroup string, uri string, workerID int, numWorkers int) (bool, error) {
+	return o.DispatchBatchInTx(func(s session.Session, messages []*OutboxMessage) error {
+		for _, msg := range messages {
+			if err := subscriber(msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, consumerGroup, uri, workerID, numWorkers)
+}
+
+// DispatchBatchInTx passes the fetched batch to the handler with the transaction session
+// the position of the consumer group is acked in.
+// Read models written by the handler with that session are committed atomically with the position,
+// so a batch is either applied and acked as a whole or redelivered.
+func (o *PgOutbox) DispatchBatchInTx(handler BatchSubscriber, consumerGroup string, uri string, workerID int, numWorkers int) (bool, error) {
 	effectiveConsumerGroup := consumerGroup
 	if numWorkers > 1 {
 		effectiveConsumerGroup = fmt.Sprintf("%s:%d", consumerGroup, workerID)
@@ -67,10 +82,15 @@ func (o *PgOutbox) Dispatch(subscriber Subscriber, consumerGroup string, uri str
 
 	ctx := context.Background()
 
+	var paused bool
 	err := o.sessionPool.Session(ctx, func(s session.Session) error {
+		var err error
+		if paused, err = o.IsPaused(s, consumerGroup, uri); err != nil || paused {
+			return err
+		}
 		return o.ensureConsumerGroup(s, effectiveConsumerGroup, uri)
 	})
-	if err != nil {
+	if err != nil || paused {
 		return false, err
 	}
 
@@ -87,10 +107,8 @@ func (o *PgOutbox) Dispatch(subscriber Subscriber, consumerGroup string, uri str
 				return nil
 			}
 
-			for _, msg := range messages {
-				if err := subscriber(msg); err != nil {
-					return err
-				}
+			if err := handler(txSession, messages); err != nil {
+				return err
 			}
 
 			last := messages[len(messages)-1]
@@ -108,7 +126,7 @@ func (o *PgOutbox) Dispatch(subscriber Subscriber, consumerGroup string, uri str
 func (o *PgOutbox) Run(ctx context.Context, subscriber Subscriber, consumerGroup string, uri string, processID int, numProcesses int, concurrency int, pollInterval float64) error {
 	effectiveTotal := numProcesses * concurrency
 
-	workerLoop := func(localID int) error {
+	workerLoop := func(ctx context.Context, localID int) error {
 		effectiveID := processID*concurrency + localID
 		for {
 			select {
@@ -132,17 +150,17 @@ func (o *PgOutbox) Run(ctx context.Context, subscriber Subscriber, consumerGroup
 	}
 
 	if concurrency == 1 {
-		return workerLoop(0)
+		return workerLoop(ctx, 0)
 	}
 
-	errCh := make(chan error, concurrency)
+	// A failed worker stops the others, and Run returns when all of them have returned.
+	g, gctx := errgroup.WithContext(ctx)
 	for i := 0; i < concurrency; i++ {
-		go func(id int) {
-			errCh <- workerLoop(id)
-		}(i)
+		g.Go(func() error {
+			return workerLoop(gctx, i)
+		})
 	}
-
-	return <-errCh
+	return g.Wait()
 }
 
 func (o *PgOutbox) Messages(ctx context.Context, consumerGroup string, uri string, workerID int, numWorkers int, pollInterval float64) <-chan *OutboxMessage {
@@ -171,8 +189,24 @@ func (o *PgOutbox) Messages(ctx context.Context, consumerGroup string, uri strin
 			default:
 			}
 
-			var messages []*OutboxMessage
+			var paused bool
 			err := o.sessionPool.Session(bgCtx, func(s session.Session) error {
+				var err error
+				paused, err = o.IsPaused(s, consumerGroup, uri)
+				return err
+			})
+			// An unreadable pause flag backs off as a pause does, so a paused consumer group is never fetched.
+			if err != nil || paused {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Duration(pollInterval * float64(time.Second))):
+				}
+				continue
+			}
+
+			var messages []*OutboxMessage
+			err = o.sessionPool.Session(bgCtx, func(s session.Session) error {
 				return s.Atomic(func(txSession session.Session) error {
 					var err error
 					messages, err = o.fetchMessages(txSession, effectiveConsumerGroup, uri, workerID, numWorkers)
@@ -252,11 +286,56 @@ func (o *PgOutbox) Setup(s session.Session) error {
 	if err := o.createOutboxTable(s); err != nil {
 		return err
 	}
-	return o.createOffsetsTable(s)
+	if err := o.createOffsetsTable(s); err != nil {
+		return err
+	}
+	return o.createPausesTable(s)
 }
 
+// Cleanup deletes messages older than the retention (see WithRetention)
+// which all consumer groups have acknowledged. Without retention messages are kept.
+// Each shard of a sharded consumer group holds only the messages of its shard, until the shard passes them,
+// also if the shard has not been dispatched yet. Each worker of a consumer group dispatched by several workers
+// holds all messages after its position, since the number of workers is not stored.
 func (o *PgOutbox) Cleanup(s session.Session) error {
-	return nil
+	if o.retention <= 0 {
+		return nil
+	}
+	sql := fmt.Sprintf(`
+		DELETE FROM %[1]s AS m
+		WHERE m.created_at < CURRENT_TIMESTAMP - make_interval(secs => $1)
+		AND NOT EXISTS (
+			SELECT 1 FROM %[2]s AS c
+			LEFT JOIN LATERAL (
+				SELECT g.shard_count, n.shard
+				FROM %[2]s AS g, generate_series(0, g.shard_count - 1) AS n(shard)
+				WHERE g.shard_count IS NOT NULL AND g.uri = c.uri
+				AND c.consumer_group = g.consumer_group || ':shard:' || n.shard
+			) AS p ON TRUE
+			WHERE c.shard_count IS NULL
+			AND (c.uri = '' OR m.uri = c.uri OR m.uri LIKE c.uri || '/%%')
+			AND (p.shard_count IS NULL OR %[3]s)
+			AND (
+				m.transaction_id > c.last_processed_transaction_id
+				OR (m.transaction_id = c.last_processed_transaction_id AND m."position" > c.offset_acked)
+			)
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM %[2]s AS g, generate_series(0, g.shard_count - 1) AS n(shard)
+			WHERE g.shard_count IS NOT NULL
+			AND (g.uri = '' OR m.uri = g.uri OR m.uri LIKE g.uri || '/%%')
+			AND %[4]s
+			AND NOT EXISTS (
+				SELECT 1 FROM %[2]s AS c
+				WHERE c.consumer_group = g.consumer_group || ':shard:' || n.shard AND c.uri = g.uri
+			)
+		)
+	`, o.outboxTable, o.offsetsTable,
+		fmt.Sprintf(shardKeyExpr, "m.", "p.shard_count", "p.shard"),
+		fmt.Sprintf(shardKeyExpr, "m.", "g.shard_count", "n.shard"))
+
+	_, err := s.(session.DbSession).Connection().Exec(sql, o.retention.Seconds())
+	return err
 }
 
 func (o *PgOutbox) ensureConsumerGroup(s session.Session, consumerGroup string, uri string) error {
@@ -271,6 +350,10 @@ func (o *PgOutbox) ensureConsumerGroup(s session.Session, consumerGroup string,
 }
 
 func (o *PgOutbox) fetchMessages(s session.Session, consumerGroup string, uri string, workerID int, numWorkers int) ([]*OutboxMessage, error) {
+	return o.fetchPartition(s, consumerGroup, uri, "hashtext(%[1]suri) %% %[2]s = %[3]s", workerID, numWorkers)
+}
+
+func (o *PgOutbox) fetchPartition(s session.Session, consumerGroup string, uri string, partitionExpr string, partitionID int, numPartitions int) ([]*OutboxMessage, error) {
 	args := []any{consumerGroup, uri}
 	paramNum := 3
 
@@ -282,9 +365,9 @@ func (o *PgOutbox) fetchMessages(s session.Session, consumerGroup string, uri st
 	}
 
 	partitionFilter := ""
-	if numWorkers > 1 {
-		partitionFilter = fmt.Sprintf("AND hashtext(uri) %% $%d = $%d", paramNum, paramNum+1)
-		args = append(args, numWorkers, workerID)
+	if numPartitions > 1 {
+		partitionFilter = "AND " + fmt.Sprintf(partitionExpr, "", fmt.Sprintf("$%d", paramNum), fmt.Sprintf("$%d", paramNum+1))
+		args = append(args, numPartitions, partitionID)
 	}
 
 	sql := fmt.Sprintf(`
@@ -317,6 +400,10 @@ func (o *PgOutbox) fetchMessages(s session.Session, consumerGroup string, uri st
 	}
 	defer rows.Close()
 
+	return scanMessages(rows)
+}
+
+func scanMessages(rows session.Rows) ([]*OutboxMessage, error) {
 	var messages []*OutboxMessage
 	for rows.Next() {
 		var position int64
@@ -410,10 +497,16 @@ func (o *PgOutbox) createOffsetsTable(s session.Session) error {
 			"offset_acked" BIGINT NOT NULL DEFAULT 0,
 			"last_processed_transaction_id" xid8 NOT NULL DEFAULT '0',
 			"updated_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			"shard_count" INTEGER,
 			PRIMARY KEY ("consumer_group", "uri")
 		)
 	`, o.offsetsTable)
 
-	_, err := s.(session.DbSession).Connection().Exec(sql)
+	conn := s.(session.DbSession).Connection()
+	if _, err := conn.Exec(sql); err != nil {
+		return err
+	}
+
+	_, err := conn.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS "shard_count" INTEGER`, o.offsetsTable))
 	return err
 }
@@ -46,6 +46,10 @@ func truncateTables(t *testing.T, pool session.SessionPool) {
 			return err
 		}
 		_, err = conn.Exec("TRUNCATE TABLE " + testOffsetsTable)
+		if err != nil {
+			return err
+		}
+		_, err = conn.Exec("TRUNCATE TABLE " + testOffsetsTable + "_pauses")
 		return err
 	})
 	require.NoError(t, err)
@@ -57,6 +61,7 @@ func dropTables(t *testing.T, pool session.SessionPool) {
 		conn := s.(session.DbSession).Connection()
 		_, _ = conn.Exec("DROP TABLE IF EXISTS " + testOutboxTable)
 		_, _ = conn.Exec("DROP TABLE IF EXISTS " + testOffsetsTable)
+		_, _ = conn.Exec("DROP TABLE IF EXISTS " + testOffsetsTable + "_pauses")
 		return nil
 	})
 }
@@ -769,3 +774,182 @@ func TestMessagesChannelAPI(t *testing.T) {
 		assert.Equal(t, float64(i), msg.Payload["order"])
 	}
 }
+
+func countRows(t *testing.T, pool session.SessionPool, table string) int {
+	var count int
+	err := pool.Session(context.Background(), func(s session.Session) error {
+		return s.(session.DbSession).Connection().QueryRow("SELECT count(*) FROM " + table).Scan(&count)
+	})
+	require.NoError(t, err)
+	return count
+}
+
+func TestPauseDoesNotCreatePosition(t *testing.T) {
+	outbox, pool := setupOutbox(t)
+	defer dropTables(t, pool)
+
+	ctx := context.Background()
+
+	err := pool.Session(ctx, func(s session.Session) error {
+		return s.Atomic(func(txSession session.Session) error {
+			return outbox.Publish(txSession, &OutboxMessage{
+				URI:      "kafka://orders",
+				Payload:  map[string]any{"type": "OrderCreated"},
+				Metadata: map[string]any{"event_id": "550e8400-e29b-41d4-a716-446655440201"},
+			})
+		})
+	})
+	require.NoError(t, err)
+
+	err = pool.Session(ctx, func(s session.Session) error {
+		return outbox.Pause(s, "group-1", "kafka://orders")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, countRows(t, pool, testOffsetsTable))
+
+	var publishedMessages []*OutboxMessage
+	subscriber := func(msg *OutboxMessage) error {
+		publishedMessages = append(publishedMessages, msg)
+		return nil
+	}
+
+	result, err := outbox.Dispatch(subscriber, "group-1", "kafka://orders", 0, 1)
+	require.NoError(t, err)
+	assert.False(t, result)
+	assert.Empty(t, publishedMessages)
+	assert.Equal(t, 0, countRows(t, pool, testOffsetsTable))
+
+	err = pool.Session(ctx, func(s session.Session) error {
+		return outbox.Resume(s, "group-1", "kafka://orders")
+	})
+	require.NoError(t, err)
+
+	result, err = outbox.Dispatch(subscriber, "group-1", "kafka://orders", 0, 1)
+	require.NoError(t, err)
+	assert.True(t, result)
+	assert.Len(t, publishedMessages, 1)
+}
+
+func publishTestMessage(t *testing.T, outbox *PgOutbox, pool session.SessionPool, uri string, eventID string, orderingKey string) {
+	err := pool.Session(context.Background(), func(s session.Session) error {
+		return s.Atomic(func(txSession session.Session) error {
+			return outbox.Publish(txSession, &OutboxMessage{
+				URI:      uri,
+				Payload:  map[string]any{"type": "OrderCreated"},
+				Metadata: map[string]any{"event_id": eventID, OrderingKeyMetadata: orderingKey},
+			})
+		})
+	})
+	require.NoError(t, err)
+}
+
+func cleanup(t *testing.T, outbox *PgOutbox, pool session.SessionPool) {
+	err := pool.Session(context.Background(), func(s session.Session) error {
+		return outbox.Cleanup(s)
+	})
+	require.NoError(t, err)
+}
+
+func TestCleanupDeletesAcknowledgedMessages(t *testing.T) {
+	_, pool := setupOutbox(t)
+	defer dropTables(t, pool)
+
+	outbox := New(pool, WithOutboxTable(testOutboxTable), WithOffsetsTable(testOffsetsTable), WithRetention(time.Microsecond))
+	noop := func(msg *OutboxMessage) error { return nil }
+
+	publishTestMessage(t, outbox, pool, "kafka://orders", "550e8400-e29b-41d4-a716-446655440301", "order-1")
+	publishTestMessage(t, outbox, pool, "kafka://users", "550e8400-e29b-41d4-a716-446655440302", "user-1")
+
+	_, err := outbox.Dispatch(noop, "orders", "kafka://orders", 0, 1)
+	require.NoError(t, err)
+	_, err = outbox.Dispatch(noop, "users", "kafka://users", 0, 1)
+	require.NoError(t, err)
+
+	publishTestMessage(t, outbox, pool, "kafka://users", "550e8400-e29b-41d4-a716-446655440303", "user-2")
+
+	cleanup(t, outbox, pool)
+	assert.Equal(t, 1, countRows(t, pool, testOutboxTable), "only the message not acknowledged yet is kept")
+}
+
+func TestCleanupMatchesShardsByShardKey(t *testing.T) {
+	_, pool := setupOutbox(t)
+	defer dropTables(t, pool)
+
+	const numShards = 8
+	outbox := New(pool, WithOutboxTable(testOutboxTable), WithOffsetsTable(testOffsetsTable), WithRetention(time.Microsecond))
+	noop := func(msg *OutboxMessage) error { return nil }
+
+	publishTestMessage(t, outbox, pool, "kafka://orders", "550e8400-e29b-41d4-a716-446655440311", "order-1")
+
+	// The message is in one shard, the other shards stay idle at the start of the outbox
+	for shard := range numShards {
+		_, err := outbox.DispatchShard(noop, "workers", "", shard, numShards)
+		require.NoError(t, err)
+	}
+	cleanup(t, outbox, pool)
+	assert.Equal(t, 0, countRows(t, pool, testOutboxTable), "idle shards do not hold messages of other shards")
+
+	publishTestMessage(t, outbox, pool, "kafka://orders", "550e8400-e29b-41d4-a716-446655440312", "order-2")
+	cleanup(t, outbox, pool)
+	assert.Equal(t, 1, countRows(t, pool, testOutboxTable), "the shard of the message has not passed it yet")
+}
+
+func TestCleanupKeepsMessagesOfShardsNotDispatchedYet(t *testing.T) {
+	_, pool := setupOutbox(t)
+	defer dropTables(t, pool)
+
+	const numShards = 2
+	outbox := New(pool, WithOutboxTable(testOutboxTable), WithOffsetsTable(testOffsetsTable), WithRetention(time.Microsecond))
+
+	var delivered int
+	counter := func(msg *OutboxMessage) error {
+		delivered++
+		return nil
+	}
+
+	for i := range 10 {
+		publishTestMessage(t, outbox, pool, "kafka://orders", fmt.Sprintf("550e8400-e29b-41d4-a716-4466554403%02d", 20+i), fmt.Sprintf("order-%d", i))
+	}
+
+	// Only shard 0 is dispatched, the messages of shard 1 are kept for it
+	for {
+		hasMessages, err := outbox.DispatchShard(counter, "workers", "", 0, numShards)
+		require.NoError(t, err)
+		if !hasMessages {
+			break
+		}
+	}
+	cleanup(t, outbox, pool)
+	assert.Equal(t, 10-delivered, countRows(t, pool, testOutboxTable))
+}
+
+func TestWaitForProjectionOfOtherURI(t *testing.T) {
+	outbox, pool := setupOutbox(t)
+	defer dropTables(t, pool)
+	noop := func(msg *OutboxMessage) error { return nil }
+
+	publishTestMessage(t, outbox, pool, "kafka://orders", "550e8400-e29b-41d4-a716-446655440401", "order-1")
+	_, err := outbox.Dispatch(noop, "orders-projector", "kafka://orders", 0, 1)
+	require.NoError(t, err)
+
+	var position Position
+	err = pool.Session(context.Background(), func(s session.Session) error {
+		return s.Atomic(func(txSession session.Session) error {
+			var err error
+			position, err = outbox.PublishWithPosition(txSession, &OutboxMessage{
+				URI:      "kafka://users",
+				Payload:  map[string]any{"type": "UserCreated"},
+				Metadata: map[string]any{"event_id": "550e8400-e29b-41d4-a716-446655440402"},
+			})
+			return err
+		})
+	})
+	require.NoError(t, err)
+
+	waiter := NewProjectionWaiter(outbox, pool, "orders-projector", "kafka://orders")
+	require.NoError(t, waiter.WaitForProjection(context.Background(), position, 100*time.Millisecond))
+
+	usersWaiter := NewProjectionWaiter(outbox, pool, "users-projector", "kafka://users")
+	err = usersWaiter.WaitForProjection(context.Background(), position, 100*time.Millisecond)
+	assert.ErrorIs(t, err, ErrProjectionTimeout)
+}
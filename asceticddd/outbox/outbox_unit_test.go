@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -56,6 +57,14 @@ func (m *mockRows) Scan(dest ...any) error {
 			switch d := dest[i].(type) {
 			case *int64:
 				*d = val.(int64)
+			case *int:
+				if v, ok := val.(int); ok {
+					*d = v
+				}
+			case *bool:
+				if v, ok := val.(bool); ok {
+					*d = v
+				}
 			case *string:
 				*d = val.(string)
 			case *[]byte:
@@ -526,6 +535,112 @@ func TestDispatchAcknowledgesLastMessage(t *testing.T) {
 	assert.True(t, ackCalled)
 }
 
+func TestDispatchBatchInTxPassesBatchAndTransactionSession(t *testing.T) {
+	payload1, _ := json.Marshal(map[string]any{"type": "OrderCreated", "order_id": "123"})
+	metadata1, _ := json.Marshal(map[string]any{"event_id": "uuid-1"})
+	payload2, _ := json.Marshal(map[string]any{"type": "OrderShipped", "order_id": "123"})
+	metadata2, _ := json.Marshal(map[string]any{"event_id": "uuid-2"})
+
+	var ackArgs []any
+	conn := &mockConnection{
+		execFunc: func(query string, args ...any) (session.Result, error) {
+			if strings.Contains(query, "ON CONFLICT (consumer_group, uri) DO UPDATE") {
+				ackArgs = args
+			}
+			return &mockResult{}, nil
+		},
+		queryFunc: func(query string, args ...any) (session.Rows, error) {
+			return &mockRows{
+				rows: [][]any{
+					{int64(1), int64(100), "kafka://orders", payload1, metadata1, "2024-01-01 00:00:00"},
+					{int64(2), int64(100), "kafka://orders", payload2, metadata2, "2024-01-01 00:00:01"},
+				},
+			}, nil
+		},
+	}
+	dbSession := &mockDbSession{conn: conn}
+	pool := &mockSessionPool{session: dbSession}
+
+	outbox := NewOutbox(pool, "outbox", "outbox_offsets", 100)
+
+	var batches [][]*OutboxMessage
+	var handlerSession session.Session
+	handler := func(s session.Session, messages []*OutboxMessage) error {
+		handlerSession = s
+		batches = append(batches, messages)
+		return nil
+	}
+
+	result, err := outbox.DispatchBatchInTx(handler, "read-model", "", 0, 1)
+	require.NoError(t, err)
+
+	assert.True(t, result)
+	require.Len(t, batches, 1)
+	assert.Len(t, batches[0], 2)
+	assert.Equal(t, "OrderShipped", batches[0][1].Payload["type"])
+	assert.Same(t, dbSession, handlerSession)
+	assert.Equal(t, []any{"read-model", "", int64(2), "100"}, ackArgs)
+}
+
+func TestDispatchBatchInTxDoesNotAckFailedBatch(t *testing.T) {
+	payload1, _ := json.Marshal(map[string]any{"type": "OrderCreated", "order_id": "123"})
+	metadata1, _ := json.Marshal(map[string]any{"event_id": "uuid-1"})
+
+	ackCalled := false
+	conn := &mockConnection{
+		execFunc: func(query string, args ...any) (session.Result, error) {
+			if strings.Contains(query, "ON CONFLICT (consumer_group, uri) DO UPDATE") {
+				ackCalled = true
+			}
+			return &mockResult{}, nil
+		},
+		queryFunc: func(query string, args ...any) (session.Rows, error) {
+			return &mockRows{
+				rows: [][]any{
+					{int64(1), int64(100), "kafka://orders", payload1, metadata1, "2024-01-01 00:00:00"},
+				},
+			}, nil
+		},
+	}
+	dbSession := &mockDbSession{conn: conn}
+	pool := &mockSessionPool{session: dbSession}
+
+	outbox := NewOutbox(pool, "outbox", "outbox_offsets", 100)
+
+	handlerErr := errors.New("read model is unavailable")
+	result, err := outbox.DispatchBatchInTx(func(s session.Session, messages []*OutboxMessage) error {
+		return handlerErr
+	}, "read-model", "", 0, 1)
+
+	assert.ErrorIs(t, err, handlerErr)
+	assert.False(t, result)
+	assert.False(t, ackCalled)
+}
+
+func TestDispatchBatchInTxSkipsEmptyBatch(t *testing.T) {
+	conn := &mockConnection{
+		execFunc: func(query string, args ...any) (session.Result, error) {
+			return &mockResult{}, nil
+		},
+		queryFunc: func(query string, args ...any) (session.Rows, error) {
+			return &mockRows{}, nil
+		},
+	}
+	pool := &mockSessionPool{session: &mockDbSession{conn: conn}}
+
+	outbox := NewOutbox(pool, "outbox", "outbox_offsets", 100)
+
+	called := false
+	result, err := outbox.DispatchBatchInTx(func(s session.Session, messages []*OutboxMessage) error {
+		called = true
+		return nil
+	}, "read-model", "", 0, 1)
+	require.NoError(t, err)
+
+	assert.False(t, result)
+	assert.False(t, called)
+}
+
 func TestMessageCreation(t *testing.T) {
 	message := &OutboxMessage{
 		URI: "kafka://orders",
@@ -569,3 +684,52 @@ func TestMessageWithAllFields(t *testing.T) {
 	assert.Equal(t, int64(5), *message.Position)
 	assert.Equal(t, int64(100), *message.TransactionID)
 }
+
+func TestNewAppliesOptions(t *testing.T) {
+	outbox := New(nil, WithOutboxTable("custom_outbox"), WithOffsetsTable("custom_offsets"), WithBatchSize(10))
+
+	assert.Equal(t, "custom_outbox", outbox.outboxTable)
+	assert.Equal(t, "custom_offsets", outbox.offsetsTable)
+	assert.Equal(t, 10, outbox.batchSize)
+
+	defaults := New(nil, WithOutboxTable(""), WithBatchSize(0))
+	assert.Equal(t, "outbox", defaults.outboxTable)
+	assert.Equal(t, "outbox_offsets", defaults.offsetsTable)
+	assert.Equal(t, 100, defaults.batchSize)
+}
+
+func TestCleanupWithoutRetentionKeepsMessages(t *testing.T) {
+	conn := &mockConnection{}
+	dbSession := &mockDbSession{conn: conn}
+
+	err := New(nil).Cleanup(dbSession)
+	require.NoError(t, err)
+
+	assert.Empty(t, conn.lastQuery)
+}
+
+func TestCleanupQueryMatchesShardsByShardKey(t *testing.T) {
+	conn := &mockConnection{}
+	dbSession := &mockDbSession{conn: conn}
+
+	outbox := New(nil, WithOutboxTable("custom_outbox"), WithRetention(24*time.Hour))
+	err := outbox.Cleanup(dbSession)
+	require.NoError(t, err)
+
+	assert.Contains(t, conn.lastQuery, "DELETE FROM custom_outbox")
+	assert.Contains(t, conn.lastQuery, "LIKE c.uri || '/%'")
+	assert.Contains(t, conn.lastQuery, "c.consumer_group = g.consumer_group || ':shard:' || n.shard")
+	assert.Contains(t, conn.lastQuery, "hashtext(COALESCE(m.metadata->>'ordering_key', m.uri))::bigint), p.shard_count) = p.shard")
+	assert.Contains(t, conn.lastQuery, "hashtext(COALESCE(m.metadata->>'ordering_key', m.uri))::bigint), g.shard_count) = n.shard")
+	require.Len(t, conn.lastArgs, 1)
+	assert.Equal(t, float64(86400), conn.lastArgs[0])
+}
+
+func TestRunStopsAllWorkersOnError(t *testing.T) {
+	pool := &failingSessionPool{err: errors.New("connection refused")}
+	outbox := NewOutbox(pool, "outbox", "outbox_offsets", 100)
+
+	err := outbox.Run(context.Background(), func(*OutboxMessage) error { return nil }, "workers", "", 0, 1, 3, 0.001)
+	assert.ErrorIs(t, err, pool.err)
+	assertStopped(t, pool)
+}
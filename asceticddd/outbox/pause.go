@@ -0,0 +1,75 @@
+package outbox
+
+import (
+	"fmt"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+// Pause stops delivery to the consumer group without losing its position.
+// Running workers honor the flag between batches, so the batch in progress is completed.
+// The flag applies to all workers and shards of the consumer group.
+// It is kept in its own table, so pausing a consumer group which has no position yet does not create one.
+func (o *PgOutbox) Pause(s session.Session, consumerGroup string, uri string) error {
+	sql := fmt.Sprintf(`
+		INSERT INTO %s (consumer_group, uri)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, o.pausesTable())
+
+	_, err := s.(session.DbSession).Connection().Exec(sql, consumerGroup, uri)
+	return err
+}
+
+// Resume continues delivery to the consumer group from its stored position.
+func (o *PgOutbox) Resume(s session.Session, consumerGroup string, uri string) error {
+	sql := fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE consumer_group = $1 AND uri = $2
+	`, o.pausesTable())
+
+	_, err := s.(session.DbSession).Connection().Exec(sql, consumerGroup, uri)
+	return err
+}
+
+func (o *PgOutbox) IsPaused(s session.Session, consumerGroup string, uri string) (bool, error) {
+	sql := fmt.Sprintf(`
+		SELECT EXISTS (
+			SELECT 1 FROM %s
+			WHERE consumer_group = $1 AND uri = $2
+		)
+	`, o.pausesTable())
+
+	rows, err := s.(session.DbSession).Connection().Query(sql, consumerGroup, uri)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	var paused bool
+	if rows.Next() {
+		if err := rows.Scan(&paused); err != nil {
+			return false, err
+		}
+	}
+	return paused, rows.Err()
+}
+
+// pausesTable is the table of the paused consumer groups, named after the offsets table.
+func (o *PgOutbox) pausesTable() string {
+	return o.offsetsTable + "_pauses"
+}
+
+func (o *PgOutbox) createPausesTable(s session.Session) error {
+	sql := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			"consumer_group" VARCHAR(255) NOT NULL,
+			"uri" VARCHAR(255) NOT NULL DEFAULT '',
+			"paused_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY ("consumer_group", "uri")
+		)
+	`, o.pausesTable())
+
+	_, err := s.(session.DbSession).Connection().Exec(sql)
+	return err
+}
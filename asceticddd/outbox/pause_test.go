@@ -0,0 +1,123 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+func newPausedConnection(paused bool) (*mockConnection, *bool) {
+	fetched := false
+	conn := &mockConnection{
+		queryFunc: func(query string, args ...any) (session.Rows, error) {
+			if strings.Contains(query, "outbox_offsets_pauses") {
+				return &mockRows{rows: [][]any{{paused}}}, nil
+			}
+			fetched = true
+			return &mockRows{}, nil
+		},
+	}
+	return conn, &fetched
+}
+
+func TestPauseKeepsFlagApartFromOffsets(t *testing.T) {
+	conn := &mockConnection{}
+	dbSession := &mockDbSession{conn: conn}
+
+	outbox := NewOutbox(nil, "outbox", "outbox_offsets", 100)
+	err := outbox.Pause(dbSession, "test-group", "kafka://orders")
+	require.NoError(t, err)
+
+	assert.Contains(t, conn.lastQuery, "INSERT INTO outbox_offsets_pauses")
+	assert.Equal(t, []any{"test-group", "kafka://orders"}, conn.lastArgs)
+
+	err = outbox.Resume(dbSession, "test-group", "kafka://orders")
+	require.NoError(t, err)
+	assert.Contains(t, conn.lastQuery, "DELETE FROM outbox_offsets_pauses")
+	assert.Equal(t, []any{"test-group", "kafka://orders"}, conn.lastArgs)
+}
+
+func TestIsPausedReturnsFalseWhenNotFound(t *testing.T) {
+	conn := &mockConnection{}
+	dbSession := &mockDbSession{conn: conn}
+
+	outbox := NewOutbox(nil, "outbox", "outbox_offsets", 100)
+	paused, err := outbox.IsPaused(dbSession, "test-group", "")
+
+	require.NoError(t, err)
+	assert.False(t, paused)
+}
+
+func TestDispatchSkipsPausedConsumerGroup(t *testing.T) {
+	conn, fetched := newPausedConnection(true)
+	pool := &mockSessionPool{session: &mockDbSession{conn: conn}}
+	outbox := NewOutbox(pool, "outbox", "outbox_offsets", 100)
+
+	called := false
+	result, err := outbox.Dispatch(func(msg *OutboxMessage) error {
+		called = true
+		return nil
+	}, "test-group", "", 1, 3)
+	require.NoError(t, err)
+
+	assert.False(t, result)
+	assert.False(t, called)
+	assert.False(t, *fetched)
+	assert.Equal(t, "test-group", conn.lastArgs[0])
+}
+
+func TestDispatchShardSkipsPausedConsumerGroup(t *testing.T) {
+	conn, fetched := newPausedConnection(true)
+	pool := &mockSessionPool{session: &mockDbSession{conn: conn}}
+	outbox := NewOutbox(pool, "outbox", "outbox_offsets", 100)
+
+	result, err := outbox.DispatchShard(func(msg *OutboxMessage) error { return nil }, "test-group", "", 0, 4)
+	require.NoError(t, err)
+
+	assert.False(t, result)
+	assert.False(t, *fetched)
+}
+
+func TestMessagesWaitWhilePaused(t *testing.T) {
+	conn, fetched := newPausedConnection(true)
+	pool := &mockSessionPool{session: &mockDbSession{conn: conn}}
+	outbox := NewOutbox(pool, "outbox", "outbox_offsets", 100)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	for range outbox.Messages(ctx, "test-group", "", 0, 1, 0.01) {
+		t.Fatal("no messages expected while paused")
+	}
+	assert.False(t, *fetched)
+}
+
+func TestMessagesWaitWhenPauseIsUnknown(t *testing.T) {
+	fetched := false
+	conn := &mockConnection{
+		queryFunc: func(query string, args ...any) (session.Rows, error) {
+			if strings.Contains(query, "outbox_offsets_pauses") {
+				return nil, errors.New("connection reset")
+			}
+			fetched = true
+			return &mockRows{}, nil
+		},
+	}
+	pool := &mockSessionPool{session: &mockDbSession{conn: conn}}
+	outbox := NewOutbox(pool, "outbox", "outbox_offsets", 100)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	for range outbox.Messages(ctx, "test-group", "", 0, 1, 0.01) {
+		t.Fatal("no messages expected while the pause flag is unknown")
+	}
+	assert.False(t, fetched)
+}
@@ -0,0 +1,189 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+// OrderingKeyMetadata is the metadata key holding the ordering key of a message.
+// Messages without it are sharded by URI.
+const OrderingKeyMetadata = "ordering_key"
+
+var ErrShardCountMismatch = errors.New("shard count mismatch")
+
+// Sharding mode.
+//
+// Each message belongs to the shard hash(ordering_key) % N. A worker owns
+// a set of shards and tracks the position of every shard independently,
+// so all messages of the same ordering key are delivered by one worker
+// in order, and the number of workers can grow up to N without
+// breaking per-key ordering.
+//
+// N is stored in the offsets table on the first dispatch of the consumer group
+// and later dispatches with a different N are rejected, because changing it
+// would move keys between shards with independent positions.
+//
+// shardKeyExpr is the format of the condition that the message is in the shard,
+// of the prefix of the columns of the message, the number of shards and the shard.
+const shardKeyExpr = "mod(abs(hashtext(COALESCE(%[1]smetadata->>'" + OrderingKeyMetadata + "', %[1]suri))::bigint), %[2]s) = %[3]s"
+
+func (o *PgOutbox) DispatchShard(subscriber Subscriber, consumerGroup string, uri string, shard int, numShards int) (bool, error) {
+	if numShards < 1 || shard < 0 || shard >= numShards {
+		return false, fmt.Errorf("invalid shard %d of %d", shard, numShards)
+	}
+	shardConsumerGroup := fmt.Sprintf("%s:shard:%d", consumerGroup, shard)
+
+	ctx := context.Background()
+
+	var paused bool
+	err := o.sessionPool.Session(ctx, func(s session.Session) error {
+		var err error
+		if paused, err = o.IsPaused(s, consumerGroup, uri); err != nil || paused {
+			return err
+		}
+		if err := o.ensureShardCount(s, consumerGroup, uri, numShards); err != nil {
+			return err
+		}
+		return o.ensureConsumerGroup(s, shardConsumerGroup, uri)
+	})
+	if err != nil || paused {
+		return false, err
+	}
+
+	var messages []*OutboxMessage
+	err = o.sessionPool.Session(ctx, func(s session.Session) error {
+		return s.Atomic(func(txSession session.Session) error {
+			var err error
+			messages, err = o.fetchPartition(txSession, shardConsumerGroup, uri, shardKeyExpr, shard, numShards)
+			if err != nil {
+				return err
+			}
+
+			if len(messages) == 0 {
+				return nil
+			}
+
+			for _, msg := range messages {
+				if err := subscriber(msg); err != nil {
+					return err
+				}
+			}
+
+			last := messages[len(messages)-1]
+			return o.ackMessage(txSession, shardConsumerGroup, uri, *last.TransactionID, *last.Position)
+		})
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	return len(messages) > 0, nil
+}
+
+// RunSharded dispatches the given shards concurrently, one goroutine per shard,
+// until ctx is done or a shard fails. Use ShardsForWorker to distribute shards between processes.
+func (o *PgOutbox) RunSharded(ctx context.Context, subscriber Subscriber, consumerGroup string, uri string, shards []int, numShards int, pollInterval float64) error {
+	if len(shards) == 0 {
+		return fmt.Errorf("no shards to dispatch")
+	}
+
+	shardLoop := func(ctx context.Context, shard int) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			hasMessages, err := o.DispatchShard(subscriber, consumerGroup, uri, shard, numShards)
+			if err != nil {
+				return err
+			}
+			if !hasMessages {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(time.Duration(pollInterval * float64(time.Second))):
+				}
+			}
+		}
+	}
+
+	// A failed shard stops the others, and RunSharded returns when all of them have returned.
+	g, gctx := errgroup.WithContext(ctx)
+	for _, shard := range shards {
+		g.Go(func() error {
+			return shardLoop(gctx, shard)
+		})
+	}
+	return g.Wait()
+}
+
+// GetShardCount returns the stored shard count of the consumer group, or 0 if it is not sharded yet.
+func (o *PgOutbox) GetShardCount(s session.Session, consumerGroup string, uri string) (int, error) {
+	sql := fmt.Sprintf(`
+		SELECT COALESCE(shard_count, 0)
+		FROM %s
+		WHERE consumer_group = $1 AND uri = $2
+	`, o.offsetsTable)
+
+	rows, err := s.(session.DbSession).Connection().Query(sql, consumerGroup, uri)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var shardCount int
+	if rows.Next() {
+		if err := rows.Scan(&shardCount); err != nil {
+			return 0, err
+		}
+	}
+	return shardCount, rows.Err()
+}
+
+func (o *PgOutbox) ensureShardCount(s session.Session, consumerGroup string, uri string, numShards int) error {
+	shardCount, err := o.GetShardCount(s, consumerGroup, uri)
+	if err != nil {
+		return err
+	}
+	if shardCount == 0 {
+		sql := fmt.Sprintf(`
+			INSERT INTO %s (consumer_group, uri, offset_acked, last_processed_transaction_id, shard_count)
+			VALUES ($1, $2, 0, '0', $3)
+			ON CONFLICT (consumer_group, uri) DO UPDATE SET
+				shard_count = COALESCE(%s.shard_count, EXCLUDED.shard_count)
+		`, o.offsetsTable, o.offsetsTable)
+
+		if _, err := s.(session.DbSession).Connection().Exec(sql, consumerGroup, uri, numShards); err != nil {
+			return err
+		}
+		shardCount, err = o.GetShardCount(s, consumerGroup, uri)
+		if err != nil {
+			return err
+		}
+	}
+	if shardCount != numShards {
+		return fmt.Errorf(
+			"%w: consumer group %q uri %q has %d shards, got %d",
+			ErrShardCountMismatch, consumerGroup, uri, shardCount, numShards,
+		)
+	}
+	return nil
+}
+
+// ShardsForWorker returns the shards owned by the worker: shard % numWorkers == workerID.
+func ShardsForWorker(workerID int, numWorkers int, numShards int) []int {
+	var shards []int
+	for shard := workerID; shard < numShards; shard += numWorkers {
+		shards = append(shards, shard)
+	}
+	return shards
+}
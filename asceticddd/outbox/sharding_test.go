@@ -0,0 +1,136 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+func newShardingConnection(shardCount int, messages [][]any) (*mockConnection, *[]string) {
+	var ackedGroups []string
+	conn := &mockConnection{
+		execFunc: func(query string, args ...any) (session.Result, error) {
+			if strings.Contains(query, "offset_acked = EXCLUDED.offset_acked") {
+				ackedGroups = append(ackedGroups, args[0].(string))
+			}
+			return &mockResult{}, nil
+		},
+		queryFunc: func(query string, args ...any) (session.Rows, error) {
+			if strings.Contains(query, "shard_count") {
+				return &mockRows{rows: [][]any{{shardCount}}}, nil
+			}
+			return &mockRows{rows: messages}, nil
+		},
+	}
+	return conn, &ackedGroups
+}
+
+// failingSessionPool fails the first session and skips the callbacks of the others,
+// so that dispatches find no messages.
+type failingSessionPool struct {
+	mockSessionPool
+	err      error
+	sessions atomic.Int32
+}
+
+func (p *failingSessionPool) Session(ctx context.Context, callback session.SessionPoolCallback) error {
+	if p.sessions.Add(1) == 1 {
+		return p.err
+	}
+	return nil
+}
+
+// assertStopped asserts that no dispatch opens sessions anymore.
+func assertStopped(t *testing.T, pool *failingSessionPool) {
+	sessions := pool.sessions.Load()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, sessions, pool.sessions.Load())
+}
+
+func TestRunShardedStopsAllShardsOnError(t *testing.T) {
+	pool := &failingSessionPool{err: errors.New("connection refused")}
+	outbox := NewOutbox(pool, "outbox", "outbox_offsets", 100)
+
+	err := outbox.RunSharded(context.Background(), func(*OutboxMessage) error { return nil },
+		"workers", "", []int{0, 1, 2, 3}, 4, 0.001)
+	assert.ErrorIs(t, err, pool.err)
+	assertStopped(t, pool)
+}
+
+func TestDispatchShardTracksPositionPerShard(t *testing.T) {
+	payload, _ := json.Marshal(map[string]any{"type": "OrderCreated"})
+	metadata, _ := json.Marshal(map[string]any{"ordering_key": "order-1"})
+
+	conn, ackedGroups := newShardingConnection(4, [][]any{
+		{int64(1), int64(100), "kafka://orders", payload, metadata, "2024-01-01 00:00:00"},
+	})
+	pool := &mockSessionPool{session: &mockDbSession{conn: conn}}
+	outbox := NewOutbox(pool, "outbox", "outbox_offsets", 100)
+
+	var published []*OutboxMessage
+	result, err := outbox.DispatchShard(func(msg *OutboxMessage) error {
+		published = append(published, msg)
+		return nil
+	}, "workers", "", 2, 4)
+	require.NoError(t, err)
+
+	assert.True(t, result)
+	assert.Len(t, published, 1)
+	assert.Equal(t, []string{"workers:shard:2"}, *ackedGroups)
+}
+
+func TestDispatchShardRejectsShardCountMismatch(t *testing.T) {
+	conn, _ := newShardingConnection(8, nil)
+	pool := &mockSessionPool{session: &mockDbSession{conn: conn}}
+	outbox := NewOutbox(pool, "outbox", "outbox_offsets", 100)
+
+	_, err := outbox.DispatchShard(func(msg *OutboxMessage) error { return nil }, "workers", "", 0, 4)
+	assert.ErrorIs(t, err, ErrShardCountMismatch)
+}
+
+func TestDispatchShardRejectsInvalidShard(t *testing.T) {
+	outbox := NewOutbox(&mockSessionPool{}, "outbox", "outbox_offsets", 100)
+
+	_, err := outbox.DispatchShard(func(msg *OutboxMessage) error { return nil }, "workers", "", 4, 4)
+	assert.Error(t, err)
+}
+
+func TestFetchShardUsesOrderingKey(t *testing.T) {
+	conn := &mockConnection{}
+	dbSession := &mockDbSession{conn: conn}
+
+	outbox := NewOutbox(nil, "outbox", "outbox_offsets", 100)
+	_, err := outbox.fetchPartition(dbSession, "workers:shard:1", "kafka://orders", shardKeyExpr, 1, 4)
+	require.NoError(t, err)
+
+	assert.Contains(t, conn.lastQuery, "hashtext(COALESCE(metadata->>'ordering_key', uri))")
+	assert.Contains(t, conn.lastQuery, "$5) = $6")
+	assert.Equal(t, 4, conn.lastArgs[len(conn.lastArgs)-2])
+	assert.Equal(t, 1, conn.lastArgs[len(conn.lastArgs)-1])
+}
+
+func TestGetShardCountReturnsZeroWhenNotSharded(t *testing.T) {
+	conn := &mockConnection{}
+	dbSession := &mockDbSession{conn: conn}
+
+	outbox := NewOutbox(nil, "outbox", "outbox_offsets", 100)
+	shardCount, err := outbox.GetShardCount(dbSession, "workers", "")
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, shardCount)
+}
+
+func TestShardsForWorker(t *testing.T) {
+	assert.Equal(t, []int{0, 3, 6}, ShardsForWorker(0, 3, 8))
+	assert.Equal(t, []int{2, 5}, ShardsForWorker(2, 3, 8))
+	assert.Nil(t, ShardsForWorker(5, 6, 4))
+}
@@ -0,0 +1,192 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+const (
+	EventIdMetadata       = "event_id"
+	CausationIdMetadata   = "causation_id"
+	CorrelationIdMetadata = "correlation_id"
+)
+
+// Causation identifies the message being handled.
+// Messages published while handling it are stamped with
+// causation_id = CausationId and correlation_id = CorrelationId.
+type Causation struct {
+	CausationId   string
+	CorrelationId string
+}
+
+// CausationFromMetadata returns the causation for messages caused by the message with the metadata.
+// The correlation_id is inherited, or starts from the event_id for the first message of the chain.
+func CausationFromMetadata(metadata map[string]any) Causation {
+	eventId, _ := metadata[EventIdMetadata].(string)
+	correlationId, _ := metadata[CorrelationIdMetadata].(string)
+	if correlationId == "" {
+		correlationId = eventId
+	}
+	return Causation{
+		CausationId:   eventId,
+		CorrelationId: correlationId,
+	}
+}
+
+type causationKey struct{}
+
+func ContextWithCausation(ctx context.Context, causation Causation) context.Context {
+	return context.WithValue(ctx, causationKey{}, causation)
+}
+
+func CausationFromContext(ctx context.Context) (Causation, bool) {
+	if ctx == nil {
+		return Causation{}, false
+	}
+	causation, ok := ctx.Value(causationKey{}).(Causation)
+	return causation, ok
+}
+
+// WithCausation returns the session whose Context() carries the causation,
+// so that Publish stamps it into any message published within the session
+// and its nested Atomic scopes.
+func WithCausation(s session.Session, causation Causation) session.Session {
+	ctx := ContextWithCausation(s.Context(), causation)
+	if dbSession, ok := s.(session.DbSession); ok {
+		return &causedDbSession{DbSession: dbSession, ctx: ctx, causation: causation}
+	}
+	return &causedSession{Session: s, ctx: ctx, causation: causation}
+}
+
+// CausedBy returns the session in which published messages are caused by the handled message.
+func CausedBy(s session.Session, message *OutboxMessage) session.Session {
+	return WithCausation(s, CausationFromMetadata(message.Metadata))
+}
+
+// ForEachMessage returns the BatchSubscriber passing the messages of the batch to the handler one by one,
+// each with the transaction session of the dispatch wrapped by CausedBy,
+// so that messages published by the handler are stamped with the causation of the handled message.
+func ForEachMessage(handler MessageHandler) BatchSubscriber {
+	return func(s session.Session, messages []*OutboxMessage) error {
+		for _, message := range messages {
+			if err := handler(CausedBy(s, message), message); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+type causedSession struct {
+	session.Session
+	ctx       context.Context
+	causation Causation
+}
+
+func (s *causedSession) Context() context.Context {
+	return s.ctx
+}
+
+func (s *causedSession) Atomic(callback session.SessionCallback) error {
+	return s.Session.Atomic(func(txSession session.Session) error {
+		return callback(WithCausation(txSession, s.causation))
+	})
+}
+
+type causedDbSession struct {
+	session.DbSession
+	ctx       context.Context
+	causation Causation
+}
+
+func (s *causedDbSession) Context() context.Context {
+	return s.ctx
+}
+
+func (s *causedDbSession) Atomic(callback session.SessionCallback) error {
+	return s.DbSession.Atomic(func(txSession session.Session) error {
+		return callback(WithCausation(txSession, s.causation))
+	})
+}
+
+// stampCausation returns the metadata with causation_id and correlation_id from the session context,
+// unless they are set explicitly. The metadata is copied, the message of the caller is not modified.
+func stampCausation(s session.Session, metadata map[string]any) map[string]any {
+	causation, ok := CausationFromContext(s.Context())
+	if !ok {
+		return metadata
+	}
+	stamped := make(map[string]any, len(metadata)+2)
+	maps.Copy(stamped, metadata)
+	if _, ok := stamped[CausationIdMetadata]; !ok && causation.CausationId != "" {
+		stamped[CausationIdMetadata] = causation.CausationId
+	}
+	if _, ok := stamped[CorrelationIdMetadata]; !ok && causation.CorrelationId != "" {
+		stamped[CorrelationIdMetadata] = causation.CorrelationId
+	}
+	return stamped
+}
+
+// BuildCausalChain returns the chain of messages which caused the message with the eventId,
+// from the root cause to the message itself. Messages may be collected from several services.
+// The chain stops at the first message whose cause is not among the messages.
+func BuildCausalChain(messages []*OutboxMessage, eventId string) ([]*OutboxMessage, error) {
+	byEventId := make(map[string]*OutboxMessage, len(messages))
+	for _, message := range messages {
+		if id, ok := message.Metadata[EventIdMetadata].(string); ok {
+			byEventId[id] = message
+		}
+	}
+	var chain []*OutboxMessage
+	visited := map[string]bool{}
+	for id := eventId; id != ""; {
+		message, ok := byEventId[id]
+		if !ok {
+			break
+		}
+		if visited[id] {
+			return nil, fmt.Errorf("causal cycle detected at event_id %s", id)
+		}
+		visited[id] = true
+		chain = append(chain, message)
+		id, _ = message.Metadata[CausationIdMetadata].(string)
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain, nil
+}
+
+// CausalChain loads the causal chain of the message with the eventId from the outbox table,
+// from the root cause to the message itself.
+func (o *PgOutbox) CausalChain(s session.Session, eventId string) ([]*OutboxMessage, error) {
+	sql := fmt.Sprintf(`
+		WITH RECURSIVE chain AS (
+			SELECT "position", transaction_id, uri, payload, metadata, created_at, 0 AS depth
+			FROM %s
+			WHERE metadata->>'event_id' = $1
+			UNION ALL
+			SELECT o."position", o.transaction_id, o.uri, o.payload, o.metadata, o.created_at, chain.depth + 1
+			FROM %s o
+			JOIN chain ON o.metadata->>'event_id' = chain.metadata->>'causation_id'
+			WHERE chain.depth < $2
+		)
+		SELECT "position", transaction_id, uri, payload, metadata, created_at
+		FROM chain
+		ORDER BY depth DESC
+	`, o.outboxTable, o.outboxTable)
+
+	rows, err := s.(session.DbSession).Connection().Query(sql, eventId, maxCausalChainDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMessages(rows)
+}
+
+// maxCausalChainDepth guards the recursive query against cycles.
+const maxCausalChainDepth = 1000
@@ -0,0 +1,157 @@
+package outbox
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+func TestCausationFromMetadata(t *testing.T) {
+	causation := CausationFromMetadata(map[string]any{"event_id": "e2", "correlation_id": "e1"})
+	assert.Equal(t, Causation{CausationId: "e2", CorrelationId: "e1"}, causation)
+
+	causation = CausationFromMetadata(map[string]any{"event_id": "e1"})
+	assert.Equal(t, Causation{CausationId: "e1", CorrelationId: "e1"}, causation)
+
+	assert.Equal(t, Causation{}, CausationFromMetadata(nil))
+}
+
+func TestPublishStampsCausation(t *testing.T) {
+	conn := &mockConnection{}
+	dbSession := &mockDbSession{conn: conn}
+	outbox := NewOutbox(nil, "outbox", "outbox_offsets", 100)
+
+	caused := WithCausation(dbSession, Causation{CausationId: "e2", CorrelationId: "e1"})
+	message := &OutboxMessage{
+		URI:     "kafka://orders",
+		Payload: map[string]any{"type": "OrderShipped"},
+	}
+	err := caused.Atomic(func(txSession session.Session) error {
+		return outbox.Publish(txSession, message)
+	})
+	require.NoError(t, err)
+
+	assert.Nil(t, message.Metadata)
+
+	var metadata map[string]any
+	require.NoError(t, json.Unmarshal(conn.lastArgs[2].([]byte), &metadata))
+	assert.Equal(t, "e2", metadata["causation_id"])
+	assert.Equal(t, "e1", metadata["correlation_id"])
+}
+
+func TestPublishKeepsExplicitCausation(t *testing.T) {
+	conn := &mockConnection{}
+	dbSession := &mockDbSession{conn: conn}
+	outbox := NewOutbox(nil, "outbox", "outbox_offsets", 100)
+
+	message := &OutboxMessage{
+		URI:      "kafka://orders",
+		Metadata: map[string]any{"causation_id": "cmd-1"},
+	}
+	err := outbox.Publish(WithCausation(dbSession, Causation{CausationId: "e2", CorrelationId: "e1"}), message)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{"causation_id": "cmd-1"}, message.Metadata)
+
+	var metadata map[string]any
+	require.NoError(t, json.Unmarshal(conn.lastArgs[2].([]byte), &metadata))
+	assert.Equal(t, "cmd-1", metadata["causation_id"])
+	assert.Equal(t, "e1", metadata["correlation_id"])
+}
+
+func TestPublishWithoutCausation(t *testing.T) {
+	conn := &mockConnection{}
+	outbox := NewOutbox(nil, "outbox", "outbox_offsets", 100)
+
+	message := &OutboxMessage{URI: "kafka://orders"}
+	err := outbox.Publish(&mockDbSession{conn: conn}, message)
+	require.NoError(t, err)
+
+	assert.Nil(t, message.Metadata)
+}
+
+func TestBuildCausalChain(t *testing.T) {
+	e1 := &OutboxMessage{Metadata: map[string]any{"event_id": "e1"}}
+	e2 := &OutboxMessage{Metadata: map[string]any{"event_id": "e2", "causation_id": "e1", "correlation_id": "e1"}}
+	e3 := &OutboxMessage{Metadata: map[string]any{"event_id": "e3", "causation_id": "e2", "correlation_id": "e1"}}
+	other := &OutboxMessage{Metadata: map[string]any{"event_id": "x", "causation_id": "e1"}}
+
+	chain, err := BuildCausalChain([]*OutboxMessage{e3, other, e1, e2}, "e3")
+	require.NoError(t, err)
+	assert.Equal(t, []*OutboxMessage{e1, e2, e3}, chain)
+
+	chain, err = BuildCausalChain([]*OutboxMessage{e3, e2}, "e3")
+	require.NoError(t, err)
+	assert.Equal(t, []*OutboxMessage{e2, e3}, chain)
+
+	chain, err = BuildCausalChain([]*OutboxMessage{e1}, "missing")
+	require.NoError(t, err)
+	assert.Empty(t, chain)
+
+	a := &OutboxMessage{Metadata: map[string]any{"event_id": "a", "causation_id": "b"}}
+	b := &OutboxMessage{Metadata: map[string]any{"event_id": "b", "causation_id": "a"}}
+	_, err = BuildCausalChain([]*OutboxMessage{a, b}, "a")
+	assert.Error(t, err)
+}
+
+func TestCausalChainQuery(t *testing.T) {
+	conn := &mockConnection{}
+	outbox := NewOutbox(nil, "outbox", "outbox_offsets", 100)
+
+	_, err := outbox.CausalChain(&mockDbSession{conn: conn}, "e3")
+	require.NoError(t, err)
+
+	assert.Contains(t, conn.lastQuery, "WITH RECURSIVE")
+	assert.Contains(t, conn.lastQuery, "ORDER BY depth DESC")
+	assert.Equal(t, "e3", conn.lastArgs[0])
+}
+
+func TestDispatchedHandlerPublishesCausedMessages(t *testing.T) {
+	payload, _ := json.Marshal(map[string]any{"type": "OrderCreated"})
+	metadata1, _ := json.Marshal(map[string]any{"event_id": "e1"})
+	metadata2, _ := json.Marshal(map[string]any{"event_id": "e3", "correlation_id": "e0"})
+
+	var published []map[string]any
+	conn := &mockConnection{
+		execFunc: func(query string, args ...any) (session.Result, error) {
+			if strings.Contains(query, "INSERT INTO outbox ") {
+				var metadata map[string]any
+				if err := json.Unmarshal(args[2].([]byte), &metadata); err != nil {
+					return nil, err
+				}
+				published = append(published, metadata)
+			}
+			return &mockResult{}, nil
+		},
+		queryFunc: func(query string, args ...any) (session.Rows, error) {
+			return &mockRows{
+				rows: [][]any{
+					{int64(1), int64(100), "kafka://orders", payload, metadata1, "2024-01-01 00:00:00"},
+					{int64(2), int64(100), "kafka://orders", payload, metadata2, "2024-01-01 00:00:01"},
+				},
+			}, nil
+		},
+	}
+	outbox := NewOutbox(&mockSessionPool{session: &mockDbSession{conn: conn}}, "outbox", "outbox_offsets", 100)
+
+	result, err := outbox.DispatchBatchInTx(ForEachMessage(func(s session.Session, message *OutboxMessage) error {
+		return s.Atomic(func(txSession session.Session) error {
+			return outbox.Publish(txSession, &OutboxMessage{
+				URI:     "kafka://invoices",
+				Payload: map[string]any{"type": "InvoiceIssued"},
+			})
+		})
+	}), "invoicing", "", 0, 1)
+	require.NoError(t, err)
+
+	assert.True(t, result)
+	assert.Equal(t, []map[string]any{
+		{"causation_id": "e1", "correlation_id": "e1"},
+		{"causation_id": "e3", "correlation_id": "e0"},
+	}, published)
+}
@@ -1,192 +1,23 @@
-package saga
+package saga_test
 
 import (
 	"context"
 	"testing"
-)
-
-type primaryActivity struct {
-	callCount       *int
-	compensateCount *int
-	shouldFail      *bool
-}
-
-func newPrimaryActivity(callCount, compensateCount *int, shouldFail *bool) ActivityType {
-	return func() Activity {
-		return &primaryActivity{
-			callCount:       callCount,
-			compensateCount: compensateCount,
-			shouldFail:      shouldFail,
-		}
-	}
-}
-
-func (a *primaryActivity) DoWork(ctx context.Context, workItem WorkItem) (*WorkLog, error) {
-	*a.callCount++
-	if *a.shouldFail {
-		return nil, nil
-	}
-	value := "default"
-	if v, ok := workItem.Arguments()["value"]; ok {
-		value = v.(string)
-	}
-	workLog := NewWorkLog(a, WorkResult{"provider": "primary", "value": value})
-	return &workLog, nil
-}
-
-func (a *primaryActivity) Compensate(ctx context.Context, workLog WorkLog, routingSlip *RoutingSlip) (bool, error) {
-	*a.compensateCount++
-	return true, nil
-}
-
-func (a *primaryActivity) WorkItemQueueAddress() string {
-	return "sb://./primary"
-}
-
-func (a *primaryActivity) CompensationQueueAddress() string {
-	return "sb://./primaryCompensation"
-}
-
-func (a *primaryActivity) ActivityType() ActivityType {
-	return newPrimaryActivity(a.callCount, a.compensateCount, a.shouldFail)
-}
-
-type backupActivity struct {
-	callCount       *int
-	compensateCount *int
-	shouldFail      *bool
-}
-
-func newBackupActivity(callCount, compensateCount *int, shouldFail *bool) ActivityType {
-	return func() Activity {
-		return &backupActivity{
-			callCount:       callCount,
-			compensateCount: compensateCount,
-			shouldFail:      shouldFail,
-		}
-	}
-}
-
-func (a *backupActivity) DoWork(ctx context.Context, workItem WorkItem) (*WorkLog, error) {
-	*a.callCount++
-	if *a.shouldFail {
-		return nil, nil
-	}
-	value := "default"
-	if v, ok := workItem.Arguments()["value"]; ok {
-		value = v.(string)
-	}
-	workLog := NewWorkLog(a, WorkResult{"provider": "backup", "value": value})
-	return &workLog, nil
-}
-
-func (a *backupActivity) Compensate(ctx context.Context, workLog WorkLog, routingSlip *RoutingSlip) (bool, error) {
-	*a.compensateCount++
-	return true, nil
-}
-
-func (a *backupActivity) WorkItemQueueAddress() string {
-	return "sb://./backup"
-}
-
-func (a *backupActivity) CompensationQueueAddress() string {
-	return "sb://./backupCompensation"
-}
-
-func (a *backupActivity) ActivityType() ActivityType {
-	return newBackupActivity(a.callCount, a.compensateCount, a.shouldFail)
-}
-
-type thirdActivity struct {
-	callCount       *int
-	compensateCount *int
-}
-
-func newThirdActivity(callCount, compensateCount *int) ActivityType {
-	return func() Activity {
-		return &thirdActivity{
-			callCount:       callCount,
-			compensateCount: compensateCount,
-		}
-	}
-}
-
-func (a *thirdActivity) DoWork(ctx context.Context, workItem WorkItem) (*WorkLog, error) {
-	*a.callCount++
-	workLog := NewWorkLog(a, WorkResult{"provider": "third"})
-	return &workLog, nil
-}
-
-func (a *thirdActivity) Compensate(ctx context.Context, workLog WorkLog, routingSlip *RoutingSlip) (bool, error) {
-	*a.compensateCount++
-	return true, nil
-}
-
-func (a *thirdActivity) WorkItemQueueAddress() string {
-	return "sb://./third"
-}
-
-func (a *thirdActivity) CompensationQueueAddress() string {
-	return "sb://./thirdCompensation"
-}
-
-func (a *thirdActivity) ActivityType() ActivityType {
-	return newThirdActivity(a.callCount, a.compensateCount)
-}
-
-type confirmActivity struct {
-	callCount       *int
-	compensateCount *int
-}
-
-func newConfirmActivity(callCount, compensateCount *int) ActivityType {
-	return func() Activity {
-		return &confirmActivity{
-			callCount:       callCount,
-			compensateCount: compensateCount,
-		}
-	}
-}
-
-func (a *confirmActivity) DoWork(ctx context.Context, workItem WorkItem) (*WorkLog, error) {
-	*a.callCount++
-	workLog := NewWorkLog(a, WorkResult{"confirmed": true})
-	return &workLog, nil
-}
-
-func (a *confirmActivity) Compensate(ctx context.Context, workLog WorkLog, routingSlip *RoutingSlip) (bool, error) {
-	*a.compensateCount++
-	return true, nil
-}
 
-func (a *confirmActivity) WorkItemQueueAddress() string {
-	return "sb://./confirm"
-}
-
-func (a *confirmActivity) CompensationQueueAddress() string {
-	return "sb://./confirmCompensation"
-}
-
-func (a *confirmActivity) ActivityType() ActivityType {
-	return newConfirmActivity(a.callCount, a.compensateCount)
-}
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/saga"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/saga/sagatest"
+)
 
 func TestFallbackActivity_PrimarySucceeds(t *testing.T) {
-	callCountPrimary := 0
-	compensateCountPrimary := 0
-	shouldFailPrimary := false
-	primaryType := newPrimaryActivity(&callCountPrimary, &compensateCountPrimary, &shouldFailPrimary)
-
-	callCountBackup := 0
-	compensateCountBackup := 0
-	shouldFailBackup := false
-	backupType := newBackupActivity(&callCountBackup, &compensateCountBackup, &shouldFailBackup)
-
-	activity := NewFallbackActivity()
-	workItem := NewWorkItem(NewFallbackActivity, WorkItemArguments{
-		"alternatives": []*RoutingSlip{
-			NewRoutingSlip([]WorkItem{NewWorkItem(primaryType, WorkItemArguments{"value": "test"})}),
-			NewRoutingSlip([]WorkItem{NewWorkItem(backupType, WorkItemArguments{"value": "test"})}),
+	recorder := sagatest.NewRecorder()
+	primary := sagatest.NewScript("primary", recorder)
+	backup := sagatest.NewScript("backup", recorder)
+
+	activity := saga.NewFallbackActivity()
+	workItem := saga.NewWorkItem(saga.NewFallbackActivity, saga.WorkItemArguments{
+		"alternatives": []*saga.RoutingSlip{
+			saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(primary.ActivityType(), saga.WorkItemArguments{"value": "test"})}),
+			saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(backup.ActivityType(), saga.WorkItemArguments{"value": "test"})}),
 		},
 	})
 
@@ -199,30 +30,19 @@ func TestFallbackActivity_PrimarySucceeds(t *testing.T) {
 	if result == nil {
 		t.Error("Expected non-nil result")
 	}
-	if callCountPrimary != 1 {
-		t.Errorf("Expected primary call count 1, got %d", callCountPrimary)
-	}
-	if callCountBackup != 0 {
-		t.Errorf("Expected backup call count 0, got %d", callCountBackup)
-	}
+	recorder.AssertTrace(t, "do:primary")
 }
 
 func TestFallbackActivity_PrimaryFailsBackupSucceeds(t *testing.T) {
-	callCountPrimary := 0
-	compensateCountPrimary := 0
-	shouldFailPrimary := true
-	primaryType := newPrimaryActivity(&callCountPrimary, &compensateCountPrimary, &shouldFailPrimary)
-
-	callCountBackup := 0
-	compensateCountBackup := 0
-	shouldFailBackup := false
-	backupType := newBackupActivity(&callCountBackup, &compensateCountBackup, &shouldFailBackup)
-
-	activity := NewFallbackActivity()
-	workItem := NewWorkItem(NewFallbackActivity, WorkItemArguments{
-		"alternatives": []*RoutingSlip{
-			NewRoutingSlip([]WorkItem{NewWorkItem(primaryType, WorkItemArguments{"value": "test"})}),
-			NewRoutingSlip([]WorkItem{NewWorkItem(backupType, WorkItemArguments{"value": "test"})}),
+	recorder := sagatest.NewRecorder()
+	primary := sagatest.NewScript("primary", recorder).AlwaysFail()
+	backup := sagatest.NewScript("backup", recorder)
+
+	activity := saga.NewFallbackActivity()
+	workItem := saga.NewWorkItem(saga.NewFallbackActivity, saga.WorkItemArguments{
+		"alternatives": []*saga.RoutingSlip{
+			saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(primary.ActivityType(), saga.WorkItemArguments{"value": "test"})}),
+			saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(backup.ActivityType(), saga.WorkItemArguments{"value": "test"})}),
 		},
 	})
 
@@ -235,30 +55,20 @@ func TestFallbackActivity_PrimaryFailsBackupSucceeds(t *testing.T) {
 	if result == nil {
 		t.Error("Expected non-nil result")
 	}
-	if callCountPrimary != 1 {
-		t.Errorf("Expected primary call count 1, got %d", callCountPrimary)
-	}
-	if callCountBackup != 1 {
-		t.Errorf("Expected backup call count 1, got %d", callCountBackup)
-	}
+	recorder.AssertTrace(t, "do:primary!", "do:backup")
 }
 
 func TestFallbackActivity_MultiStepAlternative(t *testing.T) {
-	callCountPrimary := 0
-	compensateCountPrimary := 0
-	shouldFailPrimary := false
-	primaryType := newPrimaryActivity(&callCountPrimary, &compensateCountPrimary, &shouldFailPrimary)
-
-	callCountConfirm := 0
-	compensateCountConfirm := 0
-	confirmType := newConfirmActivity(&callCountConfirm, &compensateCountConfirm)
-
-	activity := NewFallbackActivity()
-	workItem := NewWorkItem(NewFallbackActivity, WorkItemArguments{
-		"alternatives": []*RoutingSlip{
-			NewRoutingSlip([]WorkItem{
-				NewWorkItem(primaryType, WorkItemArguments{"value": "step1"}),
-				NewWorkItem(confirmType, WorkItemArguments{}),
+	recorder := sagatest.NewRecorder()
+	primary := sagatest.NewScript("primary", recorder)
+	confirm := sagatest.NewScript("confirm", recorder)
+
+	activity := saga.NewFallbackActivity()
+	workItem := saga.NewWorkItem(saga.NewFallbackActivity, saga.WorkItemArguments{
+		"alternatives": []*saga.RoutingSlip{
+			saga.NewRoutingSlip([]saga.WorkItem{
+				saga.NewWorkItem(primary.ActivityType(), saga.WorkItemArguments{"value": "step1"}),
+				saga.NewWorkItem(confirm.ActivityType(), saga.WorkItemArguments{}),
 			}),
 		},
 	})
@@ -272,30 +82,19 @@ func TestFallbackActivity_MultiStepAlternative(t *testing.T) {
 	if result == nil {
 		t.Error("Expected non-nil result")
 	}
-	if callCountPrimary != 1 {
-		t.Errorf("Expected primary call count 1, got %d", callCountPrimary)
-	}
-	if callCountConfirm != 1 {
-		t.Errorf("Expected confirm call count 1, got %d", callCountConfirm)
-	}
+	recorder.AssertExecuted(t, "primary", "confirm")
 }
 
 func TestFallbackActivity_AllAlternativesFail(t *testing.T) {
-	callCountPrimary := 0
-	compensateCountPrimary := 0
-	shouldFailPrimary := true
-	primaryType := newPrimaryActivity(&callCountPrimary, &compensateCountPrimary, &shouldFailPrimary)
-
-	callCountBackup := 0
-	compensateCountBackup := 0
-	shouldFailBackup := true
-	backupType := newBackupActivity(&callCountBackup, &compensateCountBackup, &shouldFailBackup)
-
-	activity := NewFallbackActivity()
-	workItem := NewWorkItem(NewFallbackActivity, WorkItemArguments{
-		"alternatives": []*RoutingSlip{
-			NewRoutingSlip([]WorkItem{NewWorkItem(primaryType, WorkItemArguments{"value": "test"})}),
-			NewRoutingSlip([]WorkItem{NewWorkItem(backupType, WorkItemArguments{"value": "test"})}),
+	recorder := sagatest.NewRecorder()
+	primary := sagatest.NewScript("primary", recorder).AlwaysFail()
+	backup := sagatest.NewScript("backup", recorder).AlwaysFail()
+
+	activity := saga.NewFallbackActivity()
+	workItem := saga.NewWorkItem(saga.NewFallbackActivity, saga.WorkItemArguments{
+		"alternatives": []*saga.RoutingSlip{
+			saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(primary.ActivityType(), saga.WorkItemArguments{"value": "test"})}),
+			saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(backup.ActivityType(), saga.WorkItemArguments{"value": "test"})}),
 		},
 	})
 
@@ -308,35 +107,21 @@ func TestFallbackActivity_AllAlternativesFail(t *testing.T) {
 	if result != nil {
 		t.Error("Expected nil result when all alternatives fail")
 	}
-	if callCountPrimary != 1 {
-		t.Errorf("Expected primary call count 1, got %d", callCountPrimary)
-	}
-	if callCountBackup != 1 {
-		t.Errorf("Expected backup call count 1, got %d", callCountBackup)
-	}
+	recorder.AssertTrace(t, "do:primary!", "do:backup!")
 }
 
 func TestFallbackActivity_ThirdAlternativeSucceeds(t *testing.T) {
-	callCountPrimary := 0
-	compensateCountPrimary := 0
-	shouldFailPrimary := true
-	primaryType := newPrimaryActivity(&callCountPrimary, &compensateCountPrimary, &shouldFailPrimary)
-
-	callCountBackup := 0
-	compensateCountBackup := 0
-	shouldFailBackup := true
-	backupType := newBackupActivity(&callCountBackup, &compensateCountBackup, &shouldFailBackup)
-
-	callCountThird := 0
-	compensateCountThird := 0
-	thirdType := newThirdActivity(&callCountThird, &compensateCountThird)
-
-	activity := NewFallbackActivity()
-	workItem := NewWorkItem(NewFallbackActivity, WorkItemArguments{
-		"alternatives": []*RoutingSlip{
-			NewRoutingSlip([]WorkItem{NewWorkItem(primaryType, WorkItemArguments{})}),
-			NewRoutingSlip([]WorkItem{NewWorkItem(backupType, WorkItemArguments{})}),
-			NewRoutingSlip([]WorkItem{NewWorkItem(thirdType, WorkItemArguments{})}),
+	recorder := sagatest.NewRecorder()
+	primary := sagatest.NewScript("primary", recorder).AlwaysFail()
+	backup := sagatest.NewScript("backup", recorder).AlwaysFail()
+	third := sagatest.NewScript("third", recorder)
+
+	activity := saga.NewFallbackActivity()
+	workItem := saga.NewWorkItem(saga.NewFallbackActivity, saga.WorkItemArguments{
+		"alternatives": []*saga.RoutingSlip{
+			saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(primary.ActivityType(), saga.WorkItemArguments{})}),
+			saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(backup.ActivityType(), saga.WorkItemArguments{})}),
+			saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(third.ActivityType(), saga.WorkItemArguments{})}),
 		},
 	})
 
@@ -349,33 +134,19 @@ func TestFallbackActivity_ThirdAlternativeSucceeds(t *testing.T) {
 	if result == nil {
 		t.Error("Expected non-nil result")
 	}
-	if callCountPrimary != 1 {
-		t.Errorf("Expected primary call count 1, got %d", callCountPrimary)
-	}
-	if callCountBackup != 1 {
-		t.Errorf("Expected backup call count 1, got %d", callCountBackup)
-	}
-	if callCountThird != 1 {
-		t.Errorf("Expected third call count 1, got %d", callCountThird)
-	}
+	recorder.AssertTrace(t, "do:primary!", "do:backup!", "do:third")
 }
 
 func TestFallbackActivity_CompensatePrimary(t *testing.T) {
-	callCountPrimary := 0
-	compensateCountPrimary := 0
-	shouldFailPrimary := false
-	primaryType := newPrimaryActivity(&callCountPrimary, &compensateCountPrimary, &shouldFailPrimary)
-
-	callCountBackup := 0
-	compensateCountBackup := 0
-	shouldFailBackup := false
-	backupType := newBackupActivity(&callCountBackup, &compensateCountBackup, &shouldFailBackup)
-
-	activity := NewFallbackActivity()
-	workItem := NewWorkItem(NewFallbackActivity, WorkItemArguments{
-		"alternatives": []*RoutingSlip{
-			NewRoutingSlip([]WorkItem{NewWorkItem(primaryType, WorkItemArguments{"value": "test"})}),
-			NewRoutingSlip([]WorkItem{NewWorkItem(backupType, WorkItemArguments{"value": "test"})}),
+	recorder := sagatest.NewRecorder()
+	primary := sagatest.NewScript("primary", recorder)
+	backup := sagatest.NewScript("backup", recorder)
+
+	activity := saga.NewFallbackActivity()
+	workItem := saga.NewWorkItem(saga.NewFallbackActivity, saga.WorkItemArguments{
+		"alternatives": []*saga.RoutingSlip{
+			saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(primary.ActivityType(), saga.WorkItemArguments{"value": "test"})}),
+			saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(backup.ActivityType(), saga.WorkItemArguments{"value": "test"})}),
 		},
 	})
 
@@ -388,7 +159,7 @@ func TestFallbackActivity_CompensatePrimary(t *testing.T) {
 		t.Fatal("Expected non-nil result")
 	}
 
-	compensateResult, err := activity.Compensate(ctx, *result, NewRoutingSlip(nil))
+	compensateResult, err := activity.Compensate(ctx, *result, saga.NewRoutingSlip(nil))
 	if err != nil {
 		t.Fatalf("Compensate returned error: %v", err)
 	}
@@ -396,30 +167,19 @@ func TestFallbackActivity_CompensatePrimary(t *testing.T) {
 	if !compensateResult {
 		t.Error("Expected compensate to return true")
 	}
-	if compensateCountPrimary != 1 {
-		t.Errorf("Expected primary compensate count 1, got %d", compensateCountPrimary)
-	}
-	if compensateCountBackup != 0 {
-		t.Errorf("Expected backup compensate count 0, got %d", compensateCountBackup)
-	}
+	recorder.AssertCompensated(t, "primary")
 }
 
 func TestFallbackActivity_CompensateBackup(t *testing.T) {
-	callCountPrimary := 0
-	compensateCountPrimary := 0
-	shouldFailPrimary := true
-	primaryType := newPrimaryActivity(&callCountPrimary, &compensateCountPrimary, &shouldFailPrimary)
-
-	callCountBackup := 0
-	compensateCountBackup := 0
-	shouldFailBackup := false
-	backupType := newBackupActivity(&callCountBackup, &compensateCountBackup, &shouldFailBackup)
-
-	activity := NewFallbackActivity()
-	workItem := NewWorkItem(NewFallbackActivity, WorkItemArguments{
-		"alternatives": []*RoutingSlip{
-			NewRoutingSlip([]WorkItem{NewWorkItem(primaryType, WorkItemArguments{"value": "test"})}),
-			NewRoutingSlip([]WorkItem{NewWorkItem(backupType, WorkItemArguments{"value": "test"})}),
+	recorder := sagatest.NewRecorder()
+	primary := sagatest.NewScript("primary", recorder).AlwaysFail()
+	backup := sagatest.NewScript("backup", recorder)
+
+	activity := saga.NewFallbackActivity()
+	workItem := saga.NewWorkItem(saga.NewFallbackActivity, saga.WorkItemArguments{
+		"alternatives": []*saga.RoutingSlip{
+			saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(primary.ActivityType(), saga.WorkItemArguments{"value": "test"})}),
+			saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(backup.ActivityType(), saga.WorkItemArguments{"value": "test"})}),
 		},
 	})
 
@@ -432,7 +192,7 @@ func TestFallbackActivity_CompensateBackup(t *testing.T) {
 		t.Fatal("Expected non-nil result")
 	}
 
-	compensateResult, err := activity.Compensate(ctx, *result, NewRoutingSlip(nil))
+	compensateResult, err := activity.Compensate(ctx, *result, saga.NewRoutingSlip(nil))
 	if err != nil {
 		t.Fatalf("Compensate returned error: %v", err)
 	}
@@ -440,30 +200,20 @@ func TestFallbackActivity_CompensateBackup(t *testing.T) {
 	if !compensateResult {
 		t.Error("Expected compensate to return true")
 	}
-	if compensateCountPrimary != 0 {
-		t.Errorf("Expected primary compensate count 0, got %d", compensateCountPrimary)
-	}
-	if compensateCountBackup != 1 {
-		t.Errorf("Expected backup compensate count 1, got %d", compensateCountBackup)
-	}
+	recorder.AssertCompensated(t, "backup")
 }
 
 func TestFallbackActivity_CompensateMultiStepAlternative(t *testing.T) {
-	callCountPrimary := 0
-	compensateCountPrimary := 0
-	shouldFailPrimary := false
-	primaryType := newPrimaryActivity(&callCountPrimary, &compensateCountPrimary, &shouldFailPrimary)
-
-	callCountConfirm := 0
-	compensateCountConfirm := 0
-	confirmType := newConfirmActivity(&callCountConfirm, &compensateCountConfirm)
-
-	activity := NewFallbackActivity()
-	workItem := NewWorkItem(NewFallbackActivity, WorkItemArguments{
-		"alternatives": []*RoutingSlip{
-			NewRoutingSlip([]WorkItem{
-				NewWorkItem(primaryType, WorkItemArguments{}),
-				NewWorkItem(confirmType, WorkItemArguments{}),
+	recorder := sagatest.NewRecorder()
+	primary := sagatest.NewScript("primary", recorder)
+	confirm := sagatest.NewScript("confirm", recorder)
+
+	activity := saga.NewFallbackActivity()
+	workItem := saga.NewWorkItem(saga.NewFallbackActivity, saga.WorkItemArguments{
+		"alternatives": []*saga.RoutingSlip{
+			saga.NewRoutingSlip([]saga.WorkItem{
+				saga.NewWorkItem(primary.ActivityType(), saga.WorkItemArguments{}),
+				saga.NewWorkItem(confirm.ActivityType(), saga.WorkItemArguments{}),
 			}),
 		},
 	})
@@ -476,14 +226,9 @@ func TestFallbackActivity_CompensateMultiStepAlternative(t *testing.T) {
 	if result == nil {
 		t.Fatal("Expected non-nil result")
 	}
-	if callCountPrimary != 1 {
-		t.Errorf("Expected primary call count 1, got %d", callCountPrimary)
-	}
-	if callCountConfirm != 1 {
-		t.Errorf("Expected confirm call count 1, got %d", callCountConfirm)
-	}
+	recorder.AssertExecuted(t, "primary", "confirm")
 
-	compensateResult, err := activity.Compensate(ctx, *result, NewRoutingSlip(nil))
+	compensateResult, err := activity.Compensate(ctx, *result, saga.NewRoutingSlip(nil))
 	if err != nil {
 		t.Fatalf("Compensate returned error: %v", err)
 	}
@@ -491,16 +236,11 @@ func TestFallbackActivity_CompensateMultiStepAlternative(t *testing.T) {
 	if !compensateResult {
 		t.Error("Expected compensate to return true")
 	}
-	if compensateCountPrimary != 1 {
-		t.Errorf("Expected primary compensate count 1, got %d", compensateCountPrimary)
-	}
-	if compensateCountConfirm != 1 {
-		t.Errorf("Expected confirm compensate count 1, got %d", compensateCountConfirm)
-	}
+	recorder.AssertCompensated(t, "confirm", "primary")
 }
 
 func TestFallbackActivity_QueueAddresses(t *testing.T) {
-	activity := NewFallbackActivity()
+	activity := saga.NewFallbackActivity()
 	if activity.WorkItemQueueAddress() != "sb://./fallback" {
 		t.Errorf("Expected work queue 'sb://./fallback', got '%s'", activity.WorkItemQueueAddress())
 	}
@@ -510,29 +250,20 @@ func TestFallbackActivity_QueueAddresses(t *testing.T) {
 }
 
 func TestFallbackActivity_InRoutingSlip(t *testing.T) {
-	callCountPrimary := 0
-	compensateCountPrimary := 0
-	shouldFailPrimary := true
-	primaryType := newPrimaryActivity(&callCountPrimary, &compensateCountPrimary, &shouldFailPrimary)
-
-	callCountBackup := 0
-	compensateCountBackup := 0
-	shouldFailBackup := false
-	backupType := newBackupActivity(&callCountBackup, &compensateCountBackup, &shouldFailBackup)
-
-	callCountThird := 0
-	compensateCountThird := 0
-	thirdType := newThirdActivity(&callCountThird, &compensateCountThird)
-
-	slip := NewRoutingSlip([]WorkItem{
-		NewWorkItem(thirdType, WorkItemArguments{}),
-		NewWorkItem(NewFallbackActivity, WorkItemArguments{
-			"alternatives": []*RoutingSlip{
-				NewRoutingSlip([]WorkItem{NewWorkItem(primaryType, WorkItemArguments{"value": "try1"})}),
-				NewRoutingSlip([]WorkItem{NewWorkItem(backupType, WorkItemArguments{"value": "try2"})}),
+	recorder := sagatest.NewRecorder()
+	primary := sagatest.NewScript("primary", recorder).AlwaysFail()
+	backup := sagatest.NewScript("backup", recorder)
+	third := sagatest.NewScript("third", recorder)
+
+	slip := saga.NewRoutingSlip([]saga.WorkItem{
+		saga.NewWorkItem(third.ActivityType(), saga.WorkItemArguments{}),
+		saga.NewWorkItem(saga.NewFallbackActivity, saga.WorkItemArguments{
+			"alternatives": []*saga.RoutingSlip{
+				saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(primary.ActivityType(), saga.WorkItemArguments{"value": "try1"})}),
+				saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(backup.ActivityType(), saga.WorkItemArguments{"value": "try2"})}),
 			},
 		}),
-		NewWorkItem(thirdType, WorkItemArguments{}),
+		saga.NewWorkItem(third.ActivityType(), saga.WorkItemArguments{}),
 	})
 
 	ctx := context.Background()
@@ -551,38 +282,21 @@ func TestFallbackActivity_InRoutingSlip(t *testing.T) {
 	if !slip.IsCompleted() {
 		t.Error("Expected routing slip to be completed")
 	}
-	if callCountThird != 2 {
-		t.Errorf("Expected third call count 2, got %d", callCountThird)
-	}
-	if callCountPrimary != 1 {
-		t.Errorf("Expected primary call count 1 (tried and failed), got %d", callCountPrimary)
-	}
-	if callCountBackup != 1 {
-		t.Errorf("Expected backup call count 1 (succeeded), got %d", callCountBackup)
-	}
+	recorder.AssertTrace(t, "do:third", "do:primary!", "do:backup", "do:third")
 }
 
 func TestFallbackActivity_AllFallbacksFailTriggersCompensation(t *testing.T) {
-	callCountPrimary := 0
-	compensateCountPrimary := 0
-	shouldFailPrimary := true
-	primaryType := newPrimaryActivity(&callCountPrimary, &compensateCountPrimary, &shouldFailPrimary)
-
-	callCountBackup := 0
-	compensateCountBackup := 0
-	shouldFailBackup := true
-	backupType := newBackupActivity(&callCountBackup, &compensateCountBackup, &shouldFailBackup)
-
-	callCountThird := 0
-	compensateCountThird := 0
-	thirdType := newThirdActivity(&callCountThird, &compensateCountThird)
-
-	slip := NewRoutingSlip([]WorkItem{
-		NewWorkItem(thirdType, WorkItemArguments{}),
-		NewWorkItem(NewFallbackActivity, WorkItemArguments{
-			"alternatives": []*RoutingSlip{
-				NewRoutingSlip([]WorkItem{NewWorkItem(primaryType, WorkItemArguments{})}),
-				NewRoutingSlip([]WorkItem{NewWorkItem(backupType, WorkItemArguments{})}),
+	recorder := sagatest.NewRecorder()
+	primary := sagatest.NewScript("primary", recorder).AlwaysFail()
+	backup := sagatest.NewScript("backup", recorder).AlwaysFail()
+	third := sagatest.NewScript("third", recorder)
+
+	slip := saga.NewRoutingSlip([]saga.WorkItem{
+		saga.NewWorkItem(third.ActivityType(), saga.WorkItemArguments{}),
+		saga.NewWorkItem(saga.NewFallbackActivity, saga.WorkItemArguments{
+			"alternatives": []*saga.RoutingSlip{
+				saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(primary.ActivityType(), saga.WorkItemArguments{})}),
+				saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(backup.ActivityType(), saga.WorkItemArguments{})}),
 			},
 		}),
 	})
@@ -612,7 +326,35 @@ func TestFallbackActivity_AllFallbacksFailTriggersCompensation(t *testing.T) {
 		slip.UndoLast(ctx)
 	}
 
-	if compensateCountThird != 1 {
-		t.Errorf("Expected third compensate count 1, got %d", compensateCountThird)
+	recorder.AssertCompensated(t, "third")
+}
+
+func TestFallbackActivity_InScheduledSaga(t *testing.T) {
+	recorder := sagatest.NewRecorder()
+	primary := sagatest.NewScript("primary", recorder).AlwaysFail()
+	backup := sagatest.NewScript("backup", recorder)
+	third := sagatest.NewScript("third", recorder)
+	confirm := sagatest.NewScript("confirm", recorder).AlwaysFail()
+
+	scheduler := sagatest.NewScheduler()
+	scheduler.Host(third.ActivityType(), saga.NewFallbackActivity, confirm.ActivityType())
+	ctx := context.Background()
+	err := scheduler.Start(ctx, saga.NewRoutingSlip([]saga.WorkItem{
+		saga.NewWorkItem(third.ActivityType(), saga.WorkItemArguments{}),
+		saga.NewWorkItem(saga.NewFallbackActivity, saga.WorkItemArguments{
+			"alternatives": []*saga.RoutingSlip{
+				saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(primary.ActivityType(), saga.WorkItemArguments{})}),
+				saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(backup.ActivityType(), saga.WorkItemArguments{})}),
+			},
+		}),
+		saga.NewWorkItem(confirm.ActivityType(), saga.WorkItemArguments{}),
+	}))
+	if err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	if err := scheduler.Run(ctx); err != nil {
+		t.Fatalf("Run returned error: %v", err)
 	}
+
+	recorder.AssertTrace(t, "do:third", "do:primary!", "do:backup", "do:confirm!", "undo:backup", "undo:third")
 }
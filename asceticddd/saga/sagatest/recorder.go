@@ -0,0 +1,134 @@
+// Package sagatest provides in-process helpers for saga tests:
+// scripted activities, a recorder of their calls and a deterministic scheduler of routing slips.
+package sagatest
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+type EventKind string
+
+const (
+	EventDoWork     EventKind = "do"
+	EventCompensate EventKind = "undo"
+)
+
+// Event is a call of DoWork or Compensate of a scripted activity.
+type Event struct {
+	Activity string
+	Kind     EventKind
+	Failed   bool
+}
+
+// String returns e.g. "do:flight", or "do:flight!" for a failed call.
+func (e Event) String() string {
+	s := fmt.Sprintf("%s:%s", e.Kind, e.Activity)
+	if e.Failed {
+		s += "!"
+	}
+	return s
+}
+
+// Recorder records the calls of scripted activities in their order.
+// It is safe for concurrent use, e.g. by branches of ParallelActivity.
+type Recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) record(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// Events returns all recorded calls.
+func (r *Recorder) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.events)
+}
+
+// Trace returns the recorded calls as strings, see Event.String.
+func (r *Recorder) Trace() []string {
+	events := r.Events()
+	trace := make([]string, len(events))
+	for i, event := range events {
+		trace[i] = event.String()
+	}
+	return trace
+}
+
+// Executed returns the activities whose DoWork succeeded, in order.
+func (r *Recorder) Executed() []string {
+	return r.names(EventDoWork)
+}
+
+// Compensated returns the activities whose Compensate succeeded, in order.
+func (r *Recorder) Compensated() []string {
+	return r.names(EventCompensate)
+}
+
+// Calls returns the number of DoWork calls of the activity, failed ones included.
+func (r *Recorder) Calls(activity string) int {
+	return r.count(activity, EventDoWork)
+}
+
+// Compensations returns the number of Compensate calls of the activity, failed ones included.
+func (r *Recorder) Compensations(activity string) int {
+	return r.count(activity, EventCompensate)
+}
+
+// AssertExecuted checks the activities whose DoWork succeeded, in order.
+func (r *Recorder) AssertExecuted(t testing.TB, activities ...string) bool {
+	t.Helper()
+	return assertNames(t, "executed", activities, r.Executed())
+}
+
+// AssertCompensated checks the activities whose Compensate succeeded, in order.
+func (r *Recorder) AssertCompensated(t testing.TB, activities ...string) bool {
+	t.Helper()
+	return assertNames(t, "compensated", activities, r.Compensated())
+}
+
+// AssertTrace checks all recorded calls, see Event.String.
+func (r *Recorder) AssertTrace(t testing.TB, trace ...string) bool {
+	t.Helper()
+	return assertNames(t, "trace", trace, r.Trace())
+}
+
+func (r *Recorder) names(kind EventKind) []string {
+	var names []string
+	for _, event := range r.Events() {
+		if event.Kind == kind && !event.Failed {
+			names = append(names, event.Activity)
+		}
+	}
+	return names
+}
+
+func (r *Recorder) count(activity string, kind EventKind) int {
+	n := 0
+	for _, event := range r.Events() {
+		if event.Activity == activity && event.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+func assertNames(t testing.TB, what string, expected, actual []string) bool {
+	t.Helper()
+	if len(expected) == 0 && len(actual) == 0 || slices.Equal(expected, actual) {
+		return true
+	}
+	t.Errorf("unexpected %s:\nexpected: %q\nactual:   %q", what, expected, actual)
+	return false
+}
@@ -0,0 +1,135 @@
+package sagatest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/saga"
+)
+
+var ErrNoHost = errors.New("no activity host accepts the message")
+
+// Scheduler delivers routing slips between ActivityHosts in process,
+// one message at a time in the order they were sent, so the run of a saga is deterministic.
+// It is also a virtual Clock: Sleep advances Now instantly.
+type Scheduler struct {
+	hosts    []*saga.ActivityHost
+	maxSteps int
+
+	mu        sync.Mutex
+	queue     []message
+	delivered []string
+	now       time.Duration
+}
+
+type message struct {
+	uri         string
+	routingSlip *saga.RoutingSlip
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{maxSteps: 1000}
+}
+
+// SetMaxSteps sets the number of messages after which Run fails, to stop sagas looping forever.
+func (s *Scheduler) SetMaxSteps(maxSteps int) {
+	s.maxSteps = maxSteps
+}
+
+// Host registers the ActivityHost of the activity type.
+func (s *Scheduler) Host(activityTypes ...saga.ActivityType) {
+	for _, activityType := range activityTypes {
+		s.hosts = append(s.hosts, saga.NewActivityHost(activityType, s.Send))
+	}
+}
+
+// Send queues the routing slip for the uri, it is the SendCallback of the hosts.
+func (s *Scheduler) Send(ctx context.Context, uri string, routingSlip *saga.RoutingSlip) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = append(s.queue, message{uri: uri, routingSlip: routingSlip})
+	return nil
+}
+
+// Start queues the routing slip for its first activity.
+func (s *Scheduler) Start(ctx context.Context, routingSlip *saga.RoutingSlip) error {
+	uri := routingSlip.ProgressUri()
+	if uri == "" {
+		return nil
+	}
+	return s.Send(ctx, uri, routingSlip)
+}
+
+// Run delivers the queued messages until there are none.
+// It stops at the first error of a host, or ErrNoHost.
+func (s *Scheduler) Run(ctx context.Context) error {
+	for steps := 0; ; steps++ {
+		msg, ok := s.next()
+		if !ok {
+			return nil
+		}
+		if steps >= s.maxSteps {
+			return fmt.Errorf("saga has not finished in %d steps", s.maxSteps)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		accepted, err := s.deliver(ctx, msg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", msg.uri, err)
+		}
+		if !accepted {
+			return fmt.Errorf("%w: %s", ErrNoHost, msg.uri)
+		}
+	}
+}
+
+// Delivered returns the uris of delivered messages in order.
+func (s *Scheduler) Delivered() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.delivered...)
+}
+
+// Sleep advances the virtual time.
+func (s *Scheduler) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now += d
+	return nil
+}
+
+// Now returns the virtual time elapsed since the scheduler was created.
+func (s *Scheduler) Now() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+func (s *Scheduler) next() (message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) == 0 {
+		return message{}, false
+	}
+	msg := s.queue[0]
+	s.queue = s.queue[1:]
+	s.delivered = append(s.delivered, msg.uri)
+	return msg, true
+}
+
+func (s *Scheduler) deliver(ctx context.Context, msg message) (bool, error) {
+	for _, host := range s.hosts {
+		accepted, err := host.AcceptMessage(ctx, msg.uri, msg.routingSlip)
+		if accepted || err != nil {
+			return accepted, err
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,74 @@
+package sagatest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/saga"
+)
+
+func TestScheduler_CompensatesInReverseOrder(t *testing.T) {
+	recorder := NewRecorder()
+	car := NewScript("car", recorder)
+	hotel := NewScript("hotel", recorder)
+	flight := NewScript("flight", recorder).AlwaysFail()
+
+	scheduler := NewScheduler()
+	scheduler.Host(car.ActivityType(), hotel.ActivityType(), flight.ActivityType())
+	ctx := context.Background()
+	slip := saga.NewRoutingSlip([]saga.WorkItem{
+		saga.NewWorkItem(car.ActivityType(), nil),
+		saga.NewWorkItem(hotel.ActivityType(), nil),
+		saga.NewWorkItem(flight.ActivityType(), nil),
+	})
+	if err := scheduler.Start(ctx, slip); err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	if err := scheduler.Run(ctx); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	recorder.AssertExecuted(t, "car", "hotel")
+	recorder.AssertCompensated(t, "hotel", "car")
+	recorder.AssertTrace(t, "do:car", "do:hotel", "do:flight!", "undo:hotel", "undo:car")
+	assertNames(t, "delivered", []string{
+		"sb://./car", "sb://./hotel", "sb://./flight", "sb://./hotelCompensation", "sb://./carCompensation",
+	}, scheduler.Delivered())
+}
+
+func TestScheduler_CompensationFailure(t *testing.T) {
+	recorder := NewRecorder()
+	car := NewScript("car", recorder).FailCompensationOnce()
+	flight := NewScript("flight", recorder).AlwaysFail()
+
+	scheduler := NewScheduler()
+	scheduler.Host(car.ActivityType(), flight.ActivityType())
+	ctx := context.Background()
+	slip := saga.NewRoutingSlip([]saga.WorkItem{
+		saga.NewWorkItem(car.ActivityType(), nil),
+		saga.NewWorkItem(flight.ActivityType(), nil),
+	})
+	if err := scheduler.Start(ctx, slip); err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	if err := scheduler.Run(ctx); !errors.Is(err, ErrCompensationFailed) {
+		t.Fatalf("Expected ErrCompensationFailed, got %v", err)
+	}
+	recorder.AssertCompensated(t)
+	if car.Compensations() != 1 {
+		t.Errorf("Expected 1 compensation, got %d", car.Compensations())
+	}
+}
+
+func TestScheduler_NoHost(t *testing.T) {
+	scheduler := NewScheduler()
+	car := NewScript("car", nil)
+	ctx := context.Background()
+	if err := scheduler.Start(ctx, saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(car.ActivityType(), nil)})); err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	if err := scheduler.Run(ctx); !errors.Is(err, ErrNoHost) {
+		t.Errorf("Expected ErrNoHost, got %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package sagatest
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/saga"
+)
+
+var ErrCompensationFailed = errors.New("scripted compensation failure")
+
+// Clock delays scripted activities. Scheduler is a virtual one.
+type Clock interface {
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+type realClock struct{}
+
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Script describes the behavior of a fake activity and counts its calls:
+//
+//	recorder := sagatest.NewRecorder()
+//	flight := sagatest.NewScript("flight", recorder).SucceedTimes(1).FailCompensationOnce()
+//	slip := saga.NewRoutingSlip([]saga.WorkItem{saga.NewWorkItem(flight.ActivityType(), nil)})
+//
+// By default DoWork and Compensate always succeed.
+// The queues of the activity are "sb://./<name>" and "sb://./<name>Compensation".
+// All instances created by ActivityType share the script.
+type Script struct {
+	name     string
+	recorder *Recorder
+	clock    Clock
+
+	mu                   sync.Mutex
+	successes            int
+	compensationFailures int
+	delay                time.Duration
+	result               saga.WorkResult
+	resumeForward        bool
+	calls                int
+	compensations        int
+}
+
+// NewScript creates the script of the activity with the name, the recorder may be nil.
+func NewScript(name string, recorder *Recorder) *Script {
+	return &Script{
+		name:      name,
+		recorder:  recorder,
+		clock:     realClock{},
+		successes: -1,
+		result:    saga.WorkResult{"activity": name},
+	}
+}
+
+// SucceedTimes makes DoWork succeed n times and fail afterwards.
+func (s *Script) SucceedTimes(n int) *Script {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.successes = n
+	return s
+}
+
+// AlwaysFail makes DoWork fail, i.e. return no WorkLog.
+func (s *Script) AlwaysFail() *Script {
+	return s.SucceedTimes(0)
+}
+
+// FailCompensationTimes makes Compensate return ErrCompensationFailed n times and succeed afterwards.
+func (s *Script) FailCompensationTimes(n int) *Script {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.compensationFailures = n
+	return s
+}
+
+// FailCompensationOnce makes the first Compensate fail.
+func (s *Script) FailCompensationOnce() *Script {
+	return s.FailCompensationTimes(1)
+}
+
+// ResumeForward makes Compensate return false, i.e. resume the forward path.
+func (s *Script) ResumeForward() *Script {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resumeForward = true
+	return s
+}
+
+// Delay makes DoWork wait on the clock before doing the work.
+func (s *Script) Delay(delay time.Duration) *Script {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay = delay
+	return s
+}
+
+// WithClock sets the clock of Delay, real time by default.
+func (s *Script) WithClock(clock Clock) *Script {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = clock
+	return s
+}
+
+// Returning sets the WorkResult of successful DoWork, {"activity": name} by default.
+// The arguments of the work item are added to it.
+func (s *Script) Returning(result saga.WorkResult) *Script {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.result = result
+	return s
+}
+
+func (s *Script) Name() string {
+	return s.name
+}
+
+// Calls returns the number of DoWork calls.
+func (s *Script) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+// Compensations returns the number of Compensate calls.
+func (s *Script) Compensations() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.compensations
+}
+
+// ActivityType returns the type of the scripted activity.
+func (s *Script) ActivityType() saga.ActivityType {
+	return func() saga.Activity {
+		return &scriptedActivity{script: s}
+	}
+}
+
+// doWork returns the result of the call, or nil for a failure.
+func (s *Script) doWork(ctx context.Context, arguments saga.WorkItemArguments) (saga.WorkResult, error) {
+	s.mu.Lock()
+	s.calls++
+	succeed := s.successes != 0
+	if s.successes > 0 {
+		s.successes--
+	}
+	delay, clock := s.delay, s.clock
+	result := maps.Clone(s.result)
+	s.mu.Unlock()
+
+	if delay > 0 {
+		if err := clock.Sleep(ctx, delay); err != nil {
+			s.record(EventDoWork, true)
+			return nil, err
+		}
+	}
+	s.record(EventDoWork, !succeed)
+	if !succeed {
+		return nil, nil
+	}
+	if result == nil {
+		result = saga.WorkResult{}
+	}
+	for key, value := range arguments {
+		result[key] = value
+	}
+	return result, nil
+}
+
+func (s *Script) compensate() (bool, error) {
+	s.mu.Lock()
+	s.compensations++
+	fail := s.compensationFailures > 0
+	if fail {
+		s.compensationFailures--
+	}
+	resumeForward := s.resumeForward
+	s.mu.Unlock()
+
+	s.record(EventCompensate, fail)
+	if fail {
+		return false, ErrCompensationFailed
+	}
+	return !resumeForward, nil
+}
+
+func (s *Script) record(kind EventKind, failed bool) {
+	if s.recorder != nil {
+		s.recorder.record(Event{Activity: s.name, Kind: kind, Failed: failed})
+	}
+}
+
+type scriptedActivity struct {
+	script *Script
+}
+
+func (a *scriptedActivity) DoWork(ctx context.Context, workItem saga.WorkItem) (*saga.WorkLog, error) {
+	result, err := a.script.doWork(ctx, workItem.Arguments())
+	if err != nil || result == nil {
+		return nil, err
+	}
+	workLog := saga.NewWorkLog(a, result)
+	return &workLog, nil
+}
+
+func (a *scriptedActivity) Compensate(ctx context.Context, workLog saga.WorkLog, routingSlip *saga.RoutingSlip) (bool, error) {
+	return a.script.compensate()
+}
+
+func (a *scriptedActivity) WorkItemQueueAddress() string {
+	return "sb://./" + a.script.name
+}
+
+func (a *scriptedActivity) CompensationQueueAddress() string {
+	return "sb://./" + a.script.name + "Compensation"
+}
+
+func (a *scriptedActivity) ActivityType() saga.ActivityType {
+	return a.script.ActivityType()
+}
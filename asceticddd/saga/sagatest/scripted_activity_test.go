@@ -0,0 +1,73 @@
+package sagatest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/saga"
+)
+
+func TestScript_SucceedTimes(t *testing.T) {
+	recorder := NewRecorder()
+	script := NewScript("flight", recorder).SucceedTimes(2)
+	activity := script.ActivityType()()
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		workLog, err := activity.DoWork(ctx, saga.NewWorkItem(script.ActivityType(), saga.WorkItemArguments{"seat": "1A"}))
+		if err != nil || workLog == nil {
+			t.Fatalf("Expected call %d to succeed, got %v, %v", i, workLog, err)
+		}
+		if workLog.Result()["seat"] != "1A" || workLog.Result()["activity"] != "flight" {
+			t.Errorf("Unexpected result %v", workLog.Result())
+		}
+	}
+	workLog, err := activity.DoWork(ctx, saga.NewWorkItem(script.ActivityType(), nil))
+	if err != nil || workLog != nil {
+		t.Fatalf("Expected third call to fail, got %v, %v", workLog, err)
+	}
+	if script.Calls() != 3 {
+		t.Errorf("Expected 3 calls, got %d", script.Calls())
+	}
+	recorder.AssertTrace(t, "do:flight", "do:flight", "do:flight!")
+}
+
+func TestScript_FailCompensationOnce(t *testing.T) {
+	recorder := NewRecorder()
+	script := NewScript("hotel", recorder).FailCompensationOnce()
+	activity := script.ActivityType()()
+	ctx := context.Background()
+
+	_, err := activity.Compensate(ctx, saga.WorkLog{}, nil)
+	if !errors.Is(err, ErrCompensationFailed) {
+		t.Fatalf("Expected ErrCompensationFailed, got %v", err)
+	}
+	ok, err := activity.Compensate(ctx, saga.WorkLog{}, nil)
+	if err != nil || !ok {
+		t.Fatalf("Expected second compensation to succeed, got %v, %v", ok, err)
+	}
+	if script.Compensations() != 2 {
+		t.Errorf("Expected 2 compensations, got %d", script.Compensations())
+	}
+	recorder.AssertCompensated(t, "hotel")
+}
+
+func TestScript_Delay(t *testing.T) {
+	scheduler := NewScheduler()
+	script := NewScript("car", nil).Delay(time.Minute).WithClock(scheduler)
+	if _, err := script.ActivityType()().DoWork(context.Background(), saga.NewWorkItem(script.ActivityType(), nil)); err != nil {
+		t.Fatalf("DoWork returned error: %v", err)
+	}
+	if scheduler.Now() != time.Minute {
+		t.Errorf("Expected virtual time 1m, got %v", scheduler.Now())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	real := NewScript("car", nil).Delay(time.Hour)
+	if _, err := real.ActivityType()().DoWork(ctx, saga.NewWorkItem(real.ActivityType(), nil)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
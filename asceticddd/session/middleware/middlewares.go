@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+type Scope string
+
+const (
+	ScopeSession Scope = "session"
+	ScopeAtomic  Scope = "atomic"
+)
+
+// TimingObserver receives the duration of each session or atomic scope.
+type TimingObserver func(ctx context.Context, scope Scope, elapsed time.Duration, err error)
+
+// Timing reports the duration of every session and atomic scope to the observer.
+// It is the hook for metrics and tracing.
+func Timing(observer TimingObserver) Middleware {
+	return Middleware{
+		Session: func(next SessionHandler) SessionHandler {
+			return func(ctx context.Context, callback session.SessionPoolCallback) error {
+				start := time.Now()
+				err := next(ctx, callback)
+				observer(ctx, ScopeSession, time.Since(start), err)
+				return err
+			}
+		},
+		Atomic: func(next AtomicHandler) AtomicHandler {
+			return func(s session.Session, callback session.SessionCallback) error {
+				start := time.Now()
+				err := next(s, callback)
+				observer(s.Context(), ScopeAtomic, time.Since(start), err)
+				return err
+			}
+		},
+	}
+}
+
+// WithContext transforms the context of every session, e.g. to inject the tenant.
+func WithContext(transform func(ctx context.Context) (context.Context, error)) Middleware {
+	return Middleware{
+		Session: func(next SessionHandler) SessionHandler {
+			return func(ctx context.Context, callback session.SessionPoolCallback) error {
+				ctx, err := transform(ctx)
+				if err != nil {
+					return err
+				}
+				return next(ctx, callback)
+			}
+		},
+	}
+}
+
+// Retry re-runs the outermost atomic scope while isRetryable(err) is true,
+// up to maxAttempts attempts in total. Nested scopes are never retried,
+// since a failed savepoint leaves the enclosing transaction aborted.
+// The callback must be safe to re-run.
+func Retry(maxAttempts int, backoff time.Duration, isRetryable func(error) bool) Middleware {
+	return Middleware{
+		Atomic: func(next AtomicHandler) AtomicHandler {
+			return func(s session.Session, callback session.SessionCallback) error {
+				if AtomicDepth(s) > 0 {
+					return next(s, callback)
+				}
+				var err error
+				for attempt := 1; ; attempt++ {
+					err = next(s, callback)
+					if err == nil || attempt >= maxAttempts || !isRetryable(err) {
+						return err
+					}
+					select {
+					case <-s.Context().Done():
+						return err
+					case <-time.After(backoff * time.Duration(attempt)):
+					}
+				}
+			}
+		},
+	}
+}
+
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// IsSerializationFailure reports whether the error is a PostgreSQL
+// serialization failure or deadlock, which are resolved by retrying the transaction.
+func IsSerializationFailure(err error) bool {
+	var sqlStateErr interface{ SQLState() string }
+	if !errors.As(err, &sqlStateErr) {
+		return false
+	}
+	switch sqlStateErr.SQLState() {
+	case sqlStateSerializationFailure, sqlStateDeadlockDetected:
+		return true
+	}
+	return false
+}
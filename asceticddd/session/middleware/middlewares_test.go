@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+type sqlStateError struct {
+	code string
+}
+
+func (e *sqlStateError) Error() string {
+	return "sqlstate " + e.code
+}
+
+func (e *sqlStateError) SQLState() string {
+	return e.code
+}
+
+func TestTiming(t *testing.T) {
+	var scopes []Scope
+	pool := NewSessionPool(&fakeSessionPool{}, Timing(
+		func(ctx context.Context, scope Scope, elapsed time.Duration, err error) {
+			scopes = append(scopes, scope)
+		},
+	))
+
+	err := pool.Session(context.Background(), func(s session.Session) error {
+		return s.Atomic(func(session.Session) error { return nil })
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []Scope{ScopeAtomic, ScopeSession}, scopes)
+}
+
+type tenantKey struct{}
+
+func TestWithContext(t *testing.T) {
+	t.Run("injects value", func(t *testing.T) {
+		delegate := &fakeSessionPool{}
+		pool := NewSessionPool(delegate, WithContext(func(ctx context.Context) (context.Context, error) {
+			return context.WithValue(ctx, tenantKey{}, "acme"), nil
+		}))
+
+		err := pool.Session(context.Background(), func(s session.Session) error {
+			assert.Equal(t, "acme", s.Context().Value(tenantKey{}))
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "acme", delegate.lastCtx.Value(tenantKey{}))
+	})
+
+	t.Run("error aborts session", func(t *testing.T) {
+		errNoTenant := errors.New("no tenant")
+		pool := NewSessionPool(&fakeSessionPool{}, WithContext(func(ctx context.Context) (context.Context, error) {
+			return nil, errNoTenant
+		}))
+
+		called := false
+		err := pool.Session(context.Background(), func(s session.Session) error {
+			called = true
+			return nil
+		})
+
+		assert.ErrorIs(t, err, errNoTenant)
+		assert.False(t, called)
+	})
+}
+
+func TestRetry(t *testing.T) {
+	serializationErr := fmt.Errorf("commit: %w", &sqlStateError{code: "40001"})
+
+	t.Run("retries retryable errors", func(t *testing.T) {
+		delegate := &fakeSessionPool{}
+		pool := NewSessionPool(delegate, Retry(3, 0, IsSerializationFailure))
+
+		attempts := 0
+		err := pool.Session(context.Background(), func(s session.Session) error {
+			return s.Atomic(func(session.Session) error {
+				attempts++
+				if attempts < 3 {
+					return serializationErr
+				}
+				return nil
+			})
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+		assert.Equal(t, 1, delegate.committed)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		pool := NewSessionPool(&fakeSessionPool{}, Retry(2, 0, IsSerializationFailure))
+
+		attempts := 0
+		err := pool.Session(context.Background(), func(s session.Session) error {
+			return s.Atomic(func(session.Session) error {
+				attempts++
+				return serializationErr
+			})
+		})
+
+		assert.ErrorIs(t, err, serializationErr)
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		pool := NewSessionPool(&fakeSessionPool{}, Retry(3, 0, IsSerializationFailure))
+
+		attempts := 0
+		err := pool.Session(context.Background(), func(s session.Session) error {
+			return s.Atomic(func(session.Session) error {
+				attempts++
+				return errors.New("boom")
+			})
+		})
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("does not retry nested scopes", func(t *testing.T) {
+		pool := NewSessionPool(&fakeSessionPool{}, Retry(3, 0, IsSerializationFailure))
+
+		outer, inner := 0, 0
+		err := pool.Session(context.Background(), func(s session.Session) error {
+			return s.Atomic(func(tx session.Session) error {
+				outer++
+				return tx.Atomic(func(session.Session) error {
+					inner++
+					return serializationErr
+				})
+			})
+		})
+
+		assert.Error(t, err)
+		assert.Equal(t, 3, outer)
+		assert.Equal(t, 3, inner)
+	})
+}
+
+func TestIsSerializationFailure(t *testing.T) {
+	assert.True(t, IsSerializationFailure(&sqlStateError{code: "40001"}))
+	assert.True(t, IsSerializationFailure(fmt.Errorf("wrapped: %w", &sqlStateError{code: "40P01"})))
+	assert.False(t, IsSerializationFailure(&sqlStateError{code: "23505"}))
+	assert.False(t, IsSerializationFailure(errors.New("plain")))
+	assert.False(t, IsSerializationFailure(nil))
+}
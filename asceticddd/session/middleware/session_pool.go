@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/signals"
+)
+
+// SessionHandler opens a session and runs the callback within it.
+type SessionHandler func(ctx context.Context, callback session.SessionPoolCallback) error
+
+// AtomicHandler runs the callback within an atomic scope of the session.
+type AtomicHandler func(s session.Session, callback session.SessionCallback) error
+
+type SessionMiddleware func(next SessionHandler) SessionHandler
+
+type AtomicMiddleware func(next AtomicHandler) AtomicHandler
+
+// Middleware decorates SessionPool.Session, Session.Atomic or both.
+// Nil fields are skipped.
+type Middleware struct {
+	Session SessionMiddleware
+	Atomic  AtomicMiddleware
+}
+
+// SessionPool applies the middlewares to every session of the delegate pool
+// and to every atomic scope, including the nested ones.
+// The first middleware is the outermost one.
+type SessionPool struct {
+	delegate session.SessionPool
+	session  SessionHandler
+	atomic   AtomicHandler
+}
+
+func NewSessionPool(delegate session.SessionPool, middlewares ...Middleware) *SessionPool {
+	p := &SessionPool{delegate: delegate}
+
+	var atomic AtomicHandler = func(s session.Session, callback session.SessionCallback) error {
+		depth := AtomicDepth(s)
+		return Unwrap(s).Atomic(func(txSession session.Session) error {
+			return callback(p.wrap(txSession, depth+1))
+		})
+	}
+	var sess SessionHandler = func(ctx context.Context, callback session.SessionPoolCallback) error {
+		return delegate.Session(ctx, func(s session.Session) error {
+			return callback(p.wrap(s, 0))
+		})
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i].Atomic != nil {
+			atomic = middlewares[i].Atomic(atomic)
+		}
+		if middlewares[i].Session != nil {
+			sess = middlewares[i].Session(sess)
+		}
+	}
+	p.atomic = atomic
+	p.session = sess
+	return p
+}
+
+func (p *SessionPool) OnSessionStarted() signals.Signal[session.SessionScopeStartedEvent] {
+	return p.delegate.OnSessionStarted()
+}
+
+func (p *SessionPool) OnSessionEnded() signals.Signal[session.SessionScopeEndedEvent] {
+	return p.delegate.OnSessionEnded()
+}
+
+func (p *SessionPool) Session(ctx context.Context, callback session.SessionPoolCallback) error {
+	return p.session(ctx, callback)
+}
+
+func (p *SessionPool) Delegate() session.SessionPool {
+	return p.delegate
+}
+
+func (p *SessionPool) wrap(s session.Session, depth int) session.Session {
+	switch typed := s.(type) {
+	case session.DbSession:
+		return &dbSession{DbSession: typed, pool: p, atomicDepth: depth}
+	case session.RestSession:
+		return &restSession{RestSession: typed, pool: p, atomicDepth: depth}
+	default:
+		return &plainSession{Session: s, pool: p, atomicDepth: depth}
+	}
+}
+
+// AtomicDepth returns the number of atomic scopes enclosing the session:
+// 0 outside of a transaction, 1 within a transaction, 2 and more within savepoints.
+// Sessions not created by SessionPool always have 0.
+func AtomicDepth(s session.Session) int {
+	if ds, ok := s.(decorated); ok {
+		return ds.depth()
+	}
+	return 0
+}
+
+// Unwrap returns the session of the delegate pool.
+func Unwrap(s session.Session) session.Session {
+	if ds, ok := s.(decorated); ok {
+		return ds.unwrap()
+	}
+	return s
+}
+
+type decorated interface {
+	session.Session
+	unwrap() session.Session
+	depth() int
+}
+
+type plainSession struct {
+	session.Session
+	pool        *SessionPool
+	atomicDepth int
+}
+
+func (s *plainSession) Atomic(callback session.SessionCallback) error {
+	return s.pool.atomic(s, callback)
+}
+
+func (s *plainSession) unwrap() session.Session {
+	return s.Session
+}
+
+func (s *plainSession) depth() int {
+	return s.atomicDepth
+}
+
+type dbSession struct {
+	session.DbSession
+	pool        *SessionPool
+	atomicDepth int
+}
+
+func (s *dbSession) Atomic(callback session.SessionCallback) error {
+	return s.pool.atomic(s, callback)
+}
+
+func (s *dbSession) unwrap() session.Session {
+	return s.DbSession
+}
+
+func (s *dbSession) depth() int {
+	return s.atomicDepth
+}
+
+type restSession struct {
+	session.RestSession
+	pool        *SessionPool
+	atomicDepth int
+}
+
+func (s *restSession) Atomic(callback session.SessionCallback) error {
+	return s.pool.atomic(s, callback)
+}
+
+func (s *restSession) unwrap() session.Session {
+	return s.RestSession
+}
+
+func (s *restSession) depth() int {
+	return s.atomicDepth
+}
@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session/identitymap"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/signals"
+)
+
+type fakeSession struct {
+	ctx       context.Context
+	db        bool
+	atomics   *int
+	committed *int
+}
+
+func newFakeSession(ctx context.Context, db bool, atomics *int, committed *int) session.Session {
+	s := fakeSession{ctx: ctx, db: db, atomics: atomics, committed: committed}
+	if db {
+		return &fakeDbSession{s}
+	}
+	return &s
+}
+
+func (s *fakeSession) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeSession) Atomic(callback session.SessionCallback) error {
+	*s.atomics++
+	err := callback(newFakeSession(s.ctx, s.db, s.atomics, s.committed))
+	if err == nil {
+		*s.committed++
+	}
+	return err
+}
+
+func (s *fakeSession) OnAtomicStarted() signals.Signal[session.SessionScopeStartedEvent] {
+	return signals.NewSignal[session.SessionScopeStartedEvent]()
+}
+
+func (s *fakeSession) OnAtomicEnded() signals.Signal[session.SessionScopeEndedEvent] {
+	return signals.NewSignal[session.SessionScopeEndedEvent]()
+}
+
+type fakeDbSession struct {
+	fakeSession
+}
+
+func (s *fakeDbSession) Connection() session.DbConnection {
+	return nil
+}
+
+func (s *fakeDbSession) IdentityMap() *identitymap.IdentityMap {
+	return nil
+}
+
+func (s *fakeDbSession) OnQueryStarted() signals.Signal[session.QueryStartedEvent] {
+	return signals.NewSignal[session.QueryStartedEvent]()
+}
+
+func (s *fakeDbSession) OnQueryEnded() signals.Signal[session.QueryEndedEvent] {
+	return signals.NewSignal[session.QueryEndedEvent]()
+}
+
+type fakeSessionPool struct {
+	db        bool
+	atomics   int
+	committed int
+	lastCtx   context.Context
+}
+
+func (p *fakeSessionPool) Session(ctx context.Context, callback session.SessionPoolCallback) error {
+	p.lastCtx = ctx
+	return callback(newFakeSession(ctx, p.db, &p.atomics, &p.committed))
+}
+
+func (p *fakeSessionPool) OnSessionStarted() signals.Signal[session.SessionScopeStartedEvent] {
+	return signals.NewSignal[session.SessionScopeStartedEvent]()
+}
+
+func (p *fakeSessionPool) OnSessionEnded() signals.Signal[session.SessionScopeEndedEvent] {
+	return signals.NewSignal[session.SessionScopeEndedEvent]()
+}
+
+func recorder(name string, calls *[]string) Middleware {
+	return Middleware{
+		Session: func(next SessionHandler) SessionHandler {
+			return func(ctx context.Context, callback session.SessionPoolCallback) error {
+				*calls = append(*calls, name+":session")
+				return next(ctx, callback)
+			}
+		},
+		Atomic: func(next AtomicHandler) AtomicHandler {
+			return func(s session.Session, callback session.SessionCallback) error {
+				*calls = append(*calls, name+":atomic")
+				return next(s, callback)
+			}
+		},
+	}
+}
+
+func TestSessionPool(t *testing.T) {
+	t.Run("middlewares run in registration order", func(t *testing.T) {
+		var calls []string
+		pool := NewSessionPool(&fakeSessionPool{}, recorder("a", &calls), recorder("b", &calls))
+
+		err := pool.Session(context.Background(), func(s session.Session) error {
+			return s.Atomic(func(session.Session) error { return nil })
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a:session", "b:session", "a:atomic", "b:atomic"}, calls)
+	})
+
+	t.Run("nested atomic scopes are decorated", func(t *testing.T) {
+		var calls []string
+		var depths []int
+		delegate := &fakeSessionPool{}
+		pool := NewSessionPool(delegate, recorder("a", &calls))
+
+		err := pool.Session(context.Background(), func(s session.Session) error {
+			depths = append(depths, AtomicDepth(s))
+			return s.Atomic(func(tx session.Session) error {
+				depths = append(depths, AtomicDepth(tx))
+				return tx.Atomic(func(savepoint session.Session) error {
+					depths = append(depths, AtomicDepth(savepoint))
+					return nil
+				})
+			})
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a:session", "a:atomic", "a:atomic"}, calls)
+		assert.Equal(t, []int{0, 1, 2}, depths)
+		assert.Equal(t, 2, delegate.atomics)
+	})
+
+	t.Run("db session interface is preserved", func(t *testing.T) {
+		pool := NewSessionPool(&fakeSessionPool{db: true})
+
+		err := pool.Session(context.Background(), func(s session.Session) error {
+			_, ok := s.(session.DbSession)
+			assert.True(t, ok)
+			_, ok = Unwrap(s).(*fakeDbSession)
+			assert.True(t, ok)
+			return s.Atomic(func(tx session.Session) error {
+				_, ok := tx.(session.DbSession)
+				assert.True(t, ok)
+				return nil
+			})
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("middleware may short-circuit", func(t *testing.T) {
+		errDenied := errors.New("denied")
+		delegate := &fakeSessionPool{}
+		pool := NewSessionPool(delegate, Middleware{
+			Atomic: func(next AtomicHandler) AtomicHandler {
+				return func(s session.Session, callback session.SessionCallback) error {
+					return errDenied
+				}
+			},
+		})
+
+		err := pool.Session(context.Background(), func(s session.Session) error {
+			return s.Atomic(func(session.Session) error { return nil })
+		})
+
+		assert.ErrorIs(t, err, errDenied)
+		assert.Equal(t, 0, delegate.atomics)
+	})
+}
@@ -0,0 +1,30 @@
+package specification
+
+import "time"
+
+// Clock is the source of the current time of Now and CurrentDate, a fixed one keeps time-relative
+// specifications deterministic in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to Clock.
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// FixedClock returns the Clock stopped at the time.
+func FixedClock(t time.Time) Clock {
+	return ClockFunc(func() time.Time { return t })
+}
+
+// SystemClock is the Clock of time.Now.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// today returns the midnight of the day of the time in its location.
+func today(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -26,7 +26,7 @@ func Any[T any](collection []T, predicate func(T) bool) bool {
 }
 
 // All returns true if all items in the collection satisfy the predicate.
-// This is a marker function for code generation - it will be converted to Wildcard AST node.
+// This is a marker function for code generation - it will be converted to Every AST node.
 //
 // Example:
 //
@@ -37,7 +37,7 @@ func Any[T any](collection []T, predicate func(T) bool) bool {
 //	    })
 //	}
 //
-// Generates: Wildcard(Object(GlobalScope(), "Items"), Field(Item(), "Active"))
+// Generates: Every(Object(GlobalScope(), "Items"), Field(Item(), "Active"))
 func All[T any](collection []T, predicate func(T) bool) bool {
 	for _, item := range collection {
 		if !predicate(item) {
@@ -46,3 +46,81 @@ func All[T any](collection []T, predicate func(T) bool) bool {
 	}
 	return true
 }
+
+// numeric is the constraint of the values of Sum, Min and Max.
+type numeric interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// Count returns the number of items in the collection satisfying the predicate.
+// This is a marker function for code generation - it will be converted to CountOf AST node.
+//
+// Example:
+//
+//	//spec:sql
+//	func HasActiveItemsSpec(store Store) bool {
+//	    return Count(store.Items, func(item Item) bool {
+//	        return item.Active
+//	    }) >= 3
+//	}
+//
+// Generates: GreaterThanEqual(CountOf(Object(GlobalScope(), "Items"), Field(Item(), "Active")), Value(3))
+func Count[T any](collection []T, predicate func(T) bool) int {
+	count := 0
+	for _, item := range collection {
+		if predicate(item) {
+			count++
+		}
+	}
+	return count
+}
+
+// Sum returns the sum of the values of the items in the collection, zero for an empty collection.
+// This is a marker function for code generation - it will be converted to SumOf AST node,
+// which is NULL for an empty collection, as sum() in SQL.
+//
+// Example:
+//
+//	//spec:sql
+//	func LargeOrderSpec(order Order) bool {
+//	    return Sum(order.Lines, func(line Line) int {
+//	        return line.Price * line.Quantity
+//	    }) > 1000
+//	}
+//
+// Generates: GreaterThan(SumOf(Object(GlobalScope(), "Lines"), Mul(Field(Item(), "Price"), Field(Item(), "Quantity"))), Value(1000))
+func Sum[T any, N numeric](collection []T, value func(T) N) N {
+	var sum N
+	for _, item := range collection {
+		sum += value(item)
+	}
+	return sum
+}
+
+// Min returns the least of the values of the items in the collection, zero for an empty collection.
+// This is a marker function for code generation - it will be converted to MinOf AST node,
+// which is NULL for an empty collection, as min() in SQL.
+func Min[T any, N numeric](collection []T, value func(T) N) N {
+	var result N
+	for i, item := range collection {
+		if v := value(item); i == 0 || v < result {
+			result = v
+		}
+	}
+	return result
+}
+
+// Max returns the greatest of the values of the items in the collection, zero for an empty collection.
+// This is a marker function for code generation - it will be converted to MaxOf AST node,
+// which is NULL for an empty collection, as max() in SQL.
+func Max[T any, N numeric](collection []T, value func(T) N) N {
+	var result N
+	for i, item := range collection {
+		if v := value(item); i == 0 || v > result {
+			result = v
+		}
+	}
+	return result
+}
@@ -259,3 +259,28 @@ func TestAllWithStrings(t *testing.T) {
 		t.Error("Expected true - all words start with 'a'")
 	}
 }
+
+func TestAggregateHelpers(t *testing.T) {
+	items := []TestItem{
+		{ID: 1, Name: "A", Price: 100, Active: true},
+		{ID: 2, Name: "B", Price: 300, Active: false},
+		{ID: 3, Name: "C", Price: 200, Active: true},
+	}
+	price := func(item TestItem) int { return item.Price }
+
+	if count := Count(items, func(item TestItem) bool { return item.Active }); count != 2 {
+		t.Errorf("Expected 2 active items, got %d", count)
+	}
+	if sum := Sum(items, price); sum != 600 {
+		t.Errorf("Expected sum 600, got %d", sum)
+	}
+	if minPrice := Min(items, price); minPrice != 100 {
+		t.Errorf("Expected min 100, got %d", minPrice)
+	}
+	if maxPrice := Max(items, price); maxPrice != 300 {
+		t.Errorf("Expected max 300, got %d", maxPrice)
+	}
+	if maxPrice := Max([]TestItem{}, price); maxPrice != 0 {
+		t.Errorf("Expected max 0 of empty slice, got %d", maxPrice)
+	}
+}
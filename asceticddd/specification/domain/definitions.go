@@ -0,0 +1,201 @@
+package specification
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FunctionRef references the specification defined by the name in the SpecificationRegistry.
+const FunctionRef = "ref"
+
+var (
+	ErrUndefinedSpecification = errors.New("undefined specification")
+	ErrCyclicSpecification    = errors.New("cyclic specification")
+)
+
+// Ref references the named specification, it is replaced by the definition by SpecificationRegistry.Expand
+// before evaluation or compilation.
+func Ref(name string) FunctionNode {
+	return Function(FunctionRef, Value(name))
+}
+
+// RefName returns the name of the specification referenced by Ref.
+func RefName(n FunctionNode) (string, error) {
+	if n.Name() != FunctionRef || len(n.Args()) != 1 {
+		return "", fmt.Errorf("function \"%s\" of %d arguments is not %s", n.Name(), len(n.Args()), FunctionRef)
+	}
+	value, ok := n.Args()[0].(ValueNode)
+	if !ok {
+		return "", fmt.Errorf("function \"%s\" requires the name value, got %T", FunctionRef, n.Args()[0])
+	}
+	name, ok := value.Value().(string)
+	if !ok {
+		return "", fmt.Errorf("function \"%s\" requires string, got %T", FunctionRef, value.Value())
+	}
+	return name, nil
+}
+
+// SpecificationRegistry is the vocabulary of the named specifications, e.g. "AdultUser",
+// shared by the specifications which reference them by Ref.
+type SpecificationRegistry struct {
+	definitions map[string]Visitable
+}
+
+func NewSpecificationRegistry() *SpecificationRegistry {
+	return &SpecificationRegistry{
+		definitions: make(map[string]Visitable),
+	}
+}
+
+// Define names the specification. The definition may reference the specifications defined later,
+// the cycles are detected by Expand.
+func (r *SpecificationRegistry) Define(name string, ast Visitable) error {
+	if name == "" {
+		return errors.New("specification name is empty")
+	}
+	if _, ok := r.definitions[name]; ok {
+		return fmt.Errorf("specification \"%s\" is already defined", name)
+	}
+	r.definitions[name] = ast
+	return nil
+}
+
+// Lookup returns the definition of the named specification.
+func (r *SpecificationRegistry) Lookup(name string) (Visitable, bool) {
+	ast, ok := r.definitions[name]
+	return ast, ok
+}
+
+// Expand replaces the references of the AST by the definitions recursively.
+func (r *SpecificationRegistry) Expand(ast Visitable) (Visitable, error) {
+	visitor := &expandVisitor{registry: r}
+	err := ast.Accept(visitor)
+	if err != nil {
+		return nil, err
+	}
+	return visitor.currentNode, nil
+}
+
+// expandVisitor rebuilds the nodes which contain references, the path is the chain of the expanded names.
+type expandVisitor struct {
+	registry    *SpecificationRegistry
+	path        []string
+	currentNode Visitable
+}
+
+func (v *expandVisitor) expand(n Visitable) (Visitable, error) {
+	err := n.Accept(v)
+	if err != nil {
+		return nil, err
+	}
+	return v.currentNode, nil
+}
+
+func (v *expandVisitor) VisitGlobalScope(n GlobalScopeNode) error {
+	v.currentNode = n
+	return nil
+}
+
+func (v *expandVisitor) VisitObject(n ObjectNode) error {
+	v.currentNode = n
+	return nil
+}
+
+func (v *expandVisitor) VisitCollection(n CollectionNode) error {
+	predicate, err := v.expand(n.Predicate())
+	if err != nil {
+		return err
+	}
+	v.currentNode = NewCollectionNode(n.Parent(), n.Name(), predicate)
+	return nil
+}
+
+func (v *expandVisitor) VisitItem(n ItemNode) error {
+	v.currentNode = n
+	return nil
+}
+
+func (v *expandVisitor) VisitField(n FieldNode) error {
+	v.currentNode = n
+	return nil
+}
+
+func (v *expandVisitor) VisitValue(n ValueNode) error {
+	v.currentNode = n
+	return nil
+}
+
+func (v *expandVisitor) VisitPrefix(n PrefixNode) error {
+	operand, err := v.expand(n.Operand())
+	if err != nil {
+		return err
+	}
+	v.currentNode = NewPrefixNode(n.Operator(), operand, n.Associativity())
+	return nil
+}
+
+func (v *expandVisitor) VisitInfix(n InfixNode) error {
+	left, err := v.expand(n.Left())
+	if err != nil {
+		return err
+	}
+	right, err := v.expand(n.Right())
+	if err != nil {
+		return err
+	}
+	v.currentNode = NewInfixNode(left, n.Operator(), right, n.Associativity())
+	return nil
+}
+
+func (v *expandVisitor) VisitPostfix(n PostfixNode) error {
+	operand, err := v.expand(n.Operand())
+	if err != nil {
+		return err
+	}
+	v.currentNode = NewPostfixNode(operand, n.Operator(), n.Associativity())
+	return nil
+}
+
+func (v *expandVisitor) VisitFunction(n FunctionNode) error {
+	if n.Name() == FunctionRef {
+		return v.visitRef(n)
+	}
+	var args []Visitable
+	for _, arg := range n.Args() {
+		expanded, err := v.expand(arg)
+		if err != nil {
+			return err
+		}
+		args = append(args, expanded)
+	}
+	v.currentNode = Function(n.Name(), args...)
+	return nil
+}
+
+// VisitExtension keeps the extension node as is, the references of its fallback are not expanded.
+func (v *expandVisitor) VisitExtension(n ExtensionNode) error {
+	v.currentNode = n
+	return nil
+}
+
+func (v *expandVisitor) visitRef(n FunctionNode) error {
+	name, err := RefName(n)
+	if err != nil {
+		return err
+	}
+	for i, expanding := range v.path {
+		if expanding == name {
+			cycle := append(v.path[i:len(v.path):len(v.path)], name)
+			return fmt.Errorf("%w: %s", ErrCyclicSpecification, strings.Join(cycle, " -> "))
+		}
+	}
+	ast, ok := v.registry.Lookup(name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUndefinedSpecification, name)
+	}
+	v.path = append(v.path, name)
+	defer func() { v.path = v.path[:len(v.path)-1] }()
+	_, err = v.expand(ast)
+	return err
+}
@@ -0,0 +1,78 @@
+package specification
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+func TestSpecificationRegistryExpand(t *testing.T) {
+	root := GlobalScope()
+	registry := NewSpecificationRegistry()
+	// AdultUser references ActiveUser defined later
+	for name, ast := range map[string]Visitable{
+		"AdultUser":  And(GreaterThanEqual(Field(root, "age"), Value(18)), Ref("ActiveUser")),
+		"ActiveUser": Not(Field(root, "blocked")),
+	} {
+		if err := registry.Define(name, ast); err != nil {
+			t.Fatalf("Define failed: %v", err)
+		}
+	}
+	if err := registry.Define("ActiveUser", Value(true)); err == nil {
+		t.Error("Expected error for the redefinition")
+	}
+
+	expanded, err := registry.Expand(Wildcard(Object(root, "members"), Ref("AdultUser")))
+	if err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+	expected := Wildcard(Object(root, "members"),
+		And(GreaterThanEqual(Field(root, "age"), Value(18)), Not(Field(root, "blocked"))))
+	if !reflect.DeepEqual(expanded, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, expanded)
+	}
+
+	ctx := testContext{"age": 20, "blocked": false}
+	visitor := NewEvaluateVisitor(ctx, operators.NewDefaultRegistry())
+	expanded, err = registry.Expand(Ref("AdultUser"))
+	if err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+	if err := expanded.Accept(visitor); err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	if visitor.CurrentValue() != true {
+		t.Errorf("Expected true, got %v", visitor.CurrentValue())
+	}
+}
+
+func TestSpecificationRegistryErrors(t *testing.T) {
+	root := GlobalScope()
+	registry := NewSpecificationRegistry()
+	_ = registry.Define("A", Or(Field(root, "a"), Ref("B")))
+	_ = registry.Define("B", Coalesce(Ref("A"), Value(false)))
+	_ = registry.Define("C", Ref("Missing"))
+	_ = registry.Define("D", And(Field(root, "d"), Ref("C")))
+
+	_, err := registry.Expand(Not(Ref("A")))
+	if !errors.Is(err, ErrCyclicSpecification) {
+		t.Fatalf("Expected ErrCyclicSpecification, got %v", err)
+	}
+	if err.Error() != "cyclic specification: A -> B -> A" {
+		t.Errorf("Unexpected error message %q", err.Error())
+	}
+
+	_, err = registry.Expand(Ref("D"))
+	if !errors.Is(err, ErrUndefinedSpecification) {
+		t.Errorf("Expected ErrUndefinedSpecification, got %v", err)
+	}
+
+	// The same definition referenced twice is not a cycle
+	_ = registry.Define("E", And(Ref("F"), Ref("F")))
+	_ = registry.Define("F", Field(root, "f"))
+	if _, err := registry.Expand(Ref("E")); err != nil {
+		t.Errorf("Expected the repeated reference to expand, got %v", err)
+	}
+}
@@ -9,10 +9,14 @@ import (
 
 var ErrKeyNotFound = errors.New("key not found")
 
+// NewEvaluateVisitor creates the visitor evaluating operators of the registry.
+// Float64 numbers are compared exactly unless the registry is configured with operators.RegisterFloatTolerance;
+// compiled SQL compares them exactly regardless.
 func NewEvaluateVisitor(context Context, registry *operators.OperatorRegistry) *EvaluateVisitor {
 	return &EvaluateVisitor{
 		Context:  context,
 		registry: registry,
+		clock:    SystemClock,
 	}
 }
 
@@ -21,9 +25,44 @@ type EvaluateVisitor struct {
 	currentItem  Context
 	stack        []Context
 	registry     *operators.OperatorRegistry
+	binder       func(value any) (any, error)
+	clock        Clock
+	// explanation is the trace of the evaluated node if the evaluation is explained, see Explain
+	explanation *Explanation
 	Context
 }
 
+// SetBinder sets the binder of the values of ValueNode, e.g. of the placeholders of a parametrized AST,
+// so that a shared AST is evaluated with the values of the call without being copied.
+func (v *EvaluateVisitor) SetBinder(binder func(value any) (any, error)) {
+	v.binder = binder
+}
+
+// SetClock sets the Clock of Now and CurrentDate, SystemClock by default.
+func (v *EvaluateVisitor) SetClock(clock Clock) {
+	v.clock = clock
+}
+
+// evaluate evaluates the operand, its trace is added to the explanation of the node if it is explained.
+// The literal values are traced only if they are bound, e.g. the placeholders.
+func (v *EvaluateVisitor) evaluate(n Visitable) error {
+	if _, ok := n.(ValueNode); v.explanation == nil || (ok && v.binder == nil) {
+		return n.Accept(v)
+	}
+	outer := v.explanation
+	explanation := &Explanation{Node: n, Expression: explainedExpression(n)}
+	v.explanation = explanation
+	err := n.Accept(v)
+	v.explanation = outer
+	if err != nil {
+		explanation.Err = err
+	} else {
+		explanation.Result = v.CurrentValue()
+	}
+	outer.Subexpressions = append(outer.Subexpressions, explanation)
+	return err
+}
+
 func (v *EvaluateVisitor) push(ctx Context) {
 	v.stack = append(v.stack, v.Context)
 	v.Context = ctx
@@ -57,11 +96,34 @@ func (v *EvaluateVisitor) VisitObject(n ObjectNode) error {
 	if err != nil {
 		return err
 	}
-	v.push(obj.(Context))
+	ctx, ok := obj.(Context)
+	if !ok {
+		return fmt.Errorf("%s is %T, not an object", n.Name(), obj)
+	}
+	v.push(ctx)
 	return nil
 }
 
 func (v *EvaluateVisitor) VisitCollection(n CollectionNode) error {
+	result := false
+	err := v.forEachItem(n, func() error {
+		matched, ok := v.CurrentValue().(bool)
+		if !ok {
+			return fmt.Errorf("wildcard predicate evaluated to %T, not bool", v.CurrentValue())
+		}
+		result = result || matched
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	v.SetCurrentValue(result)
+	return nil
+}
+
+// forEachItem evaluates the predicate of the collection for each selected element
+// and calls fn with the value of the predicate as the current value.
+func (v *EvaluateVisitor) forEachItem(n CollectionNode, fn func() error) error {
 	err := n.Parent().Accept(v)
 	if err != nil {
 		return err
@@ -75,14 +137,70 @@ func (v *EvaluateVisitor) VisitCollection(n CollectionNode) error {
 	if !ok {
 		return errors.New("currentValue is not a collection of Contexts")
 	}
-	result := false
+	outerItem := v.currentItem
+	defer func() { v.currentItem = outerItem }()
 	for i := range itemsTyped {
 		v.currentItem = itemsTyped[i]
-		err := n.Predicate().Accept(v)
+		err := v.evaluate(n.Predicate())
+		if err != nil {
+			return err
+		}
+		err = fn()
 		if err != nil {
 			return err
 		}
-		result = result || v.CurrentValue().(bool)
+	}
+	return nil
+}
+
+// visitAggregate aggregates the values of the elements by the operators of the registry,
+// NULL values are skipped and the aggregate of no values is NULL, except for count.
+func (v *EvaluateVisitor) visitAggregate(n FunctionNode) error {
+	if len(n.Args()) != 1 {
+		return fmt.Errorf("function \"%s\" requires 1 argument, got %d", n.Name(), len(n.Args()))
+	}
+	collection, ok := n.Args()[0].(CollectionNode)
+	if !ok {
+		return fmt.Errorf("function \"%s\" requires collection, got %T", n.Name(), n.Args()[0])
+	}
+	var result any
+	if n.Name() == FunctionCount {
+		result = 0
+	}
+	err := v.forEachItem(collection, func() error {
+		value := v.CurrentValue()
+		if n.Name() == FunctionCount {
+			if value == true {
+				result = result.(int) + 1
+			}
+			return nil
+		}
+		if value == nil {
+			return nil
+		}
+		if result == nil {
+			result = value
+			return nil
+		}
+		var err error
+		switch n.Name() {
+		case FunctionSum:
+			result, err = v.registry.ExecBinary(result, operators.OperatorAdd, value)
+		case FunctionMin, FunctionMax:
+			op := operators.OperatorLt
+			if n.Name() == FunctionMax {
+				op = operators.OperatorGt
+			}
+			var better any
+			better, err = v.registry.ExecBinary(value, op, result)
+			if better == true {
+				result = value
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return err
 	}
 	v.SetCurrentValue(result)
 	return nil
@@ -108,12 +226,20 @@ func (v *EvaluateVisitor) VisitField(n FieldNode) error {
 }
 
 func (v *EvaluateVisitor) VisitValue(n ValueNode) error {
-	v.SetCurrentValue(n.Value())
+	if v.binder == nil {
+		v.SetCurrentValue(n.Value())
+		return nil
+	}
+	value, err := v.binder(n.Value())
+	if err != nil {
+		return err
+	}
+	v.SetCurrentValue(value)
 	return nil
 }
 
 func (v *EvaluateVisitor) VisitPrefix(n PrefixNode) error {
-	err := n.Operand().Accept(v)
+	err := v.evaluate(n.Operand())
 	if err != nil {
 		return err
 	}
@@ -126,7 +252,7 @@ func (v *EvaluateVisitor) VisitPrefix(n PrefixNode) error {
 }
 
 func (v *EvaluateVisitor) VisitPostfix(n PostfixNode) error {
-	err := n.Operand().Accept(v)
+	err := v.evaluate(n.Operand())
 	if err != nil {
 		return err
 	}
@@ -139,12 +265,12 @@ func (v *EvaluateVisitor) VisitPostfix(n PostfixNode) error {
 }
 
 func (v *EvaluateVisitor) VisitInfix(n InfixNode) error {
-	err := n.Left().Accept(v)
+	err := v.evaluate(n.Left())
 	if err != nil {
 		return err
 	}
 	left := v.CurrentValue()
-	err = n.Right().Accept(v)
+	err = v.evaluate(n.Right())
 	if err != nil {
 		return err
 	}
@@ -157,6 +283,64 @@ func (v *EvaluateVisitor) VisitInfix(n InfixNode) error {
 	return nil
 }
 
+func (v *EvaluateVisitor) VisitFunction(n FunctionNode) error {
+	if n.Name() == FunctionBetween {
+		// The bounds are compared by the operators of the registry
+		comparison, err := BetweenComparison(n)
+		if err != nil {
+			return err
+		}
+		return comparison.Accept(v)
+	}
+	if n.Name() == FunctionCase {
+		return v.visitCase(n)
+	}
+	if IsAggregate(n.Name()) {
+		return v.visitAggregate(n)
+	}
+	if n.Name() == FunctionNow {
+		v.SetCurrentValue(v.clock.Now())
+		return nil
+	}
+	if n.Name() == FunctionCurrentDate {
+		v.SetCurrentValue(today(v.clock.Now()))
+		return nil
+	}
+	args := make([]any, 0, len(n.Args()))
+	for _, arg := range n.Args() {
+		err := v.evaluate(arg)
+		if err != nil {
+			return err
+		}
+		args = append(args, v.CurrentValue())
+	}
+	result, err := ExecFunction(n.Name(), args)
+	if err != nil {
+		return err
+	}
+	v.SetCurrentValue(result)
+	return nil
+}
+
+func (v *EvaluateVisitor) VisitExtension(n ExtensionNode) error {
+	return VisitFallback(n, v)
+}
+
+// visitCase evaluates only the branch selected by the condition.
+func (v *EvaluateVisitor) visitCase(n FunctionNode) error {
+	if len(n.Args()) != 3 {
+		return fmt.Errorf("function \"%s\" requires 3 arguments, got %d", n.Name(), len(n.Args()))
+	}
+	err := v.evaluate(n.Args()[0])
+	if err != nil {
+		return err
+	}
+	if v.CurrentValue() == true {
+		return v.evaluate(n.Args()[1])
+	}
+	return v.evaluate(n.Args()[2])
+}
+
 func (v EvaluateVisitor) Result() (bool, error) {
 	result := v.CurrentValue()
 	resultTyped, ok := result.(bool)
@@ -189,8 +373,9 @@ func NewCollectionContext(items []Context) CollectionContext {
 }
 
 func (c CollectionContext) Get(slice string) (any, error) {
-	if slice == "*" {
-		return c.items, nil
+	start, end, err := SelectorBounds(slice, len(c.items))
+	if err != nil {
+		return nil, err
 	}
-	return nil, fmt.Errorf("unsupported slice type \"%s\"", slice)
+	return c.items[start:end], nil
 }
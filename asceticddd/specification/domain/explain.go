@@ -0,0 +1,74 @@
+package specification
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+// Explanation is the evaluation trace of the expression: the resolved result of the expression
+// and the explanations of its subexpressions in the evaluation order, e.g. the operands of the operators,
+// the arguments of the functions and the predicate of each element of the collection.
+// The literal values are not traced, their expressions are the values.
+type Explanation struct {
+	Node           Visitable
+	Expression     string
+	Result         any
+	Err            error
+	Subexpressions []*Explanation
+}
+
+// Explain evaluates AST by the default registry and returns the explanation of the result,
+// e.g. to find out why a complex specification is unexpectedly false:
+//
+//	age >= 18 && name != "" => false
+//	  age >= 18 => false
+//	    age => 16
+//
+// If the evaluation fails, the explanation is traced up to the failed subexpression, which has Err.
+func Explain(ast Visitable, ctx Context) (*Explanation, error) {
+	return NewEvaluateVisitor(ctx, operators.NewDefaultRegistry()).Explain(ast)
+}
+
+// Explain evaluates AST as Accept does and returns the explanation of the result, see Explain.
+func (v *EvaluateVisitor) Explain(ast Visitable) (*Explanation, error) {
+	explanation := &Explanation{Node: ast, Expression: explainedExpression(ast)}
+	v.explanation = explanation
+	err := ast.Accept(v)
+	v.explanation = nil
+	if err != nil {
+		explanation.Err = err
+		return explanation, err
+	}
+	explanation.Result = v.CurrentValue()
+	return explanation, nil
+}
+
+// String formats the explanation as the indented tree of the expressions and their results.
+func (e *Explanation) String() string {
+	var text strings.Builder
+	e.format(&text, 0)
+	return strings.TrimSuffix(text.String(), "\n")
+}
+
+func (e *Explanation) format(text *strings.Builder, depth int) {
+	text.WriteString(strings.Repeat("  ", depth))
+	text.WriteString(e.Expression)
+	if e.Err != nil {
+		text.WriteString(" => error: " + e.Err.Error() + "\n")
+	} else {
+		text.WriteString(" => " + formatValue(e.Result) + "\n")
+	}
+	for _, subexpression := range e.Subexpressions {
+		subexpression.format(text, depth+1)
+	}
+}
+
+func explainedExpression(n Visitable) string {
+	expression, err := ToInfix(n)
+	if err != nil {
+		return fmt.Sprintf("%T", n)
+	}
+	return expression
+}
@@ -0,0 +1,59 @@
+package specification
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	root := GlobalScope()
+	ast := And(
+		GreaterThanEqual(Field(root, "age"), Value(18)),
+		Wildcard(Object(root, "items"), GreaterThan(Field(Item(), "price"), Value(100))),
+	)
+	ctx := testContext{
+		"age": 16,
+		"items": NewCollectionContext([]Context{
+			testContext{"price": 50},
+			testContext{"price": 150},
+		}),
+	}
+
+	explanation, err := Explain(ast, ctx)
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if explanation.Result != false {
+		t.Errorf("Expected false, got %v", explanation.Result)
+	}
+	if len(explanation.Subexpressions) != 2 || explanation.Subexpressions[0].Result != false {
+		t.Fatalf("Unexpected subexpressions %v", explanation.Subexpressions)
+	}
+	expected := `age >= 18 && items[*][?@.price > 100] => false
+  age >= 18 => false
+    age => 16
+  items[*][?@.price > 100] => true
+    @.price > 100 => false
+      @.price => 50
+    @.price > 100 => true
+      @.price => 150`
+	if explanation.String() != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, explanation)
+	}
+}
+
+func TestExplainError(t *testing.T) {
+	ast := Or(Equal(Field(GlobalScope(), "name"), Value("Alice")), Field(GlobalScope(), "active"))
+
+	explanation, err := Explain(ast, testContext{"name": "Bob"})
+	if !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("Expected ErrKeyNotFound, got %v", err)
+	}
+	failed := explanation.Subexpressions[1]
+	if failed.Expression != "active" || !errors.Is(failed.Err, ErrKeyNotFound) {
+		t.Errorf("Expected the failed field, got %#v", failed)
+	}
+	if explanation.Subexpressions[0].Result != false {
+		t.Errorf("Expected the evaluated comparison, got %v", explanation.Subexpressions[0].Result)
+	}
+}
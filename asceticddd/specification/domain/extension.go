@@ -0,0 +1,52 @@
+package specification
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnsupportedExtension is returned by the visitor of the extension node which has no fallback.
+	ErrUnsupportedExtension = errors.New("unsupported extension node")
+	// ErrUnsupportedFunction is returned for FunctionNode by the visitor which is not FunctionVisitor.
+	ErrUnsupportedFunction = errors.New("unsupported function")
+)
+
+// ExtensionNode is the node type of a downstream package, its Accept calls AcceptExtension:
+//
+//	func (n WithinRadius) Accept(v Visitor) error {
+//		return AcceptExtension(n, v)
+//	}
+//
+// The visitors which do not know the node type delegate to its fallback by VisitFallback,
+// the compilers may compile it by the registered emitters instead.
+type ExtensionNode interface {
+	Visitable
+	// Fallback returns the equivalent AST of the built-in nodes, or nil if there is none.
+	Fallback() Visitable
+}
+
+// ExtensionVisitor is implemented by the visitors handling extension nodes themselves.
+// It is not a part of Visitor, so the visitors of downstream packages keep compiling.
+type ExtensionVisitor interface {
+	Visitor
+	VisitExtension(ExtensionNode) error
+}
+
+// AcceptExtension calls ExtensionVisitor.VisitExtension, or visits the fallback of the node
+// if the visitor is not ExtensionVisitor.
+func AcceptExtension(n ExtensionNode, v Visitor) error {
+	if ev, ok := v.(ExtensionVisitor); ok {
+		return ev.VisitExtension(n)
+	}
+	return VisitFallback(n, v)
+}
+
+// VisitFallback visits the fallback of the extension node, it is the default of ExtensionVisitor.VisitExtension.
+func VisitFallback(n ExtensionNode, v Visitor) error {
+	fallback := n.Fallback()
+	if fallback == nil {
+		return fmt.Errorf("%w: %T", ErrUnsupportedExtension, n)
+	}
+	return fallback.Accept(v)
+}
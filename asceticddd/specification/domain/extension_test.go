@@ -0,0 +1,126 @@
+package specification
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+// adult is the extension node of the test, its fallback is age >= 18.
+type adult struct {
+	age FieldNode
+}
+
+func (n adult) Accept(v Visitor) error {
+	return AcceptExtension(n, v)
+}
+
+func (n adult) Fallback() Visitable {
+	return GreaterThanEqual(n.age, Value(18))
+}
+
+// opaque is the extension node of the test without fallback.
+type opaque struct{}
+
+func (n opaque) Accept(v Visitor) error {
+	return AcceptExtension(n, v)
+}
+
+func (n opaque) Fallback() Visitable {
+	return nil
+}
+
+func TestExtensionFallback(t *testing.T) {
+	ast := And(adult{Field(GlobalScope(), "age")}, Field(GlobalScope(), "active"))
+
+	for _, tc := range []struct {
+		age      int
+		expected bool
+	}{
+		{20, true},
+		{16, false},
+	} {
+		visitor := NewEvaluateVisitor(testContext{"age": tc.age, "active": true}, operators.NewDefaultRegistry())
+		if err := ast.Accept(visitor); err != nil {
+			t.Fatalf("Evaluate failed: %v", err)
+		}
+		if visitor.CurrentValue() != tc.expected {
+			t.Errorf("Age %d: expected %v, got %v", tc.age, tc.expected, visitor.CurrentValue())
+		}
+	}
+
+	infix, err := ToInfix(ast)
+	if err != nil {
+		t.Fatalf("ToInfix failed: %v", err)
+	}
+	if infix != "age >= 18 && active" {
+		t.Errorf("Unexpected infix %s", infix)
+	}
+}
+
+func TestExtensionKeptByRewriters(t *testing.T) {
+	node := adult{Field(GlobalScope(), "age")}
+
+	simplified, err := Simplify(And(node, Value(true)), operators.NewDefaultRegistry())
+	if err != nil {
+		t.Fatalf("Simplify failed: %v", err)
+	}
+	if simplified != node {
+		t.Errorf("Expected the extension node, got %#v", simplified)
+	}
+}
+
+func TestExtensionWithoutFallback(t *testing.T) {
+	visitor := NewEvaluateVisitor(testContext{}, operators.NewDefaultRegistry())
+	err := Not(opaque{}).Accept(visitor)
+	if !errors.Is(err, ErrUnsupportedExtension) {
+		t.Errorf("Expected ErrUnsupportedExtension, got %v", err)
+	}
+}
+
+// fieldCollector implements Visitor only, like the visitors written before FunctionVisitor and ExtensionVisitor.
+type fieldCollector struct {
+	fields []string
+}
+
+func (v *fieldCollector) VisitGlobalScope(GlobalScopeNode) error { return nil }
+func (v *fieldCollector) VisitObject(ObjectNode) error           { return nil }
+func (v *fieldCollector) VisitCollection(CollectionNode) error   { return nil }
+func (v *fieldCollector) VisitItem(ItemNode) error               { return nil }
+func (v *fieldCollector) VisitValue(ValueNode) error             { return nil }
+
+func (v *fieldCollector) VisitField(n FieldNode) error {
+	v.fields = append(v.fields, n.Name())
+	return nil
+}
+
+func (v *fieldCollector) VisitPrefix(n PrefixNode) error {
+	return n.Operand().Accept(v)
+}
+
+func (v *fieldCollector) VisitInfix(n InfixNode) error {
+	if err := n.Left().Accept(v); err != nil {
+		return err
+	}
+	return n.Right().Accept(v)
+}
+
+func (v *fieldCollector) VisitPostfix(n PostfixNode) error {
+	return n.Operand().Accept(v)
+}
+
+func TestExtensionOfBaseVisitor(t *testing.T) {
+	visitor := &fieldCollector{}
+	if err := And(adult{Field(GlobalScope(), "age")}, Field(GlobalScope(), "active")).Accept(visitor); err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	if len(visitor.fields) != 2 || visitor.fields[0] != "age" || visitor.fields[1] != "active" {
+		t.Errorf("Unexpected fields %v", visitor.fields)
+	}
+
+	err := Length(Field(GlobalScope(), "name")).Accept(visitor)
+	if !errors.Is(err, ErrUnsupportedFunction) {
+		t.Errorf("Expected ErrUnsupportedFunction, got %v", err)
+	}
+}
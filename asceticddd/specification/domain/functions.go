@@ -0,0 +1,142 @@
+package specification
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	FunctionMatch      = "match"
+	FunctionSearch     = "search"
+	FunctionStartsWith = "startsWith"
+	FunctionEndsWith   = "endsWith"
+	FunctionContains   = "contains"
+	FunctionLength     = "length"
+	FunctionBetween    = "between"
+	FunctionCoalesce   = "coalesce"
+	FunctionCase       = "case"
+	FunctionCount      = "count"
+	FunctionSum        = "sum"
+	FunctionMin        = "min"
+	FunctionMax        = "max"
+	// FunctionNow and FunctionCurrentDate have no arguments, they are resolved by the Clock of the evaluation.
+	FunctionNow         = "now"
+	FunctionCurrentDate = "current_date"
+)
+
+// IsAggregate reports whether the function aggregates the elements of its CollectionNode argument,
+// see CountOf, SumOf, MinOf and MaxOf.
+func IsAggregate(name string) bool {
+	switch name {
+	case FunctionCount, FunctionSum, FunctionMin, FunctionMax:
+		return true
+	}
+	return false
+}
+
+type stringFunction func(value, arg string) (bool, error)
+
+var stringFunctions = map[string]stringFunction{
+	FunctionMatch: func(value, pattern string) (bool, error) {
+		re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(value), nil
+	},
+	FunctionSearch: func(value, pattern string) (bool, error) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(value), nil
+	},
+	FunctionStartsWith: func(value, prefix string) (bool, error) {
+		return strings.HasPrefix(value, prefix), nil
+	},
+	FunctionEndsWith: func(value, suffix string) (bool, error) {
+		return strings.HasSuffix(value, suffix), nil
+	},
+	FunctionContains: func(value, substring string) (bool, error) {
+		return strings.Contains(value, substring), nil
+	},
+}
+
+// IsFunction reports whether the string predicate of the name is supported.
+func IsFunction(name string) bool {
+	_, ok := stringFunctions[name]
+	return ok
+}
+
+// ExecFunction executes the function with PostgreSQL NULL semantics, a NULL argument gives NULL.
+// As in RFC 9535, a non-string value does not match.
+func ExecFunction(name string, args []any) (any, error) {
+	if name == FunctionLength {
+		return execLength(args)
+	}
+	if name == FunctionCoalesce {
+		return execCoalesce(args)
+	}
+	fn, ok := stringFunctions[name]
+	if !ok {
+		return nil, fmt.Errorf("function \"%s\" is not supported", name)
+	}
+	if len(args) != 2 {
+		return nil, fmt.Errorf("function \"%s\" requires 2 arguments, got %d", name, len(args))
+	}
+	if args[0] == nil || args[1] == nil {
+		return nil, nil
+	}
+	arg, ok := args[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("function \"%s\" requires string argument, got %T", name, args[1])
+	}
+	value, ok := args[0].(string)
+	if !ok {
+		return false, nil
+	}
+	return fn(value, arg)
+}
+
+// BetweenComparison returns the comparisons the between function is equal to, as BETWEEN in SQL:
+// value >= low AND value <= high.
+func BetweenComparison(n FunctionNode) (InfixNode, error) {
+	if n.Name() != FunctionBetween {
+		return InfixNode{}, fmt.Errorf("function \"%s\" is not %s", n.Name(), FunctionBetween)
+	}
+	if len(n.Args()) != 3 {
+		return InfixNode{}, fmt.Errorf("function \"%s\" requires 3 arguments, got %d", n.Name(), len(n.Args()))
+	}
+	value, low, high := n.Args()[0], n.Args()[1], n.Args()[2]
+	return And(GreaterThanEqual(value, low), LessThanEqual(value, high)), nil
+}
+
+// execCoalesce returns the first argument that is not NULL, as COALESCE in SQL.
+func execCoalesce(args []any) (any, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("function \"%s\" requires at least 1 argument", FunctionCoalesce)
+	}
+	for _, arg := range args {
+		if arg != nil {
+			return arg, nil
+		}
+	}
+	return nil, nil
+}
+
+// execLength counts the characters of the string, as length() in PostgreSQL.
+func execLength(args []any) (any, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("function \"%s\" requires 1 argument, got %d", FunctionLength, len(args))
+	}
+	if args[0] == nil {
+		return nil, nil
+	}
+	value, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("function \"%s\" requires string, got %T", FunctionLength, args[0])
+	}
+	return utf8.RuneCountInString(value), nil
+}
@@ -0,0 +1,235 @@
+package specification
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+// ToInfix renders AST as the readable expression, e.g. age >= 18 && name != "", for audit logs and admin UIs.
+//
+// The fields of the global scope are the paths, e.g. user.profile.age, the fields of the collection items are
+// prefixed by @, and the collections are rendered as in JSONPath, e.g. items[*][?@.price > 100].
+// The operators are parenthesized only where their precedence requires it.
+func ToInfix(ast Visitable) (string, error) {
+	visitor := &infixVisitor{}
+	if err := ast.Accept(visitor); err != nil {
+		return "", err
+	}
+	return visitor.text.String(), nil
+}
+
+// infixOperator is the symbol and the precedence of the rendered operator, the higher one binds tighter.
+type infixOperator struct {
+	symbol     string
+	precedence int
+}
+
+var infixOperators = map[operators.Operator]infixOperator{
+	operators.OperatorOr:        {"||", 10},
+	operators.OperatorAnd:       {"&&", 20},
+	operators.OperatorEq:        {"==", 40},
+	operators.OperatorNe:        {"!=", 40},
+	operators.OperatorGt:        {">", 40},
+	operators.OperatorLt:        {"<", 40},
+	operators.OperatorGte:       {">=", 40},
+	operators.OperatorLte:       {"<=", 40},
+	operators.OperatorIs:        {"is", 40},
+	operators.OperatorIn:        {"in", 40},
+	operators.OperatorNotIn:     {"not in", 40},
+	operators.OperatorIsNull:    {"is null", 40},
+	operators.OperatorIsNotNull: {"is not null", 40},
+	operators.OperatorBitOr:     {"|", 50},
+	operators.OperatorBitXor:    {"^", 55},
+	operators.OperatorBitAnd:    {"&", 60},
+	operators.OperatorLshift:    {"<<", 65},
+	operators.OperatorRshift:    {">>", 65},
+	operators.OperatorAdd:       {"+", 70},
+	operators.OperatorSub:       {"-", 70},
+	operators.OperatorConcat:    {"++", 70},
+	operators.OperatorMul:       {"*", 80},
+	operators.OperatorDiv:       {"/", 80},
+	operators.OperatorMod:       {"%", 80},
+	operators.OperatorNot:       {"!", 90},
+	operators.OperatorPos:       {"+", 90},
+	operators.OperatorNeg:       {"-", 90},
+}
+
+func lookupInfixOperator(op operators.Operator) infixOperator {
+	if rendered, ok := infixOperators[op]; ok {
+		return rendered
+	}
+	return infixOperator{string(op), 40}
+}
+
+type infixVisitor struct {
+	text strings.Builder
+	// precedence is the least precedence of the operator rendered without parentheses at the position
+	precedence int
+}
+
+// visitOperand renders the operand of the operator, the operators of lower precedence are parenthesized.
+func (v *infixVisitor) visitOperand(n Visitable, precedence int) error {
+	outer := v.precedence
+	v.precedence = precedence
+	err := n.Accept(v)
+	v.precedence = outer
+	return err
+}
+
+func (v *infixVisitor) visitOperator(precedence int, render func() error) error {
+	parenthesized := precedence < v.precedence
+	if parenthesized {
+		v.text.WriteString("(")
+	}
+	if err := render(); err != nil {
+		return err
+	}
+	if parenthesized {
+		v.text.WriteString(")")
+	}
+	return nil
+}
+
+func (v *infixVisitor) VisitGlobalScope(_ GlobalScopeNode) error {
+	v.text.WriteString("$")
+	return nil
+}
+
+func (v *infixVisitor) VisitItem(_ ItemNode) error {
+	v.text.WriteString("@")
+	return nil
+}
+
+func (v *infixVisitor) VisitObject(n ObjectNode) error {
+	v.text.WriteString(objectPath(n))
+	return nil
+}
+
+func (v *infixVisitor) VisitCollection(n CollectionNode) error {
+	v.text.WriteString(objectPath(n.Parent()))
+	v.text.WriteString("[" + n.Name() + "][?")
+	if err := v.visitOperand(n.Predicate(), 0); err != nil {
+		return err
+	}
+	v.text.WriteString("]")
+	return nil
+}
+
+func (v *infixVisitor) VisitField(n FieldNode) error {
+	if path := objectPath(n.Object()); path != "" {
+		v.text.WriteString(path + ".")
+	}
+	v.text.WriteString(n.Name())
+	return nil
+}
+
+// objectPath returns the dotted path of the object, the path of the collection item starts with @.
+func objectPath(obj EmptiableObject) string {
+	var names []string
+	for ; !obj.IsRoot(); obj = obj.Parent() {
+		names = append([]string{obj.Name()}, names...)
+	}
+	if _, ok := obj.(ItemNode); ok {
+		names = append([]string{"@"}, names...)
+	}
+	return strings.Join(names, ".")
+}
+
+func (v *infixVisitor) VisitValue(n ValueNode) error {
+	v.text.WriteString(formatValue(n.Value()))
+	return nil
+}
+
+// formatValue renders the literal of the value, the strings, the datetimes and the identifiers are quoted.
+func formatValue(value any) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(typed)
+	case *big.Rat:
+		if prec, exact := typed.FloatPrec(); exact {
+			return typed.FloatString(prec)
+		}
+		return typed.RatString()
+	case time.Time:
+		return strconv.Quote(typed.Format(time.RFC3339Nano))
+	case time.Duration:
+		return typed.String()
+	case fmt.Stringer:
+		return strconv.Quote(typed.String())
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Slice, reflect.Array:
+		items := make([]string, 0, v.Len())
+		for i := range v.Len() {
+			items = append(items, formatValue(v.Index(i).Interface()))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return fmt.Sprint(value)
+}
+
+func (v *infixVisitor) VisitPrefix(n PrefixNode) error {
+	op := lookupInfixOperator(n.Operator())
+	return v.visitOperator(op.precedence, func() error {
+		v.text.WriteString(op.symbol)
+		return v.visitOperand(n.Operand(), op.precedence+1)
+	})
+}
+
+func (v *infixVisitor) VisitInfix(n InfixNode) error {
+	op := lookupInfixOperator(n.Operator())
+	left, right := op.precedence+1, op.precedence+1
+	switch n.Associativity() {
+	case LeftAssociative:
+		left = op.precedence
+	case RightAssociative:
+		right = op.precedence
+	}
+	return v.visitOperator(op.precedence, func() error {
+		if err := v.visitOperand(n.Left(), left); err != nil {
+			return err
+		}
+		v.text.WriteString(" " + op.symbol + " ")
+		return v.visitOperand(n.Right(), right)
+	})
+}
+
+func (v *infixVisitor) VisitPostfix(n PostfixNode) error {
+	op := lookupInfixOperator(n.Operator())
+	return v.visitOperator(op.precedence, func() error {
+		if err := v.visitOperand(n.Operand(), op.precedence+1); err != nil {
+			return err
+		}
+		v.text.WriteString(" " + op.symbol)
+		return nil
+	})
+}
+
+func (v *infixVisitor) VisitFunction(n FunctionNode) error {
+	v.text.WriteString(n.Name() + "(")
+	for i, arg := range n.Args() {
+		if i > 0 {
+			v.text.WriteString(", ")
+		}
+		if err := v.visitOperand(arg, 0); err != nil {
+			return err
+		}
+	}
+	v.text.WriteString(")")
+	return nil
+}
+
+func (v *infixVisitor) VisitExtension(n ExtensionNode) error {
+	return VisitFallback(n, v)
+}
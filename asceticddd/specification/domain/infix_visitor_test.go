@@ -0,0 +1,48 @@
+package specification
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+func TestToInfix(t *testing.T) {
+	root := GlobalScope()
+	age := Field(root, "age")
+	name := Field(root, "name")
+
+	for _, tc := range []struct {
+		name     string
+		ast      Visitable
+		expected string
+	}{
+		{"comparison", And(GreaterThanEqual(age, Value(18)), NotEqual(name, Value(""))), `age >= 18 && name != ""`},
+		{"precedence", And(Or(Field(root, "a"), Field(root, "b")), Field(root, "c")), `(a || b) && c`},
+		{"left associative", Sub(Value(10), Sub(age, Value(1))), `10 - (age - 1)`},
+		{"arithmetic", Equal(Add(Mul(Field(root, "price"), Value(2)), Value(1)), Value(5)), `price * 2 + 1 == 5`},
+		{"not", Not(Equal(name, Value("Bob"))), `!(name == "Bob")`},
+		{"negation", NewPrefixNode(operators.OperatorNeg, Field(root, "score"), RightAssociative), `-score`},
+		{"null check", IsNotNull(Field(Object(Object(root, "user"), "profile"), "email")), `user.profile.email is not null`},
+		{"membership", NotIn(Field(root, "status"), Value([]string{"closed", "archived"})),
+			`status not in ["closed", "archived"]`},
+		{"collection", Wildcard(Object(root, "items"), GreaterThan(Field(Item(), "price"), Value(100))),
+			`items[*][?@.price > 100]`},
+		{"every", Every(Object(root, "items"), Field(Item(), "paid")), `!items[*][?!@.paid]`},
+		{"functions", Or(StartsWith(name, Value("Jo")), LessThan(Field(root, "created_at"), Now())),
+			`startsWith(name, "Jo") || created_at < now()`},
+		{"typed values", And(Equal(Field(root, "price"), Value(big.NewRat(1999, 100))),
+			LessThan(Field(root, "due"), Value(time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC)))),
+			`price == 19.99 && due < "2024-05-17T00:00:00Z"`},
+		{"reference", And(Ref("AdultUser"), Value(nil)), `ref("AdultUser") && null`},
+	} {
+		text, err := ToInfix(tc.ast)
+		if err != nil {
+			t.Fatalf("%s: ToInfix failed: %v", tc.name, err)
+		}
+		if text != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.expected, text)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package specification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// JSONContext is the Context of a raw JSON object, e.g. of the payload of an event or of an outbox message.
+//
+// The object is split into raw fields on the first Get, and only the accessed fields are decoded:
+//   - nested objects are JSONContext, arrays of objects are CollectionContext of them, so are empty arrays
+//   - other arrays are []any, e.g. of IN
+//   - integer numbers are int, like integer literals of specifications, others are float64
+//   - null is NULL
+type JSONContext struct {
+	data   json.RawMessage
+	once   sync.Once
+	fields map[string]json.RawMessage
+	err    error
+}
+
+// NewJSONContext creates the context of the raw JSON object.
+func NewJSONContext(data json.RawMessage) *JSONContext {
+	return &JSONContext{data: data}
+}
+
+func (c *JSONContext) Get(key string) (any, error) {
+	c.once.Do(func() {
+		c.err = json.Unmarshal(c.data, &c.fields)
+	})
+	if c.err != nil {
+		return nil, c.err
+	}
+	raw, ok := c.fields[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+	}
+	return decodeJSONValue(raw)
+}
+
+func decodeJSONValue(raw json.RawMessage) (any, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	switch raw[0] {
+	case '{':
+		return NewJSONContext(raw), nil
+	case '[':
+		return decodeJSONArray(raw)
+	case '"':
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return s, err
+	case 't', 'f':
+		var b bool
+		err := json.Unmarshal(raw, &b)
+		return b, err
+	case 'n':
+		return nil, nil
+	}
+	return decodeJSONNumber(raw)
+}
+
+func decodeJSONArray(raw json.RawMessage) (any, error) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(raw, &elements); err != nil {
+		return nil, err
+	}
+	items := make([]Context, 0, len(elements))
+	for _, element := range elements {
+		element = bytes.TrimSpace(element)
+		if len(element) == 0 || element[0] != '{' {
+			break
+		}
+		items = append(items, NewJSONContext(element))
+	}
+	if len(items) == len(elements) {
+		return NewCollectionContext(items), nil
+	}
+	values := make([]any, len(elements))
+	for i, element := range elements {
+		value, err := decodeJSONValue(element)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func decodeJSONNumber(raw json.RawMessage) (any, error) {
+	s := string(raw)
+	if i, err := strconv.Atoi(s); err == nil {
+		return i, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON number %s: %w", s, err)
+	}
+	return f, nil
+}
@@ -0,0 +1,50 @@
+package specification
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJSONContext(t *testing.T) {
+	ctx := NewJSONContext([]byte(`{
+		"status": "paid",
+		"total": 310,
+		"rate": 0.2,
+		"gift": true,
+		"note": null,
+		"address": {"city": "Berlin"},
+		"lines": [{"price": 10.5, "qty": 1}, {"price": 150.0, "qty": 2}],
+		"returns": [],
+		"tags": ["gift", "express"]
+	}`))
+	root := GlobalScope()
+	lines := Object(root, "lines")
+
+	for _, tc := range []struct {
+		name     string
+		node     Visitable
+		expected bool
+	}{
+		{"string", Equal(Field(root, "status"), Value("paid")), true},
+		{"integer", GreaterThan(Field(root, "total"), Value(300)), true},
+		{"float", Equal(Field(root, "rate"), Value(0.2)), true},
+		{"bool", Equal(Field(root, "gift"), Value(true)), true},
+		{"null", IsNull(Field(root, "note")), true},
+		{"nested object", Equal(Field(Object(root, "address"), "city"), Value("Berlin")), true},
+		{"array of objects", Wildcard(lines, GreaterThan(Field(Item(), "price"), Value(100.0))), true},
+		{"every", Every(lines, GreaterThan(Field(Item(), "qty"), Value(1))), false},
+		{"empty array", Wildcard(Object(root, "returns"), Equal(Field(Item(), "qty"), Value(1))), false},
+		{"array of values", In(Value("express"), Field(root, "tags")), true},
+	} {
+		if result := evaluateStruct(t, ctx, tc.node); result != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, result)
+		}
+	}
+
+	if _, err := ctx.Get("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+	if _, err := NewJSONContext([]byte(`{"a": `)).Get("a"); err == nil {
+		t.Error("expected error of invalid JSON")
+	}
+}
@@ -0,0 +1,65 @@
+package jsonpath
+
+import (
+	"testing"
+
+	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+)
+
+// Benchmark: comparisons of the filter, placeholders are bound on every call
+func BenchmarkMatch_Comparison(b *testing.B) {
+	s := MustParse("$[?@.age >= %d && @.status == %s && @.score > %f]")
+	user := NewDictContext(map[string]any{"age": 30, "status": "active", "score": 9.5})
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.Match(user, 18, "active", 5.0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Benchmark: wildcard predicate evaluated for every item
+func BenchmarkMatch_Wildcard(b *testing.B) {
+	s := MustParse("$.items[*][?@.price > %f && @.stock > %d]")
+	var items []spec.Context
+	for i := 0; i < 10; i++ {
+		items = append(items, NewDictContext(map[string]any{"price": float64(i), "stock": i}))
+	}
+	root := NewDictContext(map[string]any{"items": spec.NewCollectionContext(items)})
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.Match(root, 100.0, 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Benchmark: list literal with a placeholder
+func BenchmarkMatch_ListLiteral(b *testing.B) {
+	s := MustParse("$[?@.status in ['active', 'pending', %s]]")
+	user := NewDictContext(map[string]any{"status": "trial"})
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.Match(user, "trial"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Benchmark: concurrent calls sharing the specification
+func BenchmarkMatch_Parallel(b *testing.B) {
+	s := MustParse("$[?@.age >= %d && @.status == %s]")
+	user := NewDictContext(map[string]any{"age": 30, "status": "active"})
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := s.Match(user, 18, "active"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package jsonpath
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultParseCacheSize is the size of the parse cache used by Parse and MustParse.
+const DefaultParseCacheSize = 1024
+
+var parseCache atomic.Pointer[ParseCache]
+
+func init() {
+	parseCache.Store(NewParseCache(DefaultParseCacheSize))
+}
+
+// SetParseCache replaces the parse cache used by Parse and MustParse, nil disables caching.
+func SetParseCache(cache *ParseCache) {
+	parseCache.Store(cache)
+}
+
+type parseCacheEntry struct {
+	template string
+	spec     *NativeParametrizedSpecification
+}
+
+// ParseCache caches parsed specifications by their templates, the least recently used ones are evicted
+// beyond the size. It is safe for concurrent use, since parsed specifications are never modified.
+type ParseCache struct {
+	mu    sync.Mutex
+	items map[string]*list.Element
+	order *list.List
+	size  int
+}
+
+func NewParseCache(size int) *ParseCache {
+	return &ParseCache{
+		items: make(map[string]*list.Element, size),
+		order: list.New(),
+		size:  size,
+	}
+}
+
+// Parse returns the cached specification of the template, or parses and caches it.
+// Syntax errors are not cached.
+func (c *ParseCache) Parse(template string) (*NativeParametrizedSpecification, error) {
+	if spec, ok := c.get(template); ok {
+		return spec, nil
+	}
+	// Parsed without the lock, a template parsed concurrently is cached once by the first of them.
+	spec, err := parse(template)
+	if err != nil {
+		return nil, err
+	}
+	return c.add(template, spec), nil
+}
+
+// MustParse is like Parse but panics on error.
+func (c *ParseCache) MustParse(template string) *NativeParametrizedSpecification {
+	spec, err := c.Parse(template)
+	if err != nil {
+		panic(err)
+	}
+	return spec
+}
+
+func (c *ParseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+func (c *ParseCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = make(map[string]*list.Element, c.size)
+	c.order.Init()
+}
+
+func (c *ParseCache) get(template string) (*NativeParametrizedSpecification, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[template]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToBack(elem)
+	return elem.Value.(parseCacheEntry).spec, true
+}
+
+func (c *ParseCache) add(template string, spec *NativeParametrizedSpecification) *NativeParametrizedSpecification {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[template]; ok {
+		c.order.MoveToBack(elem)
+		return elem.Value.(parseCacheEntry).spec
+	}
+	c.items[template] = c.order.PushBack(parseCacheEntry{template: template, spec: spec})
+	if len(c.items) > c.size {
+		front := c.order.Front()
+		c.order.Remove(front)
+		delete(c.items, front.Value.(parseCacheEntry).template)
+	}
+	return spec
+}
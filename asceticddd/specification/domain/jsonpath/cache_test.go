@@ -0,0 +1,90 @@
+package jsonpath
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestParseCache_ReturnsCachedSpecification(t *testing.T) {
+	cache := NewParseCache(10)
+
+	first := cache.MustParse("$[?@.age > %d]")
+	second := cache.MustParse("$[?@.age > %d]")
+
+	if first != second {
+		t.Error("expected the cached specification")
+	}
+	if cache.Len() != 1 {
+		t.Errorf("expected 1 cached specification, got %d", cache.Len())
+	}
+}
+
+func TestParseCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewParseCache(2)
+
+	a := cache.MustParse("$[?@.a > %d]")
+	cache.MustParse("$[?@.b > %d]")
+	cache.MustParse("$[?@.a > %d]") // a is used recently, b is evicted
+	cache.MustParse("$[?@.c > %d]")
+
+	if cache.Len() != 2 {
+		t.Errorf("expected 2 cached specifications, got %d", cache.Len())
+	}
+	if cache.MustParse("$[?@.a > %d]") != a {
+		t.Error("expected a to be cached")
+	}
+	if _, ok := cache.get("$[?@.b > %d]"); ok {
+		t.Error("expected b to be evicted")
+	}
+}
+
+func TestParseCache_DoesNotCacheErrors(t *testing.T) {
+	cache := NewParseCache(10)
+
+	if _, err := cache.Parse("$[?@.age >]"); err == nil {
+		t.Fatal("expected syntax error")
+	}
+	if cache.Len() != 0 {
+		t.Errorf("expected no cached specifications, got %d", cache.Len())
+	}
+}
+
+func TestParseCache_Concurrent(t *testing.T) {
+	cache := NewParseCache(5)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s := cache.MustParse("$[?@.f" + strconv.Itoa(i%10) + " > %d]")
+			data := NewDictContext(map[string]any{"f" + strconv.Itoa(i%10): 10})
+			if result, err := s.Match(data, 5); err != nil || !result {
+				t.Errorf("unexpected result %v, %v", result, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if cache.Len() > 5 {
+		t.Errorf("expected at most 5 cached specifications, got %d", cache.Len())
+	}
+}
+
+func TestSetParseCache(t *testing.T) {
+	cache := NewParseCache(10)
+	SetParseCache(cache)
+	defer SetParseCache(NewParseCache(DefaultParseCacheSize))
+
+	if MustParse("$[?@.age > %d]") != MustParse("$[?@.age > %d]") {
+		t.Error("expected the cached specification")
+	}
+	if cache.Len() != 1 {
+		t.Errorf("expected 1 cached specification, got %d", cache.Len())
+	}
+
+	SetParseCache(nil)
+	if MustParse("$[?@.age > %d]") == MustParse("$[?@.age > %d]") {
+		t.Error("expected parsing without cache")
+	}
+}
@@ -4,18 +4,40 @@
 // Parses RFC 9535 compliant JSONPath expressions with C-style placeholders
 // (%s, %d, %f, %(name)s) and converts them directly to Specification AST nodes.
 //
+// Placeholder values are validated by the format type when bound, a mismatch is JSONPathTypeError:
+//   - %d is an integer, %f is a float, %t is a bool
+//   - %a is a slice, e.g. of `@.status in %a`
+//   - %T is a time.Time
+//   - %s and %v are any value
+//
+// nil is accepted by any placeholder, it is NULL.
+//
 // RFC 9535 Compliance:
 //   - Uses == for equality (double equals)
 //   - Uses && for logical AND (double ampersand)
 //   - Uses || for logical OR (double pipe)
 //   - Uses ! for logical NOT (exclamation mark)
+//
+// Function extensions: match(@.code, 'A[0-9]+') and search(@.name, %s) of RFC 9535 with regular expressions,
+// and the convenience startsWith(@.name, 'Jo'), endsWith(@.email, %s), contains(@.title, 'sale').
+//
+// Collections are selected by the wildcard `$.items[*][?@.price > 10]`, the index `$.items[0][?...]` or `$.items[-1][?...]`,
+// or the slice `$.items[1:3][?...]`, the filter matches if any of the selected elements matches.
+// The universal wildcard `$.items[*!][?...]` matches if all the elements match, as spec.Every.
+//
+// Extensions: membership tests `@.status in %s` and `@.status nin ['closed', 'archived']`,
+// with a slice placeholder or a list literal.
 package jsonpath
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
 	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
@@ -96,6 +118,10 @@ const (
 	TokenGt          TokenType = "GT"
 	TokenLt          TokenType = "LT"
 	TokenNot         TokenType = "NOT"
+	TokenIn          TokenType = "IN"
+	TokenNin         TokenType = "NIN"
+	TokenComma       TokenType = "COMMA"
+	TokenColon       TokenType = "COLON"
 	TokenNumber      TokenType = "NUMBER"
 	TokenString      TokenType = "STRING"
 	TokenPlaceholder TokenType = "PLACEHOLDER"
@@ -140,9 +166,13 @@ var tokenPatterns = []tokenPattern{
 	{TokenGt, regexp.MustCompile(`^>`)},
 	{TokenLt, regexp.MustCompile(`^<`)},
 	{TokenNot, regexp.MustCompile(`^!`)},
+	{TokenComma, regexp.MustCompile(`^,`)},
+	{TokenColon, regexp.MustCompile(`^:`)},
 	{TokenNumber, regexp.MustCompile(`^-?\d+\.?\d*`)},
 	{TokenString, regexp.MustCompile(`^'[^']*'|^"[^"]*"`)},
-	{TokenPlaceholder, regexp.MustCompile(`^%\(\w+\)[sdf]|^%[sdf]`)},
+	{TokenPlaceholder, regexp.MustCompile(`^%\(\w+\)[sdftvaT]|^%[sdftvaT]`)},
+	{TokenIn, regexp.MustCompile(`^in\b`)},
+	{TokenNin, regexp.MustCompile(`^nin\b`)},
 	{TokenIdentifier, regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*`)},
 	{TokenWhitespace, regexp.MustCompile(`^\s+`)},
 }
@@ -165,6 +195,21 @@ func NewLexer(text string) *Lexer {
 
 // Tokenize tokenizes the input text.
 func (l *Lexer) Tokenize() ([]Token, error) {
+	tokens, errs := l.tokenize(false)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return tokens, nil
+}
+
+// TokenizeAll tokenizes the input text skipping unexpected characters,
+// so that all of them are reported at once.
+func (l *Lexer) TokenizeAll() ([]Token, []*JSONPathSyntaxError) {
+	return l.tokenize(true)
+}
+
+func (l *Lexer) tokenize(recovery bool) ([]Token, []*JSONPathSyntaxError) {
+	var errs []*JSONPathSyntaxError
 	for l.position < len(l.text) {
 		matched := false
 		remaining := l.text[l.position:]
@@ -187,16 +232,20 @@ func (l *Lexer) Tokenize() ([]Token, error) {
 		}
 
 		if !matched {
-			return nil, &JSONPathSyntaxError{
+			errs = append(errs, &JSONPathSyntaxError{
 				Message:    fmt.Sprintf("Unexpected character '%c'", l.text[l.position]),
 				Position:   l.position,
 				Expression: l.text,
 				Context:    "expected valid token",
+			})
+			if !recovery {
+				return nil, errs
 			}
+			l.position++
 		}
 	}
 
-	return l.tokens, nil
+	return l.tokens, errs
 }
 
 // parseContext is mutable parsing context passed through parser methods.
@@ -205,6 +254,10 @@ func (l *Lexer) Tokenize() ([]Token, error) {
 type parseContext struct {
 	placeholderBindIndex int
 	isWildcardContext    bool
+	// recovery makes the parser collect the syntax errors of primary expressions into errors
+	// and continue after them instead of stopping at the first one, see ParseDiagnostics.
+	recovery bool
+	errors   []*JSONPathSyntaxError
 }
 
 // placeholderInfo stores information about a placeholder.
@@ -214,6 +267,36 @@ type placeholderInfo struct {
 	Positional bool
 }
 
+// String returns the placeholder as in the template, positional ones with their position, e.g. %(age)d or %d#0.
+func (i placeholderInfo) String() string {
+	if i.Positional {
+		return fmt.Sprintf("%%%s#%s", i.FormatType, i.Name)
+	}
+	return fmt.Sprintf("%%(%s)%s", i.Name, i.FormatType)
+}
+
+// accepts reports whether the value is of the format type, the description of the type is expected.
+func (i placeholderInfo) accepts(value any) (expected string, ok bool) {
+	if value == nil {
+		return "", true
+	}
+	v := reflect.ValueOf(value)
+	switch i.FormatType {
+	case "d":
+		return "integer", v.CanInt() || v.CanUint()
+	case "f":
+		return "float", v.CanFloat()
+	case "t":
+		return "bool", v.Kind() == reflect.Bool
+	case "a":
+		return "slice", v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+	case "T":
+		_, ok := value.(time.Time)
+		return "time.Time", ok
+	}
+	return "", true
+}
+
 // placeholderMarker is a special marker for placeholders.
 type placeholderMarker struct {
 	Index int
@@ -229,6 +312,7 @@ type NativeParametrizedSpecification struct {
 	placeholderInfo []placeholderInfo
 	ast             spec.Visitable // Cached AST, parsed once at initialization
 	isWildcard      bool
+	registry        *operators.OperatorRegistry // Read-only, shared by all Match() calls
 }
 
 // Parse parses RFC 9535 compliant JSONPath expression with C-style placeholders
@@ -236,10 +320,19 @@ type NativeParametrizedSpecification struct {
 //
 // The AST is parsed once and cached for all subsequent Match() calls.
 // This makes the specification thread-safe and efficient for repeated use.
+// Specifications are cached by the template as well, see SetParseCache.
 func Parse(template string) (*NativeParametrizedSpecification, error) {
+	if cache := parseCache.Load(); cache != nil {
+		return cache.Parse(template)
+	}
+	return parse(template)
+}
+
+func parse(template string) (*NativeParametrizedSpecification, error) {
 	p := &NativeParametrizedSpecification{
 		template:        template,
 		placeholderInfo: nil,
+		registry:        operators.NewDefaultRegistry(),
 	}
 	p.extractPlaceholders()
 
@@ -262,6 +355,40 @@ func Parse(template string) (*NativeParametrizedSpecification, error) {
 	return p, nil
 }
 
+// ParseDiagnostics parses the template like Parse, but does not stop at the first syntax error,
+// it recovers and continues to report all errors in the template at once, e.g. for linters and editors.
+// The specification is returned only if there are no errors.
+// Unlike Parse, the result is not cached.
+func ParseDiagnostics(template string) (*NativeParametrizedSpecification, []*JSONPathSyntaxError) {
+	p := &NativeParametrizedSpecification{
+		template:        template,
+		placeholderInfo: nil,
+		registry:        operators.NewDefaultRegistry(),
+	}
+	p.extractPlaceholders()
+
+	lexer := NewLexer(template)
+	tokens, errs := lexer.TokenizeAll()
+
+	ctx := &parseContext{recovery: true, errors: errs}
+	ast, isWildcard, err := p.parsePath(tokens, ctx)
+	var syntaxErr *JSONPathSyntaxError
+	if errors.As(err, &syntaxErr) {
+		ctx.errors = append(ctx.errors, syntaxErr)
+	}
+	if len(ctx.errors) > 0 {
+		slices.SortStableFunc(ctx.errors, func(a, b *JSONPathSyntaxError) int {
+			return a.Position - b.Position
+		})
+		return nil, ctx.errors
+	}
+
+	p.ast = ast
+	p.isWildcard = isWildcard
+
+	return p, nil
+}
+
 // MustParse is like Parse but panics on error.
 func MustParse(template string) *NativeParametrizedSpecification {
 	p, err := Parse(template)
@@ -279,7 +406,7 @@ func (p *NativeParametrizedSpecification) AST() spec.Visitable {
 // extractPlaceholders extracts placeholder information from template.
 func (p *NativeParametrizedSpecification) extractPlaceholders() {
 	// Find named placeholders: %(name)s, %(age)d, %(price)f
-	namedPattern := regexp.MustCompile(`%\((\w+)\)([sdf])`)
+	namedPattern := regexp.MustCompile(`%\((\w+)\)([sdftvaT])`)
 	for _, match := range namedPattern.FindAllStringSubmatch(p.template, -1) {
 		p.placeholderInfo = append(p.placeholderInfo, placeholderInfo{
 			Name:       match[1],
@@ -290,7 +417,7 @@ func (p *NativeParametrizedSpecification) extractPlaceholders() {
 
 	// Find positional placeholders: %s, %d, %f
 	temp := namedPattern.ReplaceAllString(p.template, "")
-	positionalPattern := regexp.MustCompile(`%([sdf])`)
+	positionalPattern := regexp.MustCompile(`%([sdftvaT])`)
 	position := 0
 	for _, match := range positionalPattern.FindAllStringSubmatch(temp, -1) {
 		p.placeholderInfo = append(p.placeholderInfo, placeholderInfo{
@@ -340,6 +467,11 @@ func (p *NativeParametrizedSpecification) parsePrimary(tokens []Token, ctx *pars
 		if i < len(tokens) && tokens[i].Type == TokenRParen {
 			i++
 		}
+	} else if p.isFunctionCall(tokens, i) {
+		node, i, err = p.parseFunction(tokens, ctx, i)
+		if err != nil {
+			return nil, i, err
+		}
 	} else {
 		// Parse left side (field access or nested wildcard)
 		var leftNode spec.Visitable
@@ -348,8 +480,8 @@ func (p *NativeParametrizedSpecification) parsePrimary(tokens []Token, ctx *pars
 			return nil, i, err
 		}
 
-		// Check if leftNode is a CollectionNode (nested wildcard case)
-		if _, ok := leftNode.(spec.CollectionNode); ok {
+		// Check if leftNode is not a field, i.e. a CollectionNode (nested wildcard case) or spec.Every
+		if _, ok := leftNode.(spec.FieldNode); !ok {
 			node = leftNode
 		} else {
 			// Parse operator
@@ -384,20 +516,19 @@ func (p *NativeParametrizedSpecification) parsePrimary(tokens []Token, ctx *pars
 				node = spec.GreaterThanEqual(leftNode, rightNode)
 			case TokenLte:
 				node = spec.LessThanEqual(leftNode, rightNode)
+			case TokenIn:
+				node = spec.In(leftNode, rightNode)
+			case TokenNin:
+				node = spec.NotIn(leftNode, rightNode)
 			default:
 				return nil, i, &JSONPathSyntaxError{
 					Message:    fmt.Sprintf("Unexpected operator '%s'", opToken.Value),
 					Position:   opToken.Position,
 					Expression: p.template,
-					Context:    "expected comparison operator (==, !=, <, >, <=, >=)",
+					Context:    "expected comparison operator (==, !=, <, >, <=, >=, in, nin)",
 				}
 			}
 		}
-
-		// Skip closing parenthesis if present (from earlier opening)
-		if i < len(tokens) && tokens[i].Type == TokenRParen {
-			i++
-		}
 	}
 
 	// Apply NOT if present
@@ -408,12 +539,73 @@ func (p *NativeParametrizedSpecification) parsePrimary(tokens []Token, ctx *pars
 	return node, i, nil
 }
 
+// isFunctionCall checks if tokens at position form a function call, e.g. startsWith(.
+func (p *NativeParametrizedSpecification) isFunctionCall(tokens []Token, start int) bool {
+	return start+1 < len(tokens) &&
+		tokens[start].Type == TokenIdentifier &&
+		tokens[start+1].Type == TokenLParen
+}
+
+// parseFunction parses a function call with comma-separated arguments, each a field access or a value.
+func (p *NativeParametrizedSpecification) parseFunction(tokens []Token, ctx *parseContext, start int) (spec.Visitable, int, error) {
+	nameToken := tokens[start]
+	arity := 2
+	if nameToken.Value == spec.FunctionBetween {
+		arity = 3
+	} else if !spec.IsFunction(nameToken.Value) {
+		return nil, start, &JSONPathSyntaxError{
+			Message:    fmt.Sprintf("Unknown function '%s'", nameToken.Value),
+			Position:   nameToken.Position,
+			Expression: p.template,
+			Context:    "expected function (match, search, startsWith, endsWith, contains, between)",
+		}
+	}
+	i := start + 2
+	var args []spec.Visitable
+	for i >= len(tokens) || tokens[i].Type != TokenRParen {
+		if len(args) > 0 {
+			if i >= len(tokens) || tokens[i].Type != TokenComma {
+				pos := len(p.template)
+				if i < len(tokens) {
+					pos = tokens[i].Position
+				}
+				return nil, i, &JSONPathSyntaxError{
+					Message:    "Expected ',' or ')'",
+					Position:   pos,
+					Expression: p.template,
+					Context:    fmt.Sprintf("in arguments of function '%s'", nameToken.Value),
+				}
+			}
+			i++
+		}
+		var arg spec.Visitable
+		var err error
+		if i < len(tokens) && tokens[i].Type == TokenAt {
+			arg, i, err = p.parseFieldAccess(tokens, ctx, i)
+		} else {
+			arg, i, err = p.parseValue(tokens, ctx, i)
+		}
+		if err != nil {
+			return nil, i, err
+		}
+		args = append(args, arg)
+	}
+	if len(args) != arity {
+		return nil, i, &JSONPathSyntaxError{
+			Message:    fmt.Sprintf("Function '%s' requires %d arguments, got %d", nameToken.Value, arity, len(args)),
+			Position:   nameToken.Position,
+			Expression: p.template,
+		}
+	}
+	return spec.Function(nameToken.Value, args...), i + 1, nil
+}
+
 // parseAndExpression parses AND expressions with left-associativity.
 // AND (&&) has higher precedence than OR (||), so it binds tighter.
 // `a && b && c` becomes `And(And(a, b), c)`.
 func (p *NativeParametrizedSpecification) parseAndExpression(tokens []Token, ctx *parseContext, start int) (spec.Visitable, int, error) {
 	// Parse first primary expression
-	node, i, err := p.parsePrimary(tokens, ctx, start)
+	node, i, err := p.recoverPrimary(tokens, ctx, start)
 	if err != nil {
 		return nil, i, err
 	}
@@ -422,7 +614,7 @@ func (p *NativeParametrizedSpecification) parseAndExpression(tokens []Token, ctx
 	for i < len(tokens) && tokens[i].Type == TokenAnd {
 		i++
 		var rightNode spec.Visitable
-		rightNode, i, err = p.parsePrimary(tokens, ctx, i)
+		rightNode, i, err = p.recoverPrimary(tokens, ctx, i)
 		if err != nil {
 			return nil, i, err
 		}
@@ -432,6 +624,47 @@ func (p *NativeParametrizedSpecification) parseAndExpression(tokens []Token, ctx
 	return node, i, nil
 }
 
+// recoverPrimary parses the primary expression, in the recovery mode its syntax error is collected
+// and the rest of the expression is skipped up to the next "&&", "||" or the enclosing ")" or "]".
+func (p *NativeParametrizedSpecification) recoverPrimary(tokens []Token, ctx *parseContext, start int) (spec.Visitable, int, error) {
+	node, i, err := p.parsePrimary(tokens, ctx, start)
+	var syntaxErr *JSONPathSyntaxError
+	if err == nil || !ctx.recovery || !errors.As(err, &syntaxErr) {
+		return node, i, err
+	}
+	ctx.errors = append(ctx.errors, syntaxErr)
+
+	// Brackets opened by the failed expression before the error are skipped up to their closing ones
+	body := start
+	for _, skipped := range []TokenType{TokenLBracket, TokenQuestion, TokenNot} {
+		if body < len(tokens) && tokens[body].Type == skipped {
+			body++
+		}
+	}
+	depth := 0
+	for j := body; j < i && j < len(tokens); j++ {
+		depth += bracketDepth(tokens[j])
+	}
+	for ; i < len(tokens); i++ {
+		t := tokens[i].Type
+		if depth == 0 && (t == TokenAnd || t == TokenOr || t == TokenRParen || t == TokenRBracket) {
+			break
+		}
+		depth = max(depth+bracketDepth(tokens[i]), 0)
+	}
+	return spec.Value(nil), i, nil
+}
+
+func bracketDepth(token Token) int {
+	switch token.Type {
+	case TokenLParen, TokenLBracket:
+		return 1
+	case TokenRParen, TokenRBracket:
+		return -1
+	}
+	return 0
+}
+
 // parseExpression parses OR expressions with left-associativity (lowest precedence).
 //
 // Operator precedence (highest to lowest):
@@ -468,7 +701,7 @@ func (p *NativeParametrizedSpecification) parseIdentifierChain(tokens []Token, s
 	i := start
 	var chain []string
 
-	for i < len(tokens) && tokens[i].Type == TokenIdentifier {
+	for i < len(tokens) && isName(tokens[i]) {
 		chain = append(chain, tokens[i].Value)
 		i++
 
@@ -476,7 +709,7 @@ func (p *NativeParametrizedSpecification) parseIdentifierChain(tokens []Token, s
 		if i < len(tokens) &&
 			tokens[i].Type == TokenDot &&
 			i+1 < len(tokens) &&
-			tokens[i+1].Type == TokenIdentifier {
+			isName(tokens[i+1]) {
 			i++ // Skip dot, continue to next identifier
 		} else {
 			break
@@ -486,6 +719,11 @@ func (p *NativeParametrizedSpecification) parseIdentifierChain(tokens []Token, s
 	return chain, i
 }
 
+// isName reports whether the token is a field name, keywords are valid field names as well, e.g. @.in.
+func isName(token Token) bool {
+	return token.Type == TokenIdentifier || token.Type == TokenIn || token.Type == TokenNin
+}
+
 // buildObjectChain builds a chain of Object nodes from a list of field names.
 // Example: ["a", "b", "c"] with GlobalScope() parent becomes:
 //
@@ -498,12 +736,48 @@ func (p *NativeParametrizedSpecification) buildObjectChain(parent spec.Emptiable
 	return result
 }
 
-// isWildcardPattern checks if tokens at position form a wildcard pattern [*].
-func (p *NativeParametrizedSpecification) isWildcardPattern(tokens []Token, start int) bool {
-	return start+2 < len(tokens) &&
-		tokens[start].Type == TokenLBracket &&
-		tokens[start+1].Type == TokenWildcard &&
-		tokens[start+2].Type == TokenRBracket
+// parseSelector parses the collection selector at position: the wildcard [*], the universal wildcard [*!],
+// the index [0] or [-1], or the slice [1:3], [1:] or [:-1]. It returns the selector of spec.CollectionNode and the position after it,
+// ok is false if tokens at position are not a selector, e.g. the filter [?...].
+func (p *NativeParametrizedSpecification) parseSelector(tokens []Token, start int) (selector string, next int, ok bool) {
+	i := start
+	if i >= len(tokens) || tokens[i].Type != TokenLBracket {
+		return "", start, false
+	}
+	i++
+	if i+1 < len(tokens) && tokens[i].Type == TokenWildcard && tokens[i+1].Type == TokenRBracket {
+		return spec.WildcardSelector, i + 2, true
+	}
+	if i+2 < len(tokens) && tokens[i].Type == TokenWildcard && tokens[i+1].Type == TokenNot && tokens[i+2].Type == TokenRBracket {
+		return universalSelector, i + 3, true
+	}
+	var parts []string
+	for i < len(tokens) && (isIndex(tokens[i]) || tokens[i].Type == TokenColon) {
+		parts = append(parts, tokens[i].Value)
+		i++
+	}
+	selector = strings.Join(parts, "")
+	if i >= len(tokens) || tokens[i].Type != TokenRBracket || !selectorPattern.MatchString(selector) {
+		return "", start, false
+	}
+	return selector, i + 1, true
+}
+
+// universalSelector is the selector of spec.Every, it is not a selector of spec.CollectionNode.
+const universalSelector = "*!"
+
+// collectionNode creates the node of the predicate of the elements of the collection selected by the selector.
+func (p *NativeParametrizedSpecification) collectionNode(collection spec.EmptiableObject, selector string, predicate spec.Visitable) spec.Visitable {
+	if selector == universalSelector {
+		return spec.Every(collection, predicate)
+	}
+	return spec.NewCollectionNode(collection, selector, predicate)
+}
+
+var selectorPattern = regexp.MustCompile(`^-?\d+$|^(-?\d+)?:(-?\d+)?$`)
+
+func isIndex(token Token) bool {
+	return token.Type == TokenNumber && !strings.Contains(token.Value, ".")
 }
 
 // parseFieldAccess parses field access expression (including nested paths and wildcards).
@@ -549,7 +823,7 @@ func (p *NativeParametrizedSpecification) parseFieldAccess(tokens []Token, ctx *
 		}
 	}
 
-	// Check for nested wildcard on last field: field[*][?...]
+	// Check for nested wildcard on last field: field[*][?...], field[0][?...] or field[1:3][?...]
 	if p.checkNestedWildcard(tokens, i) {
 		// Build parent chain for all fields except the last
 		parent = p.buildObjectChain(parent, fieldChain[:len(fieldChain)-1])
@@ -563,21 +837,19 @@ func (p *NativeParametrizedSpecification) parseFieldAccess(tokens []Token, ctx *
 }
 
 // checkNestedWildcard checks if tokens starting at position indicate a nested wildcard pattern.
-// Pattern: [*][?...]
+// Pattern: [*][?...], or another selector instead of [*]
 func (p *NativeParametrizedSpecification) checkNestedWildcard(tokens []Token, start int) bool {
-	return p.isWildcardPattern(tokens, start) &&
-		start+3 < len(tokens) &&
-		tokens[start+3].Type == TokenLBracket
+	_, i, ok := p.parseSelector(tokens, start)
+	return ok &&
+		i < len(tokens) &&
+		tokens[i].Type == TokenLBracket
 }
 
 // parseNestedWildcard parses nested wildcard pattern: collection[*][?predicate]
 func (p *NativeParametrizedSpecification) parseNestedWildcard(tokens []Token, ctx *parseContext, start int, parent spec.EmptiableObject, collectionName string) (spec.Visitable, int, error) {
-	i := start
-
-	// Skip [*]
-	if p.isWildcardPattern(tokens, i) {
-		i += 3
-	} else {
+	// Skip [*], or the index or the slice
+	selector, i, ok := p.parseSelector(tokens, start)
+	if !ok {
 		pos := len(p.template)
 		if i < len(tokens) {
 			pos = tokens[i].Position
@@ -608,7 +880,7 @@ func (p *NativeParametrizedSpecification) parseNestedWildcard(tokens []Token, ct
 
 		// Create Wildcard node
 		collectionObj := spec.Object(parent, collectionName)
-		return spec.Wildcard(collectionObj, predicate), i, nil
+		return p.collectionNode(collectionObj, selector, predicate), i, nil
 	}
 
 	pos := len(p.template)
@@ -656,6 +928,9 @@ func (p *NativeParametrizedSpecification) parseValue(tokens []Token, ctx *parseC
 		valueNode := p.createPlaceholderValue(ctx)
 		return valueNode, i + 1, nil
 
+	case TokenLBracket:
+		return p.parseList(tokens, ctx, i)
+
 	case TokenIdentifier:
 		switch strings.ToLower(token.Value) {
 		case "true":
@@ -675,6 +950,45 @@ func (p *NativeParametrizedSpecification) parseValue(tokens []Token, ctx *parseC
 	}
 }
 
+// parseList parses a list literal of values, e.g. ['active', %s], the right side of in and nin.
+func (p *NativeParametrizedSpecification) parseList(tokens []Token, ctx *parseContext, start int) (spec.Visitable, int, error) {
+	i := start + 1
+	values := []any{}
+	for i >= len(tokens) || tokens[i].Type != TokenRBracket {
+		if len(values) > 0 {
+			if i >= len(tokens) || tokens[i].Type != TokenComma {
+				pos := len(p.template)
+				if i < len(tokens) {
+					pos = tokens[i].Position
+				}
+				return nil, i, &JSONPathSyntaxError{
+					Message:    "Expected ',' or ']'",
+					Position:   pos,
+					Expression: p.template,
+					Context:    "in list literal",
+				}
+			}
+			i++
+		}
+		valueNode, newI, err := p.parseValue(tokens, ctx, i)
+		if err != nil {
+			return nil, newI, err
+		}
+		value, ok := valueNode.(spec.ValueNode)
+		if !ok {
+			return nil, newI, &JSONPathSyntaxError{
+				Message:    "Nested list literal",
+				Position:   tokens[i].Position,
+				Expression: p.template,
+				Context:    "expected value (number, string, boolean, or placeholder)",
+			}
+		}
+		values = append(values, value.Value())
+		i = newI
+	}
+	return spec.Value(values), i + 1, nil
+}
+
 // createPlaceholderValue creates a placeholder value that will be bound later.
 func (p *NativeParametrizedSpecification) createPlaceholderValue(ctx *parseContext) spec.ValueNode {
 	value := spec.Value(placeholderMarker{Index: ctx.placeholderBindIndex})
@@ -726,15 +1040,15 @@ func (p *NativeParametrizedSpecification) parsePath(tokens []Token, ctx *parseCo
 	parent = p.buildObjectChain(parent, pathChain[:len(pathChain)-1])
 	collectionName := pathChain[len(pathChain)-1]
 
-	// Check for wildcard [*]
-	isWildcard := p.isWildcardPattern(tokens, i)
-	if isWildcard {
-		i += 3
+	// Check for wildcard [*], or the index or the slice
+	selector, next, hasSelector := p.parseSelector(tokens, i)
+	if hasSelector {
+		i = next
 	}
 
 	// Parse filter expression
 	if i < len(tokens) && tokens[i].Type == TokenLBracket {
-		if isWildcard {
+		if hasSelector {
 			ctx.isWildcardContext = true
 			predicate, _, err := p.parseExpression(tokens, ctx, i)
 			if err != nil {
@@ -743,7 +1057,7 @@ func (p *NativeParametrizedSpecification) parsePath(tokens []Token, ctx *parseCo
 			ctx.isWildcardContext = false
 
 			collectionObj := spec.Object(parent, collectionName)
-			return spec.Wildcard(collectionObj, predicate), true, nil
+			return p.collectionNode(collectionObj, selector, predicate), true, nil
 		}
 		ctx.isWildcardContext = false
 		predicate, _, err := p.parseExpression(tokens, ctx, i)
@@ -766,52 +1080,73 @@ func (p *NativeParametrizedSpecification) parsePath(tokens []Token, ctx *parseCo
 }
 
 // bindPlaceholder binds a placeholder to its actual value.
-func (p *NativeParametrizedSpecification) bindPlaceholder(value any, params []any, namedParams map[string]any) any {
+func (p *NativeParametrizedSpecification) bindPlaceholder(value any, params []any, namedParams map[string]any) (any, error) {
 	marker, ok := value.(placeholderMarker)
 	if !ok {
-		return value
+		return value, nil
 	}
-
-	if marker.Index < len(p.placeholderInfo) {
-		phInfo := p.placeholderInfo[marker.Index]
-
-		if phInfo.Positional {
-			paramIdx, _ := strconv.Atoi(phInfo.Name)
-			if paramIdx < len(params) {
-				return params[paramIdx]
-			}
-		} else {
-			if val, ok := namedParams[phInfo.Name]; ok {
-				return val
-			}
+	if marker.Index >= len(p.placeholderInfo) {
+		return nil, &JSONPathError{Message: fmt.Sprintf("unknown placeholder %d", marker.Index)}
+	}
+	phInfo := p.placeholderInfo[marker.Index]
+
+	var bound any
+	var found bool
+	if phInfo.Positional {
+		paramIdx, _ := strconv.Atoi(phInfo.Name)
+		if paramIdx < len(params) {
+			bound, found = params[paramIdx], true
 		}
+	} else {
+		bound, found = namedParams[phInfo.Name]
 	}
-
-	return value
+	if !found {
+		return nil, &JSONPathError{Message: fmt.Sprintf("missing value of placeholder %s", phInfo)}
+	}
+	if expected, ok := phInfo.accepts(bound); !ok {
+		return nil, &JSONPathTypeError{
+			Message:  fmt.Sprintf("placeholder %s", phInfo),
+			Expected: expected,
+			Got:      fmt.Sprintf("%T", bound),
+		}
+	}
+	return bound, nil
 }
 
-// bindValuesInAST recursively binds placeholder values in the AST.
-func (p *NativeParametrizedSpecification) bindValuesInAST(node spec.Visitable, params []any, namedParams map[string]any) spec.Visitable {
-	switch n := node.(type) {
-	case spec.ValueNode:
-		boundValue := p.bindPlaceholder(n.Value(), params, namedParams)
-		return spec.Value(boundValue)
-
-	case spec.InfixNode:
-		left := p.bindValuesInAST(n.Left(), params, namedParams)
-		right := p.bindValuesInAST(n.Right(), params, namedParams)
-		return spec.NewInfixNode(left, n.Operator(), right, n.Associativity())
-
-	case spec.PrefixNode:
-		operand := p.bindValuesInAST(n.Operand(), params, namedParams)
-		return spec.NewPrefixNode(n.Operator(), operand, n.Associativity())
-
-	case spec.CollectionNode:
-		predicate := p.bindValuesInAST(n.Predicate(), params, namedParams)
-		return spec.Wildcard(n.Parent(), predicate)
+// bindSlots binds the parameters to the slots of all the placeholders, indexed as placeholderMarker.
+func (p *NativeParametrizedSpecification) bindSlots(params []any, namedParams map[string]any) ([]any, error) {
+	slots := make([]any, len(p.placeholderInfo))
+	for i := range slots {
+		value, err := p.bindPlaceholder(placeholderMarker{Index: i}, params, namedParams)
+		if err != nil {
+			return nil, err
+		}
+		slots[i] = value
+	}
+	return slots, nil
+}
 
-	default:
-		return node
+// slotBinder binds placeholders of the cached AST to the slots when it is evaluated, see spec.EvaluateVisitor.SetBinder.
+func slotBinder(slots []any) func(value any) (any, error) {
+	return func(value any) (any, error) {
+		switch v := value.(type) {
+		case placeholderMarker:
+			return slots[v.Index], nil
+		case []any:
+			var bound []any
+			for i, element := range v {
+				if marker, ok := element.(placeholderMarker); ok {
+					if bound == nil {
+						bound = slices.Clone(v)
+					}
+					bound[i] = slots[marker.Index]
+				}
+			}
+			if bound != nil {
+				return bound, nil
+			}
+		}
+		return value, nil
 	}
 }
 
@@ -827,12 +1162,16 @@ func (p *NativeParametrizedSpecification) MatchNamed(data spec.Context, namedPar
 
 // matchInternal is the internal implementation of Match and MatchNamed.
 func (p *NativeParametrizedSpecification) matchInternal(data spec.Context, params []any, namedParams map[string]any) (bool, error) {
-	// Bind placeholder values to cached AST
-	boundAST := p.bindValuesInAST(p.ast, params, namedParams)
+	// Bind placeholder values to the slots of this call, the cached AST is shared and never copied
+	slots, err := p.bindSlots(params, namedParams)
+	if err != nil {
+		return false, err
+	}
 
 	// Evaluate using EvaluateVisitor
-	visitor := spec.NewEvaluateVisitor(data, operators.NewDefaultRegistry())
-	err := boundAST.Accept(visitor)
+	visitor := spec.NewEvaluateVisitor(data, p.registry)
+	visitor.SetBinder(slotBinder(slots))
+	err = p.ast.Accept(visitor)
 	if err != nil {
 		return false, err
 	}
@@ -854,7 +1193,7 @@ func NewDictContext(data map[string]any) *DictContext {
 func (c *DictContext) Get(key string) (any, error) {
 	value, ok := c.data[key]
 	if !ok {
-		return nil, fmt.Errorf("key '%s' not found", key)
+		return nil, fmt.Errorf("%w: %s", spec.ErrKeyNotFound, key)
 	}
 	return value, nil
 }
@@ -873,7 +1212,7 @@ func NewNestedDictContext(data map[string]any) *NestedDictContext {
 func (c *NestedDictContext) Get(key string) (any, error) {
 	value, ok := c.data[key]
 	if !ok {
-		return nil, fmt.Errorf("key '%s' not found", key)
+		return nil, fmt.Errorf("%w: %s", spec.ErrKeyNotFound, key)
 	}
 
 	if m, ok := value.(map[string]any); ok {
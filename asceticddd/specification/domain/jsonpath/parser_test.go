@@ -1,8 +1,12 @@
 package jsonpath
 
 import (
+	"errors"
+	"reflect"
+	"slices"
 	"sync"
 	"testing"
+	"time"
 
 	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
 	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
@@ -391,6 +395,20 @@ func TestNativeParser_LogicalOrOperator(t *testing.T) {
 	}
 }
 
+func TestNativeParser_ParenthesizedGroup(t *testing.T) {
+	// The closing parenthesis of the group is not consumed by the last comparison in it
+	s := MustParse("$[?(@.role == 'admin' || @.role == 'owner') && @.active == true]")
+	guest := NewDictContext(map[string]any{"role": "admin", "active": false})
+
+	result, err := s.Match(guest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result {
+		t.Error("expected false for inactive admin, got true")
+	}
+}
+
 func TestNativeParser_LogicalNotOperator(t *testing.T) {
 	s := MustParse("$[?!(@.active == %s)]")
 	userActive := NewDictContext(map[string]any{"active": true})
@@ -1538,25 +1556,41 @@ func TestHelperMethods_ParseIdentifierChainMultiple(t *testing.T) {
 	}
 }
 
-func TestHelperMethods_IsWildcardPatternTrue(t *testing.T) {
+func TestHelperMethods_ParseSelectorWildcard(t *testing.T) {
 	// Test wildcard pattern detection - positive case
 	s := MustParse("$[?@.x > 1]")
 	lexer := NewLexer("[*]")
 	tokens, _ := lexer.Tokenize()
 
-	if !s.isWildcardPattern(tokens, 0) {
-		t.Error("expected isWildcardPattern to return true for [*]")
+	selector, next, ok := s.parseSelector(tokens, 0)
+	if !ok || selector != spec.WildcardSelector || next != 3 {
+		t.Errorf("expected parseSelector to return [*], got %q, %d, %v", selector, next, ok)
 	}
 }
 
-func TestHelperMethods_IsWildcardPatternFalse(t *testing.T) {
+func TestHelperMethods_ParseSelectorFilter(t *testing.T) {
 	// Test wildcard pattern detection - negative case
 	s := MustParse("$[?@.x > 1]")
-	lexer := NewLexer("[?@.x]")
-	tokens, _ := lexer.Tokenize()
+	for _, template := range []string{"[?@.x]", "[1.5]", "[1:2:3]", "[]"} {
+		lexer := NewLexer(template)
+		tokens, _ := lexer.Tokenize()
 
-	if s.isWildcardPattern(tokens, 0) {
-		t.Error("expected isWildcardPattern to return false for [?@.x]")
+		if _, _, ok := s.parseSelector(tokens, 0); ok {
+			t.Errorf("expected parseSelector to return false for %s", template)
+		}
+	}
+}
+
+func TestHelperMethods_ParseSelectorIndexAndSlice(t *testing.T) {
+	s := MustParse("$[?@.x > 1]")
+	for template, expected := range map[string]string{"[0]": "0", "[-1]": "-1", "[1:3]": "1:3", "[:-1]": ":-1", "[2:]": "2:"} {
+		lexer := NewLexer(template)
+		tokens, _ := lexer.Tokenize()
+
+		selector, _, ok := s.parseSelector(tokens, 0)
+		if !ok || selector != expected {
+			t.Errorf("expected parseSelector to return %s for %s, got %q", expected, template, selector)
+		}
 	}
 }
 
@@ -1615,3 +1649,339 @@ func TestHelperMethods_BuildObjectChainMultiple(t *testing.T) {
 		t.Errorf("expected depth 3, got %d", depth)
 	}
 }
+
+func TestNativeParser_InOperator(t *testing.T) {
+	s := MustParse("$[?@.status in %s]")
+	user := NewDictContext(map[string]any{"status": "active"})
+
+	result, err := s.Match(user, []string{"active", "pending"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result {
+		t.Error("expected true, got false")
+	}
+
+	result, err = s.Match(user, []string{"closed"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result {
+		t.Error("expected false, got true")
+	}
+
+	infix, ok := s.AST().(spec.InfixNode)
+	if !ok || infix.Operator() != operators.OperatorIn {
+		t.Errorf("expected IN node, got %#v", s.AST())
+	}
+}
+
+func TestNativeParser_NinOperatorWithListLiteral(t *testing.T) {
+	s := MustParse("$[?@.status nin ['closed', %(status)s] && @.in > 0]")
+
+	for _, tc := range []struct {
+		status   string
+		expected bool
+	}{
+		{"active", true},
+		{"closed", false},
+		{"archived", false},
+	} {
+		user := NewDictContext(map[string]any{"status": tc.status, "in": 1})
+		result, err := s.MatchNamed(user, map[string]any{"status": "archived"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result != tc.expected {
+			t.Errorf("status %s: expected %v, got %v", tc.status, tc.expected, result)
+		}
+	}
+}
+
+func TestNativeParser_InOperatorNullSemantics(t *testing.T) {
+	s := MustParse("$[?@.age in [1, null]]")
+
+	result, err := s.Match(NewDictContext(map[string]any{"age": 1}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result {
+		t.Error("expected true, got false")
+	}
+
+	_, err = s.Match(NewDictContext(map[string]any{"age": 2}))
+	if err == nil {
+		t.Error("expected error for NULL result")
+	}
+}
+
+func TestErrorMessages_UnterminatedListLiteral(t *testing.T) {
+	_, err := Parse("$[?@.status in ['a' 'b']]")
+	if _, ok := err.(*JSONPathSyntaxError); !ok {
+		t.Fatalf("expected JSONPathSyntaxError, got %v", err)
+	}
+}
+
+func TestNativeParser_StringFunctions(t *testing.T) {
+	user := NewDictContext(map[string]any{"name": "John.Smith", "code": "A42", "age": 30})
+
+	for _, tc := range []struct {
+		template string
+		expected bool
+	}{
+		{"$[?startsWith(@.name, 'John')]", true},
+		{"$[?startsWith(@.name, 'Smith')]", false},
+		{"$[?endsWith(@.name, 'Smith')]", true},
+		{"$[?contains(@.name, '.')]", true},
+		{"$[?contains(@.name, 'x')]", false},
+		{"$[?match(@.code, 'A[0-9]+')]", true},
+		{"$[?match(@.code, '[0-9]+')]", false},
+		{"$[?search(@.code, '[0-9]+')]", true},
+		{"$[?!startsWith(@.name, 'Jane') && @.age > 18]", true},
+		{"$[?(contains(@.name, 'x') || endsWith(@.name, 'th'))]", true},
+		{"$[?startsWith(@.age, '3')]", false},
+	} {
+		result, err := MustParse(tc.template).Match(user)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.template, err)
+		}
+		if result != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.template, tc.expected, result)
+		}
+	}
+}
+
+func TestNativeParser_StringFunctionWithPlaceholder(t *testing.T) {
+	s := MustParse("$[?startsWith(@.name, %s)]")
+	user := NewDictContext(map[string]any{"name": "John"})
+
+	result, err := s.Match(user, "Jo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result {
+		t.Error("expected true, got false")
+	}
+
+	fn, ok := s.AST().(spec.FunctionNode)
+	if !ok || fn.Name() != spec.FunctionStartsWith || len(fn.Args()) != 2 {
+		t.Errorf("expected startsWith node, got %#v", s.AST())
+	}
+}
+
+func TestNativeParser_BetweenWithPlaceholders(t *testing.T) {
+	s := MustParse("$[?between(@.age, %d, %d) && @.name != 'John']")
+
+	for _, tc := range []struct {
+		user     map[string]any
+		expected bool
+	}{
+		{map[string]any{"age": 18, "name": "Jane"}, true},
+		{map[string]any{"age": 65, "name": "Jane"}, true},
+		{map[string]any{"age": 66, "name": "Jane"}, false},
+		{map[string]any{"age": 30, "name": "John"}, false},
+	} {
+		result, err := s.Match(NewDictContext(tc.user), 18, 65)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", tc.user, err)
+		}
+		if result != tc.expected {
+			t.Errorf("%v: expected %v, got %v", tc.user, tc.expected, result)
+		}
+	}
+}
+
+func TestNativeParser_StringFunctionErrors(t *testing.T) {
+	for _, template := range []string{
+		"$[?lower(@.name, 'john')]",
+		"$[?startsWith(@.name)]",
+		"$[?between(@.age, 18)]",
+		"$[?startsWith(@.name 'Jo')]",
+	} {
+		_, err := Parse(template)
+		var syntaxErr *JSONPathSyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%s: expected JSONPathSyntaxError, got %v", template, err)
+		}
+	}
+
+	_, err := MustParse("$[?match(@.name, '(')]").Match(NewDictContext(map[string]any{"name": "John"}))
+	if err == nil {
+		t.Error("expected error of invalid regular expression")
+	}
+}
+
+func TestNativeParser_IndexAndSliceSelectors(t *testing.T) {
+	collection := spec.NewCollectionContext([]spec.Context{
+		NewDictContext(map[string]any{"score": 90}),
+		NewDictContext(map[string]any{"score": 75}),
+		NewDictContext(map[string]any{"score": 60}),
+	})
+	root := NewDictContext(map[string]any{"items": collection})
+
+	for _, tc := range []struct {
+		template string
+		expected bool
+	}{
+		{"$.items[0][?@.score > %d]", true},
+		{"$.items[1][?@.score > %d]", false},
+		{"$.items[-1][?@.score < %d]", true},
+		{"$.items[1:3][?@.score > %d]", false},
+		{"$.items[:1][?@.score > %d]", true},
+		{"$.items[-2:][?@.score < %d]", true},
+		{"$[?@.items[0][?@.score > %d]]", true},
+	} {
+		s := MustParse(tc.template)
+		result, err := s.Match(root, 80)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.template, err)
+		}
+		if result != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.template, tc.expected, result)
+		}
+	}
+
+	collectionNode, ok := MustParse("$.items[1:3][?@.score > %d]").AST().(spec.CollectionNode)
+	if !ok || collectionNode.Name() != "1:3" || collectionNode.IsWildcard() {
+		t.Errorf("expected slice node, got %#v", collectionNode)
+	}
+}
+
+func TestNativeParser_UniversalWildcard(t *testing.T) {
+	for _, tc := range []struct {
+		scores   []int
+		expected bool
+	}{
+		{[]int{90, 85}, true},
+		{[]int{90, 75}, false},
+		{nil, true},
+	} {
+		var items []spec.Context
+		for _, score := range tc.scores {
+			items = append(items, NewDictContext(map[string]any{"score": score}))
+		}
+		root := NewDictContext(map[string]any{"items": spec.NewCollectionContext(items)})
+
+		for _, template := range []string{"$.items[*!][?@.score > %d]", "$[?@.items[*!][?@.score > %d]]"} {
+			result, err := MustParse(template).Match(root, 80)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", template, err)
+			}
+			if result != tc.expected {
+				t.Errorf("%s of %v: expected %v, got %v", template, tc.scores, tc.expected, result)
+			}
+		}
+	}
+
+	expected := spec.Every(
+		spec.Object(spec.GlobalScope(), "items"),
+		spec.GreaterThan(spec.Field(spec.Item(), "score"), spec.Value(placeholderMarker{Index: 0})),
+	)
+	s := MustParse("$.items[*!][?@.score > %d]")
+	if !reflect.DeepEqual(s.AST(), expected) {
+		t.Errorf("expected %#v, got %#v", expected, s.AST())
+	}
+}
+
+func TestNativeParser_TypedPlaceholders(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	user := NewDictContext(map[string]any{
+		"active": true, "status": "active", "created": created, "age": 30, "score": 9.5,
+	})
+	s := MustParse("$[?@.active == %t && @.status in %a && @.created < %T && @.age > %d && @.score > %f && @.status == %v]")
+
+	result, err := s.Match(user, true, []string{"active"}, created.Add(time.Hour), 18, 5.0, "active")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result {
+		t.Error("expected true, got false")
+	}
+
+	named := MustParse("$[?@.created >= %(since)T && @.status nin %(statuses)a]")
+	result, err = named.MatchNamed(user, map[string]any{"since": created, "statuses": []any{"closed"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result {
+		t.Error("expected true, got false")
+	}
+}
+
+func TestNativeParser_PlaceholderTypeMismatch(t *testing.T) {
+	user := NewDictContext(map[string]any{"active": true, "status": "active", "created": time.Now(), "age": 30})
+
+	for _, tc := range []struct {
+		template string
+		param    any
+		message  string
+	}{
+		{"$[?@.active == %t]", "true", "placeholder %t#0: expected bool, got string"},
+		{"$[?@.status in %a]", "active", "placeholder %a#0: expected slice, got string"},
+		{"$[?@.created < %T]", "2024-01-01", "placeholder %T#0: expected time.Time, got string"},
+		{"$[?@.age > %d]", 18.5, "placeholder %d#0: expected integer, got float64"},
+		{"$[?@.age > %f]", 18, "placeholder %f#0: expected float, got int"},
+	} {
+		_, err := MustParse(tc.template).Match(user, tc.param)
+		var typeErr *JSONPathTypeError
+		if !errors.As(err, &typeErr) {
+			t.Fatalf("%s: expected JSONPathTypeError, got %v", tc.template, err)
+		}
+		if err.Error() != tc.message {
+			t.Errorf("%s: expected %q, got %q", tc.template, tc.message, err.Error())
+		}
+	}
+
+	_, err := MustParse("$[?@.age > %(min_age)d]").MatchNamed(user, map[string]any{"min_age": "18"})
+	if err == nil || err.Error() != "placeholder %(min_age)d: expected integer, got string" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	_, err = MustParse("$[?@.age > %d && @.status == %s]").Match(user, 18)
+	var pathErr *JSONPathError
+	if !errors.As(err, &pathErr) {
+		t.Errorf("expected JSONPathError of missing value, got %v", err)
+	}
+
+	_, err = MustParse("$[?@.age > %d]").MatchNamed(user, nil)
+	if !errors.As(err, &pathErr) {
+		t.Errorf("expected JSONPathError of missing value, got %v", err)
+	}
+}
+
+func TestNativeParser_ParseDiagnostics(t *testing.T) {
+	for _, tc := range []struct {
+		template  string
+		positions []int
+	}{
+		{"$[?@.age > && @.name == ]", []int{11, 24}},
+		{"$[?@.age > 18 || @.name ==]", []int{26}},
+		{"$[?(@.age > ) && lower(@.name, 'jo') && @.active == true]", []int{12, 17}},
+		{"$[?@.age > 18 # && @.name == ]", []int{14, 29}},
+		{"$.items[*][?@.price > && @.qty >]", []int{22, 32}},
+	} {
+		_, errs := ParseDiagnostics(tc.template)
+		positions := make([]int, 0, len(errs))
+		for _, err := range errs {
+			positions = append(positions, err.Position)
+		}
+		if !slices.Equal(positions, tc.positions) {
+			t.Errorf("%s: expected errors at %v, got %v", tc.template, tc.positions, errs)
+		}
+	}
+
+	p, errs := ParseDiagnostics("$[?@.age > %d && @.name == 'John']")
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	result, err := p.Match(NewDictContext(map[string]any{"age": 30, "name": "John"}), 18)
+	if err != nil || !result {
+		t.Errorf("expected match, got %v, %v", result, err)
+	}
+
+	_, err = Parse("$[?@.age > && @.name == ]")
+	var syntaxErr *JSONPathSyntaxError
+	if !errors.As(err, &syntaxErr) || syntaxErr.Position != 11 {
+		t.Errorf("expected the first syntax error only, got %v", err)
+	}
+}
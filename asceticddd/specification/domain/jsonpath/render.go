@@ -0,0 +1,291 @@
+package jsonpath
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+// Render renders AST as the template of Parse, e.g. to display a stored rule in the syntax of the rule editor:
+//
+//	$[?@.age >= 18 && @.items[*][?@.price > 100]]
+//
+// The values without literals in the template, e.g. time.Time, are rendered as the positional placeholders
+// of the returned params, so Parse(template).Match(data, params...) is equivalent to AST.
+// A bare field is rendered as the comparison with true, Every as the universal wildcard [*!],
+// and the extension node as its fallback.
+// AST beyond the template syntax is JSONPathError, e.g. arithmetic or the comparison of two fields.
+func Render(ast spec.Visitable) (template string, params []any, err error) {
+	r := &templateRenderer{}
+	expression, err := r.render(ast, precedenceOr)
+	if err != nil {
+		return "", nil, err
+	}
+	return "$[?" + expression + "]", r.params, nil
+}
+
+// The precedences of the template expressions, the higher one binds tighter.
+const (
+	precedenceOr = iota + 1
+	precedenceAnd
+)
+
+var comparisonTokens = map[operators.Operator]string{
+	operators.OperatorEq:    "==",
+	operators.OperatorNe:    "!=",
+	operators.OperatorGt:    ">",
+	operators.OperatorLt:    "<",
+	operators.OperatorGte:   ">=",
+	operators.OperatorLte:   "<=",
+	operators.OperatorIn:    "in",
+	operators.OperatorNotIn: "nin",
+}
+
+// mirroredComparisons are the comparisons of the swapped operands, e.g. 18 < @.age is @.age > 18.
+var mirroredComparisons = map[operators.Operator]operators.Operator{
+	operators.OperatorEq:  operators.OperatorEq,
+	operators.OperatorNe:  operators.OperatorNe,
+	operators.OperatorGt:  operators.OperatorLt,
+	operators.OperatorLt:  operators.OperatorGt,
+	operators.OperatorGte: operators.OperatorLte,
+	operators.OperatorLte: operators.OperatorGte,
+}
+
+type templateRenderer struct {
+	params []any
+	// wildcard is true in the predicate of the collection, where @ is the item
+	wildcard bool
+}
+
+// render renders the expression, the expression of lower precedence than the least one is parenthesized.
+func (r *templateRenderer) render(n spec.Visitable, least int) (string, error) {
+	switch node := n.(type) {
+	case spec.InfixNode:
+		switch node.Operator() {
+		case operators.OperatorAnd:
+			return r.renderLogical(node, "&&", precedenceAnd, least)
+		case operators.OperatorOr:
+			return r.renderLogical(node, "||", precedenceOr, least)
+		}
+		return r.renderComparison(node)
+	case spec.PrefixNode:
+		if node.Operator() != operators.OperatorNot {
+			break
+		}
+		if collection, predicate, ok := universal(node); ok {
+			return r.renderCollection(collection, "*!", predicate)
+		}
+		operand, err := r.render(node.Operand(), precedenceOr)
+		if err != nil {
+			return "", err
+		}
+		return "!(" + operand + ")", nil
+	case spec.CollectionNode:
+		return r.renderCollection(node.Parent(), node.Name(), node.Predicate())
+	case spec.FieldNode:
+		path, err := r.renderField(node)
+		if err != nil {
+			return "", err
+		}
+		return path + " == true", nil
+	case spec.FunctionNode:
+		return r.renderFunction(node)
+	case spec.ExtensionNode:
+		if fallback := node.Fallback(); fallback != nil {
+			return r.render(fallback, least)
+		}
+	}
+	return "", unsupported(n)
+}
+
+func (r *templateRenderer) renderLogical(n spec.InfixNode, token string, precedence, least int) (string, error) {
+	left, err := r.render(n.Left(), precedence)
+	if err != nil {
+		return "", err
+	}
+	right, err := r.render(n.Right(), precedence+1)
+	if err != nil {
+		return "", err
+	}
+	expression := left + " " + token + " " + right
+	if precedence < least {
+		expression = "(" + expression + ")"
+	}
+	return expression, nil
+}
+
+func (r *templateRenderer) renderComparison(n spec.InfixNode) (string, error) {
+	op := n.Operator()
+	left, right := n.Left(), n.Right()
+	if _, ok := left.(spec.FieldNode); !ok {
+		if mirrored, ok := mirroredComparisons[op]; ok {
+			op, left, right = mirrored, right, left
+		}
+	}
+	token, ok := comparisonTokens[op]
+	field, isField := left.(spec.FieldNode)
+	value, isValue := right.(spec.ValueNode)
+	if !ok || !isField || !isValue {
+		return "", unsupported(n)
+	}
+	path, err := r.renderField(field)
+	if err != nil {
+		return "", err
+	}
+	return path + " " + token + " " + r.renderValue(value.Value()), nil
+}
+
+// universal returns the collection and the predicate of Every, which is NOT of the wildcard of NOT.
+func universal(n spec.PrefixNode) (spec.EmptiableObject, spec.Visitable, bool) {
+	collection, ok := n.Operand().(spec.CollectionNode)
+	if !ok || !collection.IsWildcard() {
+		return nil, nil, false
+	}
+	predicate, ok := collection.Predicate().(spec.PrefixNode)
+	if !ok || predicate.Operator() != operators.OperatorNot {
+		return nil, nil, false
+	}
+	return collection.Parent(), predicate.Operand(), true
+}
+
+func (r *templateRenderer) renderCollection(collection spec.EmptiableObject, selector string, predicate spec.Visitable) (string, error) {
+	path, err := r.renderPath(collection)
+	if err != nil {
+		return "", err
+	}
+	outer := r.wildcard
+	r.wildcard = true
+	expression, err := r.render(predicate, precedenceOr)
+	r.wildcard = outer
+	if err != nil {
+		return "", err
+	}
+	return path + "[" + selector + "][?" + expression + "]", nil
+}
+
+func (r *templateRenderer) renderFunction(n spec.FunctionNode) (string, error) {
+	if n.Name() != spec.FunctionBetween && !spec.IsFunction(n.Name()) {
+		return "", unsupported(n)
+	}
+	args := make([]string, 0, len(n.Args()))
+	for _, arg := range n.Args() {
+		switch node := arg.(type) {
+		case spec.FieldNode:
+			path, err := r.renderField(node)
+			if err != nil {
+				return "", err
+			}
+			args = append(args, path)
+		case spec.ValueNode:
+			args = append(args, r.renderValue(node.Value()))
+		default:
+			return "", unsupported(arg)
+		}
+	}
+	return n.Name() + "(" + strings.Join(args, ", ") + ")", nil
+}
+
+func (r *templateRenderer) renderField(n spec.FieldNode) (string, error) {
+	path, err := r.renderPath(n.Object())
+	if err != nil {
+		return "", err
+	}
+	return path + "." + n.Name(), nil
+}
+
+// renderPath renders the path of the object from @, which is the item in the predicate of the collection
+// and the global scope otherwise, so the fields of the other one are not supported.
+func (r *templateRenderer) renderPath(obj spec.EmptiableObject) (string, error) {
+	var names []string
+	for ; !obj.IsRoot(); obj = obj.Parent() {
+		names = append([]string{obj.Name()}, names...)
+	}
+	_, isItem := obj.(spec.ItemNode)
+	if isItem && !r.wildcard {
+		return "", &JSONPathError{Message: "item outside of the collection predicate is not supported by the template"}
+	}
+	if !isItem && r.wildcard {
+		return "", &JSONPathError{Message: fmt.Sprintf(
+			"%s of the global scope in the collection predicate is not supported by the template", strings.Join(names, "."),
+		)}
+	}
+	return strings.Join(append([]string{"@"}, names...), "."), nil
+}
+
+// renderValue renders the literal of the value, or the placeholder of the format type of the value.
+func (r *templateRenderer) renderValue(value any) string {
+	if text, ok := literal(value); ok {
+		return text
+	}
+	r.params = append(r.params, value)
+	if _, ok := value.(time.Time); ok {
+		return "%T"
+	}
+	v := reflect.ValueOf(value)
+	switch {
+	case v.CanInt() || v.CanUint():
+		return "%d"
+	case v.CanFloat():
+		return "%f"
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		return "%a"
+	}
+	return "%v"
+}
+
+// literal returns the literal of the value which is parsed to the equal value, see parseValue.
+func literal(value any) (string, bool) {
+	switch typed := value.(type) {
+	case nil:
+		return "null", true
+	case bool:
+		return strconv.FormatBool(typed), true
+	case int:
+		return strconv.Itoa(typed), true
+	case float64:
+		if math.IsInf(typed, 0) || math.IsNaN(typed) {
+			return "", false
+		}
+		text := strconv.FormatFloat(typed, 'f', -1, 64)
+		if !strings.Contains(text, ".") {
+			text += ".0"
+		}
+		return text, true
+	case string:
+		// The string literals have no escapes
+		if !strings.Contains(typed, "'") {
+			return "'" + typed + "'", true
+		}
+		if !strings.Contains(typed, `"`) {
+			return `"` + typed + `"`, true
+		}
+	case []any:
+		items := make([]string, 0, len(typed))
+		for _, item := range typed {
+			if _, nested := item.([]any); nested {
+				return "", false
+			}
+			text, ok := literal(item)
+			if !ok {
+				return "", false
+			}
+			items = append(items, text)
+		}
+		return "[" + strings.Join(items, ", ") + "]", true
+	}
+	return "", false
+}
+
+func unsupported(n spec.Visitable) error {
+	text, err := spec.ToInfix(n)
+	if err != nil {
+		text = fmt.Sprintf("%T", n)
+	}
+	return &JSONPathError{Message: fmt.Sprintf("%s is not supported by the template", text)}
+}
@@ -0,0 +1,130 @@
+package jsonpath
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+)
+
+func TestRender(t *testing.T) {
+	root := spec.GlobalScope()
+	age := spec.Field(root, "age")
+	items := spec.Object(root, "items")
+
+	for _, tc := range []struct {
+		name     string
+		ast      spec.Visitable
+		expected string
+		// parsed is AST of the template, if it differs from the rendered one
+		parsed spec.Visitable
+	}{
+		{"comparison", spec.GreaterThanEqual(age, spec.Value(18)), `$[?@.age >= 18]`, nil},
+		{"logical", spec.And(spec.Or(spec.Equal(spec.Field(root, "role"), spec.Value("admin")),
+			spec.Equal(spec.Field(root, "role"), spec.Value("owner"))), spec.Not(spec.LessThan(age, spec.Value(18)))),
+			`$[?(@.role == 'admin' || @.role == 'owner') && !(@.age < 18)]`, nil},
+		{"mirrored", spec.LessThan(spec.Value(1.5), spec.Field(spec.Object(root, "user"), "score")), `$[?@.user.score > 1.5]`,
+			spec.GreaterThan(spec.Field(spec.Object(root, "user"), "score"), spec.Value(1.5))},
+		{"membership", spec.NotIn(spec.Field(root, "status"), spec.Value([]any{"closed", "it's", nil})),
+			`$[?@.status nin ['closed', "it's", null]]`, nil},
+		{"bare field", spec.And(spec.Field(root, "active"), spec.Function(spec.FunctionStartsWith, spec.Field(root, "name"), spec.Value("Jo"))),
+			`$[?@.active == true && startsWith(@.name, 'Jo')]`,
+			spec.And(spec.Equal(spec.Field(root, "active"), spec.Value(true)),
+				spec.Function(spec.FunctionStartsWith, spec.Field(root, "name"), spec.Value("Jo")))},
+		{"collection", spec.Wildcard(items, spec.And(spec.GreaterThan(spec.Field(spec.Item(), "price"), spec.Value(100.0)),
+			spec.Index(spec.Object(spec.Item(), "tags"), -1, spec.Equal(spec.Field(spec.Item(), "name"), spec.Value("sale"))))),
+			`$[?@.items[*][?@.price > 100.0 && @.tags[-1][?@.name == 'sale']]]`, nil},
+		{"every", spec.Every(items, spec.Field(spec.Item(), "paid")), `$[?@.items[*!][?@.paid == true]]`,
+			spec.Every(items, spec.Equal(spec.Field(spec.Item(), "paid"), spec.Value(true)))},
+	} {
+		template, params, err := Render(tc.ast)
+		if err != nil {
+			t.Fatalf("%s: Render failed: %v", tc.name, err)
+		}
+		if template != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.expected, template)
+		}
+		if len(params) != 0 {
+			t.Errorf("%s: expected no params, got %v", tc.name, params)
+		}
+		parsed, err := Parse(template)
+		if err != nil {
+			t.Fatalf("%s: Parse of %s failed: %v", tc.name, template, err)
+		}
+		expected := tc.parsed
+		if expected == nil {
+			expected = tc.ast
+		}
+		if !reflect.DeepEqual(parsed.AST(), expected) {
+			t.Errorf("%s: expected %#v, got %#v", tc.name, expected, parsed.AST())
+		}
+	}
+}
+
+func TestRenderPlaceholders(t *testing.T) {
+	root := spec.GlobalScope()
+	since := time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC)
+	ast := spec.And(
+		spec.GreaterThanEqual(spec.Field(root, "created_at"), spec.Value(since)),
+		spec.In(spec.Field(root, "level"), spec.Value([]int64{1, 2})),
+		spec.Equal(spec.Field(root, "quote"), spec.Value(`'"`)),
+	)
+
+	template, params, err := Render(ast)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `$[?@.created_at >= %T && @.level in %a && @.quote == %v]`
+	if template != expected {
+		t.Errorf("Expected %s, got %s", expected, template)
+	}
+	if !reflect.DeepEqual(params, []any{since, []int64{1, 2}, `'"`}) {
+		t.Errorf("Unexpected params %v", params)
+	}
+
+	data := NewDictContext(map[string]any{"created_at": since.Add(time.Hour), "level": int64(2), "quote": `'"`})
+	result, err := MustParse(template).Match(data, params...)
+	if err != nil {
+		t.Fatalf("Match failed: %v", err)
+	}
+	if !result {
+		t.Error("Expected the rendered template to match")
+	}
+}
+
+func TestRenderUnsupported(t *testing.T) {
+	root := spec.GlobalScope()
+	for _, ast := range []spec.Visitable{
+		spec.GreaterThan(spec.Add(spec.Field(root, "a"), spec.Value(1)), spec.Value(2)),
+		spec.Equal(spec.Field(root, "a"), spec.Field(root, "b")),
+		spec.LessThan(spec.Field(root, "created_at"), spec.Now()),
+		spec.Wildcard(spec.Object(root, "items"), spec.Equal(spec.Field(spec.Item(), "owner"), spec.Field(root, "user"))),
+		spec.Equal(spec.Field(spec.Item(), "price"), spec.Value(1)),
+		spec.Ref("AdultUser"),
+	} {
+		if _, _, err := Render(ast); err == nil {
+			t.Errorf("Expected error for %#v", ast)
+		}
+	}
+}
+
+type adultNode struct{}
+
+func (n adultNode) Accept(v spec.Visitor) error {
+	return spec.AcceptExtension(n, v)
+}
+
+func (n adultNode) Fallback() spec.Visitable {
+	return spec.GreaterThanEqual(spec.Field(spec.GlobalScope(), "age"), spec.Value(18))
+}
+
+func TestRenderExtension(t *testing.T) {
+	template, _, err := Render(spec.And(adultNode{}, spec.Field(spec.GlobalScope(), "active")))
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if expected := "$[?@.age >= 18 && @.active == true]"; template != expected {
+		t.Errorf("Expected %s, got %s", expected, template)
+	}
+}
@@ -1,6 +1,11 @@
 package specification
 
-import "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
 
 type Associativity string
 
@@ -31,6 +36,13 @@ type Visitor interface {
 	VisitPostfix(PostfixNode) error
 }
 
+// FunctionVisitor is implemented by the visitors supporting FunctionNode.
+// It is not a part of Visitor, so the visitors of downstream packages keep compiling.
+type FunctionVisitor interface {
+	Visitor
+	VisitFunction(FunctionNode) error
+}
+
 func Value(value any) ValueNode {
 	return ValueNode{
 		value: value,
@@ -147,6 +159,25 @@ func Is(left, right Visitable) InfixNode {
 	}
 }
 
+// In is the membership of the left operand in the slice of the right one, e.g. a ValueNode of []string.
+func In(left, right Visitable) InfixNode {
+	return InfixNode{
+		left:          left,
+		operator:      operators.OperatorIn,
+		right:         right,
+		associativity: NonAssociative,
+	}
+}
+
+func NotIn(left, right Visitable) InfixNode {
+	return InfixNode{
+		left:          left,
+		operator:      operators.OperatorNotIn,
+		right:         right,
+		associativity: NonAssociative,
+	}
+}
+
 func And(left Visitable, rights ...Visitable) InfixNode {
 	left, right := foldRights(And, left, rights...)
 	return InfixNode{
@@ -185,6 +216,43 @@ func RightShift(left, right Visitable) InfixNode {
 	}
 }
 
+func BitAnd(left, right Visitable) InfixNode {
+	return InfixNode{
+		left:          left,
+		operator:      operators.OperatorBitAnd,
+		right:         right,
+		associativity: LeftAssociative,
+	}
+}
+
+func BitOr(left, right Visitable) InfixNode {
+	return InfixNode{
+		left:          left,
+		operator:      operators.OperatorBitOr,
+		right:         right,
+		associativity: LeftAssociative,
+	}
+}
+
+func BitXor(left, right Visitable) InfixNode {
+	return InfixNode{
+		left:          left,
+		operator:      operators.OperatorBitXor,
+		right:         right,
+		associativity: LeftAssociative,
+	}
+}
+
+// Concat is the concatenation of the strings, NULL if either is NULL, as || in PostgreSQL.
+func Concat(left, right Visitable) InfixNode {
+	return InfixNode{
+		left:          left,
+		operator:      operators.OperatorConcat,
+		right:         right,
+		associativity: LeftAssociative,
+	}
+}
+
 func Add(left, right Visitable) InfixNode {
 	return InfixNode{
 		left:          left,
@@ -324,6 +392,88 @@ func (n PostfixNode) Accept(v Visitor) error {
 	return v.VisitPostfix(n)
 }
 
+// Function calls the function of the FunctionXxx name with the arguments, see RFC 9535 function extensions.
+func Function(name string, args ...Visitable) FunctionNode {
+	return FunctionNode{
+		name: name,
+		args: args,
+	}
+}
+
+// Between is the range check of the value with the inclusive bounds, see BetweenComparison.
+func Between(value, low, high Visitable) FunctionNode {
+	return Function(FunctionBetween, value, low, high)
+}
+
+// Now is the current time resolved when the specification is evaluated, see EvaluateVisitor.SetClock,
+// CURRENT_TIMESTAMP in SQL.
+func Now() FunctionNode {
+	return Function(FunctionNow)
+}
+
+// CurrentDate is the midnight of the current day in the location of the Clock, CURRENT_DATE in SQL.
+func CurrentDate() FunctionNode {
+	return Function(FunctionCurrentDate)
+}
+
+// Case is the value of then if the condition is true, otherwise the value of otherwise, as CASE WHEN in SQL,
+// a NULL condition is not true. Tiers are chained by Case of otherwise.
+func Case(when, then, otherwise Visitable) FunctionNode {
+	return Function(FunctionCase, when, then, otherwise)
+}
+
+// Coalesce is the first of the values that is not NULL, e.g. the default value of a nullable field.
+func Coalesce(value Visitable, defaults ...Visitable) FunctionNode {
+	return Function(FunctionCoalesce, append([]Visitable{value}, defaults...)...)
+}
+
+// Length is the number of characters of the string.
+func Length(value Visitable) FunctionNode {
+	return Function(FunctionLength, value)
+}
+
+// Match is the match of the whole string value by the regular expression pattern.
+func Match(value, pattern Visitable) FunctionNode {
+	return Function(FunctionMatch, value, pattern)
+}
+
+// Search is the match of a substring of the value by the regular expression pattern.
+func Search(value, pattern Visitable) FunctionNode {
+	return Function(FunctionSearch, value, pattern)
+}
+
+func StartsWith(value, prefix Visitable) FunctionNode {
+	return Function(FunctionStartsWith, value, prefix)
+}
+
+func EndsWith(value, suffix Visitable) FunctionNode {
+	return Function(FunctionEndsWith, value, suffix)
+}
+
+func Contains(value, substring Visitable) FunctionNode {
+	return Function(FunctionContains, value, substring)
+}
+
+type FunctionNode struct {
+	name string
+	args []Visitable
+}
+
+func (n FunctionNode) Name() string {
+	return n.name
+}
+
+func (n FunctionNode) Args() []Visitable {
+	return n.args
+}
+
+func (n FunctionNode) Accept(v Visitor) error {
+	if fv, ok := v.(FunctionVisitor); ok {
+		return fv.VisitFunction(n)
+	}
+	return fmt.Errorf("%w: %s by %T", ErrUnsupportedFunction, n.name, v)
+}
+
 // TODO: Rename me to Scope?
 type EmptiableObject interface {
 	Visitable
@@ -382,9 +532,51 @@ func (n ObjectNode) Accept(v Visitor) error {
 }
 
 func Wildcard(parent EmptiableObject, predicate Visitable) CollectionNode {
+	return NewCollectionNode(parent, WildcardSelector, predicate)
+}
+
+// Every is the predicate of all the elements of the collection, i.e. no element does not match,
+// so it is true for an empty collection, as All is.
+func Every(parent EmptiableObject, predicate Visitable) PrefixNode {
+	return Not(Wildcard(parent, Not(predicate)))
+}
+
+// CountOf is the number of the elements of the collection matching the predicate, see Count.
+func CountOf(parent EmptiableObject, predicate Visitable) FunctionNode {
+	return Function(FunctionCount, Wildcard(parent, predicate))
+}
+
+// SumOf is the sum of the values of the elements of the collection, NULL values are skipped
+// and the sum of no values is NULL, as in SQL. See Sum.
+func SumOf(parent EmptiableObject, value Visitable) FunctionNode {
+	return Function(FunctionSum, Wildcard(parent, value))
+}
+
+// MinOf is the least of the values of the elements of the collection, see SumOf for NULL values.
+func MinOf(parent EmptiableObject, value Visitable) FunctionNode {
+	return Function(FunctionMin, Wildcard(parent, value))
+}
+
+// MaxOf is the greatest of the values of the elements of the collection, see SumOf for NULL values.
+func MaxOf(parent EmptiableObject, value Visitable) FunctionNode {
+	return Function(FunctionMax, Wildcard(parent, value))
+}
+
+// Index is the predicate of the element of the collection at the index, negative indexes count from the end.
+func Index(parent EmptiableObject, index int, predicate Visitable) CollectionNode {
+	return NewCollectionNode(parent, strconv.Itoa(index), predicate)
+}
+
+// Slice is the predicate of any element of the collection from the start index up to the end one, exclusive.
+func Slice(parent EmptiableObject, start, end int, predicate Visitable) CollectionNode {
+	return NewCollectionNode(parent, strconv.Itoa(start)+":"+strconv.Itoa(end), predicate)
+}
+
+// NewCollectionNode creates the node of the collection elements selected by the selector, see SelectorBounds.
+func NewCollectionNode(parent EmptiableObject, selector string, predicate Visitable) CollectionNode {
 	return CollectionNode{
 		parent:    parent,
-		name:      "*",
+		name:      selector,
 		predicate: predicate,
 	}
 }
@@ -414,6 +606,11 @@ func (n CollectionNode) IsRoot() bool {
 	return false
 }
 
+// IsWildcard reports whether all the elements are selected, otherwise Name is the index or the slice selector.
+func (n CollectionNode) IsWildcard() bool {
+	return n.name == WildcardSelector
+}
+
 func (n CollectionNode) Predicate() Visitable {
 	return n.predicate
 }
@@ -0,0 +1,153 @@
+package specification
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+// DefaultClauseLimit is the default limit of the clauses of a normal form, see WithClauseLimit.
+const DefaultClauseLimit = 1024
+
+var ErrNormalFormTooLarge = errors.New("normal form is too large")
+
+type NormalFormOption func(*normalForm)
+
+// WithClauseLimit limits the number of the clauses of the normal form, the distribution of AND over OR
+// (or OR over AND) multiplies them, e.g. the DNF of n conjoined disjunctions of 2 has 2^n clauses.
+func WithClauseLimit(limit int) NormalFormOption {
+	return func(f *normalForm) {
+		f.limit = limit
+	}
+}
+
+// ToDNF converts the logical expression to the disjunctive normal form, OR of ANDs of the literals,
+// which are the operands other than AND, OR and NOT, or their negations.
+// The conversion keeps the result in the three-valued logic of NULL.
+func ToDNF(ast Visitable, opts ...NormalFormOption) (Visitable, error) {
+	clauses, err := newNormalForm(operators.OperatorOr, operators.OperatorAnd, opts).convert(ast)
+	if err != nil {
+		return nil, err
+	}
+	return joinLogical(operators.OperatorOr, clauses), nil
+}
+
+// ToCNF converts the logical expression to the conjunctive normal form, AND of ORs of the literals.
+func ToCNF(ast Visitable, opts ...NormalFormOption) (Visitable, error) {
+	clauses, err := newNormalForm(operators.OperatorAnd, operators.OperatorOr, opts).convert(ast)
+	if err != nil {
+		return nil, err
+	}
+	return joinLogical(operators.OperatorAnd, clauses), nil
+}
+
+// Disjuncts returns the conjunctions of the DNF of the expression, e.g. the branches of UNION.
+func Disjuncts(ast Visitable, opts ...NormalFormOption) ([]Visitable, error) {
+	return newNormalForm(operators.OperatorOr, operators.OperatorAnd, opts).convert(ast)
+}
+
+// normalForm is the outer operator of the clauses of the literals joined by the inner one.
+type normalForm struct {
+	outer operators.Operator
+	inner operators.Operator
+	limit int
+}
+
+func newNormalForm(outer, inner operators.Operator, opts []NormalFormOption) *normalForm {
+	f := &normalForm{outer: outer, inner: inner, limit: DefaultClauseLimit}
+	for i := range opts {
+		opts[i](f)
+	}
+	return f
+}
+
+// convert returns the clauses of the expression joined by the inner operator.
+func (f *normalForm) convert(ast Visitable) ([]Visitable, error) {
+	clauses, err := f.clauses(ast, false)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Visitable, 0, len(clauses))
+	for _, clause := range clauses {
+		result = append(result, joinLogical(f.inner, clause))
+	}
+	return result, nil
+}
+
+// clauses returns the clauses of the expression, negated pushes NOT to the literals by De Morgan's laws.
+func (f *normalForm) clauses(ast Visitable, negated bool) ([][]Visitable, error) {
+	if prefix, ok := ast.(PrefixNode); ok && prefix.Operator() == operators.OperatorNot {
+		return f.clauses(prefix.Operand(), !negated)
+	}
+	infix, ok := ast.(InfixNode)
+	if !ok || (infix.Operator() != operators.OperatorAnd && infix.Operator() != operators.OperatorOr) {
+		if negated {
+			ast = Not(ast)
+		}
+		return [][]Visitable{{ast}}, nil
+	}
+	op := infix.Operator()
+	if negated {
+		op = dualLogical(op)
+	}
+	left, err := f.clauses(infix.Left(), negated)
+	if err != nil {
+		return nil, err
+	}
+	right, err := f.clauses(infix.Right(), negated)
+	if err != nil {
+		return nil, err
+	}
+	size := len(left) * len(right)
+	if op == f.outer {
+		size = len(left) + len(right)
+	}
+	if size > f.limit {
+		return nil, fmt.Errorf("%w: %d clauses exceed the limit %d", ErrNormalFormTooLarge, size, f.limit)
+	}
+	if op == f.outer {
+		return append(left, right...), nil
+	}
+	// Distribution: (a OR b) AND (c OR d) is a AND c OR a AND d OR b AND c OR b AND d
+	product := make([][]Visitable, 0, len(left)*len(right))
+	for _, l := range left {
+		for _, r := range right {
+			clause := append(make([]Visitable, 0, len(l)+len(r)), l...)
+			for _, literal := range r {
+				if !containsNode(clause, literal) {
+					clause = append(clause, literal)
+				}
+			}
+			product = append(product, clause)
+		}
+	}
+	return product, nil
+}
+
+func dualLogical(op operators.Operator) operators.Operator {
+	if op == operators.OperatorAnd {
+		return operators.OperatorOr
+	}
+	return operators.OperatorAnd
+}
+
+func containsNode(nodes []Visitable, node Visitable) bool {
+	for _, n := range nodes {
+		if reflect.DeepEqual(n, node) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinLogical(op operators.Operator, nodes []Visitable) Visitable {
+	if len(nodes) == 1 {
+		return nodes[0]
+	}
+	if op == operators.OperatorAnd {
+		return And(nodes[0], nodes[1:]...)
+	}
+	return Or(nodes[0], nodes[1:]...)
+}
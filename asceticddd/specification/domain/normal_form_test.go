@@ -0,0 +1,92 @@
+package specification
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+func TestNormalForms(t *testing.T) {
+	root := GlobalScope()
+	a := Field(root, "a")
+	b := Field(root, "b")
+	c := GreaterThan(Field(root, "c"), Value(1))
+
+	for _, tc := range []struct {
+		name     string
+		convert  func(Visitable, ...NormalFormOption) (Visitable, error)
+		ast      Visitable
+		expected Visitable
+	}{
+		{"dnf distribution", ToDNF, And(Or(a, b), c), Or(And(a, c), And(b, c))},
+		{"dnf de morgan", ToDNF, Not(And(a, Or(b, c))), Or(Not(a), And(Not(b), Not(c)))},
+		{"dnf double negation", ToDNF, Not(Not(Or(a, b))), Or(a, b)},
+		{"dnf duplicate literal", ToDNF, And(Or(a, b), a), Or(a, And(b, a))},
+		{"cnf distribution", ToCNF, Or(And(a, b), c), And(Or(a, c), Or(b, c))},
+		{"cnf literal", ToCNF, c, c},
+	} {
+		actual, err := tc.convert(tc.ast)
+		if err != nil {
+			t.Fatalf("%s: conversion failed: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("%s: expected %#v, got %#v", tc.name, tc.expected, actual)
+		}
+	}
+}
+
+func TestNormalFormsAreEquivalent(t *testing.T) {
+	root := GlobalScope()
+	ast := Not(Or(And(Field(root, "a"), Not(Field(root, "b"))), And(Field(root, "c"), Or(Field(root, "a"), Field(root, "d")))))
+	dnf, err := ToDNF(ast)
+	if err != nil {
+		t.Fatalf("ToDNF failed: %v", err)
+	}
+	cnf, err := ToCNF(ast)
+	if err != nil {
+		t.Fatalf("ToCNF failed: %v", err)
+	}
+	// All the combinations of true, false and NULL of the fields
+	values := []any{true, false, nil}
+	for i := range 81 {
+		ctx := testContext{"a": values[i%3], "b": values[i/3%3], "c": values[i/9%3], "d": values[i/27%3]}
+		var results []any
+		for _, expr := range []Visitable{ast, dnf, cnf} {
+			visitor := NewEvaluateVisitor(ctx, operators.NewDefaultRegistry())
+			if err := expr.Accept(visitor); err != nil {
+				t.Fatalf("Accept failed: %v", err)
+			}
+			results = append(results, visitor.CurrentValue())
+		}
+		if results[0] != results[1] || results[0] != results[2] {
+			t.Errorf("%v: expected %v, got DNF %v and CNF %v", ctx, results[0], results[1], results[2])
+		}
+	}
+}
+
+func TestNormalFormLimit(t *testing.T) {
+	root := GlobalScope()
+	var ast Visitable = Or(Field(root, "a0"), Field(root, "b0"))
+	for i := 1; i < 4; i++ {
+		ast = And(ast, Or(Field(root, fmt.Sprintf("a%d", i)), Field(root, fmt.Sprintf("b%d", i))))
+	}
+
+	disjuncts, err := Disjuncts(ast)
+	if err != nil {
+		t.Fatalf("Disjuncts failed: %v", err)
+	}
+	if len(disjuncts) != 16 {
+		t.Errorf("Expected 16 disjuncts, got %d", len(disjuncts))
+	}
+
+	_, err = ToDNF(ast, WithClauseLimit(8))
+	if !errors.Is(err, ErrNormalFormTooLarge) {
+		t.Errorf("Expected ErrNormalFormTooLarge, got %v", err)
+	}
+	if _, err := ToCNF(ast, WithClauseLimit(8)); err != nil {
+		t.Errorf("Expected CNF within the limit, got %v", err)
+	}
+}
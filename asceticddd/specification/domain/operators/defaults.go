@@ -3,6 +3,7 @@ package operators
 import (
 	"cmp"
 	"errors"
+	"math/big"
 	"time"
 )
 
@@ -45,6 +46,45 @@ func registerModulo[T interface {
 	})
 }
 
+func registerBitwise[T interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}](reg *OperatorRegistry) {
+	RegisterBinary[T, T](reg, OperatorBitAnd, func(a, b T) (any, error) { return a & b, nil })
+	RegisterBinary[T, T](reg, OperatorBitOr, func(a, b T) (any, error) { return a | b, nil })
+	RegisterBinary[T, T](reg, OperatorBitXor, func(a, b T) (any, error) { return a ^ b, nil })
+}
+
+// registerRat registers the exact operators of *big.Rat, the decimal values of numeric.
+func registerRat(reg *OperatorRegistry) {
+	RegisterBinary[*big.Rat, *big.Rat](reg, OperatorEq, func(a, b *big.Rat) (any, error) { return a.Cmp(b) == 0, nil })
+	RegisterBinary[*big.Rat, *big.Rat](reg, OperatorNe, func(a, b *big.Rat) (any, error) { return a.Cmp(b) != 0, nil })
+	RegisterBinary[*big.Rat, *big.Rat](reg, OperatorGt, func(a, b *big.Rat) (any, error) { return a.Cmp(b) > 0, nil })
+	RegisterBinary[*big.Rat, *big.Rat](reg, OperatorGte, func(a, b *big.Rat) (any, error) { return a.Cmp(b) >= 0, nil })
+	RegisterBinary[*big.Rat, *big.Rat](reg, OperatorLt, func(a, b *big.Rat) (any, error) { return a.Cmp(b) < 0, nil })
+	RegisterBinary[*big.Rat, *big.Rat](reg, OperatorLte, func(a, b *big.Rat) (any, error) { return a.Cmp(b) <= 0, nil })
+	RegisterBinary[*big.Rat, *big.Rat](reg, OperatorAdd, func(a, b *big.Rat) (any, error) { return new(big.Rat).Add(a, b), nil })
+	RegisterBinary[*big.Rat, *big.Rat](reg, OperatorSub, func(a, b *big.Rat) (any, error) { return new(big.Rat).Sub(a, b), nil })
+	RegisterBinary[*big.Rat, *big.Rat](reg, OperatorMul, func(a, b *big.Rat) (any, error) { return new(big.Rat).Mul(a, b), nil })
+	RegisterBinary[*big.Rat, *big.Rat](reg, OperatorDiv, func(a, b *big.Rat) (any, error) {
+		if b.Sign() == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return new(big.Rat).Quo(a, b), nil
+	})
+	RegisterBinary[*big.Rat, *big.Rat](reg, OperatorMod, func(a, b *big.Rat) (any, error) {
+		if b.Sign() == 0 {
+			return nil, errors.New("modulo by zero")
+		}
+		// As numeric of PostgreSQL, the quotient is truncated toward zero, so the remainder has the sign of a.
+		q := new(big.Rat).Quo(a, b)
+		q.SetInt(new(big.Int).Quo(q.Num(), q.Denom()))
+		return new(big.Rat).Sub(a, q.Mul(q, b)), nil
+	})
+	RegisterUnary[*big.Rat](reg, OperatorPos, func(a *big.Rat) (any, error) { return a, nil })
+	RegisterUnary[*big.Rat](reg, OperatorNeg, func(a *big.Rat) (any, error) { return new(big.Rat).Neg(a), nil })
+}
+
 // NewDefaultRegistry creates a registry with PostgreSQL-compatible operators
 // for standard Go types.
 func NewDefaultRegistry() *OperatorRegistry {
@@ -60,18 +100,24 @@ func NewDefaultRegistry() *OperatorRegistry {
 	registerComparison[int](reg)
 	registerArithmetic[int](reg)
 	registerModulo[int](reg)
+	registerBitwise[int](reg)
 
 	// int64
 	registerComparison[int64](reg)
 	registerArithmetic[int64](reg)
 	registerModulo[int64](reg)
+	registerBitwise[int64](reg)
 
 	// float64
 	registerComparison[float64](reg)
 	registerArithmetic[float64](reg)
 
+	// *big.Rat (numeric)
+	registerRat(reg)
+
 	// string
 	registerComparison[string](reg)
+	RegisterBinary[string, string](reg, OperatorConcat, func(a, b string) (any, error) { return a + b, nil })
 
 	// time.Duration (interval)
 	RegisterBinary[time.Duration, time.Duration](reg, OperatorEq, func(a, b time.Duration) (any, error) { return a == b, nil })
@@ -99,6 +145,16 @@ func NewDefaultRegistry() *OperatorRegistry {
 	// Mixed: timestamp +/- interval = timestamp
 	RegisterBinary[time.Time, time.Duration](reg, OperatorAdd, func(a time.Time, b time.Duration) (any, error) { return a.Add(b), nil })
 	RegisterBinary[time.Time, time.Duration](reg, OperatorSub, func(a time.Time, b time.Duration) (any, error) { return a.Add(-b), nil })
+	RegisterBinary[time.Duration, time.Time](reg, OperatorAdd, func(a time.Duration, b time.Time) (any, error) { return b.Add(a), nil })
+
+	// Mixed: interval * integer = interval, interval / integer = interval
+	RegisterBinary[time.Duration, int](reg, OperatorMul, func(a time.Duration, b int) (any, error) { return a * time.Duration(b), nil })
+	RegisterBinary[time.Duration, int](reg, OperatorDiv, func(a time.Duration, b int) (any, error) {
+		if b == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return a / time.Duration(b), nil
+	})
 
 	return reg
 }
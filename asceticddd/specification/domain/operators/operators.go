@@ -13,6 +13,11 @@ const (
 	OperatorNe  Operator = "!="
 	OperatorIs  Operator = "IS"
 
+	// Membership, the right operand is a slice
+
+	OperatorIn    Operator = "IN"
+	OperatorNotIn Operator = "NOT IN"
+
 	// Logical operators
 
 	OperatorAnd Operator = "AND"
@@ -30,10 +35,17 @@ const (
 	OperatorPos Operator = "+pos"
 	OperatorNeg Operator = "-neg"
 
+	// String
+
+	OperatorConcat Operator = "||"
+
 	// Bitwise
 
 	OperatorLshift Operator = "<<"
 	OperatorRshift Operator = ">>"
+	OperatorBitAnd Operator = "&"
+	OperatorBitOr  Operator = "|"
+	OperatorBitXor Operator = "#" // as in PostgreSQL, where ^ is exponentiation
 
 	// Postfix
 
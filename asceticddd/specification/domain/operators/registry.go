@@ -64,6 +64,9 @@ func (r *OperatorRegistry) ExecBinary(left any, op Operator, right any) (any, er
 	if op == OperatorOr {
 		return execOr(left, right)
 	}
+	if op == OperatorIn || op == OperatorNotIn {
+		return r.execIn(left, op, right)
+	}
 
 	// NULL propagation for all other binary operators
 	if left == nil || right == nil {
@@ -115,9 +118,28 @@ func (r *OperatorRegistry) lookupBinary(left any, op Operator, right any) (Binar
 		return fallback, nil
 	}
 
+	// Fallback: equality of the identifiers and the enums, e.g. uuid.UUID or a named string type
+	if fallback := comparableFallback(left, op, right); fallback != nil {
+		return fallback, nil
+	}
+
 	return nil, fmt.Errorf("operator \"%s\" is not supported for %T and %T", op, left, right)
 }
 
+// comparableFallback compares the operands of the same comparable type by ==.
+func comparableFallback(left any, op Operator, right any) BinaryOp {
+	if op != OperatorEq && op != OperatorNe {
+		return nil
+	}
+	t := reflect.TypeOf(left)
+	if t != reflect.TypeOf(right) || !t.Comparable() {
+		return nil
+	}
+	return func(left, right any) (any, error) {
+		return (left == right) == (op == OperatorEq), nil
+	}
+}
+
 func interfaceFallback(left any, op Operator, right any) BinaryOp {
 	switch op {
 	case OperatorEq:
@@ -220,6 +242,40 @@ func (r *OperatorRegistry) lookupUnary(op Operator, operand any) (UnaryOp, error
 	return fn, nil
 }
 
+// execIn compares the left operand with the elements of the slice by OperatorEq,
+// with the three-valued logic of IN and NOT IN: a NULL operand, or no match with a NULL element, is NULL.
+func (r *OperatorRegistry) execIn(left any, op Operator, right any) (any, error) {
+	if right == nil {
+		return nil, nil
+	}
+	elements := reflect.ValueOf(right)
+	if elements.Kind() != reflect.Slice && elements.Kind() != reflect.Array {
+		return nil, fmt.Errorf("operator \"%s\" requires slice, got %T", op, right)
+	}
+	if left == nil {
+		return nil, nil
+	}
+	hasNull := false
+	for i := range elements.Len() {
+		element := elements.Index(i).Interface()
+		if element == nil {
+			hasNull = true
+			continue
+		}
+		equal, err := r.ExecBinary(left, OperatorEq, element)
+		if err != nil {
+			return nil, err
+		}
+		if equal == true {
+			return op == OperatorIn, nil
+		}
+	}
+	if hasNull {
+		return nil, nil
+	}
+	return op == OperatorNotIn, nil
+}
+
 // Three-valued logic: NULL AND FALSE = FALSE, NULL AND TRUE = NULL
 func execAnd(left, right any) (any, error) {
 	if left == nil {
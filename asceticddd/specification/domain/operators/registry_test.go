@@ -1,7 +1,9 @@
 package operators
 
 import (
+	"math/big"
 	"testing"
+	"time"
 )
 
 type Money struct {
@@ -149,3 +151,160 @@ func TestInterfaceFallback_NullPropagation(t *testing.T) {
 		t.Errorf("Expected nil (NULL), got %v", result)
 	}
 }
+
+func TestComparableFallback(t *testing.T) {
+	type status string
+	type id [2]byte
+	reg := NewDefaultRegistry()
+	cases := []struct {
+		left     any
+		op       Operator
+		right    any
+		expected any
+	}{
+		{status("active"), OperatorEq, status("active"), true},
+		{status("active"), OperatorNe, status("active"), false},
+		{id{1, 2}, OperatorEq, id{1, 3}, false},
+		{id{1, 2}, OperatorNe, id{1, 3}, true},
+	}
+	for _, c := range cases {
+		result, err := reg.ExecBinary(c.left, c.op, c.right)
+		if err != nil {
+			t.Fatalf("%v %s %v: unexpected error: %v", c.left, c.op, c.right, err)
+		}
+		if result != c.expected {
+			t.Errorf("%v %s %v: expected %v, got %v", c.left, c.op, c.right, c.expected, result)
+		}
+	}
+
+	if _, err := reg.ExecBinary(status("active"), OperatorEq, "active"); err == nil {
+		t.Error("expected error for different types")
+	}
+	if _, err := reg.ExecBinary(status("a"), OperatorLt, status("b")); err == nil {
+		t.Error("expected error for ordering of comparable fallback")
+	}
+}
+
+func TestExecBinaryIn(t *testing.T) {
+	reg := NewDefaultRegistry()
+	cases := []struct {
+		left     any
+		op       Operator
+		right    any
+		expected any
+	}{
+		{"a", OperatorIn, []string{"a", "b"}, true},
+		{"c", OperatorIn, []string{"a", "b"}, false},
+		{"c", OperatorNotIn, []string{"a", "b"}, true},
+		{"a", OperatorNotIn, []any{"a", nil}, false},
+		{"c", OperatorIn, []any{"a", nil}, nil},
+		{"c", OperatorNotIn, []any{"a", nil}, nil},
+		{nil, OperatorIn, []string{"a"}, nil},
+		{1, OperatorIn, []int{}, false},
+	}
+	for _, c := range cases {
+		result, err := reg.ExecBinary(c.left, c.op, c.right)
+		if err != nil {
+			t.Fatalf("%v %s %v: unexpected error: %v", c.left, c.op, c.right, err)
+		}
+		if result != c.expected {
+			t.Errorf("%v %s %v: expected %v, got %v", c.left, c.op, c.right, c.expected, result)
+		}
+	}
+
+	if _, err := reg.ExecBinary("a", OperatorIn, "a"); err == nil {
+		t.Error("expected error for non-slice right operand")
+	}
+}
+
+func TestRatOperators(t *testing.T) {
+	reg := NewDefaultRegistry()
+	rat := func(s string) *big.Rat {
+		r, ok := new(big.Rat).SetString(s)
+		if !ok {
+			t.Fatalf("invalid rat %s", s)
+		}
+		return r
+	}
+
+	// 0.1 + 0.2 is exactly 0.3, unlike float64
+	sum, err := reg.ExecBinary(rat("0.1"), OperatorAdd, rat("0.2"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := reg.ExecBinary(sum, OperatorEq, rat("0.3"))
+	if err != nil || result != true {
+		t.Errorf("expected 0.1 + 0.2 = 0.3, got %v, %v", result, err)
+	}
+
+	cases := []struct {
+		left     string
+		op       Operator
+		right    string
+		expected string
+	}{
+		{"10.50", OperatorSub, "0.5", "10"},
+		{"1.5", OperatorMul, "3", "4.5"},
+		{"1", OperatorDiv, "4", "0.25"},
+		{"7.5", OperatorMod, "2", "1.5"},
+		{"-7.5", OperatorMod, "2", "-1.5"},
+	}
+	for _, c := range cases {
+		result, err := reg.ExecBinary(rat(c.left), c.op, rat(c.right))
+		if err != nil {
+			t.Fatalf("%s %s %s: unexpected error: %v", c.left, c.op, c.right, err)
+		}
+		if result.(*big.Rat).Cmp(rat(c.expected)) != 0 {
+			t.Errorf("%s %s %s: expected %s, got %v", c.left, c.op, c.right, c.expected, result)
+		}
+	}
+
+	for op, expected := range map[Operator]bool{
+		OperatorGt: true, OperatorGte: true, OperatorLt: false, OperatorLte: false, OperatorNe: true,
+	} {
+		result, err := reg.ExecBinary(rat("2.01"), op, rat("2"))
+		if err != nil || result != expected {
+			t.Errorf("2.01 %s 2: expected %v, got %v, %v", op, expected, result, err)
+		}
+	}
+
+	if _, err := reg.ExecBinary(rat("1"), OperatorDiv, rat("0")); err == nil {
+		t.Error("expected division by zero error")
+	}
+	negated, err := reg.ExecUnary(OperatorNeg, rat("1.5"))
+	if err != nil || negated.(*big.Rat).Cmp(rat("-1.5")) != 0 {
+		t.Errorf("expected -1.5, got %v, %v", negated, err)
+	}
+}
+
+func TestIntervalOperators(t *testing.T) {
+	reg := NewDefaultRegistry()
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	month := 30 * 24 * time.Hour
+	cases := []struct {
+		left     any
+		op       Operator
+		right    any
+		expected any
+	}{
+		{createdAt, OperatorAdd, month, createdAt.Add(month)},
+		{month, OperatorAdd, createdAt, createdAt.Add(month)},
+		{createdAt.Add(month), OperatorSub, createdAt, month},
+		{month, OperatorMul, 2, 2 * month},
+		{month, OperatorDiv, 30, 24 * time.Hour},
+		{month, OperatorGt, 24 * time.Hour, true},
+	}
+	for _, c := range cases {
+		result, err := reg.ExecBinary(c.left, c.op, c.right)
+		if err != nil {
+			t.Fatalf("%v %s %v: unexpected error: %v", c.left, c.op, c.right, err)
+		}
+		if result != c.expected {
+			t.Errorf("%v %s %v: expected %v, got %v", c.left, c.op, c.right, c.expected, result)
+		}
+	}
+
+	if _, err := reg.ExecBinary(month, OperatorDiv, 0); err == nil {
+		t.Error("expected error for division by zero")
+	}
+}
@@ -0,0 +1,54 @@
+package operators
+
+import (
+	"math"
+	"slices"
+)
+
+// FloatTolerance tolerates rounding errors of float64 numbers, e.g. of JSON round-trips.
+// Numbers are equal when |a - b| <= max(Absolute, Relative * max(|a|, |b|)).
+type FloatTolerance struct {
+	Absolute float64
+	Relative float64
+}
+
+func (t FloatTolerance) Equal(a, b float64) bool {
+	if a == b {
+		return true
+	}
+	diff := math.Abs(a - b)
+	return diff <= t.Absolute || diff <= t.Relative*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// Compare returns 0 for equal numbers, see Equal, -1 if a < b and +1 if a > b.
+func (t FloatTolerance) Compare(a, b float64) int {
+	switch {
+	case t.Equal(a, b):
+		return 0
+	case a < b:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// RegisterFloatTolerance replaces the float64 comparisons of the registry with ones of the tolerance,
+// only of the given operators if any. Nearly equal numbers are equal for =, >= and <=, and not for !=, > and <.
+func RegisterFloatTolerance(reg *OperatorRegistry, tolerance FloatTolerance, ops ...Operator) {
+	comparisons := map[Operator]func(cmp int) bool{
+		OperatorEq:  func(cmp int) bool { return cmp == 0 },
+		OperatorNe:  func(cmp int) bool { return cmp != 0 },
+		OperatorGt:  func(cmp int) bool { return cmp > 0 },
+		OperatorGte: func(cmp int) bool { return cmp >= 0 },
+		OperatorLt:  func(cmp int) bool { return cmp < 0 },
+		OperatorLte: func(cmp int) bool { return cmp <= 0 },
+	}
+	for op, test := range comparisons {
+		if len(ops) > 0 && !slices.Contains(ops, op) {
+			continue
+		}
+		RegisterBinary[float64, float64](reg, op, func(a, b float64) (any, error) {
+			return test(tolerance.Compare(a, b)), nil
+		})
+	}
+}
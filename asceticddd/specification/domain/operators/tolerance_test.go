@@ -0,0 +1,59 @@
+package operators
+
+import (
+	"testing"
+)
+
+func TestFloatToleranceEqual(t *testing.T) {
+	tolerance := FloatTolerance{Absolute: 1e-9, Relative: 1e-12}
+
+	if !tolerance.Equal(0.30000000000000004, 0.3) {
+		t.Error("Expected 0.30000000000000004 to equal 0.3 within absolute tolerance")
+	}
+	if !tolerance.Equal(1e15+0.0001, 1e15) {
+		t.Error("Expected large numbers to be equal within relative tolerance")
+	}
+	if tolerance.Equal(0.3, 0.31) {
+		t.Error("Expected 0.3 and 0.31 to differ")
+	}
+}
+
+func TestRegisterFloatTolerance(t *testing.T) {
+	reg := NewDefaultRegistry()
+	RegisterFloatTolerance(reg, FloatTolerance{Absolute: 1e-9})
+
+	cases := []struct {
+		op       Operator
+		expected bool
+	}{
+		{OperatorEq, true},
+		{OperatorNe, false},
+		{OperatorGt, false},
+		{OperatorGte, true},
+		{OperatorLt, false},
+		{OperatorLte, true},
+	}
+	for _, c := range cases {
+		result, err := reg.ExecBinary(0.30000000000000004, c.op, 0.3)
+		if err != nil {
+			t.Fatalf("ExecBinary(%s) failed: %v", c.op, err)
+		}
+		if result != c.expected {
+			t.Errorf("Expected 0.30000000000000004 %s 0.3 to be %v, got %v", c.op, c.expected, result)
+		}
+	}
+}
+
+func TestRegisterFloatToleranceOfOperators(t *testing.T) {
+	reg := NewDefaultRegistry()
+	RegisterFloatTolerance(reg, FloatTolerance{Absolute: 1e-9}, OperatorEq)
+
+	result, err := reg.ExecBinary(0.30000000000000004, OperatorEq, 0.3)
+	if err != nil || result != true {
+		t.Errorf("Expected tolerant =, got %v, %v", result, err)
+	}
+	result, err = reg.ExecBinary(0.30000000000000004, OperatorGt, 0.3)
+	if err != nil || result != true {
+		t.Errorf("Expected exact >, got %v, %v", result, err)
+	}
+}
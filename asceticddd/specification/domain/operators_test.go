@@ -2,6 +2,7 @@ package specification
 
 import (
 	"testing"
+	"time"
 
 	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
 )
@@ -365,6 +366,204 @@ func TestRightShiftOperator(t *testing.T) {
 	}
 }
 
+func TestBitwiseAndOrXorOperators(t *testing.T) {
+	ctx := make(testContext)
+	ctx["flags"] = 0b1010
+	flags := Field(GlobalScope(), "flags")
+
+	for _, tc := range []struct {
+		expression Visitable
+		expected   int
+	}{
+		{BitAnd(flags, Value(0b0110)), 0b0010},
+		{BitOr(flags, Value(0b0110)), 0b1110},
+		{BitXor(flags, Value(0b0110)), 0b1100},
+	} {
+		visitor := NewEvaluateVisitor(ctx, operators.NewDefaultRegistry())
+		err := Equal(tc.expression, Value(tc.expected)).Accept(visitor)
+		if err != nil {
+			t.Fatalf("Accept failed: %v", err)
+		}
+		result, err := visitor.Result()
+		if err != nil {
+			t.Fatalf("Result failed: %v", err)
+		}
+		if result != true {
+			t.Errorf("Expected %s to be %b", tc.expression.(InfixNode).Operator(), tc.expected)
+		}
+	}
+}
+
+func TestConcatAndLengthOperators(t *testing.T) {
+	ctx := make(testContext)
+	ctx["first_name"] = "Jürgen"
+	ctx["last_name"] = "Schmidt"
+	ctx["nick"] = nil
+	root := GlobalScope()
+
+	for _, tc := range []struct {
+		expression Visitable
+		expected   any
+	}{
+		{Equal(Concat(Concat(Field(root, "first_name"), Value(" ")), Field(root, "last_name")), Value("Jürgen Schmidt")), true},
+		{Equal(Length(Field(root, "first_name")), Value(6)), true},
+		{GreaterThan(Length(Concat(Field(root, "first_name"), Field(root, "last_name"))), Value(13)), false},
+		{GreaterThan(Length(Field(root, "nick")), Value(0)), nil},
+		{Equal(Concat(Field(root, "nick"), Value("x")), Value("x")), nil},
+	} {
+		visitor := NewEvaluateVisitor(ctx, operators.NewDefaultRegistry())
+		err := tc.expression.Accept(visitor)
+		if err != nil {
+			t.Fatalf("Accept failed: %v", err)
+		}
+		if visitor.CurrentValue() != tc.expected {
+			t.Errorf("Expected %v, got %v", tc.expected, visitor.CurrentValue())
+		}
+	}
+
+	err := Length(Value(42)).Accept(NewEvaluateVisitor(ctx, operators.NewDefaultRegistry()))
+	if err == nil {
+		t.Error("Expected error of length of not a string")
+	}
+}
+
+func TestBetweenOperator(t *testing.T) {
+	ctx := make(testContext)
+	ctx["age"] = 30
+	ctx["score"] = nil
+	root := GlobalScope()
+
+	for _, tc := range []struct {
+		expression Visitable
+		expected   any
+	}{
+		{Between(Field(root, "age"), Value(18), Value(30)), true},
+		{Between(Field(root, "age"), Value(31), Value(65)), false},
+		{Between(Field(root, "age"), Value(65), Value(18)), false},
+		{Between(Field(root, "score"), Value(0), Value(10)), nil},
+		{Between(Field(root, "age"), Value(40), Value(nil)), false},
+		{Not(Between(Field(root, "age"), Value(18), Value(20))), true},
+	} {
+		visitor := NewEvaluateVisitor(ctx, operators.NewDefaultRegistry())
+		err := tc.expression.Accept(visitor)
+		if err != nil {
+			t.Fatalf("Accept failed: %v", err)
+		}
+		if visitor.CurrentValue() != tc.expected {
+			t.Errorf("Expected %v, got %v", tc.expected, visitor.CurrentValue())
+		}
+	}
+
+	err := Function(FunctionBetween, Value(1), Value(2)).Accept(NewEvaluateVisitor(ctx, operators.NewDefaultRegistry()))
+	if err == nil {
+		t.Error("Expected error of between of 2 arguments")
+	}
+}
+
+func TestCoalesceFunction(t *testing.T) {
+	ctx := make(testContext)
+	ctx["discount"] = nil
+	ctx["price"] = 100
+	root := GlobalScope()
+
+	for _, tc := range []struct {
+		expression Visitable
+		expected   any
+	}{
+		{Equal(Coalesce(Field(root, "discount"), Value(0)), Value(0)), true},
+		{Equal(Coalesce(Field(root, "price"), Value(0)), Value(100)), true},
+		{Equal(Coalesce(Field(root, "discount"), Value(nil), Field(root, "price")), Value(100)), true},
+		{Equal(Coalesce(Field(root, "discount")), Value(0)), nil},
+	} {
+		visitor := NewEvaluateVisitor(ctx, operators.NewDefaultRegistry())
+		err := tc.expression.Accept(visitor)
+		if err != nil {
+			t.Fatalf("Accept failed: %v", err)
+		}
+		if visitor.CurrentValue() != tc.expected {
+			t.Errorf("Expected %v, got %v", tc.expected, visitor.CurrentValue())
+		}
+	}
+}
+
+func TestCurrentTimeFunctions(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	now := time.Date(2024, 3, 15, 1, 30, 0, 0, moscow)
+	ctx := make(testContext)
+	ctx["created_at"] = now.Add(-31 * 24 * time.Hour)
+	ctx["due_at"] = time.Date(2024, 3, 15, 0, 0, 0, 0, moscow)
+	root := GlobalScope()
+
+	for _, tc := range []struct {
+		expression Visitable
+		expected   any
+	}{
+		{Equal(Now(), Value(now)), true},
+		{LessThan(Add(Field(root, "created_at"), Value(30*24*time.Hour)), Now()), true},
+		{Equal(Field(root, "due_at"), CurrentDate()), true},
+	} {
+		visitor := NewEvaluateVisitor(ctx, operators.NewDefaultRegistry())
+		visitor.SetClock(FixedClock(now))
+		err := tc.expression.Accept(visitor)
+		if err != nil {
+			t.Fatalf("Accept failed: %v", err)
+		}
+		if visitor.CurrentValue() != tc.expected {
+			t.Errorf("Expected %v, got %v", tc.expected, visitor.CurrentValue())
+		}
+	}
+
+	visitor := NewEvaluateVisitor(ctx, operators.NewDefaultRegistry())
+	before := time.Now()
+	if err := Now().Accept(visitor); err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	if current := visitor.CurrentValue().(time.Time); current.Before(before) {
+		t.Errorf("Expected the time of SystemClock, got %v", current)
+	}
+}
+
+func TestCaseFunction(t *testing.T) {
+	root := GlobalScope()
+	quantity := Field(root, "quantity")
+	// Tiered price: 10% off from 10 items, 20% off from 100 items
+	price := Case(
+		GreaterThanEqual(quantity, Value(100)), Value(80),
+		Case(GreaterThanEqual(quantity, Value(10)), Value(90), Value(100)),
+	)
+
+	for _, tc := range []struct {
+		quantity any
+		expected any
+	}{
+		{5, 100},
+		{10, 90},
+		{150, 80},
+		{nil, 100},
+	} {
+		ctx := testContext{"quantity": tc.quantity}
+		visitor := NewEvaluateVisitor(ctx, operators.NewDefaultRegistry())
+		err := price.Accept(visitor)
+		if err != nil {
+			t.Fatalf("Accept failed: %v", err)
+		}
+		if visitor.CurrentValue() != tc.expected {
+			t.Errorf("Quantity %v: expected %v, got %v", tc.quantity, tc.expected, visitor.CurrentValue())
+		}
+	}
+
+	// The branch that is not selected is not evaluated
+	ctx := testContext{"quantity": 1}
+	visitor := NewEvaluateVisitor(ctx, operators.NewDefaultRegistry())
+	err := Case(Value(true), Value(1), Field(root, "missing")).Accept(visitor)
+	if err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	if visitor.CurrentValue() != 1 {
+		t.Errorf("Expected 1, got %v", visitor.CurrentValue())
+	}
+}
+
 // TestPostfixOperators tests IS NULL / IS NOT NULL
 
 func TestIsNullOperator(t *testing.T) {
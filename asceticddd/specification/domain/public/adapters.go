@@ -124,6 +124,21 @@ func (c ComparisonImp) Rshift(other Comparison) Logical {
 	return NewLogical(s.RightShift(c.Delegate(), other.Delegate()))
 }
 
+// In creates a membership check in the slice of values, e.g. []string.
+func (c ComparisonImp) In(values any) Logical {
+	return NewLogical(s.In(c.Delegate(), s.Value(values)))
+}
+
+// NotIn creates a non-membership check in the slice of values.
+func (c ComparisonImp) NotIn(values any) Logical {
+	return NewLogical(s.NotIn(c.Delegate(), s.Value(values)))
+}
+
+// Between creates a range check with the inclusive bounds.
+func (c ComparisonImp) Between(low, high Comparison) Logical {
+	return NewLogical(s.Between(c.Delegate(), low.Delegate(), high.Delegate()))
+}
+
 // MathematicalImp implements Mathematical interface.
 type MathematicalImp struct {
 	DelegatingImp
@@ -179,3 +194,9 @@ func Field(name string) s.FieldNode {
 	}
 	return s.Field(s.GlobalScope(), name)
 }
+
+// Ref creates the reference of the named specification, expanded by s.SpecificationRegistry.Expand.
+// Example: Ref("AdultUser").And(MakeBooleanField("is_premium")).
+func Ref(name string) Logical {
+	return NewLogical(s.Ref(name))
+}
@@ -0,0 +1,113 @@
+package public
+
+import (
+	"strings"
+
+	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+)
+
+// Collection represents a collection field whose elements are matched by the predicates of Item.
+type Collection struct {
+	object s.EmptiableObject
+}
+
+// NewCollection creates a new Collection instance of the object of the collection.
+func NewCollection(object s.EmptiableObject) Collection {
+	return Collection{object: object}
+}
+
+// MakeCollectionField creates a Collection field from a field name.
+func MakeCollectionField(name string) Collection {
+	return NewCollection(Object_(name))
+}
+
+// Any creates a check that at least one element matches the predicate.
+func (c Collection) Any(predicate func(Item) Logical) Logical {
+	return NewLogical(s.Wildcard(c.object, predicate(Item{}).Delegate()))
+}
+
+// All creates a check that every element matches the predicate, it is true for an empty collection.
+func (c Collection) All(predicate func(Item) Logical) Logical {
+	return NewLogical(s.Every(c.object, predicate(Item{}).Delegate()))
+}
+
+// Count creates the number of the elements.
+func (c Collection) Count() Number {
+	return NewNumber(s.CountOf(c.object, s.Value(true)))
+}
+
+// CountWhere creates the number of the elements matching the predicate.
+func (c Collection) CountWhere(predicate func(Item) Logical) Number {
+	return NewNumber(s.CountOf(c.object, predicate(Item{}).Delegate()))
+}
+
+// Sum creates the sum of the values of the elements, NULL for an empty collection.
+func (c Collection) Sum(value func(Item) Mathematical) NullNumber {
+	return NewNullNumber(s.SumOf(c.object, value(Item{}).Delegate()))
+}
+
+// Min creates the least of the values of the elements, NULL for an empty collection.
+func (c Collection) Min(value func(Item) Mathematical) NullNumber {
+	return NewNullNumber(s.MinOf(c.object, value(Item{}).Delegate()))
+}
+
+// Max creates the greatest of the values of the elements, NULL for an empty collection.
+func (c Collection) Max(value func(Item) Mathematical) NullNumber {
+	return NewNullNumber(s.MaxOf(c.object, value(Item{}).Delegate()))
+}
+
+// Item represents the element of the collection in the predicates of Collection,
+// its fields are created relative to the element.
+type Item struct{}
+
+// Field creates a Field node of the element from a dotted path string.
+func (i Item) Field(name string) s.FieldNode {
+	idx := strings.LastIndex(name, ".")
+	if idx == -1 {
+		return s.Field(s.Item(), name)
+	}
+	return s.Field(i.object(name[:idx]), name[idx+1:])
+}
+
+func (i Item) object(name string) s.EmptiableObject {
+	var parent s.EmptiableObject = s.Item()
+	for _, part := range strings.Split(name, ".") {
+		parent = s.Object(parent, part)
+	}
+	return parent
+}
+
+// MakeBooleanField creates a Boolean field of the element.
+func (i Item) MakeBooleanField(name string) Boolean {
+	return NewBoolean(i.Field(name))
+}
+
+// MakeNumberField creates a Number field of the element.
+func (i Item) MakeNumberField(name string) Number {
+	return NewNumber(i.Field(name))
+}
+
+// MakeNullNumberField creates a NullNumber field of the element.
+func (i Item) MakeNullNumberField(name string) NullNumber {
+	return NewNullNumber(i.Field(name))
+}
+
+// MakeDatetimeField creates a Datetime field of the element.
+func (i Item) MakeDatetimeField(name string) Datetime {
+	return NewDatetime(i.Field(name))
+}
+
+// MakeTextField creates a Text field of the element.
+func (i Item) MakeTextField(name string) Text {
+	return NewText(i.Field(name))
+}
+
+// MakeNullTextField creates a NullText field of the element.
+func (i Item) MakeNullTextField(name string) NullText {
+	return NewNullText(i.Field(name))
+}
+
+// MakeCollectionField creates a Collection field of the element, e.g. of nested wildcards.
+func (i Item) MakeCollectionField(name string) Collection {
+	return NewCollection(i.object(name))
+}
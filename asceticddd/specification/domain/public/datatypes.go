@@ -1,6 +1,12 @@
 package public
 
 import (
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
 	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
 )
 
@@ -55,6 +61,11 @@ func (n NullBoolean) IsNotNull() Logical {
 	return NewLogical(s.IsNotNull(n.Delegate()))
 }
 
+// OrElse creates the value or the default value if it is NULL.
+func (n NullBoolean) OrElse(defaultValue Boolean) Boolean {
+	return NewBoolean(s.Coalesce(n.Delegate(), defaultValue.Delegate()))
+}
+
 // Number represents a numeric field that supports comparison and mathematical operations.
 type Number struct {
 	DelegatingImp
@@ -110,6 +121,18 @@ func (n Number) Rshift(other Comparison) Logical {
 	return NewLogical(s.RightShift(n.Delegate(), other.Delegate()))
 }
 
+func (n Number) In(values any) Logical {
+	return NewLogical(s.In(n.Delegate(), s.Value(values)))
+}
+
+func (n Number) NotIn(values any) Logical {
+	return NewLogical(s.NotIn(n.Delegate(), s.Value(values)))
+}
+
+func (n Number) Between(low, high Comparison) Logical {
+	return NewLogical(s.Between(n.Delegate(), low.Delegate(), high.Delegate()))
+}
+
 // Mathematical methods
 func (n Number) Add(other Mathematical) Mathematical {
 	return NewNumber(s.Add(n.Delegate(), other.Delegate()))
@@ -131,6 +154,19 @@ func (n Number) Mod(other Mathematical) Mathematical {
 	return NewNumber(s.Mod(n.Delegate(), other.Delegate()))
 }
 
+// Bitwise methods
+func (n Number) BitAnd(other Mathematical) Mathematical {
+	return NewNumber(s.BitAnd(n.Delegate(), other.Delegate()))
+}
+
+func (n Number) BitOr(other Mathematical) Mathematical {
+	return NewNumber(s.BitOr(n.Delegate(), other.Delegate()))
+}
+
+func (n Number) BitXor(other Mathematical) Mathematical {
+	return NewNumber(s.BitXor(n.Delegate(), other.Delegate()))
+}
+
 // NullNumber represents a nullable numeric field.
 type NullNumber struct {
 	Number
@@ -162,6 +198,136 @@ func (n NullNumber) IsNotNull() Logical {
 	return NewLogical(s.IsNotNull(n.Delegate()))
 }
 
+// OrElse creates the value or the default value if it is NULL.
+func (n NullNumber) OrElse(defaultValue Number) Number {
+	return NewNumber(s.Coalesce(n.Delegate(), defaultValue.Delegate()))
+}
+
+// Decimal represents an exact decimal field, e.g. money, backed by *big.Rat,
+// so the evaluation has no float drift and SQL compiles the values as numeric.
+type Decimal struct {
+	DelegatingImp
+}
+
+// NewDecimal creates a new Decimal instance.
+func NewDecimal(delegate s.Visitable) Decimal {
+	return Decimal{
+		DelegatingImp: NewDelegating(delegate),
+	}
+}
+
+// MakeDecimalField creates a Decimal field from a field name.
+func MakeDecimalField(name string) Decimal {
+	return NewDecimal(Field(name))
+}
+
+// MakeDecimalValue creates a Decimal value.
+func MakeDecimalValue(value *big.Rat) Decimal {
+	return NewDecimal(s.Value(value))
+}
+
+// Comparison methods for Decimal
+func (d Decimal) Eq(other Comparison) Logical {
+	return NewLogical(s.Equal(d.Delegate(), other.Delegate()))
+}
+
+func (d Decimal) Ne(other Comparison) Logical {
+	return NewLogical(s.NotEqual(d.Delegate(), other.Delegate()))
+}
+
+func (d Decimal) Gt(other Comparison) Logical {
+	return NewLogical(s.GreaterThan(d.Delegate(), other.Delegate()))
+}
+
+func (d Decimal) Lt(other Comparison) Logical {
+	return NewLogical(s.LessThan(d.Delegate(), other.Delegate()))
+}
+
+func (d Decimal) Gte(other Comparison) Logical {
+	return NewLogical(s.GreaterThanEqual(d.Delegate(), other.Delegate()))
+}
+
+func (d Decimal) Lte(other Comparison) Logical {
+	return NewLogical(s.LessThanEqual(d.Delegate(), other.Delegate()))
+}
+
+func (d Decimal) Lshift(other Comparison) Logical {
+	return NewLogical(s.LeftShift(d.Delegate(), other.Delegate()))
+}
+
+func (d Decimal) Rshift(other Comparison) Logical {
+	return NewLogical(s.RightShift(d.Delegate(), other.Delegate()))
+}
+
+func (d Decimal) In(values any) Logical {
+	return NewLogical(s.In(d.Delegate(), s.Value(values)))
+}
+
+func (d Decimal) NotIn(values any) Logical {
+	return NewLogical(s.NotIn(d.Delegate(), s.Value(values)))
+}
+
+func (d Decimal) Between(low, high Comparison) Logical {
+	return NewLogical(s.Between(d.Delegate(), low.Delegate(), high.Delegate()))
+}
+
+// Mathematical methods for Decimal
+func (d Decimal) Add(other Mathematical) Mathematical {
+	return NewDecimal(s.Add(d.Delegate(), other.Delegate()))
+}
+
+func (d Decimal) Sub(other Mathematical) Mathematical {
+	return NewDecimal(s.Sub(d.Delegate(), other.Delegate()))
+}
+
+func (d Decimal) Mul(other Mathematical) Mathematical {
+	return NewDecimal(s.Mul(d.Delegate(), other.Delegate()))
+}
+
+func (d Decimal) Div(other Mathematical) Mathematical {
+	return NewDecimal(s.Div(d.Delegate(), other.Delegate()))
+}
+
+func (d Decimal) Mod(other Mathematical) Mathematical {
+	return NewDecimal(s.Mod(d.Delegate(), other.Delegate()))
+}
+
+// NullDecimal represents a nullable decimal field.
+type NullDecimal struct {
+	Decimal
+}
+
+// NewNullDecimal creates a new NullDecimal instance.
+func NewNullDecimal(delegate s.Visitable) NullDecimal {
+	return NullDecimal{
+		Decimal: NewDecimal(delegate),
+	}
+}
+
+// MakeNullDecimalField creates a NullDecimal field from a field name.
+func MakeNullDecimalField(name string) NullDecimal {
+	return NewNullDecimal(Field(name))
+}
+
+// MakeNullDecimalValue creates a NullDecimal value.
+func MakeNullDecimalValue(value any) NullDecimal {
+	return NewNullDecimal(s.Value(value))
+}
+
+// Nullable methods for NullDecimal
+func (n NullDecimal) IsNull() Logical {
+	return NewLogical(s.IsNull(n.Delegate()))
+}
+
+func (n NullDecimal) IsNotNull() Logical {
+	return NewLogical(s.IsNotNull(n.Delegate()))
+}
+
+// OrElse creates the value or the default value if it is NULL.
+func (n NullDecimal) OrElse(defaultValue Decimal) Decimal {
+	return NewDecimal(s.Coalesce(n.Delegate(), defaultValue.Delegate()))
+}
+
 // Datetime represents a datetime field that supports comparison and mathematical operations.
 type Datetime struct {
 	DelegatingImp
@@ -184,6 +350,16 @@ func MakeDatetimeValue(value any) Datetime {
 	return NewDatetime(s.Value(value))
 }
 
+// Now creates the current time, resolved by the Clock of the evaluation and CURRENT_TIMESTAMP in SQL.
+func Now() Datetime {
+	return NewDatetime(s.Now())
+}
+
+// CurrentDate creates the midnight of the current day, CURRENT_DATE in SQL.
+func CurrentDate() Datetime {
+	return NewDatetime(s.CurrentDate())
+}
+
 // Comparison methods for Datetime
 func (d Datetime) Eq(other Comparison) Logical {
 	return NewLogical(s.Equal(d.Delegate(), other.Delegate()))
@@ -217,13 +393,30 @@ func (d Datetime) Rshift(other Comparison) Logical {
 	return NewLogical(s.RightShift(d.Delegate(), other.Delegate()))
 }
 
+func (d Datetime) In(values any) Logical {
+	return NewLogical(s.In(d.Delegate(), s.Value(values)))
+}
+
+func (d Datetime) NotIn(values any) Logical {
+	return NewLogical(s.NotIn(d.Delegate(), s.Value(values)))
+}
+
+func (d Datetime) Between(low, high Comparison) Logical {
+	return NewLogical(s.Between(d.Delegate(), low.Delegate(), high.Delegate()))
+}
+
 // Mathematical methods for Datetime (for date arithmetic)
 func (d Datetime) Add(other Mathematical) Mathematical {
-	return NewMathematical(s.Add(d.Delegate(), other.Delegate()))
+	return NewDatetime(s.Add(d.Delegate(), other.Delegate()))
 }
 
+// Sub creates a subtraction, the Duration between the datetimes,
+// or the Datetime if the other is a Duration.
 func (d Datetime) Sub(other Mathematical) Mathematical {
-	return NewMathematical(s.Sub(d.Delegate(), other.Delegate()))
+	if duration, ok := other.(Duration); ok {
+		return d.SubDuration(duration)
+	}
+	return NewDuration(s.Sub(d.Delegate(), other.Delegate()))
 }
 
 func (d Datetime) Mul(other Mathematical) Mathematical {
@@ -238,6 +431,16 @@ func (d Datetime) Mod(other Mathematical) Mathematical {
 	return NewMathematical(s.Mod(d.Delegate(), other.Delegate()))
 }
 
+// AddDuration creates the datetime shifted forward by the duration.
+func (d Datetime) AddDuration(duration Duration) Datetime {
+	return NewDatetime(s.Add(d.Delegate(), duration.Delegate()))
+}
+
+// SubDuration creates the datetime shifted backward by the duration.
+func (d Datetime) SubDuration(duration Duration) Datetime {
+	return NewDatetime(s.Sub(d.Delegate(), duration.Delegate()))
+}
+
 // NullDatetime represents a nullable datetime field.
 type NullDatetime struct {
 	Datetime
@@ -269,6 +472,102 @@ func (n NullDatetime) IsNotNull() Logical {
 	return NewLogical(s.IsNotNull(n.Delegate()))
 }
 
+// OrElse creates the value or the default value if it is NULL.
+func (n NullDatetime) OrElse(defaultValue Datetime) Datetime {
+	return NewDatetime(s.Coalesce(n.Delegate(), defaultValue.Delegate()))
+}
+
+// Duration represents an interval field, e.g. the difference of the datetimes, backed by time.Duration.
+type Duration struct {
+	DelegatingImp
+}
+
+// NewDuration creates a new Duration instance.
+func NewDuration(delegate s.Visitable) Duration {
+	return Duration{
+		DelegatingImp: NewDelegating(delegate),
+	}
+}
+
+// MakeDurationField creates a Duration field from a field name.
+func MakeDurationField(name string) Duration {
+	return NewDuration(Field(name))
+}
+
+// MakeDurationValue creates a Duration value.
+func MakeDurationValue(value time.Duration) Duration {
+	return NewDuration(s.Value(value))
+}
+
+// Comparison methods for Duration
+func (d Duration) Eq(other Comparison) Logical {
+	return NewLogical(s.Equal(d.Delegate(), other.Delegate()))
+}
+
+func (d Duration) Ne(other Comparison) Logical {
+	return NewLogical(s.NotEqual(d.Delegate(), other.Delegate()))
+}
+
+func (d Duration) Gt(other Comparison) Logical {
+	return NewLogical(s.GreaterThan(d.Delegate(), other.Delegate()))
+}
+
+func (d Duration) Lt(other Comparison) Logical {
+	return NewLogical(s.LessThan(d.Delegate(), other.Delegate()))
+}
+
+func (d Duration) Gte(other Comparison) Logical {
+	return NewLogical(s.GreaterThanEqual(d.Delegate(), other.Delegate()))
+}
+
+func (d Duration) Lte(other Comparison) Logical {
+	return NewLogical(s.LessThanEqual(d.Delegate(), other.Delegate()))
+}
+
+func (d Duration) Lshift(other Comparison) Logical {
+	return NewLogical(s.LeftShift(d.Delegate(), other.Delegate()))
+}
+
+func (d Duration) Rshift(other Comparison) Logical {
+	return NewLogical(s.RightShift(d.Delegate(), other.Delegate()))
+}
+
+func (d Duration) In(values any) Logical {
+	return NewLogical(s.In(d.Delegate(), s.Value(values)))
+}
+
+func (d Duration) NotIn(values any) Logical {
+	return NewLogical(s.NotIn(d.Delegate(), s.Value(values)))
+}
+
+func (d Duration) Between(low, high Comparison) Logical {
+	return NewLogical(s.Between(d.Delegate(), low.Delegate(), high.Delegate()))
+}
+
+// Mathematical methods for Duration, the sum with a Datetime is the Datetime
+func (d Duration) Add(other Mathematical) Mathematical {
+	if _, ok := other.(Datetime); ok {
+		return NewDatetime(s.Add(d.Delegate(), other.Delegate()))
+	}
+	return NewDuration(s.Add(d.Delegate(), other.Delegate()))
+}
+
+func (d Duration) Sub(other Mathematical) Mathematical {
+	return NewDuration(s.Sub(d.Delegate(), other.Delegate()))
+}
+
+func (d Duration) Mul(other Mathematical) Mathematical {
+	return NewDuration(s.Mul(d.Delegate(), other.Delegate()))
+}
+
+func (d Duration) Div(other Mathematical) Mathematical {
+	return NewDuration(s.Div(d.Delegate(), other.Delegate()))
+}
+
+func (d Duration) Mod(other Mathematical) Mathematical {
+	return NewDuration(s.Mod(d.Delegate(), other.Delegate()))
+}
+
 // Text represents a text field that supports comparison operations.
 type Text struct {
 	ComparisonImp
@@ -289,6 +588,16 @@ func MakeTextValue(value string) Text {
 	return NewText(s.Value(value))
 }
 
+// Concat creates a concatenation of the texts.
+func (t Text) Concat(other Text) Text {
+	return NewText(s.Concat(t.Delegate(), other.Delegate()))
+}
+
+// Length creates the number of characters of the text.
+func (t Text) Length() Number {
+	return NewNumber(s.Length(t.Delegate()))
+}
+
 // NullText represents a nullable text field.
 type NullText struct {
 	Text
@@ -319,3 +628,149 @@ func (n NullText) IsNull() Logical {
 func (n NullText) IsNotNull() Logical {
 	return NewLogical(s.IsNotNull(n.Delegate()))
 }
+
+// OrElse creates the value or the default value if it is NULL.
+func (n NullText) OrElse(defaultValue Text) Text {
+	return NewText(s.Coalesce(n.Delegate(), defaultValue.Delegate()))
+}
+
+// Uuid represents a UUID field that supports equality and membership.
+type Uuid struct {
+	DelegatingImp
+}
+
+// NewUuid creates a new Uuid instance.
+func NewUuid(delegate s.Visitable) Uuid {
+	return Uuid{DelegatingImp: NewDelegating(delegate)}
+}
+
+// MakeUuidField creates a Uuid field from a field name.
+func MakeUuidField(name string) Uuid {
+	return NewUuid(Field(name))
+}
+
+// MakeUuidValue creates a Uuid value.
+func MakeUuidValue(value uuid.UUID) Uuid {
+	return NewUuid(s.Value(value))
+}
+
+// ParseUuidValue creates a Uuid value from the text, the text must be a valid UUID.
+func ParseUuidValue(text string) (Uuid, error) {
+	value, err := uuid.Parse(text)
+	if err != nil {
+		return Uuid{}, fmt.Errorf("invalid UUID %q: %w", text, err)
+	}
+	return MakeUuidValue(value), nil
+}
+
+// Eq creates an equality comparison.
+func (u Uuid) Eq(other Uuid) Logical {
+	return NewLogical(s.Equal(u.Delegate(), other.Delegate()))
+}
+
+// Ne creates an inequality comparison.
+func (u Uuid) Ne(other Uuid) Logical {
+	return NewLogical(s.NotEqual(u.Delegate(), other.Delegate()))
+}
+
+// In creates a membership check in the UUIDs.
+func (u Uuid) In(values ...uuid.UUID) Logical {
+	return NewLogical(s.In(u.Delegate(), s.Value(values)))
+}
+
+// NotIn creates a non-membership check in the UUIDs.
+func (u Uuid) NotIn(values ...uuid.UUID) Logical {
+	return NewLogical(s.NotIn(u.Delegate(), s.Value(values)))
+}
+
+// NullUuid represents a nullable UUID field, e.g. an optional reference.
+type NullUuid struct {
+	Uuid
+}
+
+// NewNullUuid creates a new NullUuid instance.
+func NewNullUuid(delegate s.Visitable) NullUuid {
+	return NullUuid{
+		Uuid: NewUuid(delegate),
+	}
+}
+
+// MakeNullUuidField creates a NullUuid field from a field name.
+func MakeNullUuidField(name string) NullUuid {
+	return NewNullUuid(Field(name))
+}
+
+// Nullable methods for NullUuid
+func (n NullUuid) IsNull() Logical {
+	return NewLogical(s.IsNull(n.Delegate()))
+}
+
+func (n NullUuid) IsNotNull() Logical {
+	return NewLogical(s.IsNotNull(n.Delegate()))
+}
+
+// Enum represents a field of the enumeration, e.g. a named string type of the statuses.
+// The values are validated against the allowed set when the specification is built,
+// so a specification can not compare the field with an invalid literal.
+type Enum[T comparable] struct {
+	DelegatingImp
+	allowed []T
+}
+
+// NewEnum creates a new Enum instance.
+func NewEnum[T comparable](delegate s.Visitable, allowed ...T) Enum[T] {
+	return Enum[T]{DelegatingImp: NewDelegating(delegate), allowed: allowed}
+}
+
+// MakeEnumField creates an Enum field from a field name and the allowed values.
+func MakeEnumField[T comparable](name string, allowed ...T) Enum[T] {
+	return NewEnum(Field(name), allowed...)
+}
+
+// Allowed returns the allowed values of the enum.
+func (e Enum[T]) Allowed() []T {
+	return e.allowed
+}
+
+// Value creates a value of the enum, the value must be allowed.
+func (e Enum[T]) Value(value T) (Enum[T], error) {
+	if err := e.validate(value); err != nil {
+		return Enum[T]{}, err
+	}
+	return NewEnum(s.Value(value), e.allowed...), nil
+}
+
+// Eq creates an equality comparison.
+func (e Enum[T]) Eq(other Enum[T]) Logical {
+	return NewLogical(s.Equal(e.Delegate(), other.Delegate()))
+}
+
+// Ne creates an inequality comparison.
+func (e Enum[T]) Ne(other Enum[T]) Logical {
+	return NewLogical(s.NotEqual(e.Delegate(), other.Delegate()))
+}
+
+// In creates a membership check in the values, the values must be allowed.
+func (e Enum[T]) In(values ...T) (Logical, error) {
+	if err := e.validate(values...); err != nil {
+		return nil, err
+	}
+	return NewLogical(s.In(e.Delegate(), s.Value(values))), nil
+}
+
+// NotIn creates a non-membership check in the values, the values must be allowed.
+func (e Enum[T]) NotIn(values ...T) (Logical, error) {
+	if err := e.validate(values...); err != nil {
+		return nil, err
+	}
+	return NewLogical(s.NotIn(e.Delegate(), s.Value(values))), nil
+}
+
+func (e Enum[T]) validate(values ...T) error {
+	for _, value := range values {
+		if !slices.Contains(e.allowed, value) {
+			return fmt.Errorf("invalid enum value %v, allowed values are %v", value, e.allowed)
+		}
+	}
+	return nil
+}
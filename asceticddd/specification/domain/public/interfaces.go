@@ -36,6 +36,9 @@ type Comparison interface {
 	Lte(other Comparison) Logical
 	Lshift(other Comparison) Logical
 	Rshift(other Comparison) Logical
+	In(values any) Logical
+	NotIn(values any) Logical
+	Between(low, high Comparison) Logical
 }
 
 // Mathematical represents a type that supports mathematical operations.
@@ -47,3 +50,11 @@ type Mathematical interface {
 	Div(other Mathematical) Mathematical
 	Mod(other Mathematical) Mathematical
 }
+
+// Bitwise represents a type that supports bitwise operations.
+type Bitwise interface {
+	Delegating
+	BitAnd(other Mathematical) Mathematical
+	BitOr(other Mathematical) Mathematical
+	BitXor(other Mathematical) Mathematical
+}
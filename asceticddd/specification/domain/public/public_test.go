@@ -1,9 +1,12 @@
 package public
 
 import (
+	"math/big"
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
 	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
 )
@@ -583,6 +586,19 @@ func TestIntegration(t *testing.T) {
 
 	})
 
+	t.Run("NullableDefaultValue", func(t *testing.T) {
+		discount := MakeNullNumberField("discount").OrElse(MakeNumberValue(0))
+		fn, ok := discount.Delegate().(s.FunctionNode)
+		if !ok || fn.Name() != s.FunctionCoalesce || len(fn.Args()) != 2 {
+			t.Errorf("Expected coalesce function, got %#v", discount.Delegate())
+		}
+		_ = discount.Gt(MakeNumberValue(10))
+
+		_ = MakeNullBooleanField("verified").OrElse(MakeBooleanValue(false)).And(MakeBooleanField("active"))
+		_ = MakeNullTextField("nickname").OrElse(MakeTextField("name")).Length()
+		_ = MakeNullDatetimeField("updated_at").OrElse(MakeDatetimeField("created_at"))
+	})
+
 	t.Run("ComplexExpression", func(t *testing.T) {
 		age := MakeNumberField("age")
 		name := MakeTextField("name")
@@ -619,6 +635,65 @@ func TestIntegration(t *testing.T) {
 
 	})
 
+	t.Run("MembershipOperations", func(t *testing.T) {
+		statuses := []string{"active", "pending"}
+		for _, tc := range []struct {
+			result   Logical
+			operator operators.Operator
+		}{
+			{MakeTextField("status").In(statuses), operators.OperatorIn},
+			{MakeTextField("status").NotIn(statuses), operators.OperatorNotIn},
+			{MakeNumberField("age").In([]int{18, 21}), operators.OperatorIn},
+			{MakeDatetimeField("created_at").NotIn([]any{}), operators.OperatorNotIn},
+		} {
+			if tc.result.Delegate().(s.InfixNode).Operator() != tc.operator {
+				t.Errorf("Expected %s operator", tc.operator)
+			}
+		}
+	})
+
+	t.Run("BetweenOperation", func(t *testing.T) {
+		for _, result := range []Logical{
+			MakeNumberField("age").Between(MakeNumberValue(18), MakeNumberValue(65)),
+			MakeTextField("code").Between(MakeTextValue("A"), MakeTextValue("M")),
+			MakeDatetimeField("created_at").Between(MakeDatetimeField("starts_at"), MakeDatetimeField("ends_at")),
+		} {
+			fn, ok := result.Delegate().(s.FunctionNode)
+			if !ok || fn.Name() != s.FunctionBetween || len(fn.Args()) != 3 {
+				t.Errorf("Expected between function, got %#v", result.Delegate())
+			}
+		}
+	})
+
+	t.Run("TextOperations", func(t *testing.T) {
+		fullName := MakeTextField("first_name").Concat(MakeTextValue(" ")).Concat(MakeTextField("last_name"))
+		if fullName.Delegate().(s.InfixNode).Operator() != operators.OperatorConcat {
+			t.Error("Expected CONCAT operator")
+		}
+
+		length := fullName.Length()
+		if length.Delegate().(s.FunctionNode).Name() != s.FunctionLength {
+			t.Error("Expected length function")
+		}
+		_ = length.Gt(MakeNumberValue(0))
+	})
+
+	t.Run("BitwiseOperations", func(t *testing.T) {
+		flags := MakeNumberField("flags")
+		mask := MakeNumberValue(0b0110)
+		var _ Bitwise = flags
+
+		for operator, result := range map[operators.Operator]Mathematical{
+			operators.OperatorBitAnd: flags.BitAnd(mask),
+			operators.OperatorBitOr:  flags.BitOr(mask),
+			operators.OperatorBitXor: flags.BitXor(mask),
+		} {
+			if result.Delegate().(s.InfixNode).Operator() != operator {
+				t.Errorf("Expected %s operator", operator)
+			}
+		}
+	})
+
 	t.Run("ModuloOperation", func(t *testing.T) {
 		number := MakeNumberField("number")
 		divisor := MakeNumberValue(10)
@@ -632,3 +707,226 @@ func TestIntegration(t *testing.T) {
 		}
 	})
 }
+
+// TestRef tests the references of the named specifications
+func TestRef(t *testing.T) {
+	registry := s.NewSpecificationRegistry()
+	if err := registry.Define("AdultUser", MakeNumberField("age").Gte(MakeNumberValue(18)).Delegate()); err != nil {
+		t.Fatalf("Define failed: %v", err)
+	}
+	spec := Ref("AdultUser").And(MakeBooleanField("is_premium"))
+	expanded, err := registry.Expand(spec.Delegate())
+	if err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+	expected := s.And(s.GreaterThanEqual(Field("age"), s.Value(18)), Field("is_premium"))
+	if !reflect.DeepEqual(expanded, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, expanded)
+	}
+}
+
+// TestCollection tests the predicates of collection fields
+func TestCollection(t *testing.T) {
+	type Line struct {
+		Price    int  `json:"price"`
+		Quantity int  `json:"quantity"`
+		Gift     bool `json:"gift"`
+	}
+	type Order struct {
+		Lines []Line `json:"lines"`
+	}
+	order := Order{Lines: []Line{
+		{Price: 100, Quantity: 2},
+		{Price: 50, Quantity: 1, Gift: true},
+		{Price: 300, Quantity: 1},
+	}}
+	lines := MakeCollectionField("lines")
+	isGift := func(line Item) Logical { return line.MakeBooleanField("gift") }
+	amount := func(line Item) Mathematical {
+		return line.MakeNumberField("price").Mul(line.MakeNumberField("quantity"))
+	}
+
+	for _, tc := range []struct {
+		name     string
+		spec     Delegating
+		expected any
+	}{
+		{"any", lines.Any(isGift), true},
+		{"all", lines.All(isGift), false},
+		{"count", lines.Count().Eq(MakeNumberValue(3)), true},
+		{"count where", lines.CountWhere(isGift), 1},
+		{"sum", lines.Sum(amount).Gt(MakeNumberValue(500)), true},
+		{"min", lines.Min(amount), 50},
+		{"max", lines.Max(amount), 300},
+		{"empty max", MakeCollectionField("empty").Max(amount).IsNull(), true},
+	} {
+		visitor := s.NewEvaluateVisitor(s.NewStructContext(struct {
+			Order
+			Empty []Line `json:"empty"`
+		}{Order: order}), operators.NewDefaultRegistry())
+		err := tc.spec.Delegate().Accept(visitor)
+		if err != nil {
+			t.Fatalf("%s: Accept failed: %v", tc.name, err)
+		}
+		if visitor.CurrentValue() != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, visitor.CurrentValue())
+		}
+	}
+
+	t.Run("NestedCollection", func(t *testing.T) {
+		spec := MakeCollectionField("orders").Any(func(order Item) Logical {
+			return order.MakeCollectionField("lines").Any(func(line Item) Logical {
+				return line.MakeNumberField("price").Gt(MakeNumberValue(200))
+			})
+		})
+		expected := s.Wildcard(
+			s.Object(s.GlobalScope(), "orders"),
+			s.Wildcard(s.Object(s.Item(), "lines"), s.GreaterThan(s.Field(s.Item(), "price"), s.Value(200))),
+		)
+		if !reflect.DeepEqual(spec.Delegate(), expected) {
+			t.Errorf("Expected %#v, got %#v", expected, spec.Delegate())
+		}
+		if field := (Item{}).Field("address.city"); field.Object().Name() != "address" || field.Name() != "city" {
+			t.Errorf("Expected address.city field of the item, got %#v", field)
+		}
+	})
+}
+
+// TestDecimal tests the exact decimal datatype
+func TestDecimal(t *testing.T) {
+	rat := func(text string) *big.Rat {
+		r, _ := new(big.Rat).SetString(text)
+		return r
+	}
+	type Product struct {
+		Price    big.Rat  `json:"price"`
+		Discount *big.Rat `json:"discount"`
+	}
+	product := Product{Price: *rat("0.1")}
+	price := MakeDecimalField("price")
+	discount := MakeNullDecimalField("discount")
+
+	for _, tc := range []struct {
+		name     string
+		spec     Delegating
+		expected any
+	}{
+		{"exact sum", price.Add(MakeDecimalValue(rat("0.2"))).(Decimal).Eq(MakeDecimalValue(rat("0.3"))), true},
+		{"between", price.Between(MakeDecimalValue(rat("0.05")), MakeDecimalValue(rat("0.1"))), true},
+		{"null", discount.IsNull(), true},
+		{"or else", discount.OrElse(MakeDecimalValue(rat("0"))).Lt(price), true},
+	} {
+		visitor := s.NewEvaluateVisitor(s.NewStructContext(product), operators.NewDefaultRegistry())
+		err := tc.spec.Delegate().Accept(visitor)
+		if err != nil {
+			t.Fatalf("%s: Accept failed: %v", tc.name, err)
+		}
+		if visitor.CurrentValue() != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, visitor.CurrentValue())
+		}
+	}
+}
+
+// TestUuidAndEnum tests the identifier and enumeration datatypes
+func TestUuidAndEnum(t *testing.T) {
+	type Status string
+	type Order struct {
+		Id         uuid.UUID  `json:"id"`
+		CustomerId *uuid.UUID `json:"customer_id"`
+		Status     Status     `json:"status"`
+	}
+	id := uuid.MustParse("0192e4a8-3c1f-7a6b-9d2e-5f8a1b3c4d5e")
+	order := Order{Id: id, Status: "paid"}
+
+	orderId, err := ParseUuidValue(id.String())
+	if err != nil {
+		t.Fatalf("ParseUuidValue failed: %v", err)
+	}
+	if _, err := ParseUuidValue("not-a-uuid"); err == nil {
+		t.Error("Expected error for invalid UUID")
+	}
+
+	status := MakeEnumField[Status]("status", "new", "paid", "shipped")
+	paid, err := status.Value("paid")
+	if err != nil {
+		t.Fatalf("Value failed: %v", err)
+	}
+	if _, err := status.Value("payed"); err == nil {
+		t.Error("Expected error for invalid enum value")
+	}
+	if _, err := status.In("new", "payed"); err == nil {
+		t.Error("Expected error for invalid enum value in In")
+	}
+	open, err := status.NotIn("shipped")
+	if err != nil {
+		t.Fatalf("NotIn failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		spec     Delegating
+		expected any
+	}{
+		{"uuid eq", MakeUuidField("id").Eq(orderId), true},
+		{"uuid in", MakeUuidField("id").In(uuid.New(), id), true},
+		{"uuid not in", MakeUuidField("id").NotIn(uuid.New()), true},
+		{"null uuid", MakeNullUuidField("customer_id").IsNull(), true},
+		{"enum eq", status.Eq(paid), true},
+		{"enum not in", open, true},
+	} {
+		visitor := s.NewEvaluateVisitor(s.NewStructContext(order), operators.NewDefaultRegistry())
+		err := tc.spec.Delegate().Accept(visitor)
+		if err != nil {
+			t.Fatalf("%s: Accept failed: %v", tc.name, err)
+		}
+		if visitor.CurrentValue() != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, visitor.CurrentValue())
+		}
+	}
+}
+
+// TestDurationArithmetic tests the interval arithmetic of datetimes
+func TestDurationArithmetic(t *testing.T) {
+	type Account struct {
+		CreatedAt   time.Time     `json:"created_at"`
+		ConfirmedAt time.Time     `json:"confirmed_at"`
+		Trial       time.Duration `json:"trial"`
+	}
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	account := Account{CreatedAt: createdAt, ConfirmedAt: createdAt.Add(2 * time.Hour), Trial: 14 * 24 * time.Hour}
+	now := MakeDatetimeValue(createdAt.AddDate(0, 2, 0))
+	month := MakeDurationValue(30 * 24 * time.Hour)
+	created := MakeDatetimeField("created_at")
+
+	confirmation, ok := MakeDatetimeField("confirmed_at").Sub(created).(Duration)
+	if !ok {
+		t.Fatal("Expected Duration of the difference of datetimes")
+	}
+	if _, ok := created.Sub(month).(Datetime); !ok {
+		t.Fatal("Expected Datetime of the datetime minus the duration")
+	}
+
+	for _, tc := range []struct {
+		name     string
+		spec     Delegating
+		expected any
+	}{
+		{"add duration", created.AddDuration(month).Lt(now), true},
+		{"sub duration", now.SubDuration(month).Gt(created), true},
+		{"difference", confirmation.Lte(MakeDurationValue(time.Hour)), false},
+		{"duration field", created.AddDuration(MakeDurationField("trial")).Eq(MakeDatetimeValue(createdAt.AddDate(0, 0, 14))), true},
+		{"now", created.AddDuration(month).Lt(Now()), true},
+		{"current date", CurrentDate().Gt(created), true},
+		{"scaled duration", month.Mul(MakeNumberValue(2)).(Duration).Gt(MakeDurationField("trial")), true},
+	} {
+		visitor := s.NewEvaluateVisitor(s.NewStructContext(account), operators.NewDefaultRegistry())
+		visitor.SetClock(s.FixedClock(createdAt.AddDate(0, 2, 0)))
+		err := tc.spec.Delegate().Accept(visitor)
+		if err != nil {
+			t.Fatalf("%s: Accept failed: %v", tc.name, err)
+		}
+		if visitor.CurrentValue() != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, visitor.CurrentValue())
+		}
+	}
+}
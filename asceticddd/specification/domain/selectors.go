@@ -0,0 +1,56 @@
+package specification
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const WildcardSelector = "*"
+
+// SelectorBounds returns the bounds of the elements of the collection of the length selected by the selector
+// of CollectionNode: the wildcard "*", the index "0" or "-1", or the slice "1:3", "1:" or ":-1" as in RFC 9535.
+// Negative indexes count from the end, indexes out of the collection select nothing.
+func SelectorBounds(selector string, length int) (start, end int, err error) {
+	if selector == WildcardSelector {
+		return 0, length, nil
+	}
+	startPart, endPart, isSlice := strings.Cut(selector, ":")
+	if !isSlice && startPart == "" {
+		return 0, 0, fmt.Errorf("unsupported slice type \"%s\"", selector)
+	}
+	start, err = parseSelectorIndex(selector, startPart, 0, length)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !isSlice {
+		if start < 0 || start >= length {
+			return 0, 0, nil
+		}
+		return start, start + 1, nil
+	}
+	end, err = parseSelectorIndex(selector, endPart, length, length)
+	if err != nil {
+		return 0, 0, err
+	}
+	start = min(max(start, 0), length)
+	end = min(max(end, 0), length)
+	if start > end {
+		return 0, 0, nil
+	}
+	return start, end, nil
+}
+
+func parseSelectorIndex(selector, part string, omitted, length int) (int, error) {
+	if part == "" {
+		return omitted, nil
+	}
+	index, err := strconv.Atoi(part)
+	if err != nil {
+		return 0, fmt.Errorf("unsupported slice type \"%s\"", selector)
+	}
+	if index < 0 {
+		index += length
+	}
+	return index, nil
+}
@@ -0,0 +1,292 @@
+package specification
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+// Simplify returns the equivalent AST, smaller to evaluate or to compile:
+//
+//   - the operators and the functions of values are folded to the value by the registry, e.g. 1 < 2 to true,
+//     unless the registry does not support them;
+//   - NOT NOT A is A;
+//   - A AND true is A, A AND false is false, A OR true is true, A OR false is A;
+//   - A AND A is A, A AND (A OR B) is A, A OR (A AND B) is A;
+//   - Case of a value condition is its selected branch, NULL values of Coalesce are dropped.
+//
+// The rules hold in the three-valued logic of NULL. Now, CurrentDate, Ref and aggregates are kept as is.
+func Simplify(ast Visitable, registry *operators.OperatorRegistry) (Visitable, error) {
+	visitor := &simplifyVisitor{registry: registry}
+	return visitor.simplify(ast)
+}
+
+// PartialEvaluate substitutes the values of the fields available in the partial context and simplifies
+// the residual specification, see Simplify, e.g. the tenant of And(Equal(tenant, Value(1)), GreaterThan(age, Value(18)))
+// is evaluated in memory, and the residual GreaterThan(age, Value(18)) is compiled to SQL.
+// The fields missing in the context (ErrKeyNotFound) and the fields of the collection items are kept.
+func PartialEvaluate(ast Visitable, partialContext Context) (Visitable, error) {
+	visitor := &simplifyVisitor{registry: operators.NewDefaultRegistry(), context: partialContext}
+	return visitor.simplify(ast)
+}
+
+type simplifyVisitor struct {
+	registry    *operators.OperatorRegistry
+	context     Context
+	currentNode Visitable
+}
+
+func (v *simplifyVisitor) simplify(n Visitable) (Visitable, error) {
+	err := n.Accept(v)
+	if err != nil {
+		return nil, err
+	}
+	return v.currentNode, nil
+}
+
+func (v *simplifyVisitor) VisitGlobalScope(n GlobalScopeNode) error {
+	v.currentNode = n
+	return nil
+}
+
+func (v *simplifyVisitor) VisitObject(n ObjectNode) error {
+	v.currentNode = n
+	return nil
+}
+
+func (v *simplifyVisitor) VisitCollection(n CollectionNode) error {
+	predicate, err := v.simplify(n.Predicate())
+	if err != nil {
+		return err
+	}
+	v.currentNode = NewCollectionNode(n.Parent(), n.Name(), predicate)
+	return nil
+}
+
+func (v *simplifyVisitor) VisitItem(n ItemNode) error {
+	v.currentNode = n
+	return nil
+}
+
+func (v *simplifyVisitor) VisitField(n FieldNode) error {
+	v.currentNode = n
+	if v.context == nil {
+		return nil
+	}
+	value, ok, err := v.resolve(n)
+	if err != nil {
+		return err
+	}
+	if ok {
+		v.currentNode = Value(value)
+	}
+	return nil
+}
+
+// resolve returns the value of the field of the global scope in the partial context.
+func (v *simplifyVisitor) resolve(n FieldNode) (any, bool, error) {
+	var objects []string
+	var obj EmptiableObject = n.Object()
+	for ; !obj.IsRoot(); obj = obj.Parent() {
+		objects = append([]string{obj.Name()}, objects...)
+	}
+	if _, ok := obj.(GlobalScopeNode); !ok {
+		return nil, false, nil
+	}
+	ctx := v.context
+	for _, name := range objects {
+		value, err := ctx.Get(name)
+		if errors.Is(err, ErrKeyNotFound) {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		object, ok := value.(Context)
+		if !ok {
+			return nil, false, fmt.Errorf("%s is %T, not an object", name, value)
+		}
+		ctx = object
+	}
+	value, err := ctx.Get(n.Name())
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (v *simplifyVisitor) VisitValue(n ValueNode) error {
+	v.currentNode = n
+	return nil
+}
+
+func (v *simplifyVisitor) VisitPrefix(n PrefixNode) error {
+	operand, err := v.simplify(n.Operand())
+	if err != nil {
+		return err
+	}
+	if n.Operator() == operators.OperatorNot {
+		if inner, ok := operand.(PrefixNode); ok && inner.Operator() == operators.OperatorNot {
+			v.currentNode = inner.Operand()
+			return nil
+		}
+	}
+	if value, ok := operand.(ValueNode); ok {
+		if result, err := v.registry.ExecUnary(n.Operator(), value.Value()); err == nil {
+			v.currentNode = Value(result)
+			return nil
+		}
+	}
+	v.currentNode = NewPrefixNode(n.Operator(), operand, n.Associativity())
+	return nil
+}
+
+func (v *simplifyVisitor) VisitInfix(n InfixNode) error {
+	left, err := v.simplify(n.Left())
+	if err != nil {
+		return err
+	}
+	right, err := v.simplify(n.Right())
+	if err != nil {
+		return err
+	}
+	switch n.Operator() {
+	case operators.OperatorAnd, operators.OperatorOr:
+		v.currentNode = v.simplifyLogical(left, n.Operator(), right, n.Associativity())
+		return nil
+	}
+	leftValue, leftOk := left.(ValueNode)
+	rightValue, rightOk := right.(ValueNode)
+	if leftOk && rightOk {
+		if result, err := v.registry.ExecBinary(leftValue.Value(), n.Operator(), rightValue.Value()); err == nil {
+			v.currentNode = Value(result)
+			return nil
+		}
+	}
+	v.currentNode = NewInfixNode(left, n.Operator(), right, n.Associativity())
+	return nil
+}
+
+// simplifyLogical prunes the constant operands and absorbs the repeated ones of AND and OR.
+func (v *simplifyVisitor) simplifyLogical(left Visitable, op operators.Operator, right Visitable, associativity Associativity) Visitable {
+	// The dominant constant is false for AND and true for OR, the other one is neutral.
+	dominant := op == operators.OperatorOr
+	for _, pair := range [][2]Visitable{{left, right}, {right, left}} {
+		value, ok := pair[0].(ValueNode)
+		if !ok {
+			continue
+		}
+		switch value.Value() {
+		case dominant:
+			return value
+		case !dominant:
+			return pair[1]
+		}
+	}
+	if reflect.DeepEqual(left, right) {
+		return left
+	}
+	dual := operators.OperatorOr
+	if op == operators.OperatorOr {
+		dual = operators.OperatorAnd
+	}
+	for _, pair := range [][2]Visitable{{left, right}, {right, left}} {
+		inner, ok := pair[1].(InfixNode)
+		if ok && inner.Operator() == dual &&
+			(reflect.DeepEqual(inner.Left(), pair[0]) || reflect.DeepEqual(inner.Right(), pair[0])) {
+			return pair[0]
+		}
+	}
+	return NewInfixNode(left, op, right, associativity)
+}
+
+func (v *simplifyVisitor) VisitPostfix(n PostfixNode) error {
+	operand, err := v.simplify(n.Operand())
+	if err != nil {
+		return err
+	}
+	if value, ok := operand.(ValueNode); ok {
+		if result, err := v.registry.ExecUnary(n.Operator(), value.Value()); err == nil {
+			v.currentNode = Value(result)
+			return nil
+		}
+	}
+	v.currentNode = NewPostfixNode(operand, n.Operator(), n.Associativity())
+	return nil
+}
+
+func (v *simplifyVisitor) VisitFunction(n FunctionNode) error {
+	var args []Visitable
+	for _, arg := range n.Args() {
+		simplified, err := v.simplify(arg)
+		if err != nil {
+			return err
+		}
+		args = append(args, simplified)
+	}
+	switch {
+	case n.Name() == FunctionCase && len(args) == 3:
+		if condition, ok := args[0].(ValueNode); ok {
+			// A NULL condition is not true, as in the evaluation.
+			if condition.Value() == true {
+				v.currentNode = args[1]
+			} else {
+				v.currentNode = args[2]
+			}
+			return nil
+		}
+	case n.Name() == FunctionCoalesce:
+		v.currentNode = simplifyCoalesce(args)
+		return nil
+	case n.Name() == FunctionLength || IsFunction(n.Name()):
+		values := make([]any, 0, len(args))
+		for _, arg := range args {
+			value, ok := arg.(ValueNode)
+			if !ok {
+				break
+			}
+			values = append(values, value.Value())
+		}
+		if len(values) == len(args) {
+			if result, err := ExecFunction(n.Name(), values); err == nil {
+				v.currentNode = Value(result)
+				return nil
+			}
+		}
+	}
+	v.currentNode = Function(n.Name(), args...)
+	return nil
+}
+
+// VisitExtension keeps the extension node as is, it is opaque to the rules.
+func (v *simplifyVisitor) VisitExtension(n ExtensionNode) error {
+	v.currentNode = n
+	return nil
+}
+
+// simplifyCoalesce drops the NULL values and the arguments after the first value which is not NULL.
+func simplifyCoalesce(args []Visitable) Visitable {
+	kept := make([]Visitable, 0, len(args))
+	for _, arg := range args {
+		value, ok := arg.(ValueNode)
+		if ok && value.Value() == nil {
+			continue
+		}
+		kept = append(kept, arg)
+		if ok {
+			break
+		}
+	}
+	switch len(kept) {
+	case 0:
+		return Value(nil)
+	case 1:
+		return kept[0]
+	}
+	return Function(FunctionCoalesce, kept...)
+}
@@ -0,0 +1,95 @@
+package specification
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+func TestSimplify(t *testing.T) {
+	root := GlobalScope()
+	a := GreaterThan(Field(root, "age"), Value(18))
+	b := Field(root, "active")
+	price := Field(root, "price")
+
+	for _, tc := range []struct {
+		name     string
+		ast      Visitable
+		expected Visitable
+	}{
+		{"constant comparison", LessThan(Value(1), Value(2)), Value(true)},
+		{"constant arithmetic", GreaterThan(price, Mul(Value(2), Add(Value(3), Value(4)))), GreaterThan(price, Value(14))},
+		{"double negation", Not(Not(a)), a},
+		{"negated constant", Not(Value(false)), Value(true)},
+		{"neutral and", And(Value(true), a), a},
+		{"dominant and", And(a, Equal(Value(1), Value(2))), Value(false)},
+		{"neutral or", Or(a, Value(false)), a},
+		{"dominant or", Or(Value(true), a), Value(true)},
+		{"null and", And(Value(nil), a), And(Value(nil), a)},
+		{"idempotence", And(a, a), a},
+		{"absorption and", And(a, Or(b, a)), a},
+		{"absorption or", Or(And(a, b), a), a},
+		{"constant null check", IsNull(Coalesce(Value(nil), Value(1))), Value(false)},
+		{"coalesce", Coalesce(price, Value(nil), Value(0), Field(root, "bonus")), Coalesce(price, Value(0))},
+		{"length", Equal(Length(Value("abc")), Value(3)), Value(true)},
+		{"case", Case(GreaterThan(Value(2), Value(1)), price, Value(0)), price},
+		{"null case", Case(Value(nil), price, Value(0)), Value(0)},
+		{"current time", LessThan(Now(), Add(Now(), Value(1))), LessThan(Now(), Add(Now(), Value(1)))},
+		{"collection", Wildcard(Object(root, "items"), And(Value(true), Field(Item(), "gift"))),
+			Wildcard(Object(root, "items"), Field(Item(), "gift"))},
+	} {
+		simplified, err := Simplify(tc.ast, operators.NewDefaultRegistry())
+		if err != nil {
+			t.Fatalf("%s: Simplify failed: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(simplified, tc.expected) {
+			t.Errorf("%s: expected %#v, got %#v", tc.name, tc.expected, simplified)
+		}
+	}
+}
+
+func TestSimplifyKeepsUnsupported(t *testing.T) {
+	// Division by zero is reported by the evaluation or by the database, not by the simplifier
+	ast := Equal(Div(Value(1), Value(0)), Value(1))
+	simplified, err := Simplify(ast, operators.NewDefaultRegistry())
+	if err != nil {
+		t.Fatalf("Simplify failed: %v", err)
+	}
+	if !reflect.DeepEqual(simplified, ast) {
+		t.Errorf("Expected %#v, got %#v", ast, simplified)
+	}
+}
+
+func TestPartialEvaluate(t *testing.T) {
+	root := GlobalScope()
+	tenant := Equal(Field(Object(root, "session"), "tenant_id"), Field(root, "tenant_id"))
+	age := GreaterThan(Field(root, "age"), Value(18))
+	ownedItem := Wildcard(Object(root, "items"), Equal(Field(Item(), "owner_id"), Field(Object(root, "session"), "user_id")))
+	partial := testContext{"session": testContext{"tenant_id": 7, "user_id": 42}}
+
+	for _, tc := range []struct {
+		name     string
+		ast      Visitable
+		context  testContext
+		expected Visitable
+	}{
+		{"residual", And(tenant, age), testContext{"session": partial["session"], "tenant_id": 7}, age},
+		{"violated", And(tenant, age), testContext{"session": partial["session"], "tenant_id": 8}, Value(false)},
+		{"unknown", And(tenant, age), partial, And(Equal(Value(7), Field(root, "tenant_id")), age)},
+		{"item fields are kept", ownedItem, partial,
+			Wildcard(Object(root, "items"), Equal(Field(Item(), "owner_id"), Value(42)))},
+	} {
+		residual, err := PartialEvaluate(tc.ast, tc.context)
+		if err != nil {
+			t.Fatalf("%s: PartialEvaluate failed: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(residual, tc.expected) {
+			t.Errorf("%s: expected %#v, got %#v", tc.name, tc.expected, residual)
+		}
+	}
+
+	if _, err := PartialEvaluate(tenant, testContext{"session": 1}); err == nil {
+		t.Error("Expected error for the field of a value which is not an object")
+	}
+}
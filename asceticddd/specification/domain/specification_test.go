@@ -338,6 +338,97 @@ func TestCollectionWildcard(t *testing.T) {
 	}
 }
 
+func TestCollectionSelectors(t *testing.T) {
+	collection := NewCollectionContext([]Context{
+		testContext{"score": 90}, testContext{"score": 75}, testContext{"score": 85}, testContext{"score": 60},
+	})
+	rootCtx := testContext{"items": collection}
+	itemsObj := Object(GlobalScope(), "items")
+	predicate := GreaterThan(Field(Item(), "score"), Value(80))
+
+	for _, tc := range []struct {
+		node     CollectionNode
+		expected bool
+	}{
+		{Index(itemsObj, 0, predicate), true},
+		{Index(itemsObj, 1, predicate), false},
+		{Index(itemsObj, -1, predicate), false},
+		{Index(itemsObj, -2, predicate), true},
+		{Index(itemsObj, 10, predicate), false},
+		{Slice(itemsObj, 1, 2, predicate), false},
+		{Slice(itemsObj, 1, 3, predicate), true},
+		{Slice(itemsObj, -1, 10, predicate), false},
+		{NewCollectionNode(itemsObj, "2:", predicate), true},
+		{NewCollectionNode(itemsObj, ":-3", predicate), true},
+		{Slice(itemsObj, 3, 1, predicate), false},
+	} {
+		visitor := NewEvaluateVisitor(rootCtx, operators.NewDefaultRegistry())
+		err := tc.node.Accept(visitor)
+		if err != nil {
+			t.Fatalf("[%s]: Accept failed: %v", tc.node.Name(), err)
+		}
+		result, err := visitor.Result()
+		if err != nil {
+			t.Fatalf("[%s]: Result failed: %v", tc.node.Name(), err)
+		}
+		if result != tc.expected {
+			t.Errorf("[%s]: Expected %v, got %v", tc.node.Name(), tc.expected, result)
+		}
+	}
+
+	for _, selector := range []string{"", "a", "1:2:3", "1.5"} {
+		err := NewCollectionNode(itemsObj, selector, predicate).Accept(NewEvaluateVisitor(rootCtx, operators.NewDefaultRegistry()))
+		if err == nil {
+			t.Errorf("[%s]: Expected error of unsupported selector", selector)
+		}
+	}
+}
+
+func TestCollectionAggregates(t *testing.T) {
+	collection := NewCollectionContext([]Context{
+		testContext{"score": 90, "active": true},
+		testContext{"score": nil, "active": true},
+		testContext{"score": 75, "active": false},
+		testContext{"score": 85, "active": nil},
+	})
+	rootCtx := testContext{"items": collection, "empty": NewCollectionContext(nil)}
+	itemsObj := Object(GlobalScope(), "items")
+	emptyObj := Object(GlobalScope(), "empty")
+	score := Field(Item(), "score")
+
+	for _, tc := range []struct {
+		name     string
+		node     Visitable
+		expected any
+	}{
+		{"count", CountOf(itemsObj, Field(Item(), "active")), 2},
+		{"count of empty", CountOf(emptyObj, Field(Item(), "active")), 0},
+		{"count comparison", GreaterThanEqual(CountOf(itemsObj, GreaterThan(score, Value(80))), Value(2)), true},
+		{"sum", SumOf(itemsObj, score), 250},
+		{"sum of expression", SumOf(itemsObj, Mul(score, Value(2))), 500},
+		{"sum of empty", SumOf(emptyObj, score), nil},
+		{"min", MinOf(itemsObj, score), 75},
+		{"max", MaxOf(itemsObj, score), 90},
+		{"max of empty", MaxOf(emptyObj, score), nil},
+		// The item of the outer wildcard is restored after the inner aggregate
+		{"nested", Wildcard(itemsObj, Coalesce(And(Equal(score, MaxOf(itemsObj, score)), Field(Item(), "active")), Value(false))), true},
+	} {
+		visitor := NewEvaluateVisitor(rootCtx, operators.NewDefaultRegistry())
+		err := tc.node.Accept(visitor)
+		if err != nil {
+			t.Fatalf("%s: Accept failed: %v", tc.name, err)
+		}
+		if visitor.CurrentValue() != tc.expected {
+			t.Errorf("%s: Expected %v, got %v", tc.name, tc.expected, visitor.CurrentValue())
+		}
+	}
+
+	err := Function(FunctionSum, Field(GlobalScope(), "items")).Accept(NewEvaluateVisitor(rootCtx, operators.NewDefaultRegistry()))
+	if err == nil {
+		t.Error("Expected error of sum of not a collection")
+	}
+}
+
 func TestCollectionAllFalse(t *testing.T) {
 	item1 := testContext{"score": 70}
 	item2 := testContext{"score": 75}
@@ -405,3 +496,45 @@ func TestTypeCheckingInComparison(t *testing.T) {
 		t.Errorf("Expected false, got %v", result)
 	}
 }
+
+func TestFloatTolerance(t *testing.T) {
+	ctx := testContext{"price": 0.30000000000000004}
+	expression := Equal(Field(GlobalScope(), "price"), Value(0.3))
+
+	visitor := NewEvaluateVisitor(ctx, operators.NewDefaultRegistry())
+	if err := expression.Accept(visitor); err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	if result, _ := visitor.Result(); result != false {
+		t.Errorf("Expected exact comparison to fail, got %v", result)
+	}
+
+	registry := operators.NewDefaultRegistry()
+	operators.RegisterFloatTolerance(registry, operators.FloatTolerance{Absolute: 1e-9})
+	visitor = NewEvaluateVisitor(ctx, registry)
+	if err := expression.Accept(visitor); err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	if result, _ := visitor.Result(); result != true {
+		t.Errorf("Expected tolerant comparison to succeed, got %v", result)
+	}
+}
+
+func TestObjectNavigationOfNonObject(t *testing.T) {
+	visitor := NewEvaluateVisitor(testContext{"user": "Alice"}, operators.NewDefaultRegistry())
+
+	err := Field(Object(GlobalScope(), "user"), "name").Accept(visitor)
+	if err == nil || err.Error() != "user is string, not an object" {
+		t.Errorf("Expected error of non-object, got %v", err)
+	}
+}
+
+func TestCollectionWildcardNonBoolPredicate(t *testing.T) {
+	collection := NewCollectionContext([]Context{testContext{"score": 90}})
+	visitor := NewEvaluateVisitor(testContext{"items": collection}, operators.NewDefaultRegistry())
+
+	err := Wildcard(Object(GlobalScope(), "items"), Field(Item(), "score")).Accept(visitor)
+	if err == nil || err.Error() != "wildcard predicate evaluated to int, not bool" {
+		t.Errorf("Expected error of non-bool predicate, got %v", err)
+	}
+}
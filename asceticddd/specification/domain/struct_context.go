@@ -0,0 +1,171 @@
+package specification
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+// StructContext is the Context of a struct, so that specifications are matched against domain entities
+// without converting them to maps.
+//
+// Fields are found by the name of the json tag, or by the field name if there is no tag,
+// fields of exported embedded structs are promoted and fields tagged "-" are skipped.
+// Values are resolved as follows:
+//   - pointers and interfaces are dereferenced, nil is NULL
+//   - nested structs are StructContext
+//   - slices and arrays of structs are CollectionContext, other slices are values, e.g. of IN
+//   - time.Time and Value Objects implementing the interfaces of operators are values, not contexts
+//   - big.Rat is the *big.Rat value, as the operators of decimals are registered for it
+type StructContext struct {
+	value reflect.Value
+}
+
+// NewStructContext creates the context of the struct or of the pointer to the struct.
+func NewStructContext(s any) StructContext {
+	return StructContext{value: reflect.ValueOf(s)}
+}
+
+func (c StructContext) Get(key string) (any, error) {
+	v := c.value
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct", v.Type())
+	}
+	index, ok := structFields(v.Type())[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s of %s", ErrKeyNotFound, key, v.Type())
+	}
+	field, err := v.FieldByIndexErr(index)
+	if err != nil {
+		// The field is promoted through a nil embedded pointer
+		return nil, nil
+	}
+	return structValue(field), nil
+}
+
+var structFieldsCache sync.Map // map[reflect.Type]map[string][]int
+
+// structFields returns the indexes of the fields by their names, the fields of the shallowest depth win.
+func structFields(t reflect.Type) map[string][]int {
+	if fields, ok := structFieldsCache.Load(t); ok {
+		return fields.(map[string][]int)
+	}
+	fields := make(map[string][]int)
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || !isPromotedThroughExported(t, f.Index) {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			if f.Anonymous && indirectType(f.Type).Kind() == reflect.Struct {
+				// Fields of the embedded struct are promoted
+				continue
+			}
+			name = f.Name
+		}
+		if prev, ok := fields[name]; ok && len(prev) <= len(f.Index) {
+			continue
+		}
+		fields[name] = f.Index
+	}
+	structFieldsCache.Store(t, fields)
+	return fields
+}
+
+// isPromotedThroughExported reports whether the embedded structs of the promoted field are exported,
+// otherwise the value of the field can not be read by reflection.
+func isPromotedThroughExported(t reflect.Type, index []int) bool {
+	for depth := 1; depth < len(index); depth++ {
+		if !t.FieldByIndex(index[:depth]).IsExported() {
+			return false
+		}
+	}
+	return true
+}
+
+func structValue(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil
+	}
+	if v.Type() == ratType {
+		r := new(big.Rat)
+		if v.CanAddr() {
+			return r.Set(v.Addr().Interface().(*big.Rat))
+		}
+		value := v.Interface().(big.Rat)
+		return r.Set(&value)
+	}
+	if isValueObject(v.Type()) {
+		return v.Interface()
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return structValue(v.Elem())
+	case reflect.Struct:
+		return StructContext{value: v}
+	case reflect.Slice, reflect.Array:
+		elem := indirectType(v.Type().Elem())
+		if elem.Kind() != reflect.Struct || isValueObject(elem) {
+			return v.Interface()
+		}
+		items := make([]Context, v.Len())
+		for i := range v.Len() {
+			items[i] = StructContext{value: v.Index(i)}
+		}
+		return NewCollectionContext(items)
+	}
+	return v.Interface()
+}
+
+var (
+	timeType                    = reflect.TypeFor[time.Time]()
+	ratType                     = reflect.TypeFor[big.Rat]()
+	equalOperandType            = reflect.TypeFor[operators.EqualOperand]()
+	greaterThanOperandType      = reflect.TypeFor[operators.GreaterThanOperand]()
+	greaterThanEqualOperandType = reflect.TypeFor[operators.GreaterThanEqualOperand]()
+	lessThanOperandType         = reflect.TypeFor[operators.LessThanOperand]()
+	lessThanEqualOperandType    = reflect.TypeFor[operators.LessThanEqualOperand]()
+)
+
+// isValueObject reports whether the struct is compared as a whole instead of being a context of its fields.
+func isValueObject(t reflect.Type) bool {
+	if t == timeType || t == ratType {
+		return true
+	}
+	for _, operand := range []reflect.Type{
+		equalOperandType,
+		greaterThanOperandType,
+		greaterThanEqualOperandType,
+		lessThanOperandType,
+		lessThanEqualOperandType,
+	} {
+		if t.Implements(operand) {
+			return true
+		}
+	}
+	return false
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,133 @@
+package specification
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+type testMoney struct {
+	Amount int
+}
+
+func (m testMoney) Equal(other operators.EqualOperand) bool {
+	o, ok := other.(testMoney)
+	return ok && m.Amount == o.Amount
+}
+
+type testAddress struct {
+	City string `json:"city"`
+}
+
+type testLine struct {
+	Price float64 `json:"price"`
+	Qty   int     `json:"qty"`
+}
+
+type TestEntity struct {
+	ID int `json:"id"`
+}
+
+type testHidden struct {
+	Hidden int `json:"hidden"`
+}
+
+type testOrder struct {
+	TestEntity
+	testHidden
+	Status    string       `json:"status,omitempty"`
+	Note      *string      `json:"note"`
+	Secret    string       `json:"-"`
+	Address   *testAddress `json:"address"`
+	Lines     []*testLine  `json:"lines"`
+	Tags      []string     `json:"tags"`
+	Total     testMoney    `json:"total"`
+	CreatedAt time.Time    `json:"created_at"`
+	Untagged  bool
+}
+
+func evaluateStruct(t *testing.T, ctx Context, node Visitable) bool {
+	t.Helper()
+	visitor := NewEvaluateVisitor(ctx, operators.NewDefaultRegistry())
+	if err := node.Accept(visitor); err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	result, err := visitor.Result()
+	if err != nil {
+		t.Fatalf("Result failed: %v", err)
+	}
+	return result
+}
+
+func TestStructContext(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	order := &testOrder{
+		TestEntity: TestEntity{ID: 7},
+		Status:     "paid",
+		Address:    &testAddress{City: "Berlin"},
+		Lines:      []*testLine{{Price: 10, Qty: 1}, {Price: 150, Qty: 2}},
+		Tags:       []string{"gift"},
+		Total:      testMoney{Amount: 310},
+		CreatedAt:  created,
+		Untagged:   true,
+	}
+	ctx := NewStructContext(order)
+	root := GlobalScope()
+	lines := Object(root, "lines")
+
+	for _, tc := range []struct {
+		name     string
+		node     Visitable
+		expected bool
+	}{
+		{"json tag", Equal(Field(root, "status"), Value("paid")), true},
+		{"promoted field", Equal(Field(root, "id"), Value(7)), true},
+		{"untagged field", Equal(Field(root, "Untagged"), Value(true)), true},
+		{"nested pointer", Equal(Field(Object(root, "address"), "city"), Value("Berlin")), true},
+		{"nil pointer", IsNull(Field(root, "note")), true},
+		{"slice of structs", Wildcard(lines, GreaterThan(Field(Item(), "price"), Value(100.0))), true},
+		{"every", Every(lines, GreaterThan(Field(Item(), "qty"), Value(1))), false},
+		{"index", Index(lines, 0, Equal(Field(Item(), "qty"), Value(1))), true},
+		{"slice of values", In(Value("gift"), Field(root, "tags")), true},
+		{"value object", Equal(Field(root, "total"), Value(testMoney{Amount: 310})), true},
+		{"time", Equal(Field(root, "created_at"), Value(created)), true},
+	} {
+		if result := evaluateStruct(t, ctx, tc.node); result != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, result)
+		}
+	}
+
+	for _, key := range []string{"Secret", "Status", "TestEntity", "hidden", "missing"} {
+		if _, err := ctx.Get(key); !errors.Is(err, ErrKeyNotFound) {
+			t.Errorf("%s: expected ErrKeyNotFound, got %v", key, err)
+		}
+	}
+
+	if _, err := NewStructContext(42).Get("id"); err == nil {
+		t.Error("expected error of not a struct")
+	}
+}
+
+func TestStructContextDecimal(t *testing.T) {
+	type invoice struct {
+		Amount   big.Rat  `json:"amount"`
+		Discount *big.Rat `json:"discount"`
+		Tax      *big.Rat `json:"tax"`
+	}
+	inv := invoice{Discount: big.NewRat(1, 10)}
+	inv.Amount.SetFrac64(3, 10)
+	root := GlobalScope()
+	expected, _ := new(big.Rat).SetString("0.4")
+
+	for _, ctx := range []Context{NewStructContext(inv), NewStructContext(&inv)} {
+		if !evaluateStruct(t, ctx, Equal(Add(Field(root, "amount"), Field(root, "discount")), Value(expected))) {
+			t.Error("Expected 0.3 + 0.1 = 0.4")
+		}
+		if !evaluateStruct(t, ctx, IsNull(Field(root, "tax"))) {
+			t.Error("Expected nil *big.Rat to be NULL")
+		}
+	}
+}
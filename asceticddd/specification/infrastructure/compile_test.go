@@ -140,6 +140,25 @@ func TestCompileToSQLArithmetic(t *testing.T) {
 	}
 }
 
+func TestCompileToSQLRightOperandPrecedence(t *testing.T) {
+	// a - (b - c) is not a - b - c
+	root := s.GlobalScope()
+	expr := s.Equal(
+		s.Sub(s.Field(root, "a"), s.Sub(s.Field(root, "b"), s.Field(root, "c"))),
+		s.Sub(s.Sub(s.Field(root, "a"), s.Field(root, "b")), s.Field(root, "c")),
+	)
+
+	sql, _, err := CompileToSQL(expr)
+	if err != nil {
+		t.Fatalf("CompileToSQL failed: %v", err)
+	}
+
+	expected := "a - (b - c) = a - b - c"
+	if sql != expected {
+		t.Errorf("Expected SQL: %s, got: %s", expected, sql)
+	}
+}
+
 func TestCompileToSQLNegation(t *testing.T) {
 	// Negation: NOT (age < 18)
 	expr := s.Not(
@@ -0,0 +1,165 @@
+package specification
+
+import (
+	"fmt"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+// Dialect defines the SQL syntax of the database of the columns the specification is compiled for,
+// see WithDialect. PostgresDialect is the default. Fields are compiled as is, or by WithFieldResolver.
+// SQL compares numbers exactly in all dialects.
+type Dialect interface {
+	// Placeholder returns the placeholder of the param by its number, starting from 1.
+	Placeholder(number int) string
+	// Cast returns the placeholder of the decimal, "numeric", or of the duration, "interval",
+	// cast to the SQL type, or the error if the database has no such type.
+	Cast(placeholder, sqlType string) (string, error)
+	// BoolLiteral returns the literal of the boolean, e.g. of IN with the empty list.
+	BoolLiteral(value bool) string
+	// Operator returns the SQL of the infix operator, or the error if the database has no such operator.
+	Operator(op operators.Operator) (string, error)
+	// ArrayParams reports whether IN and NOT IN compare with the array param, "status = ANY($1)",
+	// otherwise the list is expanded to the params of its elements, "status IN (?, ?)".
+	ArrayParams() bool
+	// Unnest returns the FROM item of the elements of the embedded collection under the alias,
+	// or the error if the database has no arrays.
+	Unnest(collection, alias string) (string, error)
+	// LengthFunction returns the function of the number of the characters of the text.
+	LengthFunction() string
+	// PatternMatch returns the operator of the match by LIKE or by the regular expression, "~",
+	// see FunctionPattern, and the clause following the pattern, if any.
+	PatternMatch(sqlOp string) (operator, clause string)
+}
+
+// PostgresDialect compiles to the syntax of PostgreSQL, the arrays are params and the collections are unnested.
+type PostgresDialect struct{}
+
+func (d PostgresDialect) Placeholder(number int) string {
+	return fmt.Sprintf("$%d", number)
+}
+
+func (d PostgresDialect) Cast(placeholder, sqlType string) (string, error) {
+	return placeholder + "::" + sqlType, nil
+}
+
+func (d PostgresDialect) BoolLiteral(value bool) string {
+	return fmt.Sprint(value)
+}
+
+func (d PostgresDialect) Operator(op operators.Operator) (string, error) {
+	return string(op), nil
+}
+
+func (d PostgresDialect) ArrayParams() bool {
+	return true
+}
+
+func (d PostgresDialect) Unnest(collection, alias string) (string, error) {
+	return fmt.Sprintf("unnest(%s) AS %s", collection, alias), nil
+}
+
+func (d PostgresDialect) LengthFunction() string {
+	return "length"
+}
+
+func (d PostgresDialect) PatternMatch(sqlOp string) (operator, clause string) {
+	return sqlOp, ""
+}
+
+// MySQLDialect compiles to the syntax of MySQL 8. Durations, embedded collections, || and # are not supported,
+// the collections are compiled as the tables of WithSchema. LIKE is case-insensitive with the case-insensitive collations.
+type MySQLDialect struct{}
+
+func (d MySQLDialect) Placeholder(number int) string {
+	return "?"
+}
+
+func (d MySQLDialect) Cast(placeholder, sqlType string) (string, error) {
+	if sqlType == "numeric" {
+		return fmt.Sprintf("CAST(%s AS DECIMAL(65, 30))", placeholder), nil
+	}
+	return "", fmt.Errorf("type %s is not supported by MySQL", sqlType)
+}
+
+func (d MySQLDialect) BoolLiteral(value bool) string {
+	return fmt.Sprint(value)
+}
+
+func (d MySQLDialect) Operator(op operators.Operator) (string, error) {
+	switch op {
+	case operators.OperatorConcat:
+		return "", fmt.Errorf("operator %s is not supported by MySQL, it is OR there", op)
+	case operators.OperatorBitXor:
+		return "", fmt.Errorf("operator %s is not supported by MySQL", op)
+	}
+	return string(op), nil
+}
+
+func (d MySQLDialect) ArrayParams() bool {
+	return false
+}
+
+func (d MySQLDialect) Unnest(collection, alias string) (string, error) {
+	return "", fmt.Errorf("embedded collection %s is not supported by MySQL, map it to the table by WithSchema", collection)
+}
+
+func (d MySQLDialect) LengthFunction() string {
+	return "CHAR_LENGTH"
+}
+
+func (d MySQLDialect) PatternMatch(sqlOp string) (operator, clause string) {
+	if sqlOp == "~" {
+		return "REGEXP", ""
+	}
+	return sqlOp, ""
+}
+
+// SQLiteDialect compiles to the syntax of SQLite. Durations, embedded collections and # are not supported,
+// the collections are compiled as the tables of WithSchema. Booleans are 1 and 0,
+// REGEXP requires the regexp function to be registered, and LIKE is case-insensitive for ASCII.
+type SQLiteDialect struct{}
+
+func (d SQLiteDialect) Placeholder(number int) string {
+	return "?"
+}
+
+func (d SQLiteDialect) Cast(placeholder, sqlType string) (string, error) {
+	if sqlType == "numeric" {
+		return fmt.Sprintf("CAST(%s AS NUMERIC)", placeholder), nil
+	}
+	return "", fmt.Errorf("type %s is not supported by SQLite", sqlType)
+}
+
+func (d SQLiteDialect) BoolLiteral(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+func (d SQLiteDialect) Operator(op operators.Operator) (string, error) {
+	if op == operators.OperatorBitXor {
+		return "", fmt.Errorf("operator %s is not supported by SQLite", op)
+	}
+	return string(op), nil
+}
+
+func (d SQLiteDialect) ArrayParams() bool {
+	return false
+}
+
+func (d SQLiteDialect) Unnest(collection, alias string) (string, error) {
+	return "", fmt.Errorf("embedded collection %s is not supported by SQLite, map it to the table by WithSchema", collection)
+}
+
+func (d SQLiteDialect) LengthFunction() string {
+	return "length"
+}
+
+func (d SQLiteDialect) PatternMatch(sqlOp string) (operator, clause string) {
+	if sqlOp == "~" {
+		return "REGEXP", ""
+	}
+	return sqlOp, ` ESCAPE '\'`
+}
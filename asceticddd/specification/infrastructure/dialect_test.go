@@ -0,0 +1,141 @@
+package specification
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+func TestCompileToSQLDialects(t *testing.T) {
+	age := s.Field(s.GlobalScope(), "age")
+	name := s.Field(s.GlobalScope(), "name")
+	status := s.Field(s.GlobalScope(), "status")
+	price := s.Field(s.GlobalScope(), "price")
+	schema := NewSchemaRegistry("orders").RegisterRelational("Items", "order_items", "order_id", "id")
+	items := s.Wildcard(s.Object(s.GlobalScope(), "Items"), s.Field(s.Item(), "Active"))
+
+	tests := []struct {
+		name     string
+		expr     s.Visitable
+		dialect  Dialect
+		expected string
+		params   []any
+	}{
+		{
+			name:     "postgres in",
+			expr:     s.And(s.GreaterThanEqual(age, s.Value(18)), s.In(status, s.Value([]string{"new", "paid"}))),
+			dialect:  PostgresDialect{},
+			expected: "age >= $1 AND status = ANY($2)",
+			params:   []any{18, []string{"new", "paid"}},
+		},
+		{
+			name:     "mysql in",
+			expr:     s.And(s.GreaterThanEqual(age, s.Value(18)), s.In(status, s.Value([]string{"new", "paid"}))),
+			dialect:  MySQLDialect{},
+			expected: "age >= ? AND status IN (?, ?)",
+			params:   []any{18, "new", "paid"},
+		},
+		{
+			name:     "sqlite not in",
+			expr:     s.NotIn(status, s.Value([]string{"new"})),
+			dialect:  SQLiteDialect{},
+			expected: "status NOT IN (?)",
+			params:   []any{"new"},
+		},
+		{
+			name:     "mysql empty in",
+			expr:     s.Or(s.In(status, s.Value([]string{})), s.NotIn(status, s.Value([]string{}))),
+			dialect:  MySQLDialect{},
+			expected: "false OR true",
+			params:   []any{},
+		},
+		{
+			name:     "sqlite empty in",
+			expr:     s.In(status, s.Value([]string{})),
+			dialect:  SQLiteDialect{},
+			expected: "0",
+			params:   []any{},
+		},
+		{
+			name:     "mysql decimal",
+			expr:     s.LessThanEqual(price, s.Value(big.NewRat(199, 2))),
+			dialect:  MySQLDialect{},
+			expected: "price <= CAST(? AS DECIMAL(65, 30))",
+			params:   []any{"99.5"},
+		},
+		{
+			name:     "sqlite decimal",
+			expr:     s.LessThanEqual(price, s.Value(big.NewRat(199, 2))),
+			dialect:  SQLiteDialect{},
+			expected: "price <= CAST(? AS NUMERIC)",
+			params:   []any{"99.5"},
+		},
+		{
+			name:     "mysql functions",
+			expr:     s.And(s.Match(name, s.Value("A.*")), s.GreaterThan(s.Length(name), s.Value(3))),
+			dialect:  MySQLDialect{},
+			expected: "name REGEXP ? AND CHAR_LENGTH(name) > ?",
+			params:   []any{"^(?:A.*)$", 3},
+		},
+		{
+			name:     "sqlite like",
+			expr:     s.StartsWith(name, s.Value("50%")),
+			dialect:  SQLiteDialect{},
+			expected: `name LIKE ? ESCAPE '\'`,
+			params:   []any{`50\%%`},
+		},
+		{
+			name:     "mysql relational collection",
+			expr:     items,
+			dialect:  MySQLDialect{},
+			expected: "EXISTS (SELECT 1 FROM order_items AS item_1 WHERE item_1.order_id = orders.id AND item_1.Active)",
+			params:   []any{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, params, err := CompileToSQL(tt.expr, WithDialect(tt.dialect), WithSchema(schema))
+			if err != nil {
+				t.Fatalf("CompileToSQL failed: %v", err)
+			}
+			if sql != tt.expected {
+				t.Errorf("Expected SQL: %s, got: %s", tt.expected, sql)
+			}
+			if len(params) != len(tt.params) || (len(params) > 0 && !reflect.DeepEqual(params, tt.params)) {
+				t.Errorf("Expected params: %v, got: %v", tt.params, params)
+			}
+		})
+	}
+}
+
+func TestCompileToSQLDialectsUnsupported(t *testing.T) {
+	createdAt := s.Field(s.GlobalScope(), "created_at")
+	name := s.Field(s.GlobalScope(), "name")
+	lines := s.Wildcard(s.Object(s.GlobalScope(), "lines"), s.Field(s.Item(), "gift"))
+
+	tests := []struct {
+		name    string
+		expr    s.Visitable
+		dialect Dialect
+	}{
+		{"mysql duration", s.LessThan(createdAt, s.Value(time.Hour)), MySQLDialect{}},
+		{"sqlite duration", s.LessThan(createdAt, s.Value(time.Hour)), SQLiteDialect{}},
+		{"mysql concat", s.Equal(s.NewInfixNode(name, operators.OperatorConcat, name, s.LeftAssociative), s.Value("aa")), MySQLDialect{}},
+		{"mysql embedded collection", lines, MySQLDialect{}},
+		{"sqlite embedded collection", lines, SQLiteDialect{}},
+		{"mysql in of field", s.In(name, s.Field(s.GlobalScope(), "names")), MySQLDialect{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := CompileToSQL(tt.expr, WithDialect(tt.dialect)); err == nil {
+				t.Errorf("Expected error for %#v", tt.expr)
+			}
+		})
+	}
+}
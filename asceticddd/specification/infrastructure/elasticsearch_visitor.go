@@ -0,0 +1,404 @@
+package specification
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+var ErrUnsupportedByElasticsearch = errors.New("not supported by Elasticsearch query DSL")
+
+var elasticsearchRangeOps = map[operators.Operator]string{
+	operators.OperatorGt:  "gt",
+	operators.OperatorGte: "gte",
+	operators.OperatorLt:  "lt",
+	operators.OperatorLte: "lte",
+}
+
+// Operators of the comparison with swapped operands.
+var swappedOps = map[operators.Operator]operators.Operator{
+	operators.OperatorEq:  operators.OperatorEq,
+	operators.OperatorNe:  operators.OperatorNe,
+	operators.OperatorGt:  operators.OperatorLt,
+	operators.OperatorGte: operators.OperatorLte,
+	operators.OperatorLt:  operators.OperatorGt,
+	operators.OperatorLte: operators.OperatorGte,
+}
+
+// CompileToElasticsearch compiles AST to the query of Elasticsearch (and OpenSearch) Query DSL,
+// to be used as the "query" of a search request.
+func CompileToElasticsearch(exp s.Visitable, opts ...ElasticsearchVisitorOption) (map[string]any, error) {
+	v := NewElasticsearchVisitor(opts...)
+	if err := exp.Accept(v); err != nil {
+		return nil, err
+	}
+	return v.Result()
+}
+
+// ElasticsearchVisitor compiles AST to Elasticsearch Query DSL:
+//
+//	And(Equal(Field(GlobalScope(), "status"), Value("active")),
+//		Wildcard(Object(GlobalScope(), "items"), GreaterThan(Field(Item(), "price"), Value(10))))
+//
+// becomes
+//
+//	{"bool": {"filter": [
+//		{"term": {"status": "active"}},
+//		{"nested": {"path": "items", "query": {"range": {"items.price": {"gt": 10}}}}}
+//	]}}
+//
+// Logical operators compile to bool queries in filter context, comparisons to term and range,
+// IS NULL to a missing field, wildcards to nested queries, so collections must be mapped as nested.
+// Text fields must be matched by their keyword sub-fields, term queries are not analyzed.
+// Comparisons of two fields and arithmetic need scripts and are ErrUnsupportedByElasticsearch.
+type ElasticsearchVisitor struct {
+	nestedPaths []string
+	query       map[string]any
+	emitters    emitters[*ElasticsearchVisitor]
+}
+
+type ElasticsearchVisitorOption func(*ElasticsearchVisitor)
+
+// WithElasticsearchEmitter registers the emitter of the extension nodes of the concrete type N,
+// which compiles the node to the query by the helpers of the visitor instead of its fallback, e.g.
+//
+//	infra.WithElasticsearchEmitter(func(v *infra.ElasticsearchVisitor, n WithinRadius) error {
+//		path, err := v.FieldPath(n.Location)
+//		if err != nil {
+//			return err
+//		}
+//		v.SetQuery(map[string]any{"geo_distance": map[string]any{"distance": n.Distance, path: n.Center}})
+//		return nil
+//	})
+func WithElasticsearchEmitter[N s.ExtensionNode](emit func(v *ElasticsearchVisitor, n N) error) ElasticsearchVisitorOption {
+	return func(v *ElasticsearchVisitor) {
+		registerEmitter(&v.emitters, emit)
+	}
+}
+
+func NewElasticsearchVisitor(opts ...ElasticsearchVisitorOption) *ElasticsearchVisitor {
+	v := &ElasticsearchVisitor{}
+	for i := range opts {
+		opts[i](v)
+	}
+	return v
+}
+
+func (v *ElasticsearchVisitor) Result() (map[string]any, error) {
+	if v.query == nil {
+		return nil, fmt.Errorf("%w: nothing to compile", ErrUnsupportedByElasticsearch)
+	}
+	return v.query, nil
+}
+
+func (v *ElasticsearchVisitor) VisitGlobalScope(n s.GlobalScopeNode) error {
+	return fmt.Errorf("%w: %T as predicate", ErrUnsupportedByElasticsearch, n)
+}
+
+func (v *ElasticsearchVisitor) VisitObject(n s.ObjectNode) error {
+	return fmt.Errorf("%w: %T as predicate", ErrUnsupportedByElasticsearch, n)
+}
+
+// VisitCollection compiles the wildcard to the nested query of the collection,
+// fields of Item() are prefixed with the path of the collection.
+func (v *ElasticsearchVisitor) VisitCollection(n s.CollectionNode) error {
+	if _, ok := n.Parent().(s.ObjectNode); !ok {
+		return fmt.Errorf("%w: wildcard without collection", ErrUnsupportedByElasticsearch)
+	}
+	if !n.IsWildcard() {
+		// Nested documents are matched regardless of their position.
+		return fmt.Errorf("%w: selector [%s] of collection", ErrUnsupportedByElasticsearch, n.Name())
+	}
+	path, err := v.objectPath(n.Parent())
+	if err != nil {
+		return err
+	}
+	v.nestedPaths = append(v.nestedPaths, path)
+	err = n.Predicate().Accept(v)
+	v.nestedPaths = v.nestedPaths[:len(v.nestedPaths)-1]
+	if err != nil {
+		return err
+	}
+	v.query = map[string]any{"nested": map[string]any{"path": path, "query": v.query}}
+	return nil
+}
+
+func (v *ElasticsearchVisitor) VisitItem(n s.ItemNode) error {
+	return fmt.Errorf("%w: %T as predicate", ErrUnsupportedByElasticsearch, n)
+}
+
+// VisitField compiles the field visited as a predicate, i.e. a boolean one.
+func (v *ElasticsearchVisitor) VisitField(n s.FieldNode) error {
+	path, err := v.fieldPath(n)
+	if err != nil {
+		return err
+	}
+	v.query = termQuery(path, true)
+	return nil
+}
+
+func (v *ElasticsearchVisitor) VisitValue(n s.ValueNode) error {
+	return fmt.Errorf("%w: %T as predicate", ErrUnsupportedByElasticsearch, n)
+}
+
+func (v *ElasticsearchVisitor) VisitPrefix(n s.PrefixNode) error {
+	if n.Operator() != operators.OperatorNot {
+		return fmt.Errorf("%w: prefix operator %s", ErrUnsupportedByElasticsearch, n.Operator())
+	}
+	if err := n.Operand().Accept(v); err != nil {
+		return err
+	}
+	v.query = mustNot(v.query)
+	return nil
+}
+
+func (v *ElasticsearchVisitor) VisitPostfix(n s.PostfixNode) error {
+	field, ok := n.Operand().(s.FieldNode)
+	if !ok {
+		return fmt.Errorf("%w: %T as operand of %s", ErrUnsupportedByElasticsearch, n.Operand(), n.Operator())
+	}
+	path, err := v.fieldPath(field)
+	if err != nil {
+		return err
+	}
+	exists := map[string]any{"exists": map[string]any{"field": path}}
+	switch n.Operator() {
+	case operators.OperatorIsNull:
+		v.query = mustNot(exists)
+	case operators.OperatorIsNotNull:
+		v.query = exists
+	default:
+		return fmt.Errorf("%w: postfix operator %s", ErrUnsupportedByElasticsearch, n.Operator())
+	}
+	return nil
+}
+
+func (v *ElasticsearchVisitor) VisitInfix(n s.InfixNode) error {
+	switch n.Operator() {
+	case operators.OperatorAnd:
+		return v.visitLogical(n, "filter")
+	case operators.OperatorOr:
+		return v.visitLogical(n, "should")
+	case operators.OperatorIs:
+		if value, ok := n.Right().(s.ValueNode); ok && value.Value() == nil {
+			return v.VisitPostfix(s.IsNull(n.Left()))
+		}
+		return fmt.Errorf("%w: IS with a value other than null", ErrUnsupportedByElasticsearch)
+	case operators.OperatorIn, operators.OperatorNotIn:
+		return v.visitIn(n)
+	}
+
+	operator, ok := swappedOps[n.Operator()]
+	if !ok {
+		return fmt.Errorf("%w: infix operator %s", ErrUnsupportedByElasticsearch, n.Operator())
+	}
+	field, fieldOk := n.Left().(s.FieldNode)
+	value, valueOk := comparedValue(n.Right())
+	if fieldOk && valueOk {
+		operator = n.Operator()
+	} else {
+		field, fieldOk = n.Right().(s.FieldNode)
+		value, valueOk = comparedValue(n.Left())
+		if !fieldOk || !valueOk {
+			return fmt.Errorf("%w: comparison of %T and %T", ErrUnsupportedByElasticsearch, n.Left(), n.Right())
+		}
+	}
+	path, err := v.fieldPath(field)
+	if err != nil {
+		return err
+	}
+
+	switch operator {
+	case operators.OperatorEq:
+		if value.Value() == nil {
+			return v.VisitPostfix(s.IsNull(field))
+		}
+		v.query = termQuery(path, value.Value())
+	case operators.OperatorNe:
+		if value.Value() == nil {
+			return v.VisitPostfix(s.IsNotNull(field))
+		}
+		// A missing field differs from any value, as with IS DISTINCT FROM.
+		v.query = mustNot(termQuery(path, value.Value()))
+	default:
+		v.query = map[string]any{
+			"range": map[string]any{path: map[string]any{elasticsearchRangeOps[operator]: value.Value()}},
+		}
+	}
+	return nil
+}
+
+// comparedValue returns the value compared with the field, the current time is the date math of Elasticsearch,
+// "now" for Now and "now/d" for CurrentDate.
+func comparedValue(n s.Visitable) (s.ValueNode, bool) {
+	if function, ok := n.(s.FunctionNode); ok {
+		switch function.Name() {
+		case s.FunctionNow:
+			return s.Value("now"), true
+		case s.FunctionCurrentDate:
+			return s.Value("now/d"), true
+		}
+	}
+	value, ok := n.(s.ValueNode)
+	return value, ok
+}
+
+// visitIn compiles membership of the field in the slice value to the terms query.
+func (v *ElasticsearchVisitor) visitIn(n s.InfixNode) error {
+	field, fieldOk := n.Left().(s.FieldNode)
+	value, valueOk := n.Right().(s.ValueNode)
+	if !fieldOk || !valueOk {
+		return fmt.Errorf("%w: %s of %T in %T", ErrUnsupportedByElasticsearch, n.Operator(), n.Left(), n.Right())
+	}
+	path, err := v.fieldPath(field)
+	if err != nil {
+		return err
+	}
+	v.query = map[string]any{"terms": map[string]any{path: value.Value()}}
+	if n.Operator() == operators.OperatorNotIn {
+		// A missing field is not in any list, as with NOT IN of SpecToSqlVisitor.
+		v.query = mustNot(v.query)
+	}
+	return nil
+}
+
+// VisitFunction compiles the string function of the field to the prefix, wildcard or regexp query,
+// and Between to the range queries of its bounds.
+// Regexp queries are anchored, as match is, and use the Lucene syntax rather than RFC 9535 I-Regexp.
+func (v *ElasticsearchVisitor) VisitFunction(n s.FunctionNode) error {
+	if n.Name() == s.FunctionLength || n.Name() == s.FunctionCoalesce || n.Name() == s.FunctionCase ||
+		s.IsAggregate(n.Name()) {
+		// The value computed from the fields needs a script.
+		return fmt.Errorf("%w: function %s", ErrUnsupportedByElasticsearch, n.Name())
+	}
+	if n.Name() == s.FunctionBetween {
+		comparison, err := s.BetweenComparison(n)
+		if err != nil {
+			return err
+		}
+		return comparison.Accept(v)
+	}
+	value, pattern, err := functionArgs(n)
+	if err != nil {
+		return err
+	}
+	field, ok := value.(s.FieldNode)
+	if !ok {
+		return fmt.Errorf("%w: function %s of %T", ErrUnsupportedByElasticsearch, n.Name(), value)
+	}
+	arg, ok := pattern.Value().(string)
+	if !ok {
+		return fmt.Errorf("%w: function %s with %T", ErrUnsupportedByElasticsearch, n.Name(), pattern.Value())
+	}
+	path, err := v.fieldPath(field)
+	if err != nil {
+		return err
+	}
+	switch n.Name() {
+	case s.FunctionStartsWith:
+		v.query = map[string]any{"prefix": map[string]any{path: arg}}
+	case s.FunctionEndsWith:
+		v.query = map[string]any{"wildcard": map[string]any{path: "*" + wildcardEscaper.Replace(arg)}}
+	case s.FunctionContains:
+		v.query = map[string]any{"wildcard": map[string]any{path: "*" + wildcardEscaper.Replace(arg) + "*"}}
+	case s.FunctionMatch:
+		v.query = map[string]any{"regexp": map[string]any{path: arg}}
+	case s.FunctionSearch:
+		v.query = map[string]any{"regexp": map[string]any{path: ".*(" + arg + ").*"}}
+	default:
+		return fmt.Errorf("%w: function %s", ErrUnsupportedByElasticsearch, n.Name())
+	}
+	return nil
+}
+
+var wildcardEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`)
+
+// visitLogical flattens the chain of the same logical operator into one bool query.
+// VisitExtension compiles the extension node by the emitter of its type, see WithElasticsearchEmitter,
+// or its fallback if there is none.
+func (v *ElasticsearchVisitor) VisitExtension(n s.ExtensionNode) error {
+	return v.emitters.emit(v, n)
+}
+
+// SetQuery sets the query of the visited predicate, for the emitters of the extension nodes.
+func (v *ElasticsearchVisitor) SetQuery(query map[string]any) {
+	v.query = query
+}
+
+// FieldPath returns the dotted path of the field from the document root, the fields of Item()
+// are prefixed with the path of the collection, for the emitters of the extension nodes.
+func (v *ElasticsearchVisitor) FieldPath(n s.FieldNode) (string, error) {
+	return v.fieldPath(n)
+}
+
+func (v *ElasticsearchVisitor) visitLogical(n s.InfixNode, occur string) error {
+	var clauses []any
+	for _, node := range logicalOperands(n, n.Operator()) {
+		if err := node.Accept(v); err != nil {
+			return err
+		}
+		clauses = append(clauses, v.query)
+	}
+	boolQuery := map[string]any{occur: clauses}
+	if occur == "should" {
+		boolQuery["minimum_should_match"] = 1
+	}
+	v.query = map[string]any{"bool": boolQuery}
+	return nil
+}
+
+func (v *ElasticsearchVisitor) fieldPath(n s.FieldNode) (string, error) {
+	object, err := v.objectPath(n.Object())
+	if err != nil {
+		return "", err
+	}
+	return joinFieldPath(object, n.Name()), nil
+}
+
+// objectPath returns the dotted path of the object from the document root,
+// the object of Item() is the element of the innermost wildcard.
+func (v *ElasticsearchVisitor) objectPath(object s.EmptiableObject) (string, error) {
+	var keys []string
+	for {
+		switch o := object.(type) {
+		case s.GlobalScopeNode:
+			return strings.Join(keys, "."), nil
+		case s.ItemNode:
+			if len(v.nestedPaths) == 0 {
+				return "", fmt.Errorf("%w: item outside of wildcard", ErrUnsupportedByElasticsearch)
+			}
+			return joinFieldPath(v.nestedPaths[len(v.nestedPaths)-1], keys...), nil
+		case s.ObjectNode:
+			keys = append([]string{o.Name()}, keys...)
+			object = o.Parent()
+		default:
+			return "", fmt.Errorf("%w: path through %T", ErrUnsupportedByElasticsearch, object)
+		}
+	}
+}
+
+func joinFieldPath(prefix string, keys ...string) string {
+	if prefix == "" {
+		return strings.Join(keys, ".")
+	}
+	return strings.Join(append([]string{prefix}, keys...), ".")
+}
+
+func termQuery(path string, value any) map[string]any {
+	return map[string]any{"term": map[string]any{path: value}}
+}
+
+func mustNot(query map[string]any) map[string]any {
+	return map[string]any{"bool": map[string]any{"must_not": []any{query}}}
+}
+
+func logicalOperands(node s.Visitable, operator operators.Operator) []s.Visitable {
+	n, ok := node.(s.InfixNode)
+	if !ok || n.Operator() != operator {
+		return []s.Visitable{node}
+	}
+	return append(logicalOperands(n.Left(), operator), logicalOperands(n.Right(), operator)...)
+}
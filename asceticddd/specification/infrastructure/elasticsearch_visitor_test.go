@@ -0,0 +1,144 @@
+package specification
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+)
+
+func assertElasticsearchQuery(t *testing.T, exp s.Visitable, expected string, opts ...ElasticsearchVisitorOption) {
+	t.Helper()
+	query, err := CompileToElasticsearch(exp, opts...)
+	if err != nil {
+		t.Fatalf("CompileToElasticsearch failed: %v", err)
+	}
+	actual, err := json.Marshal(query)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var expectedQuery any
+	if err := json.Unmarshal([]byte(expected), &expectedQuery); err != nil {
+		t.Fatalf("Invalid expected query: %v", err)
+	}
+	normalized, _ := json.Marshal(expectedQuery)
+	if string(actual) != string(normalized) {
+		t.Errorf("Expected query: %s, got: %s", normalized, actual)
+	}
+}
+
+func TestCompileToElasticsearchComparisons(t *testing.T) {
+	age := s.Field(s.GlobalScope(), "age")
+
+	assertElasticsearchQuery(t, s.Equal(s.Field(s.GlobalScope(), "status"), s.Value("active")),
+		`{"term": {"status": "active"}}`)
+	assertElasticsearchQuery(t, s.NotEqual(s.Field(s.GlobalScope(), "status"), s.Value("deleted")),
+		`{"bool": {"must_not": [{"term": {"status": "deleted"}}]}}`)
+	assertElasticsearchQuery(t, s.GreaterThanEqual(age, s.Value(18)),
+		`{"range": {"age": {"gte": 18}}}`)
+	assertElasticsearchQuery(t, s.LessThan(s.Value(18), age),
+		`{"range": {"age": {"gt": 18}}}`)
+	assertElasticsearchQuery(t, s.Field(s.Object(s.GlobalScope(), "profile"), "verified"),
+		`{"term": {"profile.verified": true}}`)
+}
+
+func TestCompileToElasticsearchCurrentTime(t *testing.T) {
+	assertElasticsearchQuery(t, s.LessThan(s.Field(s.GlobalScope(), "expires_at"), s.Now()),
+		`{"range": {"expires_at": {"lt": "now"}}}`)
+	assertElasticsearchQuery(t, s.GreaterThanEqual(s.CurrentDate(), s.Field(s.GlobalScope(), "due_at")),
+		`{"range": {"due_at": {"lte": "now/d"}}}`)
+}
+
+func TestCompileToElasticsearchMembership(t *testing.T) {
+	status := s.Field(s.GlobalScope(), "status")
+
+	assertElasticsearchQuery(t, s.In(status, s.Value([]string{"active", "pending"})),
+		`{"terms": {"status": ["active", "pending"]}}`)
+	assertElasticsearchQuery(t, s.NotIn(status, s.Value([]string{"closed"})),
+		`{"bool": {"must_not": [{"terms": {"status": ["closed"]}}]}}`)
+}
+
+func TestCompileToElasticsearchFunction(t *testing.T) {
+	name := s.Field(s.GlobalScope(), "name")
+
+	assertElasticsearchQuery(t, s.StartsWith(name, s.Value("Jo")), `{"prefix": {"name": "Jo"}}`)
+	assertElasticsearchQuery(t, s.EndsWith(name, s.Value("son")), `{"wildcard": {"name": "*son"}}`)
+	assertElasticsearchQuery(t, s.Contains(name, s.Value("a*b")), `{"wildcard": {"name": "*a\\*b*"}}`)
+	assertElasticsearchQuery(t, s.Match(name, s.Value("J.*n")), `{"regexp": {"name": "J.*n"}}`)
+	assertElasticsearchQuery(t, s.Search(name, s.Value("oh")), `{"regexp": {"name": ".*(oh).*"}}`)
+	assertElasticsearchQuery(t, s.Between(s.Field(s.GlobalScope(), "age"), s.Value(18), s.Value(65)),
+		`{"bool": {"filter": [{"range": {"age": {"gte": 18}}}, {"range": {"age": {"lte": 65}}}]}}`)
+}
+
+func TestCompileToElasticsearchNull(t *testing.T) {
+	email := s.Field(s.GlobalScope(), "email")
+	missing := `{"bool": {"must_not": [{"exists": {"field": "email"}}]}}`
+
+	assertElasticsearchQuery(t, s.IsNull(email), missing)
+	assertElasticsearchQuery(t, s.Is(email, s.Value(nil)), missing)
+	assertElasticsearchQuery(t, s.Equal(email, s.Value(nil)), missing)
+	assertElasticsearchQuery(t, s.IsNotNull(email), `{"exists": {"field": "email"}}`)
+	assertElasticsearchQuery(t, s.NotEqual(email, s.Value(nil)), `{"exists": {"field": "email"}}`)
+}
+
+func TestCompileToElasticsearchLogical(t *testing.T) {
+	active := s.Equal(s.Field(s.GlobalScope(), "active"), s.Value(true))
+	adult := s.GreaterThanEqual(s.Field(s.GlobalScope(), "age"), s.Value(18))
+	premium := s.Equal(s.Field(s.GlobalScope(), "premium"), s.Value(true))
+
+	assertElasticsearchQuery(t, s.Or(s.And(active, adult, premium), s.Not(premium)), `{"bool": {
+		"should": [
+			{"bool": {"filter": [
+				{"term": {"active": true}},
+				{"range": {"age": {"gte": 18}}},
+				{"term": {"premium": true}}
+			]}},
+			{"bool": {"must_not": [{"term": {"premium": true}}]}}
+		],
+		"minimum_should_match": 1
+	}}`)
+}
+
+func TestCompileToElasticsearchNested(t *testing.T) {
+	exp := s.And(
+		s.Equal(s.Field(s.GlobalScope(), "status"), s.Value("active")),
+		s.Wildcard(
+			s.Object(s.Object(s.GlobalScope(), "order"), "items"),
+			s.And(
+				s.GreaterThan(s.Field(s.Item(), "price"), s.Value(10)),
+				s.Wildcard(
+					s.Object(s.Item(), "tags"),
+					s.Equal(s.Field(s.Item(), "name"), s.Value("sale")),
+				),
+			),
+		),
+	)
+
+	assertElasticsearchQuery(t, exp, `{"bool": {"filter": [
+		{"term": {"status": "active"}},
+		{"nested": {"path": "order.items", "query": {"bool": {"filter": [
+			{"range": {"order.items.price": {"gt": 10}}},
+			{"nested": {"path": "order.items.tags", "query": {"term": {"order.items.tags.name": "sale"}}}}
+		]}}}}
+	]}}`)
+}
+
+func TestCompileToElasticsearchUnsupported(t *testing.T) {
+	cases := map[string]s.Visitable{
+		"field comparison": s.Equal(s.Field(s.GlobalScope(), "a"), s.Field(s.GlobalScope(), "b")),
+		"arithmetic":       s.GreaterThan(s.Add(s.Field(s.GlobalScope(), "a"), s.Value(1)), s.Value(2)),
+		"item outside":     s.Equal(s.Field(s.Item(), "a"), s.Value(1)),
+		"value":            s.Value(true),
+		"index":            s.Index(s.Object(s.GlobalScope(), "items"), 0, s.Field(s.Item(), "active")),
+		"aggregate":        s.GreaterThan(s.CountOf(s.Object(s.GlobalScope(), "items"), s.Value(true)), s.Value(2)),
+	}
+	for name, exp := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := CompileToElasticsearch(exp)
+			if !errors.Is(err, ErrUnsupportedByElasticsearch) {
+				t.Errorf("Expected ErrUnsupportedByElasticsearch, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package specification
+
+import (
+	"reflect"
+
+	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+)
+
+// emitters are the compilers of the extension nodes of the visitor V by the concrete types of the nodes.
+type emitters[V s.Visitor] map[reflect.Type]func(v V, n s.ExtensionNode) error
+
+// registerEmitter registers the emitter of the extension nodes of the concrete type N.
+func registerEmitter[V s.Visitor, N s.ExtensionNode](e *emitters[V], emit func(v V, n N) error) {
+	if *e == nil {
+		*e = make(emitters[V])
+	}
+	(*e)[reflect.TypeFor[N]()] = func(v V, n s.ExtensionNode) error {
+		return emit(v, n.(N))
+	}
+}
+
+// emit compiles the extension node by the emitter of its type, or visits its fallback if there is none.
+func (e emitters[V]) emit(v V, n s.ExtensionNode) error {
+	if emit, ok := e[reflect.TypeOf(n)]; ok {
+		return emit(v, n)
+	}
+	return s.VisitFallback(n, v)
+}
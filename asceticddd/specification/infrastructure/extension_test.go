@@ -0,0 +1,125 @@
+package specification
+
+import (
+	"errors"
+	"testing"
+
+	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+)
+
+// withinRadius is the extension node of the test, its fallback compares the precomputed distance.
+type withinRadius struct {
+	location s.FieldNode
+	distance s.FieldNode
+	radius   int
+}
+
+func (n withinRadius) Accept(v s.Visitor) error {
+	return s.AcceptExtension(n, v)
+}
+
+func (n withinRadius) Fallback() s.Visitable {
+	return s.LessThanEqual(n.distance, s.Value(n.radius))
+}
+
+type opaqueNode struct{}
+
+func (n opaqueNode) Accept(v s.Visitor) error {
+	return s.AcceptExtension(n, v)
+}
+
+func (n opaqueNode) Fallback() s.Visitable {
+	return nil
+}
+
+func newWithinRadius() withinRadius {
+	return withinRadius{
+		location: s.Field(s.GlobalScope(), "location"),
+		distance: s.Field(s.GlobalScope(), "distance"),
+		radius:   1000,
+	}
+}
+
+func TestCompileToSQLExtensionFallback(t *testing.T) {
+	expr := s.And(newWithinRadius(), s.Field(s.GlobalScope(), "active"))
+
+	sql, params, err := CompileToSQL(expr)
+	if err != nil {
+		t.Fatalf("CompileToSQL failed: %v", err)
+	}
+	expected := "distance <= $1 AND active"
+	if sql != expected {
+		t.Errorf("Expected SQL: %s, got: %s", expected, sql)
+	}
+	if len(params) != 1 || params[0] != 1000 {
+		t.Errorf("Unexpected params: %v", params)
+	}
+}
+
+func TestCompileToSQLExtensionEmitter(t *testing.T) {
+	emitter := WithPostgresqlEmitter(func(v *PostgresqlVisitor, n withinRadius) error {
+		v.WriteSQL("ST_DWithin(")
+		if err := v.VisitArg(n.location); err != nil {
+			return err
+		}
+		v.WriteSQL(", ")
+		if err := v.VisitArg(s.Value(n.radius)); err != nil {
+			return err
+		}
+		v.WriteSQL(")")
+		return nil
+	})
+	expr := s.Not(s.And(newWithinRadius(), s.Field(s.GlobalScope(), "active")))
+
+	sql, params, err := CompileToSQL(expr, emitter, Simplified())
+	if err != nil {
+		t.Fatalf("CompileToSQL failed: %v", err)
+	}
+	expected := "NOT (ST_DWithin(location, $1) AND active)"
+	if sql != expected {
+		t.Errorf("Expected SQL: %s, got: %s", expected, sql)
+	}
+	if len(params) != 1 || params[0] != 1000 {
+		t.Errorf("Unexpected params: %v", params)
+	}
+}
+
+func TestCompileToSQLExtensionWithoutFallback(t *testing.T) {
+	_, _, err := CompileToSQL(s.Not(opaqueNode{}))
+	if !errors.Is(err, s.ErrUnsupportedExtension) {
+		t.Errorf("Expected ErrUnsupportedExtension, got %v", err)
+	}
+}
+
+func TestCompileToElasticsearchExtension(t *testing.T) {
+	assertElasticsearchQuery(t, newWithinRadius(), `{"range": {"distance": {"lte": 1000}}}`)
+
+	emitter := WithElasticsearchEmitter(func(v *ElasticsearchVisitor, n withinRadius) error {
+		path, err := v.FieldPath(n.location)
+		if err != nil {
+			return err
+		}
+		v.SetQuery(map[string]any{"geo_distance": map[string]any{"distance": "1000m", path: "drm3btev3e86"}})
+		return nil
+	})
+	assertElasticsearchQuery(t, s.Wildcard(s.Object(s.GlobalScope(), "stores"), withinRadius{
+		location: s.Field(s.Item(), "location"),
+		distance: s.Field(s.Item(), "distance"),
+		radius:   1000,
+	}), `{"nested": {"path": "stores", "query":
+		{"geo_distance": {"distance": "1000m", "stores.location": "drm3btev3e86"}}}}`, emitter)
+}
+
+func TestMarshalASTExtension(t *testing.T) {
+	data, err := MarshalAST(newWithinRadius())
+	if err != nil {
+		t.Fatalf("MarshalAST failed: %v", err)
+	}
+	expected, err := MarshalAST(newWithinRadius().Fallback())
+	if err != nil {
+		t.Fatalf("MarshalAST failed: %v", err)
+	}
+	if string(data) != string(expected) {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
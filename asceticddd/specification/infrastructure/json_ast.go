@@ -0,0 +1,495 @@
+package specification
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+
+	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+// ASTFormatVersion is the version of the JSON format of AST written by MarshalAST.
+const ASTFormatVersion = 1
+
+var (
+	ErrInvalidAST            = errors.New("invalid specification AST")
+	ErrUnsupportedASTVersion = errors.New("unsupported specification AST version")
+)
+
+// MarshalAST marshals AST to the canonical JSON, e.g. to store a rule in the database:
+//
+//	{"version": 1, "ast": {"node": "infix", "operator": ">", "associativity": "NON",
+//		"left": {"node": "field", "name": "age", "object": {"node": "global"}},
+//		"right": {"node": "value", "value": {"type": "int", "value": 18}}}}
+//
+// The values are tagged by their types: null, bool, int, int64, float64, string, decimal of *big.Rat,
+// datetime of time.Time, duration of time.Duration, uuid of uuid.UUID and list of slices.
+// A value of a named string type, e.g. an enum, is a string. An extension node is marshaled as its fallback.
+func MarshalAST(ast s.Visitable) ([]byte, error) {
+	encoder := &astEncoder{}
+	if err := ast.Accept(encoder); err != nil {
+		return nil, err
+	}
+	// The operators are kept readable, e.g. ">" is not escaped to "\u003e".
+	var buf bytes.Buffer
+	jsonEncoder := json.NewEncoder(&buf)
+	jsonEncoder.SetEscapeHTML(false)
+	if err := jsonEncoder.Encode(astDocument{Version: ASTFormatVersion, AST: encoder.node}); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// UnmarshalAST unmarshals AST of MarshalAST, the version, the nodes, the operators and the values are validated.
+func UnmarshalAST(data []byte) (s.Visitable, error) {
+	var document astDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAST, err)
+	}
+	if document.Version != ASTFormatVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedASTVersion, document.Version)
+	}
+	return decodeNode(document.AST)
+}
+
+type astDocument struct {
+	Version int      `json:"version"`
+	AST     *astNode `json:"ast"`
+}
+
+type astNode struct {
+	Node          string     `json:"node"`
+	Name          string     `json:"name,omitempty"`
+	Selector      string     `json:"selector,omitempty"`
+	Operator      string     `json:"operator,omitempty"`
+	Associativity string     `json:"associativity,omitempty"`
+	Parent        *astNode   `json:"parent,omitempty"`
+	Object        *astNode   `json:"object,omitempty"`
+	Predicate     *astNode   `json:"predicate,omitempty"`
+	Operand       *astNode   `json:"operand,omitempty"`
+	Left          *astNode   `json:"left,omitempty"`
+	Right         *astNode   `json:"right,omitempty"`
+	Args          []*astNode `json:"args,omitempty"`
+	Value         *astValue  `json:"value,omitempty"`
+}
+
+type astValue struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+const (
+	astGlobal     = "global"
+	astItem       = "item"
+	astObject     = "object"
+	astCollection = "collection"
+	astField      = "field"
+	astValueNode  = "value"
+	astPrefix     = "prefix"
+	astInfix      = "infix"
+	astPostfix    = "postfix"
+	astFunction   = "function"
+)
+
+var (
+	// astOperators are the operators of the prefix, infix and postfix nodes
+	astOperators = map[string]map[operators.Operator]bool{
+		astPrefix: {operators.OperatorNot: true, operators.OperatorPos: true, operators.OperatorNeg: true},
+		astInfix: {
+			operators.OperatorEq: true, operators.OperatorNe: true, operators.OperatorGt: true,
+			operators.OperatorGte: true, operators.OperatorLt: true, operators.OperatorLte: true,
+			operators.OperatorIs: true, operators.OperatorIn: true, operators.OperatorNotIn: true,
+			operators.OperatorAnd: true, operators.OperatorOr: true, operators.OperatorConcat: true,
+			operators.OperatorAdd: true, operators.OperatorSub: true, operators.OperatorMul: true,
+			operators.OperatorDiv: true, operators.OperatorMod: true,
+			operators.OperatorLshift: true, operators.OperatorRshift: true, operators.OperatorBitAnd: true,
+			operators.OperatorBitOr: true, operators.OperatorBitXor: true,
+		},
+		astPostfix: {operators.OperatorIsNull: true, operators.OperatorIsNotNull: true},
+	}
+	associativities = map[s.Associativity]bool{
+		s.LeftAssociative: true, s.RightAssociative: true, s.NonAssociative: true,
+	}
+	astFunctions = map[string]bool{
+		s.FunctionMatch: true, s.FunctionSearch: true, s.FunctionStartsWith: true, s.FunctionEndsWith: true,
+		s.FunctionContains: true, s.FunctionLength: true, s.FunctionBetween: true, s.FunctionCoalesce: true,
+		s.FunctionCase: true, s.FunctionCount: true, s.FunctionSum: true, s.FunctionMin: true,
+		s.FunctionMax: true, s.FunctionNow: true, s.FunctionCurrentDate: true, s.FunctionRef: true,
+	}
+)
+
+// astEncoder builds the JSON node of the visited node.
+type astEncoder struct {
+	node *astNode
+}
+
+func (e *astEncoder) encode(n s.Visitable) (*astNode, error) {
+	if err := n.Accept(e); err != nil {
+		return nil, err
+	}
+	return e.node, nil
+}
+
+func (e *astEncoder) VisitGlobalScope(_ s.GlobalScopeNode) error {
+	e.node = &astNode{Node: astGlobal}
+	return nil
+}
+
+func (e *astEncoder) VisitItem(_ s.ItemNode) error {
+	e.node = &astNode{Node: astItem}
+	return nil
+}
+
+func (e *astEncoder) VisitObject(n s.ObjectNode) error {
+	parent, err := e.encode(n.Parent())
+	if err != nil {
+		return err
+	}
+	e.node = &astNode{Node: astObject, Name: n.Name(), Parent: parent}
+	return nil
+}
+
+func (e *astEncoder) VisitCollection(n s.CollectionNode) error {
+	parent, err := e.encode(n.Parent())
+	if err != nil {
+		return err
+	}
+	predicate, err := e.encode(n.Predicate())
+	if err != nil {
+		return err
+	}
+	e.node = &astNode{Node: astCollection, Selector: n.Name(), Parent: parent, Predicate: predicate}
+	return nil
+}
+
+func (e *astEncoder) VisitField(n s.FieldNode) error {
+	object, err := e.encode(n.Object())
+	if err != nil {
+		return err
+	}
+	e.node = &astNode{Node: astField, Name: n.Name(), Object: object}
+	return nil
+}
+
+func (e *astEncoder) VisitValue(n s.ValueNode) error {
+	value, err := encodeValue(n.Value())
+	if err != nil {
+		return err
+	}
+	e.node = &astNode{Node: astValueNode, Value: value}
+	return nil
+}
+
+func (e *astEncoder) VisitPrefix(n s.PrefixNode) error {
+	operand, err := e.encode(n.Operand())
+	if err != nil {
+		return err
+	}
+	e.node = &astNode{
+		Node: astPrefix, Operator: string(n.Operator()), Associativity: string(n.Associativity()), Operand: operand,
+	}
+	return nil
+}
+
+func (e *astEncoder) VisitInfix(n s.InfixNode) error {
+	left, err := e.encode(n.Left())
+	if err != nil {
+		return err
+	}
+	right, err := e.encode(n.Right())
+	if err != nil {
+		return err
+	}
+	e.node = &astNode{
+		Node: astInfix, Operator: string(n.Operator()), Associativity: string(n.Associativity()), Left: left, Right: right,
+	}
+	return nil
+}
+
+func (e *astEncoder) VisitPostfix(n s.PostfixNode) error {
+	operand, err := e.encode(n.Operand())
+	if err != nil {
+		return err
+	}
+	e.node = &astNode{
+		Node: astPostfix, Operator: string(n.Operator()), Associativity: string(n.Associativity()), Operand: operand,
+	}
+	return nil
+}
+
+func (e *astEncoder) VisitFunction(n s.FunctionNode) error {
+	args := make([]*astNode, 0, len(n.Args()))
+	for _, arg := range n.Args() {
+		node, err := e.encode(arg)
+		if err != nil {
+			return err
+		}
+		args = append(args, node)
+	}
+	e.node = &astNode{Node: astFunction, Name: n.Name(), Args: args}
+	return nil
+}
+
+// VisitExtension encodes the fallback of the extension node, its type is unknown to UnmarshalAST.
+func (e *astEncoder) VisitExtension(n s.ExtensionNode) error {
+	return s.VisitFallback(n, e)
+}
+
+func encodeValue(value any) (*astValue, error) {
+	var tag string
+	var raw any
+	switch typed := value.(type) {
+	case nil:
+		return &astValue{Type: "null"}, nil
+	case bool:
+		tag, raw = "bool", typed
+	case int:
+		tag, raw = "int", typed
+	case int64:
+		tag, raw = "int64", typed
+	case float64:
+		tag, raw = "float64", typed
+	case string:
+		tag, raw = "string", typed
+	case *big.Rat:
+		tag, raw = "decimal", decimalText(typed)
+	case time.Time:
+		tag, raw = "datetime", typed.Format(time.RFC3339Nano)
+	case time.Duration:
+		tag, raw = "duration", typed.String()
+	case uuid.UUID:
+		tag, raw = "uuid", typed.String()
+	default:
+		v := reflect.ValueOf(value)
+		switch v.Kind() {
+		case reflect.String:
+			tag, raw = "string", v.String()
+		case reflect.Slice, reflect.Array:
+			items := make([]*astValue, 0, v.Len())
+			for i := range v.Len() {
+				item, err := encodeValue(v.Index(i).Interface())
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+			tag, raw = "list", items
+		default:
+			return nil, fmt.Errorf("value of %T is not supported by the AST format", value)
+		}
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &astValue{Type: tag, Value: data}, nil
+}
+
+// decimalText returns the decimal text of the rational number if its expansion terminates, otherwise the fraction.
+func decimalText(r *big.Rat) string {
+	text := NumericText(r)
+	if parsed, ok := new(big.Rat).SetString(text); ok && parsed.Cmp(r) == 0 {
+		return text
+	}
+	return r.RatString()
+}
+
+func decodeNode(n *astNode) (s.Visitable, error) {
+	if n == nil {
+		return nil, fmt.Errorf("%w: missing node", ErrInvalidAST)
+	}
+	switch n.Node {
+	case astGlobal:
+		return s.GlobalScope(), nil
+	case astItem:
+		return s.Item(), nil
+	case astObject:
+		parent, err := decodeObject(n.Parent)
+		if err != nil {
+			return nil, err
+		}
+		if n.Name == "" {
+			return nil, fmt.Errorf("%w: object without name", ErrInvalidAST)
+		}
+		return s.Object(parent, n.Name), nil
+	case astCollection:
+		parent, err := decodeObject(n.Parent)
+		if err != nil {
+			return nil, err
+		}
+		predicate, err := decodeNode(n.Predicate)
+		if err != nil {
+			return nil, err
+		}
+		if n.Selector == "" {
+			return nil, fmt.Errorf("%w: collection without selector", ErrInvalidAST)
+		}
+		return s.NewCollectionNode(parent, n.Selector, predicate), nil
+	case astField:
+		object, err := decodeObject(n.Object)
+		if err != nil {
+			return nil, err
+		}
+		if n.Name == "" {
+			return nil, fmt.Errorf("%w: field without name", ErrInvalidAST)
+		}
+		return s.Field(object, n.Name), nil
+	case astValueNode:
+		if n.Value == nil {
+			return nil, fmt.Errorf("%w: value node without value", ErrInvalidAST)
+		}
+		value, err := decodeValue(n.Value)
+		if err != nil {
+			return nil, err
+		}
+		return s.Value(value), nil
+	case astPrefix, astPostfix:
+		operator, associativity, err := decodeOperator(n)
+		if err != nil {
+			return nil, err
+		}
+		operand, err := decodeNode(n.Operand)
+		if err != nil {
+			return nil, err
+		}
+		if n.Node == astPrefix {
+			return s.NewPrefixNode(operator, operand, associativity), nil
+		}
+		return s.NewPostfixNode(operand, operator, associativity), nil
+	case astInfix:
+		operator, associativity, err := decodeOperator(n)
+		if err != nil {
+			return nil, err
+		}
+		left, err := decodeNode(n.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := decodeNode(n.Right)
+		if err != nil {
+			return nil, err
+		}
+		return s.NewInfixNode(left, operator, right, associativity), nil
+	case astFunction:
+		if !astFunctions[n.Name] {
+			return nil, fmt.Errorf("%w: unknown function %q", ErrInvalidAST, n.Name)
+		}
+		var args []s.Visitable
+		for _, arg := range n.Args {
+			node, err := decodeNode(arg)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, node)
+		}
+		return s.Function(n.Name, args...), nil
+	}
+	return nil, fmt.Errorf("%w: unknown node %q", ErrInvalidAST, n.Node)
+}
+
+func decodeObject(n *astNode) (s.EmptiableObject, error) {
+	node, err := decodeNode(n)
+	if err != nil {
+		return nil, err
+	}
+	object, ok := node.(s.EmptiableObject)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s node is not an object", ErrInvalidAST, n.Node)
+	}
+	return object, nil
+}
+
+func decodeOperator(n *astNode) (operators.Operator, s.Associativity, error) {
+	operator := operators.Operator(n.Operator)
+	if !astOperators[n.Node][operator] {
+		return "", "", fmt.Errorf("%w: unknown %s operator %q", ErrInvalidAST, n.Node, n.Operator)
+	}
+	associativity := s.Associativity(n.Associativity)
+	if !associativities[associativity] {
+		return "", "", fmt.Errorf("%w: unknown associativity %q", ErrInvalidAST, n.Associativity)
+	}
+	return operator, associativity, nil
+}
+
+func decodeValue(v *astValue) (any, error) {
+	if v.Type == "null" {
+		return nil, nil
+	}
+	if len(v.Value) == 0 {
+		return nil, fmt.Errorf("%w: %s value is missing", ErrInvalidAST, v.Type)
+	}
+	var result any
+	var err error
+	switch v.Type {
+	case "bool":
+		result, err = unmarshalValue[bool](v.Value)
+	case "int":
+		result, err = unmarshalValue[int](v.Value)
+	case "int64":
+		result, err = unmarshalValue[int64](v.Value)
+	case "float64":
+		result, err = unmarshalValue[float64](v.Value)
+	case "string":
+		result, err = unmarshalValue[string](v.Value)
+	case "decimal":
+		result, err = parseValue(v.Value, func(text string) (any, error) {
+			r, ok := new(big.Rat).SetString(text)
+			if !ok {
+				return nil, fmt.Errorf("invalid decimal %q", text)
+			}
+			return r, nil
+		})
+	case "datetime":
+		result, err = parseValue(v.Value, func(text string) (any, error) { return time.Parse(time.RFC3339Nano, text) })
+	case "duration":
+		result, err = parseValue(v.Value, func(text string) (any, error) { return time.ParseDuration(text) })
+	case "uuid":
+		result, err = parseValue(v.Value, func(text string) (any, error) { return uuid.Parse(text) })
+	case "list":
+		var items []*astValue
+		if err := json.Unmarshal(v.Value, &items); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidAST, err)
+		}
+		list := make([]any, 0, len(items))
+		for _, item := range items {
+			if item == nil {
+				return nil, fmt.Errorf("%w: missing list item", ErrInvalidAST)
+			}
+			value, err := decodeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown value type %q", ErrInvalidAST, v.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s value: %w", ErrInvalidAST, v.Type, err)
+	}
+	return result, nil
+}
+
+func unmarshalValue[T any](data json.RawMessage) (any, error) {
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func parseValue(data json.RawMessage, parse func(text string) (any, error)) (any, error) {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return nil, err
+	}
+	return parse(text)
+}
@@ -0,0 +1,121 @@
+package specification
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+)
+
+func TestMarshalAST(t *testing.T) {
+	data, err := MarshalAST(s.GreaterThan(s.Field(s.GlobalScope(), "age"), s.Value(18)))
+	if err != nil {
+		t.Fatalf("MarshalAST failed: %v", err)
+	}
+	expected := `{"version":1,"ast":{"node":"infix","operator":">","associativity":"NON",` +
+		`"left":{"node":"field","name":"age","object":{"node":"global"}},` +
+		`"right":{"node":"value","value":{"type":"int","value":18}}}}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
+
+func TestASTRoundTrip(t *testing.T) {
+	root := s.GlobalScope()
+	items := s.Object(root, "items")
+	created := time.Date(2024, 5, 17, 10, 30, 0, 123000000, time.UTC)
+	id := uuid.MustParse("0b7e2a8c-3f1d-4c5e-9a6b-2d8f1e4c7a90")
+
+	for _, tc := range []struct {
+		name string
+		ast  s.Visitable
+	}{
+		{"logical", s.And(s.Not(s.IsNull(s.Field(root, "name"))), s.Or(s.Field(root, "active"), s.Value(false)))},
+		{"arithmetic", s.GreaterThan(s.NewPrefixNode(operators.OperatorNeg, s.Mod(s.Field(root, "score"), s.Value(int64(7))), s.RightAssociative), s.Value(1.5))},
+		{"collection", s.Wildcard(items, s.Equal(s.Field(s.Item(), "gift"), s.Value(true)))},
+		{"index", s.Index(s.Object(s.Object(root, "order"), "lines"), -1, s.Field(s.Item(), "price"))},
+		{"functions", s.And(s.Between(s.Field(root, "age"), s.Value(18), s.Value(65)),
+			s.Equal(s.Coalesce(s.Field(root, "bonus"), s.Value(nil)), s.SumOf(items, s.Field(s.Item(), "price"))))},
+		{"current time", s.LessThan(s.Field(root, "created_at"), s.Now())},
+		{"reference", s.Or(s.Ref("AdultUser"), s.Function(s.FunctionCurrentDate))},
+		{"decimal", s.Equal(s.Field(root, "price"), s.Value(big.NewRat(1999, 100)))},
+		{"fraction", s.Equal(s.Field(root, "share"), s.Value(big.NewRat(1, 3)))},
+		{"datetime", s.GreaterThanEqual(s.Field(root, "created_at"), s.Value(created))},
+		{"duration", s.LessThan(s.Field(root, "elapsed"), s.Value(90*time.Minute))},
+		{"uuid", s.Equal(s.Field(root, "id"), s.Value(id))},
+		{"list", s.In(s.Field(root, "status"), s.Value([]any{"new", 2, nil}))},
+	} {
+		data, err := MarshalAST(tc.ast)
+		if err != nil {
+			t.Fatalf("%s: MarshalAST failed: %v", tc.name, err)
+		}
+		ast, err := UnmarshalAST(data)
+		if err != nil {
+			t.Fatalf("%s: UnmarshalAST of %s failed: %v", tc.name, data, err)
+		}
+		if !reflect.DeepEqual(ast, tc.ast) {
+			t.Errorf("%s: expected %#v, got %#v", tc.name, tc.ast, ast)
+		}
+	}
+}
+
+func TestASTTypedValues(t *testing.T) {
+	type status string
+	data, err := MarshalAST(s.In(s.Field(s.GlobalScope(), "status"), s.Value([]status{"new", "paid"})))
+	if err != nil {
+		t.Fatalf("MarshalAST failed: %v", err)
+	}
+	ast, err := UnmarshalAST(data)
+	if err != nil {
+		t.Fatalf("UnmarshalAST failed: %v", err)
+	}
+	expected := s.In(s.Field(s.GlobalScope(), "status"), s.Value([]any{"new", "paid"}))
+	if !reflect.DeepEqual(ast, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, ast)
+	}
+
+	if _, err := MarshalAST(s.Value(struct{}{})); err == nil {
+		t.Error("Expected error for unsupported value")
+	}
+}
+
+func TestUnmarshalASTValidation(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		data     string
+		expected error
+	}{
+		{"version", `{"version":2,"ast":{"node":"global"}}`, ErrUnsupportedASTVersion},
+		{"missing version", `{"ast":{"node":"global"}}`, ErrUnsupportedASTVersion},
+		{"syntax", `{"version":1,"ast":`, ErrInvalidAST},
+		{"missing ast", `{"version":1}`, ErrInvalidAST},
+		{"unknown node", `{"version":1,"ast":{"node":"lambda"}}`, ErrInvalidAST},
+		{"operator of node", `{"version":1,"ast":{"node":"prefix","operator":"=","associativity":"RIGHT",` +
+			`"operand":{"node":"value","value":{"type":"bool","value":true}}}}`, ErrInvalidAST},
+		{"associativity", `{"version":1,"ast":{"node":"prefix","operator":"NOT","associativity":"UP",` +
+			`"operand":{"node":"value","value":{"type":"bool","value":true}}}}`, ErrInvalidAST},
+		{"missing operand", `{"version":1,"ast":{"node":"postfix","operator":"IS NULL","associativity":"LEFT"}}`,
+			ErrInvalidAST},
+		{"unknown function", `{"version":1,"ast":{"node":"function","name":"exec"}}`, ErrInvalidAST},
+		{"field of value", `{"version":1,"ast":{"node":"field","name":"age",` +
+			`"object":{"node":"value","value":{"type":"null"}}}}`, ErrInvalidAST},
+		{"field without name", `{"version":1,"ast":{"node":"field","object":{"node":"global"}}}`, ErrInvalidAST},
+		{"value type", `{"version":1,"ast":{"node":"value","value":{"type":"complex","value":1}}}`, ErrInvalidAST},
+		{"value of type", `{"version":1,"ast":{"node":"value","value":{"type":"int","value":"18"}}}`, ErrInvalidAST},
+		{"missing value", `{"version":1,"ast":{"node":"value","value":{"type":"uuid"}}}`, ErrInvalidAST},
+		{"uuid", `{"version":1,"ast":{"node":"value","value":{"type":"uuid","value":"42"}}}`, ErrInvalidAST},
+		{"datetime", `{"version":1,"ast":{"node":"value","value":{"type":"datetime","value":"yesterday"}}}`,
+			ErrInvalidAST},
+	} {
+		_, err := UnmarshalAST([]byte(tc.data))
+		if !errors.Is(err, tc.expected) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, err)
+		}
+	}
+}
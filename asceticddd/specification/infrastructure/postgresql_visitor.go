@@ -2,7 +2,10 @@ package specification
 
 import (
 	"fmt"
+	"math/big"
+	"reflect"
 	"strings"
+	"time"
 
 	"github.com/jinzhu/inflection"
 
@@ -29,9 +32,14 @@ func Compile(context Context, exp s.Visitable) (sql string, params []any, err er
 }
 
 // CompileToSQL compiles AST directly to SQL without context transformation
-// Useful for generated code where AST is already in the right form
-func CompileToSQL(exp s.Visitable) (sql string, params []any, err error) {
-	v := NewPostgresqlVisitor()
+// Useful for generated code where AST is already in the right form.
+// SQL compares numbers exactly, operators.RegisterFloatTolerance affects evaluation only.
+func CompileToSQL(exp s.Visitable, opts ...PostgresqlVisitorOption) (sql string, params []any, err error) {
+	v := NewPostgresqlVisitor(opts...)
+	exp, err = v.simplify(exp)
+	if err != nil {
+		return "", nil, err
+	}
 	err = exp.Accept(v)
 	if err != nil {
 		return "", nil, err
@@ -47,6 +55,46 @@ func PlaceholderIndex(index uint8) PostgresqlVisitorOption {
 	}
 }
 
+// QuestionPlaceholders makes the visitor render params as "?" instead of "$1", "$2", ...,
+// e.g. for query builders which number the placeholders of the whole query, see Sqlizer.
+func QuestionPlaceholders() PostgresqlVisitorOption {
+	return func(v *PostgresqlVisitor) {
+		v.questionPlaceholders = true
+	}
+}
+
+// ExpandedLists makes the visitor render IN and NOT IN as "status IN ?" instead of the comparison with the array,
+// for query builders which expand the slice param into the list, e.g. GORM, see specgorm.Scope.
+func ExpandedLists() PostgresqlVisitorOption {
+	return func(v *PostgresqlVisitor) {
+		v.expandedLists = true
+	}
+}
+
+// WithFieldResolver sets the resolver of the SQL expressions of fields by their paths, see ExtractFieldPath,
+// by default the path is joined by dots. Fields of the items of collections are not resolved.
+func WithFieldResolver(resolver func(path []string) (string, error)) PostgresqlVisitorOption {
+	return func(v *PostgresqlVisitor) {
+		v.fieldResolver = resolver
+	}
+}
+
+// Simplified makes CompileToSQL and CompileToColumns simplify AST before compilation, see s.Simplify,
+// e.g. "age >= $1 AND $2" of And(GreaterThanEqual(age, Value(18)), Not(Value(false))) becomes "age >= $1".
+func Simplified() PostgresqlVisitorOption {
+	return func(v *PostgresqlVisitor) {
+		v.simplified = true
+	}
+}
+
+// WithDialect sets the SQL syntax of the database, see Dialect, e.g.
+// "age >= ? AND status IN (?, ?)" of MySQLDialect. The default is PostgresDialect.
+func WithDialect(dialect Dialect) PostgresqlVisitorOption {
+	return func(v *PostgresqlVisitor) {
+		v.dialect = dialect
+	}
+}
+
 // WithSchema sets the schema registry for relational collection support
 func WithSchema(schema *SchemaRegistry) PostgresqlVisitorOption {
 	return func(v *PostgresqlVisitor) {
@@ -54,9 +102,29 @@ func WithSchema(schema *SchemaRegistry) PostgresqlVisitorOption {
 	}
 }
 
+// WithPostgresqlEmitter registers the emitter of the extension nodes of the concrete type N,
+// which compiles the node by the helpers of the visitor instead of its fallback, e.g.
+//
+//	infra.WithPostgresqlEmitter(func(v *infra.PostgresqlVisitor, n WithinRadius) error {
+//		v.WriteSQL("ST_DWithin(")
+//		if err := v.VisitArg(n.Location); err != nil {
+//			return err
+//		}
+//		v.WriteSQL(", ")
+//		...
+//	})
+//
+// The emitted SQL is an operand of the enclosing operators as is, so the emitter parenthesizes its operators.
+func WithPostgresqlEmitter[N s.ExtensionNode](emit func(v *PostgresqlVisitor, n N) error) PostgresqlVisitorOption {
+	return func(v *PostgresqlVisitor) {
+		registerEmitter(&v.emitters, emit)
+	}
+}
+
 func NewPostgresqlVisitor(opts ...PostgresqlVisitorOption) *PostgresqlVisitor {
 	v := &PostgresqlVisitor{
 		precedenceMapping: make(map[string]int),
+		dialect:           PostgresDialect{},
 	}
 	// https://www.postgresql.org/docs/14/sql-syntax-lexical.html#SQL-PRECEDENCE-TABLE
 	v.setPrecedence(160, ". LEFT")
@@ -91,7 +159,24 @@ type PostgresqlVisitor struct {
 	wildcardAlias   string // Current wildcard item alias (e.g., "item")
 	wildcardCounter int    // Counter for unique aliases
 	// Schema registry for relational collections
-	schema *SchemaRegistry
+	schema        *SchemaRegistry
+	fieldResolver func(path []string) (string, error)
+	// questionPlaceholders renders params as "?"
+	questionPlaceholders bool
+	// expandedLists renders IN and NOT IN with the list param
+	expandedLists bool
+	// simplified simplifies AST before compilation
+	simplified bool
+	dialect    Dialect
+	emitters   emitters[*PostgresqlVisitor]
+}
+
+// simplify returns the simplified AST if the visitor is Simplified, otherwise AST as is.
+func (v *PostgresqlVisitor) simplify(exp s.Visitable) (s.Visitable, error) {
+	if !v.simplified {
+		return exp, nil
+	}
+	return s.Simplify(exp, operators.NewDefaultRegistry())
 }
 
 func (v PostgresqlVisitor) getNodePrecedenceKey(n s.Operable) string {
@@ -141,6 +226,16 @@ func (v *PostgresqlVisitor) VisitCollection(n s.CollectionNode) error {
 	// Two modes:
 	// 1. Embedded (JSONB/array): EXISTS (SELECT 1 FROM unnest(collection) AS item WHERE predicate)
 	// 2. Relational (separate table): EXISTS (SELECT 1 FROM table AS item WHERE fk_conditions AND predicate)
+	return v.visitCollectionQuery(n, "EXISTS (SELECT 1", nil, n.Predicate())
+}
+
+// visitCollectionQuery generates the subquery of the elements of the collection, e.g. "EXISTS (SELECT 1" as the head.
+// selectList completes the select list in the context of the element alias, and where filters the elements, if not nil.
+func (v *PostgresqlVisitor) visitCollectionQuery(n s.CollectionNode, head string, selectList func() error, where s.Visitable) error {
+	// Positional elements have no order to rely on in SQL, so only the wildcard is compiled.
+	if !n.IsWildcard() {
+		return fmt.Errorf("selector [%s] of collection is not supported, only [*] is", n.Name())
+	}
 
 	// Extract collection name for alias and schema lookup
 	collectionName := v.extractCollectionName(n)
@@ -148,15 +243,17 @@ func (v *PostgresqlVisitor) VisitCollection(n s.CollectionNode) error {
 
 	// Check if this is a relational collection
 	if v.schema != nil && v.schema.IsRelational(fieldName) {
-		return v.visitRelationalCollection(n, fieldName, collectionName)
+		return v.visitRelationalCollection(n, fieldName, collectionName, head, selectList, where)
 	}
 
 	// Default: embedded collection (JSONB/array)
-	return v.visitEmbeddedCollection(n, collectionName)
+	return v.visitEmbeddedCollection(n, collectionName, head, selectList, where)
 }
 
 // visitEmbeddedCollection generates SQL for JSONB/array collections using unnest
-func (v *PostgresqlVisitor) visitEmbeddedCollection(n s.CollectionNode, collectionName string) error {
+func (v *PostgresqlVisitor) visitEmbeddedCollection(
+	n s.CollectionNode, collectionName, head string, selectList func() error, where s.Visitable,
+) error {
 	// Extract collection path (e.g., "Items" from Object(GlobalScope(), "Items"))
 	collectionPath := v.extractCollectionPath(n)
 
@@ -172,18 +269,29 @@ func (v *PostgresqlVisitor) visitEmbeddedCollection(n s.CollectionNode, collecti
 	v.inWildcard = true
 	v.wildcardAlias = alias
 
-	// Generate EXISTS subquery with unnest
-	v.sql += "EXISTS (SELECT 1 FROM unnest("
-	v.sql += collectionPath
-	v.sql += ") AS "
-	v.sql += alias
-	v.sql += " WHERE "
-
-	// Visit predicate
-	err := n.Predicate().Accept(v)
+	// Generate subquery with unnest
+	from, err := v.dialect.Unnest(collectionPath, alias)
 	if err != nil {
 		return err
 	}
+	v.sql += head
+	if selectList != nil {
+		err := selectList()
+		if err != nil {
+			return err
+		}
+	}
+	v.sql += " FROM "
+	v.sql += from
+
+	// Visit predicate
+	if where != nil {
+		v.sql += " WHERE "
+		err := where.Accept(v)
+		if err != nil {
+			return err
+		}
+	}
 
 	v.sql += ")"
 
@@ -195,7 +303,9 @@ func (v *PostgresqlVisitor) visitEmbeddedCollection(n s.CollectionNode, collecti
 }
 
 // visitRelationalCollection generates SQL for collections in separate tables
-func (v *PostgresqlVisitor) visitRelationalCollection(n s.CollectionNode, fieldName, collectionName string) error {
+func (v *PostgresqlVisitor) visitRelationalCollection(
+	n s.CollectionNode, fieldName, collectionName, head string, selectList func() error, where s.Visitable,
+) error {
 	mapping, _ := v.schema.Get(fieldName)
 
 	// Generate unique alias for this wildcard
@@ -219,8 +329,15 @@ func (v *PostgresqlVisitor) visitRelationalCollection(n s.CollectionNode, fieldN
 	v.inWildcard = true
 	v.wildcardAlias = alias
 
-	// Generate EXISTS subquery with JOIN conditions
-	v.sql += "EXISTS (SELECT 1 FROM "
+	// Generate subquery with JOIN conditions
+	v.sql += head
+	if selectList != nil {
+		err := selectList()
+		if err != nil {
+			return err
+		}
+	}
+	v.sql += " FROM "
 	v.sql += mapping.Table
 	v.sql += " AS "
 	v.sql += alias
@@ -241,12 +358,14 @@ func (v *PostgresqlVisitor) visitRelationalCollection(n s.CollectionNode, fieldN
 	}
 
 	// Add predicate
-	v.sql += " AND "
+	if where != nil {
+		v.sql += " AND "
 
-	// Visit predicate
-	err := n.Predicate().Accept(v)
-	if err != nil {
-		return err
+		// Visit predicate
+		err := where.Accept(v)
+		if err != nil {
+			return err
+		}
 	}
 
 	v.sql += ")"
@@ -347,7 +466,14 @@ func (v *PostgresqlVisitor) VisitField(n s.FieldNode) error {
 	} else {
 		// Normal field access
 		path := s.ExtractFieldPath(n)
-		name := strings.Join(path, ".")
+		if v.fieldResolver == nil {
+			v.sql += strings.Join(path, ".")
+			return nil
+		}
+		name, err := v.fieldResolver(path)
+		if err != nil {
+			return err
+		}
 		v.sql += name
 	}
 	return nil
@@ -360,13 +486,85 @@ func (v *PostgresqlVisitor) isItemReference(obj s.EmptiableObject) bool {
 	return isItem
 }
 
+// VisitValue compiles the value to the param, decimals of *big.Rat and durations of time.Duration
+// are passed as the text cast to numeric and interval, see Dialect.Cast.
 func (v *PostgresqlVisitor) VisitValue(n s.ValueNode) error {
 	value := n.Value()
+	sqlType := ""
+	switch typed := value.(type) {
+	case *big.Rat:
+		value, sqlType = NumericText(typed), "numeric"
+	case time.Duration:
+		value, sqlType = IntervalText(typed), "interval"
+	}
+	placeholder := v.dialect.Placeholder(len(v.parameters) + 1)
+	if v.questionPlaceholders {
+		placeholder = "?"
+	}
+	if sqlType != "" {
+		var err error
+		placeholder, err = v.dialect.Cast(placeholder, sqlType)
+		if err != nil {
+			return err
+		}
+	}
 	v.parameters = append(v.parameters, value)
-	v.sql += fmt.Sprintf("$%d", len(v.parameters))
+	v.sql += placeholder
 	return nil
 }
 
+// currentTimeSQL is the SQL of the functions of the current time, which the database resolves instead of the Clock.
+var currentTimeSQL = map[string]string{
+	s.FunctionNow:         "CURRENT_TIMESTAMP",
+	s.FunctionCurrentDate: "CURRENT_DATE",
+}
+
+// IntervalText returns the text of the interval of the duration, e.g. "720:00:00" for 30 days,
+// with the precision of microseconds as the interval of PostgreSQL.
+func IntervalText(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	hours, minutes, seconds := d/time.Hour, d%time.Hour/time.Minute, d%time.Minute/time.Second
+	text := fmt.Sprintf("%s%d:%02d:%02d", sign, hours, minutes, seconds)
+	if micros := d % time.Second / time.Microsecond; micros != 0 {
+		text += fmt.Sprintf(".%06d", micros)
+	}
+	return text
+}
+
+// numericScale is the number of the fractional digits of the decimals whose expansion does not terminate, e.g. 1/3.
+const numericScale = 32
+
+// NumericText returns the text of the numeric of the rational number, exact if its decimal expansion terminates,
+// otherwise rounded to 32 fractional digits.
+func NumericText(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	// The expansion terminates if the denominator has no prime factors other than 2 and 5,
+	// then the scale is the greater of their powers.
+	denom := new(big.Int).Set(r.Denom())
+	twos := denom.TrailingZeroBits()
+	denom.Rsh(denom, twos)
+	fives := uint(0)
+	five := big.NewInt(5)
+	quo, rem := new(big.Int), new(big.Int)
+	for {
+		quo.QuoRem(denom, five, rem)
+		if rem.Sign() != 0 {
+			break
+		}
+		denom.Set(quo)
+		fives++
+	}
+	if denom.Cmp(big.NewInt(1)) != 0 {
+		return r.FloatString(numericScale)
+	}
+	return r.FloatString(int(max(twos, fives)))
+}
+
 func (v *PostgresqlVisitor) VisitPrefix(node s.PrefixNode) error {
 	precedenceKey := v.getNodePrecedenceKey(node)
 	return v.visit(precedenceKey, func() error {
@@ -381,13 +579,24 @@ func (v *PostgresqlVisitor) VisitPrefix(node s.PrefixNode) error {
 }
 
 func (v *PostgresqlVisitor) VisitInfix(n s.InfixNode) error {
+	if n.Operator() == operators.OperatorIn || n.Operator() == operators.OperatorNotIn {
+		return v.visitIn(n)
+	}
+	sqlOp, err := v.dialect.Operator(n.Operator())
+	if err != nil {
+		return err
+	}
 	precedenceKey := v.getNodePrecedenceKey(n)
 	return v.visit(precedenceKey, func() error {
 		err := n.Left().Accept(v)
 		if err != nil {
 			return err
 		}
-		v.sql += fmt.Sprintf(" %s ", n.Operator())
+		v.sql += fmt.Sprintf(" %s ", sqlOp)
+		// The right operand of the same precedence is parenthesized, e.g. a - (b - c)
+		if n.Associativity() == s.LeftAssociative {
+			v.precedence++
+		}
 		err = n.Right().Accept(v)
 		if err != nil {
 			return err
@@ -396,6 +605,80 @@ func (v *PostgresqlVisitor) VisitInfix(n s.InfixNode) error {
 	})
 }
 
+// visitIn compiles membership to the comparison with the array param, so that the slice is a single param:
+// "status = ANY($1)" for IN and "status <> ALL($1)" for NOT IN, which keep the NULL semantics of IN and NOT IN.
+// The dialects without arrays get the params of the elements instead, see visitExpandedIn.
+func (v *PostgresqlVisitor) visitIn(n s.InfixNode) error {
+	if v.expandedLists {
+		return v.visit("IN NON", func() error {
+			err := n.Left().Accept(v)
+			if err != nil {
+				return err
+			}
+			v.sql += fmt.Sprintf(" %s ", n.Operator())
+			return n.Right().Accept(v)
+		})
+	}
+	if !v.dialect.ArrayParams() {
+		return v.visitExpandedIn(n)
+	}
+	sqlOp := "= ANY"
+	if n.Operator() == operators.OperatorNotIn {
+		sqlOp = "<> ALL"
+	}
+	return v.visit("= NON", func() error {
+		err := n.Left().Accept(v)
+		if err != nil {
+			return err
+		}
+		v.sql += fmt.Sprintf(" %s(", sqlOp)
+		outerPrecedence := v.precedence
+		v.precedence = 0
+		err = n.Right().Accept(v)
+		v.precedence = outerPrecedence
+		if err != nil {
+			return err
+		}
+		v.sql += ")"
+		return nil
+	})
+}
+
+// visitExpandedIn compiles membership to the list of the params of the elements of the value,
+// "status IN (?, ?)", and the empty list to the boolean literal, IN () is not valid in MySQL.
+func (v *PostgresqlVisitor) visitExpandedIn(n s.InfixNode) error {
+	list, ok := n.Right().(s.ValueNode)
+	if !ok {
+		return fmt.Errorf("operand of %s must be a value, got %T", n.Operator(), n.Right())
+	}
+	elements := reflect.ValueOf(list.Value())
+	if elements.Kind() != reflect.Slice && elements.Kind() != reflect.Array {
+		return fmt.Errorf("operand of %s must be a list, got %T", n.Operator(), list.Value())
+	}
+	if elements.Len() == 0 {
+		v.sql += v.dialect.BoolLiteral(n.Operator() == operators.OperatorNotIn)
+		return nil
+	}
+	return v.visit("IN NON", func() error {
+		err := n.Left().Accept(v)
+		if err != nil {
+			return err
+		}
+		v.sql += fmt.Sprintf(" %s (", n.Operator())
+		for i := range elements.Len() {
+			if i > 0 {
+				v.sql += ", "
+			}
+			err = v.VisitValue(s.Value(elements.Index(i).Interface()))
+			if err != nil {
+				return err
+			}
+		}
+		v.sql += ")"
+		return nil
+	})
+}
+
 func (v *PostgresqlVisitor) VisitPostfix(node s.PostfixNode) error {
 	precedenceKey := v.getNodePrecedenceKey(node)
 	return v.visit(precedenceKey, func() error {
@@ -409,6 +692,241 @@ func (v *PostgresqlVisitor) VisitPostfix(node s.PostfixNode) error {
 	})
 }
 
+// VisitFunction compiles the string function to LIKE or to the regular expression match, see FunctionPattern,
+// the pattern must be a value. Length compiles to length(), Coalesce to COALESCE(), Between to BETWEEN,
+// Case to CASE WHEN, and the aggregates of collections to the subqueries of their elements.
+func (v *PostgresqlVisitor) VisitFunction(n s.FunctionNode) error {
+	if s.IsAggregate(n.Name()) {
+		return v.visitAggregate(n)
+	}
+	if n.Name() == s.FunctionCase {
+		return v.visitCase(n)
+	}
+	if n.Name() == s.FunctionLength {
+		return v.visitLength(n)
+	}
+	if n.Name() == s.FunctionCoalesce {
+		return v.visitCoalesce(n)
+	}
+	if n.Name() == s.FunctionBetween {
+		return v.visitBetween(n)
+	}
+	if sql, ok := currentTimeSQL[n.Name()]; ok {
+		v.sql += sql
+		return nil
+	}
+	value, pattern, err := functionArgs(n)
+	if err != nil {
+		return err
+	}
+	sqlOp, param, err := FunctionPattern(n.Name(), pattern.Value())
+	if err != nil {
+		return err
+	}
+	precedenceKey := "LIKE NON"
+	if sqlOp != "LIKE" {
+		precedenceKey = sqlOp + " LEFT"
+	}
+	matchOp, clause := v.dialect.PatternMatch(sqlOp)
+	return v.visit(precedenceKey, func() error {
+		err := value.Accept(v)
+		if err != nil {
+			return err
+		}
+		v.sql += fmt.Sprintf(" %s ", matchOp)
+		err = v.VisitValue(s.Value(param))
+		if err != nil {
+			return err
+		}
+		v.sql += clause
+		return nil
+	})
+}
+
+// VisitExtension compiles the extension node by the emitter of its type, see WithPostgresqlEmitter,
+// or its fallback if there is none.
+func (v *PostgresqlVisitor) VisitExtension(n s.ExtensionNode) error {
+	return v.emitters.emit(v, n)
+}
+
+// WriteSQL appends the SQL to the compiled one, for the emitters of the extension nodes.
+func (v *PostgresqlVisitor) WriteSQL(sql string) {
+	v.sql += sql
+}
+
+// VisitArg compiles the node as the argument of a function, without parentheses,
+// for the emitters of the extension nodes.
+func (v *PostgresqlVisitor) VisitArg(n s.Visitable) error {
+	outerPrecedence := v.precedence
+	v.precedence = 0
+	defer func() { v.precedence = outerPrecedence }()
+	return n.Accept(v)
+}
+
+func (v *PostgresqlVisitor) visitLength(n s.FunctionNode) error {
+	if len(n.Args()) != 1 {
+		return fmt.Errorf("function \"%s\" requires 1 argument, got %d", n.Name(), len(n.Args()))
+	}
+	v.sql += v.dialect.LengthFunction() + "("
+	outerPrecedence := v.precedence
+	v.precedence = 0
+	err := n.Args()[0].Accept(v)
+	v.precedence = outerPrecedence
+	if err != nil {
+		return err
+	}
+	v.sql += ")"
+	return nil
+}
+
+// visitAggregate compiles the aggregate to the scalar subquery, e.g.
+// "(SELECT count(*) FROM unnest(Items) AS item_1 WHERE item_1.Active)" or "(SELECT sum(item_1.Price) FROM ...)".
+func (v *PostgresqlVisitor) visitAggregate(n s.FunctionNode) error {
+	if len(n.Args()) != 1 {
+		return fmt.Errorf("function \"%s\" requires 1 argument, got %d", n.Name(), len(n.Args()))
+	}
+	collection, ok := n.Args()[0].(s.CollectionNode)
+	if !ok {
+		return fmt.Errorf("function \"%s\" requires collection, got %T", n.Name(), n.Args()[0])
+	}
+	outerPrecedence := v.precedence
+	v.precedence = 0
+	defer func() { v.precedence = outerPrecedence }()
+	if n.Name() == s.FunctionCount {
+		return v.visitCollectionQuery(collection, "(SELECT count(*)", nil, collection.Predicate())
+	}
+	return v.visitCollectionQuery(collection, fmt.Sprintf("(SELECT %s(", n.Name()), func() error {
+		err := collection.Predicate().Accept(v)
+		if err != nil {
+			return err
+		}
+		v.sql += ")"
+		return nil
+	}, nil)
+}
+
+// visitCase compiles the chain of Case of otherwise to the WHEN clauses of one CASE.
+func (v *PostgresqlVisitor) visitCase(n s.FunctionNode) error {
+	outerPrecedence := v.precedence
+	v.precedence = 0
+	defer func() { v.precedence = outerPrecedence }()
+	v.sql += "CASE"
+	var otherwise s.Visitable = n
+	for {
+		node, ok := otherwise.(s.FunctionNode)
+		if !ok || node.Name() != s.FunctionCase {
+			break
+		}
+		if len(node.Args()) != 3 {
+			return fmt.Errorf("function \"%s\" requires 3 arguments, got %d", node.Name(), len(node.Args()))
+		}
+		v.sql += " WHEN "
+		err := node.Args()[0].Accept(v)
+		if err != nil {
+			return err
+		}
+		v.sql += " THEN "
+		err = node.Args()[1].Accept(v)
+		if err != nil {
+			return err
+		}
+		otherwise = node.Args()[2]
+	}
+	v.sql += " ELSE "
+	err := otherwise.Accept(v)
+	if err != nil {
+		return err
+	}
+	v.sql += " END"
+	return nil
+}
+
+func (v *PostgresqlVisitor) visitCoalesce(n s.FunctionNode) error {
+	if len(n.Args()) == 0 {
+		return fmt.Errorf("function \"%s\" requires at least 1 argument", n.Name())
+	}
+	v.sql += "COALESCE("
+	outerPrecedence := v.precedence
+	v.precedence = 0
+	defer func() { v.precedence = outerPrecedence }()
+	for i, arg := range n.Args() {
+		if i > 0 {
+			v.sql += ", "
+		}
+		err := arg.Accept(v)
+		if err != nil {
+			return err
+		}
+	}
+	v.sql += ")"
+	return nil
+}
+
+func (v *PostgresqlVisitor) visitBetween(n s.FunctionNode) error {
+	if len(n.Args()) != 3 {
+		return fmt.Errorf("function \"%s\" requires 3 arguments, got %d", n.Name(), len(n.Args()))
+	}
+	return v.visit("BETWEEN NON", func() error {
+		err := n.Args()[0].Accept(v)
+		if err != nil {
+			return err
+		}
+		v.sql += " BETWEEN "
+		err = n.Args()[1].Accept(v)
+		if err != nil {
+			return err
+		}
+		v.sql += " AND "
+		return n.Args()[2].Accept(v)
+	})
+}
+
+// FunctionPattern returns the SQL operator and the pattern param of the string function:
+// LIKE with the escaped pattern for startsWith, endsWith and contains,
+// ~ with the regular expression for match, anchored to the whole string, and for search.
+func FunctionPattern(name string, arg any) (sqlOp string, pattern any, err error) {
+	var prefix, suffix string
+	switch name {
+	case s.FunctionStartsWith:
+		sqlOp, suffix = "LIKE", "%"
+	case s.FunctionEndsWith:
+		sqlOp, prefix = "LIKE", "%"
+	case s.FunctionContains:
+		sqlOp, prefix, suffix = "LIKE", "%", "%"
+	case s.FunctionMatch:
+		sqlOp, prefix, suffix = "~", "^(?:", ")$"
+	case s.FunctionSearch:
+		sqlOp = "~"
+	default:
+		return "", nil, fmt.Errorf("function \"%s\" is not supported", name)
+	}
+	if arg == nil {
+		return sqlOp, nil, nil
+	}
+	argTyped, ok := arg.(string)
+	if !ok {
+		return "", nil, fmt.Errorf("function \"%s\" requires string argument, got %T", name, arg)
+	}
+	if sqlOp == "LIKE" {
+		argTyped = likeEscaper.Replace(argTyped)
+	}
+	return sqlOp, prefix + argTyped + suffix, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// functionArgs returns the arguments of the string function, the value and the pattern.
+func functionArgs(n s.FunctionNode) (s.Visitable, s.ValueNode, error) {
+	if len(n.Args()) != 2 {
+		return nil, s.ValueNode{}, fmt.Errorf("function \"%s\" requires 2 arguments, got %d", n.Name(), len(n.Args()))
+	}
+	pattern, ok := n.Args()[1].(s.ValueNode)
+	if !ok {
+		return nil, s.ValueNode{}, fmt.Errorf("pattern of function \"%s\" must be a value, got %T", n.Name(), n.Args()[1])
+	}
+	return n.Args()[0], pattern, nil
+}
+
 func (v PostgresqlVisitor) Result() (sql string, params []any, err error) {
 	return v.sql, v.parameters, nil
 }
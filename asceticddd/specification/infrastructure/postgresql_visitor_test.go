@@ -1,8 +1,10 @@
 package specification
 
 import (
+	"math/big"
 	"strings"
 	"testing"
+	"time"
 
 	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
 )
@@ -271,3 +273,268 @@ func TestComplexExpression(t *testing.T) {
 		t.Errorf("Expected 3 params, got %v", params)
 	}
 }
+
+func TestFunction(t *testing.T) {
+	obj := s.Object(s.GlobalScope(), "t")
+	expr := s.And(
+		s.StartsWith(s.Field(obj, "name"), s.Value("50%_off")),
+		s.Not(s.Match(s.Field(obj, "code"), s.Value("A[0-9]+"))),
+	)
+
+	sql, params, err := CompileToSQL(expr)
+	if err != nil {
+		t.Fatalf("CompileToSQL failed: %v", err)
+	}
+
+	expected := "t.name LIKE $1 AND NOT t.code ~ $2"
+	if sql != expected {
+		t.Errorf("Expected %q, got %q", expected, sql)
+	}
+	if len(params) != 2 || params[0] != `50\%\_off%` || params[1] != "^(?:A[0-9]+)$" {
+		t.Errorf("Unexpected params %v", params)
+	}
+
+	_, _, err = CompileToSQL(s.Contains(s.Field(obj, "name"), s.Field(obj, "nick")))
+	if err == nil {
+		t.Error("Expected error of pattern that is not a value")
+	}
+}
+
+func TestInfixOperatorIn(t *testing.T) {
+	obj := s.Object(s.GlobalScope(), "t")
+	expr := s.Not(s.Or(
+		s.In(s.Field(obj, "status"), s.Value([]string{"active", "pending"})),
+		s.NotIn(s.Field(obj, "role"), s.Value([]string{"root"})),
+	))
+
+	sql, params, err := CompileToSQL(expr)
+	if err != nil {
+		t.Fatalf("CompileToSQL failed: %v", err)
+	}
+
+	expected := "NOT (t.status = ANY($1) OR t.role <> ALL($2))"
+	if sql != expected {
+		t.Errorf("Expected %q, got %q", expected, sql)
+	}
+	if len(params) != 2 {
+		t.Errorf("Expected 2 params, got %v", params)
+	}
+}
+
+func TestBitwiseOperators(t *testing.T) {
+	obj := s.Object(s.GlobalScope(), "t")
+	expr := s.And(
+		s.Equal(s.BitAnd(s.Field(obj, "flags"), s.Value(4)), s.Value(4)),
+		s.NotEqual(s.BitXor(s.BitOr(s.Field(obj, "flags"), s.Value(1)), s.Value(2)), s.Value(0)),
+	)
+
+	sql, params, err := CompileToSQL(expr)
+	if err != nil {
+		t.Fatalf("CompileToSQL failed: %v", err)
+	}
+
+	expected := "t.flags & $1 = $2 AND t.flags | $3 # $4 != $5"
+	if sql != expected {
+		t.Errorf("Expected %q, got %q", expected, sql)
+	}
+	if len(params) != 5 {
+		t.Errorf("Expected 5 params, got %v", params)
+	}
+}
+
+func TestConcatAndLength(t *testing.T) {
+	obj := s.Object(s.GlobalScope(), "t")
+	expr := s.And(
+		s.GreaterThan(s.Length(s.Field(obj, "full_name")), s.Value(0)),
+		s.Equal(s.Concat(s.Concat(s.Field(obj, "tenant"), s.Value(":")), s.Field(obj, "code")), s.Value("acme:42")),
+	)
+
+	sql, params, err := CompileToSQL(expr)
+	if err != nil {
+		t.Fatalf("CompileToSQL failed: %v", err)
+	}
+
+	expected := "length(t.full_name) > $1 AND t.tenant || $2 || t.code = $3"
+	if sql != expected {
+		t.Errorf("Expected %q, got %q", expected, sql)
+	}
+	if len(params) != 3 {
+		t.Errorf("Expected 3 params, got %v", params)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	obj := s.Object(s.GlobalScope(), "t")
+	expr := s.Or(
+		s.Between(s.Field(obj, "age"), s.Value(18), s.Add(s.Field(obj, "retirement_age"), s.Value(1))),
+		s.Not(s.Between(s.Field(obj, "score"), s.Value(0), s.Value(10))),
+	)
+
+	sql, params, err := CompileToSQL(expr)
+	if err != nil {
+		t.Fatalf("CompileToSQL failed: %v", err)
+	}
+
+	expected := "t.age BETWEEN $1 AND t.retirement_age + $2 OR NOT t.score BETWEEN $3 AND $4"
+	if sql != expected {
+		t.Errorf("Expected %q, got %q", expected, sql)
+	}
+	if len(params) != 4 {
+		t.Errorf("Expected 4 params, got %v", params)
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	obj := s.Object(s.GlobalScope(), "t")
+	expr := s.GreaterThan(
+		s.Mul(s.Coalesce(s.Field(obj, "discount"), s.Add(s.Field(obj, "bonus"), s.Value(1))), s.Value(2)),
+		s.Value(10),
+	)
+
+	sql, params, err := CompileToSQL(expr)
+	if err != nil {
+		t.Fatalf("CompileToSQL failed: %v", err)
+	}
+
+	expected := "COALESCE(t.discount, t.bonus + $1) * $2 > $3"
+	if sql != expected {
+		t.Errorf("Expected %q, got %q", expected, sql)
+	}
+	if len(params) != 3 {
+		t.Errorf("Expected 3 params, got %v", params)
+	}
+}
+
+func TestCurrentTime(t *testing.T) {
+	obj := s.Object(s.GlobalScope(), "t")
+	expr := s.And(
+		s.LessThan(s.Add(s.Field(obj, "created_at"), s.Value(24*time.Hour)), s.Now()),
+		s.GreaterThanEqual(s.Field(obj, "due_at"), s.CurrentDate()),
+	)
+
+	sql, params, err := CompileToSQL(expr)
+	if err != nil {
+		t.Fatalf("CompileToSQL failed: %v", err)
+	}
+
+	expected := "t.created_at + $1::interval < CURRENT_TIMESTAMP AND t.due_at >= CURRENT_DATE"
+	if sql != expected {
+		t.Errorf("Expected %q, got %q", expected, sql)
+	}
+	if len(params) != 1 {
+		t.Errorf("Expected 1 param, got %v", params)
+	}
+}
+
+func TestCase(t *testing.T) {
+	obj := s.Object(s.GlobalScope(), "t")
+	quantity := s.Field(obj, "quantity")
+	expr := s.LessThanEqual(
+		s.Mul(quantity, s.Case(
+			s.GreaterThanEqual(quantity, s.Value(100)), s.Value(80),
+			s.Case(s.Or(s.Field(obj, "vip"), s.GreaterThanEqual(quantity, s.Value(10))), s.Value(90), s.Value(100)),
+		)),
+		s.Field(obj, "budget"),
+	)
+
+	sql, params, err := CompileToSQL(expr)
+	if err != nil {
+		t.Fatalf("CompileToSQL failed: %v", err)
+	}
+
+	expected := "t.quantity * CASE WHEN t.quantity >= $1 THEN $2 WHEN t.vip OR t.quantity >= $3 THEN $4 ELSE $5 END <= t.budget"
+	if sql != expected {
+		t.Errorf("Expected %q, got %q", expected, sql)
+	}
+	if len(params) != 5 {
+		t.Errorf("Expected 5 params, got %v", params)
+	}
+}
+
+func TestDecimalValues(t *testing.T) {
+	obj := s.Object(s.GlobalScope(), "t")
+	price, _ := new(big.Rat).SetString("19.99")
+	expr := s.GreaterThan(s.Mul(s.Field(obj, "price"), s.Value(big.NewRat(1, 3))), s.Value(price))
+
+	sql, params, err := CompileToSQL(expr)
+	if err != nil {
+		t.Fatalf("CompileToSQL failed: %v", err)
+	}
+
+	expected := "t.price * $1::numeric > $2::numeric"
+	if sql != expected {
+		t.Errorf("Expected %q, got %q", expected, sql)
+	}
+	if len(params) != 2 || params[0] != "0.33333333333333333333333333333333" || params[1] != "19.99" {
+		t.Errorf("Unexpected params %v", params)
+	}
+
+	for text, expected := range map[string]string{
+		"42": "42", "-0.5": "-0.5", "0.0625": "0.0625", "1/40": "0.025", "-2/3": "-0.66666666666666666666666666666667",
+	} {
+		r, _ := new(big.Rat).SetString(text)
+		if actual := NumericText(r); actual != expected {
+			t.Errorf("%s: expected %s, got %s", text, expected, actual)
+		}
+	}
+}
+
+func TestIntervalValues(t *testing.T) {
+	obj := s.Object(s.GlobalScope(), "t")
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	expr := s.LessThan(s.Add(s.Field(obj, "created_at"), s.Value(30*24*time.Hour)), s.Value(now))
+
+	sql, params, err := CompileToSQL(expr)
+	if err != nil {
+		t.Fatalf("CompileToSQL failed: %v", err)
+	}
+
+	expected := "t.created_at + $1::interval < $2"
+	if sql != expected {
+		t.Errorf("Expected %q, got %q", expected, sql)
+	}
+	if len(params) != 2 || params[0] != "720:00:00" || params[1] != now {
+		t.Errorf("Unexpected params %v", params)
+	}
+
+	for d, expected := range map[time.Duration]string{
+		90 * time.Minute: "1:30:00",
+		-(90*time.Minute + 1500*time.Microsecond): "-1:30:00.001500",
+		time.Nanosecond: "0:00:00",
+	} {
+		if actual := IntervalText(d); actual != expected {
+			t.Errorf("%v: expected %s, got %s", d, expected, actual)
+		}
+	}
+}
+
+func TestSimplified(t *testing.T) {
+	obj := s.Object(s.GlobalScope(), "t")
+	age := s.GreaterThanEqual(s.Field(obj, "age"), s.Value(18))
+	expr := s.And(
+		s.Or(age, s.And(age, s.Field(obj, "verified"))),
+		s.Not(s.Not(s.LessThan(s.Field(obj, "score"), s.Mul(s.Value(10), s.Value(5))))),
+		s.Case(s.Value(true), s.Value(true), s.Field(obj, "blocked")),
+	)
+
+	sql, params, err := CompileToSQL(expr)
+	if err != nil {
+		t.Fatalf("CompileToSQL failed: %v", err)
+	}
+	unsimplified := "(t.age >= $1 OR t.age >= $2 AND t.verified) AND NOT NOT t.score < $3 * $4 AND CASE WHEN $5 THEN $6 ELSE t.blocked END"
+	if sql != unsimplified || len(params) != 6 {
+		t.Errorf("Expected %q, got %q %v", unsimplified, sql, params)
+	}
+
+	sql, params, err = CompileToSQL(expr, Simplified())
+	if err != nil {
+		t.Fatalf("CompileToSQL failed: %v", err)
+	}
+	expected := "t.age >= $1 AND t.score < $2"
+	if sql != expected {
+		t.Errorf("Expected %q, got %q", expected, sql)
+	}
+	if len(params) != 2 || params[0] != 18 || params[1] != 50 {
+		t.Errorf("Unexpected params %v", params)
+	}
+}
@@ -65,6 +65,24 @@ func TestPostgresqlVisitor_Wildcard_All(t *testing.T) {
 	}
 }
 
+func TestPostgresqlVisitor_Wildcard_Every(t *testing.T) {
+	// spec.All(store.Items, func(item Item) bool { return item.Price > 1000 })
+	ast := s.Every(
+		s.Object(s.GlobalScope(), "Items"),
+		s.GreaterThan(s.Field(s.Item(), "Price"), s.Value(1000)),
+	)
+
+	sql, _, err := CompileToSQL(ast)
+	if err != nil {
+		t.Fatalf("CompileToSQL failed: %v", err)
+	}
+
+	expectedSQL := "NOT EXISTS (SELECT 1 FROM unnest(Items) AS item_1 WHERE NOT item_1.Price > $1)"
+	if sql != expectedSQL {
+		t.Errorf("Expected SQL:\n  %s\nGot:\n  %s", expectedSQL, sql)
+	}
+}
+
 func TestPostgresqlVisitor_Wildcard_ComplexPredicate(t *testing.T) {
 	// spec.Any(store.Items, func(item Item) bool {
 	//     return item.Price > 1000 && item.Active && item.Stock > 0
@@ -396,3 +414,62 @@ func TestPostgresqlVisitor_Wildcard_DoubleNested(t *testing.T) {
 		t.Errorf("Expected params [5000], got %v", params)
 	}
 }
+
+func TestPostgresqlVisitor_Wildcard_IndexUnsupported(t *testing.T) {
+	ast := s.Index(
+		s.Object(s.GlobalScope(), "Items"),
+		-1,
+		s.GreaterThan(s.Field(s.Item(), "Price"), s.Value(1000)),
+	)
+
+	err := ast.Accept(NewPostgresqlVisitor())
+	if err == nil {
+		t.Fatal("Expected error of index selector")
+	}
+}
+
+func TestPostgresqlVisitor_Aggregates(t *testing.T) {
+	items := s.Object(s.GlobalScope(), "Items")
+	// Count(store.Items, func(item Item) bool { return item.Active }) >= 3 &&
+	// Sum(store.Items, func(item Item) int { return item.Price * item.Stock }) > 1000
+	ast := s.And(
+		s.GreaterThanEqual(s.CountOf(items, s.Field(s.Item(), "Active")), s.Value(3)),
+		s.GreaterThan(s.SumOf(items, s.Mul(s.Field(s.Item(), "Price"), s.Field(s.Item(), "Stock"))), s.Value(1000)),
+	)
+
+	sql, params, err := CompileToSQL(ast)
+	if err != nil {
+		t.Fatalf("CompileToSQL failed: %v", err)
+	}
+
+	expectedSQL := "(SELECT count(*) FROM unnest(Items) AS item_1 WHERE item_1.Active) >= $1 AND " +
+		"(SELECT sum(item_2.Price * item_2.Stock) FROM unnest(Items) AS item_2) > $2"
+	if sql != expectedSQL {
+		t.Errorf("Expected SQL:\n  %s\nGot:\n  %s", expectedSQL, sql)
+	}
+	if len(params) != 2 {
+		t.Errorf("Expected 2 params, got %v", params)
+	}
+}
+
+func TestPostgresqlVisitor_AggregateInWildcard(t *testing.T) {
+	// Any(store.Categories, func(c Category) bool { return Max(c.Items, ...) > 100 && c.Active })
+	ast := s.Wildcard(
+		s.Object(s.GlobalScope(), "Categories"),
+		s.And(
+			s.GreaterThan(s.MaxOf(s.Object(s.Item(), "Items"), s.Field(s.Item(), "Price")), s.Value(100)),
+			s.Field(s.Item(), "Active"),
+		),
+	)
+
+	sql, _, err := CompileToSQL(ast)
+	if err != nil {
+		t.Fatalf("CompileToSQL failed: %v", err)
+	}
+
+	expectedSQL := "EXISTS (SELECT 1 FROM unnest(Categories) AS category_1 WHERE " +
+		"(SELECT max(item_2.Price) FROM unnest(category_1.Items) AS item_2) > $1 AND category_1.Active)"
+	if sql != expectedSQL {
+		t.Errorf("Expected SQL:\n  %s\nGot:\n  %s", expectedSQL, sql)
+	}
+}
@@ -386,3 +386,31 @@ func TestSchemaRegistry_CustomAlias(t *testing.T) {
 		t.Errorf("unexpected SQL:\nexpected: %s\ngot:      %s", expectedSQL, sql)
 	}
 }
+
+func TestSchemaRegistry_RelationalAggregate(t *testing.T) {
+	schema := NewSchemaRegistry("stores").
+		WithParentAlias("s").
+		RegisterRelational("Items", "items", "store_id", "id")
+
+	ast := s.Or(
+		s.GreaterThan(s.CountOf(s.Object(s.GlobalScope(), "Items"), s.Field(s.Item(), "Active")), s.Value(10)),
+		s.LessThan(s.MinOf(s.Object(s.GlobalScope(), "Items"), s.Field(s.Item(), "Price")), s.Value(5)),
+	)
+
+	visitor := NewPostgresqlVisitor(WithSchema(schema))
+	err := ast.Accept(visitor)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sql, _, err := visitor.Result()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedSQL := "(SELECT count(*) FROM items AS item_1 WHERE item_1.store_id = s.id AND item_1.Active) > $1 OR " +
+		"(SELECT min(item_2.Price) FROM items AS item_2 WHERE item_2.store_id = s.id) < $2"
+	if sql != expectedSQL {
+		t.Errorf("unexpected SQL:\nexpected: %s\ngot:      %s", expectedSQL, sql)
+	}
+}
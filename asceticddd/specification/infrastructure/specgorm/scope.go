@@ -0,0 +1,49 @@
+// Package specgorm drives GORM repositories by specifications.
+//
+// The package does not depend on GORM, the scope is generic over the DB with the methods of *gorm.DB:
+//
+//	db.Scopes(specgorm.Scope[*gorm.DB](spec)).Find(&orders)
+//
+// Fields are compiled to columns and comparisons to SQL by PostgresqlVisitor,
+// wildcards over relational collections are EXISTS subqueries on the association tables of the schema.
+package specgorm
+
+import (
+	"slices"
+
+	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+	specinfra "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/infrastructure"
+)
+
+// DB is the part of *gorm.DB used by the scope.
+type DB[T any] interface {
+	Where(query any, args ...any) T
+	AddError(err error) error
+}
+
+// Scope returns the GORM scope filtering by the specification, e.g. db.Scopes(Scope[*gorm.DB](spec)).
+// The schema maps the collections of wildcards to the association tables, see specinfra.SchemaRegistry.
+// The error of compilation is added to the DB, as GORM reports it by the result of the query.
+func Scope[T DB[T]](spec s.Visitable, opts ...specinfra.PostgresqlVisitorOption) func(T) T {
+	return func(db T) T {
+		sql, params, err := Compile(spec, opts...)
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		// Enclosed in parentheses not to be mixed with other conditions by precedence
+		return db.Where("("+sql+")", params...)
+	}
+}
+
+// Compile compiles the specification to the condition of GORM:
+// params are "?" and IN takes the slice param, which GORM expands into the list.
+func Compile(spec s.Visitable, opts ...specinfra.PostgresqlVisitorOption) (sql string, params []any, err error) {
+	opts = append(slices.Clip(opts), specinfra.QuestionPlaceholders(), specinfra.ExpandedLists())
+	v := specinfra.NewPostgresqlVisitor(opts...)
+	err = spec.Accept(v)
+	if err != nil {
+		return "", nil, err
+	}
+	return v.Result()
+}
@@ -0,0 +1,65 @@
+package specgorm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+	specinfra "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/infrastructure"
+)
+
+// fakeDB records the calls of the scope as *gorm.DB would build the statement
+type fakeDB struct {
+	query any
+	args  []any
+	err   error
+}
+
+func (db *fakeDB) Where(query any, args ...any) *fakeDB {
+	db.query = query
+	db.args = args
+	return db
+}
+
+func (db *fakeDB) AddError(err error) error {
+	db.err = errors.Join(db.err, err)
+	return db.err
+}
+
+func TestScope(t *testing.T) {
+	root := s.GlobalScope()
+	schema := specinfra.NewSchemaRegistry("orders").RegisterRelational("lines", "order_lines", "order_id", "id")
+	spec := s.And(
+		s.In(s.Field(root, "status"), s.Value([]string{"paid", "shipped"})),
+		s.Or(
+			s.GreaterThan(s.Field(root, "total"), s.Value(100)),
+			s.Wildcard(s.Object(root, "lines"), s.Equal(s.Field(s.Item(), "sku"), s.Value("A-1"))),
+		),
+	)
+
+	db := Scope[*fakeDB](spec, specinfra.WithSchema(schema))(&fakeDB{})
+	if db.err != nil {
+		t.Fatalf("Unexpected error: %v", db.err)
+	}
+	expected := "(status IN ? AND (total > ? OR EXISTS (SELECT 1 FROM order_lines AS line_1" +
+		" WHERE line_1.order_id = orders.id AND line_1.sku = ?)))"
+	if db.query != expected {
+		t.Errorf("Expected %q, got %q", expected, db.query)
+	}
+	if !reflect.DeepEqual(db.args, []any{[]string{"paid", "shipped"}, 100, "A-1"}) {
+		t.Errorf("Unexpected args %v", db.args)
+	}
+}
+
+func TestScopeError(t *testing.T) {
+	spec := s.Index(s.Object(s.GlobalScope(), "lines"), 0, s.Equal(s.Field(s.Item(), "sku"), s.Value("A-1")))
+
+	db := Scope[*fakeDB](spec)(&fakeDB{})
+	if db.err == nil {
+		t.Error("Expected error of unsupported selector")
+	}
+	if db.query != nil {
+		t.Errorf("Expected no condition, got %v", db.query)
+	}
+}
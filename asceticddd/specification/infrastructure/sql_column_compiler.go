@@ -0,0 +1,184 @@
+package specification
+
+import (
+	"fmt"
+	"strings"
+
+	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+)
+
+// ObjectMapping defines the table of a nested object joined to the table of its owner
+type ObjectMapping struct {
+	// Table is the name of the table of the object (e.g., "addresses")
+	Table string
+
+	// ForeignKeys defines the join condition (supports composite keys),
+	// ChildColumn is the column of the object table and ParentColumn is the column of the owner table
+	ForeignKeys []ForeignKeyPair
+
+	// Alias is optional custom alias of the joined table (defaults to the object path joined by "_")
+	Alias string
+}
+
+// ColumnRegistry maps the fields of an aggregate to the columns of plain relational tables
+type ColumnRegistry struct {
+	// Table is the main table name (e.g., "orders")
+	Table string
+
+	// Alias is the alias used for the main table in queries (e.g., "o" for "orders AS o")
+	Alias string
+
+	// columns maps the field path (e.g., "customer.name") to the column of the table of its object
+	columns map[string]string
+
+	// objects maps the object path (e.g., "customer") to its joined table
+	objects map[string]ObjectMapping
+}
+
+// NewColumnRegistry creates a new ColumnRegistry for the main table
+func NewColumnRegistry(table string) *ColumnRegistry {
+	return &ColumnRegistry{
+		Table:   table,
+		Alias:   "",
+		columns: make(map[string]string),
+		objects: make(map[string]ObjectMapping),
+	}
+}
+
+// WithAlias sets the main table alias
+func (r *ColumnRegistry) WithAlias(alias string) *ColumnRegistry {
+	r.Alias = alias
+	return r
+}
+
+// RegisterColumn maps the dot-separated field path to the column of the table of its object
+func (r *ColumnRegistry) RegisterColumn(fieldPath, column string) *ColumnRegistry {
+	r.columns[fieldPath] = column
+	return r
+}
+
+// RegisterObject maps the dot-separated object path to the table joined by simple FK
+func (r *ColumnRegistry) RegisterObject(objectPath, table, childColumn, parentColumn string) *ColumnRegistry {
+	r.objects[objectPath] = ObjectMapping{
+		Table: table,
+		ForeignKeys: []ForeignKeyPair{
+			{ChildColumn: childColumn, ParentColumn: parentColumn},
+		},
+	}
+	return r
+}
+
+// RegisterObjectMapping maps the dot-separated object path with full mapping configuration
+func (r *ColumnRegistry) RegisterObjectMapping(objectPath string, mapping ObjectMapping) *ColumnRegistry {
+	r.objects[objectPath] = mapping
+	return r
+}
+
+// Ref returns the reference to the main table (alias or table name)
+func (r *ColumnRegistry) Ref() string {
+	if r.Alias != "" {
+		return r.Alias
+	}
+	return r.Table
+}
+
+// NewSqlColumnCompiler creates the compiler of specifications to the WHERE clause over plain relational columns
+// instead of JSONB, fields are mapped to the columns by the registry and the tables of their objects are joined.
+func NewSqlColumnCompiler(columns *ColumnRegistry, opts ...PostgresqlVisitorOption) *SqlColumnCompiler {
+	c := &SqlColumnCompiler{
+		columns: columns,
+		aliases: make(map[string]string),
+	}
+	opts = append(opts, WithFieldResolver(c.resolveField))
+	c.PostgresqlVisitor = NewPostgresqlVisitor(opts...)
+	return c
+}
+
+// SqlColumnCompiler is PostgresqlVisitor resolving fields to the columns of ColumnRegistry.
+// Result returns the WHERE clause, and From returns the FROM clause with the joins it requires.
+type SqlColumnCompiler struct {
+	*PostgresqlVisitor
+	columns *ColumnRegistry
+	// aliases maps the joined object paths to the aliases of their tables
+	aliases map[string]string
+	joins   []string
+}
+
+func (c *SqlColumnCompiler) resolveField(path []string) (string, error) {
+	column, ok := c.columns.columns[strings.Join(path, ".")]
+	if !ok {
+		return "", fmt.Errorf("field \"%s\" is not mapped to a column", strings.Join(path, "."))
+	}
+	ref, err := c.join(path[:len(path)-1])
+	if err != nil {
+		return "", err
+	}
+	return ref + "." + column, nil
+}
+
+// join joins the tables of the object chain once and returns the alias of the table of the object
+func (c *SqlColumnCompiler) join(objectPath []string) (string, error) {
+	if len(objectPath) == 0 {
+		return c.columns.Ref(), nil
+	}
+	key := strings.Join(objectPath, ".")
+	if alias, ok := c.aliases[key]; ok {
+		return alias, nil
+	}
+	mapping, ok := c.columns.objects[key]
+	if !ok {
+		return "", fmt.Errorf("object \"%s\" is not mapped to a table", key)
+	}
+	parentRef, err := c.join(objectPath[:len(objectPath)-1])
+	if err != nil {
+		return "", err
+	}
+	alias := mapping.Alias
+	if alias == "" {
+		alias = strings.Join(objectPath, "_")
+	}
+	conditions := make([]string, len(mapping.ForeignKeys))
+	for i, fk := range mapping.ForeignKeys {
+		conditions[i] = fmt.Sprintf("%s.%s = %s.%s", alias, fk.ChildColumn, parentRef, fk.ParentColumn)
+	}
+	c.joins = append(c.joins, fmt.Sprintf(
+		"LEFT JOIN %s AS %s ON %s", mapping.Table, alias, strings.Join(conditions, " AND "),
+	))
+	c.aliases[key] = alias
+	return alias, nil
+}
+
+// Joins returns the joins of the tables of the objects whose fields are compiled, in order of their first use
+func (c *SqlColumnCompiler) Joins() []string {
+	return c.joins
+}
+
+// From returns the FROM clause of the main table with the joins, e.g. "orders AS o LEFT JOIN ..."
+func (c *SqlColumnCompiler) From() string {
+	from := c.columns.Table
+	if c.columns.Alias != "" {
+		from += " AS " + c.columns.Alias
+	}
+	for _, join := range c.joins {
+		from += " " + join
+	}
+	return from
+}
+
+// CompileToColumns compiles AST to the WHERE and FROM clauses over the columns of the registry
+func CompileToColumns(columns *ColumnRegistry, exp s.Visitable, opts ...PostgresqlVisitorOption) (where, from string, params []any, err error) {
+	c := NewSqlColumnCompiler(columns, opts...)
+	exp, err = c.simplify(exp)
+	if err != nil {
+		return "", "", nil, err
+	}
+	err = exp.Accept(c)
+	if err != nil {
+		return "", "", nil, err
+	}
+	where, params, err = c.Result()
+	if err != nil {
+		return "", "", nil, err
+	}
+	return where, c.From(), params, nil
+}
@@ -0,0 +1,108 @@
+package specification
+
+import (
+	"reflect"
+	"testing"
+
+	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+)
+
+func newOrderColumns() *ColumnRegistry {
+	return NewColumnRegistry("orders").
+		WithAlias("o").
+		RegisterColumn("status", "status").
+		RegisterColumn("total", "total_amount").
+		RegisterColumn("customer.name", "full_name").
+		RegisterColumn("customer.address.city", "city").
+		RegisterObject("customer", "customers", "id", "customer_id").
+		RegisterObjectMapping("customer.address", ObjectMapping{
+			Table: "addresses",
+			ForeignKeys: []ForeignKeyPair{
+				{ChildColumn: "tenant_id", ParentColumn: "tenant_id"},
+				{ChildColumn: "customer_id", ParentColumn: "id"},
+			},
+			Alias: "a",
+		})
+}
+
+func TestSqlColumnCompiler(t *testing.T) {
+	root := s.GlobalScope()
+	customer := s.Object(root, "customer")
+	exp := s.And(
+		s.Equal(s.Field(root, "status"), s.Value("paid")),
+		s.Or(
+			s.Equal(s.Field(customer, "name"), s.Value("John")),
+			s.Equal(s.Field(s.Object(customer, "address"), "city"), s.Value("Berlin")),
+		),
+		s.GreaterThan(s.Field(root, "total"), s.Value(100)),
+	)
+
+	where, from, params, err := CompileToColumns(newOrderColumns(), exp)
+	if err != nil {
+		t.Fatalf("CompileToColumns failed: %v", err)
+	}
+
+	expectedWhere := "o.status = $1 AND (customer.full_name = $2 OR a.city = $3) AND o.total_amount > $4"
+	if where != expectedWhere {
+		t.Errorf("Expected WHERE %q, got %q", expectedWhere, where)
+	}
+	expectedFrom := "orders AS o" +
+		" LEFT JOIN customers AS customer ON customer.id = o.customer_id" +
+		" LEFT JOIN addresses AS a ON a.tenant_id = customer.tenant_id AND a.customer_id = customer.id"
+	if from != expectedFrom {
+		t.Errorf("Expected FROM %q, got %q", expectedFrom, from)
+	}
+	if !reflect.DeepEqual(params, []any{"paid", "John", "Berlin", 100}) {
+		t.Errorf("Unexpected params %v", params)
+	}
+}
+
+func TestSqlColumnCompilerJoinsOnce(t *testing.T) {
+	customer := s.Object(s.GlobalScope(), "customer")
+	c := NewSqlColumnCompiler(newOrderColumns())
+	err := s.And(
+		s.Equal(s.Field(customer, "name"), s.Value("John")),
+		s.NotEqual(s.Field(customer, "name"), s.Value("Jane")),
+	).Accept(c)
+	if err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	if len(c.Joins()) != 1 {
+		t.Errorf("Expected one join, got %v", c.Joins())
+	}
+}
+
+func TestSqlColumnCompilerRelationalCollection(t *testing.T) {
+	columns := NewColumnRegistry("orders").WithAlias("o").RegisterColumn("status", "status")
+	schema := NewSchemaRegistry("orders").WithParentAlias("o").RegisterRelational("lines", "order_lines", "order_id", "id")
+	root := s.GlobalScope()
+	exp := s.And(
+		s.Equal(s.Field(root, "status"), s.Value("paid")),
+		s.Wildcard(s.Object(root, "lines"), s.GreaterThan(s.Field(s.Item(), "price"), s.Value(100))),
+	)
+
+	where, from, _, err := CompileToColumns(columns, exp, WithSchema(schema))
+	if err != nil {
+		t.Fatalf("CompileToColumns failed: %v", err)
+	}
+	expected := "o.status = $1 AND EXISTS (SELECT 1 FROM order_lines AS line_1 WHERE line_1.order_id = o.id AND line_1.price > $2)"
+	if where != expected {
+		t.Errorf("Expected WHERE %q, got %q", expected, where)
+	}
+	if from != "orders AS o" {
+		t.Errorf("Expected FROM without joins, got %q", from)
+	}
+}
+
+func TestSqlColumnCompilerUnmapped(t *testing.T) {
+	root := s.GlobalScope()
+	columns := NewColumnRegistry("orders").RegisterColumn("customer.name", "full_name")
+	for _, exp := range []s.Visitable{
+		s.Equal(s.Field(root, "missing"), s.Value(1)),
+		s.Equal(s.Field(s.Object(root, "customer"), "name"), s.Value("John")),
+	} {
+		if _, _, _, err := CompileToColumns(columns, exp); err == nil {
+			t.Error("Expected error of unmapped field or object")
+		}
+	}
+}
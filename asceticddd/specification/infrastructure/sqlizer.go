@@ -0,0 +1,37 @@
+package specification
+
+import (
+	"slices"
+
+	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+)
+
+// Sqlizer is the specification as the predicate of query builders, it implements squirrel.Sqlizer
+// without depending on squirrel, e.g. squirrel.Select("*").From("orders").Where(NewSqlizer(exp)),
+// and its ToSql is the literal of goqu, e.g. goqu.L(sql, params...).
+//
+// Params are rendered as "?", so that the builder numbers the placeholders of the whole query,
+// e.g. by squirrel.Dollar, and the predicate is enclosed in parentheses to be composed with other conditions.
+type Sqlizer struct {
+	exp  s.Visitable
+	opts []PostgresqlVisitorOption
+}
+
+// NewSqlizer creates the Sqlizer of AST compiled by PostgresqlVisitor with the options, e.g. WithSchema.
+func NewSqlizer(exp s.Visitable, opts ...PostgresqlVisitorOption) Sqlizer {
+	return Sqlizer{exp: exp, opts: opts}
+}
+
+func (z Sqlizer) ToSql() (sql string, params []any, err error) {
+	opts := append(slices.Clip(z.opts), QuestionPlaceholders())
+	v := NewPostgresqlVisitor(opts...)
+	err = z.exp.Accept(v)
+	if err != nil {
+		return "", nil, err
+	}
+	sql, params, err = v.Result()
+	if err != nil {
+		return "", nil, err
+	}
+	return "(" + sql + ")", params, nil
+}
@@ -0,0 +1,54 @@
+package specification
+
+import (
+	"reflect"
+	"testing"
+
+	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+)
+
+// sqlizer is squirrel.Sqlizer
+type sqlizer interface {
+	ToSql() (string, []any, error)
+}
+
+func TestSqlizer(t *testing.T) {
+	root := s.GlobalScope()
+	exp := s.Or(
+		s.Equal(s.Field(root, "status"), s.Value("paid")),
+		s.And(
+			s.GreaterThan(s.Field(root, "total"), s.Value(100)),
+			s.In(s.Field(root, "region"), s.Value([]string{"eu", "us"})),
+		),
+	)
+
+	var z sqlizer = NewSqlizer(exp)
+	sql, params, err := z.ToSql()
+	if err != nil {
+		t.Fatalf("ToSql failed: %v", err)
+	}
+	expected := "(status = ? OR total > ? AND region = ANY(?))"
+	if sql != expected {
+		t.Errorf("Expected %q, got %q", expected, sql)
+	}
+	if !reflect.DeepEqual(params, []any{"paid", 100, []string{"eu", "us"}}) {
+		t.Errorf("Unexpected params %v", params)
+	}
+}
+
+func TestSqlizerWithSchema(t *testing.T) {
+	schema := NewSchemaRegistry("orders").WithParentAlias("o").RegisterRelational("lines", "order_lines", "order_id", "id")
+	exp := s.Wildcard(s.Object(s.GlobalScope(), "lines"), s.GreaterThan(s.Field(s.Item(), "price"), s.Value(100)))
+
+	sql, params, err := NewSqlizer(exp, WithSchema(schema)).ToSql()
+	if err != nil {
+		t.Fatalf("ToSql failed: %v", err)
+	}
+	expected := "(EXISTS (SELECT 1 FROM order_lines AS line_1 WHERE line_1.order_id = o.id AND line_1.price > ?))"
+	if sql != expected {
+		t.Errorf("Expected %q, got %q", expected, sql)
+	}
+	if len(params) != 1 {
+		t.Errorf("Expected one param, got %v", params)
+	}
+}
@@ -129,6 +129,27 @@ func (v *TransformVisitor) VisitInfix(n s.InfixNode) error {
 	return nil
 }
 
+func (v *TransformVisitor) VisitFunction(n s.FunctionNode) error {
+	args := make([]s.Visitable, 0, len(n.Args()))
+	for _, arg := range n.Args() {
+		err := arg.Accept(v)
+		if err != nil {
+			return err
+		}
+		if _, ok := v.currentNode.(CompositeExpressionNode); ok {
+			return fmt.Errorf("function \"%s\" is not supported for composite expressions", n.Name())
+		}
+		args = append(args, v.currentNode)
+	}
+	v.currentNode = s.Function(n.Name(), args...)
+	return nil
+}
+
+// VisitExtension transforms the fallback of the extension node, the context knows only the built-in nodes.
+func (v *TransformVisitor) VisitExtension(n s.ExtensionNode) error {
+	return s.VisitFallback(n, v)
+}
+
 func (v TransformVisitor) Result() (s.Visitable, error) {
 	return v.currentNode, nil
 }
@@ -0,0 +1,277 @@
+// Package spectest checks that the compiled SQL of the specifications selects the rows
+// matched by the in-memory evaluation, on the random specifications of the random rows.
+package spectest
+
+import (
+	"errors"
+	"math/rand/v2"
+
+	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+)
+
+// ColumnType is the type of the column of the fixture table and of the field of the specification.
+type ColumnType string
+
+const (
+	Integer ColumnType = "integer"
+	Text    ColumnType = "text"
+	Boolean ColumnType = "boolean"
+)
+
+var ErrNoColumn = errors.New("no column of the type")
+
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+// DefaultColumns are the columns of the fixture table by default.
+var DefaultColumns = []Column{
+	{"a", Integer},
+	{"b", Integer},
+	{"name", Text},
+	{"active", Boolean},
+}
+
+// Predicate generates the predicate of the depth, e.g. of a custom operator, see WithPredicate.
+type Predicate func(g *Generator, depth int) s.Visitable
+
+type GeneratorOption func(*Generator)
+
+func WithColumns(columns ...Column) GeneratorOption {
+	return func(g *Generator) {
+		g.columns = columns
+	}
+}
+
+// WithMaxDepth limits the nesting of the generated expressions, 3 by default.
+func WithMaxDepth(depth int) GeneratorOption {
+	return func(g *Generator) {
+		g.maxDepth = depth
+	}
+}
+
+// WithNullRate sets the probability of NULL of the generated rows, 0.2 by default.
+func WithNullRate(rate float64) GeneratorOption {
+	return func(g *Generator) {
+		g.nullRate = rate
+	}
+}
+
+// WithPredicate adds the predicate to the random choices of the generator, e.g. of the custom operator
+// registered in the registry of the evaluation and compiled by the visitor:
+//
+//	spectest.WithPredicate(func(g *spectest.Generator, depth int) s.Visitable {
+//		return s.NewInfixNode(g.Expression(spectest.Text, depth-1), "~*", s.Value("^a"), s.NonAssociative)
+//	})
+func WithPredicate(predicate Predicate) GeneratorOption {
+	return func(g *Generator) {
+		g.predicates = append(g.predicates, predicate)
+	}
+}
+
+// Generator generates the random specifications of the fields of the columns and the random rows of the columns.
+// The generated expressions refer to a field in each comparison, so the types of the SQL params are inferred,
+// and the values are small, so the arithmetic does not overflow.
+type Generator struct {
+	rand       *rand.Rand
+	columns    []Column
+	maxDepth   int
+	nullRate   float64
+	predicates []Predicate
+}
+
+// NewGenerator creates the generator, the same seed generates the same specifications and rows.
+func NewGenerator(seed uint64, opts ...GeneratorOption) *Generator {
+	g := &Generator{
+		rand:     rand.New(rand.NewPCG(seed, seed)),
+		columns:  DefaultColumns,
+		maxDepth: 3,
+		nullRate: 0.2,
+	}
+	for i := range opts {
+		opts[i](g)
+	}
+	g.predicates = append(g.builtinPredicates(), g.predicates...)
+	return g
+}
+
+func (g *Generator) Rand() *rand.Rand {
+	return g.rand
+}
+
+func (g *Generator) Columns() []Column {
+	return g.columns
+}
+
+// Specification generates the random predicate of the max depth.
+func (g *Generator) Specification() s.Visitable {
+	return g.Predicate(g.maxDepth)
+}
+
+// Predicate generates the random predicate, AND, OR and NOT of the predicates while the depth allows.
+func (g *Generator) Predicate(depth int) s.Visitable {
+	if depth > 1 {
+		switch g.rand.IntN(4) {
+		case 0:
+			return s.And(g.Predicate(depth-1), g.Predicate(depth-1))
+		case 1:
+			return s.Or(g.Predicate(depth-1), g.Predicate(depth-1))
+		case 2:
+			return s.Not(g.Predicate(depth - 1))
+		}
+	}
+	return g.predicates[g.rand.IntN(len(g.predicates))](g, depth)
+}
+
+func (g *Generator) builtinPredicates() []Predicate {
+	var predicates []Predicate
+	if g.hasColumn(Integer) {
+		predicates = append(predicates, comparison(Integer), membership(Integer), nullCheck(Integer),
+			func(g *Generator, depth int) s.Visitable {
+				return s.Between(g.Expression(Integer, depth-1), s.Value(g.Value(Integer)), s.Value(g.Value(Integer)))
+			})
+	}
+	if g.hasColumn(Text) {
+		predicates = append(predicates, comparison(Text), membership(Text), nullCheck(Text))
+	}
+	if g.hasColumn(Boolean) {
+		predicates = append(predicates, nullCheck(Boolean),
+			func(g *Generator, depth int) s.Visitable {
+				return g.Field(Boolean)
+			},
+			func(g *Generator, depth int) s.Visitable {
+				return s.Equal(g.Field(Boolean), s.Value(g.Value(Boolean)))
+			})
+	}
+	return predicates
+}
+
+var comparisons = []func(left, right s.Visitable) s.InfixNode{
+	s.Equal, s.NotEqual, s.GreaterThan, s.GreaterThanEqual, s.LessThan, s.LessThanEqual,
+}
+
+func comparison(columnType ColumnType) Predicate {
+	return func(g *Generator, depth int) s.Visitable {
+		compare := comparisons[g.rand.IntN(len(comparisons))]
+		left := g.Expression(columnType, depth-1)
+		var right s.Visitable = s.Value(g.Value(columnType))
+		if g.rand.IntN(3) == 0 {
+			right = g.Expression(columnType, depth-1)
+		}
+		if g.rand.IntN(2) == 0 {
+			left, right = right, left
+		}
+		return compare(left, right)
+	}
+}
+
+func membership(columnType ColumnType) Predicate {
+	return func(g *Generator, depth int) s.Visitable {
+		values := g.Values(columnType, 1+g.rand.IntN(3))
+		if g.rand.IntN(2) == 0 {
+			return s.NotIn(g.Expression(columnType, depth-1), s.Value(values))
+		}
+		return s.In(g.Expression(columnType, depth-1), s.Value(values))
+	}
+}
+
+func nullCheck(columnType ColumnType) Predicate {
+	return func(g *Generator, depth int) s.Visitable {
+		if g.rand.IntN(2) == 0 {
+			return s.IsNotNull(g.Field(columnType))
+		}
+		return s.IsNull(g.Field(columnType))
+	}
+}
+
+var arithmetic = []func(left, right s.Visitable) s.InfixNode{s.Add, s.Sub, s.Mul}
+
+// Expression generates the expression of the type which refers to a field,
+// the integer one is the arithmetic of the fields and the values while the depth allows.
+func (g *Generator) Expression(columnType ColumnType, depth int) s.Visitable {
+	if columnType != Integer || depth < 1 || g.rand.IntN(2) == 0 {
+		return g.Field(columnType)
+	}
+	operator := arithmetic[g.rand.IntN(len(arithmetic))]
+	var right s.Visitable = s.Value(g.Value(Integer))
+	if g.rand.IntN(2) == 0 {
+		right = g.Expression(Integer, depth-1)
+	}
+	return operator(g.Expression(Integer, depth-1), right)
+}
+
+// Field returns the field of the random column of the type, it panics with ErrNoColumn if there is none.
+func (g *Generator) Field(columnType ColumnType) s.FieldNode {
+	var names []string
+	for _, column := range g.columns {
+		if column.Type == columnType {
+			names = append(names, column.Name)
+		}
+	}
+	if len(names) == 0 {
+		panic(ErrNoColumn)
+	}
+	return s.Field(s.GlobalScope(), names[g.rand.IntN(len(names))])
+}
+
+// Value returns the random value of the type, which is not NULL.
+func (g *Generator) Value(columnType ColumnType) any {
+	switch columnType {
+	case Integer:
+		return g.rand.IntN(21) - 10
+	case Text:
+		return textValues[g.rand.IntN(len(textValues))]
+	case Boolean:
+		return g.rand.IntN(2) == 0
+	}
+	return nil
+}
+
+// Values returns the slice of the random values of the type, e.g. []int of Integer, the list of IN.
+func (g *Generator) Values(columnType ColumnType, n int) any {
+	switch columnType {
+	case Integer:
+		values := make([]int, n)
+		for i := range values {
+			values[i] = g.Value(Integer).(int)
+		}
+		return values
+	case Text:
+		values := make([]string, n)
+		for i := range values {
+			values[i] = g.Value(Text).(string)
+		}
+		return values
+	}
+	values := make([]any, n)
+	for i := range values {
+		values[i] = g.Value(columnType)
+	}
+	return values
+}
+
+// textValues differ in case and prefix, so the order of the byte strings is checked.
+var textValues = []string{"", "a", "ab", "b", "B", "ba", "abc", "Z"}
+
+// Row generates the values of the columns, NULL by the null rate.
+func (g *Generator) Row() map[string]any {
+	row := make(map[string]any, len(g.columns))
+	for _, column := range g.columns {
+		if g.rand.Float64() < g.nullRate {
+			row[column.Name] = nil
+		} else {
+			row[column.Name] = g.Value(column.Type)
+		}
+	}
+	return row
+}
+
+func (g *Generator) hasColumn(columnType ColumnType) bool {
+	for _, column := range g.columns {
+		if column.Type == columnType {
+			return true
+		}
+	}
+	return false
+}
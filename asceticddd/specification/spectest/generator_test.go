@@ -0,0 +1,58 @@
+package spectest
+
+import (
+	"reflect"
+	"testing"
+
+	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+	infra "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/infrastructure"
+)
+
+func TestGeneratorIsDeterministic(t *testing.T) {
+	g1, g2 := NewGenerator(42), NewGenerator(42)
+	for range 10 {
+		if spec1, spec2 := g1.Specification(), g2.Specification(); !reflect.DeepEqual(spec1, spec2) {
+			t.Fatalf("Expected the same specifications, got %#v and %#v", spec1, spec2)
+		}
+		if row1, row2 := g1.Row(), g2.Row(); !reflect.DeepEqual(row1, row2) {
+			t.Fatalf("Expected the same rows, got %v and %v", row1, row2)
+		}
+	}
+}
+
+func TestGeneratedSpecifications(t *testing.T) {
+	g := NewGenerator(7)
+	harness := NewHarness()
+	rows := make([]map[string]any, 20)
+	for i := range rows {
+		rows[i] = g.Row()
+	}
+	matched, unmatched := 0, 0
+	for range 500 {
+		ast := g.Specification()
+		ids, err := harness.evaluate(ast, rows)
+		if err != nil {
+			t.Fatalf("Evaluation of %#v failed: %v", ast, err)
+		}
+		matched += len(ids)
+		unmatched += len(rows) - len(ids)
+		if _, _, err := infra.CompileToSQL(ast); err != nil {
+			t.Fatalf("Compilation of %#v failed: %v", ast, err)
+		}
+	}
+	if matched == 0 || unmatched == 0 {
+		t.Errorf("Expected both matched and unmatched rows, got %d and %d", matched, unmatched)
+	}
+}
+
+func TestWithPredicate(t *testing.T) {
+	marker := s.Equal(s.Field(s.GlobalScope(), "flag"), s.Value(true))
+	g := NewGenerator(1, WithColumns(Column{"flag", Boolean}), WithMaxDepth(1),
+		WithPredicate(func(g *Generator, depth int) s.Visitable { return marker }))
+	for range 50 {
+		if reflect.DeepEqual(g.Specification(), marker) {
+			return
+		}
+	}
+	t.Error("Expected the custom predicate to be generated")
+}
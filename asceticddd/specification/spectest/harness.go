@@ -0,0 +1,234 @@
+package spectest
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	s "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain/operators"
+	infra "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/infrastructure"
+)
+
+// columnDefinitions are the PostgreSQL types of the columns, the text is compared by bytes, as in Go.
+var columnDefinitions = map[ColumnType]string{
+	Integer: "bigint",
+	Text:    `text COLLATE "C"`,
+	Boolean: "boolean",
+}
+
+type Option func(*Harness)
+
+// WithTable sets the name of the temporary fixture table, "spectest_fixture" by default.
+func WithTable(table string) Option {
+	return func(h *Harness) {
+		h.table = table
+	}
+}
+
+// WithRows sets the number of the rows of the fixture table, 50 by default.
+func WithRows(rows int) Option {
+	return func(h *Harness) {
+		h.rows = rows
+	}
+}
+
+// WithRegistry sets the registry of the in-memory evaluation, e.g. with the custom operators.
+func WithRegistry(registry *operators.OperatorRegistry) Option {
+	return func(h *Harness) {
+		h.registry = registry
+	}
+}
+
+// WithCompileOptions sets the options of the compilation to SQL, see infrastructure.CompileToSQL.
+func WithCompileOptions(opts ...infra.PostgresqlVisitorOption) Option {
+	return func(h *Harness) {
+		h.compileOptions = opts
+	}
+}
+
+// WithGeneratorOptions sets the options of the generator of the specifications and the rows.
+func WithGeneratorOptions(opts ...GeneratorOption) Option {
+	return func(h *Harness) {
+		h.generatorOptions = opts
+	}
+}
+
+// Harness checks that the compiled SQL of the random specifications selects the rows of the fixture table
+// which are matched by the in-memory evaluation:
+//
+//	err := spectest.NewHarness().Check(conn, seed, 1000)
+//
+// A mismatch is *Divergence, which is reproduced by the same seed.
+type Harness struct {
+	table            string
+	rows             int
+	registry         *operators.OperatorRegistry
+	compileOptions   []infra.PostgresqlVisitorOption
+	generatorOptions []GeneratorOption
+}
+
+func NewHarness(opts ...Option) *Harness {
+	h := &Harness{
+		table:    "spectest_fixture",
+		rows:     50,
+		registry: operators.NewDefaultRegistry(),
+	}
+	for i := range opts {
+		opts[i](h)
+	}
+	return h
+}
+
+// Check creates the temporary fixture table of the random rows, and checks the random specifications
+// of the iterations on them. Both are generated by the seed.
+func (h *Harness) Check(conn session.DbConnection, seed uint64, iterations int) error {
+	g := NewGenerator(seed, h.generatorOptions...)
+	rows, err := h.setup(conn, g)
+	if err != nil {
+		return err
+	}
+	defer func() { _, _ = conn.Exec("DROP TABLE IF EXISTS " + h.table) }()
+	for i := range iterations {
+		ast := g.Specification()
+		expected, err := h.evaluate(ast, rows)
+		if err != nil {
+			return fmt.Errorf("iteration %d of seed %d: %w", i, seed, err)
+		}
+		sql, params, err := infra.CompileToSQL(ast, h.compileOptions...)
+		if err != nil {
+			return fmt.Errorf("iteration %d of seed %d: %w", i, seed, err)
+		}
+		actual, err := h.query(conn, sql, params)
+		if err != nil {
+			return fmt.Errorf("iteration %d of seed %d: %s: %w", i, seed, sql, err)
+		}
+		if !slices.Equal(expected, actual) {
+			return &Divergence{
+				Seed: seed, Iteration: i, Specification: ast, SQL: sql, Params: params,
+				Expected: expected, Actual: actual, rows: rows,
+			}
+		}
+	}
+	return nil
+}
+
+func (h *Harness) setup(conn session.DbConnection, g *Generator) ([]map[string]any, error) {
+	columns := g.Columns()
+	definitions := []string{"id integer PRIMARY KEY"}
+	names := []string{"id"}
+	placeholders := []string{"$1"}
+	for i, column := range columns {
+		definitions = append(definitions, column.Name+" "+columnDefinitions[column.Type])
+		names = append(names, column.Name)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", i+2))
+	}
+	_, err := conn.Exec("DROP TABLE IF EXISTS " + h.table)
+	if err != nil {
+		return nil, err
+	}
+	_, err = conn.Exec(fmt.Sprintf("CREATE TEMPORARY TABLE %s (%s)", h.table, strings.Join(definitions, ", ")))
+	if err != nil {
+		return nil, err
+	}
+	insert := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s)", h.table, strings.Join(names, ", "), strings.Join(placeholders, ", "),
+	)
+	rows := make([]map[string]any, h.rows)
+	for i := range rows {
+		rows[i] = g.Row()
+		values := []any{i}
+		for _, column := range columns {
+			values = append(values, rows[i][column.Name])
+		}
+		_, err = conn.Exec(insert, values...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+// evaluate returns the ids of the rows matched in memory, NULL is not matched, as in WHERE.
+func (h *Harness) evaluate(ast s.Visitable, rows []map[string]any) ([]int, error) {
+	var ids []int
+	for i, row := range rows {
+		visitor := s.NewEvaluateVisitor(rowContext(row), h.registry)
+		err := ast.Accept(visitor)
+		if err != nil {
+			return nil, fmt.Errorf("row %d %v: %w", i, row, err)
+		}
+		if visitor.CurrentValue() == true {
+			ids = append(ids, i)
+		}
+	}
+	return ids, nil
+}
+
+func (h *Harness) query(conn session.DbConnection, sql string, params []any) ([]int, error) {
+	rows, err := conn.Query(fmt.Sprintf("SELECT id FROM %s WHERE %s ORDER BY id", h.table, sql), params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+type rowContext map[string]any
+
+func (c rowContext) Get(key string) (any, error) {
+	value, ok := c[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", s.ErrKeyNotFound, key)
+	}
+	return value, nil
+}
+
+// Divergence is the specification whose compiled SQL selects other rows than the in-memory evaluation matches.
+type Divergence struct {
+	Seed          uint64
+	Iteration     int
+	Specification s.Visitable
+	SQL           string
+	Params        []any
+	// Expected are the ids of the rows matched in memory, Actual are the ids selected by SQL
+	Expected []int
+	Actual   []int
+	rows     []map[string]any
+}
+
+func (d *Divergence) Error() string {
+	specification, err := s.ToInfix(d.Specification)
+	if err != nil {
+		specification = fmt.Sprintf("%#v", d.Specification)
+	}
+	var text strings.Builder
+	fmt.Fprintf(&text, "specification diverges at iteration %d of seed %d:\n  %s\n  SQL: %s %v\n",
+		d.Iteration, d.Seed, specification, d.SQL, d.Params)
+	for _, id := range d.Mismatched() {
+		fmt.Fprintf(&text, "  row %d %v: in memory %t, SQL %t\n",
+			id, d.rows[id], slices.Contains(d.Expected, id), slices.Contains(d.Actual, id))
+	}
+	return strings.TrimSuffix(text.String(), "\n")
+}
+
+// Mismatched returns the ids of the rows matched either in memory or by SQL, but not by both.
+func (d *Divergence) Mismatched() []int {
+	var ids []int
+	for id := range d.rows {
+		if slices.Contains(d.Expected, id) != slices.Contains(d.Actual, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,29 @@
+package spectest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/utils/testutils"
+)
+
+func TestInMemoryAndSQLEquivalence(t *testing.T) {
+	pool, err := testutils.NewPgSessionPool()
+	if err != nil {
+		t.Fatalf("Failed to create session pool: %v", err)
+	}
+
+	err = pool.Session(context.Background(), func(s session.Session) error {
+		conn := s.(session.DbSession).Connection()
+		for seed := range uint64(5) {
+			if err := NewHarness().Check(conn, seed, 200); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
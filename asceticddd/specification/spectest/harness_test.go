@@ -0,0 +1,35 @@
+package spectest
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/utils/testutils"
+)
+
+func TestCheckReportsDivergence(t *testing.T) {
+	// The stub selects no rows, so the first specification matching a row in memory diverges
+	stub := testutils.NewDbSessionStub(testutils.NewRowsStub())
+
+	err := NewHarness(WithRows(10)).Check(stub.Connection(), 3, 100)
+
+	var divergence *Divergence
+	if !errors.As(err, &divergence) {
+		t.Fatalf("Expected divergence, got %v", err)
+	}
+	if len(divergence.Expected) == 0 || len(divergence.Actual) != 0 {
+		t.Errorf("Unexpected rows %v and %v", divergence.Expected, divergence.Actual)
+	}
+	if !slices.Equal(divergence.Mismatched(), divergence.Expected) {
+		t.Errorf("Expected mismatched %v, got %v", divergence.Expected, divergence.Mismatched())
+	}
+	if divergence.Seed != 3 || !strings.Contains(err.Error(), divergence.SQL) {
+		t.Errorf("Unexpected report %s", err)
+	}
+	create := `CREATE TEMPORARY TABLE spectest_fixture (id integer PRIMARY KEY, a bigint, b bigint, name text COLLATE "C", active boolean)`
+	if !slices.Contains(stub.ActualQueries, create) {
+		t.Errorf("Expected %s in %v", create, stub.ActualQueries)
+	}
+}
@@ -28,6 +28,7 @@ type DbSessionStub struct {
 	Rows           *RowsStub
 	ActualQuery    string
 	ActualParams   []any
+	ActualQueries  []string
 	conn           *connectionStub
 	identityMap    *identitymap.IdentityMap
 	onStarted      signals.Signal[session.SessionScopeStartedEvent]
@@ -74,18 +75,21 @@ type connectionStub struct {
 
 func (c *connectionStub) Exec(query string, args ...any) (session.Result, error) {
 	c.session.ActualQuery = query
+	c.session.ActualQueries = append(c.session.ActualQueries, query)
 	c.session.ActualParams = args
 	return result.NewResult(0, 0), nil
 }
 
 func (c *connectionStub) Query(query string, args ...any) (session.Rows, error) {
 	c.session.ActualQuery = query
+	c.session.ActualQueries = append(c.session.ActualQueries, query)
 	c.session.ActualParams = args
 	return c.session.Rows, nil
 }
 
 func (c *connectionStub) QueryRow(query string, args ...any) session.Row {
 	c.session.ActualQuery = query
+	c.session.ActualQueries = append(c.session.ActualQueries, query)
 	c.session.ActualParams = args
 	return &RowStub{rows: c.session.Rows}
 }
@@ -0,0 +1,122 @@
+package testutils
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/disposable"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/signals"
+)
+
+// Emission is an event captured by RecordingSignal.
+type Emission[E any] struct {
+	Event     E
+	EmittedAt time.Time
+}
+
+// RecordingSignal is a signals.Signal test double which records all notified events.
+// Attached observers are still notified, so it can replace a real signal
+// in session stubs and the like.
+type RecordingSignal[E any] struct {
+	mu        sync.Mutex
+	delegate  *signals.SignalImp[E]
+	emissions []Emission[E]
+	err       error
+}
+
+func NewRecordingSignal[E any]() *RecordingSignal[E] {
+	return &RecordingSignal[E]{
+		delegate: signals.NewSignal[E](),
+	}
+}
+
+// FailWith makes Notify return err after recording the event.
+func (s *RecordingSignal[E]) FailWith(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *RecordingSignal[E]) Attach(observer signals.Observer[E], observerId ...any) disposable.Disposable {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.delegate.Attach(observer, observerId...)
+}
+
+func (s *RecordingSignal[E]) Detach(observer signals.Observer[E], observerId ...any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delegate.Detach(observer, observerId...)
+}
+
+func (s *RecordingSignal[E]) Notify(event E) error {
+	s.mu.Lock()
+	s.emissions = append(s.emissions, Emission[E]{Event: event, EmittedAt: time.Now()})
+	err := s.err
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return s.delegate.Notify(event)
+}
+
+func (s *RecordingSignal[E]) Emissions() []Emission[E] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Emission[E](nil), s.emissions...)
+}
+
+func (s *RecordingSignal[E]) Events() []E {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := make([]E, 0, len(s.emissions))
+	for _, emission := range s.emissions {
+		events = append(events, emission.Event)
+	}
+	return events
+}
+
+func (s *RecordingSignal[E]) Count(matcher func(E) bool) int {
+	count := 0
+	for _, event := range s.Events() {
+		if matcher == nil || matcher(event) {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *RecordingSignal[E]) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.emissions = nil
+}
+
+// AssertEmitted checks that at least one recorded event matches. Nil matcher matches any event.
+func (s *RecordingSignal[E]) AssertEmitted(t testing.TB, matcher func(E) bool) bool {
+	t.Helper()
+	if s.Count(matcher) == 0 {
+		t.Errorf("expected matching event to be emitted, recorded events: %+v", s.Events())
+		return false
+	}
+	return true
+}
+
+func (s *RecordingSignal[E]) AssertNotEmitted(t testing.TB, matcher func(E) bool) bool {
+	t.Helper()
+	if count := s.Count(matcher); count != 0 {
+		t.Errorf("expected no matching event to be emitted, got %d, recorded events: %+v", count, s.Events())
+		return false
+	}
+	return true
+}
+
+func (s *RecordingSignal[E]) AssertEmittedTimes(t testing.TB, times int, matcher func(E) bool) bool {
+	t.Helper()
+	if count := s.Count(matcher); count != times {
+		t.Errorf("expected matching event to be emitted %d times, got %d, recorded events: %+v", times, count, s.Events())
+		return false
+	}
+	return true
+}
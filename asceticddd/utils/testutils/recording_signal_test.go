@@ -0,0 +1,99 @@
+package testutils
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/signals"
+)
+
+type sampleEvent struct {
+	Value int
+}
+
+// recordingT records the failures of the assertions instead of failing the test.
+type recordingT struct {
+	testing.TB
+	errors  []string
+	helpers int
+}
+
+func (t *recordingT) Helper() {
+	t.helpers++
+}
+
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestRecordingSignalImplementsSignal(t *testing.T) {
+	var _ signals.Signal[sampleEvent] = NewRecordingSignal[sampleEvent]()
+}
+
+func TestRecordingSignalRecordsEmissions(t *testing.T) {
+	s := NewRecordingSignal[sampleEvent]()
+	assert.NoError(t, s.Notify(sampleEvent{1}))
+	assert.NoError(t, s.Notify(sampleEvent{2}))
+
+	assert.Equal(t, []sampleEvent{{1}, {2}}, s.Events())
+	emissions := s.Emissions()
+	assert.Len(t, emissions, 2)
+	assert.False(t, emissions[0].EmittedAt.IsZero())
+	assert.False(t, emissions[1].EmittedAt.Before(emissions[0].EmittedAt))
+}
+
+func TestRecordingSignalNotifiesObservers(t *testing.T) {
+	s := NewRecordingSignal[sampleEvent]()
+	var received []sampleEvent
+	d := s.Attach(func(e sampleEvent) error {
+		received = append(received, e)
+		return nil
+	})
+	assert.NoError(t, s.Notify(sampleEvent{1}))
+	d.Dispose()
+	assert.NoError(t, s.Notify(sampleEvent{2}))
+
+	assert.Equal(t, []sampleEvent{{1}}, received)
+	assert.Len(t, s.Events(), 2)
+}
+
+func TestRecordingSignalFailWith(t *testing.T) {
+	s := NewRecordingSignal[sampleEvent]()
+	expected := errors.New("fail")
+	s.FailWith(expected)
+
+	assert.ErrorIs(t, s.Notify(sampleEvent{1}), expected)
+	assert.Len(t, s.Events(), 1)
+}
+
+func TestRecordingSignalAssertions(t *testing.T) {
+	s := NewRecordingSignal[sampleEvent]()
+	_ = s.Notify(sampleEvent{1})
+	_ = s.Notify(sampleEvent{2})
+	_ = s.Notify(sampleEvent{2})
+
+	isTwo := func(e sampleEvent) bool { return e.Value == 2 }
+	isThree := func(e sampleEvent) bool { return e.Value == 3 }
+
+	assert.True(t, s.AssertEmitted(t, isTwo))
+	assert.True(t, s.AssertEmitted(t, nil))
+	assert.True(t, s.AssertNotEmitted(t, isThree))
+	assert.True(t, s.AssertEmittedTimes(t, 2, isTwo))
+
+	mockT := &recordingT{}
+	assert.False(t, s.AssertEmitted(mockT, isThree))
+	assert.False(t, s.AssertNotEmitted(mockT, isTwo))
+	assert.False(t, s.AssertEmittedTimes(mockT, 1, isTwo))
+	assert.Equal(t, []string{
+		"expected matching event to be emitted, recorded events: [{Value:1} {Value:2} {Value:2}]",
+		"expected no matching event to be emitted, got 2, recorded events: [{Value:1} {Value:2} {Value:2}]",
+		"expected matching event to be emitted 1 times, got 2, recorded events: [{Value:1} {Value:2} {Value:2}]",
+	}, mockT.errors)
+	assert.Equal(t, 3, mockT.helpers)
+
+	s.Reset()
+	assert.Empty(t, s.Events())
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"log"
+)
+
+// ddscaffold generates the conventional layout of an aggregate:
+// domain entity with events and repository interface, in-memory (faker) and Pg repositories,
+// application service with session and outbox wiring, and test skeletons.
+//
+// Usage:
+//
+//	go run github.com/krew-solutions/ascetic-ddd-go/cmd/ddscaffold -name=Order -module=github.com/acme/shop/order -dir=./order
+
+var (
+	nameFlag   = flag.String("name", "", "Aggregate name in PascalCase, e.g. SalesOrder")
+	moduleFlag = flag.String("module", "", "Import path of the output directory")
+	dirFlag    = flag.String("dir", ".", "Output directory")
+	tableFlag  = flag.String("table", "", "Table of the Pg repository (default: snake_case name in plural)")
+	forceFlag  = flag.Bool("force", false, "Overwrite existing files")
+)
+
+func main() {
+	flag.Parse()
+
+	if *nameFlag == "" || *moduleFlag == "" {
+		log.Fatal("Usage: ddscaffold -name=AggregateName -module=import/path [-dir=.] [-table=name] [-force]")
+	}
+
+	config, err := NewConfig(*nameFlag, *moduleFlag, *tableFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	files, err := Generate(config)
+	if err != nil {
+		log.Fatalf("Failed to generate code: %v", err)
+	}
+	if err := Write(*dirFlag, files, *forceFlag); err != nil {
+		log.Fatal(err)
+	}
+	for _, file := range files {
+		log.Printf("Generated %s", file.Path)
+	}
+}
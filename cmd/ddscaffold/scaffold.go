@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// Config holds the names used by templates.
+type Config struct {
+	Name      string // SalesOrder
+	LowerName string // salesOrder
+	SnakeName string // sales_order
+	Module    string // github.com/acme/shop/salesorder
+	Table     string // sales_orders
+	Framework string
+}
+
+const framework = "github.com/krew-solutions/ascetic-ddd-go/asceticddd"
+
+func NewConfig(name, module, table string) (Config, error) {
+	if !isPascalCase(name) {
+		return Config{}, fmt.Errorf("name %q must be an exported Go identifier in PascalCase", name)
+	}
+	if module == "" {
+		return Config{}, fmt.Errorf("module is required")
+	}
+	snake := toSnakeCase(name)
+	if table == "" {
+		table = snake + "s"
+	}
+	return Config{
+		Name:      name,
+		LowerName: strings.ToLower(name[:1]) + name[1:],
+		SnakeName: snake,
+		Module:    strings.TrimSuffix(module, "/"),
+		Table:     table,
+		Framework: framework,
+	}, nil
+}
+
+// File is a generated file, Path is relative to the output directory.
+type File struct {
+	Path    string
+	Content []byte
+}
+
+var layout = []struct {
+	template string
+	path     string
+}{
+	{"aggregate.go.tmpl", "domain/{{snake}}.go"},
+	{"events.go.tmpl", "domain/{{snake}}_events.go"},
+	{"repository.go.tmpl", "domain/{{snake}}_repository.go"},
+	{"aggregate_test.go.tmpl", "domain/{{snake}}_test.go"},
+	{"faker_repository.go.tmpl", "infrastructure/{{snake}}_faker_repository.go"},
+	{"pg_repository.go.tmpl", "infrastructure/{{snake}}_pg_repository.go"},
+	{"pg_repository_test.go.tmpl", "infrastructure/{{snake}}_pg_repository_test.go"},
+	{"service.go.tmpl", "application/{{snake}}_service.go"},
+	{"service_test.go.tmpl", "application/{{snake}}_service_test.go"},
+}
+
+// Generate renders all files of the layout and formats them with gofmt.
+func Generate(config Config) ([]File, error) {
+	tmpl, err := template.ParseFS(templates, "templates/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	files := make([]File, 0, len(layout))
+	for _, item := range layout {
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, item.template, config); err != nil {
+			return nil, err
+		}
+		path := strings.ReplaceAll(item.path, "{{snake}}", config.SnakeName)
+		content, err := format.Source(buf.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		files = append(files, File{Path: path, Content: content})
+	}
+	return files, nil
+}
+
+// Write writes the files into dir.
+// Unless force is set, nothing is written if any of the files exists.
+func Write(dir string, files []File, force bool) error {
+	if !force {
+		for _, file := range files {
+			path := filepath.Join(dir, file.Path)
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("%s already exists, use -force to overwrite", path)
+			}
+		}
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, file.Path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, file.Content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isPascalCase(name string) bool {
+	if name == "" || !unicode.IsUpper(rune(name[0])) {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// toSnakeCase converts PascalCase to snake_case, keeping acronyms together:
+// SalesOrder -> sales_order, HTTPRequest -> http_request.
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestToSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"Order":       "order",
+		"SalesOrder":  "sales_order",
+		"HTTPRequest": "http_request",
+		"OrderV2":     "order_v2",
+	}
+	for name, expected := range tests {
+		if actual := toSnakeCase(name); actual != expected {
+			t.Errorf("toSnakeCase(%q) = %q, expected %q", name, actual, expected)
+		}
+	}
+}
+
+func TestNewConfig(t *testing.T) {
+	config, err := NewConfig("SalesOrder", "github.com/acme/shop/salesorder/", "")
+	if err != nil {
+		t.Fatalf("NewConfig failed: %v", err)
+	}
+	if config.LowerName != "salesOrder" || config.SnakeName != "sales_order" || config.Table != "sales_orders" {
+		t.Errorf("Unexpected names: %+v", config)
+	}
+	if config.Module != "github.com/acme/shop/salesorder" {
+		t.Errorf("Expected trailing slash to be trimmed, got %q", config.Module)
+	}
+
+	config, err = NewConfig("SalesOrder", "github.com/acme/shop/salesorder", "orders")
+	if err != nil || config.Table != "orders" {
+		t.Errorf("Expected custom table, got %q (%v)", config.Table, err)
+	}
+
+	for _, name := range []string{"", "salesOrder", "Sales-Order"} {
+		if _, err := NewConfig(name, "github.com/acme/shop", ""); err == nil {
+			t.Errorf("Expected error for name %q", name)
+		}
+	}
+	if _, err := NewConfig("Order", "", ""); err == nil {
+		t.Error("Expected error for empty module")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	config, err := NewConfig("SalesOrder", "github.com/acme/shop/salesorder", "")
+	if err != nil {
+		t.Fatalf("NewConfig failed: %v", err)
+	}
+	files, err := Generate(config)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	expectedPaths := []string{
+		"domain/sales_order.go",
+		"domain/sales_order_events.go",
+		"domain/sales_order_repository.go",
+		"domain/sales_order_test.go",
+		"infrastructure/sales_order_faker_repository.go",
+		"infrastructure/sales_order_pg_repository.go",
+		"infrastructure/sales_order_pg_repository_test.go",
+		"application/sales_order_service.go",
+		"application/sales_order_service_test.go",
+	}
+	if len(files) != len(expectedPaths) {
+		t.Fatalf("Expected %d files, got %d", len(expectedPaths), len(files))
+	}
+	for i, file := range files {
+		if file.Path != expectedPaths[i] {
+			t.Errorf("Expected %s, got %s", expectedPaths[i], file.Path)
+		}
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, file.Path, file.Content, parser.ImportsOnly)
+		if err != nil {
+			t.Errorf("%s does not parse: %v", file.Path, err)
+			continue
+		}
+		if expected := filepath.Dir(file.Path); f.Name.Name != expected {
+			t.Errorf("%s: expected package %s, got %s", file.Path, expected, f.Name.Name)
+		}
+	}
+
+	content := string(files[0].Content)
+	for _, expected := range []string{"type SalesOrder struct", "func NewSalesOrder(id SalesOrderId) *SalesOrder"} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected %q in domain/sales_order.go", expected)
+		}
+	}
+	if !strings.Contains(string(files[5].Content), `"github.com/acme/shop/salesorder/domain"`) {
+		t.Error("Expected Pg repository to import the domain package of the module")
+	}
+	if !strings.Contains(string(files[5].Content), `salesOrderTable = "sales_orders"`) {
+		t.Error("Expected Pg repository to use the table")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	files := []File{{Path: "domain/order.go", Content: []byte("package domain\n")}}
+
+	if err := Write(dir, files, false); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "domain/order.go"))
+	if err != nil || string(content) != "package domain\n" {
+		t.Fatalf("Unexpected content %q (%v)", content, err)
+	}
+
+	files[0].Content = []byte("package domain // changed\n")
+	if err := Write(dir, files, false); err == nil {
+		t.Error("Expected error for existing file")
+	}
+	if err := Write(dir, files, true); err != nil {
+		t.Fatalf("Write with force failed: %v", err)
+	}
+	content, _ = os.ReadFile(filepath.Join(dir, "domain/order.go"))
+	if string(content) != "package domain // changed\n" {
+		t.Errorf("Expected file to be overwritten, got %q", content)
+	}
+}
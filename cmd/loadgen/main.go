@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/config"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/infrastructure/repositories"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/outbox"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	pgsession "github.com/krew-solutions/ascetic-ddd-go/asceticddd/session/pg"
+)
+
+// loadgen measures the throughput and latency percentiles of the outbox and of queries
+// against a seeded document store, for capacity planning and as a regression baseline.
+//
+// Usage:
+//
+//	go run github.com/krew-solutions/ascetic-ddd-go/cmd/loadgen -dsn=postgres://... -messages=10000 -workers=4
+//	go run github.com/krew-solutions/ascetic-ddd-go/cmd/loadgen -scenarios=query -store=memory
+//
+// The database and outbox settings are read with asceticddd/config, flags take precedence.
+
+var (
+	configFlag     = flag.String("config", "", "YAML config file, see asceticddd/config")
+	dsnFlag        = flag.String("dsn", "", "Database DSN (default: database.dsn of the config)")
+	scenariosFlag  = flag.String("scenarios", "outbox,query", "Comma-separated scenarios: outbox, query")
+	messagesFlag   = flag.Int("messages", 10000, "Messages to publish")
+	publishersFlag = flag.Int("publishers", 8, "Concurrent publishers")
+	workersFlag    = flag.Int("workers", 4, "Dispatch workers")
+	storeFlag      = flag.String("store", "pg", "Document store of the query scenario: pg or memory")
+	tableFlag      = flag.String("table", "loadgen_documents", "Table of the seeded documents")
+	documentsFlag  = flag.Int("documents", 10000, "Documents to seed")
+	queriesFlag    = flag.Int("queries", 1000, "Random queries to run")
+	queryWorkers   = flag.Int("query-workers", 4, "Concurrent query workers")
+	seedFlag       = flag.Uint64("seed", 1, "Seed of random documents and queries")
+	timeoutFlag    = flag.Duration("timeout", 5*time.Minute, "Timeout of each scenario")
+)
+
+func main() {
+	flag.Parse()
+
+	cfg := config.Default()
+	if *configFlag != "" {
+		if err := cfg.LoadYAML(*configFlag); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := cfg.LoadEnv(config.EnvPrefix); err != nil {
+		log.Fatal(err)
+	}
+	if *dsnFlag != "" {
+		cfg.Database.DSN = *dsnFlag
+	}
+
+	scenarios := strings.Split(*scenariosFlag, ",")
+	needsDb := slices.Contains(scenarios, "outbox") || *storeFlag == "pg"
+	if needsDb {
+		if err := cfg.Validate(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var pool *pgsession.SessionPool
+	if needsDb {
+		poolConfig, err := cfg.Database.PoolConfig()
+		if err != nil {
+			log.Fatal(err)
+		}
+		pgxPool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer pgxPool.Close()
+		pool = pgsession.NewSessionPool(pgxPool)
+	}
+
+	failed := false
+	for _, scenario := range scenarios {
+		scenarioCtx, cancel := context.WithTimeout(ctx, *timeoutFlag)
+		reports, err := run(scenarioCtx, scenario, cfg, pool)
+		cancel()
+		for _, report := range reports {
+			fmt.Println(report)
+		}
+		if err != nil {
+			log.Printf("%s: %v", scenario, err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, scenario string, cfg config.Config, pool *pgsession.SessionPool) ([]Report, error) {
+	switch scenario {
+	case "outbox":
+		return RunOutboxLoad(ctx, pool, outbox.New(pool, cfg.Outbox.Options()...), OutboxLoad{
+			Messages:     *messagesFlag,
+			Publishers:   *publishersFlag,
+			Workers:      *workersFlag,
+			PollInterval: 10 * time.Millisecond,
+			Timeout:      *timeoutFlag,
+		})
+	case "query":
+		load := QueryLoad{
+			Table:     *tableFlag,
+			Documents: *documentsFlag,
+			Queries:   *queriesFlag,
+			Workers:   *queryWorkers,
+			Seed:      *seedFlag,
+		}
+		var store repositories.DocumentStore
+		var sessions Sessions
+		switch *storeFlag {
+		case "pg":
+			store, sessions = repositories.NewPgDocumentStore("", ""), pool.Session
+			if err := pool.Session(ctx, func(s session.Session) error {
+				return createDocumentsTable(s, load.Table)
+			}); err != nil {
+				return nil, err
+			}
+		case "memory":
+			store = repositories.NewInMemoryDocumentStore("", nil)
+			sessions = func(ctx context.Context, callback session.SessionPoolCallback) error {
+				return callback(nil)
+			}
+		default:
+			return nil, fmt.Errorf("unknown store: %s", *storeFlag)
+		}
+		if err := sessions(ctx, func(s session.Session) error {
+			return SeedDocuments(s, store, load)
+		}); err != nil {
+			return nil, err
+		}
+		return RunQueryLoad(ctx, sessions, store, load)
+	}
+	return nil, fmt.Errorf("unknown scenario: %s", scenario)
+}
+
+func createDocumentsTable(s session.Session, table string) error {
+	_, err := s.(session.DbSession).Connection().Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (value_id jsonb PRIMARY KEY, value jsonb NOT NULL)", table,
+	))
+	return err
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/outbox"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+type OutboxLoad struct {
+	Messages   int
+	Publishers int
+	Workers    int
+	// PollInterval is the pause of a worker after an empty dispatch.
+	PollInterval time.Duration
+	Timeout      time.Duration
+}
+
+// RunOutboxLoad publishes the messages, each in its own transaction, while the workers dispatch them.
+// Messages are published to a URI unique for the run and consumed by a new consumer group,
+// so that the messages of other runs are not dispatched.
+// It reports the latencies of publishing, of dispatched batches, and from publishing to delivery.
+func RunOutboxLoad(ctx context.Context, pool session.SessionPool, o outbox.Outbox, load OutboxLoad) ([]Report, error) {
+	if err := pool.Session(ctx, o.Setup); err != nil {
+		return nil, err
+	}
+
+	run := time.Now().UnixNano()
+	uri := fmt.Sprintf("loadgen://%d", run)
+	consumerGroup := fmt.Sprintf("loadgen-%d", run)
+	ctx, cancel := context.WithTimeout(ctx, load.Timeout)
+	defer cancel()
+
+	publish := NewRecorder("publish")
+	dispatch := NewRecorder("dispatch")
+	delivery := NewRecorder("delivery")
+	var delivered atomic.Int64
+	subscriber := func(message *outbox.OutboxMessage) error {
+		if publishedAt, ok := message.Payload["published_at"].(float64); ok {
+			delivery.Record(time.Since(time.Unix(0, int64(publishedAt))))
+		}
+		delivered.Add(1)
+		return nil
+	}
+
+	start := time.Now()
+	var workers sync.WaitGroup
+	for workerID := 0; workerID < load.Workers; workerID++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for ctx.Err() == nil && delivered.Load() < int64(load.Messages) {
+				began := time.Now()
+				ok, err := o.Dispatch(subscriber, consumerGroup, uri, workerID, load.Workers)
+				switch {
+				case err != nil:
+					dispatch.RecordError()
+				case ok:
+					dispatch.Record(time.Since(began))
+					continue
+				}
+				select {
+				case <-ctx.Done():
+				case <-time.After(load.PollInterval):
+				}
+			}
+		}()
+	}
+
+	jobs := make(chan int)
+	var publishers sync.WaitGroup
+	for range load.Publishers {
+		publishers.Add(1)
+		go func() {
+			defer publishers.Done()
+			for i := range jobs {
+				began := time.Now()
+				err := pool.Session(ctx, func(s session.Session) error {
+					return s.Atomic(func(txSession session.Session) error {
+						return o.Publish(txSession, &outbox.OutboxMessage{
+							URI:     uri,
+							Payload: map[string]any{"n": i, "published_at": began.UnixNano()},
+						})
+					})
+				})
+				if err != nil {
+					publish.RecordError()
+					continue
+				}
+				publish.Record(time.Since(began))
+			}
+		}()
+	}
+	for i := 0; i < load.Messages && ctx.Err() == nil; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	publishers.Wait()
+	publishElapsed := time.Since(start)
+	workers.Wait()
+	elapsed := time.Since(start)
+
+	reports := []Report{publish.Report(publishElapsed), dispatch.Report(elapsed), delivery.Report(elapsed)}
+	if n := delivered.Load(); n < int64(load.Messages) {
+		return reports, fmt.Errorf("delivered %d of %d messages: %w", n, load.Messages, context.Cause(ctx))
+	}
+	return reports, nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	domainquery "github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/domain/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/infrastructure/repositories"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+var (
+	statuses = []string{"active", "blocked", "pending", "deleted"}
+	cities   = []string{"Berlin", "Lisbon", "Oslo", "Riga", "Tallinn"}
+	tags     = []string{"go", "sql", "ddd", "kafka", "k8s", "grpc"}
+)
+
+type QueryLoad struct {
+	Table     string
+	Documents int
+	Queries   int
+	Workers   int
+	Seed      uint64
+}
+
+// RandomDocument returns the i-th document of the seeded store.
+func RandomDocument(r *rand.Rand, i int) map[string]any {
+	documentTags := []any{}
+	for _, tag := range tags {
+		if r.IntN(3) == 0 {
+			documentTags = append(documentTags, tag)
+		}
+	}
+	return map[string]any{
+		"id":      i,
+		"status":  statuses[r.IntN(len(statuses))],
+		"age":     18 + r.IntN(60),
+		"score":   r.IntN(1000),
+		"tags":    documentTags,
+		"address": map[string]any{"city": cities[r.IntN(len(cities))]},
+	}
+}
+
+// RandomQuery returns a query of 1 to 3 conditions on the fields of RandomDocument,
+// sometimes a disjunction.
+func RandomQuery(r *rand.Rand) (domainquery.IQueryOperator, error) {
+	conditions := []func() map[string]any{
+		func() map[string]any {
+			return map[string]any{"status": statuses[r.IntN(len(statuses))]}
+		},
+		func() map[string]any {
+			low := 18 + r.IntN(50)
+			return map[string]any{"age": map[string]any{"$gte": low, "$lt": low + 1 + r.IntN(20)}}
+		},
+		func() map[string]any {
+			return map[string]any{"score": map[string]any{"$gt": r.IntN(1000)}}
+		},
+		func() map[string]any {
+			return map[string]any{"tags": map[string]any{"$any": map[string]any{"$eq": tags[r.IntN(len(tags))]}}}
+		},
+		func() map[string]any {
+			return map[string]any{"address": map[string]any{"city": cities[r.IntN(len(cities))]}}
+		},
+	}
+	query := map[string]any{}
+	for range 1 + r.IntN(3) {
+		for field, condition := range conditions[r.IntN(len(conditions))]() {
+			query[field] = condition
+		}
+	}
+	if r.IntN(4) == 0 {
+		query = map[string]any{"$or": []any{query, conditions[r.IntN(len(conditions))]()}}
+	}
+	return domainquery.ParseQuery(query)
+}
+
+// SeedDocuments replaces the documents of the table with load.Documents random documents.
+func SeedDocuments(s session.Session, store repositories.DocumentStore, load QueryLoad) error {
+	r := rand.New(rand.NewPCG(load.Seed, 0))
+	states := make([]map[string]any, load.Documents)
+	for i := range states {
+		states[i] = RandomDocument(r, i)
+	}
+	_, err := store.SaveMany(s, load.Table, states, repositories.OnConflictMerge)
+	return err
+}
+
+// Sessions runs the callback with a session, e.g. SessionPool.Session.
+type Sessions func(ctx context.Context, callback session.SessionPoolCallback) error
+
+// RunQueryLoad counts the documents matching random queries by the workers,
+// each query in its own session.
+func RunQueryLoad(ctx context.Context, sessions Sessions, store repositories.DocumentStore, load QueryLoad) ([]Report, error) {
+	r := rand.New(rand.NewPCG(load.Seed, 1))
+	queries := make([]domainquery.IQueryOperator, load.Queries)
+	for i := range queries {
+		query, err := RandomQuery(r)
+		if err != nil {
+			return nil, fmt.Errorf("query %d: %w", i, err)
+		}
+		queries[i] = query
+	}
+
+	count := NewRecorder("query")
+	jobs := make(chan domainquery.IQueryOperator)
+	start := time.Now()
+	var workers sync.WaitGroup
+	for range load.Workers {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for query := range jobs {
+				began := time.Now()
+				err := sessions(ctx, func(s session.Session) error {
+					_, err := store.CountBySpec(s, load.Table, domainquery.QuerySpec{Query: query})
+					return err
+				})
+				if err != nil {
+					count.RecordError()
+					continue
+				}
+				count.Record(time.Since(began))
+			}
+		}()
+	}
+	for _, query := range queries {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- query
+	}
+	close(jobs)
+	workers.Wait()
+	return []Report{count.Report(time.Since(start))}, ctx.Err()
+}
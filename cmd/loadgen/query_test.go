@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/infrastructure/query"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/faker/infrastructure/repositories"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+func TestRandomQueryCompiles(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 1))
+	for i := 0; i < 200; i++ {
+		q, err := RandomQuery(r)
+		if err != nil {
+			t.Fatalf("Query %d: %v", i, err)
+		}
+		if _, _, err := query.NewPgQueryCompiler("", nil, nil).Compile(q); err != nil {
+			t.Fatalf("Query %d %v does not compile: %v", i, q, err)
+		}
+	}
+}
+
+func TestRunQueryLoadInMemory(t *testing.T) {
+	store := repositories.NewInMemoryDocumentStore("", nil)
+	sessions := func(ctx context.Context, callback session.SessionPoolCallback) error {
+		return callback(nil)
+	}
+	load := QueryLoad{Table: "documents", Documents: 100, Queries: 50, Workers: 4, Seed: 7}
+
+	if err := SeedDocuments(nil, store, load); err != nil {
+		t.Fatalf("SeedDocuments failed: %v", err)
+	}
+	reports, err := RunQueryLoad(context.Background(), sessions, store, load)
+	if err != nil {
+		t.Fatalf("RunQueryLoad failed: %v", err)
+	}
+	if len(reports) != 1 || reports[0].Count != 50 || reports[0].Errors != 0 {
+		t.Errorf("Expected 50 successful queries, got %+v", reports)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Recorder collects latencies of one kind of operation, safe for concurrent use.
+type Recorder struct {
+	name      string
+	mu        sync.Mutex
+	latencies []time.Duration
+	errors    int
+}
+
+func NewRecorder(name string) *Recorder {
+	return &Recorder{name: name}
+}
+
+func (r *Recorder) Record(latency time.Duration) {
+	r.mu.Lock()
+	r.latencies = append(r.latencies, latency)
+	r.mu.Unlock()
+}
+
+func (r *Recorder) RecordError() {
+	r.mu.Lock()
+	r.errors++
+	r.mu.Unlock()
+}
+
+// Report summarizes the latencies, the throughput is per the elapsed wall time.
+func (r *Recorder) Report(elapsed time.Duration) Report {
+	r.mu.Lock()
+	latencies := slices.Clone(r.latencies)
+	errors := r.errors
+	r.mu.Unlock()
+
+	slices.Sort(latencies)
+	report := Report{Name: r.name, Count: len(latencies), Errors: errors, Elapsed: elapsed}
+	if elapsed > 0 {
+		report.Throughput = float64(len(latencies)) / elapsed.Seconds()
+	}
+	if len(latencies) > 0 {
+		report.P50 = percentile(latencies, 50)
+		report.P90 = percentile(latencies, 90)
+		report.P99 = percentile(latencies, 99)
+		report.Max = latencies[len(latencies)-1]
+	}
+	return report
+}
+
+// percentile returns the nearest-rank percentile of the sorted latencies.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+type Report struct {
+	Name       string
+	Count      int
+	Errors     int
+	Elapsed    time.Duration
+	Throughput float64
+	P50        time.Duration
+	P90        time.Duration
+	P99        time.Duration
+	Max        time.Duration
+}
+
+func (r Report) String() string {
+	return fmt.Sprintf("%-10s %8d ops %4d errors %10s %10.1f ops/s  p50 %-10s p90 %-10s p99 %-10s max %s",
+		r.Name, r.Count, r.Errors, r.Elapsed.Round(time.Millisecond), r.Throughput,
+		r.P50.Round(time.Microsecond), r.P90.Round(time.Microsecond), r.P99.Round(time.Microsecond),
+		r.Max.Round(time.Microsecond))
+}
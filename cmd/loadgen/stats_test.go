@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecorderReport(t *testing.T) {
+	recorder := NewRecorder("publish")
+	for i := 100; i >= 1; i-- {
+		recorder.Record(time.Duration(i) * time.Millisecond)
+	}
+	recorder.RecordError()
+
+	report := recorder.Report(2 * time.Second)
+
+	if report.Count != 100 || report.Errors != 1 {
+		t.Errorf("Expected 100 ops and 1 error, got %d and %d", report.Count, report.Errors)
+	}
+	if report.Throughput != 50 {
+		t.Errorf("Expected 50 ops/s, got %v", report.Throughput)
+	}
+	expected := []time.Duration{50 * time.Millisecond, 90 * time.Millisecond, 99 * time.Millisecond, 100 * time.Millisecond}
+	actual := []time.Duration{report.P50, report.P90, report.P99, report.Max}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("Expected percentiles %v, got %v", expected, actual)
+			break
+		}
+	}
+	if !strings.HasPrefix(report.String(), "publish ") {
+		t.Errorf("Expected report of publish, got %q", report.String())
+	}
+}
+
+func TestRecorderReportEmpty(t *testing.T) {
+	report := NewRecorder("query").Report(0)
+	if report.Count != 0 || report.Throughput != 0 || report.Max != 0 {
+		t.Errorf("Expected empty report, got %+v", report)
+	}
+}
@@ -1,14 +1,23 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
 	"go/ast"
+	"go/format"
 	"go/parser"
 	"go/token"
+	"go/types"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -20,77 +29,289 @@ import (
 //
 // This will scan all functions with //spec:sql comment and generate
 // corresponding AST builder functions in *_spec_gen.go files.
+//
+// To generate the specifications of all types of the packages, one file per source file:
+//
+//	specgen ./...
+//
+// Without -type and packages, //go:generate specgen generates the package of the file.
+// The generated files have the hash of their sources, and -check fails if they are out of date, e.g. in CI:
+//
+//	specgen -check ./...
 
 var (
-	typeFlag = flag.String("type", "", "Type name to generate specs for")
+	typeFlag     = flag.String("type", "", "Type name to generate specs for")
+	dialectsFlag = flag.String("dialects", "", "Comma-separated SQL dialects to generate specs for: postgres, mysql, sqlite")
+	checkFlag    = flag.Bool("check", false, "Fail if the generated code is out of date instead of writing it")
 )
 
+// Dialect is the SQL dialect of the generated <Name>SQL<Suffix>() function
+type Dialect struct {
+	// Suffix is the suffix of the generated function (e.g., "MySQL")
+	Suffix string
+	// Type is the infra.Dialect implementation (e.g., "MySQLDialect")
+	Type string
+}
+
+var dialects = map[string]Dialect{
+	"postgres": {Suffix: "Postgres", Type: "PostgresDialect"},
+	"mysql":    {Suffix: "MySQL", Type: "MySQLDialect"},
+	"sqlite":   {Suffix: "SQLite", Type: "SQLiteDialect"},
+}
+
+// parseDialects parses the comma-separated names of the -dialects flag, in their order
+func parseDialects(names string) ([]Dialect, error) {
+	var result []Dialect
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		dialect, ok := dialects[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown dialect %q, expected postgres, mysql or sqlite", name)
+		}
+		result = append(result, dialect)
+	}
+	return result, nil
+}
+
 func main() {
 	flag.Parse()
+	patterns := flag.Args()
 
-	if *typeFlag == "" {
-		log.Fatal("Usage: specgen -type=TypeName")
+	sqlDialects, err := parseDialects(*dialectsFlag)
+	if err != nil {
+		log.Fatal(err)
 	}
+	opts := Options{Type: *typeFlag, Dialects: sqlDialects, Check: *checkFlag}
 
-	// Get the directory from GOFILE env variable (set by go:generate)
-	gofile := os.Getenv("GOFILE")
-	if gofile == "" {
-		// Fallback: use current directory
-		gofile = "."
+	if len(patterns) == 0 && opts.Type == "" {
+		// `//go:generate specgen` generates the package of the file
+		if os.Getenv("GOFILE") == "" {
+			log.Fatal("Usage: specgen -type=TypeName [-dialects=postgres,mysql,sqlite] [-check] [packages]")
+		}
+		patterns = []string{"."}
 	}
 
-	dir := filepath.Dir(gofile)
-	if dir == "" {
-		dir = "."
+	if len(patterns) == 0 {
+		// Get the directory from GOFILE env variable (set by go:generate)
+		gofile := os.Getenv("GOFILE")
+		if gofile == "" {
+			// Fallback: use current directory
+			gofile = "."
+		}
+		exit(generateType(filepath.Dir(gofile), opts))
+		return
 	}
 
-	// Parse Go files in the directory
-	fset := token.NewFileSet()
-	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
-		// Skip generated files and test files
-		name := fi.Name()
-		return !strings.HasSuffix(name, "_test.go") &&
-			!strings.HasSuffix(name, "_gen.go") &&
-			strings.HasSuffix(name, ".go")
-	}, parser.ParseComments)
+	dirs, err := resolvePatterns(patterns)
+	if err != nil {
+		log.Fatalf("Failed to resolve packages: %v", err)
+	}
+	var errs []error
+	for _, dir := range dirs {
+		err = generateFiles(dir, opts)
+		if err != nil && !errors.Is(err, ErrOutOfDate) {
+			log.Fatal(err)
+		}
+		errs = append(errs, err)
+	}
+	exit(errors.Join(errs...))
+}
+
+// exit exits with non-zero code on the error, e.g. the generated code is out of date in the check mode
+func exit(err error) {
 	if err != nil {
-		log.Fatalf("Failed to parse directory: %v", err)
+		log.Fatal(err)
+	}
+}
+
+// ErrOutOfDate is returned in the check mode by the generated file which differs from the one of the sources
+var ErrOutOfDate = errors.New("generated code is out of date, run specgen")
+
+// Options are the options of the generation, see the flags
+type Options struct {
+	// Type is the parameter type of the specifications, any type if empty
+	Type     string
+	Dialects []Dialect
+	// Check compares the generated code with the existing files instead of writing them
+	Check bool
+}
+
+// generateType generates one <type>_specs_gen.go file with the specifications of the type in the directory
+func generateType(dir string, opts Options) error {
+	fset, files, err := parsePackage(dir)
+	if err != nil {
+		return err
 	}
 
 	// Find specification functions
 	var specs []SpecFunc
+	var sources []sourceFile
 	var pkgName string
-
-	for name, pkg := range pkgs {
-		pkgName = name
-		for _, file := range pkg.Files {
-			specs = append(specs, findSpecFunctions(fset, file, *typeFlag)...)
+	for _, file := range files {
+		pkgName = file.AST.Name.Name
+		found := findSpecFunctions(fset, file.AST, opts.Type)
+		if len(found) > 0 {
+			specs = append(specs, found...)
+			sources = append(sources, file)
 		}
 	}
 
 	if len(specs) == 0 {
-		log.Printf("No specification functions found for type %s", *typeFlag)
-		return
+		log.Printf("No specification functions found for type %s", opts.Type)
+		return nil
 	}
 
 	// Generate output file
-	outputPath := filepath.Join(dir, strings.ToLower(*typeFlag)+"_specs_gen.go")
-	err = generateCode(outputPath, pkgName, *typeFlag, specs)
+	outputPath := filepath.Join(dir, strings.ToLower(opts.Type)+"_specs_gen.go")
+	return generateCode(outputPath, pkgName, specs, sources, opts)
+}
+
+// generateFiles generates <file>_specs_gen.go for each source file of the directory with specifications,
+// of any type unless the type name is given, grouped by their types
+func generateFiles(dir string, opts Options) error {
+	fset, files, err := parsePackage(dir)
 	if err != nil {
-		log.Fatalf("Failed to generate code: %v", err)
+		return err
 	}
 
-	log.Printf("Generated %s with %d specifications", outputPath, len(specs))
+	var errs []error
+	for _, file := range files {
+		specs := findSpecFunctions(fset, file.AST, opts.Type)
+		if len(specs) == 0 {
+			continue
+		}
+		slices.SortStableFunc(specs, func(a, b SpecFunc) int {
+			return strings.Compare(a.Type, b.Type)
+		})
+
+		outputPath := strings.TrimSuffix(file.Path, ".go") + "_specs_gen.go"
+		err = generateCode(outputPath, file.AST.Name.Name, specs, []sourceFile{file}, opts)
+		if err != nil && !errors.Is(err, ErrOutOfDate) {
+			return err
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// sourceFile is the parsed source file and its content
+type sourceFile struct {
+	Path    string
+	AST     *ast.File
+	Content []byte
+}
+
+// parsePackage parses the source files of the directory, except generated files and test files,
+// and returns them in the order of their paths
+func parsePackage(dir string) (*token.FileSet, []sourceFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	fset := token.NewFileSet()
+	var files []sourceFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() ||
+			!strings.HasSuffix(name, ".go") ||
+			strings.HasSuffix(name, "_test.go") ||
+			strings.HasSuffix(name, "_gen.go") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		file, err := parser.ParseFile(fset, path, content, parser.ParseComments)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse file: %w", err)
+		}
+		files = append(files, sourceFile{Path: path, AST: file, Content: content})
+	}
+	return fset, files, nil
+}
+
+// sourceHash returns the SHA-256 of the names and the contents of the source files
+func sourceHash(sources []sourceFile) string {
+	h := sha256.New()
+	for _, source := range sources {
+		fmt.Fprintf(h, "%s\n%d\n", filepath.Base(source.Path), len(source.Content))
+		h.Write(source.Content)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// resolvePatterns resolves the package patterns to directories, e.g. "./..." to the directories of the tree
+// with Go files, except vendor, testdata and hidden ones, as the go command does
+func resolvePatterns(patterns []string) ([]string, error) {
+	var dirs []string
+	for _, pattern := range patterns {
+		root, recursive := strings.CutSuffix(pattern, "...")
+		if !recursive {
+			dirs = append(dirs, filepath.Clean(pattern))
+			continue
+		}
+		root = filepath.Clean(strings.TrimSuffix(root, "/"))
+		if root == "" {
+			root = "."
+		}
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() {
+				return nil
+			}
+			name := entry.Name()
+			if path != root && (name == "vendor" || name == "testdata" ||
+				strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			hasGoFiles, err := containsGoFiles(path)
+			if err != nil {
+				return err
+			}
+			if hasGoFiles {
+				dirs = append(dirs, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dirs, nil
+}
+
+func containsGoFiles(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 // SpecFunc represents a specification function
 type SpecFunc struct {
 	Name string
+	// Type is the name of the parameter type (e.g., "User")
+	Type string
 	Doc  string
 	Body ast.Expr
 }
 
-// findSpecFunctions finds all functions with //spec:sql comment
+// findSpecFunctions finds all functions and methods with //spec:sql comment of the parameter type,
+// or of any parameter type if the type name is empty, the receiver of the method is its parameter
 func findSpecFunctions(fset *token.FileSet, file *ast.File, typeName string) []SpecFunc {
 	var specs []SpecFunc
 
@@ -117,15 +338,8 @@ func findSpecFunctions(fset *token.FileSet, file *ast.File, typeName string) []S
 			return true
 		}
 
-		// Validate function signature: func(T) bool
-		if funcDecl.Type.Params == nil || len(funcDecl.Type.Params.List) != 1 {
-			log.Printf("Warning: %s must have exactly one parameter", funcDecl.Name.Name)
-			return true
-		}
-
-		param := funcDecl.Type.Params.List[0]
-		paramType, ok := param.Type.(*ast.Ident)
-		if !ok || paramType.Name != typeName {
+		name, paramType, ok := specSignature(funcDecl)
+		if !ok || (typeName != "" && paramType != typeName) {
 			return true
 		}
 
@@ -134,30 +348,21 @@ func findSpecFunctions(fset *token.FileSet, file *ast.File, typeName string) []S
 			return true
 		}
 
-		// Extract the return expression
 		if funcDecl.Body == nil || len(funcDecl.Body.List) == 0 {
 			log.Printf("Warning: %s has empty body", funcDecl.Name.Name)
 			return true
 		}
 
-		// Find return statement
-		var returnExpr ast.Expr
-		for _, stmt := range funcDecl.Body.List {
-			if retStmt, ok := stmt.(*ast.ReturnStmt); ok {
-				if len(retStmt.Results) == 1 {
-					returnExpr = retStmt.Results[0]
-					break
-				}
-			}
-		}
-
-		if returnExpr == nil {
-			log.Printf("Warning: %s has no return statement", funcDecl.Name.Name)
+		// Translate the body to the return expression
+		returnExpr, err := bodyExpr(fset, funcDecl.Body.List, funcDecl.Body.Rbrace)
+		if err != nil {
+			log.Printf("Warning: %v in %s", err, funcDecl.Name.Name)
 			return true
 		}
 
 		specs = append(specs, SpecFunc{
-			Name: funcDecl.Name.Name,
+			Name: name,
+			Type: paramType,
 			Doc:  funcDecl.Doc.Text(),
 			Body: returnExpr,
 		})
@@ -168,41 +373,267 @@ func findSpecFunctions(fset *token.FileSet, file *ast.File, typeName string) []S
 	return specs
 }
 
-// generateCode generates the *_spec_gen.go file
-func generateCode(outputPath, pkgName, typeName string, specs []SpecFunc) error {
-	f, err := os.Create(outputPath)
+// specSignature returns the name of the specification and its parameter type:
+// of the function func(T) bool, or UserIsAdultSpec of the method func (u User) IsAdult() bool,
+// whose receiver is the parameter
+func specSignature(funcDecl *ast.FuncDecl) (name, paramType string, ok bool) {
+	if funcDecl.Recv != nil {
+		if funcDecl.Type.Params != nil && len(funcDecl.Type.Params.List) != 0 {
+			log.Printf("Warning: %s must have no parameters", funcDecl.Name.Name)
+			return "", "", false
+		}
+		recvType := funcDecl.Recv.List[0].Type
+		if star, isPointer := recvType.(*ast.StarExpr); isPointer {
+			recvType = star.X
+		}
+		ident, ok := recvType.(*ast.Ident)
+		if !ok {
+			return "", "", false
+		}
+		return ident.Name + strings.TrimSuffix(funcDecl.Name.Name, "Spec") + "Spec", ident.Name, true
+	}
+
+	// Validate function signature: func(T) bool
+	if funcDecl.Type.Params == nil || len(funcDecl.Type.Params.List) != 1 {
+		log.Printf("Warning: %s must have exactly one parameter", funcDecl.Name.Name)
+		return "", "", false
+	}
+
+	param := funcDecl.Type.Params.List[0]
+	ident, ok := param.Type.(*ast.Ident)
+	if !ok {
+		return "", "", false
+	}
+	return funcDecl.Name.Name, ident.Name, true
+}
+
+// UnsupportedError is the construct of the specification body which has no equivalent specification
+type UnsupportedError struct {
+	Position  token.Position
+	Construct string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s: unsupported %s", e.Position, e.Construct)
+}
+
+// caseExpr is the expression of the if statement, When ? Then : Else, which has no Go syntax.
+// The embedded expression is When.
+type caseExpr struct {
+	ast.Expr
+	Then ast.Expr
+	Else ast.Expr
+}
+
+// bodyExpr translates the statements of the body to the equivalent return expression: the if/else chains
+// and the switch statements become Or/And of their conditions, or Case, the statements after the if
+// statement without else are its else branch. The end is the position of the missing return.
+func bodyExpr(fset *token.FileSet, stmts []ast.Stmt, end token.Pos) (ast.Expr, error) {
+	if len(stmts) == 0 {
+		return nil, &UnsupportedError{Position: fset.Position(end), Construct: "missing return"}
+	}
+
+	rest := stmts[1:]
+	switch stmt := stmts[0].(type) {
+	case *ast.ReturnStmt:
+		if len(stmt.Results) != 1 {
+			return nil, &UnsupportedError{
+				Position:  fset.Position(stmt.Pos()),
+				Construct: fmt.Sprintf("return of %d values", len(stmt.Results)),
+			}
+		}
+		return stmt.Results[0], nil
+
+	case *ast.IfStmt:
+		if stmt.Init != nil {
+			return nil, &UnsupportedError{Position: fset.Position(stmt.Pos()), Construct: "if statement with initialization"}
+		}
+		thenExpr, err := bodyExpr(fset, slices.Concat(stmt.Body.List, rest), end)
+		if err != nil {
+			return nil, err
+		}
+		var elseStmts []ast.Stmt
+		switch elseStmt := stmt.Else.(type) {
+		case *ast.BlockStmt:
+			elseStmts = elseStmt.List
+		case *ast.IfStmt:
+			elseStmts = []ast.Stmt{elseStmt}
+		}
+		elseExpr, err := bodyExpr(fset, slices.Concat(elseStmts, rest), end)
+		if err != nil {
+			return nil, err
+		}
+		return conditionalExpr(stmt.Cond, thenExpr, elseExpr), nil
+
+	case *ast.SwitchStmt:
+		return switchExpr(fset, stmt, rest, end)
+
+	case *ast.BlockStmt:
+		return bodyExpr(fset, slices.Concat(stmt.List, rest), end)
+	}
+	return nil, &UnsupportedError{Position: fset.Position(stmts[0].Pos()), Construct: constructName(stmts[0])}
+}
+
+// switchExpr translates the switch statement to the chain of the conditionals of its cases in their order,
+// the default case, or the statements after the switch, is the last else branch
+func switchExpr(fset *token.FileSet, stmt *ast.SwitchStmt, rest []ast.Stmt, end token.Pos) (ast.Expr, error) {
+	if stmt.Init != nil {
+		return nil, &UnsupportedError{Position: fset.Position(stmt.Pos()), Construct: "switch statement with initialization"}
+	}
+
+	var clauses []*ast.CaseClause
+	otherwise := rest
+	for _, s := range stmt.Body.List {
+		clause := s.(*ast.CaseClause)
+		for _, bodyStmt := range clause.Body {
+			if branch, ok := bodyStmt.(*ast.BranchStmt); ok {
+				return nil, &UnsupportedError{Position: fset.Position(branch.Pos()), Construct: constructName(branch)}
+			}
+		}
+		if clause.List == nil {
+			otherwise = slices.Concat(clause.Body, rest)
+		} else {
+			clauses = append(clauses, clause)
+		}
+	}
+
+	result, err := bodyExpr(fset, otherwise, end)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer f.Close()
+	for _, clause := range slices.Backward(clauses) {
+		var cond ast.Expr
+		for _, value := range clause.List {
+			if stmt.Tag != nil {
+				value = &ast.BinaryExpr{X: stmt.Tag, OpPos: value.Pos(), Op: token.EQL, Y: value}
+			}
+			if cond == nil {
+				cond = value
+			} else {
+				cond = &ast.BinaryExpr{X: cond, OpPos: value.Pos(), Op: token.LOR, Y: value}
+			}
+		}
+		thenExpr, err := bodyExpr(fset, slices.Concat(clause.Body, rest), end)
+		if err != nil {
+			return nil, err
+		}
+		result = conditionalExpr(cond, thenExpr, result)
+	}
+	return result, nil
+}
+
+// conditionalExpr returns the expression of cond ? then : otherwise, Or or And if a branch is the boolean literal
+func conditionalExpr(cond, then, otherwise ast.Expr) ast.Expr {
+	not := &ast.UnaryExpr{OpPos: cond.Pos(), Op: token.NOT, X: cond}
+	switch {
+	case isBoolLiteral(then, true) && isBoolLiteral(otherwise, false):
+		return cond
+	case isBoolLiteral(then, false) && isBoolLiteral(otherwise, true):
+		return not
+	case isBoolLiteral(then, true):
+		return &ast.BinaryExpr{X: cond, Op: token.LOR, Y: otherwise}
+	case isBoolLiteral(then, false):
+		return &ast.BinaryExpr{X: not, Op: token.LAND, Y: otherwise}
+	case isBoolLiteral(otherwise, true):
+		return &ast.BinaryExpr{X: not, Op: token.LOR, Y: then}
+	case isBoolLiteral(otherwise, false):
+		return &ast.BinaryExpr{X: cond, Op: token.LAND, Y: then}
+	}
+	return &caseExpr{Expr: cond, Then: then, Else: otherwise}
+}
+
+func isBoolLiteral(expr ast.Expr, value bool) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == strconv.FormatBool(value)
+}
+
+// constructName returns the readable name of the unsupported statement
+func constructName(stmt ast.Stmt) string {
+	switch s := stmt.(type) {
+	case *ast.AssignStmt:
+		return "assignment"
+	case *ast.DeclStmt:
+		return "declaration"
+	case *ast.ForStmt, *ast.RangeStmt:
+		return "loop"
+	case *ast.TypeSwitchStmt:
+		return "type switch"
+	case *ast.BranchStmt:
+		return s.Tok.String() + " statement"
+	case *ast.ExprStmt:
+		return "expression statement"
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", stmt), "*ast.")
+}
+
+// generateCode generates the *_spec_gen.go file, or checks it in the check mode,
+// the SQL functions of the dialects compile the same AST by infra.CompileToSQL, see infra.WithDialect
+func generateCode(outputPath, pkgName string, specs []SpecFunc, sources []sourceFile, opts Options) error {
+	code, err := renderCode(pkgName, specs, sources, opts.Dialects)
+	if err != nil {
+		return fmt.Errorf("failed to format code of %s: %w", outputPath, err)
+	}
+
+	if opts.Check {
+		existing, err := os.ReadFile(outputPath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if !bytes.Equal(existing, code) {
+			return fmt.Errorf("%s: %w", outputPath, ErrOutOfDate)
+		}
+		return nil
+	}
+
+	err = os.WriteFile(outputPath, code, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to generate code: %w", err)
+	}
+	log.Printf("Generated %s with %d specifications", outputPath, len(specs))
+	return nil
+}
+
+// renderCode renders the gofmt-ed generated file of the specifications in their order,
+// the header has the hash of the sources
+func renderCode(pkgName string, specs []SpecFunc, sources []sourceFile, sqlDialects []Dialect) ([]byte, error) {
+	var f bytes.Buffer
 
 	// Write header
-	fmt.Fprintf(f, "// Code generated by specgen. DO NOT EDIT.\n\n")
-	fmt.Fprintf(f, "package %s\n\n", pkgName)
-	fmt.Fprintf(f, "import (\n")
-	fmt.Fprintf(f, "\tspec \"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain\"\n")
-	fmt.Fprintf(f, "\tinfra \"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/infrastructure\"\n")
-	fmt.Fprintf(f, ")\n\n")
+	fmt.Fprintf(&f, "// Code generated by specgen. DO NOT EDIT.\n")
+	fmt.Fprintf(&f, "// Source hash: sha256:%s\n\n", sourceHash(sources))
+	fmt.Fprintf(&f, "package %s\n\n", pkgName)
+	fmt.Fprintf(&f, "import (\n")
+	fmt.Fprintf(&f, "\tspec \"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain\"\n")
+	fmt.Fprintf(&f, "\tinfra \"github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/infrastructure\"\n")
+	fmt.Fprintf(&f, ")\n\n")
 
 	// Generate AST builder for each spec
 	for _, s := range specs {
-		visitor := NewSpecGenVisitor(typeName)
+		visitor := NewSpecGenVisitor(s.Type)
 
 		// Generate AST function
-		fmt.Fprintf(f, "// %sAST returns AST for %s\n", s.Name, s.Name)
-		fmt.Fprintf(f, "func %sAST() spec.Visitable {\n", s.Name)
-		fmt.Fprintf(f, "\treturn %s\n", visitor.Visit(s.Body))
-		fmt.Fprintf(f, "}\n\n")
+		fmt.Fprintf(&f, "// %sAST returns AST for %s\n", s.Name, s.Name)
+		fmt.Fprintf(&f, "func %sAST() spec.Visitable {\n", s.Name)
+		fmt.Fprintf(&f, "\treturn %s\n", visitor.Visit(s.Body))
+		fmt.Fprintf(&f, "}\n\n")
 
 		// Generate SQL helper
-		fmt.Fprintf(f, "// %sSQL returns SQL for %s\n", s.Name, s.Name)
-		fmt.Fprintf(f, "func %sSQL() (string, []any, error) {\n", s.Name)
-		fmt.Fprintf(f, "\tast := %sAST()\n", s.Name)
-		fmt.Fprintf(f, "\treturn infra.CompileToSQL(ast)\n")
-		fmt.Fprintf(f, "}\n\n")
+		fmt.Fprintf(&f, "// %sSQL returns SQL for %s\n", s.Name, s.Name)
+		fmt.Fprintf(&f, "func %sSQL() (string, []any, error) {\n", s.Name)
+		fmt.Fprintf(&f, "\tast := %sAST()\n", s.Name)
+		fmt.Fprintf(&f, "\treturn infra.CompileToSQL(ast)\n")
+		fmt.Fprintf(&f, "}\n\n")
+
+		// Generate SQL helper for each dialect
+		for _, d := range sqlDialects {
+			fmt.Fprintf(&f, "// %sSQL%s returns %s SQL for %s\n", s.Name, d.Suffix, d.Suffix, s.Name)
+			fmt.Fprintf(&f, "func %sSQL%s() (string, []any, error) {\n", s.Name, d.Suffix)
+			fmt.Fprintf(&f, "\treturn infra.CompileToSQL(%sAST(), infra.WithDialect(infra.%s{}))\n", s.Name, d.Type)
+			fmt.Fprintf(&f, "}\n\n")
+		}
 	}
 
-	return nil
+	return format.Source(f.Bytes())
 }
 
 // SpecGenVisitor converts Go AST expressions to Specification AST builder code.
@@ -237,6 +668,8 @@ func (v *SpecGenVisitor) withWildcardContext(itemName string) *SpecGenVisitor {
 // Visit dispatches to the appropriate visit method based on node type.
 func (v *SpecGenVisitor) Visit(expr ast.Expr) string {
 	switch e := expr.(type) {
+	case *caseExpr:
+		return fmt.Sprintf("spec.Case(%s, %s, %s)", v.Visit(e.Expr), v.Visit(e.Then), v.Visit(e.Else))
 	case *ast.BinaryExpr:
 		return v.VisitBinaryExpr(e)
 	case *ast.UnaryExpr:
@@ -296,11 +729,11 @@ func (v *SpecGenVisitor) VisitBinaryExpr(expr *ast.BinaryExpr) string {
 
 	// Bitwise
 	case token.AND: // & (bitwise AND)
-		return fmt.Sprintf("spec.Value(nil) /* TODO: bitwise AND not yet implemented in spec: %s & %s */", left, right)
+		return fmt.Sprintf("spec.BitAnd(%s, %s)", left, right)
 	case token.OR: // | (bitwise OR)
-		return fmt.Sprintf("spec.Value(nil) /* TODO: bitwise OR not yet implemented in spec: %s | %s */", left, right)
+		return fmt.Sprintf("spec.BitOr(%s, %s)", left, right)
 	case token.XOR: // ^ (bitwise XOR)
-		return fmt.Sprintf("spec.Value(nil) /* TODO: bitwise XOR not yet implemented in spec: %s ^ %s */", left, right)
+		return fmt.Sprintf("spec.BitXor(%s, %s)", left, right)
 	case token.SHL: // <<
 		return fmt.Sprintf("spec.LeftShift(%s, %s)", left, right)
 	case token.SHR: // >>
@@ -313,6 +746,9 @@ func (v *SpecGenVisitor) VisitBinaryExpr(expr *ast.BinaryExpr) string {
 
 // VisitUnaryExpr handles unary expressions (!, -, +).
 func (v *SpecGenVisitor) VisitUnaryExpr(expr *ast.UnaryExpr) string {
+	if call, ok := expr.X.(*ast.CallExpr); ok && expr.Op == token.NOT && isMembershipCall(call) {
+		return v.visitMembership(call, "spec.NotIn")
+	}
 	operand := v.Visit(expr.X)
 
 	switch expr.Op {
@@ -372,18 +808,25 @@ func (v *SpecGenVisitor) VisitSelectorExpr(expr *ast.SelectorExpr) string {
 	return fmt.Sprintf("spec.Field(%s, %q)", scope, path[len(path)-1])
 }
 
-// VisitCallExpr handles function calls (Any, All, IsNull, method calls).
+// VisitCallExpr handles function calls (Any, All, Count, Sum, Min, Max, In, slices.Contains, IsNull, method calls).
 func (v *SpecGenVisitor) VisitCallExpr(expr *ast.CallExpr) string {
+	if isMembershipCall(expr) {
+		return v.visitMembership(expr, "spec.In")
+	}
 	switch fun := expr.Fun.(type) {
 	case *ast.Ident:
 		switch fun.Name {
 		case "Any", "All":
 			return v.visitAnyAll(expr, fun.Name)
+		case "Count", "Sum", "Min", "Max":
+			return v.visitAggregate(expr, fun.Name)
 		}
 	case *ast.SelectorExpr:
 		switch fun.Sel.Name {
 		case "Any", "All":
 			return v.visitAnyAll(expr, fun.Sel.Name)
+		case "Count", "Sum", "Min", "Max":
+			return v.visitAggregate(expr, fun.Sel.Name)
 		case "IsNull":
 			return v.visitIsNull(expr)
 		case "IsNotNull":
@@ -431,15 +874,41 @@ func (v *SpecGenVisitor) VisitParenExpr(expr *ast.ParenExpr) string {
 // visitAnyAll handles Any/All collection predicates.
 func (v *SpecGenVisitor) visitAnyAll(expr *ast.CallExpr, funcName string) string {
 	// Any/All(collection, func(item Type) bool { return predicate })
+	collection, predicate, ok := v.visitCollectionLambda(expr, funcName)
+	if !ok {
+		return predicate
+	}
+
+	// Generate Wildcard node, All is true unless any item does not match
+	if funcName == "All" {
+		return fmt.Sprintf("spec.Every(%s, %s)", collection, predicate)
+	}
+	return fmt.Sprintf("spec.Wildcard(%s, %s)", collection, predicate)
+}
+
+// visitAggregate handles Count/Sum/Min/Max of collections.
+func (v *SpecGenVisitor) visitAggregate(expr *ast.CallExpr, funcName string) string {
+	// Count(collection, func(item Type) bool { return predicate })
+	// Sum(collection, func(item Type) int { return value })
+	collection, body, ok := v.visitCollectionLambda(expr, funcName)
+	if !ok {
+		return body
+	}
+	return fmt.Sprintf("spec.%sOf(%s, %s)", funcName, collection, body)
+}
+
+// visitCollectionLambda converts the collection selector and the body of the lambda of the item,
+// otherwise ok is false and body is the placeholder with the reason.
+func (v *SpecGenVisitor) visitCollectionLambda(expr *ast.CallExpr, funcName string) (collection, body string, ok bool) {
 	if len(expr.Args) != 2 {
-		return fmt.Sprintf("spec.Value(nil) /* %s requires 2 arguments */", funcName)
+		return "", fmt.Sprintf("spec.Value(nil) /* %s requires 2 arguments */", funcName), false
 	}
 
 	// First arg is the collection selector (e.g., store.Items or region.Categories)
 	collectionExpr := expr.Args[0]
 	collectionSelector, ok := collectionExpr.(*ast.SelectorExpr)
 	if !ok {
-		return fmt.Sprintf("spec.Value(nil) /* %s first arg must be selector */", funcName)
+		return "", fmt.Sprintf("spec.Value(nil) /* %s first arg must be selector */", funcName), false
 	}
 
 	collectionField := collectionSelector.Sel.Name
@@ -462,37 +931,107 @@ func (v *SpecGenVisitor) visitAnyAll(expr *ast.CallExpr, funcName string) string
 		// Convert Field to Object
 		parentScope = fmt.Sprintf("spec.Object(%s.Object(), %s.Name())", parentScope, parentScope)
 	default:
-		return fmt.Sprintf("spec.Value(nil) /* unsupported collection parent %T */", collectionSelector.X)
+		return "", fmt.Sprintf("spec.Value(nil) /* unsupported collection parent %T */", collectionSelector.X), false
 	}
 
 	// Second arg is the lambda function
 	lambdaExpr := expr.Args[1]
 	funcLit, ok := lambdaExpr.(*ast.FuncLit)
 	if !ok {
-		return fmt.Sprintf("spec.Value(nil) /* %s second arg must be func literal */", funcName)
+		return "", fmt.Sprintf("spec.Value(nil) /* %s second arg must be func literal */", funcName), false
 	}
 
 	// Extract lambda parameter name
 	if len(funcLit.Type.Params.List) != 1 || len(funcLit.Type.Params.List[0].Names) != 1 {
-		return fmt.Sprintf("spec.Value(nil) /* %s lambda must have exactly one param */", funcName)
+		return "", fmt.Sprintf("spec.Value(nil) /* %s lambda must have exactly one param */", funcName), false
 	}
 	lambdaItemName := funcLit.Type.Params.List[0].Names[0].Name
 
 	// Extract lambda body (should be a return statement)
 	if len(funcLit.Body.List) != 1 {
-		return fmt.Sprintf("spec.Value(nil) /* %s lambda must have exactly one statement */", funcName)
+		return "", fmt.Sprintf("spec.Value(nil) /* %s lambda must have exactly one statement */", funcName), false
 	}
 	retStmt, ok := funcLit.Body.List[0].(*ast.ReturnStmt)
 	if !ok || len(retStmt.Results) != 1 {
-		return fmt.Sprintf("spec.Value(nil) /* %s lambda must have return statement */", funcName)
+		return "", fmt.Sprintf("spec.Value(nil) /* %s lambda must have return statement */", funcName), false
 	}
 
-	// Convert predicate in wildcard context using a new visitor
+	// Convert lambda body in wildcard context using a new visitor
 	wildcardVisitor := v.withWildcardContext(lambdaItemName)
-	predicate := wildcardVisitor.Visit(retStmt.Results[0])
+	body = wildcardVisitor.Visit(retStmt.Results[0])
+
+	return fmt.Sprintf("spec.Object(%s, %q)", parentScope, collectionField), body, true
+}
 
-	// Generate Wildcard node
-	return fmt.Sprintf("spec.Wildcard(spec.Object(%s, %q), %s)", parentScope, collectionField, predicate)
+// isMembershipCall reports whether the call is In(value, values...) helper of any package
+// or slices.Contains(values, value).
+func isMembershipCall(expr *ast.CallExpr) bool {
+	switch fun := expr.Fun.(type) {
+	case *ast.Ident:
+		return fun.Name == "In"
+	case *ast.SelectorExpr:
+		pkg, ok := fun.X.(*ast.Ident)
+		if !ok {
+			return false
+		}
+		return fun.Sel.Name == "In" || (pkg.Name == "slices" && fun.Sel.Name == "Contains")
+	}
+	return false
+}
+
+// visitMembership handles In(value, "a", "b") and slices.Contains([]string{"a", "b"}, value),
+// the values must be literals since they become the param of the generated specification.
+func (v *SpecGenVisitor) visitMembership(expr *ast.CallExpr, specFunc string) string {
+	if fun, ok := expr.Fun.(*ast.SelectorExpr); ok && fun.Sel.Name == "Contains" {
+		if len(expr.Args) != 2 {
+			return "spec.Value(nil) /* Contains requires 2 arguments */"
+		}
+		values, ok := literalSource(expr.Args[0])
+		if !ok {
+			return "spec.Value(nil) /* Contains first arg must be slice literal */"
+		}
+		return fmt.Sprintf("%s(%s, spec.Value(%s))", specFunc, v.Visit(expr.Args[1]), values)
+	}
+
+	if len(expr.Args) < 1 {
+		return "spec.Value(nil) /* In requires at least 1 argument */"
+	}
+	values := make([]string, len(expr.Args)-1)
+	for i, arg := range expr.Args[1:] {
+		value, ok := literalSource(arg)
+		if !ok {
+			return "spec.Value(nil) /* In values must be literals */"
+		}
+		values[i] = value
+	}
+	return fmt.Sprintf("%s(%s, spec.Value([]any{%s}))", specFunc, v.Visit(expr.Args[0]), strings.Join(values, ", "))
+}
+
+// literalSource returns the source of the literal, e.g. "-1" or `[]string{"a", "b"}`.
+func literalSource(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return e.Value, true
+	case *ast.Ident:
+		return e.Name, e.Name == "true" || e.Name == "false" || e.Name == "nil"
+	case *ast.UnaryExpr:
+		operand, ok := literalSource(e.X)
+		return e.Op.String() + operand, ok && (e.Op == token.SUB || e.Op == token.ADD)
+	case *ast.CompositeLit:
+		if _, ok := e.Type.(*ast.ArrayType); !ok {
+			return "", false
+		}
+		elts := make([]string, len(e.Elts))
+		for i, elt := range e.Elts {
+			value, ok := literalSource(elt)
+			if !ok {
+				return "", false
+			}
+			elts[i] = value
+		}
+		return types.ExprString(e.Type) + "{" + strings.Join(elts, ", ") + "}", true
+	}
+	return "", false
 }
 
 // visitIsNull handles value.IsNull() calls.
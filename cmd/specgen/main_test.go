@@ -1,9 +1,12 @@
 package main
 
 import (
+	"errors"
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -159,6 +162,21 @@ func TestVisitBinaryExpr_Bitwise(t *testing.T) {
 			expr:     "i.ID >> 1",
 			expected: `spec.RightShift(spec.Field(spec.GlobalScope(), "ID"), spec.Value(1))`,
 		},
+		{
+			name:     "BitAnd",
+			expr:     "i.Flags & 4",
+			expected: `spec.BitAnd(spec.Field(spec.GlobalScope(), "Flags"), spec.Value(4))`,
+		},
+		{
+			name:     "BitOr",
+			expr:     "i.Flags | 4",
+			expected: `spec.BitOr(spec.Field(spec.GlobalScope(), "Flags"), spec.Value(4))`,
+		},
+		{
+			name:     "BitXor",
+			expr:     "i.Flags ^ 4",
+			expected: `spec.BitXor(spec.Field(spec.GlobalScope(), "Flags"), spec.Value(4))`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -464,6 +482,31 @@ func test(s Store) bool {
 	}
 }
 
+func TestVisitAnyAll_RootAll(t *testing.T) {
+	source := `package main
+func test(s Store) bool {
+	return spec.All(s.Items, func(item Item) bool { return item.Active })
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", source, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	fn := file.Decls[0].(*ast.FuncDecl)
+	retStmt := fn.Body.List[0].(*ast.ReturnStmt)
+	callExpr := retStmt.Results[0].(*ast.CallExpr)
+
+	visitor := NewSpecGenVisitor("Store")
+	result := visitor.visitAnyAll(callExpr, "All")
+
+	expected := `spec.Every(spec.Object(spec.GlobalScope(), "Items"), spec.Field(spec.Item(), "Active"))`
+	if result != expected {
+		t.Errorf("Expected %s\nGot: %s", expected, result)
+	}
+}
+
 func TestVisitAnyAll_NestedWildcard(t *testing.T) {
 	// Test: spec.Any(region.Categories, func(category Category) bool { return category.Active })
 	// Inside a wildcard context (region is the item)
@@ -741,3 +784,504 @@ func TestVisitMethodComparison_CombinedWithLogical(t *testing.T) {
 		t.Errorf("\nExpected: %s\nGot:      %s", expected, result)
 	}
 }
+
+func TestVisitMembership(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		expected string
+	}{
+		{
+			name:     "In helper",
+			expr:     `spec.In(u.Status, "active", "pending")`,
+			expected: `spec.In(spec.Field(spec.GlobalScope(), "Status"), spec.Value([]any{"active", "pending"}))`,
+		},
+		{
+			name:     "Unqualified In helper",
+			expr:     `In(u.Age, 18, -1)`,
+			expected: `spec.In(spec.Field(spec.GlobalScope(), "Age"), spec.Value([]any{18, -1}))`,
+		},
+		{
+			name:     "slices.Contains",
+			expr:     `slices.Contains([]string{"active", "pending"}, u.Status)`,
+			expected: `spec.In(spec.Field(spec.GlobalScope(), "Status"), spec.Value([]string{"active", "pending"}))`,
+		},
+		{
+			name:     "Negated slices.Contains",
+			expr:     `!slices.Contains([]int{1, 2}, item.Stock)`,
+			expected: `spec.NotIn(spec.Field(spec.Item(), "Stock"), spec.Value([]int{1, 2}))`,
+		},
+		{
+			name:     "Negated In helper",
+			expr:     `!spec.In(u.Status, "banned")`,
+			expected: `spec.NotIn(spec.Field(spec.GlobalScope(), "Status"), spec.Value([]any{"banned"}))`,
+		},
+		{
+			name:     "Non-literal values",
+			expr:     `slices.Contains(statuses, u.Status)`,
+			expected: `spec.Value(nil) /* Contains first arg must be slice literal */`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			visitor := NewSpecGenVisitor("User").withWildcardContext("item")
+			result := visitor.Visit(parseExpr(t, tt.expr))
+			if result != tt.expected {
+				t.Errorf("\nExpected: %s\nGot:      %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestVisitAggregate(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		expected string
+	}{
+		{
+			name:     "Count",
+			expr:     `spec.Count(s.Items, func(item Item) bool { return item.Active }) >= 3`,
+			expected: `spec.GreaterThanEqual(spec.CountOf(spec.Object(spec.GlobalScope(), "Items"), spec.Field(spec.Item(), "Active")), spec.Value(3))`,
+		},
+		{
+			name:     "Sum",
+			expr:     `spec.Sum(s.Items, func(item Item) int { return item.Price * item.Stock }) > 1000`,
+			expected: `spec.GreaterThan(spec.SumOf(spec.Object(spec.GlobalScope(), "Items"), spec.Mul(spec.Field(spec.Item(), "Price"), spec.Field(spec.Item(), "Stock"))), spec.Value(1000))`,
+		},
+		{
+			name:     "Min",
+			expr:     `Min(s.Items, func(item Item) int { return item.Price })`,
+			expected: `spec.MinOf(spec.Object(spec.GlobalScope(), "Items"), spec.Field(spec.Item(), "Price"))`,
+		},
+		{
+			name:     "Max in wildcard",
+			expr:     `spec.Any(s.Categories, func(c Category) bool { return spec.Max(c.Items, func(item Item) int { return item.Price }) > 100 })`,
+			expected: `spec.Wildcard(spec.Object(spec.GlobalScope(), "Categories"), spec.GreaterThan(spec.MaxOf(spec.Object(spec.Item(), "Items"), spec.Field(spec.Item(), "Price")), spec.Value(100)))`,
+		},
+		{
+			name:     "Missing lambda",
+			expr:     `spec.Sum(s.Items)`,
+			expected: `spec.Value(nil) /* Sum requires 2 arguments */`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			visitor := NewSpecGenVisitor("Store")
+			result := visitor.Visit(parseExpr(t, tt.expr))
+			if result != tt.expected {
+				t.Errorf("\nExpected: %s\nGot:      %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseDialects(t *testing.T) {
+	result, err := parseDialects("postgres, MySQL,sqlite")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result) != 3 || result[0].Suffix != "Postgres" || result[1].Suffix != "MySQL" || result[2].Suffix != "SQLite" {
+		t.Errorf("Unexpected dialects: %v", result)
+	}
+
+	result, err = parseDialects("")
+	if err != nil || len(result) != 0 {
+		t.Errorf("Expected no dialects, got %v, %v", result, err)
+	}
+
+	if _, err := parseDialects("postgres,oracle"); err == nil {
+		t.Error("Expected error for unknown dialect")
+	}
+}
+
+func TestGenerateCode_Dialects(t *testing.T) {
+	specs := []SpecFunc{{Name: "AdultUserSpec", Type: "User", Body: parseExpr(t, "u.Age >= 18")}}
+	outputPath := filepath.Join(t.TempDir(), "user_specs_gen.go")
+
+	err := generateCode(outputPath, "main", specs, nil, Options{Dialects: []Dialect{dialects["mysql"], dialects["sqlite"]}})
+	if err != nil {
+		t.Fatalf("Failed to generate code: %v", err)
+	}
+	code, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read generated code: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), outputPath, code, 0); err != nil {
+		t.Fatalf("Generated code does not parse: %v", err)
+	}
+
+	for _, expected := range []string{
+		"func AdultUserSpecSQL() (string, []any, error) {",
+		"func AdultUserSpecSQLMySQL() (string, []any, error) {\n\treturn infra.CompileToSQL(AdultUserSpecAST(), infra.WithDialect(infra.MySQLDialect{}))",
+		"func AdultUserSpecSQLSQLite() (string, []any, error) {\n\treturn infra.CompileToSQL(AdultUserSpecAST(), infra.WithDialect(infra.SQLiteDialect{}))",
+	} {
+		if !strings.Contains(string(code), expected) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", expected, code)
+		}
+	}
+	if strings.Contains(string(code), "AdultUserSpecSQLPostgres") {
+		t.Error("Expected no Postgres function")
+	}
+}
+
+func writeSource(t *testing.T, path, source string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+}
+
+func TestResolvePatterns(t *testing.T) {
+	root := t.TempDir()
+	writeSource(t, filepath.Join(root, "domain", "user.go"), "package domain\n")
+	writeSource(t, filepath.Join(root, "domain", "order", "order.go"), "package order\n")
+	writeSource(t, filepath.Join(root, "domain", "testdata", "fixture.go"), "package fixture\n")
+	writeSource(t, filepath.Join(root, "domain", ".cache", "cache.go"), "package cache\n")
+	writeSource(t, filepath.Join(root, "docs", "README.md"), "# Docs\n")
+
+	dirs, err := resolvePatterns([]string{root + "/...", filepath.Join(root, "docs")})
+	if err != nil {
+		t.Fatalf("Failed to resolve patterns: %v", err)
+	}
+	expected := []string{
+		filepath.Join(root, "domain"),
+		filepath.Join(root, "domain", "order"),
+		filepath.Join(root, "docs"),
+	}
+	if strings.Join(dirs, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected directories %v, got %v", expected, dirs)
+	}
+}
+
+func TestGenerateFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeSource(t, filepath.Join(dir, "customers.go"), `package domain
+
+//spec:sql
+func VipOrderSpec(o Order) bool {
+	return o.Total > 1000
+}
+
+//spec:sql
+func AdultUserSpec(u User) bool {
+	return u.Age >= 18
+}
+`)
+	writeSource(t, filepath.Join(dir, "stores.go"), `package domain
+
+//spec:sql
+func OpenStoreSpec(s Store) bool {
+	return s.Open
+}
+`)
+	writeSource(t, filepath.Join(dir, "types.go"), "package domain\n\ntype User struct{ Age int }\n")
+
+	if err := generateFiles(dir, Options{}); err != nil {
+		t.Fatalf("Failed to generate files: %v", err)
+	}
+
+	code, err := os.ReadFile(filepath.Join(dir, "customers_specs_gen.go"))
+	if err != nil {
+		t.Fatalf("Failed to read generated code: %v", err)
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), "customers_specs_gen.go", code, 0)
+	if err != nil {
+		t.Fatalf("Generated code does not parse: %v", err)
+	}
+	if file.Name.Name != "domain" {
+		t.Errorf("Expected package domain, got %s", file.Name.Name)
+	}
+	// The specifications are grouped by their types
+	order, user := strings.Index(string(code), "func VipOrderSpecAST()"), strings.Index(string(code), "func AdultUserSpecAST()")
+	if order < 0 || user < 0 || order > user {
+		t.Errorf("Expected Order specifications before User ones, got:\n%s", code)
+	}
+
+	code, err = os.ReadFile(filepath.Join(dir, "stores_specs_gen.go"))
+	if err != nil {
+		t.Fatalf("Failed to read generated code: %v", err)
+	}
+	if !strings.Contains(string(code), "func OpenStoreSpecAST() spec.Visitable") {
+		t.Errorf("Expected OpenStoreSpecAST, got:\n%s", code)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "types_specs_gen.go")); !os.IsNotExist(err) {
+		t.Errorf("Expected no file for the source without specifications, got %v", err)
+	}
+}
+
+func TestGenerateFiles_Check(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "user.go")
+	writeSource(t, source, `package domain
+
+//spec:sql
+func AdultUserSpec(u User) bool {
+	return u.Age >= 18
+}
+`)
+
+	if err := generateFiles(dir, Options{Check: true}); !errors.Is(err, ErrOutOfDate) {
+		t.Errorf("Expected ErrOutOfDate for missing generated file, got %v", err)
+	}
+
+	if err := generateFiles(dir, Options{}); err != nil {
+		t.Fatalf("Failed to generate files: %v", err)
+	}
+	code, err := os.ReadFile(filepath.Join(dir, "user_specs_gen.go"))
+	if err != nil {
+		t.Fatalf("Failed to read generated code: %v", err)
+	}
+	if !strings.HasPrefix(string(code), "// Code generated by specgen. DO NOT EDIT.\n// Source hash: sha256:") {
+		t.Errorf("Expected header with source hash, got:\n%s", code)
+	}
+	if err := generateFiles(dir, Options{Check: true}); err != nil {
+		t.Errorf("Expected up to date generated code, got %v", err)
+	}
+
+	writeSource(t, source, `package domain
+
+//spec:sql
+func AdultUserSpec(u User) bool {
+	return u.Age >= 21
+}
+`)
+	if err := generateFiles(dir, Options{Check: true}); !errors.Is(err, ErrOutOfDate) {
+		t.Errorf("Expected ErrOutOfDate for changed source, got %v", err)
+	}
+	if err := generateFiles(dir, Options{Check: true, Dialects: []Dialect{dialects["mysql"]}}); !errors.Is(err, ErrOutOfDate) {
+		t.Errorf("Expected ErrOutOfDate for changed flags, got %v", err)
+	}
+}
+
+func TestRenderCode_Deterministic(t *testing.T) {
+	specs := []SpecFunc{
+		{Name: "AdultUserSpec", Type: "User", Body: parseExpr(t, "u.Age >= 18")},
+		{Name: "ActiveUserSpec", Type: "User", Body: parseExpr(t, "u.Active")},
+	}
+	sources := []sourceFile{{Path: "user.go", Content: []byte("package main\n")}}
+
+	first, err := renderCode("main", specs, sources, nil)
+	if err != nil {
+		t.Fatalf("Failed to render code: %v", err)
+	}
+	second, _ := renderCode("main", specs, sources, nil)
+	if string(first) != string(second) {
+		t.Errorf("Expected the same code, got:\n%s\nand:\n%s", first, second)
+	}
+
+	changed, _ := renderCode("main", specs, []sourceFile{{Path: "user.go", Content: []byte("package main\n\n")}}, nil)
+	if string(first) == string(changed) {
+		t.Error("Expected the source hash to change with the source")
+	}
+}
+
+func TestFindSpecFunctions_Methods(t *testing.T) {
+	source := `package main
+
+//spec:sql
+func (u User) IsAdult() bool {
+	return u.Age >= 18
+}
+
+//spec:sql
+func (u *User) ActiveSpec() bool {
+	return u.Active
+}
+
+//spec:sql
+func (u User) OlderThan(age int) bool {
+	return u.Age > age
+}
+
+//spec:sql
+func (s Store) IsOpen() bool {
+	return s.Open
+}
+
+// Regular method without marker
+func (u User) Name() string {
+	return u.FirstName
+}
+`
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", source, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse source: %v", err)
+	}
+
+	specs := findSpecFunctions(fset, file, "User")
+	if len(specs) != 2 {
+		t.Fatalf("Expected 2 spec methods, got %d", len(specs))
+	}
+	if specs[0].Name != "UserIsAdultSpec" || specs[0].Type != "User" {
+		t.Errorf("Expected UserIsAdultSpec of User, got %s of %s", specs[0].Name, specs[0].Type)
+	}
+	if specs[1].Name != "UserActiveSpec" || specs[1].Type != "User" {
+		t.Errorf("Expected UserActiveSpec of User, got %s of %s", specs[1].Name, specs[1].Type)
+	}
+
+	visitor := NewSpecGenVisitor(specs[0].Type)
+	expected := `spec.GreaterThanEqual(spec.Field(spec.GlobalScope(), "Age"), spec.Value(18))`
+	if result := visitor.Visit(specs[0].Body); result != expected {
+		t.Errorf("Expected %s, got %s", expected, result)
+	}
+
+	specs = findSpecFunctions(fset, file, "")
+	if len(specs) != 3 || specs[2].Name != "StoreIsOpenSpec" {
+		t.Errorf("Expected UserIsAdultSpec, UserActiveSpec and StoreIsOpenSpec, got %v", specs)
+	}
+}
+
+func parseSpecBody(t *testing.T, body string) (*token.FileSet, *ast.FuncDecl) {
+	t.Helper()
+	source := "package main\n\nfunc Spec(o Order) bool {\n" + body + "\n}\n"
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", source, 0)
+	if err != nil {
+		t.Fatalf("Failed to parse source: %v", err)
+	}
+	return fset, file.Decls[0].(*ast.FuncDecl)
+}
+
+func TestBodyExpr(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name: "if without else",
+			body: `if o.Total > 100 {
+		return true
+	}
+	return o.VIP`,
+			expected: `spec.Or(spec.GreaterThan(spec.Field(spec.GlobalScope(), "Total"), spec.Value(100)), spec.Field(spec.GlobalScope(), "VIP"))`,
+		},
+		{
+			name: "if else chain",
+			body: `if o.Cancelled {
+		return false
+	} else if o.VIP {
+		return o.Total > 50
+	} else {
+		return o.Total > 100
+	}`,
+			expected: `spec.And(spec.Not(spec.Field(spec.GlobalScope(), "Cancelled")), ` +
+				`spec.Case(spec.Field(spec.GlobalScope(), "VIP"), ` +
+				`spec.GreaterThan(spec.Field(spec.GlobalScope(), "Total"), spec.Value(50)), ` +
+				`spec.GreaterThan(spec.Field(spec.GlobalScope(), "Total"), spec.Value(100))))`,
+		},
+		{
+			name: "nested if falls through",
+			body: `if o.VIP {
+		if o.Total > 50 {
+			return true
+		}
+	}
+	return false`,
+			expected: `spec.And(spec.Field(spec.GlobalScope(), "VIP"), ` +
+				`spec.GreaterThan(spec.Field(spec.GlobalScope(), "Total"), spec.Value(50)))`,
+		},
+		{
+			name: "switch over field",
+			body: `switch o.Status {
+	case "new", "paid":
+		return true
+	case "shipped":
+		return o.Tracked
+	default:
+		return false
+	}`,
+			expected: `spec.Or(spec.Or(spec.Equal(spec.Field(spec.GlobalScope(), "Status"), spec.Value("new")), ` +
+				`spec.Equal(spec.Field(spec.GlobalScope(), "Status"), spec.Value("paid"))), ` +
+				`spec.And(spec.Equal(spec.Field(spec.GlobalScope(), "Status"), spec.Value("shipped")), ` +
+				`spec.Field(spec.GlobalScope(), "Tracked")))`,
+		},
+		{
+			name: "switch without tag",
+			body: `switch {
+	case o.Total > 100:
+		return o.Paid
+	}
+	return o.VIP`,
+			expected: `spec.Case(spec.GreaterThan(spec.Field(spec.GlobalScope(), "Total"), spec.Value(100)), ` +
+				`spec.Field(spec.GlobalScope(), "Paid"), spec.Field(spec.GlobalScope(), "VIP"))`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fset, funcDecl := parseSpecBody(t, tt.body)
+			expr, err := bodyExpr(fset, funcDecl.Body.List, funcDecl.Body.Rbrace)
+			if err != nil {
+				t.Fatalf("Failed to translate body: %v", err)
+			}
+			result := NewSpecGenVisitor("Order").Visit(expr)
+			if result != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestBodyExpr_Unsupported(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name: "assignment",
+			body: `limit := 100
+	return o.Total > limit`,
+			expected: "test.go:4:1: unsupported assignment",
+		},
+		{
+			name: "missing return",
+			body: `if o.VIP {
+		return true
+	}`,
+			expected: "test.go:7:1: unsupported missing return",
+		},
+		{
+			name: "fallthrough",
+			body: `switch o.Status {
+	case "new":
+		fallthrough
+	default:
+		return o.VIP
+	}`,
+			expected: "test.go:6:3: unsupported fallthrough statement",
+		},
+		{
+			name: "loop",
+			body: `for _, item := range o.Items {
+		return item.Active
+	}
+	return false`,
+			expected: "test.go:4:1: unsupported loop",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fset, funcDecl := parseSpecBody(t, tt.body)
+			_, err := bodyExpr(fset, funcDecl.Body.List, funcDecl.Body.Rbrace)
+			var unsupported *UnsupportedError
+			if !errors.As(err, &unsupported) {
+				t.Fatalf("Expected UnsupportedError, got %v", err)
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
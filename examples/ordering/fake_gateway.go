@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// FakeGateway is a PaymentGateway for the demo and the tests.
+type FakeGateway struct {
+	mu       sync.Mutex
+	method   string
+	declines bool
+	charges  map[string]int64
+}
+
+func NewFakeGateway(method string) *FakeGateway {
+	return &FakeGateway{method: method, charges: map[string]int64{}}
+}
+
+// SetDeclines makes the gateway decline all charges, e.g. to simulate an outage.
+func (g *FakeGateway) SetDeclines(declines bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.declines = declines
+}
+
+func (g *FakeGateway) Method() string {
+	return g.method
+}
+
+func (g *FakeGateway) Charge(orderId string, amount int64) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.declines {
+		return "", fmt.Errorf("%s: %w", g.method, ErrPaymentDeclined)
+	}
+	chargeId := g.method + ":" + orderId
+	g.charges[chargeId] = amount
+	return chargeId, nil
+}
+
+func (g *FakeGateway) Refund(chargeId string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.charges, chargeId)
+	return nil
+}
+
+// Charged returns the sum of charges not refunded.
+func (g *FakeGateway) Charged() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var total int64
+	for _, amount := range g.charges {
+		total += amount
+	}
+	return total
+}
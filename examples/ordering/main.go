@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+func main() {
+	db := NewMemoryDb()
+	sess := NewMemorySession(context.Background(), db)
+	publisher := NewMemoryOutbox(db)
+	repository := NewMemoryOrderRepository()
+	warehouse := NewWarehouse(map[string]int{"laptop": 2, "mouse": 10})
+	card := NewFakeGateway("card")
+	wallet := NewFakeGateway("wallet")
+	service := NewOrderingService(repository, publisher, NewPaymentSaga(warehouse, card, wallet))
+	projector := NewCustomerOrdersProjector()
+
+	fmt.Println("=== Place orders ===")
+	laptop, err := service.PlaceOrder(sess, "alice", []OrderLine{{Sku: "laptop", Quantity: 1, Price: 150000}})
+	if err != nil {
+		log.Fatal(err)
+	}
+	mice, err := service.PlaceOrder(sess, "bob", []OrderLine{{Sku: "mouse", Quantity: 2, Price: 2500}})
+	if err != nil {
+		log.Fatal(err)
+	}
+	tooMany, err := service.PlaceOrder(sess, "bob", []OrderLine{{Sku: "laptop", Quantity: 5, Price: 150000}})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sql, params, _ := PendingLargeOrderSpecSQL()
+	fmt.Printf("Pending large orders (SQL: WHERE %s %v):\n", sql, params)
+	orders, _ := repository.Find(sess, PendingLargeOrders)
+	for _, order := range orders {
+		fmt.Printf("  %s of %s: $%d.%02d\n", order.Id(), order.CustomerId(), order.Total()/100, order.Total()%100)
+	}
+
+	fmt.Println("\n=== Pay orders ===")
+	card.SetDeclines(true)
+	for _, id := range []OrderId{laptop, mice, tooMany} {
+		err := service.PayOrder(sess, id)
+		order, _ := repository.Get(sess, id)
+		switch {
+		case errors.Is(err, ErrPaymentFailed):
+			fmt.Printf("  %s: %s (stock or payment unavailable)\n", id, order.Status())
+		case err != nil:
+			log.Fatal(err)
+		default:
+			fmt.Printf("  %s: %s by %s (card is declined, fallback to wallet)\n", id, order.Status(), order.PaymentMethod())
+		}
+	}
+	fmt.Printf("  laptops left: %d, charged by wallet: %d\n", warehouse.Stock("laptop"), wallet.Charged())
+
+	fmt.Println("\n=== Project read model from outbox ===")
+	if err := publisher.DispatchAll(projector.Handle, "customer_orders"); err != nil {
+		log.Fatal(err)
+	}
+	for _, customer := range projector.TopCustomers(10) {
+		fmt.Printf("  %s: paid %d, cancelled %d, pending %d, spent $%d\n",
+			customer.CustomerId, customer.Paid, customer.Cancelled, customer.Pending, customer.Spent/100)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"maps"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/outbox"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/signals"
+)
+
+// orderRecord is the stored state of Order.
+type orderRecord struct {
+	id            OrderId
+	customerId    string
+	lines         []OrderLine
+	status        OrderStatus
+	paymentMethod string
+	version       uint
+}
+
+// MemoryDb stands in for the database of the bounded context: orders and the outbox table.
+type MemoryDb struct {
+	orders   map[string]orderRecord
+	messages []*outbox.OutboxMessage
+}
+
+func NewMemoryDb() *MemoryDb {
+	return &MemoryDb{
+		orders: map[string]orderRecord{},
+	}
+}
+
+func (db *MemoryDb) snapshot() *MemoryDb {
+	return &MemoryDb{
+		orders:   maps.Clone(db.orders),
+		messages: append([]*outbox.OutboxMessage(nil), db.messages...),
+	}
+}
+
+func (db *MemoryDb) restore(snapshot *MemoryDb) {
+	db.orders = snapshot.orders
+	db.messages = snapshot.messages
+}
+
+// MemorySession is a session over MemoryDb.
+// Atomic rolls back all changes of the callback if it fails, like a transaction or a savepoint.
+type MemorySession struct {
+	ctx       context.Context
+	db        *MemoryDb
+	onStarted *signals.SignalImp[session.SessionScopeStartedEvent]
+	onEnded   *signals.SignalImp[session.SessionScopeEndedEvent]
+}
+
+func NewMemorySession(ctx context.Context, db *MemoryDb) *MemorySession {
+	return &MemorySession{
+		ctx:       ctx,
+		db:        db,
+		onStarted: signals.NewSignal[session.SessionScopeStartedEvent](),
+		onEnded:   signals.NewSignal[session.SessionScopeEndedEvent](),
+	}
+}
+
+func (s *MemorySession) Context() context.Context {
+	return s.ctx
+}
+
+func (s *MemorySession) Atomic(callback session.SessionCallback) error {
+	snapshot := s.db.snapshot()
+	if err := callback(s); err != nil {
+		s.db.restore(snapshot)
+		return err
+	}
+	return nil
+}
+
+func (s *MemorySession) OnAtomicStarted() signals.Signal[session.SessionScopeStartedEvent] {
+	return s.onStarted
+}
+
+func (s *MemorySession) OnAtomicEnded() signals.Signal[session.SessionScopeEndedEvent] {
+	return s.onEnded
+}
+
+func memoryDb(s session.Session) *MemoryDb {
+	return s.(*MemorySession).db
+}
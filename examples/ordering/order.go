@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/seedwork/domain/aggregate"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/seedwork/domain/identity"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/seedwork/domain/uuid"
+)
+
+var (
+	ErrEmptyOrder      = errors.New("order has no lines")
+	ErrInvalidQuantity = errors.New("quantity must be positive")
+	ErrOrderNotPending = errors.New("order is not pending")
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusPending   OrderStatus = "pending"
+	OrderStatusPaid      OrderStatus = "paid"
+	OrderStatusCancelled OrderStatus = "cancelled"
+)
+
+type OrderId = identity.UuidIdentity
+
+func NewOrderId() OrderId {
+	id, _ := identity.NewUuidIdentity(uuid.NewUuid())
+	return id
+}
+
+// OrderLine is a value object, Price is in cents.
+type OrderLine struct {
+	Sku      string
+	Quantity int
+	Price    int64
+}
+
+func (l OrderLine) Total() int64 {
+	return int64(l.Quantity) * l.Price
+}
+
+// PlaceOrder creates a pending Order and records OrderPlaced.
+func PlaceOrder(id OrderId, customerId string, lines []OrderLine) (*Order, error) {
+	if len(lines) == 0 {
+		return nil, ErrEmptyOrder
+	}
+	for _, line := range lines {
+		if line.Quantity <= 0 {
+			return nil, fmt.Errorf("%s: %w", line.Sku, ErrInvalidQuantity)
+		}
+	}
+	o := &Order{
+		id:                 id,
+		customerId:         customerId,
+		lines:              append([]OrderLine(nil), lines...),
+		status:             OrderStatusPending,
+		VersionedAggregate: aggregate.NewVersionedAggregate(0),
+	}
+	o.AddDomainEvent(OrderPlaced{OrderId: id, CustomerId: customerId, Lines: o.Lines(), Total: o.Total()})
+	return o, nil
+}
+
+type Order struct {
+	id            OrderId
+	customerId    string
+	lines         []OrderLine
+	status        OrderStatus
+	paymentMethod string
+	aggregate.EventiveEntity[aggregate.DomainEvent]
+	aggregate.VersionedAggregate
+}
+
+func (o Order) Id() OrderId {
+	return o.id
+}
+
+func (o Order) CustomerId() string {
+	return o.customerId
+}
+
+func (o Order) Lines() []OrderLine {
+	return append([]OrderLine(nil), o.lines...)
+}
+
+func (o Order) Status() OrderStatus {
+	return o.status
+}
+
+func (o Order) PaymentMethod() string {
+	return o.paymentMethod
+}
+
+func (o Order) Total() int64 {
+	var total int64
+	for _, line := range o.lines {
+		total += line.Total()
+	}
+	return total
+}
+
+// MarkPaid completes the order paid with the method chosen by the payment saga.
+func (o *Order) MarkPaid(method string) error {
+	if o.status != OrderStatusPending {
+		return ErrOrderNotPending
+	}
+	o.status = OrderStatusPaid
+	o.paymentMethod = method
+	o.AddDomainEvent(OrderPaid{OrderId: o.id, CustomerId: o.customerId, Method: method, Total: o.Total()})
+	return nil
+}
+
+func (o *Order) Cancel(reason string) error {
+	if o.status != OrderStatusPending {
+		return ErrOrderNotPending
+	}
+	o.status = OrderStatusCancelled
+	o.AddDomainEvent(OrderCancelled{OrderId: o.id, CustomerId: o.customerId, Reason: reason})
+	return nil
+}
+
+// View exports the state the specifications are written against.
+func (o Order) View() OrderView {
+	return OrderView{
+		CustomerId: o.customerId,
+		Status:     string(o.status),
+		Total:      o.Total(),
+	}
+}
+
+type OrderPlaced struct {
+	OrderId    OrderId
+	CustomerId string
+	Lines      []OrderLine
+	Total      int64
+}
+
+type OrderPaid struct {
+	OrderId    OrderId
+	CustomerId string
+	Method     string
+	Total      int64
+}
+
+type OrderCancelled struct {
+	OrderId    OrderId
+	CustomerId string
+	Reason     string
+}
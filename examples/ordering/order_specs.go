@@ -0,0 +1,32 @@
+package main
+
+//go:generate go run github.com/krew-solutions/ascetic-ddd-go/cmd/specgen -type=OrderView
+
+// OrderView is the flat state of Order, the same for the in-memory repository
+// and for the columns of the orders table.
+type OrderView struct {
+	CustomerId string
+	Status     string
+	Total      int64
+}
+
+// PendingOrderSpec checks if order awaits payment
+//
+//spec:sql
+func PendingOrderSpec(o OrderView) bool {
+	return o.Status == "pending"
+}
+
+// LargeOrderSpec checks if order total is at least $1000
+//
+//spec:sql
+func LargeOrderSpec(o OrderView) bool {
+	return o.Total >= 100000
+}
+
+// PendingLargeOrderSpec checks if large order awaits payment, e.g. for manual review
+//
+//spec:sql
+func PendingLargeOrderSpec(o OrderView) bool {
+	return o.Status == "pending" && o.Total >= 100000
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/outbox"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+type fixture struct {
+	db         *MemoryDb
+	sess       *MemorySession
+	outbox     *MemoryOutbox
+	repository *MemoryOrderRepository
+	warehouse  *Warehouse
+	card       *FakeGateway
+	wallet     *FakeGateway
+	service    *OrderingService
+	projector  *CustomerOrdersProjector
+}
+
+func newFixture() *fixture {
+	f := &fixture{db: NewMemoryDb()}
+	f.sess = NewMemorySession(context.Background(), f.db)
+	f.outbox = NewMemoryOutbox(f.db)
+	f.repository = NewMemoryOrderRepository()
+	f.warehouse = NewWarehouse(map[string]int{"laptop": 2, "mouse": 10})
+	f.card = NewFakeGateway("card")
+	f.wallet = NewFakeGateway("wallet")
+	f.service = NewOrderingService(f.repository, f.outbox, NewPaymentSaga(f.warehouse, f.card, f.wallet))
+	f.projector = NewCustomerOrdersProjector()
+	return f
+}
+
+func (f *fixture) place(t *testing.T, customerId string, lines ...OrderLine) OrderId {
+	t.Helper()
+	id, err := f.service.PlaceOrder(f.sess, customerId, lines)
+	if err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	return id
+}
+
+func (f *fixture) project(t *testing.T) {
+	t.Helper()
+	if err := f.outbox.DispatchAll(f.projector.Handle, "customer_orders"); err != nil {
+		t.Fatalf("DispatchAll: %v", err)
+	}
+}
+
+func (f *fixture) uris() []string {
+	var uris []string
+	for _, message := range f.db.messages {
+		uris = append(uris, message.URI)
+	}
+	return uris
+}
+
+func laptops(quantity int) OrderLine {
+	return OrderLine{Sku: "laptop", Quantity: quantity, Price: 150000}
+}
+
+func mice(quantity int) OrderLine {
+	return OrderLine{Sku: "mouse", Quantity: quantity, Price: 2500}
+}
+
+func TestPayOrderWithPrimaryGateway(t *testing.T) {
+	f := newFixture()
+	id := f.place(t, "alice", laptops(1), mice(2))
+
+	if err := f.service.PayOrder(f.sess, id); err != nil {
+		t.Fatalf("PayOrder: %v", err)
+	}
+
+	order, _ := f.repository.Get(f.sess, id)
+	if order.Status() != OrderStatusPaid || order.PaymentMethod() != "card" {
+		t.Errorf("expected paid by card, got %s by %q", order.Status(), order.PaymentMethod())
+	}
+	if order.Version() != 2 {
+		t.Errorf("expected version 2, got %d", order.Version())
+	}
+	if f.card.Charged() != 155000 || f.wallet.Charged() != 0 {
+		t.Errorf("expected card charged 155000, got card %d, wallet %d", f.card.Charged(), f.wallet.Charged())
+	}
+	if f.warehouse.Stock("laptop") != 1 || f.warehouse.Stock("mouse") != 8 {
+		t.Errorf("expected stock reserved, got laptop %d, mouse %d", f.warehouse.Stock("laptop"), f.warehouse.Stock("mouse"))
+	}
+
+	f.project(t)
+	customer, _ := f.projector.Get("alice")
+	expected := CustomerOrders{CustomerId: "alice", Paid: 1, Spent: 155000}
+	if customer != expected {
+		t.Errorf("expected %+v, got %+v", expected, customer)
+	}
+}
+
+func TestPayOrderFallsBackToWallet(t *testing.T) {
+	f := newFixture()
+	f.card.SetDeclines(true)
+	id := f.place(t, "alice", laptops(1))
+
+	if err := f.service.PayOrder(f.sess, id); err != nil {
+		t.Fatalf("PayOrder: %v", err)
+	}
+
+	order, _ := f.repository.Get(f.sess, id)
+	if order.PaymentMethod() != "wallet" {
+		t.Errorf("expected wallet, got %q", order.PaymentMethod())
+	}
+	if f.card.Charged() != 0 || f.wallet.Charged() != 150000 {
+		t.Errorf("expected wallet charged, got card %d, wallet %d", f.card.Charged(), f.wallet.Charged())
+	}
+}
+
+func TestPayOrderCompensatesWhenPaymentFails(t *testing.T) {
+	f := newFixture()
+	f.card.SetDeclines(true)
+	f.wallet.SetDeclines(true)
+	id := f.place(t, "bob", laptops(1))
+
+	err := f.service.PayOrder(f.sess, id)
+	if !errors.Is(err, ErrPaymentFailed) {
+		t.Fatalf("expected ErrPaymentFailed, got %v", err)
+	}
+
+	order, _ := f.repository.Get(f.sess, id)
+	if order.Status() != OrderStatusCancelled {
+		t.Errorf("expected cancelled, got %s", order.Status())
+	}
+	if f.warehouse.Stock("laptop") != 2 {
+		t.Errorf("expected stock released, got %d", f.warehouse.Stock("laptop"))
+	}
+
+	f.project(t)
+	customer, _ := f.projector.Get("bob")
+	expected := CustomerOrders{CustomerId: "bob", Cancelled: 1}
+	if customer != expected {
+		t.Errorf("expected %+v, got %+v", expected, customer)
+	}
+}
+
+func TestPayOrderOutOfStockDoesNotCharge(t *testing.T) {
+	f := newFixture()
+	id := f.place(t, "bob", laptops(1), mice(20))
+
+	err := f.service.PayOrder(f.sess, id)
+	if !errors.Is(err, ErrPaymentFailed) {
+		t.Fatalf("expected ErrPaymentFailed, got %v", err)
+	}
+	if f.card.Charged() != 0 {
+		t.Errorf("expected no charge, got %d", f.card.Charged())
+	}
+	if f.warehouse.Stock("laptop") != 2 || f.warehouse.Stock("mouse") != 10 {
+		t.Errorf("expected stock untouched, got laptop %d, mouse %d", f.warehouse.Stock("laptop"), f.warehouse.Stock("mouse"))
+	}
+}
+
+func TestPayOrderRefundsWhenOrderCannotBeSaved(t *testing.T) {
+	f := newFixture()
+	id := f.place(t, "alice", laptops(1))
+	f.service.publisher = publisherFunc(func(s session.Session, message *outbox.OutboxMessage) error {
+		return errors.New("outbox is unavailable")
+	})
+
+	if err := f.service.PayOrder(f.sess, id); err == nil {
+		t.Fatal("expected error")
+	}
+
+	order, _ := f.repository.Get(f.sess, id)
+	if order.Status() != OrderStatusPending {
+		t.Errorf("expected the transaction rolled back, got %s", order.Status())
+	}
+	if f.card.Charged() != 0 || f.warehouse.Stock("laptop") != 2 {
+		t.Errorf("expected payment compensated, got charged %d, stock %d", f.card.Charged(), f.warehouse.Stock("laptop"))
+	}
+}
+
+func TestPlaceOrderIsAtomic(t *testing.T) {
+	f := newFixture()
+	if _, err := f.service.PlaceOrder(f.sess, "alice", nil); !errors.Is(err, ErrEmptyOrder) {
+		t.Fatalf("expected ErrEmptyOrder, got %v", err)
+	}
+
+	failing := NewOrderingService(f.repository, publisherFunc(func(s session.Session, message *outbox.OutboxMessage) error {
+		return errors.New("outbox is unavailable")
+	}), nil)
+	if _, err := failing.PlaceOrder(f.sess, "alice", []OrderLine{mice(1)}); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(f.db.orders) != 0 || len(f.db.messages) != 0 {
+		t.Errorf("expected nothing saved, got %d orders, %d messages", len(f.db.orders), len(f.db.messages))
+	}
+}
+
+func TestFindBySpecification(t *testing.T) {
+	f := newFixture()
+	large := f.place(t, "alice", laptops(1))
+	f.place(t, "bob", mice(1))
+	paid := f.place(t, "carol", laptops(1))
+	if err := f.service.PayOrder(f.sess, paid); err != nil {
+		t.Fatalf("PayOrder: %v", err)
+	}
+
+	cases := []struct {
+		name          string
+		specification OrderSpecification
+		expected      int
+	}{
+		{"pending", PendingOrders, 2},
+		{"large", LargeOrders, 2},
+		{"pending large", PendingLargeOrders, 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			orders, err := f.repository.Find(f.sess, c.specification)
+			if err != nil {
+				t.Fatalf("Find: %v", err)
+			}
+			if len(orders) != c.expected {
+				t.Errorf("expected %d orders, got %d", c.expected, len(orders))
+			}
+		})
+	}
+
+	orders, _ := f.repository.Find(f.sess, PendingLargeOrders)
+	if orders[0].Id() != large {
+		t.Errorf("expected %s, got %s", large, orders[0].Id())
+	}
+}
+
+func TestOutboxPublishesInOrder(t *testing.T) {
+	f := newFixture()
+	f.card.SetDeclines(true)
+	f.wallet.SetDeclines(true)
+	id := f.place(t, "alice", mice(1))
+	_ = f.service.PayOrder(f.sess, id)
+
+	expected := []string{OrderPlacedURI, OrderCancelledURI}
+	uris := f.uris()
+	if len(uris) != len(expected) || uris[0] != expected[0] || uris[1] != expected[1] {
+		t.Errorf("expected %v, got %v", expected, uris)
+	}
+}
+
+func TestProjectorIsIdempotent(t *testing.T) {
+	f := newFixture()
+	id := f.place(t, "alice", mice(2))
+	if err := f.service.PayOrder(f.sess, id); err != nil {
+		t.Fatalf("PayOrder: %v", err)
+	}
+	f.project(t)
+
+	for _, message := range f.db.messages {
+		if err := f.projector.Handle(message); err != nil {
+			t.Fatalf("Handle: %v", err)
+		}
+	}
+
+	customer, _ := f.projector.Get("alice")
+	expected := CustomerOrders{CustomerId: "alice", Paid: 1, Spent: 5000}
+	if customer != expected {
+		t.Errorf("expected %+v, got %+v", expected, customer)
+	}
+}
+
+func TestProjectorRedeliversFailedMessage(t *testing.T) {
+	f := newFixture()
+	f.place(t, "alice", mice(1))
+	failures := 1
+	subscriber := func(message *outbox.OutboxMessage) error {
+		if failures > 0 {
+			failures--
+			return errors.New("read model is unavailable")
+		}
+		return f.projector.Handle(message)
+	}
+
+	if err := f.outbox.DispatchAll(subscriber, "customer_orders"); err == nil {
+		t.Fatal("expected error")
+	}
+	if err := f.outbox.DispatchAll(subscriber, "customer_orders"); err != nil {
+		t.Fatalf("DispatchAll: %v", err)
+	}
+
+	customer, _ := f.projector.Get("alice")
+	if customer.Pending != 1 {
+		t.Errorf("expected 1 pending, got %d", customer.Pending)
+	}
+}
+
+func TestSaveDetectsConcurrentModification(t *testing.T) {
+	f := newFixture()
+	id := f.place(t, "alice", mice(1))
+	first, _ := f.repository.Get(f.sess, id)
+	second, _ := f.repository.Get(f.sess, id)
+
+	_ = first.Cancel("changed my mind")
+	if err := f.repository.Save(f.sess, first); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	_ = second.MarkPaid("card")
+	if err := f.repository.Save(f.sess, second); !errors.Is(err, ErrConcurrentModification) {
+		t.Errorf("expected ErrConcurrentModification, got %v", err)
+	}
+}
+
+type publisherFunc func(s session.Session, message *outbox.OutboxMessage) error
+
+func (f publisherFunc) Publish(s session.Session, message *outbox.OutboxMessage) error {
+	return f(s, message)
+}
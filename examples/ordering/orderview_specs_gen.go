@@ -0,0 +1,42 @@
+// Code generated by specgen. DO NOT EDIT.
+
+package main
+
+import (
+	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
+	infra "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/infrastructure"
+)
+
+// PendingOrderSpecAST returns AST for PendingOrderSpec
+func PendingOrderSpecAST() spec.Visitable {
+	return spec.Equal(spec.Field(spec.GlobalScope(), "Status"), spec.Value("pending"))
+}
+
+// PendingOrderSpecSQL returns SQL for PendingOrderSpec
+func PendingOrderSpecSQL() (string, []any, error) {
+	ast := PendingOrderSpecAST()
+	return infra.CompileToSQL(ast)
+}
+
+// LargeOrderSpecAST returns AST for LargeOrderSpec
+func LargeOrderSpecAST() spec.Visitable {
+	return spec.GreaterThanEqual(spec.Field(spec.GlobalScope(), "Total"), spec.Value(100000))
+}
+
+// LargeOrderSpecSQL returns SQL for LargeOrderSpec
+func LargeOrderSpecSQL() (string, []any, error) {
+	ast := LargeOrderSpecAST()
+	return infra.CompileToSQL(ast)
+}
+
+// PendingLargeOrderSpecAST returns AST for PendingLargeOrderSpec
+func PendingLargeOrderSpecAST() spec.Visitable {
+	return spec.And(spec.Equal(spec.Field(spec.GlobalScope(), "Status"), spec.Value("pending")), spec.GreaterThanEqual(spec.Field(spec.GlobalScope(), "Total"), spec.Value(100000)))
+}
+
+// PendingLargeOrderSpecSQL returns SQL for PendingLargeOrderSpec
+func PendingLargeOrderSpecSQL() (string, []any, error) {
+	ast := PendingLargeOrderSpecAST()
+	return infra.CompileToSQL(ast)
+}
+
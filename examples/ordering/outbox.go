@@ -0,0 +1,57 @@
+package main
+
+import (
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/outbox"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+// Publisher publishes integration messages within the transaction, e.g. outbox.Outbox.
+type Publisher interface {
+	Publish(s session.Session, message *outbox.OutboxMessage) error
+}
+
+// MemoryOutbox keeps messages in MemoryDb, so they are published
+// if and only if the atomic scope that changed the aggregate is committed.
+// Dispatch delivers them to subscribers in the order of publication, one consumer group at a time.
+type MemoryOutbox struct {
+	db        *MemoryDb
+	positions map[string]int64
+}
+
+func NewMemoryOutbox(db *MemoryDb) *MemoryOutbox {
+	return &MemoryOutbox{db: db, positions: map[string]int64{}}
+}
+
+func (o *MemoryOutbox) Publish(s session.Session, message *outbox.OutboxMessage) error {
+	db := memoryDb(s)
+	position := int64(len(db.messages) + 1)
+	published := *message
+	published.Position = &position
+	db.messages = append(db.messages, &published)
+	return nil
+}
+
+// Dispatch delivers the next message to the subscriber and returns false when there is none.
+// The position of the consumer group advances only if the subscriber succeeds,
+// so a failed message is delivered again (at-least-once).
+func (o *MemoryOutbox) Dispatch(subscriber outbox.Subscriber, consumerGroup string) (bool, error) {
+	position := o.positions[consumerGroup]
+	if position >= int64(len(o.db.messages)) {
+		return false, nil
+	}
+	if err := subscriber(o.db.messages[position]); err != nil {
+		return false, err
+	}
+	o.positions[consumerGroup] = position + 1
+	return true, nil
+}
+
+// DispatchAll delivers all pending messages to the subscriber.
+func (o *MemoryOutbox) DispatchAll(subscriber outbox.Subscriber, consumerGroup string) error {
+	for {
+		dispatched, err := o.Dispatch(subscriber, consumerGroup)
+		if err != nil || !dispatched {
+			return err
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/saga"
+)
+
+var (
+	ErrOutOfStock      = errors.New("out of stock")
+	ErrPaymentDeclined = errors.New("payment declined")
+	ErrPaymentFailed   = errors.New("payment failed")
+)
+
+// Warehouse is the stock of another bounded context, reserved by the payment saga.
+type Warehouse struct {
+	mu           sync.Mutex
+	stock        map[string]int
+	reservations map[string][]OrderLine
+}
+
+func NewWarehouse(stock map[string]int) *Warehouse {
+	return &Warehouse{stock: maps.Clone(stock), reservations: map[string][]OrderLine{}}
+}
+
+func (w *Warehouse) Reserve(orderId string, lines []OrderLine) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, line := range lines {
+		if w.stock[line.Sku] < line.Quantity {
+			return fmt.Errorf("%s: %w", line.Sku, ErrOutOfStock)
+		}
+	}
+	for _, line := range lines {
+		w.stock[line.Sku] -= line.Quantity
+	}
+	w.reservations[orderId] = lines
+	return nil
+}
+
+func (w *Warehouse) Release(orderId string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, line := range w.reservations[orderId] {
+		w.stock[line.Sku] += line.Quantity
+	}
+	delete(w.reservations, orderId)
+}
+
+func (w *Warehouse) Stock(sku string) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stock[sku]
+}
+
+// PaymentGateway charges the customer by a payment method, e.g. a card or a wallet.
+type PaymentGateway interface {
+	Method() string
+	Charge(orderId string, amount int64) (chargeId string, err error)
+	Refund(chargeId string) error
+}
+
+// ReserveStockActivity reserves the lines of the order in the warehouse.
+// Arguments: "warehouse" *Warehouse, "orderId" string, "lines" []OrderLine.
+type ReserveStockActivity struct{}
+
+func NewReserveStockActivity() saga.Activity {
+	return &ReserveStockActivity{}
+}
+
+func (a *ReserveStockActivity) DoWork(ctx context.Context, workItem saga.WorkItem) (*saga.WorkLog, error) {
+	args := workItem.Arguments()
+	warehouse := args["warehouse"].(*Warehouse)
+	orderId := args["orderId"].(string)
+	if err := warehouse.Reserve(orderId, args["lines"].([]OrderLine)); err != nil {
+		return nil, err
+	}
+	workLog := saga.NewWorkLog(a, saga.WorkResult{"warehouse": warehouse, "orderId": orderId})
+	return &workLog, nil
+}
+
+func (a *ReserveStockActivity) Compensate(ctx context.Context, workLog saga.WorkLog, routingSlip *saga.RoutingSlip) (bool, error) {
+	result := workLog.Result()
+	result["warehouse"].(*Warehouse).Release(result["orderId"].(string))
+	return true, nil
+}
+
+func (a *ReserveStockActivity) WorkItemQueueAddress() string {
+	return "sb://./stockReservations"
+}
+
+func (a *ReserveStockActivity) CompensationQueueAddress() string {
+	return "sb://./stockReleases"
+}
+
+func (a *ReserveStockActivity) ActivityType() saga.ActivityType {
+	return NewReserveStockActivity
+}
+
+// ChargeActivity charges the order total with the gateway.
+// Arguments: "gateway" PaymentGateway, "orderId" string, "amount" int64.
+type ChargeActivity struct{}
+
+func NewChargeActivity() saga.Activity {
+	return &ChargeActivity{}
+}
+
+func (a *ChargeActivity) DoWork(ctx context.Context, workItem saga.WorkItem) (*saga.WorkLog, error) {
+	args := workItem.Arguments()
+	gateway := args["gateway"].(PaymentGateway)
+	chargeId, err := gateway.Charge(args["orderId"].(string), args["amount"].(int64))
+	if err != nil {
+		return nil, err
+	}
+	workLog := saga.NewWorkLog(a, saga.WorkResult{
+		"gateway":  gateway,
+		"chargeId": chargeId,
+		"method":   gateway.Method(),
+	})
+	return &workLog, nil
+}
+
+func (a *ChargeActivity) Compensate(ctx context.Context, workLog saga.WorkLog, routingSlip *saga.RoutingSlip) (bool, error) {
+	result := workLog.Result()
+	if err := result["gateway"].(PaymentGateway).Refund(result["chargeId"].(string)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (a *ChargeActivity) WorkItemQueueAddress() string {
+	return "sb://./charges"
+}
+
+func (a *ChargeActivity) CompensationQueueAddress() string {
+	return "sb://./refunds"
+}
+
+func (a *ChargeActivity) ActivityType() saga.ActivityType {
+	return NewChargeActivity
+}
+
+// PaymentSaga reserves the stock and charges the order
+// with the first payment gateway that accepts it (saga.FallbackActivity).
+// If the stock cannot be reserved or all gateways decline, completed steps are compensated.
+type PaymentSaga struct {
+	warehouse *Warehouse
+	gateways  []PaymentGateway
+}
+
+func NewPaymentSaga(warehouse *Warehouse, gateways ...PaymentGateway) *PaymentSaga {
+	return &PaymentSaga{warehouse: warehouse, gateways: gateways}
+}
+
+// Payment is the outcome of a completed PaymentSaga.
+type Payment struct {
+	Method      string
+	routingSlip *saga.RoutingSlip
+}
+
+// Compensate refunds the charge and releases the stock,
+// e.g. when the order cannot be saved as paid.
+func (p *Payment) Compensate(ctx context.Context) error {
+	return compensate(ctx, p.routingSlip)
+}
+
+func (ps *PaymentSaga) Run(ctx context.Context, order *Order) (*Payment, error) {
+	orderId := order.Id().String()
+	alternatives := make([]*saga.RoutingSlip, len(ps.gateways))
+	for i, gateway := range ps.gateways {
+		alternatives[i] = saga.NewRoutingSlip([]saga.WorkItem{
+			saga.NewWorkItem(NewChargeActivity, saga.WorkItemArguments{
+				"gateway": gateway,
+				"orderId": orderId,
+				"amount":  order.Total(),
+			}),
+		})
+	}
+	routingSlip := saga.NewRoutingSlip([]saga.WorkItem{
+		saga.NewWorkItem(NewReserveStockActivity, saga.WorkItemArguments{
+			"warehouse": ps.warehouse,
+			"orderId":   orderId,
+			"lines":     order.Lines(),
+		}),
+		saga.NewWorkItem(saga.NewFallbackActivity, saga.WorkItemArguments{
+			"alternatives": alternatives,
+		}),
+	})
+
+	for !routingSlip.IsCompleted() {
+		ok, err := routingSlip.ProcessNext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if err := compensate(ctx, routingSlip); err != nil {
+				return nil, err
+			}
+			return nil, ErrPaymentFailed
+		}
+	}
+
+	workLogs := routingSlip.CompletedWorkLogs()
+	charged := workLogs[len(workLogs)-1].Result()["_succeeded"].(*saga.RoutingSlip)
+	method := charged.CompletedWorkLogs()[0].Result()["method"].(string)
+	return &Payment{Method: method, routingSlip: routingSlip}, nil
+}
+
+func compensate(ctx context.Context, routingSlip *saga.RoutingSlip) error {
+	for routingSlip.IsInProgress() {
+		if _, err := routingSlip.UndoLast(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/seedwork/domain/identity"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/seedwork/domain/uuid"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+// OrdersTable has the columns OrderView is mapped to,
+// so that the SQL of the specifications applies as is.
+const OrdersTable = `
+CREATE TABLE IF NOT EXISTS orders (
+    id uuid PRIMARY KEY,
+    customerid text NOT NULL,
+    status text NOT NULL,
+    total bigint NOT NULL,
+    lines jsonb NOT NULL,
+    paymentmethod text NOT NULL DEFAULT '',
+    version integer NOT NULL
+)`
+
+const selectOrders = "SELECT id, customerid, status, lines, paymentmethod, version FROM orders"
+
+type PgOrderRepository struct{}
+
+func NewPgOrderRepository() *PgOrderRepository {
+	return &PgOrderRepository{}
+}
+
+func (r *PgOrderRepository) Get(s session.Session, id OrderId) (*Order, error) {
+	row := s.(session.DbSession).Connection().QueryRow(selectOrders+" WHERE id = $1", id.String())
+	order, err := r.scan(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOrderNotFound
+	}
+	return order, err
+}
+
+func (r *PgOrderRepository) Save(s session.Session, order *Order) error {
+	lines, err := json.Marshal(order.Lines())
+	if err != nil {
+		return err
+	}
+	conn := s.(session.DbSession).Connection()
+	version := order.Version()
+	if version == 0 {
+		_, err = conn.Exec(
+			"INSERT INTO orders (id, customerid, status, total, lines, paymentmethod, version) "+
+				"VALUES ($1, $2, $3, $4, $5, $6, $7)",
+			order.Id().String(), order.CustomerId(), string(order.Status()), order.Total(),
+			lines, order.PaymentMethod(), version+1,
+		)
+		if err != nil {
+			return err
+		}
+		order.NextVersion()
+		return nil
+	}
+	result, err := conn.Exec(
+		"UPDATE orders SET status = $2, total = $3, lines = $4, paymentmethod = $5, version = $6 "+
+			"WHERE id = $1 AND version = $7",
+		order.Id().String(), string(order.Status()), order.Total(),
+		lines, order.PaymentMethod(), version+1, version,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrConcurrentModification
+	}
+	order.NextVersion()
+	return nil
+}
+
+// Find selects orders with the SQL generated by specgen for the specification.
+func (r *PgOrderRepository) Find(s session.Session, specification OrderSpecification) ([]*Order, error) {
+	where, params, err := specification.SQL()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.(session.DbSession).Connection().Query(selectOrders+" WHERE "+where+" ORDER BY id", params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var orders []*Order
+	for rows.Next() {
+		order, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, rows.Err()
+}
+
+func (r *PgOrderRepository) scan(row session.Row) (*Order, error) {
+	var rawId, customerId, status, paymentMethod string
+	var lines []byte
+	var version int64
+	if err := row.Scan(&rawId, &customerId, &status, &lines, &paymentMethod, &version); err != nil {
+		return nil, err
+	}
+	value, err := uuid.Parse(rawId)
+	if err != nil {
+		return nil, err
+	}
+	id, err := identity.NewUuidIdentity(value)
+	if err != nil {
+		return nil, err
+	}
+	record := orderRecord{
+		id:            id,
+		customerId:    customerId,
+		status:        OrderStatus(status),
+		paymentMethod: paymentMethod,
+		version:       uint(version),
+	}
+	if err := json.Unmarshal(lines, &record.lines); err != nil {
+		return nil, err
+	}
+	return reconstituteOrder(record), nil
+}
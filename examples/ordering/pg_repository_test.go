@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/utils/testutils"
+)
+
+func TestPgOrderRepositoryFind(t *testing.T) {
+	id := NewOrderId()
+	rows := testutils.NewRowsStub(
+		[]any{id.String(), "alice", "pending", []byte(`[{"Sku":"laptop","Quantity":1,"Price":150000}]`), "", int64(1)},
+	)
+	s := testutils.NewDbSessionStub(rows)
+
+	orders, err := NewPgOrderRepository().Find(s, PendingLargeOrders)
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+
+	expectedQuery := selectOrders + " WHERE Status = $1 AND Total >= $2 ORDER BY id"
+	if s.ActualQuery != expectedQuery {
+		t.Errorf("expected %q, got %q", expectedQuery, s.ActualQuery)
+	}
+	if len(s.ActualParams) != 2 || s.ActualParams[0] != "pending" || s.ActualParams[1] != 100000 {
+		t.Errorf("unexpected params %v", s.ActualParams)
+	}
+	if len(orders) != 1 {
+		t.Fatalf("expected 1 order, got %d", len(orders))
+	}
+	if orders[0].Id() != id || orders[0].Total() != 150000 || orders[0].Version() != 1 {
+		t.Errorf("unexpected order %+v", orders[0])
+	}
+	if !PendingLargeOrderSpec(orders[0].View()) {
+		t.Error("expected the order to satisfy the specification in memory")
+	}
+	if !rows.Closed {
+		t.Error("expected rows closed")
+	}
+}
+
+func TestPgOrderRepositorySave(t *testing.T) {
+	t.Run("insert", func(t *testing.T) {
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+		order, _ := PlaceOrder(NewOrderId(), "alice", []OrderLine{{Sku: "mouse", Quantity: 2, Price: 2500}})
+
+		if err := NewPgOrderRepository().Save(s, order); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		if s.ActualParams[3] != int64(5000) || s.ActualParams[6] != uint(1) {
+			t.Errorf("unexpected params %v", s.ActualParams)
+		}
+		if order.Version() != 1 {
+			t.Errorf("expected version 1, got %d", order.Version())
+		}
+	})
+	t.Run("update of stale version", func(t *testing.T) {
+		// The stub affects no rows, like an UPDATE of a version changed concurrently.
+		s := testutils.NewDbSessionStub(testutils.NewRowsStub())
+		order, _ := PlaceOrder(NewOrderId(), "alice", []OrderLine{{Sku: "mouse", Quantity: 2, Price: 2500}})
+		order.SetVersion(1)
+
+		err := NewPgOrderRepository().Save(s, order)
+		if !errors.Is(err, ErrConcurrentModification) {
+			t.Errorf("expected ErrConcurrentModification, got %v", err)
+		}
+		if order.Version() != 1 {
+			t.Errorf("expected version 1, got %d", order.Version())
+		}
+	})
+}
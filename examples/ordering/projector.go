@@ -0,0 +1,113 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/outbox"
+)
+
+// CustomerOrders is the read model of orders of a customer.
+type CustomerOrders struct {
+	CustomerId string
+	Pending    int
+	Paid       int
+	Cancelled  int
+	// Spent is the total of paid orders, in cents.
+	Spent int64
+}
+
+// CustomerOrdersProjector maintains CustomerOrders from the messages of the outbox.
+// Messages at or below the last projected position are skipped,
+// so redelivery does not count an order twice.
+type CustomerOrdersProjector struct {
+	mu        sync.RWMutex
+	customers map[string]*CustomerOrders
+	position  int64
+}
+
+func NewCustomerOrdersProjector() *CustomerOrdersProjector {
+	return &CustomerOrdersProjector{customers: map[string]*CustomerOrders{}}
+}
+
+// Handle is an outbox.Subscriber.
+func (p *CustomerOrdersProjector) Handle(message *outbox.OutboxMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if message.Position != nil && *message.Position <= p.position {
+		return nil
+	}
+	customerId, _ := message.Payload["customer_id"].(string)
+	customer := p.customer(customerId)
+	switch message.URI {
+	case OrderPlacedURI:
+		customer.Pending++
+	case OrderPaidURI:
+		customer.Pending--
+		customer.Paid++
+		customer.Spent += amount(message.Payload["total"])
+	case OrderCancelledURI:
+		customer.Pending--
+		customer.Cancelled++
+	default:
+		return fmt.Errorf("unexpected message %s", message.URI)
+	}
+	if message.Position != nil {
+		p.position = *message.Position
+	}
+	return nil
+}
+
+func (p *CustomerOrdersProjector) Get(customerId string) (CustomerOrders, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	customer, ok := p.customers[customerId]
+	if !ok {
+		return CustomerOrders{}, false
+	}
+	return *customer, true
+}
+
+// TopCustomers returns customers by Spent in descending order.
+func (p *CustomerOrdersProjector) TopCustomers(limit int) []CustomerOrders {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	customers := make([]CustomerOrders, 0, len(p.customers))
+	for _, customer := range p.customers {
+		customers = append(customers, *customer)
+	}
+	slices.SortFunc(customers, func(a, b CustomerOrders) int {
+		if c := cmp.Compare(b.Spent, a.Spent); c != 0 {
+			return c
+		}
+		return strings.Compare(a.CustomerId, b.CustomerId)
+	})
+	return customers[:min(limit, len(customers))]
+}
+
+func (p *CustomerOrdersProjector) customer(customerId string) *CustomerOrders {
+	customer, ok := p.customers[customerId]
+	if !ok {
+		customer = &CustomerOrders{CustomerId: customerId}
+		p.customers[customerId] = customer
+	}
+	return customer
+}
+
+// amount reads cents from the payload, which is decoded from JSON by a real outbox.
+func amount(value any) int64 {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	case json.Number:
+		n, _ := v.Int64()
+		return n
+	}
+	return 0
+}
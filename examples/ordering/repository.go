@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/seedwork/domain/aggregate"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+var (
+	ErrOrderNotFound          = errors.New("order not found")
+	ErrConcurrentModification = errors.New("order was modified concurrently")
+)
+
+// OrderSpecification pairs the generated forms of a specgen specification:
+// the predicate for in-memory stores and the SQL for PostgreSQL.
+type OrderSpecification struct {
+	IsSatisfiedBy func(OrderView) bool
+	SQL           func() (string, []any, error)
+}
+
+var (
+	PendingOrders      = OrderSpecification{PendingOrderSpec, PendingOrderSpecSQL}
+	LargeOrders        = OrderSpecification{LargeOrderSpec, LargeOrderSpecSQL}
+	PendingLargeOrders = OrderSpecification{PendingLargeOrderSpec, PendingLargeOrderSpecSQL}
+)
+
+type OrderRepository interface {
+	Get(s session.Session, id OrderId) (*Order, error)
+	// Save inserts a new Order (version 0) or updates the stored one of the previous version.
+	Save(s session.Session, order *Order) error
+	Find(s session.Session, specification OrderSpecification) ([]*Order, error)
+}
+
+func (o Order) record() orderRecord {
+	return orderRecord{
+		id:            o.id,
+		customerId:    o.customerId,
+		lines:         o.Lines(),
+		status:        o.status,
+		paymentMethod: o.paymentMethod,
+		version:       o.Version(),
+	}
+}
+
+func reconstituteOrder(r orderRecord) *Order {
+	return &Order{
+		id:                 r.id,
+		customerId:         r.customerId,
+		lines:              append([]OrderLine(nil), r.lines...),
+		status:             r.status,
+		paymentMethod:      r.paymentMethod,
+		VersionedAggregate: aggregate.NewVersionedAggregate(r.version),
+	}
+}
+
+// MemoryOrderRepository stores orders in MemoryDb, the session must be MemorySession.
+type MemoryOrderRepository struct{}
+
+func NewMemoryOrderRepository() *MemoryOrderRepository {
+	return &MemoryOrderRepository{}
+}
+
+func (r *MemoryOrderRepository) Get(s session.Session, id OrderId) (*Order, error) {
+	record, ok := memoryDb(s).orders[id.String()]
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+	return reconstituteOrder(record), nil
+}
+
+func (r *MemoryOrderRepository) Save(s session.Session, order *Order) error {
+	db := memoryDb(s)
+	key := order.Id().String()
+	stored, ok := db.orders[key]
+	if ok != (order.Version() > 0) || (ok && stored.version != order.Version()) {
+		return ErrConcurrentModification
+	}
+	order.NextVersion()
+	db.orders[key] = order.record()
+	return nil
+}
+
+func (r *MemoryOrderRepository) Find(s session.Session, specification OrderSpecification) ([]*Order, error) {
+	var orders []*Order
+	for _, record := range memoryDb(s).orders {
+		order := reconstituteOrder(record)
+		if specification.IsSatisfiedBy(order.View()) {
+			orders = append(orders, order)
+		}
+	}
+	slices.SortFunc(orders, func(a, b *Order) int {
+		return strings.Compare(a.Id().String(), b.Id().String())
+	})
+	return orders, nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/outbox"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/seedwork/domain/aggregate"
+	"github.com/krew-solutions/ascetic-ddd-go/asceticddd/session"
+)
+
+const (
+	OrderPlacedURI    = "order.placed"
+	OrderPaidURI      = "order.paid"
+	OrderCancelledURI = "order.cancelled"
+)
+
+// OrderingService is the application service of the ordering bounded context.
+// Each use case saves the aggregate and publishes its events in one atomic scope.
+type OrderingService struct {
+	repository OrderRepository
+	publisher  Publisher
+	payment    *PaymentSaga
+}
+
+func NewOrderingService(repository OrderRepository, publisher Publisher, payment *PaymentSaga) *OrderingService {
+	return &OrderingService{
+		repository: repository,
+		publisher:  publisher,
+		payment:    payment,
+	}
+}
+
+func (s *OrderingService) PlaceOrder(sess session.Session, customerId string, lines []OrderLine) (OrderId, error) {
+	id := NewOrderId()
+	err := sess.Atomic(func(tx session.Session) error {
+		order, err := PlaceOrder(id, customerId, lines)
+		if err != nil {
+			return err
+		}
+		return s.save(tx, order)
+	})
+	return id, err
+}
+
+// PayOrder runs the payment saga outside of the transaction, as it calls other services,
+// then marks the order paid, or cancels it and returns ErrPaymentFailed.
+// If the order cannot be saved as paid, the payment is compensated.
+func (s *OrderingService) PayOrder(sess session.Session, id OrderId) error {
+	order, err := s.repository.Get(sess, id)
+	if err != nil {
+		return err
+	}
+	if order.Status() != OrderStatusPending {
+		return ErrOrderNotPending
+	}
+
+	payment, err := s.payment.Run(sess.Context(), order)
+	if errors.Is(err, ErrPaymentFailed) {
+		cancelErr := sess.Atomic(func(tx session.Session) error {
+			if err := order.Cancel("payment failed"); err != nil {
+				return err
+			}
+			return s.save(tx, order)
+		})
+		return errors.Join(err, cancelErr)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = sess.Atomic(func(tx session.Session) error {
+		if err := order.MarkPaid(payment.Method); err != nil {
+			return err
+		}
+		return s.save(tx, order)
+	})
+	if err != nil {
+		return errors.Join(err, payment.Compensate(sess.Context()))
+	}
+	return nil
+}
+
+func (s *OrderingService) save(tx session.Session, order *Order) error {
+	if err := s.repository.Save(tx, order); err != nil {
+		return err
+	}
+	if err := s.publish(tx, order.PendingDomainEvents()); err != nil {
+		return err
+	}
+	order.ClearPendingDomainEvents()
+	return nil
+}
+
+func (s *OrderingService) publish(tx session.Session, events []aggregate.DomainEvent) error {
+	for _, event := range events {
+		var message *outbox.OutboxMessage
+		switch e := event.(type) {
+		case OrderPlaced:
+			message = &outbox.OutboxMessage{
+				URI: OrderPlacedURI,
+				Payload: map[string]any{
+					"order_id":    e.OrderId.String(),
+					"customer_id": e.CustomerId,
+					"total":       e.Total,
+				},
+			}
+		case OrderPaid:
+			message = &outbox.OutboxMessage{
+				URI: OrderPaidURI,
+				Payload: map[string]any{
+					"order_id":    e.OrderId.String(),
+					"customer_id": e.CustomerId,
+					"method":      e.Method,
+					"total":       e.Total,
+				},
+			}
+		case OrderCancelled:
+			message = &outbox.OutboxMessage{
+				URI: OrderCancelledURI,
+				Payload: map[string]any{
+					"order_id":    e.OrderId.String(),
+					"customer_id": e.CustomerId,
+					"reason":      e.Reason,
+				},
+			}
+		default:
+			return fmt.Errorf("unknown event %T", event)
+		}
+		if err := s.publisher.Publish(tx, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -4,7 +4,7 @@ import (
 	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
 )
 
-//go:generate go run github.com/krew-solutions/ascetic-ddd-go/cmd/specgen -type=Store
+//go:generate go run github.com/krew-solutions/ascetic-ddd-go/cmd/specgen -type=Store -dialects=postgres
 
 // Item represents an item in a store
 type Item struct {
@@ -160,3 +160,21 @@ func NotAllItemsActiveSpec(s Store) bool {
 		return item.Active
 	})
 }
+
+// === Aggregates of Collections ===
+
+// WellStockedStoreSpec checks that the store has at least 3 items in stock
+//spec:sql
+func WellStockedStoreSpec(s Store) bool {
+	return spec.Count(s.Items, func(item Item) bool {
+		return item.Stock > 0
+	}) >= 3
+}
+
+// ValuableStockSpec checks that the total value of the stock exceeds 100000
+//spec:sql
+func ValuableStockSpec(s Store) bool {
+	return spec.Sum(s.Items, func(item Item) int {
+		return item.Price * item.Stock
+	}) > 100000
+}
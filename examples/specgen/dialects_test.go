@@ -0,0 +1,67 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSQLPostgresMatchesSQL checks that the Postgres dialect functions compile the specs like the default ones.
+func TestSQLPostgresMatchesSQL(t *testing.T) {
+	type compile func() (string, []any, error)
+	tests := []struct {
+		name     string
+		sql      compile
+		postgres compile
+	}{
+		{"AdultUserSpec", AdultUserSpecSQL, AdultUserSpecSQLPostgres},
+		{"ActiveUserSpec", ActiveUserSpecSQL, ActiveUserSpecSQLPostgres},
+		{"ValidEmailSpec", ValidEmailSpecSQL, ValidEmailSpecSQLPostgres},
+		{"PremiumUserSpec", PremiumUserSpecSQL, PremiumUserSpecSQLPostgres},
+		{"YoungUserSpec", YoungUserSpecSQL, YoungUserSpecSQLPostgres},
+		{"InactiveUserSpec", InactiveUserSpecSQL, InactiveUserSpecSQLPostgres},
+		{"TeenagerUserSpec", TeenagerUserSpecSQL, TeenagerUserSpecSQLPostgres},
+		{"UserIsSeniorSpec", UserIsSeniorSpecSQL, UserIsSeniorSpecSQLPostgres},
+		{"EligibleUserSpec", EligibleUserSpecSQL, EligibleUserSpecSQLPostgres},
+		{"HasRegionWithExpensiveItemsSpec", HasRegionWithExpensiveItemsSpecSQL, HasRegionWithExpensiveItemsSpecSQLPostgres},
+		{"HasActiveRegionWithPremiumItemsSpec", HasActiveRegionWithPremiumItemsSpecSQL, HasActiveRegionWithPremiumItemsSpecSQLPostgres},
+		{"ActiveOrgWithExpensiveItemsSpec", ActiveOrgWithExpensiveItemsSpecSQL, ActiveOrgWithExpensiveItemsSpecSQLPostgres},
+		{"NoRegionWithExpensiveItemsSpec", NoRegionWithExpensiveItemsSpecSQL, NoRegionWithExpensiveItemsSpecSQLPostgres},
+		{"ActiveStoreSpec", ActiveStoreSpecSQL, ActiveStoreSpecSQLPostgres},
+		{"NamedStoreSpec", NamedStoreSpecSQL, NamedStoreSpecSQLPostgres},
+		{"HasExpensiveItemsSpec", HasExpensiveItemsSpecSQL, HasExpensiveItemsSpecSQLPostgres},
+		{"AllItemsActiveSpec", AllItemsActiveSpecSQL, AllItemsActiveSpecSQLPostgres},
+		{"HasCheapItemsSpec", HasCheapItemsSpecSQL, HasCheapItemsSpecSQLPostgres},
+		{"HasItemInStockSpec", HasItemInStockSpecSQL, HasItemInStockSpecSQLPostgres},
+		{"HasAffordableActiveItemsSpec", HasAffordableActiveItemsSpecSQL, HasAffordableActiveItemsSpecSQLPostgres},
+		{"HasPremiumItemsSpec", HasPremiumItemsSpecSQL, HasPremiumItemsSpecSQLPostgres},
+		{"HasDiscountedExpensiveItemsSpec", HasDiscountedExpensiveItemsSpecSQL, HasDiscountedExpensiveItemsSpecSQLPostgres},
+		{"HasTaxedCheapItemsSpec", HasTaxedCheapItemsSpecSQL, HasTaxedCheapItemsSpecSQLPostgres},
+		{"HasItemWithFlagSpec", HasItemWithFlagSpecSQL, HasItemWithFlagSpecSQLPostgres},
+		{"HasItemWithShiftedIDSpec", HasItemWithShiftedIDSpecSQL, HasItemWithShiftedIDSpecSQLPostgres},
+		{"PremiumActiveStoreSpec", PremiumActiveStoreSpecSQL, PremiumActiveStoreSpecSQLPostgres},
+		{"BudgetFriendlyStoreSpec", BudgetFriendlyStoreSpecSQL, BudgetFriendlyStoreSpecSQLPostgres},
+		{"NoExpensiveItemsSpec", NoExpensiveItemsSpecSQL, NoExpensiveItemsSpecSQLPostgres},
+		{"NotAllItemsActiveSpec", NotAllItemsActiveSpecSQL, NotAllItemsActiveSpecSQLPostgres},
+		{"WellStockedStoreSpec", WellStockedStoreSpecSQL, WellStockedStoreSpecSQLPostgres},
+		{"ValuableStockSpec", ValuableStockSpecSQL, ValuableStockSpecSQLPostgres},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, params, err := tt.sql()
+			if err != nil {
+				t.Fatalf("SQL failed: %v", err)
+			}
+			postgresSQL, postgresParams, err := tt.postgres()
+			if err != nil {
+				t.Fatalf("SQLPostgres failed: %v", err)
+			}
+			if postgresSQL != sql {
+				t.Errorf("Expected SQL: %s, got: %s", sql, postgresSQL)
+			}
+			if !reflect.DeepEqual(postgresParams, params) {
+				t.Errorf("Expected params: %v, got: %v", params, postgresParams)
+			}
+		})
+	}
+}
@@ -102,6 +102,12 @@ func runAdvancedDemo() {
 
 	fmt.Println("\n=== 4. BITWISE OPERATIONS ===")
 
+	if HasItemWithFlagSpec(store) {
+		fmt.Println("✓ Store has item where Stock & 1 == 1")
+		sql, params, _ := HasItemWithFlagSpecSQL()
+		fmt.Printf("  SQL: WHERE %s\n  Params: %v\n", sql, params)
+	}
+
 	if HasItemWithShiftedIDSpec(store) {
 		fmt.Println("✓ Store has item where (ID << 2) == 8")
 		sql, params, _ := HasItemWithShiftedIDSpecSQL()
@@ -140,7 +146,7 @@ func runAdvancedDemo() {
 	fmt.Println("✅ Wildcards: spec.Any() and spec.All() for collections")
 	fmt.Println("✅ Nested fields: item.Price, item.Active, etc.")
 	fmt.Println("✅ Arithmetic: +, -, *, /, %")
-	fmt.Println("✅ Bitwise: <<, >> (AND, OR, XOR - TODO)")
+	fmt.Println("✅ Bitwise: <<, >>, &, |, ^")
 	fmt.Println("✅ Complex predicates: Wildcards + AND + OR + NOT")
 	fmt.Println("✅ SQL generation: All features compile to SQL")
 
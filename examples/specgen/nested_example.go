@@ -1,6 +1,6 @@
 package main
 
-//go:generate go run github.com/krew-solutions/ascetic-ddd-go/cmd/specgen -type=Organization
+//go:generate go run github.com/krew-solutions/ascetic-ddd-go/cmd/specgen -type=Organization -dialects=postgres
 
 import (
 	spec "github.com/krew-solutions/ascetic-ddd-go/asceticddd/specification/domain"
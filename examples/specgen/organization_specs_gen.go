@@ -1,4 +1,5 @@
 // Code generated by specgen. DO NOT EDIT.
+// Source hash: sha256:255c203677dd12992b6e7e106dd487c32c3164e318f9cf02e7e420fe98cc04c3
 
 package main
 
@@ -18,6 +19,11 @@ func HasRegionWithExpensiveItemsSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// HasRegionWithExpensiveItemsSpecSQLPostgres returns Postgres SQL for HasRegionWithExpensiveItemsSpec
+func HasRegionWithExpensiveItemsSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(HasRegionWithExpensiveItemsSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
 // HasActiveRegionWithPremiumItemsSpecAST returns AST for HasActiveRegionWithPremiumItemsSpec
 func HasActiveRegionWithPremiumItemsSpecAST() spec.Visitable {
 	return spec.Wildcard(spec.Object(spec.GlobalScope(), "Regions"), spec.And(spec.Field(spec.Item(), "Active"), spec.Wildcard(spec.Object(spec.Item(), "Categories"), spec.And(spec.Field(spec.Item(), "Active"), spec.Wildcard(spec.Object(spec.Item(), "Items"), spec.And(spec.GreaterThan(spec.Field(spec.Item(), "Price"), spec.Value(5000)), spec.Field(spec.Item(), "Active")))))))
@@ -29,6 +35,11 @@ func HasActiveRegionWithPremiumItemsSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// HasActiveRegionWithPremiumItemsSpecSQLPostgres returns Postgres SQL for HasActiveRegionWithPremiumItemsSpec
+func HasActiveRegionWithPremiumItemsSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(HasActiveRegionWithPremiumItemsSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
 // ActiveOrgWithExpensiveItemsSpecAST returns AST for ActiveOrgWithExpensiveItemsSpec
 func ActiveOrgWithExpensiveItemsSpecAST() spec.Visitable {
 	return spec.And(spec.Field(spec.GlobalScope(), "Active"), spec.Wildcard(spec.Object(spec.GlobalScope(), "Regions"), spec.Wildcard(spec.Object(spec.Item(), "Categories"), spec.Wildcard(spec.Object(spec.Item(), "Items"), spec.GreaterThan(spec.Field(spec.Item(), "Price"), spec.Value(10000))))))
@@ -40,6 +51,11 @@ func ActiveOrgWithExpensiveItemsSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// ActiveOrgWithExpensiveItemsSpecSQLPostgres returns Postgres SQL for ActiveOrgWithExpensiveItemsSpec
+func ActiveOrgWithExpensiveItemsSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(ActiveOrgWithExpensiveItemsSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
 // NoRegionWithExpensiveItemsSpecAST returns AST for NoRegionWithExpensiveItemsSpec
 func NoRegionWithExpensiveItemsSpecAST() spec.Visitable {
 	return spec.Not(spec.Wildcard(spec.Object(spec.GlobalScope(), "Regions"), spec.Wildcard(spec.Object(spec.Item(), "Categories"), spec.Wildcard(spec.Object(spec.Item(), "Items"), spec.GreaterThan(spec.Field(spec.Item(), "Price"), spec.Value(100000))))))
@@ -51,3 +67,7 @@ func NoRegionWithExpensiveItemsSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// NoRegionWithExpensiveItemsSpecSQLPostgres returns Postgres SQL for NoRegionWithExpensiveItemsSpec
+func NoRegionWithExpensiveItemsSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(NoRegionWithExpensiveItemsSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
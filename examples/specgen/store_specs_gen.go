@@ -1,4 +1,5 @@
 // Code generated by specgen. DO NOT EDIT.
+// Source hash: sha256:22be5e24162c87a413be754628ad8dc930d1c889fa385a0a6aa74ff30be75ff0
 
 package main
 
@@ -18,6 +19,11 @@ func ActiveStoreSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// ActiveStoreSpecSQLPostgres returns Postgres SQL for ActiveStoreSpec
+func ActiveStoreSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(ActiveStoreSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
 // NamedStoreSpecAST returns AST for NamedStoreSpec
 func NamedStoreSpecAST() spec.Visitable {
 	return spec.NotEqual(spec.Field(spec.GlobalScope(), "Name"), spec.Value(""))
@@ -29,6 +35,11 @@ func NamedStoreSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// NamedStoreSpecSQLPostgres returns Postgres SQL for NamedStoreSpec
+func NamedStoreSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(NamedStoreSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
 // HasExpensiveItemsSpecAST returns AST for HasExpensiveItemsSpec
 func HasExpensiveItemsSpecAST() spec.Visitable {
 	return spec.Wildcard(spec.Object(spec.GlobalScope(), "Items"), spec.GreaterThan(spec.Field(spec.Item(), "Price"), spec.Value(1000)))
@@ -40,9 +51,14 @@ func HasExpensiveItemsSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// HasExpensiveItemsSpecSQLPostgres returns Postgres SQL for HasExpensiveItemsSpec
+func HasExpensiveItemsSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(HasExpensiveItemsSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
 // AllItemsActiveSpecAST returns AST for AllItemsActiveSpec
 func AllItemsActiveSpecAST() spec.Visitable {
-	return spec.Wildcard(spec.Object(spec.GlobalScope(), "Items"), spec.Field(spec.Item(), "Active"))
+	return spec.Every(spec.Object(spec.GlobalScope(), "Items"), spec.Field(spec.Item(), "Active"))
 }
 
 // AllItemsActiveSpecSQL returns SQL for AllItemsActiveSpec
@@ -51,6 +67,11 @@ func AllItemsActiveSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// AllItemsActiveSpecSQLPostgres returns Postgres SQL for AllItemsActiveSpec
+func AllItemsActiveSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(AllItemsActiveSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
 // HasCheapItemsSpecAST returns AST for HasCheapItemsSpec
 func HasCheapItemsSpecAST() spec.Visitable {
 	return spec.Wildcard(spec.Object(spec.GlobalScope(), "Items"), spec.LessThan(spec.Field(spec.Item(), "Price"), spec.Value(100)))
@@ -62,6 +83,11 @@ func HasCheapItemsSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// HasCheapItemsSpecSQLPostgres returns Postgres SQL for HasCheapItemsSpec
+func HasCheapItemsSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(HasCheapItemsSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
 // HasItemInStockSpecAST returns AST for HasItemInStockSpec
 func HasItemInStockSpecAST() spec.Visitable {
 	return spec.Wildcard(spec.Object(spec.GlobalScope(), "Items"), spec.GreaterThan(spec.Field(spec.Item(), "Stock"), spec.Value(0)))
@@ -73,6 +99,11 @@ func HasItemInStockSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// HasItemInStockSpecSQLPostgres returns Postgres SQL for HasItemInStockSpec
+func HasItemInStockSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(HasItemInStockSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
 // HasAffordableActiveItemsSpecAST returns AST for HasAffordableActiveItemsSpec
 func HasAffordableActiveItemsSpecAST() spec.Visitable {
 	return spec.Wildcard(spec.Object(spec.GlobalScope(), "Items"), spec.And(spec.LessThan(spec.Field(spec.Item(), "Price"), spec.Value(500)), spec.Field(spec.Item(), "Active")))
@@ -84,6 +115,11 @@ func HasAffordableActiveItemsSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// HasAffordableActiveItemsSpecSQLPostgres returns Postgres SQL for HasAffordableActiveItemsSpec
+func HasAffordableActiveItemsSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(HasAffordableActiveItemsSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
 // HasPremiumItemsSpecAST returns AST for HasPremiumItemsSpec
 func HasPremiumItemsSpecAST() spec.Visitable {
 	return spec.Wildcard(spec.Object(spec.GlobalScope(), "Items"), spec.And(spec.And(spec.GreaterThan(spec.Field(spec.Item(), "Price"), spec.Value(5000)), spec.GreaterThan(spec.Field(spec.Item(), "Stock"), spec.Value(0))), spec.Field(spec.Item(), "Active")))
@@ -95,6 +131,11 @@ func HasPremiumItemsSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// HasPremiumItemsSpecSQLPostgres returns Postgres SQL for HasPremiumItemsSpec
+func HasPremiumItemsSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(HasPremiumItemsSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
 // HasDiscountedExpensiveItemsSpecAST returns AST for HasDiscountedExpensiveItemsSpec
 func HasDiscountedExpensiveItemsSpecAST() spec.Visitable {
 	return spec.Wildcard(spec.Object(spec.GlobalScope(), "Items"), spec.GreaterThan(spec.Sub(spec.Field(spec.Item(), "Price"), spec.Value(100)), spec.Value(900)))
@@ -106,6 +147,11 @@ func HasDiscountedExpensiveItemsSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// HasDiscountedExpensiveItemsSpecSQLPostgres returns Postgres SQL for HasDiscountedExpensiveItemsSpec
+func HasDiscountedExpensiveItemsSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(HasDiscountedExpensiveItemsSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
 // HasTaxedCheapItemsSpecAST returns AST for HasTaxedCheapItemsSpec
 func HasTaxedCheapItemsSpecAST() spec.Visitable {
 	return spec.Wildcard(spec.Object(spec.GlobalScope(), "Items"), spec.LessThan(spec.Add(spec.Field(spec.Item(), "Price"), spec.Div(spec.Field(spec.Item(), "Price"), spec.Value(10))), spec.Value(110)))
@@ -117,9 +163,14 @@ func HasTaxedCheapItemsSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// HasTaxedCheapItemsSpecSQLPostgres returns Postgres SQL for HasTaxedCheapItemsSpec
+func HasTaxedCheapItemsSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(HasTaxedCheapItemsSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
 // HasItemWithFlagSpecAST returns AST for HasItemWithFlagSpec
 func HasItemWithFlagSpecAST() spec.Visitable {
-	return spec.Wildcard(spec.Object(spec.GlobalScope(), "Items"), spec.Equal(spec.Value(nil) /* TODO: unsupported op & */, spec.Value(1)))
+	return spec.Wildcard(spec.Object(spec.GlobalScope(), "Items"), spec.Equal(spec.BitAnd(spec.Field(spec.Item(), "Stock"), spec.Value(1)), spec.Value(1)))
 }
 
 // HasItemWithFlagSpecSQL returns SQL for HasItemWithFlagSpec
@@ -128,6 +179,11 @@ func HasItemWithFlagSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// HasItemWithFlagSpecSQLPostgres returns Postgres SQL for HasItemWithFlagSpec
+func HasItemWithFlagSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(HasItemWithFlagSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
 // HasItemWithShiftedIDSpecAST returns AST for HasItemWithShiftedIDSpec
 func HasItemWithShiftedIDSpecAST() spec.Visitable {
 	return spec.Wildcard(spec.Object(spec.GlobalScope(), "Items"), spec.Equal(spec.LeftShift(spec.Field(spec.Item(), "ID"), spec.Value(2)), spec.Value(8)))
@@ -139,6 +195,11 @@ func HasItemWithShiftedIDSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// HasItemWithShiftedIDSpecSQLPostgres returns Postgres SQL for HasItemWithShiftedIDSpec
+func HasItemWithShiftedIDSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(HasItemWithShiftedIDSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
 // PremiumActiveStoreSpecAST returns AST for PremiumActiveStoreSpec
 func PremiumActiveStoreSpecAST() spec.Visitable {
 	return spec.And(spec.And(spec.Field(spec.GlobalScope(), "Active"), spec.NotEqual(spec.Field(spec.GlobalScope(), "Name"), spec.Value(""))), spec.Wildcard(spec.Object(spec.GlobalScope(), "Items"), spec.GreaterThan(spec.Field(spec.Item(), "Price"), spec.Value(1000))))
@@ -150,9 +211,14 @@ func PremiumActiveStoreSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// PremiumActiveStoreSpecSQLPostgres returns Postgres SQL for PremiumActiveStoreSpec
+func PremiumActiveStoreSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(PremiumActiveStoreSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
 // BudgetFriendlyStoreSpecAST returns AST for BudgetFriendlyStoreSpec
 func BudgetFriendlyStoreSpecAST() spec.Visitable {
-	return spec.And(spec.Field(spec.GlobalScope(), "Active"), spec.Wildcard(spec.Object(spec.GlobalScope(), "Items"), spec.LessThan(spec.Field(spec.Item(), "Price"), spec.Value(1000))))
+	return spec.And(spec.Field(spec.GlobalScope(), "Active"), spec.Every(spec.Object(spec.GlobalScope(), "Items"), spec.LessThan(spec.Field(spec.Item(), "Price"), spec.Value(1000))))
 }
 
 // BudgetFriendlyStoreSpecSQL returns SQL for BudgetFriendlyStoreSpec
@@ -161,6 +227,11 @@ func BudgetFriendlyStoreSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// BudgetFriendlyStoreSpecSQLPostgres returns Postgres SQL for BudgetFriendlyStoreSpec
+func BudgetFriendlyStoreSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(BudgetFriendlyStoreSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
 // NoExpensiveItemsSpecAST returns AST for NoExpensiveItemsSpec
 func NoExpensiveItemsSpecAST() spec.Visitable {
 	return spec.Not(spec.Wildcard(spec.Object(spec.GlobalScope(), "Items"), spec.GreaterThan(spec.Field(spec.Item(), "Price"), spec.Value(5000))))
@@ -172,9 +243,14 @@ func NoExpensiveItemsSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// NoExpensiveItemsSpecSQLPostgres returns Postgres SQL for NoExpensiveItemsSpec
+func NoExpensiveItemsSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(NoExpensiveItemsSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
 // NotAllItemsActiveSpecAST returns AST for NotAllItemsActiveSpec
 func NotAllItemsActiveSpecAST() spec.Visitable {
-	return spec.Not(spec.Wildcard(spec.Object(spec.GlobalScope(), "Items"), spec.Field(spec.Item(), "Active")))
+	return spec.Not(spec.Every(spec.Object(spec.GlobalScope(), "Items"), spec.Field(spec.Item(), "Active")))
 }
 
 // NotAllItemsActiveSpecSQL returns SQL for NotAllItemsActiveSpec
@@ -183,3 +259,39 @@ func NotAllItemsActiveSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// NotAllItemsActiveSpecSQLPostgres returns Postgres SQL for NotAllItemsActiveSpec
+func NotAllItemsActiveSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(NotAllItemsActiveSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
+// WellStockedStoreSpecAST returns AST for WellStockedStoreSpec
+func WellStockedStoreSpecAST() spec.Visitable {
+	return spec.GreaterThanEqual(spec.CountOf(spec.Object(spec.GlobalScope(), "Items"), spec.GreaterThan(spec.Field(spec.Item(), "Stock"), spec.Value(0))), spec.Value(3))
+}
+
+// WellStockedStoreSpecSQL returns SQL for WellStockedStoreSpec
+func WellStockedStoreSpecSQL() (string, []any, error) {
+	ast := WellStockedStoreSpecAST()
+	return infra.CompileToSQL(ast)
+}
+
+// WellStockedStoreSpecSQLPostgres returns Postgres SQL for WellStockedStoreSpec
+func WellStockedStoreSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(WellStockedStoreSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
+// ValuableStockSpecAST returns AST for ValuableStockSpec
+func ValuableStockSpecAST() spec.Visitable {
+	return spec.GreaterThan(spec.SumOf(spec.Object(spec.GlobalScope(), "Items"), spec.Mul(spec.Field(spec.Item(), "Price"), spec.Field(spec.Item(), "Stock"))), spec.Value(100000))
+}
+
+// ValuableStockSpecSQL returns SQL for ValuableStockSpec
+func ValuableStockSpecSQL() (string, []any, error) {
+	ast := ValuableStockSpecAST()
+	return infra.CompileToSQL(ast)
+}
+
+// ValuableStockSpecSQLPostgres returns Postgres SQL for ValuableStockSpec
+func ValuableStockSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(ValuableStockSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
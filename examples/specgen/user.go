@@ -1,6 +1,8 @@
 package main
 
-//go:generate go run github.com/krew-solutions/ascetic-ddd-go/cmd/specgen -type=User
+import "slices"
+
+//go:generate go run github.com/krew-solutions/ascetic-ddd-go/cmd/specgen -type=User -dialects=postgres,mysql,sqlite
 
 // User represents a domain user
 type User struct {
@@ -46,3 +48,28 @@ func YoungUserSpec(u User) bool {
 func InactiveUserSpec(u User) bool {
 	return !u.Active
 }
+
+// TeenagerUserSpec checks if user is a teenager
+//spec:sql
+func TeenagerUserSpec(u User) bool {
+	return slices.Contains([]int{13, 14, 15, 16, 17, 18, 19}, u.Age)
+}
+
+// IsSenior checks if user is a senior, generated as UserIsSeniorSpec
+//spec:sql
+func (u User) IsSenior() bool {
+	return u.Age >= 65
+}
+
+// EligibleUserSpec checks if user is eligible: seniors always, adults if active
+//spec:sql
+func EligibleUserSpec(u User) bool {
+	switch {
+	case u.Age < 18:
+		return false
+	case u.Age >= 65:
+		return true
+	default:
+		return u.Active
+	}
+}
@@ -1,4 +1,5 @@
 // Code generated by specgen. DO NOT EDIT.
+// Source hash: sha256:7183ebc1d59ace2149e2aa1c018f15a38aaad9f288ce393e921884dd9fcbfd75
 
 package main
 
@@ -18,6 +19,21 @@ func AdultUserSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// AdultUserSpecSQLPostgres returns Postgres SQL for AdultUserSpec
+func AdultUserSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(AdultUserSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
+// AdultUserSpecSQLMySQL returns MySQL SQL for AdultUserSpec
+func AdultUserSpecSQLMySQL() (string, []any, error) {
+	return infra.CompileToSQL(AdultUserSpecAST(), infra.WithDialect(infra.MySQLDialect{}))
+}
+
+// AdultUserSpecSQLSQLite returns SQLite SQL for AdultUserSpec
+func AdultUserSpecSQLSQLite() (string, []any, error) {
+	return infra.CompileToSQL(AdultUserSpecAST(), infra.WithDialect(infra.SQLiteDialect{}))
+}
+
 // ActiveUserSpecAST returns AST for ActiveUserSpec
 func ActiveUserSpecAST() spec.Visitable {
 	return spec.Equal(spec.Field(spec.GlobalScope(), "Active"), spec.Value(true))
@@ -29,6 +45,21 @@ func ActiveUserSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// ActiveUserSpecSQLPostgres returns Postgres SQL for ActiveUserSpec
+func ActiveUserSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(ActiveUserSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
+// ActiveUserSpecSQLMySQL returns MySQL SQL for ActiveUserSpec
+func ActiveUserSpecSQLMySQL() (string, []any, error) {
+	return infra.CompileToSQL(ActiveUserSpecAST(), infra.WithDialect(infra.MySQLDialect{}))
+}
+
+// ActiveUserSpecSQLSQLite returns SQLite SQL for ActiveUserSpec
+func ActiveUserSpecSQLSQLite() (string, []any, error) {
+	return infra.CompileToSQL(ActiveUserSpecAST(), infra.WithDialect(infra.SQLiteDialect{}))
+}
+
 // ValidEmailSpecAST returns AST for ValidEmailSpec
 func ValidEmailSpecAST() spec.Visitable {
 	return spec.NotEqual(spec.Field(spec.GlobalScope(), "Email"), spec.Value(""))
@@ -40,6 +71,21 @@ func ValidEmailSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// ValidEmailSpecSQLPostgres returns Postgres SQL for ValidEmailSpec
+func ValidEmailSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(ValidEmailSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
+// ValidEmailSpecSQLMySQL returns MySQL SQL for ValidEmailSpec
+func ValidEmailSpecSQLMySQL() (string, []any, error) {
+	return infra.CompileToSQL(ValidEmailSpecAST(), infra.WithDialect(infra.MySQLDialect{}))
+}
+
+// ValidEmailSpecSQLSQLite returns SQLite SQL for ValidEmailSpec
+func ValidEmailSpecSQLSQLite() (string, []any, error) {
+	return infra.CompileToSQL(ValidEmailSpecAST(), infra.WithDialect(infra.SQLiteDialect{}))
+}
+
 // PremiumUserSpecAST returns AST for PremiumUserSpec
 func PremiumUserSpecAST() spec.Visitable {
 	return spec.And(spec.And(spec.GreaterThanEqual(spec.Field(spec.GlobalScope(), "Age"), spec.Value(18)), spec.Field(spec.GlobalScope(), "Active")), spec.NotEqual(spec.Field(spec.GlobalScope(), "Name"), spec.Value("")))
@@ -51,6 +97,21 @@ func PremiumUserSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// PremiumUserSpecSQLPostgres returns Postgres SQL for PremiumUserSpec
+func PremiumUserSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(PremiumUserSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
+// PremiumUserSpecSQLMySQL returns MySQL SQL for PremiumUserSpec
+func PremiumUserSpecSQLMySQL() (string, []any, error) {
+	return infra.CompileToSQL(PremiumUserSpecAST(), infra.WithDialect(infra.MySQLDialect{}))
+}
+
+// PremiumUserSpecSQLSQLite returns SQLite SQL for PremiumUserSpec
+func PremiumUserSpecSQLSQLite() (string, []any, error) {
+	return infra.CompileToSQL(PremiumUserSpecAST(), infra.WithDialect(infra.SQLiteDialect{}))
+}
+
 // YoungUserSpecAST returns AST for YoungUserSpec
 func YoungUserSpecAST() spec.Visitable {
 	return spec.LessThan(spec.Field(spec.GlobalScope(), "Age"), spec.Value(25))
@@ -62,6 +123,21 @@ func YoungUserSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// YoungUserSpecSQLPostgres returns Postgres SQL for YoungUserSpec
+func YoungUserSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(YoungUserSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
+// YoungUserSpecSQLMySQL returns MySQL SQL for YoungUserSpec
+func YoungUserSpecSQLMySQL() (string, []any, error) {
+	return infra.CompileToSQL(YoungUserSpecAST(), infra.WithDialect(infra.MySQLDialect{}))
+}
+
+// YoungUserSpecSQLSQLite returns SQLite SQL for YoungUserSpec
+func YoungUserSpecSQLSQLite() (string, []any, error) {
+	return infra.CompileToSQL(YoungUserSpecAST(), infra.WithDialect(infra.SQLiteDialect{}))
+}
+
 // InactiveUserSpecAST returns AST for InactiveUserSpec
 func InactiveUserSpecAST() spec.Visitable {
 	return spec.Not(spec.Field(spec.GlobalScope(), "Active"))
@@ -73,3 +149,95 @@ func InactiveUserSpecSQL() (string, []any, error) {
 	return infra.CompileToSQL(ast)
 }
 
+// InactiveUserSpecSQLPostgres returns Postgres SQL for InactiveUserSpec
+func InactiveUserSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(InactiveUserSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
+// InactiveUserSpecSQLMySQL returns MySQL SQL for InactiveUserSpec
+func InactiveUserSpecSQLMySQL() (string, []any, error) {
+	return infra.CompileToSQL(InactiveUserSpecAST(), infra.WithDialect(infra.MySQLDialect{}))
+}
+
+// InactiveUserSpecSQLSQLite returns SQLite SQL for InactiveUserSpec
+func InactiveUserSpecSQLSQLite() (string, []any, error) {
+	return infra.CompileToSQL(InactiveUserSpecAST(), infra.WithDialect(infra.SQLiteDialect{}))
+}
+
+// TeenagerUserSpecAST returns AST for TeenagerUserSpec
+func TeenagerUserSpecAST() spec.Visitable {
+	return spec.In(spec.Field(spec.GlobalScope(), "Age"), spec.Value([]int{13, 14, 15, 16, 17, 18, 19}))
+}
+
+// TeenagerUserSpecSQL returns SQL for TeenagerUserSpec
+func TeenagerUserSpecSQL() (string, []any, error) {
+	ast := TeenagerUserSpecAST()
+	return infra.CompileToSQL(ast)
+}
+
+// TeenagerUserSpecSQLPostgres returns Postgres SQL for TeenagerUserSpec
+func TeenagerUserSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(TeenagerUserSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
+// TeenagerUserSpecSQLMySQL returns MySQL SQL for TeenagerUserSpec
+func TeenagerUserSpecSQLMySQL() (string, []any, error) {
+	return infra.CompileToSQL(TeenagerUserSpecAST(), infra.WithDialect(infra.MySQLDialect{}))
+}
+
+// TeenagerUserSpecSQLSQLite returns SQLite SQL for TeenagerUserSpec
+func TeenagerUserSpecSQLSQLite() (string, []any, error) {
+	return infra.CompileToSQL(TeenagerUserSpecAST(), infra.WithDialect(infra.SQLiteDialect{}))
+}
+
+// UserIsSeniorSpecAST returns AST for UserIsSeniorSpec
+func UserIsSeniorSpecAST() spec.Visitable {
+	return spec.GreaterThanEqual(spec.Field(spec.GlobalScope(), "Age"), spec.Value(65))
+}
+
+// UserIsSeniorSpecSQL returns SQL for UserIsSeniorSpec
+func UserIsSeniorSpecSQL() (string, []any, error) {
+	ast := UserIsSeniorSpecAST()
+	return infra.CompileToSQL(ast)
+}
+
+// UserIsSeniorSpecSQLPostgres returns Postgres SQL for UserIsSeniorSpec
+func UserIsSeniorSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(UserIsSeniorSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
+// UserIsSeniorSpecSQLMySQL returns MySQL SQL for UserIsSeniorSpec
+func UserIsSeniorSpecSQLMySQL() (string, []any, error) {
+	return infra.CompileToSQL(UserIsSeniorSpecAST(), infra.WithDialect(infra.MySQLDialect{}))
+}
+
+// UserIsSeniorSpecSQLSQLite returns SQLite SQL for UserIsSeniorSpec
+func UserIsSeniorSpecSQLSQLite() (string, []any, error) {
+	return infra.CompileToSQL(UserIsSeniorSpecAST(), infra.WithDialect(infra.SQLiteDialect{}))
+}
+
+// EligibleUserSpecAST returns AST for EligibleUserSpec
+func EligibleUserSpecAST() spec.Visitable {
+	return spec.And(spec.Not(spec.LessThan(spec.Field(spec.GlobalScope(), "Age"), spec.Value(18))), spec.Or(spec.GreaterThanEqual(spec.Field(spec.GlobalScope(), "Age"), spec.Value(65)), spec.Field(spec.GlobalScope(), "Active")))
+}
+
+// EligibleUserSpecSQL returns SQL for EligibleUserSpec
+func EligibleUserSpecSQL() (string, []any, error) {
+	ast := EligibleUserSpecAST()
+	return infra.CompileToSQL(ast)
+}
+
+// EligibleUserSpecSQLPostgres returns Postgres SQL for EligibleUserSpec
+func EligibleUserSpecSQLPostgres() (string, []any, error) {
+	return infra.CompileToSQL(EligibleUserSpecAST(), infra.WithDialect(infra.PostgresDialect{}))
+}
+
+// EligibleUserSpecSQLMySQL returns MySQL SQL for EligibleUserSpec
+func EligibleUserSpecSQLMySQL() (string, []any, error) {
+	return infra.CompileToSQL(EligibleUserSpecAST(), infra.WithDialect(infra.MySQLDialect{}))
+}
+
+// EligibleUserSpecSQLSQLite returns SQLite SQL for EligibleUserSpec
+func EligibleUserSpecSQLSQLite() (string, []any, error) {
+	return infra.CompileToSQL(EligibleUserSpecAST(), infra.WithDialect(infra.SQLiteDialect{}))
+}
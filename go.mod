@@ -5,17 +5,18 @@ go 1.25
 require (
 	github.com/google/uuid v1.3.0
 	github.com/hashicorp/go-multierror v1.1.1
+	github.com/jinzhu/inflection v1.0.0
 	github.com/oklog/ulid/v2 v2.1.0
 	github.com/pkg/errors v0.8.1
 	github.com/stretchr/testify v1.8.4
+	golang.org/x/sync v0.8.0
 )
 
 require (
 	github.com/corpix/uarand v0.0.0-20170723150923-031be390f409 // indirect
 	github.com/jackc/puddle/v2 v2.2.0 // indirect
-	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/kr/text v0.2.0 // indirect
 	github.com/rogpeppe/go-internal v1.14.1 // indirect
-	golang.org/x/sync v0.1.0 // indirect
 )
 
 require (
@@ -29,6 +30,6 @@ require (
 	github.com/sergi/go-diff v1.3.1
 	golang.org/x/crypto v0.6.0 // indirect
 	golang.org/x/text v0.7.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 	syreclabs.com/go/faker v1.2.3
 )